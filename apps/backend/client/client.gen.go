@@ -0,0 +1,2351 @@
+// Code generated by openapigen from the OpenAPI spec. DO NOT EDIT.
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+type AccountResponse struct {
+	ArchivedAt string `json:"archived_at,omitempty"`
+	CreatedAt  string `json:"created_at"`
+	Currency   string `json:"currency"`
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	Type       string `json:"type"`
+	UpdatedAt  string `json:"updated_at"`
+}
+
+type AccountTotalRow struct {
+	AccountID   string `json:"account_id"`
+	AccountName string `json:"account_name"`
+	AccountType string `json:"account_type"`
+	Currency    string `json:"currency"`
+	// Tag, tracking option ID or contact ID; absent for entries without one
+	Group string `json:"group,omitempty"`
+	// Tracking option or contact name when grouped by tracking or contact
+	GroupName string `json:"group_name,omitempty"`
+	// Net movement in minor units; debits positive
+	Total int64 `json:"total"`
+}
+
+type AccountTotalsResponse struct {
+	EndDate            string            `json:"end_date,omitempty"`
+	GroupBy            string            `json:"group_by"`
+	Rows               []AccountTotalRow `json:"rows"`
+	StartDate          string            `json:"start_date,omitempty"`
+	TrackingCategoryID string            `json:"tracking_category_id,omitempty"`
+}
+
+type AddMemberRequest struct {
+	Email string `json:"email"`
+	Role  string `json:"role"`
+}
+
+type AgedBill struct {
+	BillID string `json:"bill_id"`
+	Bucket string `json:"bucket"`
+	// 0 until the due date has passed
+	DaysOverdue int32  `json:"days_overdue"`
+	DueOn       string `json:"due_on"`
+	Number      string `json:"number"`
+	Outstanding int64  `json:"outstanding"`
+}
+
+type AgedContactRow struct {
+	ContactID   string `json:"contact_id"`
+	ContactName string `json:"contact_name"`
+	Currency    string `json:"currency"`
+	// Not yet past due
+	Current  int64         `json:"current"`
+	Days130  int64         `json:"days_1_30"`
+	Days3160 int64         `json:"days_31_60"`
+	Days6190 int64         `json:"days_61_90"`
+	Invoices []AgedInvoice `json:"invoices"`
+	Over90   int64         `json:"over_90"`
+	Total    int64         `json:"total"`
+}
+
+type AgedCurrencyTotal struct {
+	Currency string `json:"currency"`
+	// Not yet past due
+	Current  int64 `json:"current"`
+	Days130  int64 `json:"days_1_30"`
+	Days3160 int64 `json:"days_31_60"`
+	Days6190 int64 `json:"days_61_90"`
+	Over90   int64 `json:"over_90"`
+	Total    int64 `json:"total"`
+}
+
+type AgedInvoice struct {
+	Bucket string `json:"bucket"`
+	// 0 until the due date has passed
+	DaysOverdue int32  `json:"days_overdue"`
+	DueOn       string `json:"due_on"`
+	InvoiceID   string `json:"invoice_id"`
+	Number      string `json:"number"`
+	Outstanding int64  `json:"outstanding"`
+}
+
+type AgedPayablesResponse struct {
+	AsOf string `json:"as_of"`
+	// One row per supplier and currency
+	Rows []AgedSupplierRow `json:"rows"`
+	// One row per currency
+	Totals []AgedCurrencyTotal `json:"totals"`
+}
+
+type AgedReceivablesResponse struct {
+	AsOf string `json:"as_of"`
+	// One row per contact and currency
+	Rows []AgedContactRow `json:"rows"`
+	// One row per currency
+	Totals []AgedCurrencyTotal `json:"totals"`
+}
+
+type AgedSupplierRow struct {
+	Bills       []AgedBill `json:"bills"`
+	ContactID   string     `json:"contact_id"`
+	ContactName string     `json:"contact_name"`
+	Currency    string     `json:"currency"`
+	// Not yet past due
+	Current  int64 `json:"current"`
+	Days130  int64 `json:"days_1_30"`
+	Days3160 int64 `json:"days_31_60"`
+	Days6190 int64 `json:"days_61_90"`
+	Over90   int64 `json:"over_90"`
+	Total    int64 `json:"total"`
+}
+
+type ApiError struct {
+	Code    string         `json:"code"`
+	Details map[string]any `json:"details,omitempty"`
+	Field   string         `json:"field,omitempty"`
+	Message string         `json:"message"`
+}
+
+type ApiTokenResponse struct {
+	CreatedAt  string `json:"created_at"`
+	ExpiresAt  string `json:"expires_at,omitempty"`
+	ID         string `json:"id"`
+	LastUsedAt string `json:"last_used_at,omitempty"`
+	Name       string `json:"name"`
+	// First characters of the token, to tell tokens apart
+	Prefix    string   `json:"prefix"`
+	RevokedAt string   `json:"revoked_at,omitempty"`
+	Scopes    []string `json:"scopes"`
+}
+
+type AttachmentResponse struct {
+	ContentType string `json:"content_type"`
+	CreatedAt   string `json:"created_at"`
+	Filename    string `json:"filename"`
+	ID          string `json:"id"`
+	// Hex SHA-256 of the content
+	SHA256    string `json:"sha256"`
+	SizeBytes int64  `json:"size_bytes"`
+	// Absent while a receipt waits in the inbox
+	TransactionID string `json:"transaction_id,omitempty"`
+	UploadedBy    string `json:"uploaded_by"`
+}
+
+type AttachmentUploadRequest struct {
+	// JPEG, PNG, WebP or PDF of at most 8 MiB
+	File string `json:"file"`
+}
+
+type AuditEventResponse struct {
+	Action      string `json:"action"`
+	ActorEmail  string `json:"actor_email"`
+	ActorMethod string `json:"actor_method"`
+	ActorUserID string `json:"actor_user_id"`
+	// State after the change; absent for deletions
+	After any `json:"after,omitempty"`
+	// State before the change; absent for creations
+	Before    any    `json:"before,omitempty"`
+	CreatedAt string `json:"created_at"`
+	Entity    string `json:"entity"`
+	EntityID  string `json:"entity_id"`
+	// Hex SHA-256 chaining this event to the previous one
+	Hash      string `json:"hash"`
+	ID        string `json:"id"`
+	RequestID string `json:"request_id"`
+	// Position in the organisation's hash chain, from 1
+	Seq int64 `json:"seq"`
+}
+
+type AuditVerificationResponse struct {
+	// First event whose hash does not match; absent while the chain is intact
+	BrokenAtSeq int64 `json:"broken_at_seq,omitempty"`
+	// Events verified before the first break
+	Checked int32 `json:"checked"`
+	Ok      bool  `json:"ok"`
+}
+
+type BatchTransactionResult struct {
+	// Why the transaction failed; fields are relative to the transaction
+	Errors []FieldError `json:"errors,omitempty"`
+	// Position in the request's transactions
+	Index       int32               `json:"index"`
+	Status      string              `json:"status"`
+	Transaction TransactionResponse `json:"transaction,omitempty"`
+}
+
+type BillLineRequest struct {
+	// Expense or asset account the line is debited to
+	AccountID   string `json:"account_id"`
+	Description string `json:"description,omitempty"`
+	// Up to 3 decimal places; defaults to 1
+	Quantity *float64 `json:"quantity,omitempty"`
+	// The tax is debited to the tax code's account
+	TaxCodeID string `json:"tax_code_id,omitempty"`
+	// Minor units before tax; negative for a discount
+	UnitPrice int64 `json:"unit_price"`
+}
+
+type BillPaymentRequest struct {
+	// Bank, credit card or other asset or liability account the money left
+	AccountID string `json:"account_id"`
+	// Minor units; at most the amount outstanding
+	Amount int64 `json:"amount"`
+	// Defaults to Payment for bill and the bill number
+	Description string `json:"description,omitempty"`
+	// As for POST /transactions; a retry returns the payment already posted
+	IdempotencyKey string `json:"idempotency_key"`
+	// Defaults to today in the organisation's timezone
+	PaidOn string `json:"paid_on,omitempty"`
+}
+
+type BillPostingResponse struct {
+	Bill BillResponse `json:"bill"`
+	// Transaction posted by the change; absent when voiding a draft
+	Transaction TransactionResponse `json:"transaction,omitempty"`
+}
+
+type BillRequest struct {
+	// Supplier the bill is from
+	ContactID string `json:"contact_id"`
+	// Defaults to 30 days after issue_on
+	DueOn string `json:"due_on,omitempty"`
+	// Defaults to today in the organisation's timezone; approval posts on this date
+	IssueOn string            `json:"issue_on,omitempty"`
+	Lines   []BillLineRequest `json:"lines"`
+	// The supplier's invoice number; unique per supplier
+	Number string `json:"number"`
+	// Liability account the amount is owed on; sets the bill currency
+	PayableAccountID string `json:"payable_account_id"`
+	// Our reference, such as a purchase order number
+	Reference string `json:"reference,omitempty"`
+}
+
+type BillResponse struct {
+	ApprovedAt string `json:"approved_at,omitempty"`
+	ContactID  string `json:"contact_id"`
+	CreatedAt  string `json:"created_at"`
+	Currency   string `json:"currency"`
+	DueOn      string `json:"due_on"`
+	ID         string `json:"id"`
+	IssueOn    string `json:"issue_on"`
+	// Absent from listings
+	Lines  []InvoiceLineResponse `json:"lines,omitempty"`
+	Number string                `json:"number"`
+	// Total less payments; 0 for drafts and void bills
+	Outstanding      int64  `json:"outstanding"`
+	Paid             int64  `json:"paid"`
+	PaidOn           string `json:"paid_on,omitempty"`
+	PayableAccountID string `json:"payable_account_id"`
+	// Absent from listings
+	Payments  []InvoicePaymentResponse `json:"payments,omitempty"`
+	Reference string                   `json:"reference"`
+	Status    string                   `json:"status"`
+	Subtotal  int64                    `json:"subtotal"`
+	Tax       int64                    `json:"tax"`
+	Total     int64                    `json:"total"`
+	// Transaction posted on approval
+	TransactionID string `json:"transaction_id,omitempty"`
+	UpdatedAt     string `json:"updated_at"`
+	// Transaction reversing the approval, for voided approved bills
+	VoidTransactionID string `json:"void_transaction_id,omitempty"`
+	VoidedOn          string `json:"voided_on,omitempty"`
+}
+
+type CashFlowCurrency struct {
+	Currency string `json:"currency"`
+	// Ordered by expected_on
+	Items    []CashFlowItem `json:"items"`
+	Net      int64          `json:"net"`
+	Payments int64          `json:"payments"`
+	Receipts int64          `json:"receipts"`
+	Weeks    []CashFlowWeek `json:"weeks"`
+}
+
+type CashFlowItem struct {
+	// Amount outstanding
+	Amount      int64  `json:"amount"`
+	ContactID   string `json:"contact_id"`
+	ContactName string `json:"contact_name"`
+	DueOn       string `json:"due_on"`
+	// due_on, or as_of for overdue items
+	ExpectedOn string `json:"expected_on"`
+	// Invoice or bill ID
+	ID     string `json:"id"`
+	Number string `json:"number"`
+	// invoice for money coming in, bill for money going out
+	Source string `json:"source"`
+}
+
+type CashFlowResponse struct {
+	AsOf string `json:"as_of"`
+	// One entry per currency with anything due
+	Currencies []CashFlowCurrency `json:"currencies"`
+	EndsOn     string             `json:"ends_on"`
+}
+
+type CashFlowWeek struct {
+	EndsOn string `json:"ends_on"`
+	// Receipts less payments
+	Net int64 `json:"net"`
+	// Outstanding on bills expected in the week
+	Payments int64 `json:"payments"`
+	// Outstanding on invoices expected in the week
+	Receipts int64 `json:"receipts"`
+	// Net from as_of to the end of the week
+	Running  int64  `json:"running"`
+	StartsOn string `json:"starts_on"`
+}
+
+type CloseYearRequest struct {
+	// Inclusive
+	EndsOn string `json:"ends_on,omitempty"`
+	// Financial year such as FY2026, instead of starts_on and ends_on
+	Period string `json:"period,omitempty"`
+	// Equity account that receives the year's profit or loss
+	RetainedEarningsAccountID string `json:"retained_earnings_account_id"`
+	StartsOn                  string `json:"starts_on,omitempty"`
+}
+
+type CloseYearResponse struct {
+	Lock PeriodLockResponse `json:"lock"`
+	// The closing entries; absent when no income or expense account moved
+	Transaction TransactionResponse `json:"transaction,omitempty"`
+}
+
+type ContactAliasRequest struct {
+	// Matched anywhere in a description, case-insensitively; * stands for any run of characters
+	Pattern string `json:"pattern"`
+}
+
+type ContactAliasResponse struct {
+	CreatedAt string `json:"created_at"`
+	ID        string `json:"id"`
+	Pattern   string `json:"pattern"`
+}
+
+type ContactMatchResponse struct {
+	Contact ContactResponse `json:"contact"`
+	// The alias that matched
+	Pattern string `json:"pattern"`
+}
+
+type ContactRequest struct {
+	// Account suggested for transactions with this contact
+	DefaultAccountID string `json:"default_account_id,omitempty"`
+	Email            string `json:"email,omitempty"`
+	Name             string `json:"name"`
+}
+
+type ContactResponse struct {
+	Aliases          []ContactAliasResponse `json:"aliases"`
+	CreatedAt        string                 `json:"created_at"`
+	DefaultAccountID string                 `json:"default_account_id,omitempty"`
+	Email            string                 `json:"email,omitempty"`
+	ID               string                 `json:"id"`
+	Name             string                 `json:"name"`
+	UpdatedAt        string                 `json:"updated_at"`
+}
+
+type CreateAPITokenRequest struct {
+	// Omit for a token that never expires
+	ExpiresAt *string  `json:"expires_at,omitempty"`
+	Name      string   `json:"name"`
+	Scopes    []string `json:"scopes"`
+}
+
+type CreateAccountRequest struct {
+	// ISO 4217 code
+	Currency string `json:"currency"`
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+}
+
+type CreateOrganisationRequest struct {
+	// First month of the financial year; defaults to 1 (January)
+	FiscalYearStartMonth int32  `json:"fiscal_year_start_month,omitempty"`
+	Name                 string `json:"name"`
+	// IANA timezone reporting periods are computed in; defaults to UTC
+	Timezone string `json:"timezone,omitempty"`
+}
+
+type CreatePeriodLockRequest struct {
+	// Inclusive
+	EndsOn string `json:"ends_on,omitempty"`
+	// Reporting period such as FY2026-Q3 or 2026-05, instead of starts_on and ends_on
+	Period   string `json:"period,omitempty"`
+	Reason   string `json:"reason"`
+	StartsOn string `json:"starts_on,omitempty"`
+}
+
+type CreateReconciliationRequest struct {
+	// Bank or card account the statement belongs to
+	AccountID string `json:"account_id"`
+	// Statement closing balance in minor units
+	ClosingBalance int64 `json:"closing_balance"`
+	// Statement date, inclusive
+	EndsOn string `json:"ends_on"`
+	// Statement opening balance in minor units; defaults to the closing balance of the account's previous statement, or 0 for its first
+	OpeningBalance *int64 `json:"opening_balance,omitempty"`
+	StartsOn       string `json:"starts_on"`
+}
+
+type CreateTrackingOptionRequest struct {
+	Name string `json:"name"`
+}
+
+type CreateTransactionRequest struct {
+	// Defaults to the contact whose alias matches the description
+	ContactID      string               `json:"contact_id,omitempty"`
+	Description    string               `json:"description,omitempty"`
+	Entries        []LedgerEntryRequest `json:"entries"`
+	IdempotencyKey string               `json:"idempotency_key"`
+	// Capture timestamp; defaults to now
+	PostedAt string `json:"posted_at,omitempty"`
+	// Accounting date (YYYY-MM-DD); defaults to the day posted_at falls on in the organisation's timezone
+	PostedOn string `json:"posted_on,omitempty"`
+	Source   string `json:"source"`
+}
+
+type CreateTransactionsBatchRequest struct {
+	// all_or_nothing posts nothing unless every transaction is valid; per_item posts the valid ones and reports the rest
+	Mode         string                     `json:"mode"`
+	Transactions []CreateTransactionRequest `json:"transactions"`
+}
+
+type CreateTransactionsBatchResponse struct {
+	Created  int32                    `json:"created"`
+	Failed   int32                    `json:"failed"`
+	Replayed int32                    `json:"replayed"`
+	Results  []BatchTransactionResult `json:"results"`
+}
+
+type CreateUserRequest struct {
+	Email    string `json:"email"`
+	IsAdmin  bool   `json:"is_admin,omitempty"`
+	Password string `json:"password"`
+}
+
+type CreatedAPITokenResponse struct {
+	CreatedAt  string `json:"created_at"`
+	ExpiresAt  string `json:"expires_at,omitempty"`
+	ID         string `json:"id"`
+	LastUsedAt string `json:"last_used_at,omitempty"`
+	Name       string `json:"name"`
+	// First characters of the token, to tell tokens apart
+	Prefix    string   `json:"prefix"`
+	RevokedAt string   `json:"revoked_at,omitempty"`
+	Scopes    []string `json:"scopes"`
+	// The secret. It is only ever returned here
+	Token string `json:"token"`
+}
+
+type EntryTrackingRequest struct {
+	CategoryID string `json:"category_id"`
+	OptionID   string `json:"option_id"`
+}
+
+type EntryTrackingResponse struct {
+	CategoryID string `json:"category_id"`
+	OptionID   string `json:"option_id"`
+}
+
+type ErrorResponse struct {
+	Error ApiError `json:"error"`
+}
+
+type ExtractedAmount struct {
+	// How sure the scan is of the value; highlight low values for checking
+	Confidence float64 `json:"confidence"`
+	// Minor units
+	Value int64 `json:"value"`
+}
+
+type ExtractedDate struct {
+	// How sure the scan is of the value; highlight low values for checking
+	Confidence float64 `json:"confidence"`
+	Value      string  `json:"value"`
+}
+
+type ExtractedText struct {
+	// How sure the scan is of the value; highlight low values for checking
+	Confidence float64 `json:"confidence"`
+	Value      string  `json:"value"`
+}
+
+type FieldError struct {
+	Code    string `json:"code"`
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+type ImportStatementLinesRequest struct {
+	Lines []StatementLineRequest `json:"lines"`
+}
+
+type InvoiceLineRequest struct {
+	// Income account the line is credited to
+	AccountID   string `json:"account_id"`
+	Description string `json:"description,omitempty"`
+	// Up to 3 decimal places; defaults to 1
+	Quantity  *float64 `json:"quantity,omitempty"`
+	TaxCodeID string   `json:"tax_code_id,omitempty"`
+	// Minor units before tax; negative for a discount
+	UnitPrice int64 `json:"unit_price"`
+}
+
+type InvoiceLineResponse struct {
+	AccountID string `json:"account_id"`
+	// Quantity times unit price, before tax
+	Amount      int64   `json:"amount"`
+	Description string  `json:"description"`
+	ID          string  `json:"id"`
+	Quantity    float64 `json:"quantity"`
+	Tax         int64   `json:"tax"`
+	TaxCodeID   string  `json:"tax_code_id,omitempty"`
+	UnitPrice   int64   `json:"unit_price"`
+}
+
+type InvoiceLogoRequest struct {
+	ContentType string `json:"content_type"`
+	// Base64-encoded image, at most 512 KiB and 4000 pixels a side
+	Data string `json:"data"`
+}
+
+type InvoicePaymentRequest struct {
+	// Bank or other asset account the money arrived in
+	AccountID string `json:"account_id"`
+	// Minor units; at most the amount outstanding
+	Amount int64 `json:"amount"`
+	// Defaults to today in the organisation's timezone
+	PaidOn string `json:"paid_on,omitempty"`
+}
+
+type InvoicePaymentResponse struct {
+	AccountID     string `json:"account_id"`
+	Amount        int64  `json:"amount"`
+	CreatedAt     string `json:"created_at"`
+	ID            string `json:"id"`
+	PaidOn        string `json:"paid_on"`
+	TransactionID string `json:"transaction_id"`
+}
+
+type InvoicePostingResponse struct {
+	Invoice InvoiceResponse `json:"invoice"`
+	// Transaction posted by the change; absent when voiding a draft
+	Transaction TransactionResponse `json:"transaction,omitempty"`
+}
+
+type InvoiceRequest struct {
+	// Customer the invoice is to
+	ContactID string `json:"contact_id"`
+	// Defaults to 30 days after issue_on
+	DueOn string `json:"due_on,omitempty"`
+	// Defaults to today in the organisation's timezone; approval posts on this date
+	IssueOn string               `json:"issue_on,omitempty"`
+	Lines   []InvoiceLineRequest `json:"lines"`
+	// Defaults to the next INV-0001 style number
+	Number string `json:"number,omitempty"`
+	// Asset account the amount is owed on; sets the invoice currency
+	ReceivableAccountID string `json:"receivable_account_id"`
+	// Customer's reference, such as a purchase order number
+	Reference string `json:"reference,omitempty"`
+}
+
+type InvoiceResponse struct {
+	ContactID string `json:"contact_id"`
+	CreatedAt string `json:"created_at"`
+	Currency  string `json:"currency"`
+	DueOn     string `json:"due_on"`
+	ID        string `json:"id"`
+	IssueOn   string `json:"issue_on"`
+	// Absent from listings
+	Lines  []InvoiceLineResponse `json:"lines,omitempty"`
+	Number string                `json:"number"`
+	// Total less payments; 0 for drafts and void invoices
+	Outstanding int64  `json:"outstanding"`
+	Paid        int64  `json:"paid"`
+	PaidOn      string `json:"paid_on,omitempty"`
+	// Absent from listings
+	Payments            []InvoicePaymentResponse `json:"payments,omitempty"`
+	ReceivableAccountID string                   `json:"receivable_account_id"`
+	Reference           string                   `json:"reference"`
+	SentAt              string                   `json:"sent_at,omitempty"`
+	Status              string                   `json:"status"`
+	Subtotal            int64                    `json:"subtotal"`
+	Tax                 int64                    `json:"tax"`
+	Total               int64                    `json:"total"`
+	// Transaction posted on approval
+	TransactionID string `json:"transaction_id,omitempty"`
+	UpdatedAt     string `json:"updated_at"`
+	// Transaction reversing the approval, for voided sent invoices
+	VoidTransactionID string `json:"void_transaction_id,omitempty"`
+	VoidedOn          string `json:"voided_on,omitempty"`
+}
+
+type InvoiceTemplateRequest struct {
+	// #rrggbb; defaults to #1f4e79
+	AccentColor string `json:"accent_color,omitempty"`
+	// One line per address line
+	Address string `json:"address,omitempty"`
+	Email   string `json:"email,omitempty"`
+	// Go text/template shown at the foot of every page, with the same fields as payment_instructions
+	Footer string `json:"footer,omitempty"`
+	// Go text/template over the invoice, e.g. Pay {{.AmountDue}} {{.Currency}} to 12-3456-0123456-00 by {{.DueOn}} quoting {{.Number}}. Fields: Number, Reference, Currency, IssueOn, DueOn, Total, Paid, AmountDue, Customer, TradingName
+	PaymentInstructions string `json:"payment_instructions,omitempty"`
+	// GST number; when set, PDFs are titled TAX INVOICE
+	TaxNumber string `json:"tax_number,omitempty"`
+	// Name invoices are issued under; defaults to the organisation's name
+	TradingName string `json:"trading_name,omitempty"`
+}
+
+type InvoiceTemplateResponse struct {
+	AccentColor string `json:"accent_color"`
+	Address     string `json:"address"`
+	Email       string `json:"email"`
+	Footer      string `json:"footer"`
+	// Absent without a logo
+	LogoType            string `json:"logo_type,omitempty"`
+	PaymentInstructions string `json:"payment_instructions"`
+	TaxNumber           string `json:"tax_number"`
+	TradingName         string `json:"trading_name"`
+	// Absent until the template is first saved
+	UpdatedAt string `json:"updated_at,omitempty"`
+}
+
+type LedgerEntryRequest struct {
+	AccountID string `json:"account_id"`
+	// Minor units; debits positive, credits negative
+	Amount int64 `json:"amount"`
+	// Free-form labels, stored trimmed and lowercased
+	Tags []string `json:"tags,omitempty"`
+	// At most one active option per tracking category
+	Tracking []EntryTrackingRequest `json:"tracking,omitempty"`
+}
+
+type LedgerEntryResponse struct {
+	AccountID string                  `json:"account_id"`
+	Amount    int64                   `json:"amount"`
+	Currency  string                  `json:"currency"`
+	ID        string                  `json:"id"`
+	Tags      []string                `json:"tags,omitempty"`
+	Tracking  []EntryTrackingResponse `json:"tracking,omitempty"`
+}
+
+type LoginRequest struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+type MatchStatementLineRequest struct {
+	LedgerEntryID string `json:"ledger_entry_id"`
+}
+
+type MemberResponse struct {
+	CreatedAt string `json:"created_at"`
+	Email     string `json:"email"`
+	Role      string `json:"role"`
+	UserID    string `json:"user_id"`
+}
+
+type MergeContactRequest struct {
+	// Contact to merge into this one; it is deleted once its transactions and aliases move over
+	ContactID string `json:"contact_id"`
+}
+
+type MergeContactResponse struct {
+	AliasesMoved      int64           `json:"aliases_moved"`
+	BillsMoved        int64           `json:"bills_moved"`
+	Contact           ContactResponse `json:"contact"`
+	InvoicesMoved     int64           `json:"invoices_moved"`
+	MergedContactID   string          `json:"merged_contact_id"`
+	ReceiptsMoved     int64           `json:"receipts_moved"`
+	TransactionsMoved int64           `json:"transactions_moved"`
+}
+
+type OrganisationResponse struct {
+	CreatedAt            string `json:"created_at"`
+	FiscalYearStartMonth int32  `json:"fiscal_year_start_month"`
+	ID                   string `json:"id"`
+	Name                 string `json:"name"`
+	// The caller's role
+	Role     string `json:"role"`
+	Timezone string `json:"timezone"`
+}
+
+type PeriodLockResponse struct {
+	CreatedAt string `json:"created_at"`
+	// Inclusive
+	EndsOn   string `json:"ends_on"`
+	ID       string `json:"id"`
+	LockedBy string `json:"locked_by"`
+	Reason   string `json:"reason"`
+	StartsOn string `json:"starts_on"`
+}
+
+type PostReceiptRequest struct {
+	// Account debited with the spending; defaults to the suggested account_id
+	AccountID string `json:"account_id,omitempty"`
+	// Defaults to the scanned merchant
+	Description string `json:"description,omitempty"`
+	// Minor units; defaults to the scanned GST. Only used with tax_account_id
+	GST            *int64 `json:"gst,omitempty"`
+	IdempotencyKey string `json:"idempotency_key"`
+	// Bank, card or cash account the receipt was paid from
+	PaymentAccountID string `json:"payment_account_id"`
+	// Defaults to the scanned date
+	PostedOn string `json:"posted_on,omitempty"`
+	// Account debited with the GST; without it the GST stays in the amount debited to account_id
+	TaxAccountID string `json:"tax_account_id,omitempty"`
+	// Minor units, GST included; defaults to the scanned total
+	Total *int64 `json:"total,omitempty"`
+}
+
+type PrincipalResponse struct {
+	Email  string   `json:"email"`
+	Method string   `json:"method"`
+	Scopes []string `json:"scopes"`
+	UserID string   `json:"user_id"`
+}
+
+type ReceiptResponse struct {
+	// Suggested account: the contact's default account
+	AccountID  string             `json:"account_id,omitempty"`
+	Attachment AttachmentResponse `json:"attachment"`
+	Attempts   int32              `json:"attempts"`
+	// Suggested contact, whose alias matches the merchant
+	ContactID string        `json:"contact_id,omitempty"`
+	CreatedAt string        `json:"created_at"`
+	Date      ExtractedDate `json:"date,omitempty"`
+	// Why the last scan attempt failed
+	Error string `json:"error,omitempty"`
+	// GST included in the total
+	GST      ExtractedAmount `json:"gst,omitempty"`
+	ID       string          `json:"id"`
+	Merchant ExtractedText   `json:"merchant,omitempty"`
+	// Set once scanned: attached when the file was already on a transaction, matched when an imported bank line has its total near its date, draft when it waits to be posted and posted once it has been
+	Outcome string `json:"outcome,omitempty"`
+	// Imported bank line the receipt matched
+	StatementLineID string `json:"statement_line_id,omitempty"`
+	Status          string `json:"status"`
+	// Everything recognised, one line per line
+	Text string `json:"text,omitempty"`
+	// Amount paid, GST included
+	Total ExtractedAmount `json:"total,omitempty"`
+	// Transaction the receipt is evidence for
+	TransactionID string `json:"transaction_id,omitempty"`
+	UpdatedAt     string `json:"updated_at"`
+}
+
+type ReconciliationEntryResponse struct {
+	Amount        int64  `json:"amount"`
+	Description   string `json:"description"`
+	LedgerEntryID string `json:"ledger_entry_id"`
+	PostedOn      string `json:"posted_on"`
+}
+
+type ReconciliationReportResponse struct {
+	// The statement's opening balance plus the ledger entries matched to its lines
+	ClearedBalance int64 `json:"cleared_balance"`
+	// statement_closing_balance minus cleared_balance
+	Difference              int64                  `json:"difference"`
+	Reconciliation          ReconciliationResponse `json:"reconciliation"`
+	StatementClosingBalance int64                  `json:"statement_closing_balance"`
+	// Ledger entries up to the statement date without an accepted match
+	UnclearedEntries []ReconciliationEntryResponse `json:"uncleared_entries"`
+	// Statement lines without an accepted match
+	UnreconciledLines []StatementLineResponse `json:"unreconciled_lines"`
+}
+
+type ReconciliationResponse struct {
+	AccountID      string `json:"account_id"`
+	ClosingBalance int64  `json:"closing_balance"`
+	CompletedAt    string `json:"completed_at,omitempty"`
+	CreatedAt      string `json:"created_at"`
+	CreatedBy      string `json:"created_by"`
+	EndsOn         string `json:"ends_on"`
+	ID             string `json:"id"`
+	OpeningBalance int64  `json:"opening_balance"`
+	StartsOn       string `json:"starts_on"`
+	Status         string `json:"status"`
+}
+
+type SessionResponse struct {
+	ExpiresAt string       `json:"expires_at"`
+	User      UserResponse `json:"user"`
+}
+
+type SetTransactionContactRequest struct {
+	// Empty clears the contact
+	ContactID string `json:"contact_id,omitempty"`
+}
+
+type SplitPartRequest struct {
+	AccountID string `json:"account_id"`
+	// Minor units, same sign as the split entry; give amount or percent
+	Amount *int64 `json:"amount,omitempty"`
+	// Share of the split entry with up to 2 decimal places; percents must total 100
+	Percent  *float64               `json:"percent,omitempty"`
+	Tags     []string               `json:"tags,omitempty"`
+	Tracking []EntryTrackingRequest `json:"tracking,omitempty"`
+}
+
+type SplitTransactionRequest struct {
+	// Defaults to the original description prefixed with "Split: "
+	Description    string `json:"description,omitempty"`
+	IdempotencyKey string `json:"idempotency_key"`
+	// Entry to reallocate; defaults to the transaction's only expense entry
+	LedgerEntryID string             `json:"ledger_entry_id,omitempty"`
+	Parts         []SplitPartRequest `json:"parts"`
+	// Defaults to the original transaction's accounting date
+	PostedOn string `json:"posted_on,omitempty"`
+}
+
+type StatementLineRequest struct {
+	// Minor units; deposits positive, withdrawals negative
+	Amount      int64  `json:"amount"`
+	Description string `json:"description,omitempty"`
+	PostedOn    string `json:"posted_on"`
+	Reference   string `json:"reference,omitempty"`
+}
+
+type StatementLineResponse struct {
+	Amount        int64  `json:"amount"`
+	Description   string `json:"description"`
+	ID            string `json:"id"`
+	LedgerEntryID string `json:"ledger_entry_id,omitempty"`
+	// Auto-matcher confidence from 0 to 1
+	MatchScore  *float64 `json:"match_score,omitempty"`
+	MatchStatus string   `json:"match_status"`
+	PostedOn    string   `json:"posted_on"`
+	Reference   string   `json:"reference"`
+}
+
+type TaxCodeRequest struct {
+	// Liability account the tax is credited to; required unless rate is 0
+	AccountID string `json:"account_id,omitempty"`
+	Name      string `json:"name"`
+	// Percent with up to 2 decimal places, from 0 to 100
+	Rate float64 `json:"rate"`
+}
+
+type TaxCodeResponse struct {
+	AccountID string `json:"account_id,omitempty"`
+	CreatedAt string `json:"created_at"`
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	// Percent
+	Rate      float64 `json:"rate"`
+	UpdatedAt string  `json:"updated_at"`
+}
+
+type TrackingCategoryRequest struct {
+	Name string `json:"name"`
+}
+
+type TrackingCategoryResponse struct {
+	CreatedAt string                   `json:"created_at"`
+	ID        string                   `json:"id"`
+	Name      string                   `json:"name"`
+	Options   []TrackingOptionResponse `json:"options"`
+	UpdatedAt string                   `json:"updated_at"`
+}
+
+type TrackingOptionResponse struct {
+	Active     bool   `json:"active"`
+	CategoryID string `json:"category_id"`
+	CreatedAt  string `json:"created_at"`
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	UpdatedAt  string `json:"updated_at"`
+}
+
+type TransactionResponse struct {
+	// Receipts and documents; only returned by GET /transactions/{id}
+	Attachments []AttachmentResponse `json:"attachments,omitempty"`
+	// Bill the transaction approves, pays or voids
+	BillID    string `json:"bill_id,omitempty"`
+	ContactID string `json:"contact_id,omitempty"`
+	// Ledger entry this transaction reverses and reposts, for corrections such as splits
+	CorrectsEntryID string                `json:"corrects_entry_id,omitempty"`
+	CreatedAt       string                `json:"created_at"`
+	Description     string                `json:"description"`
+	Entries         []LedgerEntryResponse `json:"entries,omitempty"`
+	ID              string                `json:"id"`
+	// Empty once the key's retention window has passed
+	IdempotencyKey string `json:"idempotency_key"`
+	// Capture timestamp
+	PostedAt string `json:"posted_at"`
+	// Accounting date
+	PostedOn string `json:"posted_on"`
+	Source   string `json:"source"`
+}
+
+type TransactionSearchResult struct {
+	// HTML-escaped description with matched words wrapped in <mark>
+	Highlight string `json:"highlight"`
+	// Higher is a better match
+	Rank        float64             `json:"rank"`
+	Transaction TransactionResponse `json:"transaction"`
+}
+
+type UpdateAccountRequest struct {
+	Name *string `json:"name,omitempty"`
+	Type *string `json:"type,omitempty"`
+}
+
+type UpdateMemberRequest struct {
+	Role string `json:"role"`
+}
+
+type UpdateOrganisationRequest struct {
+	FiscalYearStartMonth *int32  `json:"fiscal_year_start_month,omitempty"`
+	Name                 *string `json:"name,omitempty"`
+	Timezone             *string `json:"timezone,omitempty"`
+}
+
+type UpdateTrackingOptionRequest struct {
+	// Inactive options stay on existing entries but cannot be used on new ones
+	Active *bool   `json:"active,omitempty"`
+	Name   *string `json:"name,omitempty"`
+}
+
+type UserResponse struct {
+	CreatedAt string `json:"created_at"`
+	Email     string `json:"email"`
+	ID        string `json:"id"`
+	IsAdmin   bool   `json:"is_admin"`
+}
+
+type VoidBillRequest struct {
+	// Date the reversal posts on; defaults to today in the organisation's timezone
+	VoidedOn string `json:"voided_on,omitempty"`
+}
+
+type VoidInvoiceRequest struct {
+	// Date the reversal posts on; defaults to today in the organisation's timezone
+	VoidedOn string `json:"voided_on,omitempty"`
+}
+
+// ListAccountsParams holds the query parameters of ListAccounts.
+type ListAccountsParams struct {
+	// Include archived accounts
+	IncludeArchived *bool
+}
+
+// ListAccounts calls GET /accounts.
+//
+// List accounts.
+func (c *Client) ListAccounts(ctx context.Context, params *ListAccountsParams) ([]AccountResponse, error) {
+	q := url.Values{}
+	if params != nil {
+		if params.IncludeArchived != nil {
+			q.Set("include_archived", strconv.FormatBool(*params.IncludeArchived))
+		}
+	}
+	var out []AccountResponse
+	if err := c.do(ctx, http.MethodGet, "/accounts", q, nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// CreateAccount calls POST /accounts.
+//
+// Create an account.
+func (c *Client) CreateAccount(ctx context.Context, body CreateAccountRequest) (*AccountResponse, error) {
+	var out AccountResponse
+	if err := c.do(ctx, http.MethodPost, "/accounts", nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DeleteAccount calls DELETE /accounts/{id}.
+//
+// Delete an account that has no ledger entries.
+func (c *Client) DeleteAccount(ctx context.Context, id string) error {
+	return c.do(ctx, http.MethodDelete, fmt.Sprintf("/accounts/%s", url.PathEscape(id)), nil, nil, nil)
+}
+
+// GetAccount calls GET /accounts/{id}.
+//
+// Get an account.
+func (c *Client) GetAccount(ctx context.Context, id string) (*AccountResponse, error) {
+	var out AccountResponse
+	if err := c.do(ctx, http.MethodGet, fmt.Sprintf("/accounts/%s", url.PathEscape(id)), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// UpdateAccount calls PUT /accounts/{id}.
+//
+// Rename or retype an account.
+func (c *Client) UpdateAccount(ctx context.Context, id string, body UpdateAccountRequest) (*AccountResponse, error) {
+	var out AccountResponse
+	if err := c.do(ctx, http.MethodPut, fmt.Sprintf("/accounts/%s", url.PathEscape(id)), nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ArchiveAccount calls POST /accounts/{id}/archive.
+//
+// Archive an account.
+func (c *Client) ArchiveAccount(ctx context.Context, id string) (*AccountResponse, error) {
+	var out AccountResponse
+	if err := c.do(ctx, http.MethodPost, fmt.Sprintf("/accounts/%s/archive", url.PathEscape(id)), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// UnarchiveAccount calls POST /accounts/{id}/unarchive.
+//
+// Unarchive an account.
+func (c *Client) UnarchiveAccount(ctx context.Context, id string) (*AccountResponse, error) {
+	var out AccountResponse
+	if err := c.do(ctx, http.MethodPost, fmt.Sprintf("/accounts/%s/unarchive", url.PathEscape(id)), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListAuditEventsParams holds the query parameters of ListAuditEvents.
+type ListAuditEventsParams struct {
+	// Only events for this kind of entity
+	Entity string
+	// Only events for this entity
+	EntityID string
+}
+
+// ListAuditEvents calls GET /audit.
+//
+// List audit events, newest first.
+func (c *Client) ListAuditEvents(ctx context.Context, params *ListAuditEventsParams) ([]AuditEventResponse, error) {
+	q := url.Values{}
+	if params != nil {
+		if params.Entity != "" {
+			q.Set("entity", params.Entity)
+		}
+		if params.EntityID != "" {
+			q.Set("entity_id", params.EntityID)
+		}
+	}
+	var out []AuditEventResponse
+	if err := c.do(ctx, http.MethodGet, "/audit", q, nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// VerifyAuditChain calls GET /audit/verify.
+//
+// Recompute the audit hash chain and report the first break.
+func (c *Client) VerifyAuditChain(ctx context.Context) (*AuditVerificationResponse, error) {
+	var out AuditVerificationResponse
+	if err := c.do(ctx, http.MethodGet, "/audit/verify", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// Login calls POST /auth/login.
+//
+// Log in with email and password; sets the session cookie.
+func (c *Client) Login(ctx context.Context, body LoginRequest) (*SessionResponse, error) {
+	var out SessionResponse
+	if err := c.do(ctx, http.MethodPost, "/auth/login", nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// Logout calls POST /auth/logout.
+//
+// End the current session and clear the cookie.
+func (c *Client) Logout(ctx context.Context) error {
+	return c.do(ctx, http.MethodPost, "/auth/logout", nil, nil, nil)
+}
+
+// GetCurrentPrincipal calls GET /auth/me.
+//
+// Describe the authenticated caller.
+func (c *Client) GetCurrentPrincipal(ctx context.Context) (*PrincipalResponse, error) {
+	var out PrincipalResponse
+	if err := c.do(ctx, http.MethodGet, "/auth/me", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListAPITokens calls GET /auth/tokens.
+//
+// List the caller's API tokens.
+func (c *Client) ListAPITokens(ctx context.Context) ([]ApiTokenResponse, error) {
+	var out []ApiTokenResponse
+	if err := c.do(ctx, http.MethodGet, "/auth/tokens", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// CreateAPIToken calls POST /auth/tokens.
+//
+// Create a personal API token; the secret is returned only once.
+func (c *Client) CreateAPIToken(ctx context.Context, body CreateAPITokenRequest) (*CreatedAPITokenResponse, error) {
+	var out CreatedAPITokenResponse
+	if err := c.do(ctx, http.MethodPost, "/auth/tokens", nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// RevokeAPIToken calls DELETE /auth/tokens/{id}.
+//
+// Revoke one of the caller's API tokens.
+func (c *Client) RevokeAPIToken(ctx context.Context, id string) error {
+	return c.do(ctx, http.MethodDelete, fmt.Sprintf("/auth/tokens/%s", url.PathEscape(id)), nil, nil, nil)
+}
+
+// ListBillsParams holds the query parameters of ListBills.
+type ListBillsParams struct {
+	// Only bills in this state
+	Status string
+	// Only bills from this supplier
+	ContactID string
+}
+
+// ListBills calls GET /bills.
+//
+// List bills, newest first, without their lines.
+func (c *Client) ListBills(ctx context.Context, params *ListBillsParams) ([]BillResponse, error) {
+	q := url.Values{}
+	if params != nil {
+		if params.Status != "" {
+			q.Set("status", params.Status)
+		}
+		if params.ContactID != "" {
+			q.Set("contact_id", params.ContactID)
+		}
+	}
+	var out []BillResponse
+	if err := c.do(ctx, http.MethodGet, "/bills", q, nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// CreateBill calls POST /bills.
+//
+// Create a draft bill from a supplier.
+func (c *Client) CreateBill(ctx context.Context, body BillRequest) (*BillResponse, error) {
+	var out BillResponse
+	if err := c.do(ctx, http.MethodPost, "/bills", nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DeleteBill calls DELETE /bills/{id}.
+//
+// Delete a draft bill.
+func (c *Client) DeleteBill(ctx context.Context, id string) error {
+	return c.do(ctx, http.MethodDelete, fmt.Sprintf("/bills/%s", url.PathEscape(id)), nil, nil, nil)
+}
+
+// GetBill calls GET /bills/{id}.
+//
+// Get a bill with its lines and payments.
+func (c *Client) GetBill(ctx context.Context, id string) (*BillResponse, error) {
+	var out BillResponse
+	if err := c.do(ctx, http.MethodGet, fmt.Sprintf("/bills/%s", url.PathEscape(id)), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// UpdateBill calls PUT /bills/{id}.
+//
+// Replace a draft bill, lines included.
+func (c *Client) UpdateBill(ctx context.Context, id string, body BillRequest) (*BillResponse, error) {
+	var out BillResponse
+	if err := c.do(ctx, http.MethodPut, fmt.Sprintf("/bills/%s", url.PathEscape(id)), nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ApproveBill calls POST /bills/{id}/approve.
+//
+// Approve a draft and post it to expenses, tax and payables.
+func (c *Client) ApproveBill(ctx context.Context, id string) (*BillPostingResponse, error) {
+	var out BillPostingResponse
+	if err := c.do(ctx, http.MethodPost, fmt.Sprintf("/bills/%s/approve", url.PathEscape(id)), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// RecordBillPayment calls POST /bills/{id}/payments.
+//
+// Pay some or all of an approved bill, posting a transaction linked to it.
+func (c *Client) RecordBillPayment(ctx context.Context, id string, body BillPaymentRequest) (*TransactionResponse, error) {
+	var out TransactionResponse
+	if err := c.do(ctx, http.MethodPost, fmt.Sprintf("/bills/%s/payments", url.PathEscape(id)), nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// VoidBill calls POST /bills/{id}/void.
+//
+// Void an unpaid bill, reversing its posting if it was approved.
+func (c *Client) VoidBill(ctx context.Context, id string, body VoidBillRequest) (*BillPostingResponse, error) {
+	var out BillPostingResponse
+	if err := c.do(ctx, http.MethodPost, fmt.Sprintf("/bills/%s/void", url.PathEscape(id)), nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListContacts calls GET /contacts.
+//
+// List contacts with their aliases.
+func (c *Client) ListContacts(ctx context.Context) ([]ContactResponse, error) {
+	var out []ContactResponse
+	if err := c.do(ctx, http.MethodGet, "/contacts", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// CreateContact calls POST /contacts.
+//
+// Create a payee, customer or supplier.
+func (c *Client) CreateContact(ctx context.Context, body ContactRequest) (*ContactResponse, error) {
+	var out ContactResponse
+	if err := c.do(ctx, http.MethodPost, "/contacts", nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// MatchContactParams holds the query parameters of MatchContact.
+type MatchContactParams struct {
+	// Description as it appears on a statement; required
+	Description string
+}
+
+// MatchContact calls GET /contacts/match.
+//
+// Find the contact whose alias matches a raw description.
+func (c *Client) MatchContact(ctx context.Context, params *MatchContactParams) (*ContactMatchResponse, error) {
+	q := url.Values{}
+	if params != nil {
+		if params.Description != "" {
+			q.Set("description", params.Description)
+		}
+	}
+	var out ContactMatchResponse
+	if err := c.do(ctx, http.MethodGet, "/contacts/match", q, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DeleteContact calls DELETE /contacts/{id}.
+//
+// Delete a contact no transaction, invoice, bill or receipt names.
+func (c *Client) DeleteContact(ctx context.Context, id string) error {
+	return c.do(ctx, http.MethodDelete, fmt.Sprintf("/contacts/%s", url.PathEscape(id)), nil, nil, nil)
+}
+
+// GetContact calls GET /contacts/{id}.
+//
+// Get a contact with its aliases.
+func (c *Client) GetContact(ctx context.Context, id string) (*ContactResponse, error) {
+	var out ContactResponse
+	if err := c.do(ctx, http.MethodGet, fmt.Sprintf("/contacts/%s", url.PathEscape(id)), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// UpdateContact calls PUT /contacts/{id}.
+//
+// Replace a contact's name, email and default account.
+func (c *Client) UpdateContact(ctx context.Context, id string, body ContactRequest) (*ContactResponse, error) {
+	var out ContactResponse
+	if err := c.do(ctx, http.MethodPut, fmt.Sprintf("/contacts/%s", url.PathEscape(id)), nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// CreateContactAlias calls POST /contacts/{id}/aliases.
+//
+// Add a pattern that maps descriptions to the contact.
+func (c *Client) CreateContactAlias(ctx context.Context, id string, body ContactAliasRequest) (*ContactAliasResponse, error) {
+	var out ContactAliasResponse
+	if err := c.do(ctx, http.MethodPost, fmt.Sprintf("/contacts/%s/aliases", url.PathEscape(id)), nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DeleteContactAlias calls DELETE /contacts/{id}/aliases/{alias_id}.
+//
+// Remove a contact alias.
+func (c *Client) DeleteContactAlias(ctx context.Context, id string, aliasID string) error {
+	return c.do(ctx, http.MethodDelete, fmt.Sprintf("/contacts/%s/aliases/%s", url.PathEscape(id), url.PathEscape(aliasID)), nil, nil, nil)
+}
+
+// MergeContact calls POST /contacts/{id}/merge.
+//
+// Merge a duplicate contact into this one, moving everything that names it.
+func (c *Client) MergeContact(ctx context.Context, id string, body MergeContactRequest) (*MergeContactResponse, error) {
+	var out MergeContactResponse
+	if err := c.do(ctx, http.MethodPost, fmt.Sprintf("/contacts/%s/merge", url.PathEscape(id)), nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// Healthz calls GET /healthz.
+//
+// Liveness probe.
+func (c *Client) Healthz(ctx context.Context) (string, error) {
+	var out string
+	if err := c.do(ctx, http.MethodGet, "/healthz", nil, nil, &out); err != nil {
+		return "", err
+	}
+	return out, nil
+}
+
+// GetInvoiceTemplate calls GET /invoice-template.
+//
+// Get the branding and text invoice PDFs are rendered with.
+func (c *Client) GetInvoiceTemplate(ctx context.Context) (*InvoiceTemplateResponse, error) {
+	var out InvoiceTemplateResponse
+	if err := c.do(ctx, http.MethodGet, "/invoice-template", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// UpdateInvoiceTemplate calls PUT /invoice-template.
+//
+// Replace the invoice template apart from the logo.
+func (c *Client) UpdateInvoiceTemplate(ctx context.Context, body InvoiceTemplateRequest) (*InvoiceTemplateResponse, error) {
+	var out InvoiceTemplateResponse
+	if err := c.do(ctx, http.MethodPut, "/invoice-template", nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DeleteInvoiceLogo calls DELETE /invoice-template/logo.
+//
+// Remove the invoice logo.
+func (c *Client) DeleteInvoiceLogo(ctx context.Context) error {
+	return c.do(ctx, http.MethodDelete, "/invoice-template/logo", nil, nil, nil)
+}
+
+// UpdateInvoiceLogo calls PUT /invoice-template/logo.
+//
+// Upload the PNG or JPEG logo shown on invoice PDFs.
+func (c *Client) UpdateInvoiceLogo(ctx context.Context, body InvoiceLogoRequest) (*InvoiceTemplateResponse, error) {
+	var out InvoiceTemplateResponse
+	if err := c.do(ctx, http.MethodPut, "/invoice-template/logo", nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListInvoicesParams holds the query parameters of ListInvoices.
+type ListInvoicesParams struct {
+	// Only invoices in this state
+	Status string
+	// Only invoices to this contact
+	ContactID string
+}
+
+// ListInvoices calls GET /invoices.
+//
+// List invoices, newest first, without their lines.
+func (c *Client) ListInvoices(ctx context.Context, params *ListInvoicesParams) ([]InvoiceResponse, error) {
+	q := url.Values{}
+	if params != nil {
+		if params.Status != "" {
+			q.Set("status", params.Status)
+		}
+		if params.ContactID != "" {
+			q.Set("contact_id", params.ContactID)
+		}
+	}
+	var out []InvoiceResponse
+	if err := c.do(ctx, http.MethodGet, "/invoices", q, nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// CreateInvoice calls POST /invoices.
+//
+// Create a draft sales invoice.
+func (c *Client) CreateInvoice(ctx context.Context, body InvoiceRequest) (*InvoiceResponse, error) {
+	var out InvoiceResponse
+	if err := c.do(ctx, http.MethodPost, "/invoices", nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DeleteInvoice calls DELETE /invoices/{id}.
+//
+// Delete a draft invoice.
+func (c *Client) DeleteInvoice(ctx context.Context, id string) error {
+	return c.do(ctx, http.MethodDelete, fmt.Sprintf("/invoices/%s", url.PathEscape(id)), nil, nil, nil)
+}
+
+// GetInvoice calls GET /invoices/{id}.
+//
+// Get an invoice with its lines and payments.
+func (c *Client) GetInvoice(ctx context.Context, id string) (*InvoiceResponse, error) {
+	var out InvoiceResponse
+	if err := c.do(ctx, http.MethodGet, fmt.Sprintf("/invoices/%s", url.PathEscape(id)), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// UpdateInvoice calls PUT /invoices/{id}.
+//
+// Replace a draft invoice, lines included.
+func (c *Client) UpdateInvoice(ctx context.Context, id string, body InvoiceRequest) (*InvoiceResponse, error) {
+	var out InvoiceResponse
+	if err := c.do(ctx, http.MethodPut, fmt.Sprintf("/invoices/%s", url.PathEscape(id)), nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetInvoicePDF calls GET /invoices/{id}.pdf.
+//
+// Render an invoice as a PDF using the invoice template.
+func (c *Client) GetInvoicePDF(ctx context.Context, id string) ([]byte, error) {
+	var out []byte
+	if err := c.do(ctx, http.MethodGet, fmt.Sprintf("/invoices/%s.pdf", url.PathEscape(id)), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ApproveInvoice calls POST /invoices/{id}/approve.
+//
+// Mark a draft as sent and post it to receivables, income and tax.
+func (c *Client) ApproveInvoice(ctx context.Context, id string) (*InvoicePostingResponse, error) {
+	var out InvoicePostingResponse
+	if err := c.do(ctx, http.MethodPost, fmt.Sprintf("/invoices/%s/approve", url.PathEscape(id)), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// RecordInvoicePayment calls POST /invoices/{id}/payments.
+//
+// Record money received against a sent invoice, clearing the receivable.
+func (c *Client) RecordInvoicePayment(ctx context.Context, id string, body InvoicePaymentRequest) (*InvoicePostingResponse, error) {
+	var out InvoicePostingResponse
+	if err := c.do(ctx, http.MethodPost, fmt.Sprintf("/invoices/%s/payments", url.PathEscape(id)), nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// VoidInvoice calls POST /invoices/{id}/void.
+//
+// Void an unpaid invoice, reversing its posting if it was sent.
+func (c *Client) VoidInvoice(ctx context.Context, id string, body VoidInvoiceRequest) (*InvoicePostingResponse, error) {
+	var out InvoicePostingResponse
+	if err := c.do(ctx, http.MethodPost, fmt.Sprintf("/invoices/%s/void", url.PathEscape(id)), nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetOpenAPISpec calls GET /openapi.json.
+//
+// This document.
+func (c *Client) GetOpenAPISpec(ctx context.Context) (map[string]any, error) {
+	var out map[string]any
+	if err := c.do(ctx, http.MethodGet, "/openapi.json", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ListOrganisations calls GET /organisations.
+//
+// List the caller's organisations.
+func (c *Client) ListOrganisations(ctx context.Context) ([]OrganisationResponse, error) {
+	var out []OrganisationResponse
+	if err := c.do(ctx, http.MethodGet, "/organisations", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// CreateOrganisation calls POST /organisations.
+//
+// Create an organisation owned by the caller.
+func (c *Client) CreateOrganisation(ctx context.Context, body CreateOrganisationRequest) (*OrganisationResponse, error) {
+	var out OrganisationResponse
+	if err := c.do(ctx, http.MethodPost, "/organisations", nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// UpdateOrganisation calls PUT /organisations/{id}.
+//
+// Rename an organisation or change its reporting calendar; owners only.
+func (c *Client) UpdateOrganisation(ctx context.Context, id string, body UpdateOrganisationRequest) (*OrganisationResponse, error) {
+	var out OrganisationResponse
+	if err := c.do(ctx, http.MethodPut, fmt.Sprintf("/organisations/%s", url.PathEscape(id)), nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListMembers calls GET /organisations/{id}/members.
+//
+// List an organisation's members.
+func (c *Client) ListMembers(ctx context.Context, id string) ([]MemberResponse, error) {
+	var out []MemberResponse
+	if err := c.do(ctx, http.MethodGet, fmt.Sprintf("/organisations/%s/members", url.PathEscape(id)), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// AddMember calls POST /organisations/{id}/members.
+//
+// Add a user to an organisation; owners only.
+func (c *Client) AddMember(ctx context.Context, id string, body AddMemberRequest) (*MemberResponse, error) {
+	var out MemberResponse
+	if err := c.do(ctx, http.MethodPost, fmt.Sprintf("/organisations/%s/members", url.PathEscape(id)), nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// RemoveMember calls DELETE /organisations/{id}/members/{user_id}.
+//
+// Remove a member, or leave an organisation.
+func (c *Client) RemoveMember(ctx context.Context, id string, userID string) error {
+	return c.do(ctx, http.MethodDelete, fmt.Sprintf("/organisations/%s/members/%s", url.PathEscape(id), url.PathEscape(userID)), nil, nil, nil)
+}
+
+// UpdateMember calls PUT /organisations/{id}/members/{user_id}.
+//
+// Change a member's role; owners only.
+func (c *Client) UpdateMember(ctx context.Context, id string, userID string, body UpdateMemberRequest) (*MemberResponse, error) {
+	var out MemberResponse
+	if err := c.do(ctx, http.MethodPut, fmt.Sprintf("/organisations/%s/members/%s", url.PathEscape(id), url.PathEscape(userID)), nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListPeriodLocks calls GET /period-locks.
+//
+// List locked periods.
+func (c *Client) ListPeriodLocks(ctx context.Context) ([]PeriodLockResponse, error) {
+	var out []PeriodLockResponse
+	if err := c.do(ctx, http.MethodGet, "/period-locks", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// CreatePeriodLock calls POST /period-locks.
+//
+// Lock a period against postings; owners only.
+func (c *Client) CreatePeriodLock(ctx context.Context, body CreatePeriodLockRequest) (*PeriodLockResponse, error) {
+	var out PeriodLockResponse
+	if err := c.do(ctx, http.MethodPost, "/period-locks", nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// CloseYear calls POST /period-locks/close-year.
+//
+// Post closing entries into retained earnings and lock the year; owners only.
+func (c *Client) CloseYear(ctx context.Context, body CloseYearRequest) (*CloseYearResponse, error) {
+	var out CloseYearResponse
+	if err := c.do(ctx, http.MethodPost, "/period-locks/close-year", nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DeletePeriodLock calls DELETE /period-locks/{id}.
+//
+// Unlock a period; audited.
+func (c *Client) DeletePeriodLock(ctx context.Context, id string) error {
+	return c.do(ctx, http.MethodDelete, fmt.Sprintf("/period-locks/%s", url.PathEscape(id)), nil, nil, nil)
+}
+
+// Readyz calls GET /readyz.
+//
+// Readiness probe; checks the database and fails once shutdown has begun.
+func (c *Client) Readyz(ctx context.Context) (string, error) {
+	var out string
+	if err := c.do(ctx, http.MethodGet, "/readyz", nil, nil, &out); err != nil {
+		return "", err
+	}
+	return out, nil
+}
+
+// ListReceiptsParams holds the query parameters of ListReceipts.
+type ListReceiptsParams struct {
+	// Only receipts whose scan is in this state
+	Status string
+	// Only receipts whose scan led to this; draft lists the receipts waiting to be posted
+	Outcome string
+}
+
+// ListReceipts calls GET /receipts.
+//
+// List receipts with what their scans read, newest first.
+func (c *Client) ListReceipts(ctx context.Context, params *ListReceiptsParams) ([]ReceiptResponse, error) {
+	q := url.Values{}
+	if params != nil {
+		if params.Status != "" {
+			q.Set("status", params.Status)
+		}
+		if params.Outcome != "" {
+			q.Set("outcome", params.Outcome)
+		}
+	}
+	var out []ReceiptResponse
+	if err := c.do(ctx, http.MethodGet, "/receipts", q, nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// CreateReceipt calls POST /receipts.
+//
+// Upload a photographed receipt (JPEG, PNG, WebP or PDF) to the inbox and queue it for scanning; re-uploading a file already there returns that receipt.
+func (c *Client) CreateReceipt(ctx context.Context, file File) (*ReceiptResponse, error) {
+	var out ReceiptResponse
+	if err := c.do(ctx, http.MethodPost, "/receipts", nil, formFile{field: "file", file: file}, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DeleteReceipt calls DELETE /receipts/{id}.
+//
+// Discard a receipt still in the inbox.
+func (c *Client) DeleteReceipt(ctx context.Context, id string) error {
+	return c.do(ctx, http.MethodDelete, fmt.Sprintf("/receipts/%s", url.PathEscape(id)), nil, nil, nil)
+}
+
+// GetReceipt calls GET /receipts/{id}.
+//
+// Get a receipt with the fields its scan read and their confidence.
+func (c *Client) GetReceipt(ctx context.Context, id string) (*ReceiptResponse, error) {
+	var out ReceiptResponse
+	if err := c.do(ctx, http.MethodGet, fmt.Sprintf("/receipts/%s", url.PathEscape(id)), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetReceiptContent calls GET /receipts/{id}/content.
+//
+// Download a receipt's file.
+func (c *Client) GetReceiptContent(ctx context.Context, id string) ([]byte, error) {
+	var out []byte
+	if err := c.do(ctx, http.MethodGet, fmt.Sprintf("/receipts/%s/content", url.PathEscape(id)), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// PostReceipt calls POST /receipts/{id}/post.
+//
+// Post a draft receipt as a transaction paid from a bank or card account, filing the receipt on it.
+func (c *Client) PostReceipt(ctx context.Context, id string, body PostReceiptRequest) (*TransactionResponse, error) {
+	var out TransactionResponse
+	if err := c.do(ctx, http.MethodPost, fmt.Sprintf("/receipts/%s/post", url.PathEscape(id)), nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// RescanReceipt calls POST /receipts/{id}/rescan.
+//
+// Queue a receipt to be scanned again from scratch.
+func (c *Client) RescanReceipt(ctx context.Context, id string) (*ReceiptResponse, error) {
+	var out ReceiptResponse
+	if err := c.do(ctx, http.MethodPost, fmt.Sprintf("/receipts/%s/rescan", url.PathEscape(id)), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListReconciliationsParams holds the query parameters of ListReconciliations.
+type ListReconciliationsParams struct {
+	// Only reconciliations of this account
+	AccountID string
+}
+
+// ListReconciliations calls GET /reconciliations.
+//
+// List reconciliations.
+func (c *Client) ListReconciliations(ctx context.Context, params *ListReconciliationsParams) ([]ReconciliationResponse, error) {
+	q := url.Values{}
+	if params != nil {
+		if params.AccountID != "" {
+			q.Set("account_id", params.AccountID)
+		}
+	}
+	var out []ReconciliationResponse
+	if err := c.do(ctx, http.MethodGet, "/reconciliations", q, nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// CreateReconciliation calls POST /reconciliations.
+//
+// Start reconciling an account against a bank statement.
+func (c *Client) CreateReconciliation(ctx context.Context, body CreateReconciliationRequest) (*ReconciliationResponse, error) {
+	var out ReconciliationResponse
+	if err := c.do(ctx, http.MethodPost, "/reconciliations", nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetReconciliation calls GET /reconciliations/{id}.
+//
+// Get a reconciliation.
+func (c *Client) GetReconciliation(ctx context.Context, id string) (*ReconciliationResponse, error) {
+	var out ReconciliationResponse
+	if err := c.do(ctx, http.MethodGet, fmt.Sprintf("/reconciliations/%s", url.PathEscape(id)), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// AutoMatchStatementLines calls POST /reconciliations/{id}/auto-match.
+//
+// Suggest ledger entries for unmatched lines by amount, date and description.
+func (c *Client) AutoMatchStatementLines(ctx context.Context, id string) ([]StatementLineResponse, error) {
+	var out []StatementLineResponse
+	if err := c.do(ctx, http.MethodPost, fmt.Sprintf("/reconciliations/%s/auto-match", url.PathEscape(id)), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// CompleteReconciliation calls POST /reconciliations/{id}/complete.
+//
+// Complete a balanced reconciliation.
+func (c *Client) CompleteReconciliation(ctx context.Context, id string) (*ReconciliationResponse, error) {
+	var out ReconciliationResponse
+	if err := c.do(ctx, http.MethodPost, fmt.Sprintf("/reconciliations/%s/complete", url.PathEscape(id)), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListStatementLines calls GET /reconciliations/{id}/lines.
+//
+// List statement lines with their match state.
+func (c *Client) ListStatementLines(ctx context.Context, id string) ([]StatementLineResponse, error) {
+	var out []StatementLineResponse
+	if err := c.do(ctx, http.MethodGet, fmt.Sprintf("/reconciliations/%s/lines", url.PathEscape(id)), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ImportStatementLines calls POST /reconciliations/{id}/lines.
+//
+// Import statement lines.
+func (c *Client) ImportStatementLines(ctx context.Context, id string, body ImportStatementLinesRequest) ([]StatementLineResponse, error) {
+	var out []StatementLineResponse
+	if err := c.do(ctx, http.MethodPost, fmt.Sprintf("/reconciliations/%s/lines", url.PathEscape(id)), nil, body, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// AcceptStatementLineMatch calls POST /reconciliations/{id}/lines/{line_id}/accept.
+//
+// Accept a suggested match.
+func (c *Client) AcceptStatementLineMatch(ctx context.Context, id string, lineID string) (*StatementLineResponse, error) {
+	var out StatementLineResponse
+	if err := c.do(ctx, http.MethodPost, fmt.Sprintf("/reconciliations/%s/lines/%s/accept", url.PathEscape(id), url.PathEscape(lineID)), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// MatchStatementLine calls POST /reconciliations/{id}/lines/{line_id}/match.
+//
+// Match a line to a ledger entry by hand.
+func (c *Client) MatchStatementLine(ctx context.Context, id string, lineID string, body MatchStatementLineRequest) (*StatementLineResponse, error) {
+	var out StatementLineResponse
+	if err := c.do(ctx, http.MethodPost, fmt.Sprintf("/reconciliations/%s/lines/%s/match", url.PathEscape(id), url.PathEscape(lineID)), nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// RejectStatementLineMatch calls POST /reconciliations/{id}/lines/{line_id}/reject.
+//
+// Reject a match so it is not suggested again.
+func (c *Client) RejectStatementLineMatch(ctx context.Context, id string, lineID string) (*StatementLineResponse, error) {
+	var out StatementLineResponse
+	if err := c.do(ctx, http.MethodPost, fmt.Sprintf("/reconciliations/%s/lines/%s/reject", url.PathEscape(id), url.PathEscape(lineID)), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetReconciliationReport calls GET /reconciliations/{id}/report.
+//
+// Compare the statement closing balance with the cleared ledger balance.
+func (c *Client) GetReconciliationReport(ctx context.Context, id string) (*ReconciliationReportResponse, error) {
+	var out ReconciliationReportResponse
+	if err := c.do(ctx, http.MethodGet, fmt.Sprintf("/reconciliations/%s/report", url.PathEscape(id)), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetAccountTotalsReportParams holds the query parameters of GetAccountTotalsReport.
+type GetAccountTotalsReportParams struct {
+	// Inclusive lower bound on the accounting date posted_on (YYYY-MM-DD)
+	StartDate string
+	// Inclusive upper bound on the accounting date posted_on (YYYY-MM-DD)
+	EndDate string
+	// Reporting period in the organisation's calendar, such as FY2026-Q3 or 2026-05; replaces start_date and end_date
+	Period string
+	// Break each account's total down by tag, tracking option or contact; defaults to account
+	GroupBy string
+	// Category whose options to group by; required with group_by=tracking
+	TrackingCategoryID string
+}
+
+// GetAccountTotalsReport calls GET /reports/account-totals.
+//
+// Net movement per account, optionally grouped by tag, tracking option or contact.
+func (c *Client) GetAccountTotalsReport(ctx context.Context, params *GetAccountTotalsReportParams) (*AccountTotalsResponse, error) {
+	q := url.Values{}
+	if params != nil {
+		if params.StartDate != "" {
+			q.Set("start_date", params.StartDate)
+		}
+		if params.EndDate != "" {
+			q.Set("end_date", params.EndDate)
+		}
+		if params.Period != "" {
+			q.Set("period", params.Period)
+		}
+		if params.GroupBy != "" {
+			q.Set("group_by", params.GroupBy)
+		}
+		if params.TrackingCategoryID != "" {
+			q.Set("tracking_category_id", params.TrackingCategoryID)
+		}
+	}
+	var out AccountTotalsResponse
+	if err := c.do(ctx, http.MethodGet, "/reports/account-totals", q, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetAgedPayablesReportParams holds the query parameters of GetAgedPayablesReport.
+type GetAgedPayablesReportParams struct {
+	// Day to age balances on; defaults to today in the organisation's timezone
+	AsOf string
+}
+
+// GetAgedPayablesReport calls GET /reports/aged-payables.
+//
+// Amounts owed on approved bills by supplier and days past due.
+func (c *Client) GetAgedPayablesReport(ctx context.Context, params *GetAgedPayablesReportParams) (*AgedPayablesResponse, error) {
+	q := url.Values{}
+	if params != nil {
+		if params.AsOf != "" {
+			q.Set("as_of", params.AsOf)
+		}
+	}
+	var out AgedPayablesResponse
+	if err := c.do(ctx, http.MethodGet, "/reports/aged-payables", q, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetAgedReceivablesReportParams holds the query parameters of GetAgedReceivablesReport.
+type GetAgedReceivablesReportParams struct {
+	// Day to age balances on; defaults to today in the organisation's timezone
+	AsOf string
+}
+
+// GetAgedReceivablesReport calls GET /reports/aged-receivables.
+//
+// Amounts owed on approved invoices by contact and days past due.
+func (c *Client) GetAgedReceivablesReport(ctx context.Context, params *GetAgedReceivablesReportParams) (*AgedReceivablesResponse, error) {
+	q := url.Values{}
+	if params != nil {
+		if params.AsOf != "" {
+			q.Set("as_of", params.AsOf)
+		}
+	}
+	var out AgedReceivablesResponse
+	if err := c.do(ctx, http.MethodGet, "/reports/aged-receivables", q, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetCashFlowReportParams holds the query parameters of GetCashFlowReport.
+type GetCashFlowReportParams struct {
+	// How many days ahead to project; defaults to 30, at most 366
+	Days *int64
+}
+
+// GetCashFlowReport calls GET /reports/cash-flow.
+//
+// Receipts and payments expected from outstanding invoices and bills, by week.
+func (c *Client) GetCashFlowReport(ctx context.Context, params *GetCashFlowReportParams) (*CashFlowResponse, error) {
+	q := url.Values{}
+	if params != nil {
+		if params.Days != nil {
+			q.Set("days", strconv.FormatInt(*params.Days, 10))
+		}
+	}
+	var out CashFlowResponse
+	if err := c.do(ctx, http.MethodGet, "/reports/cash-flow", q, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListTaxCodes calls GET /tax-codes.
+//
+// List tax codes.
+func (c *Client) ListTaxCodes(ctx context.Context) ([]TaxCodeResponse, error) {
+	var out []TaxCodeResponse
+	if err := c.do(ctx, http.MethodGet, "/tax-codes", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// CreateTaxCode calls POST /tax-codes.
+//
+// Create a sales tax rate and the liability account it is owed on.
+func (c *Client) CreateTaxCode(ctx context.Context, body TaxCodeRequest) (*TaxCodeResponse, error) {
+	var out TaxCodeResponse
+	if err := c.do(ctx, http.MethodPost, "/tax-codes", nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DeleteTaxCode calls DELETE /tax-codes/{id}.
+//
+// Delete a tax code no invoice or bill line uses.
+func (c *Client) DeleteTaxCode(ctx context.Context, id string) error {
+	return c.do(ctx, http.MethodDelete, fmt.Sprintf("/tax-codes/%s", url.PathEscape(id)), nil, nil, nil)
+}
+
+// GetTaxCode calls GET /tax-codes/{id}.
+//
+// Get a tax code.
+func (c *Client) GetTaxCode(ctx context.Context, id string) (*TaxCodeResponse, error) {
+	var out TaxCodeResponse
+	if err := c.do(ctx, http.MethodGet, fmt.Sprintf("/tax-codes/%s", url.PathEscape(id)), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// UpdateTaxCode calls PUT /tax-codes/{id}.
+//
+// Replace a tax code's name, rate and account.
+func (c *Client) UpdateTaxCode(ctx context.Context, id string, body TaxCodeRequest) (*TaxCodeResponse, error) {
+	var out TaxCodeResponse
+	if err := c.do(ctx, http.MethodPut, fmt.Sprintf("/tax-codes/%s", url.PathEscape(id)), nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListTrackingCategories calls GET /tracking-categories.
+//
+// List tracking categories with their options.
+func (c *Client) ListTrackingCategories(ctx context.Context) ([]TrackingCategoryResponse, error) {
+	var out []TrackingCategoryResponse
+	if err := c.do(ctx, http.MethodGet, "/tracking-categories", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// CreateTrackingCategory calls POST /tracking-categories.
+//
+// Create a tracking category such as a department or project.
+func (c *Client) CreateTrackingCategory(ctx context.Context, body TrackingCategoryRequest) (*TrackingCategoryResponse, error) {
+	var out TrackingCategoryResponse
+	if err := c.do(ctx, http.MethodPost, "/tracking-categories", nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DeleteTrackingCategory calls DELETE /tracking-categories/{id}.
+//
+// Delete a tracking category no ledger entry uses.
+func (c *Client) DeleteTrackingCategory(ctx context.Context, id string) error {
+	return c.do(ctx, http.MethodDelete, fmt.Sprintf("/tracking-categories/%s", url.PathEscape(id)), nil, nil, nil)
+}
+
+// GetTrackingCategory calls GET /tracking-categories/{id}.
+//
+// Get a tracking category with its options.
+func (c *Client) GetTrackingCategory(ctx context.Context, id string) (*TrackingCategoryResponse, error) {
+	var out TrackingCategoryResponse
+	if err := c.do(ctx, http.MethodGet, fmt.Sprintf("/tracking-categories/%s", url.PathEscape(id)), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// UpdateTrackingCategory calls PUT /tracking-categories/{id}.
+//
+// Rename a tracking category.
+func (c *Client) UpdateTrackingCategory(ctx context.Context, id string, body TrackingCategoryRequest) (*TrackingCategoryResponse, error) {
+	var out TrackingCategoryResponse
+	if err := c.do(ctx, http.MethodPut, fmt.Sprintf("/tracking-categories/%s", url.PathEscape(id)), nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// CreateTrackingOption calls POST /tracking-categories/{id}/options.
+//
+// Add an option to a tracking category.
+func (c *Client) CreateTrackingOption(ctx context.Context, id string, body CreateTrackingOptionRequest) (*TrackingOptionResponse, error) {
+	var out TrackingOptionResponse
+	if err := c.do(ctx, http.MethodPost, fmt.Sprintf("/tracking-categories/%s/options", url.PathEscape(id)), nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DeleteTrackingOption calls DELETE /tracking-categories/{id}/options/{option_id}.
+//
+// Delete a tracking option no ledger entry uses.
+func (c *Client) DeleteTrackingOption(ctx context.Context, id string, optionID string) error {
+	return c.do(ctx, http.MethodDelete, fmt.Sprintf("/tracking-categories/%s/options/%s", url.PathEscape(id), url.PathEscape(optionID)), nil, nil, nil)
+}
+
+// UpdateTrackingOption calls PUT /tracking-categories/{id}/options/{option_id}.
+//
+// Rename, deactivate or reactivate a tracking option.
+func (c *Client) UpdateTrackingOption(ctx context.Context, id string, optionID string, body UpdateTrackingOptionRequest) (*TrackingOptionResponse, error) {
+	var out TrackingOptionResponse
+	if err := c.do(ctx, http.MethodPut, fmt.Sprintf("/tracking-categories/%s/options/%s", url.PathEscape(id), url.PathEscape(optionID)), nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListTransactionsParams holds the query parameters of ListTransactions.
+type ListTransactionsParams struct {
+	// Only transactions with an entry on this account
+	AccountID string
+	// Inclusive lower bound on the accounting date posted_on (YYYY-MM-DD)
+	StartDate string
+	// Inclusive upper bound on the accounting date posted_on (YYYY-MM-DD)
+	EndDate string
+	// Reporting period in the organisation's calendar, such as FY2026-Q3 or 2026-05; replaces start_date and end_date
+	Period string
+	// Only transactions with an entry carrying this tag
+	Tag string
+	// Only transactions with an entry tracked to this option
+	TrackingOptionID string
+	// Only transactions from this source; closing marks year-end closing entries, invoice those posted by invoices and bill those posted by bills
+	Source string
+	// Inclusive lower bound in minor units on the account_id entry, or on the transaction's total debits without account_id
+	MinAmount *int64
+	// Inclusive upper bound in minor units, measured as for min_amount
+	MaxAmount *int64
+	// Case-insensitive substring of the description
+	DescriptionContains string
+	// Only transactions with an entry on this account, other than the account_id side
+	CounterpartyAccountID string
+	// Only transactions filed under this contact
+	ContactID string
+	// Only transactions whose entries carry at least one tag (true) or none (false)
+	HasTag *bool
+	// Sort key; amount is measured as for min_amount. Defaults to posted_on
+	Sort string
+	// Sort direction; defaults to desc
+	Order string
+}
+
+// ListTransactions calls GET /transactions.
+//
+// List transactions.
+func (c *Client) ListTransactions(ctx context.Context, params *ListTransactionsParams) ([]TransactionResponse, error) {
+	q := url.Values{}
+	if params != nil {
+		if params.AccountID != "" {
+			q.Set("account_id", params.AccountID)
+		}
+		if params.StartDate != "" {
+			q.Set("start_date", params.StartDate)
+		}
+		if params.EndDate != "" {
+			q.Set("end_date", params.EndDate)
+		}
+		if params.Period != "" {
+			q.Set("period", params.Period)
+		}
+		if params.Tag != "" {
+			q.Set("tag", params.Tag)
+		}
+		if params.TrackingOptionID != "" {
+			q.Set("tracking_option_id", params.TrackingOptionID)
+		}
+		if params.Source != "" {
+			q.Set("source", params.Source)
+		}
+		if params.MinAmount != nil {
+			q.Set("min_amount", strconv.FormatInt(*params.MinAmount, 10))
+		}
+		if params.MaxAmount != nil {
+			q.Set("max_amount", strconv.FormatInt(*params.MaxAmount, 10))
+		}
+		if params.DescriptionContains != "" {
+			q.Set("description_contains", params.DescriptionContains)
+		}
+		if params.CounterpartyAccountID != "" {
+			q.Set("counterparty_account_id", params.CounterpartyAccountID)
+		}
+		if params.ContactID != "" {
+			q.Set("contact_id", params.ContactID)
+		}
+		if params.HasTag != nil {
+			q.Set("has_tag", strconv.FormatBool(*params.HasTag))
+		}
+		if params.Sort != "" {
+			q.Set("sort", params.Sort)
+		}
+		if params.Order != "" {
+			q.Set("order", params.Order)
+		}
+	}
+	var out []TransactionResponse
+	if err := c.do(ctx, http.MethodGet, "/transactions", q, nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// CreateTransaction calls POST /transactions.
+//
+// Post a balanced transaction.
+func (c *Client) CreateTransaction(ctx context.Context, body CreateTransactionRequest) (*TransactionResponse, error) {
+	var out TransactionResponse
+	if err := c.do(ctx, http.MethodPost, "/transactions", nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// CreateTransactionsBatch calls POST /transactions/batch.
+//
+// Post up to 10000 transactions, all or nothing or reporting each one.
+func (c *Client) CreateTransactionsBatch(ctx context.Context, body CreateTransactionsBatchRequest) (*CreateTransactionsBatchResponse, error) {
+	var out CreateTransactionsBatchResponse
+	if err := c.do(ctx, http.MethodPost, "/transactions/batch", nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// SearchTransactionsParams holds the query parameters of SearchTransactions.
+type SearchTransactionsParams struct {
+	// Words to find in descriptions, in web search syntax ("exact phrase", or, -exclude), plus amount:>100, amount:-45.50 (major units, debits positive; every amount term must hold for one entry) and tag:travel terms
+	Q string
+	// Only transactions with an entry on this account
+	AccountID string
+	// Inclusive lower bound on the accounting date posted_on (YYYY-MM-DD)
+	StartDate string
+	// Inclusive upper bound on the accounting date posted_on (YYYY-MM-DD)
+	EndDate string
+	// Reporting period in the organisation's calendar, such as FY2026-Q3 or 2026-05; replaces start_date and end_date
+	Period string
+	// Only transactions with an entry carrying this tag
+	Tag string
+	// Only transactions with an entry tracked to this option
+	TrackingOptionID string
+}
+
+// SearchTransactions calls GET /transactions/search.
+//
+// Search transaction descriptions, ranked, with fuzzy matching and amount and tag terms.
+func (c *Client) SearchTransactions(ctx context.Context, params *SearchTransactionsParams) ([]TransactionSearchResult, error) {
+	q := url.Values{}
+	if params != nil {
+		if params.Q != "" {
+			q.Set("q", params.Q)
+		}
+		if params.AccountID != "" {
+			q.Set("account_id", params.AccountID)
+		}
+		if params.StartDate != "" {
+			q.Set("start_date", params.StartDate)
+		}
+		if params.EndDate != "" {
+			q.Set("end_date", params.EndDate)
+		}
+		if params.Period != "" {
+			q.Set("period", params.Period)
+		}
+		if params.Tag != "" {
+			q.Set("tag", params.Tag)
+		}
+		if params.TrackingOptionID != "" {
+			q.Set("tracking_option_id", params.TrackingOptionID)
+		}
+	}
+	var out []TransactionSearchResult
+	if err := c.do(ctx, http.MethodGet, "/transactions/search", q, nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetTransaction calls GET /transactions/{id}.
+//
+// Get a transaction with its entries.
+func (c *Client) GetTransaction(ctx context.Context, id string) (*TransactionResponse, error) {
+	var out TransactionResponse
+	if err := c.do(ctx, http.MethodGet, fmt.Sprintf("/transactions/%s", url.PathEscape(id)), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// CreateAttachment calls POST /transactions/{id}/attachments.
+//
+// Attach a receipt or document (JPEG, PNG, WebP or PDF) to a transaction; re-uploading a file it already has returns that attachment.
+func (c *Client) CreateAttachment(ctx context.Context, id string, file File) (*AttachmentResponse, error) {
+	var out AttachmentResponse
+	if err := c.do(ctx, http.MethodPost, fmt.Sprintf("/transactions/%s/attachments", url.PathEscape(id)), nil, formFile{field: "file", file: file}, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DeleteAttachment calls DELETE /transactions/{id}/attachments/{attachment_id}.
+//
+// Remove an attachment, deleting its content once no transaction refers to it.
+func (c *Client) DeleteAttachment(ctx context.Context, id string, attachmentID string) error {
+	return c.do(ctx, http.MethodDelete, fmt.Sprintf("/transactions/%s/attachments/%s", url.PathEscape(id), url.PathEscape(attachmentID)), nil, nil, nil)
+}
+
+// GetAttachmentContent calls GET /transactions/{id}/attachments/{attachment_id}.
+//
+// Download an attachment's content.
+func (c *Client) GetAttachmentContent(ctx context.Context, id string, attachmentID string) ([]byte, error) {
+	var out []byte
+	if err := c.do(ctx, http.MethodGet, fmt.Sprintf("/transactions/%s/attachments/%s", url.PathEscape(id), url.PathEscape(attachmentID)), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// SetTransactionContact calls PUT /transactions/{id}/contact.
+//
+// Set or clear the contact of a transaction.
+func (c *Client) SetTransactionContact(ctx context.Context, id string, body SetTransactionContactRequest) (*TransactionResponse, error) {
+	var out TransactionResponse
+	if err := c.do(ctx, http.MethodPut, fmt.Sprintf("/transactions/%s/contact", url.PathEscape(id)), nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// SplitTransaction calls POST /transactions/{id}/split.
+//
+// Reallocate one entry across accounts by amounts or percentages with a correcting transaction.
+func (c *Client) SplitTransaction(ctx context.Context, id string, body SplitTransactionRequest) (*TransactionResponse, error) {
+	var out TransactionResponse
+	if err := c.do(ctx, http.MethodPost, fmt.Sprintf("/transactions/%s/split", url.PathEscape(id)), nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListUsers calls GET /users.
+//
+// List users.
+func (c *Client) ListUsers(ctx context.Context) ([]UserResponse, error) {
+	var out []UserResponse
+	if err := c.do(ctx, http.MethodGet, "/users", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// CreateUser calls POST /users.
+//
+// Create a user.
+func (c *Client) CreateUser(ctx context.Context, body CreateUserRequest) (*UserResponse, error) {
+	var out UserResponse
+	if err := c.do(ctx, http.MethodPost, "/users", nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
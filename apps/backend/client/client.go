@@ -0,0 +1,198 @@
+// Package client is a Go client for the Go Figure API. The request and
+// response types and one method per operation live in client.gen.go, which is
+// generated from the OpenAPI spec; this file holds the transport.
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+	header     http.Header
+}
+
+// Option configures a Client.
+type Option func(*Client)
+
+// WithHTTPClient replaces http.DefaultClient.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = hc
+	}
+}
+
+// WithHeader adds a header to every request.
+func WithHeader(key, value string) Option {
+	return func(c *Client) {
+		c.header.Add(key, value)
+	}
+}
+
+// WithToken authenticates every request with a personal API token.
+func WithToken(token string) Option {
+	return WithHeader("Authorization", "Bearer "+token)
+}
+
+// WithOrganisation sends every request to one organisation's books. It is
+// required for users who belong to more than one organisation.
+func WithOrganisation(id string) Option {
+	return WithHeader("X-Organisation-ID", id)
+}
+
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: http.DefaultClient,
+		header:     make(http.Header),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Error is returned for any non-2xx response.
+type Error struct {
+	StatusCode int
+	Body       ErrorResponse
+}
+
+func (e *Error) Error() string {
+	if e.Body.Error.Code != "" {
+		return fmt.Sprintf("go-figure api: %d %s: %s", e.StatusCode, e.Body.Error.Code, e.Body.Error.Message)
+	}
+	return fmt.Sprintf("go-figure api: %d %s", e.StatusCode, http.StatusText(e.StatusCode))
+}
+
+// File is a file to upload, such as a receipt.
+type File struct {
+	Name string
+	Data []byte
+}
+
+// formFile is the body of an upload: file in the multipart/form-data field
+// named field.
+type formFile struct {
+	field string
+	file  File
+}
+
+// encode renders the form with a boundary derived from the content, so
+// retrying an upload under the same Idempotency-Key sends identical bytes.
+func (f formFile) encode() ([]byte, string, error) {
+	sum := sha256.Sum256(f.file.Data)
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	if err := mw.SetBoundary("gofigure-" + hex.EncodeToString(sum[:16])); err != nil {
+		return nil, "", err
+	}
+	part, err := mw.CreateFormFile(f.field, f.file.Name)
+	if err != nil {
+		return nil, "", err
+	}
+	if _, err := part.Write(f.file.Data); err != nil {
+		return nil, "", err
+	}
+	if err := mw.Close(); err != nil {
+		return nil, "", err
+	}
+	return buf.Bytes(), mw.FormDataContentType(), nil
+}
+
+type idempotencyKeyCtx struct{}
+
+// WithIdempotencyKey makes requests issued with the returned context carry an
+// Idempotency-Key header, so retrying them after a timeout cannot apply the
+// change twice.
+func WithIdempotencyKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, idempotencyKeyCtx{}, key)
+}
+
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body any, out any) error {
+	u := c.baseURL + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+
+	var reqBody io.Reader
+	var contentType string
+	switch b := body.(type) {
+	case nil:
+	case formFile:
+		encoded, ct, err := b.encode()
+		if err != nil {
+			return fmt.Errorf("encode request: %w", err)
+		}
+		reqBody, contentType = bytes.NewReader(encoded), ct
+	default:
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("encode request: %w", err)
+		}
+		reqBody, contentType = bytes.NewReader(encoded), "application/json"
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u, reqBody)
+	if err != nil {
+		return err
+	}
+	for k, vs := range c.header {
+		for _, v := range vs {
+			req.Header.Add(k, v)
+		}
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if key, ok := ctx.Value(idempotencyKeyCtx{}).(string); ok && key != "" {
+		req.Header.Set("Idempotency-Key", key)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		apiErr := &Error{StatusCode: resp.StatusCode}
+		_ = json.NewDecoder(resp.Body).Decode(&apiErr.Body)
+		return apiErr
+	}
+
+	switch o := out.(type) {
+	case nil:
+		return nil
+	case *string:
+		b, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return err
+		}
+		*o = string(b)
+		return nil
+	case *[]byte:
+		b, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return err
+		}
+		*o = b
+		return nil
+	default:
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return fmt.Errorf("decode response: %w", err)
+		}
+		return nil
+	}
+}
@@ -2,33 +2,155 @@ package main
 
 import (
 	"context"
-	"log"
+	"fmt"
+	"log/slog"
 	"net/http"
 	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
 
+	"github.com/LBaronceli/go-figure/internal/config"
 	"github.com/LBaronceli/go-figure/internal/db"
 	"github.com/LBaronceli/go-figure/internal/httpserver"
+	"github.com/LBaronceli/go-figure/internal/ocr"
+	"github.com/LBaronceli/go-figure/internal/storage"
 )
 
 func main() {
-	ctx := context.Background()
+	cfg, err := config.Load()
+	if err != nil {
+		fatal("invalid configuration", err)
+	}
+	slog.SetDefault(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: cfg.Log.Level})))
+	slog.Info("effective configuration\n" + cfg.String())
 
-	pool, err := db.NewPool(ctx)
+	// SIGTERM is how Cloud Run and Kubernetes ask the process to stop
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	pool, err := db.NewPool(ctx, cfg.Database)
 	if err != nil {
-		log.Fatalf("db init: %v", err)
+		fatal("db init", err)
 	}
 	defer pool.Close()
 
-	srv := httpserver.NewServer(pool)
+	opts := []httpserver.Option{
+		httpserver.WithIdempotencyRetention(cfg.HTTP.IdempotencyKeyRetention),
+		httpserver.WithSessionTTL(cfg.Auth.SessionTTL),
+	}
+	if cfg.HTTP.ValidateRequests {
+		opts = append(opts, httpserver.WithRequestValidation())
+	}
+	if !cfg.Auth.SessionCookieSecure {
+		opts = append(opts, httpserver.WithInsecureCookies())
+	}
 
-	handler := srv.Routes()
+	store, err := newStore(cfg.Storage)
+	if err != nil {
+		fatal("storage init", err)
+	}
+	opts = append(opts, httpserver.WithStorage(store))
+	engine := newOCREngine(cfg.OCR)
+	if engine != nil {
+		opts = append(opts, httpserver.WithOCR(engine))
+	}
+
+	srv := httpserver.NewServer(pool, opts...)
 
-	addr := ":8080"
-	if v := os.Getenv("HTTP_ADDR"); v != "" {
-		addr = v
+	httpSrv := &http.Server{
+		Addr:              cfg.HTTP.Addr,
+		Handler:           srv.Routes(),
+		ReadHeaderTimeout: cfg.HTTP.ReadHeaderTimeout,
+		ReadTimeout:       cfg.HTTP.ReadTimeout,
+		WriteTimeout:      cfg.HTTP.WriteTimeout,
+		IdleTimeout:       cfg.HTTP.IdleTimeout,
+	}
+
+	// background workers stop with ctx and are waited for before the pool
+	// closes under them
+	var workers sync.WaitGroup
+	runWorker := func(run func(ctx context.Context)) {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			run(ctx)
+		}()
+	}
+	runWorker(func(ctx context.Context) { srv.RunIdempotencyJanitor(ctx, cfg.Jobs.IdempotencyJanitorInterval) })
+	runWorker(func(ctx context.Context) { srv.RunSessionJanitor(ctx, cfg.Jobs.SessionJanitorInterval) })
+	runWorker(func(ctx context.Context) { srv.RunAttachmentSweeper(ctx, cfg.Jobs.AttachmentSweepInterval) })
+	if engine != nil {
+		for range cfg.Jobs.ReceiptScanWorkers {
+			runWorker(func(ctx context.Context) { srv.RunReceiptScanner(ctx, cfg.Jobs.ReceiptScanInterval) })
+		}
 	}
 
-	log.Printf("api listening on %s", addr)
-	log.Fatal(http.ListenAndServe(addr, handler))
+	serveErr := make(chan error, 1)
+	go func() {
+		slog.Info("api listening", "addr", cfg.HTTP.Addr)
+		serveErr <- httpSrv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serveErr:
+		fatal("http server", err)
+	case <-ctx.Done():
+	}
+	// a second signal kills the process without waiting
+	stop()
+
+	slog.Info("shutting down: failing readiness, then draining requests",
+		"delay", cfg.HTTP.ShutdownDelay, "timeout", cfg.HTTP.ShutdownTimeout)
+	srv.Drain()
+	time.Sleep(cfg.HTTP.ShutdownDelay)
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.HTTP.ShutdownTimeout)
+	defer cancel()
+	if err := httpSrv.Shutdown(shutdownCtx); err != nil {
+		slog.Warn("http shutdown timed out; closing remaining connections", "err", err)
+		_ = httpSrv.Close()
+	}
+	workers.Wait()
+	slog.Info("shutdown complete")
+}
+
+// fatal logs err at error level, which no LOG_LEVEL hides, and exits.
+func fatal(msg string, err error) {
+	slog.Error(msg, "err", err)
+	os.Exit(1)
+}
+
+// newStore opens the attachment store cfg names: a local directory or an
+// S3-compatible bucket.
+func newStore(cfg config.Storage) (storage.Store, error) {
+	switch cfg.Backend {
+	case "local":
+		return storage.NewLocal(cfg.Dir)
+	case "s3":
+		return storage.NewS3(storage.S3Config{
+			Endpoint:        cfg.S3Endpoint,
+			Region:          cfg.S3Region,
+			Bucket:          cfg.S3Bucket,
+			AccessKeyID:     cfg.S3AccessKeyID,
+			SecretAccessKey: cfg.S3SecretAccessKey,
+			PathStyle:       cfg.S3PathStyle,
+		})
+	default:
+		return nil, fmt.Errorf("unknown storage backend %q", cfg.Backend)
+	}
 }
 
+// newOCREngine returns the engine that scans uploaded receipts, or nil when
+// cfg names none and receipts stay queued.
+func newOCREngine(cfg config.OCR) ocr.Engine {
+	switch cfg.Engine {
+	case "tesseract":
+		return &ocr.Tesseract{Path: cfg.TesseractPath, Language: cfg.TesseractLang}
+	case "http":
+		return &ocr.Remote{URL: cfg.URL}
+	default:
+		return nil
+	}
+}
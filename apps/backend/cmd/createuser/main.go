@@ -0,0 +1,101 @@
+// Command createuser bootstraps a user account, typically the first admin,
+// since creating users over the API already requires an admin.
+//
+//	echo "$PASSWORD" | go run ./cmd/createuser -email me@example.com -admin -org "Household"
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/LBaronceli/go-figure/internal/auth"
+	"github.com/LBaronceli/go-figure/internal/config"
+	"github.com/LBaronceli/go-figure/internal/db"
+	sqlc "github.com/LBaronceli/go-figure/internal/db/sqlc"
+)
+
+func main() {
+	email := flag.String("email", "", "email address to log in with")
+	admin := flag.Bool("admin", false, "grant the admin scope")
+	org := flag.String("org", "", "also create an organisation with this name, owned by the user")
+	flag.Parse()
+
+	e := strings.ToLower(strings.TrimSpace(*email))
+	if e == "" {
+		log.Fatal("-email is required")
+	}
+
+	// read the password from stdin so it stays out of shell history and ps
+	fmt.Fprint(os.Stderr, "password: ")
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && line == "" {
+		log.Fatalf("read password: %v", err)
+	}
+	password := strings.TrimRight(line, "\r\n")
+	if len(password) < 12 {
+		log.Fatal("password must be at least 12 characters")
+	}
+
+	hash, err := auth.HashPassword(password)
+	if err != nil {
+		log.Fatalf("hash password: %v", err)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatal(err)
+	}
+	ctx := context.Background()
+	pool, err := db.NewPool(ctx, cfg.Database)
+	if err != nil {
+		log.Fatalf("db init: %v", err)
+	}
+	defer pool.Close()
+
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		log.Fatalf("begin: %v", err)
+	}
+	defer tx.Rollback(ctx)
+	q := sqlc.New(tx)
+
+	user, err := q.CreateUser(ctx, sqlc.CreateUserParams{
+		Email:        e,
+		PasswordHash: hash,
+		IsAdmin:      *admin,
+	})
+	if err != nil {
+		log.Fatalf("create user: %v", err)
+	}
+	fmt.Fprintf(os.Stderr, "\ncreated user %s (%s)\n", user.Email, uuid.UUID(user.ID.Bytes))
+
+	if name := strings.TrimSpace(*org); name != "" {
+		o, err := q.CreateOrganisation(ctx, sqlc.CreateOrganisationParams{
+			Name:                 name,
+			Timezone:             "UTC",
+			FiscalYearStartMonth: 1,
+		})
+		if err != nil {
+			log.Fatalf("create organisation: %v", err)
+		}
+		if _, err := q.CreateMembership(ctx, sqlc.CreateMembershipParams{
+			OrganisationID: o.ID,
+			UserID:         user.ID,
+			Role:           string(auth.RoleOwner),
+		}); err != nil {
+			log.Fatalf("create membership: %v", err)
+		}
+		fmt.Fprintf(os.Stderr, "created organisation %s (%s)\n", o.Name, uuid.UUID(o.ID.Bytes))
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		log.Fatalf("commit: %v", err)
+	}
+}
@@ -0,0 +1,56 @@
+// Command ocrd serves tesseract over HTTP for the API's receipt scanner
+// (OCR_ENGINE=http), so the API image stays distroless. It reads OCRD_ADDR
+// (default :8090), TESSERACT_PATH and TESSERACT_LANG.
+package main
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/LBaronceli/go-figure/internal/ocr"
+)
+
+func main() {
+	addr := os.Getenv("OCRD_ADDR")
+	if addr == "" {
+		addr = ":8090"
+	}
+	engine := &ocr.Tesseract{Path: os.Getenv("TESSERACT_PATH"), Language: os.Getenv("TESSERACT_LANG")}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           ocr.Handler(engine),
+		ReadHeaderTimeout: 5 * time.Second,
+		ReadTimeout:       time.Minute,
+		// a large photo can keep tesseract busy for a while
+		WriteTimeout: 3 * time.Minute,
+	}
+	serveErr := make(chan error, 1)
+	go func() {
+		slog.Info("ocrd listening", "addr", addr)
+		serveErr <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serveErr:
+		slog.Error("http server", "err", err)
+		os.Exit(1)
+	case <-ctx.Done():
+	}
+	stop()
+
+	// let scans in flight finish
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		slog.Warn("http shutdown timed out", "err", err)
+	}
+}
@@ -0,0 +1,49 @@
+// Command openapigen writes the OpenAPI spec derived from the HTTP handlers
+// and the Go client generated from it. Run it through go generate:
+//
+//	go generate ./internal/httpserver
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+
+	"github.com/LBaronceli/go-figure/internal/httpserver"
+	"github.com/LBaronceli/go-figure/internal/openapi"
+)
+
+func main() {
+	specPath := flag.String("spec", "openapi.json", "where to write the spec")
+	clientPath := flag.String("client", "", "where to write the generated client (skipped when empty)")
+	clientPkg := flag.String("client-package", "client", "package name of the generated client")
+	flag.Parse()
+
+	doc := httpserver.Spec()
+
+	spec, err := doc.Marshal()
+	if err != nil {
+		log.Fatalf("marshal spec: %v", err)
+	}
+	if err := os.WriteFile(*specPath, spec, 0o644); err != nil {
+		log.Fatalf("write spec: %v", err)
+	}
+
+	if *clientPath == "" {
+		return
+	}
+
+	// generate from the marshalled form so the client sees exactly what
+	// clients of the published spec see
+	parsed, err := openapi.Parse(spec)
+	if err != nil {
+		log.Fatalf("parse spec: %v", err)
+	}
+	src, err := openapi.GenerateClient(parsed, *clientPkg)
+	if err != nil {
+		log.Fatalf("generate client: %v", err)
+	}
+	if err := os.WriteFile(*clientPath, src, 0o644); err != nil {
+		log.Fatalf("write client: %v", err)
+	}
+}
@@ -0,0 +1,168 @@
+// Package audit defines the tamper-evident audit trail: every event is
+// hashed together with the hash of the event before it in the same
+// organisation, so editing, deleting or reordering any stored event breaks
+// every hash after it.
+package audit
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Actions recorded in the trail.
+const (
+	ActionCreate    = "create"
+	ActionUpdate    = "update"
+	ActionDelete    = "delete"
+	ActionArchive   = "archive"
+	ActionUnarchive = "unarchive"
+	ActionLock      = "lock"
+	ActionUnlock    = "unlock"
+	ActionMerge     = "merge"
+	ActionApprove   = "approve"
+	ActionPay       = "pay"
+	ActionVoid      = "void"
+)
+
+// Entities recorded in the trail.
+const (
+	EntityAccount     = "account"
+	EntityTransaction = "transaction"
+	EntityPeriodLock  = "period_lock"
+	EntityContact     = "contact"
+	EntityInvoice     = "invoice"
+	EntityBill        = "bill"
+	EntityAttachment  = "attachment"
+)
+
+// Entities lists every entity the trail records, for filters and docs.
+var Entities = []string{EntityAccount, EntityTransaction, EntityPeriodLock, EntityContact, EntityInvoice, EntityBill, EntityAttachment}
+
+// Event is one audited change. Before is empty for creations and After is
+// empty for deletions.
+type Event struct {
+	OrganisationID uuid.UUID
+	Seq            int64
+	ActorUserID    uuid.UUID
+	ActorEmail     string
+	ActorMethod    string
+	RequestID      string
+	Entity         string
+	EntityID       uuid.UUID
+	Action         string
+	Before         []byte
+	After          []byte
+	// CreatedAt is truncated to microseconds, the precision Postgres stores.
+	CreatedAt time.Time
+	PrevHash  []byte
+	Hash      []byte
+}
+
+// Canonical re-encodes a JSON document so that equal documents have equal
+// bytes: object keys sorted, no insignificant whitespace, numbers kept as
+// written. jsonb rewrites documents on the way in, so both the writer and the
+// verifier hash the canonical form rather than the bytes they were handed.
+func Canonical(doc []byte) ([]byte, error) {
+	if len(doc) == 0 {
+		return nil, nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(doc))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	return json.Marshal(v)
+}
+
+// Snapshot encodes v as canonical JSON for Before or After. A nil v yields
+// nil, meaning "no state".
+func Snapshot(v any) ([]byte, error) {
+	if v == nil {
+		return nil, nil
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return Canonical(b)
+}
+
+// ComputeHash returns SHA-256 over the previous hash and every field of e.
+// Fields are length-prefixed so no two events share an encoding.
+func ComputeHash(prev []byte, e Event) ([]byte, error) {
+	before, err := Canonical(e.Before)
+	if err != nil {
+		return nil, err
+	}
+	after, err := Canonical(e.After)
+	if err != nil {
+		return nil, err
+	}
+
+	h := sha256.New()
+	field := func(b []byte) {
+		var n [8]byte
+		binary.BigEndian.PutUint64(n[:], uint64(len(b)))
+		h.Write(n[:])
+		h.Write(b)
+	}
+	num := func(v int64) {
+		var n [8]byte
+		binary.BigEndian.PutUint64(n[:], uint64(v))
+		field(n[:])
+	}
+
+	field(prev)
+	field(e.OrganisationID[:])
+	num(e.Seq)
+	field(e.ActorUserID[:])
+	field([]byte(e.ActorEmail))
+	field([]byte(e.ActorMethod))
+	field([]byte(e.RequestID))
+	field([]byte(e.Entity))
+	field(e.EntityID[:])
+	field([]byte(e.Action))
+	field(before)
+	field(after)
+	num(e.CreatedAt.UnixMicro())
+	return h.Sum(nil), nil
+}
+
+// Verification is the result of checking a chain.
+type Verification struct {
+	Checked int
+	// BrokenAt is the sequence number of the first event whose hash or link
+	// does not match, or 0 when the chain is intact.
+	BrokenAt int64
+}
+
+func (v Verification) OK() bool {
+	return v.BrokenAt == 0
+}
+
+// Verify walks events in sequence order and reports the first one that does
+// not chain onto its predecessor. A gap in the sequence counts as a break:
+// it means an event was deleted.
+func Verify(events []Event) (Verification, error) {
+	var v Verification
+	var prev []byte
+	for i, e := range events {
+		want, err := ComputeHash(prev, e)
+		if err != nil {
+			return v, err
+		}
+		if e.Seq != int64(i+1) || !bytes.Equal(e.PrevHash, prev) || !bytes.Equal(e.Hash, want) {
+			v.BrokenAt = e.Seq
+			return v, nil
+		}
+		prev = e.Hash
+		v.Checked++
+	}
+	return v, nil
+}
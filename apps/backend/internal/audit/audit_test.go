@@ -0,0 +1,81 @@
+package audit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func chain(t *testing.T, n int) []Event {
+	t.Helper()
+	org := uuid.New()
+	var events []Event
+	var prev []byte
+	for i := 1; i <= n; i++ {
+		after, err := Snapshot(map[string]any{"name": "Cash", "n": i})
+		require.NoError(t, err)
+		e := Event{
+			OrganisationID: org,
+			Seq:            int64(i),
+			ActorUserID:    uuid.New(),
+			ActorEmail:     "a@example.com",
+			ActorMethod:    "session",
+			RequestID:      "req-1",
+			Entity:         EntityAccount,
+			EntityID:       uuid.New(),
+			Action:         ActionCreate,
+			After:          after,
+			CreatedAt:      time.Now().Truncate(time.Microsecond),
+			PrevHash:       prev,
+		}
+		e.Hash, err = ComputeHash(prev, e)
+		require.NoError(t, err)
+		prev = e.Hash
+		events = append(events, e)
+	}
+	return events
+}
+
+func TestVerifyAcceptsIntactChain(t *testing.T) {
+	v, err := Verify(chain(t, 5))
+	require.NoError(t, err)
+	require.True(t, v.OK())
+	require.Equal(t, 5, v.Checked)
+}
+
+func TestVerifyDetectsTampering(t *testing.T) {
+	t.Run("edited payload", func(t *testing.T) {
+		events := chain(t, 5)
+		events[2].After = []byte(`{"name":"Petty cash","n":3}`)
+		v, err := Verify(events)
+		require.NoError(t, err)
+		require.Equal(t, int64(3), v.BrokenAt)
+	})
+
+	t.Run("deleted event", func(t *testing.T) {
+		events := chain(t, 5)
+		events = append(events[:1], events[2:]...)
+		v, err := Verify(events)
+		require.NoError(t, err)
+		require.Equal(t, int64(3), v.BrokenAt)
+	})
+
+	t.Run("rewritten actor", func(t *testing.T) {
+		events := chain(t, 5)
+		events[4].ActorEmail = "someone-else@example.com"
+		v, err := Verify(events)
+		require.NoError(t, err)
+		require.Equal(t, int64(5), v.BrokenAt)
+	})
+}
+
+func TestCanonicalIgnoresJSONBFormatting(t *testing.T) {
+	// jsonb hands documents back with its own spacing and key order
+	a, err := Canonical([]byte(`{"b": 1.50, "a": [1, 2]}`))
+	require.NoError(t, err)
+	b, err := Canonical([]byte(`{"a":[1,2],"b":1.50}`))
+	require.NoError(t, err)
+	require.Equal(t, string(a), string(b))
+}
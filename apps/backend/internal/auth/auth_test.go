@@ -0,0 +1,70 @@
+package auth
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPasswordRoundTrip(t *testing.T) {
+	hash, err := HashPassword("correct horse battery staple")
+	require.NoError(t, err)
+	require.Contains(t, hash, "$argon2id$v=19$m=65536,t=3,p=2$")
+
+	ok, err := VerifyPassword("correct horse battery staple", hash)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	ok, err = VerifyPassword("Tr0ub4dor&3", hash)
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	_, err = VerifyPassword("x", "$bcrypt$nope")
+	require.ErrorIs(t, err, ErrInvalidHash)
+}
+
+func TestPasswordHashingIsBounded(t *testing.T) {
+	for range maxConcurrentHashes {
+		hashSlots <- struct{}{}
+	}
+	_, err := VerifyPassword("correct horse battery staple", dummyHash)
+	require.ErrorIs(t, err, ErrBusy)
+
+	<-hashSlots
+	_, err = VerifyPassword("correct horse battery staple", dummyHash)
+	require.NoError(t, err)
+	for range maxConcurrentHashes - 1 {
+		<-hashSlots
+	}
+}
+
+func TestTokenHashIsStable(t *testing.T) {
+	plain, hash, err := NewToken(APITokenPrefix)
+	require.NoError(t, err)
+	require.True(t, IsAPIToken(plain))
+	require.Equal(t, hash, HashToken(plain))
+	require.Len(t, DisplayPrefix(plain), DisplayPrefixLen)
+}
+
+func TestScopesImplyLowerScopes(t *testing.T) {
+	admin := Principal{Scopes: []Scope{ScopeAdmin}}
+	require.True(t, admin.Has(ScopeRead))
+	require.True(t, admin.Has(ScopeWrite))
+
+	reader := Principal{Scopes: []Scope{ScopeRead}}
+	require.True(t, reader.Has(ScopeRead))
+	require.False(t, reader.Has(ScopeWrite))
+	require.False(t, reader.Has(ScopeAdmin))
+
+	require.False(t, Principal{}.Has(ScopeRead))
+}
+
+func TestViewersCannotWrite(t *testing.T) {
+	require.True(t, RoleOwner.CanWrite())
+	require.True(t, RoleMember.CanWrite())
+	require.False(t, RoleViewer.CanWrite())
+
+	require.True(t, RoleOwner.CanManageMembers())
+	require.False(t, RoleMember.CanManageMembers())
+	require.False(t, Role("auditor").IsValid())
+}
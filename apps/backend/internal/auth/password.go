@@ -0,0 +1,123 @@
+// Package auth implements password hashing, opaque bearer tokens and the
+// principal that authenticated requests carry through their context.
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/argon2"
+)
+
+// Argon2id parameters, following the OWASP baseline (m=64 MiB, t=3, p=2).
+// They are encoded in every hash so they can be raised without breaking
+// existing passwords.
+const (
+	argonMemory  = 64 * 1024
+	argonTime    = 3
+	argonThreads = 2
+	argonKeyLen  = 32
+	argonSaltLen = 16
+)
+
+// Each hash takes argonMemory, so at most maxConcurrentHashes run at once and
+// a burst of logins queues for up to hashQueueWait before being turned away.
+const (
+	maxConcurrentHashes = 4
+	hashQueueWait       = time.Second
+)
+
+var (
+	ErrInvalidHash = errors.New("auth: invalid password hash")
+	// ErrBusy means every hashing slot stayed taken for hashQueueWait; the
+	// caller should ask the client to retry shortly.
+	ErrBusy = errors.New("auth: too many password hashes in progress")
+)
+
+var hashSlots = make(chan struct{}, maxConcurrentHashes)
+
+// argonKey runs argon2id in one of the hashing slots.
+func argonKey(password, salt []byte, iterations, memory uint32, threads uint8, keyLen uint32) ([]byte, error) {
+	select {
+	case hashSlots <- struct{}{}:
+	default:
+		wait := time.NewTimer(hashQueueWait)
+		defer wait.Stop()
+		select {
+		case hashSlots <- struct{}{}:
+		case <-wait.C:
+			return nil, ErrBusy
+		}
+	}
+	defer func() { <-hashSlots }()
+	return argon2.IDKey(password, salt, iterations, memory, threads, keyLen), nil
+}
+
+// HashPassword returns an argon2id hash in the PHC string format:
+// $argon2id$v=19$m=65536,t=3,p=2$<salt>$<hash>.
+func HashPassword(password string) (string, error) {
+	salt := make([]byte, argonSaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	key, err := argonKey([]byte(password), salt, argonTime, argonMemory, argonThreads, argonKeyLen)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, argonMemory, argonTime, argonThreads,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+// VerifyPassword reports whether password matches an encoded hash.
+func VerifyPassword(password, encoded string) (bool, error) {
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return false, ErrInvalidHash
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return false, ErrInvalidHash
+	}
+
+	var memory, iterations uint32
+	var threads uint8
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &memory, &iterations, &threads); err != nil {
+		return false, ErrInvalidHash
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return false, ErrInvalidHash
+	}
+	want, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return false, ErrInvalidHash
+	}
+
+	got, err := argonKey([]byte(password), salt, iterations, memory, threads, uint32(len(want)))
+	if err != nil {
+		return false, err
+	}
+	return subtle.ConstantTimeCompare(got, want) == 1, nil
+}
+
+// dummyHash is verified against when a login names an unknown user, so the
+// response time does not reveal which emails exist.
+var dummyHash, _ = HashPassword("go-figure-timing-equaliser")
+
+// BurnPasswordCheck spends the same time as a real VerifyPassword, and
+// returns ErrBusy when it would.
+func BurnPasswordCheck(password string) error {
+	_, err := VerifyPassword(password, dummyHash)
+	return err
+}
@@ -0,0 +1,80 @@
+package auth
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// Scope is a permission granted to an API token or session.
+type Scope string
+
+const (
+	ScopeRead  Scope = "read"
+	ScopeWrite Scope = "write"
+	ScopeAdmin Scope = "admin"
+)
+
+func (s Scope) IsValid() bool {
+	switch s {
+	case ScopeRead, ScopeWrite, ScopeAdmin:
+		return true
+	}
+	return false
+}
+
+// rank orders scopes so that a higher scope implies the lower ones: admin can
+// write, and anyone who can write can read.
+func (s Scope) rank() int {
+	switch s {
+	case ScopeRead:
+		return 1
+	case ScopeWrite:
+		return 2
+	case ScopeAdmin:
+		return 3
+	}
+	return 0
+}
+
+// Method says how a principal authenticated.
+type Method string
+
+const (
+	MethodAPIToken Method = "api_token"
+	MethodSession  Method = "session"
+)
+
+// Principal is the authenticated caller of a request.
+type Principal struct {
+	UserID uuid.UUID
+	Email  string
+	Method Method
+	// CredentialID is the API token or session ID.
+	CredentialID uuid.UUID
+	Scopes       []Scope
+}
+
+// Has reports whether the principal holds scope, directly or implied by a
+// higher one.
+func (p Principal) Has(scope Scope) bool {
+	for _, s := range p.Scopes {
+		if s.rank() >= scope.rank() {
+			return true
+		}
+	}
+	return false
+}
+
+type principalKey struct{}
+
+func WithPrincipal(ctx context.Context, p Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// PrincipalFrom returns the principal stored by the authentication
+// middleware.
+func PrincipalFrom(ctx context.Context) (Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(Principal)
+	return p, ok
+}
@@ -0,0 +1,36 @@
+package auth
+
+// Role is a user's standing within one organisation. Scopes say what a
+// credential may do at all; the role says what its user may do in a given
+// organisation, and a request needs both.
+type Role string
+
+const (
+	RoleOwner  Role = "owner"
+	RoleMember Role = "member"
+	RoleViewer Role = "viewer"
+)
+
+func (r Role) IsValid() bool {
+	switch r {
+	case RoleOwner, RoleMember, RoleViewer:
+		return true
+	}
+	return false
+}
+
+// CanWrite reports whether the role may change the organisation's books.
+func (r Role) CanWrite() bool {
+	return r == RoleOwner || r == RoleMember
+}
+
+// CanManageMembers reports whether the role may add, remove or change members.
+func (r Role) CanManageMembers() bool {
+	return r == RoleOwner
+}
+
+// CanLockPeriods reports whether the role may lock accounting periods and
+// close the year.
+func (r Role) CanLockPeriods() bool {
+	return r == RoleOwner
+}
@@ -0,0 +1,53 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"strings"
+)
+
+// Token prefixes make leaked secrets easy to recognise in logs and scanners.
+const (
+	APITokenPrefix = "gf_"
+	SessionPrefix  = "gfs_"
+
+	tokenBytes = 32
+
+	// DisplayPrefixLen is how much of a token is stored in clear so users
+	// can tell their tokens apart.
+	DisplayPrefixLen = 10
+)
+
+// NewToken returns a random opaque token and the hash to store for it. The
+// plaintext is shown to the user once and never persisted.
+//
+// Tokens carry 256 bits of entropy, so a plain SHA-256 is enough to store
+// them; the slow argon2id hash is only needed for human-chosen passwords.
+func NewToken(prefix string) (plaintext string, hash []byte, err error) {
+	b := make([]byte, tokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", nil, err
+	}
+	plaintext = prefix + base64.RawURLEncoding.EncodeToString(b)
+	return plaintext, HashToken(plaintext), nil
+}
+
+// HashToken returns the lookup hash of a token.
+func HashToken(plaintext string) []byte {
+	sum := sha256.Sum256([]byte(plaintext))
+	return sum[:]
+}
+
+// DisplayPrefix returns the part of a token that is safe to store and show.
+func DisplayPrefix(plaintext string) string {
+	if len(plaintext) <= DisplayPrefixLen {
+		return plaintext
+	}
+	return plaintext[:DisplayPrefixLen]
+}
+
+// IsAPIToken reports whether a bearer credential looks like an API token.
+func IsAPIToken(s string) bool {
+	return strings.HasPrefix(s, APITokenPrefix)
+}
@@ -0,0 +1,369 @@
+// Package config loads the API's settings from environment variables and an
+// optional file, checks them once at startup and prints them with secrets
+// redacted.
+//
+// Every setting has one name, used both as the environment variable and as
+// the key in the file. The file, named by CONFIG_FILE, holds KEY=value lines;
+// blank lines and lines starting with # are skipped and values may be
+// quoted. The environment wins over the file and the file over the defaults.
+// An empty value counts as unset.
+//
+// Settings are declared by struct tags:
+//   - env names the setting; default is its value when unset
+//   - required rejects an empty value
+//   - oneof=a|b limits a string to the listed values
+//   - min sets the smallest allowed integer
+//   - durations must be positive unless tagged zero:"allowed"
+//   - secret:"true" redacts the value when printed, and secret:"url" only
+//     the passwords and keys in it
+package config
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"os"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FileEnv names the environment variable holding the optional config file.
+const FileEnv = "CONFIG_FILE"
+
+type Config struct {
+	HTTP     HTTP
+	Database Database
+	Log      Log
+	Auth     Auth
+	Storage  Storage
+	OCR      OCR
+	Jobs     Jobs
+}
+
+type HTTP struct {
+	Addr              string        `env:"HTTP_ADDR" default:":8080"`
+	ReadHeaderTimeout time.Duration `env:"HTTP_READ_HEADER_TIMEOUT" default:"5s"`
+	// ReadTimeout allows for an 8 MiB attachment over a slow link.
+	ReadTimeout time.Duration `env:"HTTP_READ_TIMEOUT" default:"60s"`
+	// WriteTimeout allows for the slowest reports and invoice PDFs.
+	WriteTimeout time.Duration `env:"HTTP_WRITE_TIMEOUT" default:"60s"`
+	IdleTimeout  time.Duration `env:"HTTP_IDLE_TIMEOUT" default:"120s"`
+	// ShutdownDelay keeps serving with /readyz failing long enough for load
+	// balancers to stop routing new requests here.
+	ShutdownDelay time.Duration `env:"SHUTDOWN_DELAY" default:"5s" zero:"allowed"`
+	// ShutdownTimeout bounds how long in-flight requests get to finish;
+	// Kubernetes kills the pod 30s after SIGTERM by default.
+	ShutdownTimeout         time.Duration `env:"SHUTDOWN_TIMEOUT" default:"20s"`
+	ValidateRequests        bool          `env:"OPENAPI_VALIDATE_REQUESTS" default:"false"`
+	IdempotencyKeyRetention time.Duration `env:"IDEMPOTENCY_KEY_RETENTION" default:"720h"`
+}
+
+type Database struct {
+	URL             string        `env:"DATABASE_URL" required:"true" secret:"url"`
+	MinConns        int32         `env:"DB_MIN_CONNS" default:"1" min:"0"`
+	MaxConns        int32         `env:"DB_MAX_CONNS" default:"5" min:"1"`
+	MaxConnLifetime time.Duration `env:"DB_MAX_CONN_LIFETIME" default:"30m"`
+	MaxConnIdleTime time.Duration `env:"DB_MAX_CONN_IDLE_TIME" default:"30m"`
+}
+
+type Log struct {
+	Level slog.Level `env:"LOG_LEVEL" default:"info"`
+}
+
+type Auth struct {
+	SessionTTL time.Duration `env:"SESSION_TTL" default:"336h"`
+	// SessionCookieSecure is only turned off for plain HTTP in local
+	// development.
+	SessionCookieSecure bool `env:"SESSION_COOKIE_SECURE" default:"true"`
+}
+
+type Storage struct {
+	Backend           string `env:"STORAGE_BACKEND" default:"local" oneof:"local|s3"`
+	Dir               string `env:"STORAGE_DIR" default:"data/attachments"`
+	S3Endpoint        string `env:"S3_ENDPOINT"`
+	S3Region          string `env:"S3_REGION" default:"us-east-1"`
+	S3Bucket          string `env:"S3_BUCKET"`
+	S3AccessKeyID     string `env:"S3_ACCESS_KEY_ID"`
+	S3SecretAccessKey string `env:"S3_SECRET_ACCESS_KEY" secret:"true"`
+	S3PathStyle       bool   `env:"S3_PATH_STYLE" default:"false"`
+}
+
+type OCR struct {
+	// Engine scans receipts; with none they stay queued. http calls an ocrd
+	// at URL, which is how the distroless image scans.
+	Engine        string `env:"OCR_ENGINE" default:"none" oneof:"none|tesseract|http"`
+	URL           string `env:"OCR_URL"`
+	TesseractPath string `env:"TESSERACT_PATH"`
+	TesseractLang string `env:"TESSERACT_LANG"`
+}
+
+type Jobs struct {
+	IdempotencyJanitorInterval time.Duration `env:"IDEMPOTENCY_JANITOR_INTERVAL" default:"1h"`
+	SessionJanitorInterval     time.Duration `env:"SESSION_JANITOR_INTERVAL" default:"1h"`
+	// ReceiptScanInterval is how often an idle scanner checks the queue.
+	ReceiptScanInterval time.Duration `env:"RECEIPT_SCAN_INTERVAL" default:"5s"`
+	ReceiptScanWorkers  int           `env:"RECEIPT_SCAN_WORKERS" default:"1" min:"1"`
+	// AttachmentSweepInterval is how often blobs of deleted attachments are
+	// removed from storage.
+	AttachmentSweepInterval time.Duration `env:"ATTACHMENT_SWEEP_INTERVAL" default:"1m"`
+}
+
+// Load reads the configuration from the environment and the file named by
+// CONFIG_FILE. The error lists every invalid setting, not just the first.
+func Load() (*Config, error) {
+	return load(os.LookupEnv)
+}
+
+func load(lookup func(string) (string, bool)) (*Config, error) {
+	file := map[string]string{}
+	if path, ok := lookup(FileEnv); ok && path != "" {
+		var err error
+		if file, err = readFile(path); err != nil {
+			return nil, err
+		}
+	}
+
+	var c Config
+	var errs []error
+	known := map[string]bool{}
+	for _, s := range settings(&c) {
+		known[s.key] = true
+		v, ok := lookup(s.key)
+		if !ok || v == "" {
+			v = file[s.key]
+		}
+		if v == "" {
+			v = s.def
+		}
+		if err := s.set(v); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", s.key, err))
+		}
+	}
+	var unknown []string
+	for key := range file {
+		if !known[key] {
+			unknown = append(unknown, key)
+		}
+	}
+	slices.Sort(unknown)
+	for _, key := range unknown {
+		errs = append(errs, fmt.Errorf("%s: unknown setting in %s", key, FileEnv))
+	}
+	if len(errs) == 0 {
+		errs = c.validate()
+	}
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("config: %w", errors.Join(errs...))
+	}
+	return &c, nil
+}
+
+// validate checks the rules that span settings.
+func (c *Config) validate() []error {
+	var errs []error
+	if c.Database.MinConns > c.Database.MaxConns {
+		errs = append(errs, fmt.Errorf("DB_MIN_CONNS: %d is more than DB_MAX_CONNS %d", c.Database.MinConns, c.Database.MaxConns))
+	}
+	if c.OCR.Engine == "http" && c.OCR.URL == "" {
+		errs = append(errs, errors.New("OCR_URL: required when OCR_ENGINE is http"))
+	}
+	if c.Storage.Backend == "s3" {
+		for _, s := range []struct{ key, value string }{
+			{"S3_ENDPOINT", c.Storage.S3Endpoint},
+			{"S3_BUCKET", c.Storage.S3Bucket},
+			{"S3_ACCESS_KEY_ID", c.Storage.S3AccessKeyID},
+			{"S3_SECRET_ACCESS_KEY", c.Storage.S3SecretAccessKey},
+		} {
+			if s.value == "" {
+				errs = append(errs, fmt.Errorf("%s: required when STORAGE_BACKEND is s3", s.key))
+			}
+		}
+	}
+	return errs
+}
+
+// String lists the effective settings as KEY=value, one per line in
+// declaration order, with secrets redacted.
+func (c *Config) String() string {
+	var b strings.Builder
+	for _, s := range settings(c) {
+		fmt.Fprintf(&b, "%s=%s\n", s.key, s.display())
+	}
+	return b.String()
+}
+
+// setting is one tagged field of Config.
+type setting struct {
+	key      string
+	def      string
+	required bool
+	oneof    []string
+	min      string
+	zero     bool
+	secret   string
+	v        reflect.Value
+}
+
+// settings returns every setting of c, addressable so they can be set.
+func settings(c *Config) []setting {
+	var out []setting
+	groups := reflect.ValueOf(c).Elem()
+	for i := range groups.NumField() {
+		group := groups.Field(i)
+		for j := range group.NumField() {
+			f := group.Type().Field(j)
+			key := f.Tag.Get("env")
+			if key == "" {
+				continue
+			}
+			s := setting{
+				key:      key,
+				def:      f.Tag.Get("default"),
+				required: f.Tag.Get("required") == "true",
+				min:      f.Tag.Get("min"),
+				zero:     f.Tag.Get("zero") == "allowed",
+				secret:   f.Tag.Get("secret"),
+				v:        group.Field(j),
+			}
+			if o := f.Tag.Get("oneof"); o != "" {
+				s.oneof = strings.Split(o, "|")
+			}
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+var levelType = reflect.TypeFor[slog.Level]()
+
+// set parses raw into the setting's field, checking its tags.
+func (s setting) set(raw string) error {
+	if raw == "" && s.required {
+		return errors.New("required")
+	}
+	switch {
+	case s.v.Type() == levelType:
+		var level slog.Level
+		if err := level.UnmarshalText([]byte(raw)); err != nil {
+			return fmt.Errorf("invalid value %q: use debug, info, warn or error", raw)
+		}
+		s.v.Set(reflect.ValueOf(level))
+	case s.v.Type() == reflect.TypeFor[time.Duration]():
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return fmt.Errorf("invalid duration %q: use a Go duration such as 30s or 1h", raw)
+		}
+		if d < 0 || (d == 0 && !s.zero) {
+			return fmt.Errorf("%s must be positive", d)
+		}
+		s.v.SetInt(int64(d))
+	case s.v.Kind() == reflect.String:
+		if len(s.oneof) > 0 && !slices.Contains(s.oneof, raw) {
+			return fmt.Errorf("invalid value %q: use %s", raw, strings.Join(s.oneof, " or "))
+		}
+		s.v.SetString(raw)
+	case s.v.Kind() == reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("invalid value %q: use true or false", raw)
+		}
+		s.v.SetBool(b)
+	case s.v.CanInt():
+		n, err := strconv.ParseInt(raw, 10, s.v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid number %q", raw)
+		}
+		if s.min != "" {
+			if lo, _ := strconv.ParseInt(s.min, 10, 64); n < lo {
+				return fmt.Errorf("%d is less than %d", n, lo)
+			}
+		}
+		s.v.SetInt(n)
+	default:
+		panic("config: unsupported field type " + s.v.Type().String())
+	}
+	return nil
+}
+
+// display formats the setting's value for printing.
+func (s setting) display() string {
+	switch v := s.v.Interface().(type) {
+	case string:
+		switch {
+		case v == "" || s.secret == "":
+			return v
+		case s.secret == "url":
+			// a URL keeps its host and user for debugging; a key=value
+			// connection string could carry the password anywhere
+			if u, err := url.Parse(v); err == nil && u.Host != "" {
+				return redactURL(u)
+			}
+		}
+		return "[redacted]"
+	case slog.Level:
+		return strings.ToLower(v.String())
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// secretURLParams are the connection URL query parameters that carry
+// secrets: libpq and pgx accept the password there as well as in the
+// userinfo, and pgx takes an inline client key in sslkey.
+var secretURLParams = []string{"password", "sslpassword", "sslkey"}
+
+// redactURL masks the password in u's userinfo and the values of its
+// secretURLParams.
+func redactURL(u *url.URL) string {
+	query := u.Query()
+	redacted := false
+	for key := range query {
+		if slices.Contains(secretURLParams, strings.ToLower(key)) {
+			query.Set(key, "xxxxx")
+			redacted = true
+		}
+	}
+	if redacted {
+		r := *u
+		r.RawQuery = query.Encode()
+		u = &r
+	}
+	return u.Redacted()
+}
+
+// readFile parses a KEY=value config file.
+func readFile(path string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("config: %w", err)
+	}
+	defer f.Close()
+
+	values := map[string]string{}
+	sc := bufio.NewScanner(f)
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("config: %s line %d: expected KEY=value", path, n)
+		}
+		value = strings.TrimSpace(value)
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+		values[key] = value
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("config: %s: %w", path, err)
+	}
+	return values, nil
+}
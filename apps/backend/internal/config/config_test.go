@@ -0,0 +1,145 @@
+package config
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func env(vars map[string]string) func(string) (string, bool) {
+	return func(key string) (string, bool) {
+		v, ok := vars[key]
+		return v, ok
+	}
+}
+
+func TestLoadDefaults(t *testing.T) {
+	c, err := load(env(map[string]string{"DATABASE_URL": "postgres://localhost/go-figure"}))
+	require.NoError(t, err)
+
+	require.Equal(t, ":8080", c.HTTP.Addr)
+	require.Equal(t, 5*time.Second, c.HTTP.ReadHeaderTimeout)
+	require.Equal(t, 720*time.Hour, c.HTTP.IdempotencyKeyRetention)
+	require.Equal(t, int32(1), c.Database.MinConns)
+	require.Equal(t, int32(5), c.Database.MaxConns)
+	require.Equal(t, slog.LevelInfo, c.Log.Level)
+	require.True(t, c.Auth.SessionCookieSecure)
+	require.Equal(t, "local", c.Storage.Backend)
+	require.Equal(t, "none", c.OCR.Engine)
+	require.Equal(t, 1, c.Jobs.ReceiptScanWorkers)
+	require.Equal(t, time.Minute, c.Jobs.AttachmentSweepInterval)
+}
+
+func TestLoadFileUnderEnvironment(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "api.env")
+	require.NoError(t, os.WriteFile(path, []byte(`
+# production
+DATABASE_URL="postgres://api:hunter2@db:5432/go-figure"
+DB_MAX_CONNS=20
+LOG_LEVEL=debug
+SHUTDOWN_DELAY=0s
+`), 0o600))
+
+	c, err := load(env(map[string]string{
+		FileEnv:        path,
+		"DB_MAX_CONNS": "30",
+		// empty counts as unset, so the file's value stands
+		"LOG_LEVEL": "",
+	}))
+	require.NoError(t, err)
+	require.Equal(t, "postgres://api:hunter2@db:5432/go-figure", c.Database.URL)
+	require.Equal(t, int32(30), c.Database.MaxConns)
+	require.Equal(t, slog.LevelDebug, c.Log.Level)
+	require.Zero(t, c.HTTP.ShutdownDelay)
+}
+
+func TestLoadReportsEveryError(t *testing.T) {
+	_, err := load(env(map[string]string{
+		"HTTP_READ_TIMEOUT":     "soon",
+		"SESSION_TTL":           "-1h",
+		"DB_MAX_CONNS":          "0",
+		"LOG_LEVEL":             "loud",
+		"STORAGE_BACKEND":       "ftp",
+		"SESSION_COOKIE_SECURE": "maybe",
+	}))
+	require.Error(t, err)
+	for _, want := range []string{
+		"DATABASE_URL: required",
+		`HTTP_READ_TIMEOUT: invalid duration "soon"`,
+		"SESSION_TTL: -1h0m0s must be positive",
+		"DB_MAX_CONNS: 0 is less than 1",
+		`LOG_LEVEL: invalid value "loud"`,
+		`STORAGE_BACKEND: invalid value "ftp": use local or s3`,
+		`SESSION_COOKIE_SECURE: invalid value "maybe"`,
+	} {
+		require.ErrorContains(t, err, want)
+	}
+}
+
+func TestLoadChecksAcrossSettings(t *testing.T) {
+	_, err := load(env(map[string]string{
+		"DATABASE_URL":    "postgres://localhost/go-figure",
+		"DB_MIN_CONNS":    "8",
+		"STORAGE_BACKEND": "s3",
+		"S3_ENDPOINT":     "http://minio:9000",
+		"S3_BUCKET":       "attachments",
+	}))
+	require.ErrorContains(t, err, "DB_MIN_CONNS: 8 is more than DB_MAX_CONNS 5")
+	require.ErrorContains(t, err, "S3_ACCESS_KEY_ID: required when STORAGE_BACKEND is s3")
+	require.ErrorContains(t, err, "S3_SECRET_ACCESS_KEY: required when STORAGE_BACKEND is s3")
+	require.NotContains(t, err.Error(), "S3_BUCKET")
+
+	_, err = load(env(map[string]string{
+		"DATABASE_URL": "postgres://localhost/go-figure",
+		"OCR_ENGINE":   "http",
+	}))
+	require.ErrorContains(t, err, "OCR_URL: required when OCR_ENGINE is http")
+}
+
+func TestLoadRejectsUnknownFileKeys(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "api.env")
+	require.NoError(t, os.WriteFile(path, []byte("DATABASE_URL=postgres://localhost/db\nDB_MAX_CONN=10\n"), 0o600))
+	_, err := load(env(map[string]string{FileEnv: path}))
+	require.ErrorContains(t, err, "DB_MAX_CONN: unknown setting in CONFIG_FILE")
+
+	require.NoError(t, os.WriteFile(path, []byte("just words\n"), 0o600))
+	_, err = load(env(map[string]string{FileEnv: path}))
+	require.ErrorContains(t, err, "line 1: expected KEY=value")
+}
+
+func TestStringRedactsSecrets(t *testing.T) {
+	c, err := load(env(map[string]string{
+		"DATABASE_URL":         "postgres://api:hunter2@db:5432/go-figure",
+		"STORAGE_BACKEND":      "s3",
+		"S3_ENDPOINT":          "http://minio:9000",
+		"S3_BUCKET":            "attachments",
+		"S3_ACCESS_KEY_ID":     "minioadmin",
+		"S3_SECRET_ACCESS_KEY": "s3cret",
+	}))
+	require.NoError(t, err)
+
+	out := c.String()
+	require.NotContains(t, out, "hunter2")
+	require.NotContains(t, out, "s3cret")
+	require.Contains(t, out, "DATABASE_URL=postgres://api:xxxxx@db:5432/go-figure\n")
+	require.Contains(t, out, "S3_SECRET_ACCESS_KEY=[redacted]\n")
+	require.Contains(t, out, "S3_ACCESS_KEY_ID=minioadmin\n")
+	require.Contains(t, out, "LOG_LEVEL=info\n")
+	require.Contains(t, out, "HTTP_READ_TIMEOUT=1m0s\n")
+
+	// passwords and keys given as query parameters are masked too
+	c.Database.URL = "postgres://api@db:5432/go-figure?sslmode=verify-full&password=hunter2&sslpassword=s3cret&SSLKey=-----BEGIN"
+	out = c.String()
+	require.NotContains(t, out, "hunter2")
+	require.NotContains(t, out, "s3cret")
+	require.NotContains(t, out, "BEGIN")
+	require.Contains(t, out, "DATABASE_URL=postgres://api@db:5432/go-figure?SSLKey=xxxxx&password=xxxxx&sslmode=verify-full&sslpassword=xxxxx\n")
+
+	// a key=value connection string is redacted whole
+	c.Database.URL = "host=db password=hunter2"
+	require.Contains(t, c.String(), "DATABASE_URL=[redacted]\n")
+}
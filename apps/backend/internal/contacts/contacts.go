@@ -0,0 +1,88 @@
+// Package contacts maps raw bank descriptions to the contacts behind them
+// through alias patterns such as "POS * COUNTDOWN".
+package contacts
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// MinPatternLength is the fewest characters, wildcards and spaces aside, a
+// pattern needs; shorter ones would claim most descriptions.
+const MinPatternLength = 3
+
+// ErrPatternTooShort is returned for patterns with too few literal characters.
+var ErrPatternTooShort = errors.New("pattern needs at least 3 characters besides * and spaces")
+
+// Alias is one pattern naming a contact.
+type Alias struct {
+	ID        uuid.UUID
+	ContactID uuid.UUID
+	// Pattern is normalised by NormalisePattern.
+	Pattern string
+}
+
+// NormalisePattern uppercases p, collapses runs of whitespace and of * and
+// trims both, so equivalent patterns are stored the same way.
+func NormalisePattern(p string) (string, error) {
+	p = normalise(p)
+	for strings.Contains(p, "**") {
+		p = strings.ReplaceAll(p, "**", "*")
+	}
+	p = strings.ReplaceAll(p, "* *", "*")
+	p = strings.Trim(p, "* ")
+	if literalLength(p) < MinPatternLength {
+		return "", ErrPatternTooShort
+	}
+	return p, nil
+}
+
+// Match returns the alias whose pattern matches description, preferring the
+// most specific pattern (the most literal characters) and then the earliest
+// alias. A pattern matches anywhere in the description, case-insensitively,
+// with * standing for any run of characters.
+func Match(aliases []Alias, description string) (Alias, bool) {
+	d := normalise(description)
+	var best Alias
+	bestLen := -1
+	for _, a := range aliases {
+		if n := literalLength(a.Pattern); n > bestLen && matches(a.Pattern, d) {
+			best, bestLen = a, n
+		}
+	}
+	return best, bestLen >= 0
+}
+
+// matches reports whether the glob pattern p occurs in s. Taking each
+// literal part at its leftmost occurrence after the previous one is enough:
+// a later occurrence never leaves more room for the parts that follow.
+func matches(p, s string) bool {
+	for _, part := range strings.Split(p, "*") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		i := strings.Index(s, part)
+		if i < 0 {
+			return false
+		}
+		s = s[i+len(part):]
+	}
+	return true
+}
+
+func normalise(s string) string {
+	return strings.Join(strings.Fields(strings.ToUpper(s)), " ")
+}
+
+func literalLength(p string) int {
+	n := 0
+	for _, r := range p {
+		if r != '*' && r != ' ' {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,46 @@
+package contacts
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalisePattern(t *testing.T) {
+	p, err := NormalisePattern("  pos ** countdown   * ")
+	require.NoError(t, err)
+	require.Equal(t, "POS * COUNTDOWN", p)
+
+	_, err = NormalisePattern("* a *")
+	require.ErrorIs(t, err, ErrPatternTooShort)
+}
+
+func TestMatchPrefersMostSpecificPattern(t *testing.T) {
+	countdown := Alias{ID: uuid.New(), ContactID: uuid.New(), Pattern: "COUNTDOWN"}
+	countdownAKL := Alias{ID: uuid.New(), ContactID: uuid.New(), Pattern: "POS * COUNTDOWN AKL"}
+	bunnings := Alias{ID: uuid.New(), ContactID: uuid.New(), Pattern: "BUNNINGS"}
+	aliases := []Alias{countdown, countdownAKL, bunnings}
+
+	got, ok := Match(aliases, "POS 1234  Countdown AKL")
+	require.True(t, ok)
+	require.Equal(t, countdownAKL.ID, got.ID)
+
+	got, ok = Match(aliases, "EFTPOS COUNTDOWN WELLINGTON")
+	require.True(t, ok)
+	require.Equal(t, countdown.ID, got.ID)
+
+	_, ok = Match(aliases, "AKL COUNTDOWN POS")
+	require.True(t, ok)
+
+	_, ok = Match(aliases, "Mitre 10")
+	require.False(t, ok)
+}
+
+func TestMatchRespectsPartOrder(t *testing.T) {
+	a := Alias{ID: uuid.New(), Pattern: "Z ENERGY * PONSONBY"}
+	_, ok := Match([]Alias{a}, "PONSONBY Z ENERGY")
+	require.False(t, ok)
+	_, ok = Match([]Alias{a}, "Z ENERGY 221 PONSONBY RD")
+	require.True(t, ok)
+}
@@ -3,29 +3,28 @@ package db
 import (
 	"context"
 	"errors"
-	"os"
-	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/LBaronceli/go-figure/internal/config"
 )
 
 var ErrMissingDatabaseURL = errors.New("DATABASE_URL is not set")
 
-func NewPool(ctx context.Context) (*pgxpool.Pool, error) {
-	dsn := os.Getenv("DATABASE_URL")
-	if dsn == "" {
+// NewPool opens a connection pool sized by cfg.
+func NewPool(ctx context.Context, cfg config.Database) (*pgxpool.Pool, error) {
+	if cfg.URL == "" {
 		return nil, ErrMissingDatabaseURL
 	}
 
-	cfg, err := pgxpool.ParseConfig(dsn)
+	poolCfg, err := pgxpool.ParseConfig(cfg.URL)
 	if err != nil {
 		return nil, err
 	}
+	poolCfg.MinConns = cfg.MinConns
+	poolCfg.MaxConns = cfg.MaxConns
+	poolCfg.MaxConnLifetime = cfg.MaxConnLifetime
+	poolCfg.MaxConnIdleTime = cfg.MaxConnIdleTime
 
-	// sensible defaults for dev
-	cfg.MinConns = 1
-	cfg.MaxConns = 5
-	cfg.MaxConnLifetime = 30 * time.Minute
-
-	return pgxpool.NewWithConfig(ctx, cfg)
+	return pgxpool.NewWithConfig(ctx, poolCfg)
 }
@@ -1,10 +1,11 @@
 -- name: CreateAccount :one
 INSERT INTO accounts (
+    organisation_id,
     name,
     type,
     currency
 ) VALUES (
-    $1, $2, $3
+    $1, $2, $3, $4
 )
 RETURNING
     id,
@@ -12,7 +13,9 @@ RETURNING
     type,
     currency,
     created_at,
-    updated_at;
+    updated_at,
+    archived_at,
+    organisation_id;
 
 -- name: GetAccount :one
 SELECT 
@@ -21,9 +24,11 @@ SELECT
   type,
   currency,
   created_at,
-  updated_at
+  updated_at,
+  archived_at,
+  organisation_id
 FROM accounts
-WHERE id = $1;
+WHERE organisation_id = $1 AND id = $2;
 
 -- name: ListAccounts :many
 SELECT 
@@ -32,8 +37,12 @@ SELECT
   type,
   currency,
   created_at,
-  updated_at
+  updated_at,
+  archived_at,
+  organisation_id
 FROM accounts
+WHERE organisation_id = $1
+  AND (sqlc.arg('include_archived')::boolean OR archived_at IS NULL)
 ORDER BY created_at DESC;
 
 -- name: UpdateAccount :one
@@ -41,20 +50,55 @@ UPDATE accounts
 SET
     name = COALESCE(sqlc.narg('name'), name),
     type = COALESCE(sqlc.narg('type'), type)
-WHERE id = $1
+WHERE organisation_id = $1 AND id = $2
 RETURNING
     id,
     name,
     type,
     currency,
     created_at,
-    updated_at;
+    updated_at,
+    archived_at,
+    organisation_id;
 
 -- name: DeleteAccount :exec
 DELETE FROM accounts
-WHERE id = $1;
+WHERE organisation_id = $1 AND id = $2;
 
 -- name: GetAccountsByIDs :many
 SELECT * FROM accounts
-WHERE id = ANY($1::uuid[]);
+WHERE organisation_id = $1 AND id = ANY(sqlc.arg('ids')::uuid[]);
 
+-- name: AccountHasLedgerEntries :one
+SELECT EXISTS (
+  SELECT 1 FROM ledger_entries
+  WHERE organisation_id = $1 AND account_id = $2
+) AS has_entries;
+
+-- name: ArchiveAccount :one
+UPDATE accounts
+SET archived_at = COALESCE(archived_at, now())
+WHERE organisation_id = $1 AND id = $2
+RETURNING
+    id,
+    name,
+    type,
+    currency,
+    created_at,
+    updated_at,
+    archived_at,
+    organisation_id;
+
+-- name: UnarchiveAccount :one
+UPDATE accounts
+SET archived_at = NULL
+WHERE organisation_id = $1 AND id = $2
+RETURNING
+    id,
+    name,
+    type,
+    currency,
+    created_at,
+    updated_at,
+    archived_at,
+    organisation_id;
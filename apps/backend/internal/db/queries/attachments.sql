@@ -0,0 +1,78 @@
+-- name: LockAttachmentContent :exec
+-- Serialises attaching and deleting one file within an organisation, so a
+-- blob is never deleted while another transaction starts to refer to it.
+SELECT pg_advisory_xact_lock(hashtextextended('attachments:' || sqlc.arg('organisation_id')::uuid::text || encode(sqlc.arg('sha256')::bytea, 'hex'), 0));
+
+-- name: CreateAttachment :one
+INSERT INTO attachments (
+  organisation_id,
+  transaction_id,
+  sha256,
+  filename,
+  content_type,
+  size_bytes,
+  uploaded_by
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7
+)
+RETURNING *;
+
+-- name: GetAttachment :one
+SELECT * FROM attachments
+WHERE organisation_id = $1 AND transaction_id = $2 AND id = $3;
+
+-- name: GetAttachmentByContent :one
+SELECT * FROM attachments
+WHERE organisation_id = $1 AND transaction_id = $2 AND sha256 = $3;
+
+-- name: ListAttachments :many
+SELECT * FROM attachments
+WHERE organisation_id = $1 AND transaction_id = $2
+ORDER BY created_at, id;
+
+-- name: DeleteAttachment :one
+DELETE FROM attachments
+WHERE organisation_id = $1 AND transaction_id = $2 AND id = $3
+RETURNING *;
+
+-- name: CountAttachmentsWithContent :one
+SELECT count(*) FROM attachments
+WHERE organisation_id = $1 AND sha256 = $2;
+
+-- name: GetAttachmentByID :one
+SELECT * FROM attachments
+WHERE organisation_id = $1 AND id = $2;
+
+-- name: GetInboxAttachmentByContent :one
+SELECT * FROM attachments
+WHERE organisation_id = $1 AND transaction_id IS NULL AND sha256 = $2;
+
+-- name: SetAttachmentTransaction :one
+-- Files an inbox receipt under the transaction it records.
+UPDATE attachments
+SET transaction_id = $3
+WHERE organisation_id = $1 AND id = $2 AND transaction_id IS NULL
+RETURNING *;
+
+-- name: GetAttachmentsByIDs :many
+SELECT * FROM attachments
+WHERE organisation_id = $1 AND id = ANY(sqlc.arg('ids')::uuid[]);
+
+-- name: DeleteInboxAttachment :one
+DELETE FROM attachments
+WHERE organisation_id = $1 AND id = $2 AND transaction_id IS NULL
+RETURNING *;
+
+-- name: QueueAttachmentContentDeletion :exec
+INSERT INTO attachment_deletions (organisation_id, sha256)
+VALUES ($1, $2)
+ON CONFLICT (organisation_id, sha256) DO NOTHING;
+
+-- name: ListAttachmentContentDeletions :many
+SELECT * FROM attachment_deletions
+ORDER BY created_at
+LIMIT $1;
+
+-- name: DeleteAttachmentContentDeletion :exec
+DELETE FROM attachment_deletions
+WHERE organisation_id = $1 AND sha256 = $2;
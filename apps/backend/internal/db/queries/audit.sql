@@ -0,0 +1,85 @@
+-- name: LockAuditChain :exec
+-- Serialises appends to one organisation's chain until the transaction ends.
+SELECT pg_advisory_xact_lock(hashtextextended(sqlc.arg('organisation_id')::uuid::text, 0));
+
+-- name: GetAuditChainHead :one
+SELECT seq, hash FROM audit_events
+WHERE organisation_id = $1
+ORDER BY seq DESC
+LIMIT 1;
+
+-- name: CreateAuditEvent :exec
+INSERT INTO audit_events (
+  organisation_id,
+  seq,
+  actor_user_id,
+  actor_email,
+  actor_method,
+  request_id,
+  entity,
+  entity_id,
+  action,
+  before,
+  after,
+  created_at,
+  prev_hash,
+  hash
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14
+);
+
+-- name: ListAuditEvents :many
+SELECT * FROM audit_events
+WHERE organisation_id = $1
+  AND (sqlc.narg('entity')::text IS NULL OR entity = sqlc.narg('entity'))
+  AND (sqlc.narg('entity_id')::uuid IS NULL OR entity_id = sqlc.narg('entity_id'))
+ORDER BY seq DESC
+LIMIT $2 OFFSET $3;
+
+-- name: ListAuditChain :many
+SELECT * FROM audit_events
+WHERE organisation_id = $1
+ORDER BY seq;
+
+-- name: CreateAuditEvents :exec
+-- Appends a run of events recorded by one request, already chained in seq
+-- order. Empty before or after documents are stored as NULL.
+INSERT INTO audit_events (
+  organisation_id,
+  seq,
+  actor_user_id,
+  actor_email,
+  actor_method,
+  request_id,
+  entity,
+  entity_id,
+  action,
+  before,
+  after,
+  created_at,
+  prev_hash,
+  hash
+)
+SELECT
+  sqlc.arg('organisation_id')::uuid,
+  e.seq,
+  sqlc.arg('actor_user_id')::uuid,
+  sqlc.arg('actor_email')::text,
+  sqlc.arg('actor_method')::text,
+  sqlc.arg('request_id')::text,
+  sqlc.arg('entity')::text,
+  e.entity_id,
+  sqlc.arg('action')::text,
+  NULLIF(e.before, '')::jsonb,
+  NULLIF(e.after, '')::jsonb,
+  sqlc.arg('created_at')::timestamptz,
+  e.prev_hash,
+  e.hash
+FROM unnest(
+  sqlc.arg('seqs')::bigint[],
+  sqlc.arg('entity_ids')::uuid[],
+  sqlc.arg('befores')::text[],
+  sqlc.arg('afters')::text[],
+  sqlc.arg('prev_hashes')::bytea[],
+  sqlc.arg('hashes')::bytea[]
+) AS e(seq, entity_id, before, after, prev_hash, hash);
@@ -0,0 +1,92 @@
+-- name: CreateUser :one
+INSERT INTO users (
+  email,
+  password_hash,
+  is_admin
+) VALUES (
+  $1, $2, $3
+)
+RETURNING *;
+
+-- name: GetUser :one
+SELECT * FROM users
+WHERE id = $1;
+
+-- name: GetUserByEmail :one
+SELECT * FROM users
+WHERE email = $1;
+
+-- name: ListUsers :many
+SELECT * FROM users
+ORDER BY created_at;
+
+-- name: CreateSession :one
+INSERT INTO sessions (
+  token_hash,
+  user_id,
+  expires_at
+) VALUES (
+  $1, $2, $3
+)
+RETURNING *;
+
+-- name: GetSessionPrincipal :one
+SELECT
+  s.id,
+  s.user_id,
+  s.expires_at,
+  u.email,
+  u.is_admin
+FROM sessions s
+JOIN users u ON u.id = s.user_id
+WHERE s.token_hash = $1
+  AND s.expires_at > now();
+
+-- name: DeleteSession :exec
+DELETE FROM sessions
+WHERE id = $1;
+
+-- name: DeleteExpiredSessions :execrows
+DELETE FROM sessions
+WHERE expires_at <= now();
+
+-- name: CreateAPIToken :one
+INSERT INTO api_tokens (
+  user_id,
+  name,
+  token_prefix,
+  token_hash,
+  scopes,
+  expires_at
+) VALUES (
+  $1, $2, $3, $4, $5, $6
+)
+RETURNING *;
+
+-- name: GetAPITokenPrincipal :one
+SELECT
+  t.id,
+  t.user_id,
+  t.scopes,
+  u.email
+FROM api_tokens t
+JOIN users u ON u.id = t.user_id
+WHERE t.token_hash = $1
+  AND t.revoked_at IS NULL
+  AND (t.expires_at IS NULL OR t.expires_at > now());
+
+-- name: TouchAPIToken :exec
+UPDATE api_tokens
+SET last_used_at = now()
+WHERE id = $1;
+
+-- name: ListAPITokens :many
+SELECT * FROM api_tokens
+WHERE user_id = $1
+ORDER BY created_at DESC;
+
+-- name: RevokeAPIToken :one
+UPDATE api_tokens
+SET revoked_at = COALESCE(revoked_at, now())
+WHERE id = $1 AND user_id = $2
+RETURNING *;
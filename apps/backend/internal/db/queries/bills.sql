@@ -0,0 +1,157 @@
+-- name: CreateBill :one
+INSERT INTO bills (
+  organisation_id,
+  contact_id,
+  number,
+  reference,
+  currency,
+  issue_on,
+  due_on,
+  payable_account_id,
+  subtotal_minor,
+  tax_minor,
+  total_minor
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11
+)
+RETURNING *;
+
+-- name: GetBill :one
+SELECT * FROM bills
+WHERE organisation_id = $1 AND id = $2;
+
+-- name: GetBillForUpdate :one
+SELECT * FROM bills
+WHERE organisation_id = $1 AND id = $2
+FOR UPDATE;
+
+-- name: ListBills :many
+SELECT * FROM bills
+WHERE organisation_id = $1
+  AND (sqlc.narg('status')::text IS NULL OR status = sqlc.narg('status'))
+  AND (sqlc.narg('contact_id')::uuid IS NULL OR contact_id = sqlc.narg('contact_id'))
+ORDER BY issue_on DESC, number DESC
+LIMIT $2 OFFSET $3;
+
+-- name: UpdateBill :one
+UPDATE bills
+SET contact_id = $3,
+    number = $4,
+    reference = $5,
+    currency = $6,
+    issue_on = $7,
+    due_on = $8,
+    payable_account_id = $9,
+    subtotal_minor = $10,
+    tax_minor = $11,
+    total_minor = $12
+WHERE organisation_id = $1 AND id = $2
+RETURNING *;
+
+-- name: DeleteBill :exec
+DELETE FROM bills
+WHERE organisation_id = $1 AND id = $2;
+
+-- name: ApproveBill :one
+UPDATE bills
+SET status = 'approved', approved_at = now(), transaction_id = $3
+WHERE organisation_id = $1 AND id = $2
+RETURNING *;
+
+-- name: AddBillPayment :one
+-- Raises paid_minor and marks the bill paid once it covers the total.
+UPDATE bills
+SET paid_minor = paid_minor + sqlc.arg('amount_minor')::bigint,
+    status = CASE WHEN paid_minor + sqlc.arg('amount_minor')::bigint = total_minor THEN 'paid' ELSE status END,
+    paid_on = CASE WHEN paid_minor + sqlc.arg('amount_minor')::bigint = total_minor THEN sqlc.arg('paid_on')::date ELSE paid_on END
+WHERE organisation_id = $1 AND id = $2
+RETURNING *;
+
+-- name: VoidBill :one
+UPDATE bills
+SET status = 'void', voided_on = $3, void_transaction_id = $4
+WHERE organisation_id = $1 AND id = $2
+RETURNING *;
+
+-- name: CreateBillLines :many
+INSERT INTO bill_lines (
+  organisation_id,
+  bill_id,
+  position,
+  description,
+  quantity_thousandths,
+  unit_price_minor,
+  account_id,
+  tax_code_id,
+  amount_minor,
+  tax_minor
+)
+SELECT $1, $2, l.position, l.description, l.quantity_thousandths, l.unit_price_minor, l.account_id, l.tax_code_id, l.amount_minor, l.tax_minor
+FROM unnest(
+  sqlc.arg('positions')::int[],
+  sqlc.arg('descriptions')::text[],
+  sqlc.arg('quantities_thousandths')::bigint[],
+  sqlc.arg('unit_prices_minor')::bigint[],
+  sqlc.arg('account_ids')::uuid[],
+  sqlc.arg('tax_code_ids')::uuid[],
+  sqlc.arg('amounts_minor')::bigint[],
+  sqlc.arg('taxes_minor')::bigint[]
+) AS l(position, description, quantity_thousandths, unit_price_minor, account_id, tax_code_id, amount_minor, tax_minor)
+RETURNING *;
+
+-- name: ListBillLines :many
+SELECT * FROM bill_lines
+WHERE organisation_id = $1 AND bill_id = $2
+ORDER BY position;
+
+-- name: DeleteBillLines :exec
+DELETE FROM bill_lines
+WHERE organisation_id = $1 AND bill_id = $2;
+
+-- name: CreateBillPayment :one
+INSERT INTO bill_payments (
+  organisation_id,
+  bill_id,
+  transaction_id,
+  account_id,
+  paid_on,
+  amount_minor
+) VALUES (
+  $1, $2, $3, $4, $5, $6
+)
+RETURNING *;
+
+-- name: ListBillPayments :many
+SELECT * FROM bill_payments
+WHERE organisation_id = $1 AND bill_id = $2
+ORDER BY paid_on, created_at;
+
+-- name: ListOutstandingBills :many
+-- Approved bills with money still owed on the given day: issued by then,
+-- not yet voided and not fully paid by payments dated up to then.
+SELECT
+  b.id,
+  b.number,
+  b.contact_id,
+  c.name AS contact_name,
+  b.currency,
+  b.issue_on,
+  b.due_on,
+  (b.total_minor - COALESCE(p.paid_minor, 0))::bigint AS outstanding_minor
+FROM bills b
+JOIN contacts c
+  ON c.organisation_id = b.organisation_id AND c.id = b.contact_id
+LEFT JOIN LATERAL (
+  SELECT SUM(bp.amount_minor)::bigint AS paid_minor
+  FROM bill_payments bp
+  WHERE bp.organisation_id = b.organisation_id
+    AND bp.bill_id = b.id
+    AND bp.paid_on <= sqlc.arg('as_of')::date
+) p ON true
+WHERE b.organisation_id = $1
+  AND b.transaction_id IS NOT NULL
+  AND b.issue_on <= sqlc.arg('as_of')::date
+  AND (b.voided_on IS NULL OR b.voided_on > sqlc.arg('as_of')::date)
+  AND b.total_minor > COALESCE(p.paid_minor, 0)
+ORDER BY c.name, b.contact_id, b.currency, b.due_on, b.number;
+
@@ -0,0 +1,84 @@
+-- name: CreateContact :one
+INSERT INTO contacts (
+  organisation_id,
+  name,
+  email,
+  default_account_id
+) VALUES (
+  $1, $2, $3, $4
+)
+RETURNING *;
+
+-- name: GetContact :one
+SELECT * FROM contacts
+WHERE organisation_id = $1 AND id = $2;
+
+-- name: GetContactsByIDs :many
+SELECT * FROM contacts
+WHERE organisation_id = $1 AND id = ANY(sqlc.arg('ids')::uuid[]);
+
+-- name: ListContacts :many
+SELECT * FROM contacts
+WHERE organisation_id = $1
+ORDER BY name;
+
+-- name: UpdateContact :one
+UPDATE contacts
+SET name = $3, email = $4, default_account_id = $5
+WHERE organisation_id = $1 AND id = $2
+RETURNING *;
+
+-- name: DeleteContact :exec
+DELETE FROM contacts
+WHERE organisation_id = $1 AND id = $2;
+
+-- name: CreateContactAlias :one
+INSERT INTO contact_aliases (
+  organisation_id,
+  contact_id,
+  pattern
+) VALUES (
+  $1, $2, $3
+)
+RETURNING *;
+
+-- name: ListContactAliases :many
+SELECT * FROM contact_aliases
+WHERE organisation_id = $1
+  AND (sqlc.narg('contact_id')::uuid IS NULL OR contact_id = sqlc.narg('contact_id'))
+ORDER BY created_at, id;
+
+-- name: DeleteContactAlias :execrows
+DELETE FROM contact_aliases
+WHERE organisation_id = $1 AND contact_id = $2 AND id = $3;
+
+-- name: ReassignContactTransactions :execrows
+UPDATE transactions
+SET contact_id = sqlc.arg('to_contact_id')
+WHERE organisation_id = $1 AND contact_id = sqlc.arg('from_contact_id');
+
+-- name: ReassignContactAliases :execrows
+UPDATE contact_aliases
+SET contact_id = sqlc.arg('to_contact_id')
+WHERE organisation_id = $1 AND contact_id = sqlc.arg('from_contact_id');
+
+-- name: ReassignContactInvoices :execrows
+UPDATE invoices
+SET contact_id = sqlc.arg('to_contact_id')
+WHERE organisation_id = $1 AND contact_id = sqlc.arg('from_contact_id');
+
+-- name: ReassignContactBills :execrows
+UPDATE bills
+SET contact_id = sqlc.arg('to_contact_id')
+WHERE organisation_id = $1 AND contact_id = sqlc.arg('from_contact_id');
+
+-- name: ReassignContactReceiptScans :execrows
+UPDATE receipt_scans
+SET contact_id = sqlc.arg('to_contact_id')
+WHERE organisation_id = $1 AND contact_id = sqlc.arg('from_contact_id');
+
+-- name: SetTransactionContact :one
+UPDATE transactions
+SET contact_id = $3
+WHERE organisation_id = $1 AND id = $2
+RETURNING *;
@@ -0,0 +1,33 @@
+-- name: InsertIdempotencyRecord :execrows
+INSERT INTO idempotency_records (
+  scope,
+  key,
+  method,
+  path,
+  request_hash
+) VALUES (
+  $1, $2, $3, $4, $5
+)
+ON CONFLICT (scope, key) DO NOTHING;
+
+-- name: GetIdempotencyRecord :one
+SELECT * FROM idempotency_records
+WHERE scope = $1 AND key = $2;
+
+-- name: CompleteIdempotencyRecord :exec
+UPDATE idempotency_records
+SET status_code = $3,
+    response_headers = $4,
+    response_body = $5,
+    completed_at = now()
+WHERE scope = $1 AND key = $2;
+
+-- name: DeleteExpiredIdempotencyRecord :exec
+DELETE FROM idempotency_records
+WHERE scope = $1
+  AND key = $2
+  AND created_at < sqlc.arg('cutoff')::timestamptz;
+
+-- name: DeleteExpiredIdempotencyRecords :execrows
+DELETE FROM idempotency_records
+WHERE created_at < sqlc.arg('cutoff')::timestamptz;
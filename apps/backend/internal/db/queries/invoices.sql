@@ -0,0 +1,239 @@
+-- name: CreateTaxCode :one
+INSERT INTO tax_codes (
+  organisation_id,
+  name,
+  rate_basis_points,
+  account_id
+) VALUES (
+  $1, $2, $3, $4
+)
+RETURNING *;
+
+-- name: GetTaxCode :one
+SELECT * FROM tax_codes
+WHERE organisation_id = $1 AND id = $2;
+
+-- name: GetTaxCodesByIDs :many
+SELECT * FROM tax_codes
+WHERE organisation_id = $1 AND id = ANY(sqlc.arg('ids')::uuid[]);
+
+-- name: ListTaxCodes :many
+SELECT * FROM tax_codes
+WHERE organisation_id = $1
+ORDER BY name;
+
+-- name: UpdateTaxCode :one
+UPDATE tax_codes
+SET name = $3, rate_basis_points = $4, account_id = $5
+WHERE organisation_id = $1 AND id = $2
+RETURNING *;
+
+-- name: DeleteTaxCode :exec
+DELETE FROM tax_codes
+WHERE organisation_id = $1 AND id = $2;
+
+-- name: NextInvoiceNumber :one
+-- One more than the highest INV-n number so far; numbers chosen by hand in
+-- other formats are skipped.
+SELECT (COALESCE(MAX(substring(number FROM '^INV-([0-9]{1,9})$')::bigint), 0) + 1)::bigint AS next
+FROM invoices
+WHERE organisation_id = $1;
+
+-- name: CreateInvoice :one
+INSERT INTO invoices (
+  organisation_id,
+  contact_id,
+  number,
+  reference,
+  currency,
+  issue_on,
+  due_on,
+  receivable_account_id,
+  subtotal_minor,
+  tax_minor,
+  total_minor
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11
+)
+RETURNING *;
+
+-- name: GetInvoice :one
+SELECT * FROM invoices
+WHERE organisation_id = $1 AND id = $2;
+
+-- name: GetInvoiceForUpdate :one
+SELECT * FROM invoices
+WHERE organisation_id = $1 AND id = $2
+FOR UPDATE;
+
+-- name: ListInvoices :many
+SELECT * FROM invoices
+WHERE organisation_id = $1
+  AND (sqlc.narg('status')::text IS NULL OR status = sqlc.narg('status'))
+  AND (sqlc.narg('contact_id')::uuid IS NULL OR contact_id = sqlc.narg('contact_id'))
+ORDER BY issue_on DESC, number DESC
+LIMIT $2 OFFSET $3;
+
+-- name: UpdateInvoice :one
+UPDATE invoices
+SET contact_id = $3,
+    number = $4,
+    reference = $5,
+    currency = $6,
+    issue_on = $7,
+    due_on = $8,
+    receivable_account_id = $9,
+    subtotal_minor = $10,
+    tax_minor = $11,
+    total_minor = $12
+WHERE organisation_id = $1 AND id = $2
+RETURNING *;
+
+-- name: DeleteInvoice :exec
+DELETE FROM invoices
+WHERE organisation_id = $1 AND id = $2;
+
+-- name: ApproveInvoice :one
+UPDATE invoices
+SET status = 'sent', sent_at = now(), transaction_id = $3
+WHERE organisation_id = $1 AND id = $2
+RETURNING *;
+
+-- name: AddInvoicePayment :one
+-- Raises paid_minor and marks the invoice paid once it covers the total.
+UPDATE invoices
+SET paid_minor = paid_minor + sqlc.arg('amount_minor')::bigint,
+    status = CASE WHEN paid_minor + sqlc.arg('amount_minor')::bigint = total_minor THEN 'paid' ELSE status END,
+    paid_on = CASE WHEN paid_minor + sqlc.arg('amount_minor')::bigint = total_minor THEN sqlc.arg('paid_on')::date ELSE paid_on END
+WHERE organisation_id = $1 AND id = $2
+RETURNING *;
+
+-- name: VoidInvoice :one
+UPDATE invoices
+SET status = 'void', voided_on = $3, void_transaction_id = $4
+WHERE organisation_id = $1 AND id = $2
+RETURNING *;
+
+-- name: CreateInvoiceLines :many
+INSERT INTO invoice_lines (
+  organisation_id,
+  invoice_id,
+  position,
+  description,
+  quantity_thousandths,
+  unit_price_minor,
+  account_id,
+  tax_code_id,
+  amount_minor,
+  tax_minor
+)
+SELECT $1, $2, l.position, l.description, l.quantity_thousandths, l.unit_price_minor, l.account_id, l.tax_code_id, l.amount_minor, l.tax_minor
+FROM unnest(
+  sqlc.arg('positions')::int[],
+  sqlc.arg('descriptions')::text[],
+  sqlc.arg('quantities_thousandths')::bigint[],
+  sqlc.arg('unit_prices_minor')::bigint[],
+  sqlc.arg('account_ids')::uuid[],
+  sqlc.arg('tax_code_ids')::uuid[],
+  sqlc.arg('amounts_minor')::bigint[],
+  sqlc.arg('taxes_minor')::bigint[]
+) AS l(position, description, quantity_thousandths, unit_price_minor, account_id, tax_code_id, amount_minor, tax_minor)
+RETURNING *;
+
+-- name: ListInvoiceLines :many
+SELECT * FROM invoice_lines
+WHERE organisation_id = $1 AND invoice_id = $2
+ORDER BY position;
+
+-- name: DeleteInvoiceLines :exec
+DELETE FROM invoice_lines
+WHERE organisation_id = $1 AND invoice_id = $2;
+
+-- name: CreateInvoicePayment :one
+INSERT INTO invoice_payments (
+  organisation_id,
+  invoice_id,
+  transaction_id,
+  account_id,
+  paid_on,
+  amount_minor
+) VALUES (
+  $1, $2, $3, $4, $5, $6
+)
+RETURNING *;
+
+-- name: ListInvoicePayments :many
+SELECT * FROM invoice_payments
+WHERE organisation_id = $1 AND invoice_id = $2
+ORDER BY paid_on, created_at;
+
+-- name: ListOutstandingInvoices :many
+-- Approved invoices with money still owed on the given day: issued by then,
+-- not yet voided and not fully paid by payments dated up to then.
+SELECT
+  i.id,
+  i.number,
+  i.contact_id,
+  c.name AS contact_name,
+  i.currency,
+  i.issue_on,
+  i.due_on,
+  (i.total_minor - COALESCE(p.paid_minor, 0))::bigint AS outstanding_minor
+FROM invoices i
+JOIN contacts c
+  ON c.organisation_id = i.organisation_id AND c.id = i.contact_id
+LEFT JOIN LATERAL (
+  SELECT SUM(ip.amount_minor)::bigint AS paid_minor
+  FROM invoice_payments ip
+  WHERE ip.organisation_id = i.organisation_id
+    AND ip.invoice_id = i.id
+    AND ip.paid_on <= sqlc.arg('as_of')::date
+) p ON true
+WHERE i.organisation_id = $1
+  AND i.transaction_id IS NOT NULL
+  AND i.issue_on <= sqlc.arg('as_of')::date
+  AND (i.voided_on IS NULL OR i.voided_on > sqlc.arg('as_of')::date)
+  AND i.total_minor > COALESCE(p.paid_minor, 0)
+ORDER BY c.name, i.contact_id, i.currency, i.due_on, i.number;
+
+-- name: GetInvoiceTemplate :one
+SELECT * FROM invoice_templates
+WHERE organisation_id = $1;
+
+-- name: UpsertInvoiceTemplate :one
+-- Replaces everything but the logo, which has its own endpoint.
+INSERT INTO invoice_templates (
+  organisation_id,
+  trading_name,
+  address,
+  tax_number,
+  email,
+  accent_color,
+  payment_instructions,
+  footer
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7, $8
+)
+ON CONFLICT (organisation_id) DO UPDATE
+SET trading_name = EXCLUDED.trading_name,
+    address = EXCLUDED.address,
+    tax_number = EXCLUDED.tax_number,
+    email = EXCLUDED.email,
+    accent_color = EXCLUDED.accent_color,
+    payment_instructions = EXCLUDED.payment_instructions,
+    footer = EXCLUDED.footer
+RETURNING *;
+
+-- name: SetInvoiceTemplateLogo :one
+-- A null logo and logo_type remove the logo.
+INSERT INTO invoice_templates (
+  organisation_id,
+  logo,
+  logo_type
+) VALUES (
+  $1, $2, $3
+)
+ON CONFLICT (organisation_id) DO UPDATE
+SET logo = EXCLUDED.logo,
+    logo_type = EXCLUDED.logo_type
+RETURNING *;
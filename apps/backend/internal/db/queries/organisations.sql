@@ -0,0 +1,77 @@
+-- name: CreateOrganisation :one
+INSERT INTO organisations (
+  name,
+  timezone,
+  fiscal_year_start_month
+) VALUES (
+  $1, $2, $3
+)
+RETURNING *;
+
+-- name: UpdateOrganisation :one
+UPDATE organisations
+SET
+  name = COALESCE(sqlc.narg('name'), name),
+  timezone = COALESCE(sqlc.narg('timezone'), timezone),
+  fiscal_year_start_month = COALESCE(sqlc.narg('fiscal_year_start_month'), fiscal_year_start_month)
+WHERE id = $1
+RETURNING *;
+
+-- name: ListOrganisationsForUser :many
+SELECT
+  o.id,
+  o.name,
+  o.created_at,
+  m.role,
+  o.timezone,
+  o.fiscal_year_start_month
+FROM organisations o
+JOIN memberships m ON m.organisation_id = o.id
+WHERE m.user_id = $1
+ORDER BY o.created_at;
+
+-- name: GetMembership :one
+SELECT * FROM memberships
+WHERE organisation_id = $1 AND user_id = $2;
+
+-- name: CreateMembership :one
+INSERT INTO memberships (
+  organisation_id,
+  user_id,
+  role
+) VALUES (
+  $1, $2, $3
+)
+RETURNING *;
+
+-- name: UpdateMembershipRole :one
+UPDATE memberships
+SET role = $3
+WHERE organisation_id = $1 AND user_id = $2
+RETURNING *;
+
+-- name: DeleteMembership :execrows
+DELETE FROM memberships
+WHERE organisation_id = $1 AND user_id = $2;
+
+-- name: ListMembers :many
+SELECT
+  m.user_id,
+  u.email,
+  m.role,
+  m.created_at
+FROM memberships m
+JOIN users u ON u.id = m.user_id
+WHERE m.organisation_id = $1
+ORDER BY m.created_at;
+
+-- name: CountOwners :one
+SELECT count(*) FROM memberships
+WHERE organisation_id = $1 AND role = 'owner';
+
+-- name: LockOrganisation :exec
+-- Serialises membership changes so the last owner cannot be removed by two
+-- concurrent requests.
+SELECT id FROM organisations
+WHERE id = $1
+FOR UPDATE;
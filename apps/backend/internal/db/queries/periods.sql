@@ -0,0 +1,69 @@
+-- name: LockPeriodsShared :exec
+-- Held by every posting until it commits, so a period cannot be locked
+-- between the check and the insert.
+SELECT pg_advisory_xact_lock_shared(hashtextextended('period_locks:' || sqlc.arg('organisation_id')::uuid::text, 0));
+
+-- name: LockPeriodsExclusive :exec
+-- Held while locking or closing a period; waits for postings in flight.
+SELECT pg_advisory_xact_lock(hashtextextended('period_locks:' || sqlc.arg('organisation_id')::uuid::text, 0));
+
+-- name: GetPeriodLockOn :one
+SELECT * FROM period_locks
+WHERE organisation_id = $1
+  AND starts_on <= sqlc.arg('on')::date
+  AND sqlc.arg('on')::date <= ends_on
+ORDER BY starts_on
+LIMIT 1;
+
+-- name: GetOverlappingPeriodLock :one
+SELECT * FROM period_locks
+WHERE organisation_id = $1
+  AND starts_on <= sqlc.arg('ends_on')::date
+  AND sqlc.arg('starts_on')::date <= ends_on
+ORDER BY starts_on
+LIMIT 1;
+
+-- name: CreatePeriodLock :one
+INSERT INTO period_locks (
+  organisation_id,
+  starts_on,
+  ends_on,
+  reason,
+  locked_by
+) VALUES (
+  $1, $2, $3, $4, $5
+)
+RETURNING *;
+
+-- name: GetPeriodLock :one
+SELECT * FROM period_locks
+WHERE organisation_id = $1 AND id = $2;
+
+-- name: ListPeriodLocks :many
+SELECT * FROM period_locks
+WHERE organisation_id = $1
+ORDER BY starts_on DESC;
+
+-- name: DeletePeriodLock :exec
+DELETE FROM period_locks
+WHERE organisation_id = $1 AND id = $2;
+
+-- name: ListClosingBalances :many
+-- Net movement of every income and expense account from starts_on to
+-- ends_on inclusive.
+SELECT
+  a.id AS account_id,
+  a.currency,
+  SUM(le.amount_minor)::bigint AS balance_minor
+FROM ledger_entries le
+JOIN transactions t
+  ON t.organisation_id = le.organisation_id AND t.id = le.transaction_id
+JOIN accounts a
+  ON a.organisation_id = le.organisation_id AND a.id = le.account_id
+WHERE le.organisation_id = $1
+  AND a.type IN ('income', 'expense')
+  AND t.posted_on >= sqlc.arg('starts_on')::date
+  AND t.posted_on <= sqlc.arg('ends_on')::date
+GROUP BY a.id, a.currency
+HAVING SUM(le.amount_minor) <> 0
+ORDER BY a.id;
@@ -0,0 +1,136 @@
+-- name: CreateReceiptScan :one
+INSERT INTO receipt_scans (organisation_id, attachment_id)
+VALUES ($1, $2)
+RETURNING *;
+
+-- name: ClaimReceiptScan :one
+-- Takes the next scan that is due, or whose worker stopped before
+-- stale_before with attempts left, across every organisation. Run with
+-- row-level security bypassed.
+UPDATE receipt_scans
+SET status = 'running',
+    attempts = attempts + 1,
+    locked_at = now()
+WHERE id = (
+  SELECT id FROM receipt_scans
+  WHERE (status = 'queued' AND run_after <= now())
+     OR (status = 'running' AND locked_at < sqlc.arg('stale_before') AND attempts < sqlc.arg('max_attempts'))
+  ORDER BY run_after, id
+  LIMIT 1
+  FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
+
+-- name: GetReceiptScan :one
+SELECT * FROM receipt_scans
+WHERE organisation_id = $1 AND id = $2;
+
+-- name: GetReceiptScanByAttachment :one
+SELECT * FROM receipt_scans
+WHERE organisation_id = $1 AND attachment_id = $2;
+
+-- name: GetReceiptScanForUpdate :one
+SELECT * FROM receipt_scans
+WHERE organisation_id = $1 AND id = $2
+FOR UPDATE;
+
+-- name: ListReceiptScans :many
+SELECT * FROM receipt_scans
+WHERE organisation_id = $1
+  AND (sqlc.narg('status')::text IS NULL OR status = sqlc.narg('status'))
+  AND (sqlc.narg('outcome')::text IS NULL OR outcome = sqlc.narg('outcome'))
+ORDER BY created_at DESC, id DESC
+LIMIT $2 OFFSET $3;
+
+-- name: CompleteReceiptScan :one
+UPDATE receipt_scans
+SET status = 'done',
+    locked_at = NULL,
+    error = '',
+    text = $3,
+    merchant = $4,
+    merchant_confidence = $5,
+    receipt_date = $6,
+    date_confidence = $7,
+    total_minor = $8,
+    total_confidence = $9,
+    gst_minor = $10,
+    gst_confidence = $11,
+    outcome = $12,
+    statement_line_id = $13,
+    transaction_id = $14,
+    contact_id = $15,
+    account_id = $16
+WHERE organisation_id = $1 AND id = $2
+RETURNING *;
+
+-- name: RetryReceiptScan :exec
+UPDATE receipt_scans
+SET status = 'queued',
+    locked_at = NULL,
+    error = $3,
+    run_after = $4
+WHERE organisation_id = $1 AND id = $2 AND status = 'running';
+
+-- name: FailReceiptScan :exec
+UPDATE receipt_scans
+SET status = 'failed',
+    locked_at = NULL,
+    error = $3
+WHERE organisation_id = $1 AND id = $2 AND status = 'running';
+
+-- name: FailStaleReceiptScans :execrows
+-- Gives up on scans whose worker stopped before stale_before on their last
+-- attempt, such as receipts that crash the engine, across every
+-- organisation. Run with row-level security bypassed.
+UPDATE receipt_scans
+SET status = 'failed',
+    locked_at = NULL,
+    error = 'scan stopped without finishing on every attempt'
+WHERE status = 'running'
+  AND locked_at < sqlc.arg('stale_before')
+  AND attempts >= sqlc.arg('max_attempts');
+
+-- name: RequeueReceiptScan :one
+UPDATE receipt_scans
+SET status = 'queued',
+    attempts = 0,
+    run_after = now(),
+    locked_at = NULL,
+    error = '',
+    outcome = NULL,
+    statement_line_id = NULL,
+    transaction_id = NULL,
+    contact_id = NULL,
+    account_id = NULL
+WHERE organisation_id = $1 AND id = $2
+RETURNING *;
+
+-- name: SetReceiptScanPosted :one
+UPDATE receipt_scans
+SET outcome = 'posted',
+    transaction_id = $3
+WHERE organisation_id = $1 AND id = $2
+RETURNING *;
+
+-- name: ListReceiptMatchCandidates :many
+-- Imported statement lines for the amount within the window that no other
+-- receipt evidences, with the transaction of the ledger entry they are
+-- matched to, if any.
+SELECT
+  sl.id,
+  sl.posted_on,
+  sl.amount_minor,
+  sl.description,
+  le.transaction_id
+FROM statement_lines sl
+LEFT JOIN ledger_entries le
+  ON le.id = sl.ledger_entry_id AND sl.match_status = 'matched'
+WHERE sl.organisation_id = $1
+  AND sl.amount_minor = $2
+  AND sl.posted_on BETWEEN sqlc.arg('from_date') AND sqlc.arg('to_date')
+  AND NOT EXISTS (
+    SELECT 1 FROM receipt_scans rs
+    WHERE rs.statement_line_id = sl.id
+  )
+ORDER BY sl.posted_on, sl.id;
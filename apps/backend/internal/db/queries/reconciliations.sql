@@ -0,0 +1,158 @@
+-- name: CreateReconciliation :one
+INSERT INTO reconciliations (
+  organisation_id,
+  account_id,
+  starts_on,
+  ends_on,
+  closing_balance_minor,
+  created_by,
+  opening_balance_minor
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7
+)
+RETURNING *;
+
+-- name: GetReconciliation :one
+SELECT * FROM reconciliations
+WHERE organisation_id = $1 AND id = $2;
+
+-- name: GetReconciliationForUpdate :one
+-- Serialises changes to one session's lines.
+SELECT * FROM reconciliations
+WHERE organisation_id = $1 AND id = $2
+FOR UPDATE;
+
+-- name: GetPreviousClosingBalance :one
+-- The closing balance of the account's latest statement before starts_on,
+-- which the next statement opens with.
+SELECT closing_balance_minor FROM reconciliations
+WHERE organisation_id = $1
+  AND account_id = $2
+  AND ends_on < sqlc.arg('starts_on')::date
+ORDER BY ends_on DESC, created_at DESC
+LIMIT 1;
+
+-- name: ListReconciliations :many
+SELECT * FROM reconciliations
+WHERE organisation_id = $1
+  AND (sqlc.narg('account_id')::uuid IS NULL OR account_id = sqlc.narg('account_id'))
+ORDER BY ends_on DESC, created_at DESC;
+
+-- name: CompleteReconciliation :one
+UPDATE reconciliations
+SET status = 'completed',
+    completed_at = now()
+WHERE organisation_id = $1 AND id = $2
+RETURNING *;
+
+-- name: CreateStatementLine :one
+INSERT INTO statement_lines (
+  organisation_id,
+  reconciliation_id,
+  posted_on,
+  amount_minor,
+  description,
+  reference
+) VALUES (
+  $1, $2, $3, $4, $5, $6
+)
+RETURNING *;
+
+-- name: GetStatementLine :one
+SELECT * FROM statement_lines
+WHERE organisation_id = $1 AND reconciliation_id = $2 AND id = $3;
+
+-- name: ListStatementLines :many
+SELECT * FROM statement_lines
+WHERE organisation_id = $1 AND reconciliation_id = $2
+ORDER BY posted_on, created_at, id;
+
+-- name: SetStatementLineMatch :one
+UPDATE statement_lines
+SET match_status = $3,
+    ledger_entry_id = $4,
+    match_score = $5
+WHERE organisation_id = $1 AND id = $2
+RETURNING *;
+
+-- name: RejectStatementLineMatch :one
+-- Clears the match and remembers the entry so the matcher skips it.
+UPDATE statement_lines
+SET match_status = 'unmatched',
+    rejected_entry_ids = array_append(rejected_entry_ids, ledger_entry_id),
+    ledger_entry_id = NULL,
+    match_score = NULL
+WHERE organisation_id = $1 AND id = $2 AND ledger_entry_id IS NOT NULL
+RETURNING *;
+
+-- name: ClearSuggestedMatches :exec
+UPDATE statement_lines
+SET match_status = 'unmatched',
+    ledger_entry_id = NULL,
+    match_score = NULL
+WHERE organisation_id = $1 AND reconciliation_id = $2 AND match_status = 'suggested';
+
+-- name: ListMatchCandidates :many
+-- Entries on the account dated within the range that no statement line has
+-- claimed yet.
+SELECT
+  le.id,
+  t.posted_on,
+  le.amount_minor,
+  COALESCE(t.description, '')::text AS description
+FROM ledger_entries le
+JOIN transactions t
+  ON t.organisation_id = le.organisation_id AND t.id = le.transaction_id
+WHERE le.organisation_id = $1
+  AND le.account_id = $2
+  AND t.posted_on >= sqlc.arg('from_date')::date
+  AND t.posted_on <= sqlc.arg('to_date')::date
+  AND NOT EXISTS (
+    SELECT 1 FROM statement_lines sl WHERE sl.ledger_entry_id = le.id
+  )
+ORDER BY t.posted_on, le.id;
+
+-- name: GetAccountLedgerEntry :one
+SELECT
+  le.id,
+  t.posted_on,
+  le.amount_minor,
+  COALESCE(t.description, '')::text AS description
+FROM ledger_entries le
+JOIN transactions t
+  ON t.organisation_id = le.organisation_id AND t.id = le.transaction_id
+WHERE le.organisation_id = $1
+  AND le.account_id = $2
+  AND le.id = $3;
+
+-- name: GetClearedBalance :one
+-- The statement's opening balance plus the entries matched to its lines.
+SELECT (r.opening_balance_minor + COALESCE(SUM(le.amount_minor), 0))::bigint AS balance_minor
+FROM reconciliations r
+LEFT JOIN statement_lines sl
+  ON sl.organisation_id = r.organisation_id
+  AND sl.reconciliation_id = r.id
+  AND sl.match_status = 'matched'
+LEFT JOIN ledger_entries le
+  ON le.id = sl.ledger_entry_id
+WHERE r.organisation_id = $1 AND r.id = $2
+GROUP BY r.opening_balance_minor;
+
+-- name: ListUnclearedEntries :many
+-- Entries on the account up to the statement date without an accepted match.
+SELECT
+  le.id,
+  t.posted_on,
+  le.amount_minor,
+  COALESCE(t.description, '')::text AS description
+FROM ledger_entries le
+JOIN transactions t
+  ON t.organisation_id = le.organisation_id AND t.id = le.transaction_id
+WHERE le.organisation_id = $1
+  AND le.account_id = $2
+  AND t.posted_on <= sqlc.arg('ends_on')::date
+  AND NOT EXISTS (
+    SELECT 1 FROM statement_lines sl
+    WHERE sl.ledger_entry_id = le.id AND sl.match_status = 'matched'
+  )
+ORDER BY t.posted_on, le.id;
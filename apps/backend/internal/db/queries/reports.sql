@@ -0,0 +1,40 @@
+-- name: ReportAccountTotals :many
+-- Net movement per account over the accounting dates, optionally split by a
+-- tag, by the options of one tracking category or by contact. Entries without
+-- a tag, option or contact fall in a group with a NULL key; an entry with
+-- several tags counts under each of them.
+SELECT
+  a.id AS account_id,
+  a.name AS account_name,
+  a.type AS account_type,
+  le.currency,
+  g.group_key,
+  COALESCE(SUM(le.amount_minor), 0)::bigint AS total_minor
+FROM ledger_entries le
+JOIN transactions t
+  ON t.organisation_id = le.organisation_id AND t.id = le.transaction_id
+JOIN accounts a
+  ON a.organisation_id = le.organisation_id AND a.id = le.account_id
+LEFT JOIN LATERAL (
+  SELECT NULL::text AS group_key
+  WHERE sqlc.arg('group_by')::text = 'account'
+  UNION ALL
+  SELECT tg.tag
+  FROM (SELECT 1) one
+  LEFT JOIN ledger_entry_tags tg ON tg.ledger_entry_id = le.id
+  WHERE sqlc.arg('group_by')::text = 'tag'
+  UNION ALL
+  SELECT tr.option_id::text
+  FROM (SELECT 1) one
+  LEFT JOIN ledger_entry_tracking tr
+    ON tr.ledger_entry_id = le.id AND tr.category_id = sqlc.narg('category_id')::uuid
+  WHERE sqlc.arg('group_by')::text = 'tracking'
+  UNION ALL
+  SELECT t.contact_id::text
+  WHERE sqlc.arg('group_by')::text = 'contact'
+) g ON true
+WHERE le.organisation_id = sqlc.arg('organisation_id')
+  AND (sqlc.narg('start_date')::date IS NULL OR t.posted_on >= sqlc.narg('start_date'))
+  AND (sqlc.narg('end_date')::date IS NULL OR t.posted_on <= sqlc.narg('end_date'))
+GROUP BY a.id, a.name, a.type, le.currency, g.group_key
+ORDER BY a.type, a.name, a.id, g.group_key NULLS LAST;
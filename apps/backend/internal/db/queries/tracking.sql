@@ -0,0 +1,95 @@
+-- name: CreateTrackingCategory :one
+INSERT INTO tracking_categories (
+  organisation_id,
+  name
+) VALUES (
+  $1, $2
+)
+RETURNING *;
+
+-- name: GetTrackingCategory :one
+SELECT * FROM tracking_categories
+WHERE organisation_id = $1 AND id = $2;
+
+-- name: ListTrackingCategories :many
+SELECT * FROM tracking_categories
+WHERE organisation_id = $1
+ORDER BY name;
+
+-- name: UpdateTrackingCategory :one
+UPDATE tracking_categories
+SET name = $3
+WHERE organisation_id = $1 AND id = $2
+RETURNING *;
+
+-- name: DeleteTrackingCategory :exec
+DELETE FROM tracking_categories
+WHERE organisation_id = $1 AND id = $2;
+
+-- name: CreateTrackingOption :one
+INSERT INTO tracking_options (
+  organisation_id,
+  category_id,
+  name
+) VALUES (
+  $1, $2, $3
+)
+RETURNING *;
+
+-- name: ListTrackingOptions :many
+SELECT * FROM tracking_options
+WHERE organisation_id = $1
+  AND (sqlc.narg('category_id')::uuid IS NULL OR category_id = sqlc.narg('category_id'))
+ORDER BY name;
+
+-- name: UpdateTrackingOption :one
+UPDATE tracking_options
+SET
+  name = COALESCE(sqlc.narg('name'), name),
+  active = COALESCE(sqlc.narg('active'), active)
+WHERE organisation_id = $1 AND category_id = $2 AND id = $3
+RETURNING *;
+
+-- name: DeleteTrackingOption :execrows
+DELETE FROM tracking_options
+WHERE organisation_id = $1 AND category_id = $2 AND id = $3;
+
+-- name: GetTrackingOptionsByIDs :many
+SELECT * FROM tracking_options
+WHERE organisation_id = $1 AND id = ANY(sqlc.arg('ids')::uuid[]);
+
+-- name: CreateLedgerEntryTags :exec
+INSERT INTO ledger_entry_tags (
+  organisation_id,
+  ledger_entry_id,
+  tag
+)
+SELECT sqlc.arg('organisation_id')::uuid, t.ledger_entry_id, t.tag
+FROM unnest(
+  sqlc.arg('ledger_entry_ids')::uuid[],
+  sqlc.arg('tags')::text[]
+) AS t(ledger_entry_id, tag);
+
+-- name: CreateLedgerEntryTracking :exec
+INSERT INTO ledger_entry_tracking (
+  organisation_id,
+  ledger_entry_id,
+  category_id,
+  option_id
+)
+SELECT sqlc.arg('organisation_id')::uuid, t.ledger_entry_id, t.category_id, t.option_id
+FROM unnest(
+  sqlc.arg('ledger_entry_ids')::uuid[],
+  sqlc.arg('category_ids')::uuid[],
+  sqlc.arg('option_ids')::uuid[]
+) AS t(ledger_entry_id, category_id, option_id);
+
+-- name: ListLedgerEntryTags :many
+SELECT ledger_entry_id, tag FROM ledger_entry_tags
+WHERE organisation_id = $1 AND ledger_entry_id = ANY(sqlc.arg('ledger_entry_ids')::uuid[])
+ORDER BY ledger_entry_id, tag;
+
+-- name: ListLedgerEntryTracking :many
+SELECT ledger_entry_id, category_id, option_id FROM ledger_entry_tracking
+WHERE organisation_id = $1 AND ledger_entry_id = ANY(sqlc.arg('ledger_entry_ids')::uuid[])
+ORDER BY ledger_entry_id, category_id;
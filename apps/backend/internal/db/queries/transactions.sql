@@ -1,47 +1,549 @@
 -- name: CreateTransaction :one
 INSERT INTO transactions (
+  organisation_id,
   idempotency_key,
   description,
   source,
-  posted_at
+  posted_at,
+  request_hash,
+  posted_on,
+  corrects_entry_id,
+  contact_id,
+  bill_id
 ) VALUES (
-  $1, $2, $3, $4
+  $1, $2, $3, $4, $5, $6, $7, $8, $9, $10
 )
 RETURNING *;
 
 -- name: CreateLedgerEntry :one
 INSERT INTO ledger_entries (
+  organisation_id,
   transaction_id,
   account_id,
   amount_minor,
   currency
 ) VALUES (
-  $1, $2, $3, $4
+  $1, $2, $3, $4, $5
 )
 RETURNING *;
 
 -- name: GetTransaction :one
 SELECT * FROM transactions
-WHERE id = $1 LIMIT 1;
+WHERE organisation_id = $1 AND id = $2 LIMIT 1;
 
 -- name: GetTransactionByIdempotencyKey :one
 SELECT * FROM transactions
-WHERE idempotency_key = $1 LIMIT 1;
+WHERE organisation_id = $1 AND idempotency_key = $2 LIMIT 1;
 
 -- name: ListTransactions :many
-SELECT * FROM transactions t
-WHERE 
-  (sqlc.narg('account_id')::uuid IS NULL OR EXISTS (
+-- Sorted newest posted first. Every sort order GET /transactions offers is a
+-- query of its own below, with the same filters, so the planner can read the
+-- order off the organisation's posted_on, posted_at or created_at index.
+-- transaction_amount only runs when an amount filter or order is asked for.
+SELECT t.* FROM transactions t
+WHERE
+  t.organisation_id = sqlc.arg('organisation_id')
+  AND (sqlc.narg('account_id')::uuid IS NULL OR EXISTS (
     SELECT 1 FROM ledger_entries le 
     WHERE le.transaction_id = t.id 
     AND le.account_id = sqlc.narg('account_id')
   ))
-  AND (sqlc.narg('start_date')::timestamptz IS NULL OR t.posted_at >= sqlc.narg('start_date'))
-  AND (sqlc.narg('end_date')::timestamptz IS NULL OR t.posted_at <= sqlc.narg('end_date'))
-ORDER BY posted_at DESC, created_at DESC
+  AND (sqlc.narg('start_date')::date IS NULL OR t.posted_on >= sqlc.narg('start_date'))
+  AND (sqlc.narg('end_date')::date IS NULL OR t.posted_on <= sqlc.narg('end_date'))
+  AND (sqlc.narg('tag')::text IS NULL OR EXISTS (
+    SELECT 1 FROM ledger_entries le
+    JOIN ledger_entry_tags tg ON tg.ledger_entry_id = le.id
+    WHERE le.transaction_id = t.id
+    AND tg.tag = sqlc.narg('tag')
+  ))
+  AND (sqlc.narg('tracking_option_id')::uuid IS NULL OR EXISTS (
+    SELECT 1 FROM ledger_entries le
+    JOIN ledger_entry_tracking tr ON tr.ledger_entry_id = le.id
+    WHERE le.transaction_id = t.id
+    AND tr.option_id = sqlc.narg('tracking_option_id')
+  ))
+  AND (sqlc.narg('source')::text IS NULL OR t.source = sqlc.narg('source'))
+  AND (sqlc.narg('min_amount')::bigint IS NULL
+    OR transaction_amount(t.id, sqlc.narg('account_id')) >= sqlc.narg('min_amount'))
+  AND (sqlc.narg('max_amount')::bigint IS NULL
+    OR transaction_amount(t.id, sqlc.narg('account_id')) <= sqlc.narg('max_amount'))
+  AND (sqlc.narg('description_contains')::text IS NULL
+    OR COALESCE(t.description, '') ILIKE '%' || sqlc.narg('description_contains') || '%')
+  AND (sqlc.narg('counterparty_account_id')::uuid IS NULL OR EXISTS (
+    SELECT 1 FROM ledger_entries le
+    WHERE le.transaction_id = t.id
+    AND le.account_id = sqlc.narg('counterparty_account_id')
+    AND le.account_id IS DISTINCT FROM sqlc.narg('account_id')
+  ))
+  AND (sqlc.narg('contact_id')::uuid IS NULL OR t.contact_id = sqlc.narg('contact_id'))
+  AND (sqlc.narg('has_tag')::boolean IS NULL OR EXISTS (
+    SELECT 1 FROM ledger_entries le
+    JOIN ledger_entry_tags tg ON tg.ledger_entry_id = le.id
+    WHERE le.transaction_id = t.id
+  ) = sqlc.narg('has_tag'))
+ORDER BY t.posted_on DESC, t.created_at DESC, t.id
+LIMIT $1 OFFSET $2;
+
+-- name: ListTransactionsPostedOnAsc :many
+SELECT t.* FROM transactions t
+WHERE
+  t.organisation_id = sqlc.arg('organisation_id')
+  AND (sqlc.narg('account_id')::uuid IS NULL OR EXISTS (
+    SELECT 1 FROM ledger_entries le 
+    WHERE le.transaction_id = t.id 
+    AND le.account_id = sqlc.narg('account_id')
+  ))
+  AND (sqlc.narg('start_date')::date IS NULL OR t.posted_on >= sqlc.narg('start_date'))
+  AND (sqlc.narg('end_date')::date IS NULL OR t.posted_on <= sqlc.narg('end_date'))
+  AND (sqlc.narg('tag')::text IS NULL OR EXISTS (
+    SELECT 1 FROM ledger_entries le
+    JOIN ledger_entry_tags tg ON tg.ledger_entry_id = le.id
+    WHERE le.transaction_id = t.id
+    AND tg.tag = sqlc.narg('tag')
+  ))
+  AND (sqlc.narg('tracking_option_id')::uuid IS NULL OR EXISTS (
+    SELECT 1 FROM ledger_entries le
+    JOIN ledger_entry_tracking tr ON tr.ledger_entry_id = le.id
+    WHERE le.transaction_id = t.id
+    AND tr.option_id = sqlc.narg('tracking_option_id')
+  ))
+  AND (sqlc.narg('source')::text IS NULL OR t.source = sqlc.narg('source'))
+  AND (sqlc.narg('min_amount')::bigint IS NULL
+    OR transaction_amount(t.id, sqlc.narg('account_id')) >= sqlc.narg('min_amount'))
+  AND (sqlc.narg('max_amount')::bigint IS NULL
+    OR transaction_amount(t.id, sqlc.narg('account_id')) <= sqlc.narg('max_amount'))
+  AND (sqlc.narg('description_contains')::text IS NULL
+    OR COALESCE(t.description, '') ILIKE '%' || sqlc.narg('description_contains') || '%')
+  AND (sqlc.narg('counterparty_account_id')::uuid IS NULL OR EXISTS (
+    SELECT 1 FROM ledger_entries le
+    WHERE le.transaction_id = t.id
+    AND le.account_id = sqlc.narg('counterparty_account_id')
+    AND le.account_id IS DISTINCT FROM sqlc.narg('account_id')
+  ))
+  AND (sqlc.narg('contact_id')::uuid IS NULL OR t.contact_id = sqlc.narg('contact_id'))
+  AND (sqlc.narg('has_tag')::boolean IS NULL OR EXISTS (
+    SELECT 1 FROM ledger_entries le
+    JOIN ledger_entry_tags tg ON tg.ledger_entry_id = le.id
+    WHERE le.transaction_id = t.id
+  ) = sqlc.narg('has_tag'))
+ORDER BY t.posted_on ASC, t.created_at ASC, t.id
+LIMIT $1 OFFSET $2;
+
+-- name: ListTransactionsPostedAtDesc :many
+SELECT t.* FROM transactions t
+WHERE
+  t.organisation_id = sqlc.arg('organisation_id')
+  AND (sqlc.narg('account_id')::uuid IS NULL OR EXISTS (
+    SELECT 1 FROM ledger_entries le 
+    WHERE le.transaction_id = t.id 
+    AND le.account_id = sqlc.narg('account_id')
+  ))
+  AND (sqlc.narg('start_date')::date IS NULL OR t.posted_on >= sqlc.narg('start_date'))
+  AND (sqlc.narg('end_date')::date IS NULL OR t.posted_on <= sqlc.narg('end_date'))
+  AND (sqlc.narg('tag')::text IS NULL OR EXISTS (
+    SELECT 1 FROM ledger_entries le
+    JOIN ledger_entry_tags tg ON tg.ledger_entry_id = le.id
+    WHERE le.transaction_id = t.id
+    AND tg.tag = sqlc.narg('tag')
+  ))
+  AND (sqlc.narg('tracking_option_id')::uuid IS NULL OR EXISTS (
+    SELECT 1 FROM ledger_entries le
+    JOIN ledger_entry_tracking tr ON tr.ledger_entry_id = le.id
+    WHERE le.transaction_id = t.id
+    AND tr.option_id = sqlc.narg('tracking_option_id')
+  ))
+  AND (sqlc.narg('source')::text IS NULL OR t.source = sqlc.narg('source'))
+  AND (sqlc.narg('min_amount')::bigint IS NULL
+    OR transaction_amount(t.id, sqlc.narg('account_id')) >= sqlc.narg('min_amount'))
+  AND (sqlc.narg('max_amount')::bigint IS NULL
+    OR transaction_amount(t.id, sqlc.narg('account_id')) <= sqlc.narg('max_amount'))
+  AND (sqlc.narg('description_contains')::text IS NULL
+    OR COALESCE(t.description, '') ILIKE '%' || sqlc.narg('description_contains') || '%')
+  AND (sqlc.narg('counterparty_account_id')::uuid IS NULL OR EXISTS (
+    SELECT 1 FROM ledger_entries le
+    WHERE le.transaction_id = t.id
+    AND le.account_id = sqlc.narg('counterparty_account_id')
+    AND le.account_id IS DISTINCT FROM sqlc.narg('account_id')
+  ))
+  AND (sqlc.narg('contact_id')::uuid IS NULL OR t.contact_id = sqlc.narg('contact_id'))
+  AND (sqlc.narg('has_tag')::boolean IS NULL OR EXISTS (
+    SELECT 1 FROM ledger_entries le
+    JOIN ledger_entry_tags tg ON tg.ledger_entry_id = le.id
+    WHERE le.transaction_id = t.id
+  ) = sqlc.narg('has_tag'))
+ORDER BY t.posted_at DESC NULLS LAST, t.created_at DESC, t.id
+LIMIT $1 OFFSET $2;
+
+-- name: ListTransactionsPostedAtAsc :many
+SELECT t.* FROM transactions t
+WHERE
+  t.organisation_id = sqlc.arg('organisation_id')
+  AND (sqlc.narg('account_id')::uuid IS NULL OR EXISTS (
+    SELECT 1 FROM ledger_entries le 
+    WHERE le.transaction_id = t.id 
+    AND le.account_id = sqlc.narg('account_id')
+  ))
+  AND (sqlc.narg('start_date')::date IS NULL OR t.posted_on >= sqlc.narg('start_date'))
+  AND (sqlc.narg('end_date')::date IS NULL OR t.posted_on <= sqlc.narg('end_date'))
+  AND (sqlc.narg('tag')::text IS NULL OR EXISTS (
+    SELECT 1 FROM ledger_entries le
+    JOIN ledger_entry_tags tg ON tg.ledger_entry_id = le.id
+    WHERE le.transaction_id = t.id
+    AND tg.tag = sqlc.narg('tag')
+  ))
+  AND (sqlc.narg('tracking_option_id')::uuid IS NULL OR EXISTS (
+    SELECT 1 FROM ledger_entries le
+    JOIN ledger_entry_tracking tr ON tr.ledger_entry_id = le.id
+    WHERE le.transaction_id = t.id
+    AND tr.option_id = sqlc.narg('tracking_option_id')
+  ))
+  AND (sqlc.narg('source')::text IS NULL OR t.source = sqlc.narg('source'))
+  AND (sqlc.narg('min_amount')::bigint IS NULL
+    OR transaction_amount(t.id, sqlc.narg('account_id')) >= sqlc.narg('min_amount'))
+  AND (sqlc.narg('max_amount')::bigint IS NULL
+    OR transaction_amount(t.id, sqlc.narg('account_id')) <= sqlc.narg('max_amount'))
+  AND (sqlc.narg('description_contains')::text IS NULL
+    OR COALESCE(t.description, '') ILIKE '%' || sqlc.narg('description_contains') || '%')
+  AND (sqlc.narg('counterparty_account_id')::uuid IS NULL OR EXISTS (
+    SELECT 1 FROM ledger_entries le
+    WHERE le.transaction_id = t.id
+    AND le.account_id = sqlc.narg('counterparty_account_id')
+    AND le.account_id IS DISTINCT FROM sqlc.narg('account_id')
+  ))
+  AND (sqlc.narg('contact_id')::uuid IS NULL OR t.contact_id = sqlc.narg('contact_id'))
+  AND (sqlc.narg('has_tag')::boolean IS NULL OR EXISTS (
+    SELECT 1 FROM ledger_entries le
+    JOIN ledger_entry_tags tg ON tg.ledger_entry_id = le.id
+    WHERE le.transaction_id = t.id
+  ) = sqlc.narg('has_tag'))
+ORDER BY t.posted_at ASC NULLS LAST, t.created_at ASC, t.id
+LIMIT $1 OFFSET $2;
+
+-- name: ListTransactionsAmountDesc :many
+SELECT t.* FROM transactions t
+WHERE
+  t.organisation_id = sqlc.arg('organisation_id')
+  AND (sqlc.narg('account_id')::uuid IS NULL OR EXISTS (
+    SELECT 1 FROM ledger_entries le 
+    WHERE le.transaction_id = t.id 
+    AND le.account_id = sqlc.narg('account_id')
+  ))
+  AND (sqlc.narg('start_date')::date IS NULL OR t.posted_on >= sqlc.narg('start_date'))
+  AND (sqlc.narg('end_date')::date IS NULL OR t.posted_on <= sqlc.narg('end_date'))
+  AND (sqlc.narg('tag')::text IS NULL OR EXISTS (
+    SELECT 1 FROM ledger_entries le
+    JOIN ledger_entry_tags tg ON tg.ledger_entry_id = le.id
+    WHERE le.transaction_id = t.id
+    AND tg.tag = sqlc.narg('tag')
+  ))
+  AND (sqlc.narg('tracking_option_id')::uuid IS NULL OR EXISTS (
+    SELECT 1 FROM ledger_entries le
+    JOIN ledger_entry_tracking tr ON tr.ledger_entry_id = le.id
+    WHERE le.transaction_id = t.id
+    AND tr.option_id = sqlc.narg('tracking_option_id')
+  ))
+  AND (sqlc.narg('source')::text IS NULL OR t.source = sqlc.narg('source'))
+  AND (sqlc.narg('min_amount')::bigint IS NULL
+    OR transaction_amount(t.id, sqlc.narg('account_id')) >= sqlc.narg('min_amount'))
+  AND (sqlc.narg('max_amount')::bigint IS NULL
+    OR transaction_amount(t.id, sqlc.narg('account_id')) <= sqlc.narg('max_amount'))
+  AND (sqlc.narg('description_contains')::text IS NULL
+    OR COALESCE(t.description, '') ILIKE '%' || sqlc.narg('description_contains') || '%')
+  AND (sqlc.narg('counterparty_account_id')::uuid IS NULL OR EXISTS (
+    SELECT 1 FROM ledger_entries le
+    WHERE le.transaction_id = t.id
+    AND le.account_id = sqlc.narg('counterparty_account_id')
+    AND le.account_id IS DISTINCT FROM sqlc.narg('account_id')
+  ))
+  AND (sqlc.narg('contact_id')::uuid IS NULL OR t.contact_id = sqlc.narg('contact_id'))
+  AND (sqlc.narg('has_tag')::boolean IS NULL OR EXISTS (
+    SELECT 1 FROM ledger_entries le
+    JOIN ledger_entry_tags tg ON tg.ledger_entry_id = le.id
+    WHERE le.transaction_id = t.id
+  ) = sqlc.narg('has_tag'))
+ORDER BY transaction_amount(t.id, sqlc.narg('account_id')) DESC, t.created_at DESC, t.id
+LIMIT $1 OFFSET $2;
+
+-- name: ListTransactionsAmountAsc :many
+SELECT t.* FROM transactions t
+WHERE
+  t.organisation_id = sqlc.arg('organisation_id')
+  AND (sqlc.narg('account_id')::uuid IS NULL OR EXISTS (
+    SELECT 1 FROM ledger_entries le 
+    WHERE le.transaction_id = t.id 
+    AND le.account_id = sqlc.narg('account_id')
+  ))
+  AND (sqlc.narg('start_date')::date IS NULL OR t.posted_on >= sqlc.narg('start_date'))
+  AND (sqlc.narg('end_date')::date IS NULL OR t.posted_on <= sqlc.narg('end_date'))
+  AND (sqlc.narg('tag')::text IS NULL OR EXISTS (
+    SELECT 1 FROM ledger_entries le
+    JOIN ledger_entry_tags tg ON tg.ledger_entry_id = le.id
+    WHERE le.transaction_id = t.id
+    AND tg.tag = sqlc.narg('tag')
+  ))
+  AND (sqlc.narg('tracking_option_id')::uuid IS NULL OR EXISTS (
+    SELECT 1 FROM ledger_entries le
+    JOIN ledger_entry_tracking tr ON tr.ledger_entry_id = le.id
+    WHERE le.transaction_id = t.id
+    AND tr.option_id = sqlc.narg('tracking_option_id')
+  ))
+  AND (sqlc.narg('source')::text IS NULL OR t.source = sqlc.narg('source'))
+  AND (sqlc.narg('min_amount')::bigint IS NULL
+    OR transaction_amount(t.id, sqlc.narg('account_id')) >= sqlc.narg('min_amount'))
+  AND (sqlc.narg('max_amount')::bigint IS NULL
+    OR transaction_amount(t.id, sqlc.narg('account_id')) <= sqlc.narg('max_amount'))
+  AND (sqlc.narg('description_contains')::text IS NULL
+    OR COALESCE(t.description, '') ILIKE '%' || sqlc.narg('description_contains') || '%')
+  AND (sqlc.narg('counterparty_account_id')::uuid IS NULL OR EXISTS (
+    SELECT 1 FROM ledger_entries le
+    WHERE le.transaction_id = t.id
+    AND le.account_id = sqlc.narg('counterparty_account_id')
+    AND le.account_id IS DISTINCT FROM sqlc.narg('account_id')
+  ))
+  AND (sqlc.narg('contact_id')::uuid IS NULL OR t.contact_id = sqlc.narg('contact_id'))
+  AND (sqlc.narg('has_tag')::boolean IS NULL OR EXISTS (
+    SELECT 1 FROM ledger_entries le
+    JOIN ledger_entry_tags tg ON tg.ledger_entry_id = le.id
+    WHERE le.transaction_id = t.id
+  ) = sqlc.narg('has_tag'))
+ORDER BY transaction_amount(t.id, sqlc.narg('account_id')) ASC, t.created_at ASC, t.id
 LIMIT $1 OFFSET $2;
 
+-- name: ListTransactionsCreatedAtDesc :many
+SELECT t.* FROM transactions t
+WHERE
+  t.organisation_id = sqlc.arg('organisation_id')
+  AND (sqlc.narg('account_id')::uuid IS NULL OR EXISTS (
+    SELECT 1 FROM ledger_entries le 
+    WHERE le.transaction_id = t.id 
+    AND le.account_id = sqlc.narg('account_id')
+  ))
+  AND (sqlc.narg('start_date')::date IS NULL OR t.posted_on >= sqlc.narg('start_date'))
+  AND (sqlc.narg('end_date')::date IS NULL OR t.posted_on <= sqlc.narg('end_date'))
+  AND (sqlc.narg('tag')::text IS NULL OR EXISTS (
+    SELECT 1 FROM ledger_entries le
+    JOIN ledger_entry_tags tg ON tg.ledger_entry_id = le.id
+    WHERE le.transaction_id = t.id
+    AND tg.tag = sqlc.narg('tag')
+  ))
+  AND (sqlc.narg('tracking_option_id')::uuid IS NULL OR EXISTS (
+    SELECT 1 FROM ledger_entries le
+    JOIN ledger_entry_tracking tr ON tr.ledger_entry_id = le.id
+    WHERE le.transaction_id = t.id
+    AND tr.option_id = sqlc.narg('tracking_option_id')
+  ))
+  AND (sqlc.narg('source')::text IS NULL OR t.source = sqlc.narg('source'))
+  AND (sqlc.narg('min_amount')::bigint IS NULL
+    OR transaction_amount(t.id, sqlc.narg('account_id')) >= sqlc.narg('min_amount'))
+  AND (sqlc.narg('max_amount')::bigint IS NULL
+    OR transaction_amount(t.id, sqlc.narg('account_id')) <= sqlc.narg('max_amount'))
+  AND (sqlc.narg('description_contains')::text IS NULL
+    OR COALESCE(t.description, '') ILIKE '%' || sqlc.narg('description_contains') || '%')
+  AND (sqlc.narg('counterparty_account_id')::uuid IS NULL OR EXISTS (
+    SELECT 1 FROM ledger_entries le
+    WHERE le.transaction_id = t.id
+    AND le.account_id = sqlc.narg('counterparty_account_id')
+    AND le.account_id IS DISTINCT FROM sqlc.narg('account_id')
+  ))
+  AND (sqlc.narg('contact_id')::uuid IS NULL OR t.contact_id = sqlc.narg('contact_id'))
+  AND (sqlc.narg('has_tag')::boolean IS NULL OR EXISTS (
+    SELECT 1 FROM ledger_entries le
+    JOIN ledger_entry_tags tg ON tg.ledger_entry_id = le.id
+    WHERE le.transaction_id = t.id
+  ) = sqlc.narg('has_tag'))
+ORDER BY t.created_at DESC, t.id
+LIMIT $1 OFFSET $2;
+
+-- name: ListTransactionsCreatedAtAsc :many
+SELECT t.* FROM transactions t
+WHERE
+  t.organisation_id = sqlc.arg('organisation_id')
+  AND (sqlc.narg('account_id')::uuid IS NULL OR EXISTS (
+    SELECT 1 FROM ledger_entries le 
+    WHERE le.transaction_id = t.id 
+    AND le.account_id = sqlc.narg('account_id')
+  ))
+  AND (sqlc.narg('start_date')::date IS NULL OR t.posted_on >= sqlc.narg('start_date'))
+  AND (sqlc.narg('end_date')::date IS NULL OR t.posted_on <= sqlc.narg('end_date'))
+  AND (sqlc.narg('tag')::text IS NULL OR EXISTS (
+    SELECT 1 FROM ledger_entries le
+    JOIN ledger_entry_tags tg ON tg.ledger_entry_id = le.id
+    WHERE le.transaction_id = t.id
+    AND tg.tag = sqlc.narg('tag')
+  ))
+  AND (sqlc.narg('tracking_option_id')::uuid IS NULL OR EXISTS (
+    SELECT 1 FROM ledger_entries le
+    JOIN ledger_entry_tracking tr ON tr.ledger_entry_id = le.id
+    WHERE le.transaction_id = t.id
+    AND tr.option_id = sqlc.narg('tracking_option_id')
+  ))
+  AND (sqlc.narg('source')::text IS NULL OR t.source = sqlc.narg('source'))
+  AND (sqlc.narg('min_amount')::bigint IS NULL
+    OR transaction_amount(t.id, sqlc.narg('account_id')) >= sqlc.narg('min_amount'))
+  AND (sqlc.narg('max_amount')::bigint IS NULL
+    OR transaction_amount(t.id, sqlc.narg('account_id')) <= sqlc.narg('max_amount'))
+  AND (sqlc.narg('description_contains')::text IS NULL
+    OR COALESCE(t.description, '') ILIKE '%' || sqlc.narg('description_contains') || '%')
+  AND (sqlc.narg('counterparty_account_id')::uuid IS NULL OR EXISTS (
+    SELECT 1 FROM ledger_entries le
+    WHERE le.transaction_id = t.id
+    AND le.account_id = sqlc.narg('counterparty_account_id')
+    AND le.account_id IS DISTINCT FROM sqlc.narg('account_id')
+  ))
+  AND (sqlc.narg('contact_id')::uuid IS NULL OR t.contact_id = sqlc.narg('contact_id'))
+  AND (sqlc.narg('has_tag')::boolean IS NULL OR EXISTS (
+    SELECT 1 FROM ledger_entries le
+    JOIN ledger_entry_tags tg ON tg.ledger_entry_id = le.id
+    WHERE le.transaction_id = t.id
+  ) = sqlc.narg('has_tag'))
+ORDER BY t.created_at ASC, t.id
+LIMIT $1 OFFSET $2;
+
+-- name: SearchTransactions :many
+-- Free text matches the description's words, or failing that trigram word
+-- similarity, so misspellings still find the transaction. Amount terms must
+-- all hold for one ledger entry; tag terms must all appear on the
+-- transaction's entries. The headline marks matched words with chr(2) and
+-- chr(3) for the caller to escape and highlight.
+SELECT
+  t.id, t.idempotency_key, t.description, t.source, t.posted_at, t.created_at,
+  t.request_hash, t.organisation_id, t.posted_on, t.corrects_entry_id, t.contact_id, t.bill_id,
+  (ts_rank(to_tsvector('english', COALESCE(t.description, '')), s.query)
+    + word_similarity(sqlc.arg('text')::text, COALESCE(t.description, '')))::float8 AS rank,
+  ts_headline('english', COALESCE(t.description, ''), s.query,
+    'HighlightAll=true, StartSel=' || chr(2) || ', StopSel=' || chr(3))::text AS headline
+FROM transactions t
+CROSS JOIN (SELECT websearch_to_tsquery('english', sqlc.arg('text')::text) AS query) s
+WHERE
+  t.organisation_id = sqlc.arg('organisation_id')
+  AND (sqlc.arg('text')::text = ''
+    OR to_tsvector('english', COALESCE(t.description, '')) @@ s.query
+    OR sqlc.arg('text')::text <% COALESCE(t.description, ''))
+  AND (sqlc.narg('account_id')::uuid IS NULL OR EXISTS (
+    SELECT 1 FROM ledger_entries le
+    WHERE le.transaction_id = t.id
+    AND le.account_id = sqlc.narg('account_id')
+  ))
+  AND (sqlc.narg('start_date')::date IS NULL OR t.posted_on >= sqlc.narg('start_date'))
+  AND (sqlc.narg('end_date')::date IS NULL OR t.posted_on <= sqlc.narg('end_date'))
+  AND (sqlc.narg('tracking_option_id')::uuid IS NULL OR EXISTS (
+    SELECT 1 FROM ledger_entries le
+    JOIN ledger_entry_tracking tr ON tr.ledger_entry_id = le.id
+    WHERE le.transaction_id = t.id
+    AND tr.option_id = sqlc.narg('tracking_option_id')
+  ))
+  AND (cardinality(sqlc.arg('tags')::text[]) = 0 OR (
+    SELECT count(DISTINCT tg.tag) FROM ledger_entries le
+    JOIN ledger_entry_tags tg ON tg.ledger_entry_id = le.id
+    WHERE le.transaction_id = t.id
+    AND tg.tag = ANY(sqlc.arg('tags')::text[])
+  ) = cardinality(sqlc.arg('tags')::text[]))
+  AND (cardinality(sqlc.arg('amount_ops')::text[]) = 0 OR EXISTS (
+    SELECT 1 FROM ledger_entries le
+    WHERE le.transaction_id = t.id
+    AND NOT EXISTS (
+      SELECT 1 FROM unnest(
+        sqlc.arg('amount_ops')::text[],
+        sqlc.arg('amounts_minor')::bigint[]
+      ) AS f(op, amount_minor)
+      WHERE NOT CASE f.op
+        WHEN '=' THEN le.amount_minor = f.amount_minor
+        WHEN '>' THEN le.amount_minor > f.amount_minor
+        WHEN '>=' THEN le.amount_minor >= f.amount_minor
+        WHEN '<' THEN le.amount_minor < f.amount_minor
+        WHEN '<=' THEN le.amount_minor <= f.amount_minor
+      END
+    )
+  ))
+ORDER BY rank DESC, t.posted_on DESC, t.created_at DESC
+LIMIT $1 OFFSET $2;
+
+-- name: GetLedgerEntry :one
+SELECT * FROM ledger_entries
+WHERE organisation_id = $1 AND transaction_id = $2 AND id = $3 LIMIT 1;
+
 -- name: ListLedgerEntries :many
 SELECT * FROM ledger_entries
-WHERE transaction_id = $1
+WHERE organisation_id = $1 AND transaction_id = $2
 ORDER BY amount_minor DESC;
+
+-- name: ReleaseExpiredIdempotencyKey :execrows
+UPDATE transactions
+SET idempotency_key = NULL,
+    request_hash = NULL
+WHERE organisation_id = $1
+  AND idempotency_key = $2
+  AND created_at < sqlc.arg('cutoff')::timestamptz;
+
+-- name: ReleaseExpiredIdempotencyKeys :execrows
+-- Spans organisations: run it with app.rls_bypass set.
+UPDATE transactions
+SET idempotency_key = NULL,
+    request_hash = NULL
+WHERE idempotency_key IS NOT NULL
+  AND created_at < sqlc.arg('cutoff')::timestamptz;
+
+-- name: ListTransactionsByIdempotencyKeys :many
+SELECT * FROM transactions
+WHERE organisation_id = $1
+  AND idempotency_key = ANY(sqlc.arg('keys')::text[]);
+
+-- name: ReleaseExpiredIdempotencyKeysIn :execrows
+UPDATE transactions
+SET idempotency_key = NULL,
+    request_hash = NULL
+WHERE organisation_id = $1
+  AND idempotency_key = ANY(sqlc.arg('keys')::text[])
+  AND created_at < sqlc.arg('cutoff')::timestamptz;
+
+-- name: CreateTransactions :many
+-- Inserts many transactions in one statement. COPY is refused on tables with
+-- row-level security, so the rows arrive as parallel arrays instead.
+INSERT INTO transactions (
+  organisation_id,
+  idempotency_key,
+  description,
+  source,
+  posted_at,
+  request_hash,
+  posted_on,
+  contact_id
+)
+SELECT sqlc.arg('organisation_id')::uuid, t.idempotency_key, NULLIF(t.description, ''), t.source, t.posted_at, t.request_hash, t.posted_on, t.contact_id
+FROM unnest(
+  sqlc.arg('idempotency_keys')::text[],
+  sqlc.arg('descriptions')::text[],
+  sqlc.arg('sources')::text[],
+  sqlc.arg('posted_ats')::timestamptz[],
+  sqlc.arg('request_hashes')::bytea[],
+  sqlc.arg('posted_ons')::date[],
+  sqlc.arg('contact_ids')::uuid[]
+) AS t(idempotency_key, description, source, posted_at, request_hash, posted_on, contact_id)
+RETURNING *;
+
+-- name: CreateLedgerEntries :many
+-- IDs are chosen by the caller so each row can be matched to the request
+-- entry it came from.
+INSERT INTO ledger_entries (
+  id,
+  organisation_id,
+  transaction_id,
+  account_id,
+  amount_minor,
+  currency
+)
+SELECT e.id, sqlc.arg('organisation_id')::uuid, e.transaction_id, e.account_id, e.amount_minor, e.currency
+FROM unnest(
+  sqlc.arg('ids')::uuid[],
+  sqlc.arg('transaction_ids')::uuid[],
+  sqlc.arg('account_ids')::uuid[],
+  sqlc.arg('amounts_minor')::bigint[],
+  sqlc.arg('currencies')::text[]
+) AS e(id, transaction_id, account_id, amount_minor, currency)
+RETURNING *;
+
+-- name: ListLedgerEntriesForTransactions :many
+SELECT * FROM ledger_entries
+WHERE organisation_id = $1
+  AND transaction_id = ANY(sqlc.arg('transaction_ids')::uuid[])
+ORDER BY transaction_id, amount_minor DESC;
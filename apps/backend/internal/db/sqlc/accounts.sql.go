@@ -11,13 +11,69 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const accountHasLedgerEntries = `-- name: AccountHasLedgerEntries :one
+SELECT EXISTS (
+  SELECT 1 FROM ledger_entries
+  WHERE organisation_id = $1 AND account_id = $2
+) AS has_entries
+`
+
+type AccountHasLedgerEntriesParams struct {
+	OrganisationID pgtype.UUID
+	AccountID      pgtype.UUID
+}
+
+func (q *Queries) AccountHasLedgerEntries(ctx context.Context, arg AccountHasLedgerEntriesParams) (bool, error) {
+	row := q.db.QueryRow(ctx, accountHasLedgerEntries, arg.OrganisationID, arg.AccountID)
+	var has_entries bool
+	err := row.Scan(&has_entries)
+	return has_entries, err
+}
+
+const archiveAccount = `-- name: ArchiveAccount :one
+UPDATE accounts
+SET archived_at = COALESCE(archived_at, now())
+WHERE organisation_id = $1 AND id = $2
+RETURNING
+    id,
+    name,
+    type,
+    currency,
+    created_at,
+    updated_at,
+    archived_at,
+    organisation_id
+`
+
+type ArchiveAccountParams struct {
+	OrganisationID pgtype.UUID
+	ID             pgtype.UUID
+}
+
+func (q *Queries) ArchiveAccount(ctx context.Context, arg ArchiveAccountParams) (Account, error) {
+	row := q.db.QueryRow(ctx, archiveAccount, arg.OrganisationID, arg.ID)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Type,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ArchivedAt,
+		&i.OrganisationID,
+	)
+	return i, err
+}
+
 const createAccount = `-- name: CreateAccount :one
 INSERT INTO accounts (
+    organisation_id,
     name,
     type,
     currency
 ) VALUES (
-    $1, $2, $3
+    $1, $2, $3, $4
 )
 RETURNING
     id,
@@ -25,17 +81,25 @@ RETURNING
     type,
     currency,
     created_at,
-    updated_at
+    updated_at,
+    archived_at,
+    organisation_id
 `
 
 type CreateAccountParams struct {
-	Name     string
-	Type     string
-	Currency string
+	OrganisationID pgtype.UUID
+	Name           string
+	Type           string
+	Currency       string
 }
 
 func (q *Queries) CreateAccount(ctx context.Context, arg CreateAccountParams) (Account, error) {
-	row := q.db.QueryRow(ctx, createAccount, arg.Name, arg.Type, arg.Currency)
+	row := q.db.QueryRow(ctx, createAccount,
+		arg.OrganisationID,
+		arg.Name,
+		arg.Type,
+		arg.Currency,
+	)
 	var i Account
 	err := row.Scan(
 		&i.ID,
@@ -44,17 +108,24 @@ func (q *Queries) CreateAccount(ctx context.Context, arg CreateAccountParams) (A
 		&i.Currency,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.ArchivedAt,
+		&i.OrganisationID,
 	)
 	return i, err
 }
 
 const deleteAccount = `-- name: DeleteAccount :exec
 DELETE FROM accounts
-WHERE id = $1
+WHERE organisation_id = $1 AND id = $2
 `
 
-func (q *Queries) DeleteAccount(ctx context.Context, id pgtype.UUID) error {
-	_, err := q.db.Exec(ctx, deleteAccount, id)
+type DeleteAccountParams struct {
+	OrganisationID pgtype.UUID
+	ID             pgtype.UUID
+}
+
+func (q *Queries) DeleteAccount(ctx context.Context, arg DeleteAccountParams) error {
+	_, err := q.db.Exec(ctx, deleteAccount, arg.OrganisationID, arg.ID)
 	return err
 }
 
@@ -65,13 +136,20 @@ SELECT
   type,
   currency,
   created_at,
-  updated_at
+  updated_at,
+  archived_at,
+  organisation_id
 FROM accounts
-WHERE id = $1
+WHERE organisation_id = $1 AND id = $2
 `
 
-func (q *Queries) GetAccount(ctx context.Context, id pgtype.UUID) (Account, error) {
-	row := q.db.QueryRow(ctx, getAccount, id)
+type GetAccountParams struct {
+	OrganisationID pgtype.UUID
+	ID             pgtype.UUID
+}
+
+func (q *Queries) GetAccount(ctx context.Context, arg GetAccountParams) (Account, error) {
+	row := q.db.QueryRow(ctx, getAccount, arg.OrganisationID, arg.ID)
 	var i Account
 	err := row.Scan(
 		&i.ID,
@@ -80,17 +158,24 @@ func (q *Queries) GetAccount(ctx context.Context, id pgtype.UUID) (Account, erro
 		&i.Currency,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.ArchivedAt,
+		&i.OrganisationID,
 	)
 	return i, err
 }
 
 const getAccountsByIDs = `-- name: GetAccountsByIDs :many
-SELECT id, name, type, currency, created_at, updated_at FROM accounts
-WHERE id = ANY($1::uuid[])
+SELECT id, name, type, currency, created_at, updated_at, archived_at, organisation_id FROM accounts
+WHERE organisation_id = $1 AND id = ANY($2::uuid[])
 `
 
-func (q *Queries) GetAccountsByIDs(ctx context.Context, dollar_1 []pgtype.UUID) ([]Account, error) {
-	rows, err := q.db.Query(ctx, getAccountsByIDs, dollar_1)
+type GetAccountsByIDsParams struct {
+	OrganisationID pgtype.UUID
+	Ids            []pgtype.UUID
+}
+
+func (q *Queries) GetAccountsByIDs(ctx context.Context, arg GetAccountsByIDsParams) ([]Account, error) {
+	rows, err := q.db.Query(ctx, getAccountsByIDs, arg.OrganisationID, arg.Ids)
 	if err != nil {
 		return nil, err
 	}
@@ -105,6 +190,8 @@ func (q *Queries) GetAccountsByIDs(ctx context.Context, dollar_1 []pgtype.UUID)
 			&i.Currency,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.ArchivedAt,
+			&i.OrganisationID,
 		); err != nil {
 			return nil, err
 		}
@@ -123,13 +210,22 @@ SELECT
   type,
   currency,
   created_at,
-  updated_at
+  updated_at,
+  archived_at,
+  organisation_id
 FROM accounts
+WHERE organisation_id = $1
+  AND ($2::boolean OR archived_at IS NULL)
 ORDER BY created_at DESC
 `
 
-func (q *Queries) ListAccounts(ctx context.Context) ([]Account, error) {
-	rows, err := q.db.Query(ctx, listAccounts)
+type ListAccountsParams struct {
+	OrganisationID  pgtype.UUID
+	IncludeArchived bool
+}
+
+func (q *Queries) ListAccounts(ctx context.Context, arg ListAccountsParams) ([]Account, error) {
+	rows, err := q.db.Query(ctx, listAccounts, arg.OrganisationID, arg.IncludeArchived)
 	if err != nil {
 		return nil, err
 	}
@@ -144,6 +240,8 @@ func (q *Queries) ListAccounts(ctx context.Context) ([]Account, error) {
 			&i.Currency,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.ArchivedAt,
+			&i.OrganisationID,
 		); err != nil {
 			return nil, err
 		}
@@ -155,29 +253,73 @@ func (q *Queries) ListAccounts(ctx context.Context) ([]Account, error) {
 	return items, nil
 }
 
+const unarchiveAccount = `-- name: UnarchiveAccount :one
+UPDATE accounts
+SET archived_at = NULL
+WHERE organisation_id = $1 AND id = $2
+RETURNING
+    id,
+    name,
+    type,
+    currency,
+    created_at,
+    updated_at,
+    archived_at,
+    organisation_id
+`
+
+type UnarchiveAccountParams struct {
+	OrganisationID pgtype.UUID
+	ID             pgtype.UUID
+}
+
+func (q *Queries) UnarchiveAccount(ctx context.Context, arg UnarchiveAccountParams) (Account, error) {
+	row := q.db.QueryRow(ctx, unarchiveAccount, arg.OrganisationID, arg.ID)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Type,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ArchivedAt,
+		&i.OrganisationID,
+	)
+	return i, err
+}
+
 const updateAccount = `-- name: UpdateAccount :one
 UPDATE accounts
 SET
-    name = COALESCE($2, name),
-    type = COALESCE($3, type)
-WHERE id = $1
+    name = COALESCE($3, name),
+    type = COALESCE($4, type)
+WHERE organisation_id = $1 AND id = $2
 RETURNING
     id,
     name,
     type,
     currency,
     created_at,
-    updated_at
+    updated_at,
+    archived_at,
+    organisation_id
 `
 
 type UpdateAccountParams struct {
-	ID   pgtype.UUID
-	Name pgtype.Text
-	Type pgtype.Text
+	OrganisationID pgtype.UUID
+	ID             pgtype.UUID
+	Name           pgtype.Text
+	Type           pgtype.Text
 }
 
 func (q *Queries) UpdateAccount(ctx context.Context, arg UpdateAccountParams) (Account, error) {
-	row := q.db.QueryRow(ctx, updateAccount, arg.ID, arg.Name, arg.Type)
+	row := q.db.QueryRow(ctx, updateAccount,
+		arg.OrganisationID,
+		arg.ID,
+		arg.Name,
+		arg.Type,
+	)
 	var i Account
 	err := row.Scan(
 		&i.ID,
@@ -186,6 +328,8 @@ func (q *Queries) UpdateAccount(ctx context.Context, arg UpdateAccountParams) (A
 		&i.Currency,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.ArchivedAt,
+		&i.OrganisationID,
 	)
 	return i, err
 }
@@ -29,12 +29,23 @@ func TestCreateAccount(t *testing.T) {
 	pool := setupDB(t)
 	defer pool.Close()
 
-	q := db.New(pool)
+	org, err := db.New(pool).CreateOrganisation(ctx, db.CreateOrganisationParams{Name: "Test", Timezone: "UTC", FiscalYearStartMonth: 1})
+	require.NoError(t, err)
+
+	// row-level security only admits rows of the connection's organisation
+	tx, err := pool.Begin(ctx)
+	require.NoError(t, err)
+	defer tx.Rollback(ctx)
+	_, err = tx.Exec(ctx, "SELECT set_config('app.organisation_id', $1, true)", uuid.UUID(org.ID.Bytes).String())
+	require.NoError(t, err)
+
+	q := db.New(tx)
 
 	account, err := q.CreateAccount(ctx, db.CreateAccountParams{
-		Name:     "Cash",
-		Type:     "asset",
-		Currency: "NZD",
+		OrganisationID: org.ID,
+		Name:           "Cash",
+		Type:           "asset",
+		Currency:       "NZD",
 	})
 
 	require.NoError(t, err)
@@ -42,5 +53,76 @@ func TestCreateAccount(t *testing.T) {
 	require.Equal(t, "Cash", account.Name)
 	require.NotZero(t, account.CreatedAt)
 	require.NotZero(t, account.UpdatedAt)
+	require.Equal(t, org.ID, account.OrganisationID)
+
+	_, err = q.GetAccount(ctx, db.GetAccountParams{OrganisationID: org.ID, ID: account.ID})
+	require.NoError(t, err)
+}
+
+func TestAccountsAreIsolatedByOrganisation(t *testing.T) {
+	ctx := context.Background()
+	pool := setupDB(t)
+	defer pool.Close()
+
+	mine, err := db.New(pool).CreateOrganisation(ctx, db.CreateOrganisationParams{Name: "Mine", Timezone: "UTC", FiscalYearStartMonth: 1})
+	require.NoError(t, err)
+	theirs, err := db.New(pool).CreateOrganisation(ctx, db.CreateOrganisationParams{Name: "Theirs", Timezone: "UTC", FiscalYearStartMonth: 1})
+	require.NoError(t, err)
+
+	tx, err := pool.Begin(ctx)
+	require.NoError(t, err)
+	defer tx.Rollback(ctx)
+	_, err = tx.Exec(ctx, "SELECT set_config('app.organisation_id', $1, true)", uuid.UUID(mine.ID.Bytes).String())
+	require.NoError(t, err)
+
+	q := db.New(tx)
+
+	// the query filter and row-level security both refuse a foreign row
+	_, err = q.CreateAccount(ctx, db.CreateAccountParams{
+		OrganisationID: theirs.ID,
+		Name:           "Smuggled",
+		Type:           "asset",
+		Currency:       "NZD",
+	})
+	require.Error(t, err)
 }
 
+func TestAppRoleSeesNoRowsWithoutOrganisation(t *testing.T) {
+	ctx := context.Background()
+	pool := setupDB(t)
+	defer pool.Close()
+
+	org, err := db.New(pool).CreateOrganisation(ctx, db.CreateOrganisationParams{Name: "Hidden", Timezone: "UTC", FiscalYearStartMonth: 1})
+	require.NoError(t, err)
+	orgID := uuid.UUID(org.ID.Bytes).String()
+
+	tx, err := pool.Begin(ctx)
+	require.NoError(t, err)
+	defer tx.Rollback(ctx)
+	_, err = tx.Exec(ctx, "SELECT set_config('app.organisation_id', $1, true)", orgID)
+	require.NoError(t, err)
+	account, err := db.New(tx).CreateAccount(ctx, db.CreateAccountParams{
+		OrganisationID: org.ID,
+		Name:           "Cash",
+		Type:           "asset",
+		Currency:       "NZD",
+	})
+	require.NoError(t, err)
+
+	// the API's role, unlike the owner, is held to row-level security
+	_, err = tx.Exec(ctx, "SET LOCAL ROLE gofigure_app")
+	require.NoError(t, err)
+	count := func() int {
+		var n int
+		require.NoError(t, tx.QueryRow(ctx, "SELECT count(*) FROM accounts WHERE id = $1", account.ID).Scan(&n))
+		return n
+	}
+
+	_, err = tx.Exec(ctx, "SELECT set_config('app.organisation_id', '', true)")
+	require.NoError(t, err)
+	require.Zero(t, count())
+
+	_, err = tx.Exec(ctx, "SELECT set_config('app.organisation_id', $1, true)", orgID)
+	require.NoError(t, err)
+	require.Equal(t, 1, count())
+}
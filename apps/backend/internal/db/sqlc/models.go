@@ -9,12 +9,14 @@ import (
 )
 
 type Account struct {
-	ID        pgtype.UUID
-	Name      string
-	Type      string
-	Currency  string
-	CreatedAt pgtype.Timestamptz
-	UpdatedAt pgtype.Timestamptz
+	ID             pgtype.UUID
+	Name           string
+	Type           string
+	Currency       string
+	CreatedAt      pgtype.Timestamptz
+	UpdatedAt      pgtype.Timestamptz
+	ArchivedAt     pgtype.Timestamptz
+	OrganisationID pgtype.UUID
 }
 
 type AccountBalance struct {
@@ -23,22 +25,363 @@ type AccountBalance struct {
 	AccountType     string
 	AccountCurrency string
 	BalanceMinor    interface{}
+	OrganisationID  pgtype.UUID
+}
+
+type ApiToken struct {
+	ID          pgtype.UUID
+	UserID      pgtype.UUID
+	Name        string
+	TokenPrefix string
+	TokenHash   []byte
+	Scopes      []string
+	CreatedAt   pgtype.Timestamptz
+	LastUsedAt  pgtype.Timestamptz
+	ExpiresAt   pgtype.Timestamptz
+	RevokedAt   pgtype.Timestamptz
+}
+
+type Attachment struct {
+	ID             pgtype.UUID
+	OrganisationID pgtype.UUID
+	TransactionID  pgtype.UUID
+	Sha256         []byte
+	Filename       string
+	ContentType    string
+	SizeBytes      int64
+	UploadedBy     pgtype.UUID
+	CreatedAt      pgtype.Timestamptz
+}
+
+type AttachmentDeletion struct {
+	OrganisationID pgtype.UUID
+	Sha256         []byte
+	CreatedAt      pgtype.Timestamptz
+}
+
+type AuditEvent struct {
+	ID             pgtype.UUID
+	OrganisationID pgtype.UUID
+	Seq            int64
+	ActorUserID    pgtype.UUID
+	ActorEmail     string
+	ActorMethod    string
+	RequestID      string
+	Entity         string
+	EntityID       pgtype.UUID
+	Action         string
+	Before         []byte
+	After          []byte
+	CreatedAt      pgtype.Timestamptz
+	PrevHash       []byte
+	Hash           []byte
+}
+
+type Bill struct {
+	ID                pgtype.UUID
+	OrganisationID    pgtype.UUID
+	ContactID         pgtype.UUID
+	Number            string
+	Reference         string
+	Currency          string
+	IssueOn           pgtype.Date
+	DueOn             pgtype.Date
+	Status            string
+	PayableAccountID  pgtype.UUID
+	SubtotalMinor     int64
+	TaxMinor          int64
+	TotalMinor        int64
+	PaidMinor         int64
+	TransactionID     pgtype.UUID
+	VoidTransactionID pgtype.UUID
+	ApprovedAt        pgtype.Timestamptz
+	PaidOn            pgtype.Date
+	VoidedOn          pgtype.Date
+	CreatedAt         pgtype.Timestamptz
+	UpdatedAt         pgtype.Timestamptz
+}
+
+type BillLine struct {
+	ID                  pgtype.UUID
+	OrganisationID      pgtype.UUID
+	BillID              pgtype.UUID
+	Position            int32
+	Description         string
+	QuantityThousandths int64
+	UnitPriceMinor      int64
+	AccountID           pgtype.UUID
+	TaxCodeID           pgtype.UUID
+	AmountMinor         int64
+	TaxMinor            int64
+}
+
+type BillPayment struct {
+	ID             pgtype.UUID
+	OrganisationID pgtype.UUID
+	BillID         pgtype.UUID
+	TransactionID  pgtype.UUID
+	AccountID      pgtype.UUID
+	PaidOn         pgtype.Date
+	AmountMinor    int64
+	CreatedAt      pgtype.Timestamptz
+}
+
+type Contact struct {
+	ID               pgtype.UUID
+	OrganisationID   pgtype.UUID
+	Name             string
+	Email            pgtype.Text
+	DefaultAccountID pgtype.UUID
+	CreatedAt        pgtype.Timestamptz
+	UpdatedAt        pgtype.Timestamptz
+}
+
+type ContactAlias struct {
+	ID             pgtype.UUID
+	OrganisationID pgtype.UUID
+	ContactID      pgtype.UUID
+	Pattern        string
+	CreatedAt      pgtype.Timestamptz
+}
+
+type IdempotencyRecord struct {
+	Scope           string
+	Key             string
+	Method          string
+	Path            string
+	RequestHash     []byte
+	StatusCode      pgtype.Int4
+	ResponseHeaders []byte
+	ResponseBody    []byte
+	CreatedAt       pgtype.Timestamptz
+	CompletedAt     pgtype.Timestamptz
+}
+
+type Invoice struct {
+	ID                  pgtype.UUID
+	OrganisationID      pgtype.UUID
+	ContactID           pgtype.UUID
+	Number              string
+	Reference           string
+	Currency            string
+	IssueOn             pgtype.Date
+	DueOn               pgtype.Date
+	Status              string
+	ReceivableAccountID pgtype.UUID
+	SubtotalMinor       int64
+	TaxMinor            int64
+	TotalMinor          int64
+	PaidMinor           int64
+	TransactionID       pgtype.UUID
+	VoidTransactionID   pgtype.UUID
+	SentAt              pgtype.Timestamptz
+	PaidOn              pgtype.Date
+	VoidedOn            pgtype.Date
+	CreatedAt           pgtype.Timestamptz
+	UpdatedAt           pgtype.Timestamptz
+}
+
+type InvoiceLine struct {
+	ID                  pgtype.UUID
+	OrganisationID      pgtype.UUID
+	InvoiceID           pgtype.UUID
+	Position            int32
+	Description         string
+	QuantityThousandths int64
+	UnitPriceMinor      int64
+	AccountID           pgtype.UUID
+	TaxCodeID           pgtype.UUID
+	AmountMinor         int64
+	TaxMinor            int64
+}
+
+type InvoicePayment struct {
+	ID             pgtype.UUID
+	OrganisationID pgtype.UUID
+	InvoiceID      pgtype.UUID
+	TransactionID  pgtype.UUID
+	AccountID      pgtype.UUID
+	PaidOn         pgtype.Date
+	AmountMinor    int64
+	CreatedAt      pgtype.Timestamptz
+}
+
+type InvoiceTemplate struct {
+	OrganisationID      pgtype.UUID
+	TradingName         string
+	Address             string
+	TaxNumber           string
+	Email               string
+	AccentColor         string
+	PaymentInstructions string
+	Footer              string
+	Logo                []byte
+	LogoType            pgtype.Text
+	CreatedAt           pgtype.Timestamptz
+	UpdatedAt           pgtype.Timestamptz
 }
 
 type LedgerEntry struct {
-	ID            pgtype.UUID
-	TransactionID pgtype.UUID
-	AccountID     pgtype.UUID
-	AmountMinor   int64
-	Currency      string
-	CreatedAt     pgtype.Timestamptz
+	ID             pgtype.UUID
+	TransactionID  pgtype.UUID
+	AccountID      pgtype.UUID
+	AmountMinor    int64
+	Currency       string
+	CreatedAt      pgtype.Timestamptz
+	OrganisationID pgtype.UUID
 }
 
-type Transaction struct {
+type LedgerEntryTag struct {
+	OrganisationID pgtype.UUID
+	LedgerEntryID  pgtype.UUID
+	Tag            string
+}
+
+type LedgerEntryTracking struct {
+	OrganisationID pgtype.UUID
+	LedgerEntryID  pgtype.UUID
+	CategoryID     pgtype.UUID
+	OptionID       pgtype.UUID
+}
+
+type Membership struct {
+	OrganisationID pgtype.UUID
+	UserID         pgtype.UUID
+	Role           string
+	CreatedAt      pgtype.Timestamptz
+}
+
+type Organisation struct {
+	ID                   pgtype.UUID
+	Name                 string
+	CreatedAt            pgtype.Timestamptz
+	UpdatedAt            pgtype.Timestamptz
+	Timezone             string
+	FiscalYearStartMonth int16
+}
+
+type PeriodLock struct {
+	ID             pgtype.UUID
+	OrganisationID pgtype.UUID
+	Reason         string
+	LockedBy       pgtype.UUID
+	CreatedAt      pgtype.Timestamptz
+	StartsOn       pgtype.Date
+	EndsOn         pgtype.Date
+}
+
+type ReceiptScan struct {
+	ID                 pgtype.UUID
+	OrganisationID     pgtype.UUID
+	AttachmentID       pgtype.UUID
+	Status             string
+	Attempts           int32
+	RunAfter           pgtype.Timestamptz
+	LockedAt           pgtype.Timestamptz
+	Error              string
+	Text               string
+	Merchant           pgtype.Text
+	MerchantConfidence pgtype.Float8
+	ReceiptDate        pgtype.Date
+	DateConfidence     pgtype.Float8
+	TotalMinor         pgtype.Int8
+	TotalConfidence    pgtype.Float8
+	GstMinor           pgtype.Int8
+	GstConfidence      pgtype.Float8
+	Outcome            pgtype.Text
+	StatementLineID    pgtype.UUID
+	TransactionID      pgtype.UUID
+	ContactID          pgtype.UUID
+	AccountID          pgtype.UUID
+	CreatedAt          pgtype.Timestamptz
+	UpdatedAt          pgtype.Timestamptz
+}
+
+type Reconciliation struct {
+	ID                  pgtype.UUID
+	OrganisationID      pgtype.UUID
+	AccountID           pgtype.UUID
+	StartsOn            pgtype.Date
+	EndsOn              pgtype.Date
+	ClosingBalanceMinor int64
+	Status              string
+	CreatedBy           pgtype.UUID
+	CreatedAt           pgtype.Timestamptz
+	CompletedAt         pgtype.Timestamptz
+	OpeningBalanceMinor int64
+}
+
+type Session struct {
+	ID        pgtype.UUID
+	TokenHash []byte
+	UserID    pgtype.UUID
+	CreatedAt pgtype.Timestamptz
+	ExpiresAt pgtype.Timestamptz
+}
+
+type StatementLine struct {
+	ID               pgtype.UUID
+	OrganisationID   pgtype.UUID
+	ReconciliationID pgtype.UUID
+	PostedOn         pgtype.Date
+	AmountMinor      int64
+	Description      string
+	Reference        string
+	MatchStatus      string
+	LedgerEntryID    pgtype.UUID
+	MatchScore       pgtype.Float8
+	RejectedEntryIds []pgtype.UUID
+	CreatedAt        pgtype.Timestamptz
+}
+
+type TaxCode struct {
+	ID              pgtype.UUID
+	OrganisationID  pgtype.UUID
+	Name            string
+	RateBasisPoints int32
+	AccountID       pgtype.UUID
+	CreatedAt       pgtype.Timestamptz
+	UpdatedAt       pgtype.Timestamptz
+}
+
+type TrackingCategory struct {
+	ID             pgtype.UUID
+	OrganisationID pgtype.UUID
+	Name           string
+	CreatedAt      pgtype.Timestamptz
+	UpdatedAt      pgtype.Timestamptz
+}
+
+type TrackingOption struct {
 	ID             pgtype.UUID
-	IdempotencyKey string
-	Description    pgtype.Text
-	Source         string
-	PostedAt       pgtype.Timestamptz
+	OrganisationID pgtype.UUID
+	CategoryID     pgtype.UUID
+	Name           string
+	Active         bool
 	CreatedAt      pgtype.Timestamptz
+	UpdatedAt      pgtype.Timestamptz
+}
+
+type Transaction struct {
+	ID              pgtype.UUID
+	IdempotencyKey  pgtype.Text
+	Description     pgtype.Text
+	Source          string
+	PostedAt        pgtype.Timestamptz
+	CreatedAt       pgtype.Timestamptz
+	RequestHash     []byte
+	OrganisationID  pgtype.UUID
+	PostedOn        pgtype.Date
+	CorrectsEntryID pgtype.UUID
+	ContactID       pgtype.UUID
+	BillID          pgtype.UUID
+}
+
+type User struct {
+	ID           pgtype.UUID
+	Email        string
+	PasswordHash string
+	IsAdmin      bool
+	CreatedAt    pgtype.Timestamptz
+	UpdatedAt    pgtype.Timestamptz
 }
@@ -0,0 +1,115 @@
+package sqlc_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+
+	db "github.com/LBaronceli/go-figure/internal/db/sqlc"
+)
+
+func TestFirstStatementClearsToItsClosingBalance(t *testing.T) {
+	ctx := context.Background()
+	pool := setupDB(t)
+	defer pool.Close()
+
+	org, err := db.New(pool).CreateOrganisation(ctx, db.CreateOrganisationParams{Name: "Test", Timezone: "UTC", FiscalYearStartMonth: 1})
+	require.NoError(t, err)
+
+	tx, err := pool.Begin(ctx)
+	require.NoError(t, err)
+	defer tx.Rollback(ctx)
+	_, err = tx.Exec(ctx, "SELECT set_config('app.organisation_id', $1, true)", uuid.UUID(org.ID.Bytes).String())
+	require.NoError(t, err)
+
+	q := db.New(tx)
+
+	account, err := q.CreateAccount(ctx, db.CreateAccountParams{
+		OrganisationID: org.ID,
+		Name:           "Cheque",
+		Type:           "asset",
+		Currency:       "NZD",
+	})
+	require.NoError(t, err)
+
+	post := func(on time.Time, amount int64) db.LedgerEntry {
+		t.Helper()
+		txn, err := q.CreateTransaction(ctx, db.CreateTransactionParams{
+			OrganisationID: org.ID,
+			Source:         "manual",
+			PostedAt:       pgtype.Timestamptz{Time: on, Valid: true},
+			PostedOn:       pgtype.Date{Time: on, Valid: true},
+		})
+		require.NoError(t, err)
+		entry, err := q.CreateLedgerEntry(ctx, db.CreateLedgerEntryParams{
+			OrganisationID: org.ID,
+			TransactionID:  txn.ID,
+			AccountID:      account.ID,
+			AmountMinor:    amount,
+			Currency:       "NZD",
+		})
+		require.NoError(t, err)
+		return entry
+	}
+
+	// the account was in use before the bank's first statement in the system
+	post(time.Date(2025, 12, 15, 0, 0, 0, 0, time.UTC), 50_000)
+	deposit := post(time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC), 2_500)
+
+	startsOn := pgtype.Date{Time: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), Valid: true}
+	_, err = q.GetPreviousClosingBalance(ctx, db.GetPreviousClosingBalanceParams{
+		OrganisationID: org.ID,
+		AccountID:      account.ID,
+		StartsOn:       startsOn,
+	})
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+
+	rec, err := q.CreateReconciliation(ctx, db.CreateReconciliationParams{
+		OrganisationID:      org.ID,
+		AccountID:           account.ID,
+		StartsOn:            startsOn,
+		EndsOn:              pgtype.Date{Time: time.Date(2026, 1, 31, 0, 0, 0, 0, time.UTC), Valid: true},
+		ClosingBalanceMinor: 52_500,
+		CreatedBy:           pgtype.UUID{Bytes: uuid.New(), Valid: true},
+		OpeningBalanceMinor: 50_000,
+	})
+	require.NoError(t, err)
+
+	cleared, err := q.GetClearedBalance(ctx, db.GetClearedBalanceParams{OrganisationID: org.ID, ID: rec.ID})
+	require.NoError(t, err)
+	require.Equal(t, int64(50_000), cleared)
+
+	line, err := q.CreateStatementLine(ctx, db.CreateStatementLineParams{
+		OrganisationID:   org.ID,
+		ReconciliationID: rec.ID,
+		PostedOn:         pgtype.Date{Time: time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC), Valid: true},
+		AmountMinor:      2_500,
+	})
+	require.NoError(t, err)
+	_, err = q.SetStatementLineMatch(ctx, db.SetStatementLineMatchParams{
+		OrganisationID: org.ID,
+		ID:             line.ID,
+		MatchStatus:    "matched",
+		LedgerEntryID:  deposit.ID,
+	})
+	require.NoError(t, err)
+
+	// the opening balance carries the history no statement line covers
+	cleared, err = q.GetClearedBalance(ctx, db.GetClearedBalanceParams{OrganisationID: org.ID, ID: rec.ID})
+	require.NoError(t, err)
+	require.Equal(t, rec.ClosingBalanceMinor, cleared)
+
+	// and the next statement opens where this one closed
+	opening, err := q.GetPreviousClosingBalance(ctx, db.GetPreviousClosingBalanceParams{
+		OrganisationID: org.ID,
+		AccountID:      account.ID,
+		StartsOn:       pgtype.Date{Time: time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC), Valid: true},
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(52_500), opening)
+}
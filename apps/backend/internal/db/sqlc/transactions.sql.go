@@ -11,27 +11,96 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const createLedgerEntries = `-- name: CreateLedgerEntries :many
+INSERT INTO ledger_entries (
+  id,
+  organisation_id,
+  transaction_id,
+  account_id,
+  amount_minor,
+  currency
+)
+SELECT e.id, $1::uuid, e.transaction_id, e.account_id, e.amount_minor, e.currency
+FROM unnest(
+  $2::uuid[],
+  $3::uuid[],
+  $4::uuid[],
+  $5::bigint[],
+  $6::text[]
+) AS e(id, transaction_id, account_id, amount_minor, currency)
+RETURNING id, transaction_id, account_id, amount_minor, currency, created_at, organisation_id
+`
+
+type CreateLedgerEntriesParams struct {
+	OrganisationID pgtype.UUID
+	Ids            []pgtype.UUID
+	TransactionIds []pgtype.UUID
+	AccountIds     []pgtype.UUID
+	AmountsMinor   []int64
+	Currencies     []string
+}
+
+// IDs are chosen by the caller so each row can be matched to the request
+// entry it came from.
+func (q *Queries) CreateLedgerEntries(ctx context.Context, arg CreateLedgerEntriesParams) ([]LedgerEntry, error) {
+	rows, err := q.db.Query(ctx, createLedgerEntries,
+		arg.OrganisationID,
+		arg.Ids,
+		arg.TransactionIds,
+		arg.AccountIds,
+		arg.AmountsMinor,
+		arg.Currencies,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []LedgerEntry
+	for rows.Next() {
+		var i LedgerEntry
+		if err := rows.Scan(
+			&i.ID,
+			&i.TransactionID,
+			&i.AccountID,
+			&i.AmountMinor,
+			&i.Currency,
+			&i.CreatedAt,
+			&i.OrganisationID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const createLedgerEntry = `-- name: CreateLedgerEntry :one
 INSERT INTO ledger_entries (
+  organisation_id,
   transaction_id,
   account_id,
   amount_minor,
   currency
 ) VALUES (
-  $1, $2, $3, $4
+  $1, $2, $3, $4, $5
 )
-RETURNING id, transaction_id, account_id, amount_minor, currency, created_at
+RETURNING id, transaction_id, account_id, amount_minor, currency, created_at, organisation_id
 `
 
 type CreateLedgerEntryParams struct {
-	TransactionID pgtype.UUID
-	AccountID     pgtype.UUID
-	AmountMinor   int64
-	Currency      string
+	OrganisationID pgtype.UUID
+	TransactionID  pgtype.UUID
+	AccountID      pgtype.UUID
+	AmountMinor    int64
+	Currency       string
 }
 
 func (q *Queries) CreateLedgerEntry(ctx context.Context, arg CreateLedgerEntryParams) (LedgerEntry, error) {
 	row := q.db.QueryRow(ctx, createLedgerEntry,
+		arg.OrganisationID,
 		arg.TransactionID,
 		arg.AccountID,
 		arg.AmountMinor,
@@ -45,35 +114,54 @@ func (q *Queries) CreateLedgerEntry(ctx context.Context, arg CreateLedgerEntryPa
 		&i.AmountMinor,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.OrganisationID,
 	)
 	return i, err
 }
 
 const createTransaction = `-- name: CreateTransaction :one
 INSERT INTO transactions (
+  organisation_id,
   idempotency_key,
   description,
   source,
-  posted_at
+  posted_at,
+  request_hash,
+  posted_on,
+  corrects_entry_id,
+  contact_id,
+  bill_id
 ) VALUES (
-  $1, $2, $3, $4
+  $1, $2, $3, $4, $5, $6, $7, $8, $9, $10
 )
-RETURNING id, idempotency_key, description, source, posted_at, created_at
+RETURNING id, idempotency_key, description, source, posted_at, created_at, request_hash, organisation_id, posted_on, corrects_entry_id, contact_id, bill_id
 `
 
 type CreateTransactionParams struct {
-	IdempotencyKey string
-	Description    pgtype.Text
-	Source         string
-	PostedAt       pgtype.Timestamptz
+	OrganisationID  pgtype.UUID
+	IdempotencyKey  pgtype.Text
+	Description     pgtype.Text
+	Source          string
+	PostedAt        pgtype.Timestamptz
+	RequestHash     []byte
+	PostedOn        pgtype.Date
+	CorrectsEntryID pgtype.UUID
+	ContactID       pgtype.UUID
+	BillID          pgtype.UUID
 }
 
 func (q *Queries) CreateTransaction(ctx context.Context, arg CreateTransactionParams) (Transaction, error) {
 	row := q.db.QueryRow(ctx, createTransaction,
+		arg.OrganisationID,
 		arg.IdempotencyKey,
 		arg.Description,
 		arg.Source,
 		arg.PostedAt,
+		arg.RequestHash,
+		arg.PostedOn,
+		arg.CorrectsEntryID,
+		arg.ContactID,
+		arg.BillID,
 	)
 	var i Transaction
 	err := row.Scan(
@@ -83,17 +171,133 @@ func (q *Queries) CreateTransaction(ctx context.Context, arg CreateTransactionPa
 		&i.Source,
 		&i.PostedAt,
 		&i.CreatedAt,
+		&i.RequestHash,
+		&i.OrganisationID,
+		&i.PostedOn,
+		&i.CorrectsEntryID,
+		&i.ContactID,
+		&i.BillID,
+	)
+	return i, err
+}
+
+const createTransactions = `-- name: CreateTransactions :many
+INSERT INTO transactions (
+  organisation_id,
+  idempotency_key,
+  description,
+  source,
+  posted_at,
+  request_hash,
+  posted_on,
+  contact_id
+)
+SELECT $1::uuid, t.idempotency_key, NULLIF(t.description, ''), t.source, t.posted_at, t.request_hash, t.posted_on, t.contact_id
+FROM unnest(
+  $2::text[],
+  $3::text[],
+  $4::text[],
+  $5::timestamptz[],
+  $6::bytea[],
+  $7::date[],
+  $8::uuid[]
+) AS t(idempotency_key, description, source, posted_at, request_hash, posted_on, contact_id)
+RETURNING id, idempotency_key, description, source, posted_at, created_at, request_hash, organisation_id, posted_on, corrects_entry_id, contact_id, bill_id
+`
+
+type CreateTransactionsParams struct {
+	OrganisationID  pgtype.UUID
+	IdempotencyKeys []string
+	Descriptions    []string
+	Sources         []string
+	PostedAts       []pgtype.Timestamptz
+	RequestHashes   [][]byte
+	PostedOns       []pgtype.Date
+	ContactIds      []pgtype.UUID
+}
+
+// Inserts many transactions in one statement. COPY is refused on tables with
+// row-level security, so the rows arrive as parallel arrays instead.
+func (q *Queries) CreateTransactions(ctx context.Context, arg CreateTransactionsParams) ([]Transaction, error) {
+	rows, err := q.db.Query(ctx, createTransactions,
+		arg.OrganisationID,
+		arg.IdempotencyKeys,
+		arg.Descriptions,
+		arg.Sources,
+		arg.PostedAts,
+		arg.RequestHashes,
+		arg.PostedOns,
+		arg.ContactIds,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Transaction
+	for rows.Next() {
+		var i Transaction
+		if err := rows.Scan(
+			&i.ID,
+			&i.IdempotencyKey,
+			&i.Description,
+			&i.Source,
+			&i.PostedAt,
+			&i.CreatedAt,
+			&i.RequestHash,
+			&i.OrganisationID,
+			&i.PostedOn,
+			&i.CorrectsEntryID,
+			&i.ContactID,
+			&i.BillID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getLedgerEntry = `-- name: GetLedgerEntry :one
+SELECT id, transaction_id, account_id, amount_minor, currency, created_at, organisation_id FROM ledger_entries
+WHERE organisation_id = $1 AND transaction_id = $2 AND id = $3 LIMIT 1
+`
+
+type GetLedgerEntryParams struct {
+	OrganisationID pgtype.UUID
+	TransactionID  pgtype.UUID
+	ID             pgtype.UUID
+}
+
+func (q *Queries) GetLedgerEntry(ctx context.Context, arg GetLedgerEntryParams) (LedgerEntry, error) {
+	row := q.db.QueryRow(ctx, getLedgerEntry, arg.OrganisationID, arg.TransactionID, arg.ID)
+	var i LedgerEntry
+	err := row.Scan(
+		&i.ID,
+		&i.TransactionID,
+		&i.AccountID,
+		&i.AmountMinor,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.OrganisationID,
 	)
 	return i, err
 }
 
 const getTransaction = `-- name: GetTransaction :one
-SELECT id, idempotency_key, description, source, posted_at, created_at FROM transactions
-WHERE id = $1 LIMIT 1
+SELECT id, idempotency_key, description, source, posted_at, created_at, request_hash, organisation_id, posted_on, corrects_entry_id, contact_id, bill_id FROM transactions
+WHERE organisation_id = $1 AND id = $2 LIMIT 1
 `
 
-func (q *Queries) GetTransaction(ctx context.Context, id pgtype.UUID) (Transaction, error) {
-	row := q.db.QueryRow(ctx, getTransaction, id)
+type GetTransactionParams struct {
+	OrganisationID pgtype.UUID
+	ID             pgtype.UUID
+}
+
+func (q *Queries) GetTransaction(ctx context.Context, arg GetTransactionParams) (Transaction, error) {
+	row := q.db.QueryRow(ctx, getTransaction, arg.OrganisationID, arg.ID)
 	var i Transaction
 	err := row.Scan(
 		&i.ID,
@@ -102,17 +306,28 @@ func (q *Queries) GetTransaction(ctx context.Context, id pgtype.UUID) (Transacti
 		&i.Source,
 		&i.PostedAt,
 		&i.CreatedAt,
+		&i.RequestHash,
+		&i.OrganisationID,
+		&i.PostedOn,
+		&i.CorrectsEntryID,
+		&i.ContactID,
+		&i.BillID,
 	)
 	return i, err
 }
 
 const getTransactionByIdempotencyKey = `-- name: GetTransactionByIdempotencyKey :one
-SELECT id, idempotency_key, description, source, posted_at, created_at FROM transactions
-WHERE idempotency_key = $1 LIMIT 1
+SELECT id, idempotency_key, description, source, posted_at, created_at, request_hash, organisation_id, posted_on, corrects_entry_id, contact_id, bill_id FROM transactions
+WHERE organisation_id = $1 AND idempotency_key = $2 LIMIT 1
 `
 
-func (q *Queries) GetTransactionByIdempotencyKey(ctx context.Context, idempotencyKey string) (Transaction, error) {
-	row := q.db.QueryRow(ctx, getTransactionByIdempotencyKey, idempotencyKey)
+type GetTransactionByIdempotencyKeyParams struct {
+	OrganisationID pgtype.UUID
+	IdempotencyKey pgtype.Text
+}
+
+func (q *Queries) GetTransactionByIdempotencyKey(ctx context.Context, arg GetTransactionByIdempotencyKeyParams) (Transaction, error) {
+	row := q.db.QueryRow(ctx, getTransactionByIdempotencyKey, arg.OrganisationID, arg.IdempotencyKey)
 	var i Transaction
 	err := row.Scan(
 		&i.ID,
@@ -121,18 +336,69 @@ func (q *Queries) GetTransactionByIdempotencyKey(ctx context.Context, idempotenc
 		&i.Source,
 		&i.PostedAt,
 		&i.CreatedAt,
+		&i.RequestHash,
+		&i.OrganisationID,
+		&i.PostedOn,
+		&i.CorrectsEntryID,
+		&i.ContactID,
+		&i.BillID,
 	)
 	return i, err
 }
 
 const listLedgerEntries = `-- name: ListLedgerEntries :many
-SELECT id, transaction_id, account_id, amount_minor, currency, created_at FROM ledger_entries
-WHERE transaction_id = $1
+SELECT id, transaction_id, account_id, amount_minor, currency, created_at, organisation_id FROM ledger_entries
+WHERE organisation_id = $1 AND transaction_id = $2
 ORDER BY amount_minor DESC
 `
 
-func (q *Queries) ListLedgerEntries(ctx context.Context, transactionID pgtype.UUID) ([]LedgerEntry, error) {
-	rows, err := q.db.Query(ctx, listLedgerEntries, transactionID)
+type ListLedgerEntriesParams struct {
+	OrganisationID pgtype.UUID
+	TransactionID  pgtype.UUID
+}
+
+func (q *Queries) ListLedgerEntries(ctx context.Context, arg ListLedgerEntriesParams) ([]LedgerEntry, error) {
+	rows, err := q.db.Query(ctx, listLedgerEntries, arg.OrganisationID, arg.TransactionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []LedgerEntry
+	for rows.Next() {
+		var i LedgerEntry
+		if err := rows.Scan(
+			&i.ID,
+			&i.TransactionID,
+			&i.AccountID,
+			&i.AmountMinor,
+			&i.Currency,
+			&i.CreatedAt,
+			&i.OrganisationID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listLedgerEntriesForTransactions = `-- name: ListLedgerEntriesForTransactions :many
+SELECT id, transaction_id, account_id, amount_minor, currency, created_at, organisation_id FROM ledger_entries
+WHERE organisation_id = $1
+  AND transaction_id = ANY($2::uuid[])
+ORDER BY transaction_id, amount_minor DESC
+`
+
+type ListLedgerEntriesForTransactionsParams struct {
+	OrganisationID pgtype.UUID
+	TransactionIds []pgtype.UUID
+}
+
+func (q *Queries) ListLedgerEntriesForTransactions(ctx context.Context, arg ListLedgerEntriesForTransactionsParams) ([]LedgerEntry, error) {
+	rows, err := q.db.Query(ctx, listLedgerEntriesForTransactions, arg.OrganisationID, arg.TransactionIds)
 	if err != nil {
 		return nil, err
 	}
@@ -147,6 +413,7 @@ func (q *Queries) ListLedgerEntries(ctx context.Context, transactionID pgtype.UU
 			&i.AmountMinor,
 			&i.Currency,
 			&i.CreatedAt,
+			&i.OrganisationID,
 		); err != nil {
 			return nil, err
 		}
@@ -159,34 +426,316 @@ func (q *Queries) ListLedgerEntries(ctx context.Context, transactionID pgtype.UU
 }
 
 const listTransactions = `-- name: ListTransactions :many
-SELECT id, idempotency_key, description, source, posted_at, created_at FROM transactions t
-WHERE 
-  ($3::uuid IS NULL OR EXISTS (
+SELECT t.* FROM transactions t
+WHERE
+  t.organisation_id = $3
+  AND ($4::uuid IS NULL OR EXISTS (
     SELECT 1 FROM ledger_entries le 
     WHERE le.transaction_id = t.id 
-    AND le.account_id = $3
+    AND le.account_id = $4
+  ))
+  AND ($5::date IS NULL OR t.posted_on >= $5)
+  AND ($6::date IS NULL OR t.posted_on <= $6)
+  AND ($7::text IS NULL OR EXISTS (
+    SELECT 1 FROM ledger_entries le
+    JOIN ledger_entry_tags tg ON tg.ledger_entry_id = le.id
+    WHERE le.transaction_id = t.id
+    AND tg.tag = $7
+  ))
+  AND ($8::uuid IS NULL OR EXISTS (
+    SELECT 1 FROM ledger_entries le
+    JOIN ledger_entry_tracking tr ON tr.ledger_entry_id = le.id
+    WHERE le.transaction_id = t.id
+    AND tr.option_id = $8
   ))
-  AND ($4::timestamptz IS NULL OR t.posted_at >= $4)
-  AND ($5::timestamptz IS NULL OR t.posted_at <= $5)
-ORDER BY posted_at DESC, created_at DESC
+  AND ($9::text IS NULL OR t.source = $9)
+  AND ($10::bigint IS NULL
+    OR transaction_amount(t.id, $4) >= $10)
+  AND ($11::bigint IS NULL
+    OR transaction_amount(t.id, $4) <= $11)
+  AND ($12::text IS NULL
+    OR COALESCE(t.description, '') ILIKE '%' || $12 || '%')
+  AND ($13::uuid IS NULL OR EXISTS (
+    SELECT 1 FROM ledger_entries le
+    WHERE le.transaction_id = t.id
+    AND le.account_id = $13
+    AND le.account_id IS DISTINCT FROM $4
+  ))
+  AND ($14::uuid IS NULL OR t.contact_id = $14)
+  AND ($15::boolean IS NULL OR EXISTS (
+    SELECT 1 FROM ledger_entries le
+    JOIN ledger_entry_tags tg ON tg.ledger_entry_id = le.id
+    WHERE le.transaction_id = t.id
+  ) = $15)
+ORDER BY t.posted_on DESC, t.created_at DESC, t.id
 LIMIT $1 OFFSET $2
 `
 
 type ListTransactionsParams struct {
-	Limit     int32
-	Offset    int32
-	AccountID pgtype.UUID
-	StartDate pgtype.Timestamptz
-	EndDate   pgtype.Timestamptz
+	Limit                 int32
+	Offset                int32
+	OrganisationID        pgtype.UUID
+	AccountID             pgtype.UUID
+	StartDate             pgtype.Date
+	EndDate               pgtype.Date
+	Tag                   pgtype.Text
+	TrackingOptionID      pgtype.UUID
+	Source                pgtype.Text
+	MinAmount             pgtype.Int8
+	MaxAmount             pgtype.Int8
+	DescriptionContains   pgtype.Text
+	CounterpartyAccountID pgtype.UUID
+	ContactID             pgtype.UUID
+	HasTag                pgtype.Bool
 }
 
+// Sorted newest posted first. Every sort order GET /transactions offers is a
+// query of its own below, with the same filters, so the planner can read the
+// order off the organisation's posted_on, posted_at or created_at index.
+// transaction_amount only runs when an amount filter or order is asked for.
 func (q *Queries) ListTransactions(ctx context.Context, arg ListTransactionsParams) ([]Transaction, error) {
 	rows, err := q.db.Query(ctx, listTransactions,
 		arg.Limit,
 		arg.Offset,
+		arg.OrganisationID,
+		arg.AccountID,
+		arg.StartDate,
+		arg.EndDate,
+		arg.Tag,
+		arg.TrackingOptionID,
+		arg.Source,
+		arg.MinAmount,
+		arg.MaxAmount,
+		arg.DescriptionContains,
+		arg.CounterpartyAccountID,
+		arg.ContactID,
+		arg.HasTag,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Transaction
+	for rows.Next() {
+		var i Transaction
+		if err := rows.Scan(
+			&i.ID,
+			&i.IdempotencyKey,
+			&i.Description,
+			&i.Source,
+			&i.PostedAt,
+			&i.CreatedAt,
+			&i.RequestHash,
+			&i.OrganisationID,
+			&i.PostedOn,
+			&i.CorrectsEntryID,
+			&i.ContactID,
+			&i.BillID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTransactionsAmountAsc = `-- name: ListTransactionsAmountAsc :many
+SELECT t.* FROM transactions t
+WHERE
+  t.organisation_id = $3
+  AND ($4::uuid IS NULL OR EXISTS (
+    SELECT 1 FROM ledger_entries le 
+    WHERE le.transaction_id = t.id 
+    AND le.account_id = $4
+  ))
+  AND ($5::date IS NULL OR t.posted_on >= $5)
+  AND ($6::date IS NULL OR t.posted_on <= $6)
+  AND ($7::text IS NULL OR EXISTS (
+    SELECT 1 FROM ledger_entries le
+    JOIN ledger_entry_tags tg ON tg.ledger_entry_id = le.id
+    WHERE le.transaction_id = t.id
+    AND tg.tag = $7
+  ))
+  AND ($8::uuid IS NULL OR EXISTS (
+    SELECT 1 FROM ledger_entries le
+    JOIN ledger_entry_tracking tr ON tr.ledger_entry_id = le.id
+    WHERE le.transaction_id = t.id
+    AND tr.option_id = $8
+  ))
+  AND ($9::text IS NULL OR t.source = $9)
+  AND ($10::bigint IS NULL
+    OR transaction_amount(t.id, $4) >= $10)
+  AND ($11::bigint IS NULL
+    OR transaction_amount(t.id, $4) <= $11)
+  AND ($12::text IS NULL
+    OR COALESCE(t.description, '') ILIKE '%' || $12 || '%')
+  AND ($13::uuid IS NULL OR EXISTS (
+    SELECT 1 FROM ledger_entries le
+    WHERE le.transaction_id = t.id
+    AND le.account_id = $13
+    AND le.account_id IS DISTINCT FROM $4
+  ))
+  AND ($14::uuid IS NULL OR t.contact_id = $14)
+  AND ($15::boolean IS NULL OR EXISTS (
+    SELECT 1 FROM ledger_entries le
+    JOIN ledger_entry_tags tg ON tg.ledger_entry_id = le.id
+    WHERE le.transaction_id = t.id
+  ) = $15)
+ORDER BY transaction_amount(t.id, $4) ASC, t.created_at ASC, t.id
+LIMIT $1 OFFSET $2
+`
+
+type ListTransactionsAmountAscParams struct {
+	Limit                 int32
+	Offset                int32
+	OrganisationID        pgtype.UUID
+	AccountID             pgtype.UUID
+	StartDate             pgtype.Date
+	EndDate               pgtype.Date
+	Tag                   pgtype.Text
+	TrackingOptionID      pgtype.UUID
+	Source                pgtype.Text
+	MinAmount             pgtype.Int8
+	MaxAmount             pgtype.Int8
+	DescriptionContains   pgtype.Text
+	CounterpartyAccountID pgtype.UUID
+	ContactID             pgtype.UUID
+	HasTag                pgtype.Bool
+}
+
+func (q *Queries) ListTransactionsAmountAsc(ctx context.Context, arg ListTransactionsAmountAscParams) ([]Transaction, error) {
+	rows, err := q.db.Query(ctx, listTransactionsAmountAsc,
+		arg.Limit,
+		arg.Offset,
+		arg.OrganisationID,
+		arg.AccountID,
+		arg.StartDate,
+		arg.EndDate,
+		arg.Tag,
+		arg.TrackingOptionID,
+		arg.Source,
+		arg.MinAmount,
+		arg.MaxAmount,
+		arg.DescriptionContains,
+		arg.CounterpartyAccountID,
+		arg.ContactID,
+		arg.HasTag,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Transaction
+	for rows.Next() {
+		var i Transaction
+		if err := rows.Scan(
+			&i.ID,
+			&i.IdempotencyKey,
+			&i.Description,
+			&i.Source,
+			&i.PostedAt,
+			&i.CreatedAt,
+			&i.RequestHash,
+			&i.OrganisationID,
+			&i.PostedOn,
+			&i.CorrectsEntryID,
+			&i.ContactID,
+			&i.BillID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTransactionsAmountDesc = `-- name: ListTransactionsAmountDesc :many
+SELECT t.* FROM transactions t
+WHERE
+  t.organisation_id = $3
+  AND ($4::uuid IS NULL OR EXISTS (
+    SELECT 1 FROM ledger_entries le 
+    WHERE le.transaction_id = t.id 
+    AND le.account_id = $4
+  ))
+  AND ($5::date IS NULL OR t.posted_on >= $5)
+  AND ($6::date IS NULL OR t.posted_on <= $6)
+  AND ($7::text IS NULL OR EXISTS (
+    SELECT 1 FROM ledger_entries le
+    JOIN ledger_entry_tags tg ON tg.ledger_entry_id = le.id
+    WHERE le.transaction_id = t.id
+    AND tg.tag = $7
+  ))
+  AND ($8::uuid IS NULL OR EXISTS (
+    SELECT 1 FROM ledger_entries le
+    JOIN ledger_entry_tracking tr ON tr.ledger_entry_id = le.id
+    WHERE le.transaction_id = t.id
+    AND tr.option_id = $8
+  ))
+  AND ($9::text IS NULL OR t.source = $9)
+  AND ($10::bigint IS NULL
+    OR transaction_amount(t.id, $4) >= $10)
+  AND ($11::bigint IS NULL
+    OR transaction_amount(t.id, $4) <= $11)
+  AND ($12::text IS NULL
+    OR COALESCE(t.description, '') ILIKE '%' || $12 || '%')
+  AND ($13::uuid IS NULL OR EXISTS (
+    SELECT 1 FROM ledger_entries le
+    WHERE le.transaction_id = t.id
+    AND le.account_id = $13
+    AND le.account_id IS DISTINCT FROM $4
+  ))
+  AND ($14::uuid IS NULL OR t.contact_id = $14)
+  AND ($15::boolean IS NULL OR EXISTS (
+    SELECT 1 FROM ledger_entries le
+    JOIN ledger_entry_tags tg ON tg.ledger_entry_id = le.id
+    WHERE le.transaction_id = t.id
+  ) = $15)
+ORDER BY transaction_amount(t.id, $4) DESC, t.created_at DESC, t.id
+LIMIT $1 OFFSET $2
+`
+
+type ListTransactionsAmountDescParams struct {
+	Limit                 int32
+	Offset                int32
+	OrganisationID        pgtype.UUID
+	AccountID             pgtype.UUID
+	StartDate             pgtype.Date
+	EndDate               pgtype.Date
+	Tag                   pgtype.Text
+	TrackingOptionID      pgtype.UUID
+	Source                pgtype.Text
+	MinAmount             pgtype.Int8
+	MaxAmount             pgtype.Int8
+	DescriptionContains   pgtype.Text
+	CounterpartyAccountID pgtype.UUID
+	ContactID             pgtype.UUID
+	HasTag                pgtype.Bool
+}
+
+func (q *Queries) ListTransactionsAmountDesc(ctx context.Context, arg ListTransactionsAmountDescParams) ([]Transaction, error) {
+	rows, err := q.db.Query(ctx, listTransactionsAmountDesc,
+		arg.Limit,
+		arg.Offset,
+		arg.OrganisationID,
 		arg.AccountID,
 		arg.StartDate,
 		arg.EndDate,
+		arg.Tag,
+		arg.TrackingOptionID,
+		arg.Source,
+		arg.MinAmount,
+		arg.MaxAmount,
+		arg.DescriptionContains,
+		arg.CounterpartyAccountID,
+		arg.ContactID,
+		arg.HasTag,
 	)
 	if err != nil {
 		return nil, err
@@ -202,6 +751,822 @@ func (q *Queries) ListTransactions(ctx context.Context, arg ListTransactionsPara
 			&i.Source,
 			&i.PostedAt,
 			&i.CreatedAt,
+			&i.RequestHash,
+			&i.OrganisationID,
+			&i.PostedOn,
+			&i.CorrectsEntryID,
+			&i.ContactID,
+			&i.BillID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTransactionsByIdempotencyKeys = `-- name: ListTransactionsByIdempotencyKeys :many
+SELECT id, idempotency_key, description, source, posted_at, created_at, request_hash, organisation_id, posted_on, corrects_entry_id, contact_id, bill_id FROM transactions
+WHERE organisation_id = $1
+  AND idempotency_key = ANY($2::text[])
+`
+
+type ListTransactionsByIdempotencyKeysParams struct {
+	OrganisationID pgtype.UUID
+	Keys           []string
+}
+
+func (q *Queries) ListTransactionsByIdempotencyKeys(ctx context.Context, arg ListTransactionsByIdempotencyKeysParams) ([]Transaction, error) {
+	rows, err := q.db.Query(ctx, listTransactionsByIdempotencyKeys, arg.OrganisationID, arg.Keys)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Transaction
+	for rows.Next() {
+		var i Transaction
+		if err := rows.Scan(
+			&i.ID,
+			&i.IdempotencyKey,
+			&i.Description,
+			&i.Source,
+			&i.PostedAt,
+			&i.CreatedAt,
+			&i.RequestHash,
+			&i.OrganisationID,
+			&i.PostedOn,
+			&i.CorrectsEntryID,
+			&i.ContactID,
+			&i.BillID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTransactionsCreatedAtAsc = `-- name: ListTransactionsCreatedAtAsc :many
+SELECT t.* FROM transactions t
+WHERE
+  t.organisation_id = $3
+  AND ($4::uuid IS NULL OR EXISTS (
+    SELECT 1 FROM ledger_entries le 
+    WHERE le.transaction_id = t.id 
+    AND le.account_id = $4
+  ))
+  AND ($5::date IS NULL OR t.posted_on >= $5)
+  AND ($6::date IS NULL OR t.posted_on <= $6)
+  AND ($7::text IS NULL OR EXISTS (
+    SELECT 1 FROM ledger_entries le
+    JOIN ledger_entry_tags tg ON tg.ledger_entry_id = le.id
+    WHERE le.transaction_id = t.id
+    AND tg.tag = $7
+  ))
+  AND ($8::uuid IS NULL OR EXISTS (
+    SELECT 1 FROM ledger_entries le
+    JOIN ledger_entry_tracking tr ON tr.ledger_entry_id = le.id
+    WHERE le.transaction_id = t.id
+    AND tr.option_id = $8
+  ))
+  AND ($9::text IS NULL OR t.source = $9)
+  AND ($10::bigint IS NULL
+    OR transaction_amount(t.id, $4) >= $10)
+  AND ($11::bigint IS NULL
+    OR transaction_amount(t.id, $4) <= $11)
+  AND ($12::text IS NULL
+    OR COALESCE(t.description, '') ILIKE '%' || $12 || '%')
+  AND ($13::uuid IS NULL OR EXISTS (
+    SELECT 1 FROM ledger_entries le
+    WHERE le.transaction_id = t.id
+    AND le.account_id = $13
+    AND le.account_id IS DISTINCT FROM $4
+  ))
+  AND ($14::uuid IS NULL OR t.contact_id = $14)
+  AND ($15::boolean IS NULL OR EXISTS (
+    SELECT 1 FROM ledger_entries le
+    JOIN ledger_entry_tags tg ON tg.ledger_entry_id = le.id
+    WHERE le.transaction_id = t.id
+  ) = $15)
+ORDER BY t.created_at ASC, t.id
+LIMIT $1 OFFSET $2
+`
+
+type ListTransactionsCreatedAtAscParams struct {
+	Limit                 int32
+	Offset                int32
+	OrganisationID        pgtype.UUID
+	AccountID             pgtype.UUID
+	StartDate             pgtype.Date
+	EndDate               pgtype.Date
+	Tag                   pgtype.Text
+	TrackingOptionID      pgtype.UUID
+	Source                pgtype.Text
+	MinAmount             pgtype.Int8
+	MaxAmount             pgtype.Int8
+	DescriptionContains   pgtype.Text
+	CounterpartyAccountID pgtype.UUID
+	ContactID             pgtype.UUID
+	HasTag                pgtype.Bool
+}
+
+func (q *Queries) ListTransactionsCreatedAtAsc(ctx context.Context, arg ListTransactionsCreatedAtAscParams) ([]Transaction, error) {
+	rows, err := q.db.Query(ctx, listTransactionsCreatedAtAsc,
+		arg.Limit,
+		arg.Offset,
+		arg.OrganisationID,
+		arg.AccountID,
+		arg.StartDate,
+		arg.EndDate,
+		arg.Tag,
+		arg.TrackingOptionID,
+		arg.Source,
+		arg.MinAmount,
+		arg.MaxAmount,
+		arg.DescriptionContains,
+		arg.CounterpartyAccountID,
+		arg.ContactID,
+		arg.HasTag,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Transaction
+	for rows.Next() {
+		var i Transaction
+		if err := rows.Scan(
+			&i.ID,
+			&i.IdempotencyKey,
+			&i.Description,
+			&i.Source,
+			&i.PostedAt,
+			&i.CreatedAt,
+			&i.RequestHash,
+			&i.OrganisationID,
+			&i.PostedOn,
+			&i.CorrectsEntryID,
+			&i.ContactID,
+			&i.BillID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTransactionsCreatedAtDesc = `-- name: ListTransactionsCreatedAtDesc :many
+SELECT t.* FROM transactions t
+WHERE
+  t.organisation_id = $3
+  AND ($4::uuid IS NULL OR EXISTS (
+    SELECT 1 FROM ledger_entries le 
+    WHERE le.transaction_id = t.id 
+    AND le.account_id = $4
+  ))
+  AND ($5::date IS NULL OR t.posted_on >= $5)
+  AND ($6::date IS NULL OR t.posted_on <= $6)
+  AND ($7::text IS NULL OR EXISTS (
+    SELECT 1 FROM ledger_entries le
+    JOIN ledger_entry_tags tg ON tg.ledger_entry_id = le.id
+    WHERE le.transaction_id = t.id
+    AND tg.tag = $7
+  ))
+  AND ($8::uuid IS NULL OR EXISTS (
+    SELECT 1 FROM ledger_entries le
+    JOIN ledger_entry_tracking tr ON tr.ledger_entry_id = le.id
+    WHERE le.transaction_id = t.id
+    AND tr.option_id = $8
+  ))
+  AND ($9::text IS NULL OR t.source = $9)
+  AND ($10::bigint IS NULL
+    OR transaction_amount(t.id, $4) >= $10)
+  AND ($11::bigint IS NULL
+    OR transaction_amount(t.id, $4) <= $11)
+  AND ($12::text IS NULL
+    OR COALESCE(t.description, '') ILIKE '%' || $12 || '%')
+  AND ($13::uuid IS NULL OR EXISTS (
+    SELECT 1 FROM ledger_entries le
+    WHERE le.transaction_id = t.id
+    AND le.account_id = $13
+    AND le.account_id IS DISTINCT FROM $4
+  ))
+  AND ($14::uuid IS NULL OR t.contact_id = $14)
+  AND ($15::boolean IS NULL OR EXISTS (
+    SELECT 1 FROM ledger_entries le
+    JOIN ledger_entry_tags tg ON tg.ledger_entry_id = le.id
+    WHERE le.transaction_id = t.id
+  ) = $15)
+ORDER BY t.created_at DESC, t.id
+LIMIT $1 OFFSET $2
+`
+
+type ListTransactionsCreatedAtDescParams struct {
+	Limit                 int32
+	Offset                int32
+	OrganisationID        pgtype.UUID
+	AccountID             pgtype.UUID
+	StartDate             pgtype.Date
+	EndDate               pgtype.Date
+	Tag                   pgtype.Text
+	TrackingOptionID      pgtype.UUID
+	Source                pgtype.Text
+	MinAmount             pgtype.Int8
+	MaxAmount             pgtype.Int8
+	DescriptionContains   pgtype.Text
+	CounterpartyAccountID pgtype.UUID
+	ContactID             pgtype.UUID
+	HasTag                pgtype.Bool
+}
+
+func (q *Queries) ListTransactionsCreatedAtDesc(ctx context.Context, arg ListTransactionsCreatedAtDescParams) ([]Transaction, error) {
+	rows, err := q.db.Query(ctx, listTransactionsCreatedAtDesc,
+		arg.Limit,
+		arg.Offset,
+		arg.OrganisationID,
+		arg.AccountID,
+		arg.StartDate,
+		arg.EndDate,
+		arg.Tag,
+		arg.TrackingOptionID,
+		arg.Source,
+		arg.MinAmount,
+		arg.MaxAmount,
+		arg.DescriptionContains,
+		arg.CounterpartyAccountID,
+		arg.ContactID,
+		arg.HasTag,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Transaction
+	for rows.Next() {
+		var i Transaction
+		if err := rows.Scan(
+			&i.ID,
+			&i.IdempotencyKey,
+			&i.Description,
+			&i.Source,
+			&i.PostedAt,
+			&i.CreatedAt,
+			&i.RequestHash,
+			&i.OrganisationID,
+			&i.PostedOn,
+			&i.CorrectsEntryID,
+			&i.ContactID,
+			&i.BillID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTransactionsPostedAtAsc = `-- name: ListTransactionsPostedAtAsc :many
+SELECT t.* FROM transactions t
+WHERE
+  t.organisation_id = $3
+  AND ($4::uuid IS NULL OR EXISTS (
+    SELECT 1 FROM ledger_entries le 
+    WHERE le.transaction_id = t.id 
+    AND le.account_id = $4
+  ))
+  AND ($5::date IS NULL OR t.posted_on >= $5)
+  AND ($6::date IS NULL OR t.posted_on <= $6)
+  AND ($7::text IS NULL OR EXISTS (
+    SELECT 1 FROM ledger_entries le
+    JOIN ledger_entry_tags tg ON tg.ledger_entry_id = le.id
+    WHERE le.transaction_id = t.id
+    AND tg.tag = $7
+  ))
+  AND ($8::uuid IS NULL OR EXISTS (
+    SELECT 1 FROM ledger_entries le
+    JOIN ledger_entry_tracking tr ON tr.ledger_entry_id = le.id
+    WHERE le.transaction_id = t.id
+    AND tr.option_id = $8
+  ))
+  AND ($9::text IS NULL OR t.source = $9)
+  AND ($10::bigint IS NULL
+    OR transaction_amount(t.id, $4) >= $10)
+  AND ($11::bigint IS NULL
+    OR transaction_amount(t.id, $4) <= $11)
+  AND ($12::text IS NULL
+    OR COALESCE(t.description, '') ILIKE '%' || $12 || '%')
+  AND ($13::uuid IS NULL OR EXISTS (
+    SELECT 1 FROM ledger_entries le
+    WHERE le.transaction_id = t.id
+    AND le.account_id = $13
+    AND le.account_id IS DISTINCT FROM $4
+  ))
+  AND ($14::uuid IS NULL OR t.contact_id = $14)
+  AND ($15::boolean IS NULL OR EXISTS (
+    SELECT 1 FROM ledger_entries le
+    JOIN ledger_entry_tags tg ON tg.ledger_entry_id = le.id
+    WHERE le.transaction_id = t.id
+  ) = $15)
+ORDER BY t.posted_at ASC NULLS LAST, t.created_at ASC, t.id
+LIMIT $1 OFFSET $2
+`
+
+type ListTransactionsPostedAtAscParams struct {
+	Limit                 int32
+	Offset                int32
+	OrganisationID        pgtype.UUID
+	AccountID             pgtype.UUID
+	StartDate             pgtype.Date
+	EndDate               pgtype.Date
+	Tag                   pgtype.Text
+	TrackingOptionID      pgtype.UUID
+	Source                pgtype.Text
+	MinAmount             pgtype.Int8
+	MaxAmount             pgtype.Int8
+	DescriptionContains   pgtype.Text
+	CounterpartyAccountID pgtype.UUID
+	ContactID             pgtype.UUID
+	HasTag                pgtype.Bool
+}
+
+func (q *Queries) ListTransactionsPostedAtAsc(ctx context.Context, arg ListTransactionsPostedAtAscParams) ([]Transaction, error) {
+	rows, err := q.db.Query(ctx, listTransactionsPostedAtAsc,
+		arg.Limit,
+		arg.Offset,
+		arg.OrganisationID,
+		arg.AccountID,
+		arg.StartDate,
+		arg.EndDate,
+		arg.Tag,
+		arg.TrackingOptionID,
+		arg.Source,
+		arg.MinAmount,
+		arg.MaxAmount,
+		arg.DescriptionContains,
+		arg.CounterpartyAccountID,
+		arg.ContactID,
+		arg.HasTag,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Transaction
+	for rows.Next() {
+		var i Transaction
+		if err := rows.Scan(
+			&i.ID,
+			&i.IdempotencyKey,
+			&i.Description,
+			&i.Source,
+			&i.PostedAt,
+			&i.CreatedAt,
+			&i.RequestHash,
+			&i.OrganisationID,
+			&i.PostedOn,
+			&i.CorrectsEntryID,
+			&i.ContactID,
+			&i.BillID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTransactionsPostedAtDesc = `-- name: ListTransactionsPostedAtDesc :many
+SELECT t.* FROM transactions t
+WHERE
+  t.organisation_id = $3
+  AND ($4::uuid IS NULL OR EXISTS (
+    SELECT 1 FROM ledger_entries le 
+    WHERE le.transaction_id = t.id 
+    AND le.account_id = $4
+  ))
+  AND ($5::date IS NULL OR t.posted_on >= $5)
+  AND ($6::date IS NULL OR t.posted_on <= $6)
+  AND ($7::text IS NULL OR EXISTS (
+    SELECT 1 FROM ledger_entries le
+    JOIN ledger_entry_tags tg ON tg.ledger_entry_id = le.id
+    WHERE le.transaction_id = t.id
+    AND tg.tag = $7
+  ))
+  AND ($8::uuid IS NULL OR EXISTS (
+    SELECT 1 FROM ledger_entries le
+    JOIN ledger_entry_tracking tr ON tr.ledger_entry_id = le.id
+    WHERE le.transaction_id = t.id
+    AND tr.option_id = $8
+  ))
+  AND ($9::text IS NULL OR t.source = $9)
+  AND ($10::bigint IS NULL
+    OR transaction_amount(t.id, $4) >= $10)
+  AND ($11::bigint IS NULL
+    OR transaction_amount(t.id, $4) <= $11)
+  AND ($12::text IS NULL
+    OR COALESCE(t.description, '') ILIKE '%' || $12 || '%')
+  AND ($13::uuid IS NULL OR EXISTS (
+    SELECT 1 FROM ledger_entries le
+    WHERE le.transaction_id = t.id
+    AND le.account_id = $13
+    AND le.account_id IS DISTINCT FROM $4
+  ))
+  AND ($14::uuid IS NULL OR t.contact_id = $14)
+  AND ($15::boolean IS NULL OR EXISTS (
+    SELECT 1 FROM ledger_entries le
+    JOIN ledger_entry_tags tg ON tg.ledger_entry_id = le.id
+    WHERE le.transaction_id = t.id
+  ) = $15)
+ORDER BY t.posted_at DESC NULLS LAST, t.created_at DESC, t.id
+LIMIT $1 OFFSET $2
+`
+
+type ListTransactionsPostedAtDescParams struct {
+	Limit                 int32
+	Offset                int32
+	OrganisationID        pgtype.UUID
+	AccountID             pgtype.UUID
+	StartDate             pgtype.Date
+	EndDate               pgtype.Date
+	Tag                   pgtype.Text
+	TrackingOptionID      pgtype.UUID
+	Source                pgtype.Text
+	MinAmount             pgtype.Int8
+	MaxAmount             pgtype.Int8
+	DescriptionContains   pgtype.Text
+	CounterpartyAccountID pgtype.UUID
+	ContactID             pgtype.UUID
+	HasTag                pgtype.Bool
+}
+
+func (q *Queries) ListTransactionsPostedAtDesc(ctx context.Context, arg ListTransactionsPostedAtDescParams) ([]Transaction, error) {
+	rows, err := q.db.Query(ctx, listTransactionsPostedAtDesc,
+		arg.Limit,
+		arg.Offset,
+		arg.OrganisationID,
+		arg.AccountID,
+		arg.StartDate,
+		arg.EndDate,
+		arg.Tag,
+		arg.TrackingOptionID,
+		arg.Source,
+		arg.MinAmount,
+		arg.MaxAmount,
+		arg.DescriptionContains,
+		arg.CounterpartyAccountID,
+		arg.ContactID,
+		arg.HasTag,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Transaction
+	for rows.Next() {
+		var i Transaction
+		if err := rows.Scan(
+			&i.ID,
+			&i.IdempotencyKey,
+			&i.Description,
+			&i.Source,
+			&i.PostedAt,
+			&i.CreatedAt,
+			&i.RequestHash,
+			&i.OrganisationID,
+			&i.PostedOn,
+			&i.CorrectsEntryID,
+			&i.ContactID,
+			&i.BillID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTransactionsPostedOnAsc = `-- name: ListTransactionsPostedOnAsc :many
+SELECT t.* FROM transactions t
+WHERE
+  t.organisation_id = $3
+  AND ($4::uuid IS NULL OR EXISTS (
+    SELECT 1 FROM ledger_entries le 
+    WHERE le.transaction_id = t.id 
+    AND le.account_id = $4
+  ))
+  AND ($5::date IS NULL OR t.posted_on >= $5)
+  AND ($6::date IS NULL OR t.posted_on <= $6)
+  AND ($7::text IS NULL OR EXISTS (
+    SELECT 1 FROM ledger_entries le
+    JOIN ledger_entry_tags tg ON tg.ledger_entry_id = le.id
+    WHERE le.transaction_id = t.id
+    AND tg.tag = $7
+  ))
+  AND ($8::uuid IS NULL OR EXISTS (
+    SELECT 1 FROM ledger_entries le
+    JOIN ledger_entry_tracking tr ON tr.ledger_entry_id = le.id
+    WHERE le.transaction_id = t.id
+    AND tr.option_id = $8
+  ))
+  AND ($9::text IS NULL OR t.source = $9)
+  AND ($10::bigint IS NULL
+    OR transaction_amount(t.id, $4) >= $10)
+  AND ($11::bigint IS NULL
+    OR transaction_amount(t.id, $4) <= $11)
+  AND ($12::text IS NULL
+    OR COALESCE(t.description, '') ILIKE '%' || $12 || '%')
+  AND ($13::uuid IS NULL OR EXISTS (
+    SELECT 1 FROM ledger_entries le
+    WHERE le.transaction_id = t.id
+    AND le.account_id = $13
+    AND le.account_id IS DISTINCT FROM $4
+  ))
+  AND ($14::uuid IS NULL OR t.contact_id = $14)
+  AND ($15::boolean IS NULL OR EXISTS (
+    SELECT 1 FROM ledger_entries le
+    JOIN ledger_entry_tags tg ON tg.ledger_entry_id = le.id
+    WHERE le.transaction_id = t.id
+  ) = $15)
+ORDER BY t.posted_on ASC, t.created_at ASC, t.id
+LIMIT $1 OFFSET $2
+`
+
+type ListTransactionsPostedOnAscParams struct {
+	Limit                 int32
+	Offset                int32
+	OrganisationID        pgtype.UUID
+	AccountID             pgtype.UUID
+	StartDate             pgtype.Date
+	EndDate               pgtype.Date
+	Tag                   pgtype.Text
+	TrackingOptionID      pgtype.UUID
+	Source                pgtype.Text
+	MinAmount             pgtype.Int8
+	MaxAmount             pgtype.Int8
+	DescriptionContains   pgtype.Text
+	CounterpartyAccountID pgtype.UUID
+	ContactID             pgtype.UUID
+	HasTag                pgtype.Bool
+}
+
+func (q *Queries) ListTransactionsPostedOnAsc(ctx context.Context, arg ListTransactionsPostedOnAscParams) ([]Transaction, error) {
+	rows, err := q.db.Query(ctx, listTransactionsPostedOnAsc,
+		arg.Limit,
+		arg.Offset,
+		arg.OrganisationID,
+		arg.AccountID,
+		arg.StartDate,
+		arg.EndDate,
+		arg.Tag,
+		arg.TrackingOptionID,
+		arg.Source,
+		arg.MinAmount,
+		arg.MaxAmount,
+		arg.DescriptionContains,
+		arg.CounterpartyAccountID,
+		arg.ContactID,
+		arg.HasTag,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Transaction
+	for rows.Next() {
+		var i Transaction
+		if err := rows.Scan(
+			&i.ID,
+			&i.IdempotencyKey,
+			&i.Description,
+			&i.Source,
+			&i.PostedAt,
+			&i.CreatedAt,
+			&i.RequestHash,
+			&i.OrganisationID,
+			&i.PostedOn,
+			&i.CorrectsEntryID,
+			&i.ContactID,
+			&i.BillID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const releaseExpiredIdempotencyKey = `-- name: ReleaseExpiredIdempotencyKey :execrows
+UPDATE transactions
+SET idempotency_key = NULL,
+    request_hash = NULL
+WHERE organisation_id = $1
+  AND idempotency_key = $2
+  AND created_at < $3::timestamptz
+`
+
+type ReleaseExpiredIdempotencyKeyParams struct {
+	OrganisationID pgtype.UUID
+	IdempotencyKey pgtype.Text
+	Cutoff         pgtype.Timestamptz
+}
+
+func (q *Queries) ReleaseExpiredIdempotencyKey(ctx context.Context, arg ReleaseExpiredIdempotencyKeyParams) (int64, error) {
+	result, err := q.db.Exec(ctx, releaseExpiredIdempotencyKey, arg.OrganisationID, arg.IdempotencyKey, arg.Cutoff)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const releaseExpiredIdempotencyKeys = `-- name: ReleaseExpiredIdempotencyKeys :execrows
+UPDATE transactions
+SET idempotency_key = NULL,
+    request_hash = NULL
+WHERE idempotency_key IS NOT NULL
+  AND created_at < $1::timestamptz
+`
+
+// Spans organisations: run it with app.rls_bypass set.
+func (q *Queries) ReleaseExpiredIdempotencyKeys(ctx context.Context, cutoff pgtype.Timestamptz) (int64, error) {
+	result, err := q.db.Exec(ctx, releaseExpiredIdempotencyKeys, cutoff)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const releaseExpiredIdempotencyKeysIn = `-- name: ReleaseExpiredIdempotencyKeysIn :execrows
+UPDATE transactions
+SET idempotency_key = NULL,
+    request_hash = NULL
+WHERE organisation_id = $1
+  AND idempotency_key = ANY($2::text[])
+  AND created_at < $3::timestamptz
+`
+
+type ReleaseExpiredIdempotencyKeysInParams struct {
+	OrganisationID pgtype.UUID
+	Keys           []string
+	Cutoff         pgtype.Timestamptz
+}
+
+func (q *Queries) ReleaseExpiredIdempotencyKeysIn(ctx context.Context, arg ReleaseExpiredIdempotencyKeysInParams) (int64, error) {
+	result, err := q.db.Exec(ctx, releaseExpiredIdempotencyKeysIn, arg.OrganisationID, arg.Keys, arg.Cutoff)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const searchTransactions = `-- name: SearchTransactions :many
+SELECT
+  t.id, t.idempotency_key, t.description, t.source, t.posted_at, t.created_at,
+  t.request_hash, t.organisation_id, t.posted_on, t.corrects_entry_id, t.contact_id, t.bill_id,
+  (ts_rank(to_tsvector('english', COALESCE(t.description, '')), s.query)
+    + word_similarity($3::text, COALESCE(t.description, '')))::float8 AS rank,
+  ts_headline('english', COALESCE(t.description, ''), s.query,
+    'HighlightAll=true, StartSel=' || chr(2) || ', StopSel=' || chr(3))::text AS headline
+FROM transactions t
+CROSS JOIN (SELECT websearch_to_tsquery('english', $3::text) AS query) s
+WHERE
+  t.organisation_id = $4
+  AND ($3::text = ''
+    OR to_tsvector('english', COALESCE(t.description, '')) @@ s.query
+    OR $3::text <% COALESCE(t.description, ''))
+  AND ($5::uuid IS NULL OR EXISTS (
+    SELECT 1 FROM ledger_entries le
+    WHERE le.transaction_id = t.id
+    AND le.account_id = $5
+  ))
+  AND ($6::date IS NULL OR t.posted_on >= $6)
+  AND ($7::date IS NULL OR t.posted_on <= $7)
+  AND ($8::uuid IS NULL OR EXISTS (
+    SELECT 1 FROM ledger_entries le
+    JOIN ledger_entry_tracking tr ON tr.ledger_entry_id = le.id
+    WHERE le.transaction_id = t.id
+    AND tr.option_id = $8
+  ))
+  AND (cardinality($9::text[]) = 0 OR (
+    SELECT count(DISTINCT tg.tag) FROM ledger_entries le
+    JOIN ledger_entry_tags tg ON tg.ledger_entry_id = le.id
+    WHERE le.transaction_id = t.id
+    AND tg.tag = ANY($9::text[])
+  ) = cardinality($9::text[]))
+  AND (cardinality($10::text[]) = 0 OR EXISTS (
+    SELECT 1 FROM ledger_entries le
+    WHERE le.transaction_id = t.id
+    AND NOT EXISTS (
+      SELECT 1 FROM unnest(
+        $10::text[],
+        $11::bigint[]
+      ) AS f(op, amount_minor)
+      WHERE NOT CASE f.op
+        WHEN '=' THEN le.amount_minor = f.amount_minor
+        WHEN '>' THEN le.amount_minor > f.amount_minor
+        WHEN '>=' THEN le.amount_minor >= f.amount_minor
+        WHEN '<' THEN le.amount_minor < f.amount_minor
+        WHEN '<=' THEN le.amount_minor <= f.amount_minor
+      END
+    )
+  ))
+ORDER BY rank DESC, t.posted_on DESC, t.created_at DESC
+LIMIT $1 OFFSET $2
+`
+
+type SearchTransactionsParams struct {
+	Limit            int32
+	Offset           int32
+	Text             string
+	OrganisationID   pgtype.UUID
+	AccountID        pgtype.UUID
+	StartDate        pgtype.Date
+	EndDate          pgtype.Date
+	TrackingOptionID pgtype.UUID
+	Tags             []string
+	AmountOps        []string
+	AmountsMinor     []int64
+}
+
+type SearchTransactionsRow struct {
+	ID              pgtype.UUID
+	IdempotencyKey  pgtype.Text
+	Description     pgtype.Text
+	Source          string
+	PostedAt        pgtype.Timestamptz
+	CreatedAt       pgtype.Timestamptz
+	RequestHash     []byte
+	OrganisationID  pgtype.UUID
+	PostedOn        pgtype.Date
+	CorrectsEntryID pgtype.UUID
+	ContactID       pgtype.UUID
+	BillID          pgtype.UUID
+	Rank            float64
+	Headline        string
+}
+
+// Free text matches the description's words, or failing that trigram word
+// similarity, so misspellings still find the transaction. Amount terms must
+// all hold for one ledger entry; tag terms must all appear on the
+// transaction's entries. The headline marks matched words with chr(2) and
+// chr(3) for the caller to escape and highlight.
+func (q *Queries) SearchTransactions(ctx context.Context, arg SearchTransactionsParams) ([]SearchTransactionsRow, error) {
+	rows, err := q.db.Query(ctx, searchTransactions,
+		arg.Limit,
+		arg.Offset,
+		arg.Text,
+		arg.OrganisationID,
+		arg.AccountID,
+		arg.StartDate,
+		arg.EndDate,
+		arg.TrackingOptionID,
+		arg.Tags,
+		arg.AmountOps,
+		arg.AmountsMinor,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SearchTransactionsRow
+	for rows.Next() {
+		var i SearchTransactionsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.IdempotencyKey,
+			&i.Description,
+			&i.Source,
+			&i.PostedAt,
+			&i.CreatedAt,
+			&i.RequestHash,
+			&i.OrganisationID,
+			&i.PostedOn,
+			&i.CorrectsEntryID,
+			&i.ContactID,
+			&i.BillID,
+			&i.Rank,
+			&i.Headline,
 		); err != nil {
 			return nil, err
 		}
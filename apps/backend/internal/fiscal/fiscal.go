@@ -0,0 +1,159 @@
+// Package fiscal maps reporting periods such as "FY2026-Q3" or "2026-05" onto
+// time ranges in an organisation's own calendar: its timezone and the month
+// its financial year starts.
+package fiscal
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+
+	// organisations pick arbitrary IANA zones; don't depend on the host's
+	// zoneinfo being installed
+	_ "time/tzdata"
+)
+
+// Calendar is an organisation's reporting calendar.
+type Calendar struct {
+	Location *time.Location
+	// StartMonth is the first month of the financial year: April in New
+	// Zealand, July in Australia, January for calendar years.
+	StartMonth time.Month
+}
+
+// UTC is the calendar-year calendar in UTC, used when an organisation has
+// not configured its own.
+var UTC = Calendar{Location: time.UTC, StartMonth: time.January}
+
+// NewCalendar loads the named IANA timezone.
+func NewCalendar(timezone string, startMonth int) (Calendar, error) {
+	if startMonth < 1 || startMonth > 12 {
+		return Calendar{}, fmt.Errorf("fiscal year start month %d out of range", startMonth)
+	}
+	loc, err := LoadLocation(timezone)
+	if err != nil {
+		return Calendar{}, err
+	}
+	return Calendar{Location: loc, StartMonth: time.Month(startMonth)}, nil
+}
+
+// LoadLocation is time.LoadLocation without the "Local" zone, whose meaning
+// depends on the server rather than the organisation.
+func LoadLocation(name string) (*time.Location, error) {
+	if name == "" || name == "Local" {
+		return nil, fmt.Errorf("unknown time zone %q", name)
+	}
+	return time.LoadLocation(name)
+}
+
+// Date returns the calendar date t falls on in the organisation's timezone.
+// Dates are carried as midnight UTC, the way pgx reads DATE columns.
+func (c Calendar) Date(t time.Time) time.Time {
+	return civil(t.In(c.Location))
+}
+
+func civil(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+// Period is a half-open range [Start, End) with the label it was parsed from.
+type Period struct {
+	Label string
+	Start time.Time
+	End   time.Time
+}
+
+// Contains reports whether t falls inside the period.
+func (p Period) Contains(t time.Time) bool {
+	return !t.Before(p.Start) && t.Before(p.End)
+}
+
+// FirstDay returns the period's first date.
+func (p Period) FirstDay() time.Time {
+	return civil(p.Start)
+}
+
+// LastDay returns the period's last date, inclusive.
+func (p Period) LastDay() time.Time {
+	return civil(p.End.AddDate(0, 0, -1))
+}
+
+// ErrInvalidPeriod is returned for labels Parse does not understand.
+var ErrInvalidPeriod = errors.New("invalid period (use FY2026, FY2026-Q3, FY2026-H1 or 2026-05)")
+
+var (
+	fiscalPattern = regexp.MustCompile(`^FY(\d{4})(?:-([QH])([1-4]))?$`)
+	monthPattern  = regexp.MustCompile(`^(\d{4})-(\d{2})$`)
+)
+
+// Parse turns a period label into its range:
+//
+//	FY2026     the financial year ending in 2026
+//	FY2026-Q3  its third quarter
+//	FY2026-H1  its first half
+//	2026-05    a calendar month
+//
+// Financial years are named after the calendar year they end in, so with an
+// April start FY2026 runs from 1 April 2025 to 31 March 2026.
+func (c Calendar) Parse(label string) (Period, error) {
+	if m := fiscalPattern.FindStringSubmatch(label); m != nil {
+		year, _ := strconv.Atoi(m[1])
+		start := c.YearStart(year)
+		months := 12
+		if m[2] != "" {
+			n, _ := strconv.Atoi(m[3])
+			size := 3
+			if m[2] == "H" {
+				if n > 2 {
+					return Period{}, ErrInvalidPeriod
+				}
+				size = 6
+			}
+			start = start.AddDate(0, (n-1)*size, 0)
+			months = size
+		}
+		return Period{Label: label, Start: start, End: start.AddDate(0, months, 0)}, nil
+	}
+
+	if m := monthPattern.FindStringSubmatch(label); m != nil {
+		year, _ := strconv.Atoi(m[1])
+		month, _ := strconv.Atoi(m[2])
+		if month < 1 || month > 12 {
+			return Period{}, ErrInvalidPeriod
+		}
+		start := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, c.Location)
+		return Period{Label: label, Start: start, End: start.AddDate(0, 1, 0)}, nil
+	}
+
+	return Period{}, ErrInvalidPeriod
+}
+
+// YearStart returns midnight, local time, on the first day of financial year
+// fy.
+func (c Calendar) YearStart(fy int) time.Time {
+	year := fy
+	if c.StartMonth != time.January {
+		year = fy - 1
+	}
+	return time.Date(year, c.StartMonth, 1, 0, 0, 0, 0, c.Location)
+}
+
+// Year returns the financial year containing t.
+func (c Calendar) Year(t time.Time) int {
+	t = t.In(c.Location)
+	fy := t.Year()
+	if c.StartMonth != time.January && t.Month() >= c.StartMonth {
+		fy++
+	}
+	return fy
+}
+
+// YearOf returns the whole financial year containing t.
+func (c Calendar) YearOf(t time.Time) Period {
+	fy := c.Year(t)
+	start := c.YearStart(fy)
+	return Period{Label: fmt.Sprintf("FY%d", fy), Start: start, End: start.AddDate(1, 0, 0)}
+}
@@ -0,0 +1,84 @@
+package fiscal
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func calendar(t *testing.T, tz string, start int) Calendar {
+	t.Helper()
+	c, err := NewCalendar(tz, start)
+	require.NoError(t, err)
+	return c
+}
+
+func TestParseFiscalPeriods(t *testing.T) {
+	nz := calendar(t, "Pacific/Auckland", 4)
+	au := calendar(t, "Australia/Sydney", 7)
+
+	tests := []struct {
+		cal        Calendar
+		label      string
+		start, end string
+	}{
+		{nz, "FY2026", "2025-04-01T00:00:00+13:00", "2026-04-01T00:00:00+13:00"},
+		{nz, "FY2026-Q3", "2025-10-01T00:00:00+13:00", "2026-01-01T00:00:00+13:00"},
+		{nz, "FY2026-Q4", "2026-01-01T00:00:00+13:00", "2026-04-01T00:00:00+13:00"},
+		{nz, "FY2026-H2", "2025-10-01T00:00:00+13:00", "2026-04-01T00:00:00+13:00"},
+		{au, "FY2026", "2025-07-01T00:00:00+10:00", "2026-07-01T00:00:00+10:00"},
+		{au, "FY2026-Q1", "2025-07-01T00:00:00+10:00", "2025-10-01T00:00:00+10:00"},
+		{UTC, "FY2026", "2026-01-01T00:00:00Z", "2027-01-01T00:00:00Z"},
+		{nz, "2026-05", "2026-05-01T00:00:00+12:00", "2026-06-01T00:00:00+12:00"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.label, func(t *testing.T) {
+			p, err := tt.cal.Parse(tt.label)
+			require.NoError(t, err)
+			require.Equal(t, tt.start, p.Start.Format(time.RFC3339))
+			require.Equal(t, tt.end, p.End.Format(time.RFC3339))
+		})
+	}
+}
+
+func TestParseRejectsUnknownLabels(t *testing.T) {
+	for _, label := range []string{"", "FY26", "FY2026-Q5", "FY2026-H3", "2026-13", "2026-5", "fy2026"} {
+		_, err := UTC.Parse(label)
+		require.ErrorIs(t, err, ErrInvalidPeriod, label)
+	}
+}
+
+func TestYearUsesLocalTime(t *testing.T) {
+	nz := calendar(t, "Pacific/Auckland", 4)
+
+	// 31 March 11:30 UTC is already 1 April in Auckland
+	at := time.Date(2026, time.March, 31, 11, 30, 0, 0, time.UTC)
+	require.Equal(t, 2027, nz.Year(at))
+	require.Equal(t, "FY2027", nz.YearOf(at).Label)
+	require.True(t, nz.YearOf(at).Contains(at))
+	require.Equal(t, 2026, nz.Year(at.Add(-time.Hour)))
+}
+
+func TestNewCalendarRejectsBadSettings(t *testing.T) {
+	_, err := NewCalendar("Mars/Olympus_Mons", 1)
+	require.Error(t, err)
+	_, err = NewCalendar("Local", 1)
+	require.Error(t, err)
+	_, err = NewCalendar("UTC", 13)
+	require.Error(t, err)
+}
+
+func TestPeriodDaysAndLocalDates(t *testing.T) {
+	nz := calendar(t, "Pacific/Auckland", 4)
+
+	p, err := nz.Parse("FY2026")
+	require.NoError(t, err)
+	require.Equal(t, "2025-04-01", p.FirstDay().Format(time.DateOnly))
+	require.Equal(t, "2026-03-31", p.LastDay().Format(time.DateOnly))
+
+	// a bank line dated 31 March in Auckland is still 30 March in UTC
+	at := time.Date(2026, time.March, 30, 20, 0, 0, 0, time.UTC)
+	require.Equal(t, "2026-03-31", nz.Date(at).Format(time.DateOnly))
+	require.Equal(t, "2026-03-30", UTC.Date(at).Format(time.DateOnly))
+}
@@ -9,36 +9,41 @@ import (
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgtype"
 
+	"github.com/LBaronceli/go-figure/internal/audit"
 	db "github.com/LBaronceli/go-figure/internal/db/sqlc"
+	"github.com/LBaronceli/go-figure/internal/models"
 )
 
 type createAccountRequest struct {
-	Name     string `json:"name"`
-	Type     string `json:"type"`
-	Currency string `json:"currency"`
+	Name     string `json:"name" openapi:"minLength=1,maxLength=500"`
+	Type     string `json:"type" openapi:"enum=asset|liability|expense|income|equity"`
+	Currency string `json:"currency" openapi:"minLength=3,maxLength=3" doc:"ISO 4217 code"`
 }
 
 type updateAccountRequest struct {
-	Name *string `json:"name"`
-	Type *string `json:"type"`
+	Name *string `json:"name" openapi:"maxLength=500"`
+	Type *string `json:"type" openapi:"enum=asset|liability|expense|income|equity"`
 }
 
 type accountResponse struct {
-	ID        string `json:"id"`
-	Name      string `json:"name"`
-	Type      string `json:"type"`
-	Currency  string `json:"currency"`
-	CreatedAt string `json:"created_at"`
-	UpdatedAt string `json:"updated_at"`
+	ID         string `json:"id" openapi:"format=uuid"`
+	Name       string `json:"name"`
+	Type       string `json:"type"`
+	Currency   string `json:"currency"`
+	CreatedAt  string `json:"created_at" openapi:"format=date-time"`
+	UpdatedAt  string `json:"updated_at" openapi:"format=date-time"`
+	ArchivedAt string `json:"archived_at,omitempty" openapi:"format=date-time"`
 }
 
 // POST /accounts
 func (s *Server) createAccount(w http.ResponseWriter, r *http.Request) {
 	var req createAccountRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "invalid json", http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, CodeInvalidJSON, "invalid json")
 		return
 	}
 
@@ -46,29 +51,71 @@ func (s *Server) createAccount(w http.ResponseWriter, r *http.Request) {
 	req.Type = strings.TrimSpace(strings.ToLower(req.Type))
 	req.Currency = strings.TrimSpace(strings.ToUpper(req.Currency))
 
-	if req.Name == "" || req.Type == "" || req.Currency == "" {
-		http.Error(w, "missing fields", http.StatusBadRequest)
+	var errs validationErrors
+	if req.Name == "" {
+		errs.add("name", CodeRequired, "name is required")
+	} else if len(req.Name) > maxStringLength {
+		errs.add("name", CodeTooLong, "name too long")
+	}
+	if req.Type == "" {
+		errs.add("type", CodeRequired, "type is required")
+	} else if !models.AccountType(req.Type).IsValid() {
+		errs.add("type", CodeInvalidValue, "invalid type (must be asset, liability, expense, income, or equity)")
+	}
+	if req.Currency == "" {
+		errs.add("currency", CodeRequired, "currency is required")
+	} else if len(req.Currency) != 3 || !models.IsValidCurrency(req.Currency) {
+		errs.add("currency", CodeInvalidValue, "currency must be a 3-letter ISO 4217 code")
+	}
+	if !errs.empty() {
+		writeValidationErrors(w, errs)
+		return
+	}
+
+	org := tenantFrom(r.Context())
+	tx, qtx, err := org.begin(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to begin transaction")
 		return
 	}
+	defer tx.Rollback(r.Context())
 
-	acc, err := s.q.CreateAccount(r.Context(), db.CreateAccountParams{
-		Name:     req.Name,
-		Type:     req.Type,
-		Currency: req.Currency,
+	acc, err := qtx.CreateAccount(r.Context(), db.CreateAccountParams{
+		OrganisationID: org.id,
+		Name:           req.Name,
+		Type:           req.Type,
+		Currency:       req.Currency,
 	})
 	if err != nil {
-		http.Error(w, "failed to create account", http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to create account")
 		return
 	}
 
-	writeJSON(w, http.StatusCreated, toAccountResponse(acc))
+	resp := toAccountResponse(acc)
+	if err := recordAudit(r.Context(), qtx, org, audit.EntityAccount, acc.ID, audit.ActionCreate, nil, resp); err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to record audit event")
+		return
+	}
+
+	if err := tx.Commit(r.Context()); err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to commit transaction")
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, resp)
 }
 
 // GET /accounts
 func (s *Server) listAccounts(w http.ResponseWriter, r *http.Request) {
-	accounts, err := s.q.ListAccounts(r.Context())
+	includeArchived := r.URL.Query().Get("include_archived") == "true"
+
+	org := tenantFrom(r.Context())
+	accounts, err := org.q.ListAccounts(r.Context(), db.ListAccountsParams{
+		OrganisationID:  org.id,
+		IncludeArchived: includeArchived,
+	})
 	if err != nil {
-		http.Error(w, "failed to list accounts", http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to list accounts")
 		return
 	}
 
@@ -85,13 +132,14 @@ func (s *Server) getAccount(w http.ResponseWriter, r *http.Request) {
 	idStr := chi.URLParam(r, "id")
 	id, err := parseUUID(idStr)
 	if err != nil {
-		http.Error(w, "invalid id", http.StatusBadRequest)
+		writeFieldError(w, http.StatusBadRequest, CodeInvalidFormat, "id", "invalid id")
 		return
 	}
 
-	acc, err := s.q.GetAccount(r.Context(), id)
+	org := tenantFrom(r.Context())
+	acc, err := org.q.GetAccount(r.Context(), db.GetAccountParams{OrganisationID: org.id, ID: id})
 	if err != nil {
-		http.Error(w, "account not found", http.StatusNotFound)
+		writeError(w, http.StatusNotFound, CodeNotFound, "account not found")
 		return
 	}
 
@@ -103,54 +151,100 @@ func (s *Server) updateAccount(w http.ResponseWriter, r *http.Request) {
 	idStr := chi.URLParam(r, "id")
 	id, err := parseUUID(idStr)
 	if err != nil {
-		http.Error(w, "invalid id", http.StatusBadRequest)
+		writeFieldError(w, http.StatusBadRequest, CodeInvalidFormat, "id", "invalid id")
 		return
 	}
 
 	var req updateAccountRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "invalid json", http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, CodeInvalidJSON, "invalid json")
 		return
 	}
 
+	org := tenantFrom(r.Context())
+
 	// Prepare update parameters
 	params := db.UpdateAccountParams{
-		ID: id,
+		OrganisationID: org.id,
+		ID:             id,
 	}
 
 	// Handle partial updates
+	var errs validationErrors
 	if req.Name != nil {
 		cleanName := strings.TrimSpace(*req.Name)
-		if cleanName != "" {
+		if len(cleanName) > maxStringLength {
+			errs.add("name", CodeTooLong, "name too long")
+		} else if cleanName != "" {
 			params.Name = pgtype.Text{String: cleanName, Valid: true}
 		}
 	}
 
 	if req.Type != nil {
 		cleanType := strings.TrimSpace(strings.ToLower(*req.Type))
-		if cleanType != "" {
+		if cleanType != "" && !models.AccountType(cleanType).IsValid() {
+			errs.add("type", CodeInvalidValue, "invalid type (must be asset, liability, expense, income, or equity)")
+		} else if cleanType != "" {
 			params.Type = pgtype.Text{String: cleanType, Valid: true}
 		}
 	}
 
+	if !errs.empty() {
+		writeValidationErrors(w, errs)
+		return
+	}
+
 	if !params.Name.Valid && !params.Type.Valid {
-		http.Error(w, "nothing to update", http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, CodeNothingToUpdate, "nothing to update")
+		return
+	}
+
+	tx, qtx, err := org.begin(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to begin transaction")
 		return
 	}
+	defer tx.Rollback(r.Context())
 
 	// check existence first
-	if _, err := s.q.GetAccount(r.Context(), id); err != nil {
-		http.Error(w, "account not found", http.StatusNotFound)
+	current, err := qtx.GetAccount(r.Context(), db.GetAccountParams{OrganisationID: org.id, ID: id})
+	if err != nil {
+		writeError(w, http.StatusNotFound, CodeNotFound, "account not found")
 		return
 	}
 
-	acc, err := s.q.UpdateAccount(r.Context(), params)
+	// Retyping an account with postings would silently rewrite every report
+	// that already used it, so the type is frozen once history exists.
+	if params.Type.Valid && params.Type.String != current.Type {
+		hasEntries, err := qtx.AccountHasLedgerEntries(r.Context(), db.AccountHasLedgerEntriesParams{OrganisationID: org.id, AccountID: id})
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, CodeInternal, "failed to check account history")
+			return
+		}
+		if hasEntries {
+			writeError(w, http.StatusConflict, CodeAccountTypeLocked, "account type cannot change once it has ledger entries")
+			return
+		}
+	}
+
+	acc, err := qtx.UpdateAccount(r.Context(), params)
 	if err != nil {
-		http.Error(w, "failed to update account", http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to update account")
 		return
 	}
 
-	writeJSON(w, http.StatusOK, toAccountResponse(acc))
+	resp := toAccountResponse(acc)
+	if err := recordAudit(r.Context(), qtx, org, audit.EntityAccount, acc.ID, audit.ActionUpdate, toAccountResponse(current), resp); err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to record audit event")
+		return
+	}
+
+	if err := tx.Commit(r.Context()); err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to commit transaction")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, resp)
 }
 
 // DELETE /accounts/{id}
@@ -158,23 +252,154 @@ func (s *Server) deleteAccount(w http.ResponseWriter, r *http.Request) {
 	idStr := chi.URLParam(r, "id")
 	id, err := parseUUID(idStr)
 	if err != nil {
-		http.Error(w, "invalid id", http.StatusBadRequest)
+		writeFieldError(w, http.StatusBadRequest, CodeInvalidFormat, "id", "invalid id")
+		return
+	}
+
+	org := tenantFrom(r.Context())
+	tx, qtx, err := org.begin(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to begin transaction")
+		return
+	}
+	defer tx.Rollback(r.Context())
+
+	current, err := qtx.GetAccount(r.Context(), db.GetAccountParams{OrganisationID: org.id, ID: id})
+	if err != nil {
+		writeError(w, http.StatusNotFound, CodeNotFound, "account not found")
+		return
+	}
+
+	// Accounts with history can only be archived; hard delete is reserved for
+	// accounts that never had a posting.
+	hasEntries, err := qtx.AccountHasLedgerEntries(r.Context(), db.AccountHasLedgerEntriesParams{OrganisationID: org.id, AccountID: id})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to check account history")
+		return
+	}
+	if hasEntries {
+		writeError(w, http.StatusConflict, CodeAccountHasLedgerEntries, "account has ledger entries; archive it instead")
 		return
 	}
 
-	if _, err := s.q.GetAccount(r.Context(), id); err != nil {
-		http.Error(w, "account not found", http.StatusNotFound)
+	if err := qtx.DeleteAccount(r.Context(), db.DeleteAccountParams{OrganisationID: org.id, ID: id}); err != nil {
+		// An entry may have been posted between the check and the delete.
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" { // foreign_key_violation
+			writeError(w, http.StatusConflict, CodeAccountHasLedgerEntries, "account has ledger entries; archive it instead")
+			return
+		}
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to delete account")
 		return
 	}
 
-	if err := s.q.DeleteAccount(r.Context(), id); err != nil {
-		http.Error(w, "failed to delete account", http.StatusInternalServerError)
+	if err := recordAudit(r.Context(), qtx, org, audit.EntityAccount, current.ID, audit.ActionDelete, toAccountResponse(current), nil); err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to record audit event")
+		return
+	}
+
+	if err := tx.Commit(r.Context()); err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to commit transaction")
 		return
 	}
 
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// POST /accounts/{id}/archive
+func (s *Server) archiveAccount(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := parseUUID(idStr)
+	if err != nil {
+		writeFieldError(w, http.StatusBadRequest, CodeInvalidFormat, "id", "invalid id")
+		return
+	}
+
+	org := tenantFrom(r.Context())
+	tx, qtx, err := org.begin(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to begin transaction")
+		return
+	}
+	defer tx.Rollback(r.Context())
+
+	current, err := qtx.GetAccount(r.Context(), db.GetAccountParams{OrganisationID: org.id, ID: id})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			writeError(w, http.StatusNotFound, CodeNotFound, "account not found")
+			return
+		}
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to archive account")
+		return
+	}
+
+	acc, err := qtx.ArchiveAccount(r.Context(), db.ArchiveAccountParams{OrganisationID: org.id, ID: id})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to archive account")
+		return
+	}
+
+	resp := toAccountResponse(acc)
+	if err := recordAudit(r.Context(), qtx, org, audit.EntityAccount, acc.ID, audit.ActionArchive, toAccountResponse(current), resp); err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to record audit event")
+		return
+	}
+
+	if err := tx.Commit(r.Context()); err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to commit transaction")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// POST /accounts/{id}/unarchive
+func (s *Server) unarchiveAccount(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := parseUUID(idStr)
+	if err != nil {
+		writeFieldError(w, http.StatusBadRequest, CodeInvalidFormat, "id", "invalid id")
+		return
+	}
+
+	org := tenantFrom(r.Context())
+	tx, qtx, err := org.begin(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to begin transaction")
+		return
+	}
+	defer tx.Rollback(r.Context())
+
+	current, err := qtx.GetAccount(r.Context(), db.GetAccountParams{OrganisationID: org.id, ID: id})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			writeError(w, http.StatusNotFound, CodeNotFound, "account not found")
+			return
+		}
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to unarchive account")
+		return
+	}
+
+	acc, err := qtx.UnarchiveAccount(r.Context(), db.UnarchiveAccountParams{OrganisationID: org.id, ID: id})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to unarchive account")
+		return
+	}
+
+	resp := toAccountResponse(acc)
+	if err := recordAudit(r.Context(), qtx, org, audit.EntityAccount, acc.ID, audit.ActionUnarchive, toAccountResponse(current), resp); err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to record audit event")
+		return
+	}
+
+	if err := tx.Commit(r.Context()); err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to commit transaction")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
 // Helpers
 
 func writeJSON(w http.ResponseWriter, status int, v any) {
@@ -210,12 +435,18 @@ func toAccountResponse(a db.Account) accountResponse {
 		updated = a.UpdatedAt.Time.Format(time.RFC3339Nano)
 	}
 
+	archived := ""
+	if a.ArchivedAt.Valid {
+		archived = a.ArchivedAt.Time.Format(time.RFC3339Nano)
+	}
+
 	return accountResponse{
-		ID:        idStr,
-		Name:      a.Name,
-		Type:      a.Type,
-		Currency:  a.Currency,
-		CreatedAt: created,
-		UpdatedAt: updated,
+		ID:         idStr,
+		Name:       a.Name,
+		Type:       a.Type,
+		Currency:   a.Currency,
+		CreatedAt:  created,
+		UpdatedAt:  updated,
+		ArchivedAt: archived,
 	}
 }
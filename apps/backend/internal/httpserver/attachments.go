@@ -0,0 +1,414 @@
+package httpserver
+
+import (
+	"encoding/hex"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/LBaronceli/go-figure/internal/audit"
+	"github.com/LBaronceli/go-figure/internal/auth"
+	db "github.com/LBaronceli/go-figure/internal/db/sqlc"
+	"github.com/LBaronceli/go-figure/internal/storage"
+)
+
+const (
+	// maxAttachmentBytes stays under maxIdempotentBodySize with room for the
+	// multipart framing, so retried uploads are always hashed whole.
+	maxAttachmentBytes = 8 << 20
+	maxFilenameLength  = 255
+	// attachmentField is the multipart form field carrying the file.
+	attachmentField = "file"
+)
+
+// attachmentTypes are the content types accepted for attachments, as sniffed
+// from the file itself; the type the client declares is ignored.
+var attachmentTypes = []string{"image/jpeg", "image/png", "image/webp", "application/pdf"}
+
+// attachmentUpload is the file part of a multipart upload.
+type attachmentUpload struct {
+	filename    string
+	contentType string
+	data        []byte
+}
+
+// attachmentUploadRequest documents the multipart form; handlers read it
+// with readAttachment.
+type attachmentUploadRequest struct {
+	File []byte `json:"file" openapi:"format=binary" doc:"JPEG, PNG, WebP or PDF of at most 8 MiB"`
+}
+
+type attachmentResponse struct {
+	ID            string `json:"id" openapi:"format=uuid"`
+	TransactionID string `json:"transaction_id,omitempty" openapi:"format=uuid" doc:"Absent while a receipt waits in the inbox"`
+	Filename      string `json:"filename"`
+	ContentType   string `json:"content_type"`
+	SizeBytes     int64  `json:"size_bytes"`
+	SHA256        string `json:"sha256" doc:"Hex SHA-256 of the content"`
+	UploadedBy    string `json:"uploaded_by" openapi:"format=uuid"`
+	CreatedAt     string `json:"created_at" openapi:"format=date-time"`
+}
+
+// POST /transactions/{id}/attachments
+//
+// Stores the file under its SHA-256 within the organisation, so a receipt
+// attached to several transactions is stored once. Uploading a file the
+// transaction already has returns the existing attachment with 200. New
+// attachments are queued for a receipt scan.
+func (s *Server) createAttachment(w http.ResponseWriter, r *http.Request) {
+	if s.storage == nil {
+		writeError(w, http.StatusServiceUnavailable, CodeUnavailable, "attachment storage is not configured")
+		return
+	}
+	transactionID, err := parseUUID(chi.URLParam(r, "id"))
+	if err != nil {
+		writeFieldError(w, http.StatusBadRequest, CodeInvalidFormat, "id", "invalid id")
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxAttachmentBytes+64<<10)
+	var errs validationErrors
+	upload := readAttachment(r, &errs)
+	if !errs.empty() {
+		writeValidationErrors(w, errs)
+		return
+	}
+
+	org := tenantFrom(r.Context())
+	tx, qtx, err := org.begin(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to begin transaction")
+		return
+	}
+	defer tx.Rollback(r.Context())
+
+	if _, err := qtx.GetTransaction(r.Context(), db.GetTransactionParams{OrganisationID: org.id, ID: transactionID}); err != nil {
+		writeError(w, http.StatusNotFound, CodeNotFound, "transaction not found")
+		return
+	}
+	a, created, ok := s.saveAttachment(w, r, qtx, org, transactionID, upload)
+	if !ok {
+		return
+	}
+	if !created {
+		writeJSON(w, http.StatusOK, toAttachmentResponse(a))
+		return
+	}
+	if err := tx.Commit(r.Context()); err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to commit transaction")
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, toAttachmentResponse(a))
+}
+
+// saveAttachment stores upload as an attachment of transactionID, or in the
+// receipt inbox when transactionID is null, and queues it for OCR. A file
+// already there is returned with created false. It writes the error response
+// itself and reports whether to carry on.
+func (s *Server) saveAttachment(w http.ResponseWriter, r *http.Request, q *db.Queries, org *tenant, transactionID pgtype.UUID, upload attachmentUpload) (a db.Attachment, created, ok bool) {
+	p, _ := auth.PrincipalFrom(r.Context())
+	key, sum := storage.ContentKey(uuidString(org.id), upload.data)
+
+	if err := q.LockAttachmentContent(r.Context(), db.LockAttachmentContentParams{OrganisationID: org.id, Sha256: sum[:]}); err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to lock attachment")
+		return db.Attachment{}, false, false
+	}
+	var existing db.Attachment
+	var err error
+	if transactionID.Valid {
+		existing, err = q.GetAttachmentByContent(r.Context(), db.GetAttachmentByContentParams{
+			OrganisationID: org.id,
+			TransactionID:  transactionID,
+			Sha256:         sum[:],
+		})
+	} else {
+		existing, err = q.GetInboxAttachmentByContent(r.Context(), db.GetInboxAttachmentByContentParams{OrganisationID: org.id, Sha256: sum[:]})
+	}
+	if err == nil {
+		return existing, false, true
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to look up attachment")
+		return db.Attachment{}, false, false
+	}
+
+	// The blob goes in first: if the insert then fails, the stray blob is
+	// the same bytes the next upload of this file would write.
+	if err := s.storage.Put(r.Context(), key, upload.data, upload.contentType); err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to store attachment")
+		return db.Attachment{}, false, false
+	}
+	a, err = q.CreateAttachment(r.Context(), db.CreateAttachmentParams{
+		OrganisationID: org.id,
+		TransactionID:  transactionID,
+		Sha256:         sum[:],
+		Filename:       upload.filename,
+		ContentType:    upload.contentType,
+		SizeBytes:      int64(len(upload.data)),
+		UploadedBy:     pgtype.UUID{Bytes: p.UserID, Valid: true},
+	})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to save attachment")
+		return db.Attachment{}, false, false
+	}
+	if err := recordAudit(r.Context(), q, org, audit.EntityAttachment, a.ID, audit.ActionCreate, nil, toAttachmentResponse(a)); err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to record audit event")
+		return db.Attachment{}, false, false
+	}
+	if _, err := q.CreateReceiptScan(r.Context(), db.CreateReceiptScanParams{OrganisationID: org.id, AttachmentID: a.ID}); err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to queue receipt scan")
+		return db.Attachment{}, false, false
+	}
+	return a, true, true
+}
+
+// GET /transactions/{id}/attachments/{attachment_id}
+func (s *Server) getAttachmentContent(w http.ResponseWriter, r *http.Request) {
+	if s.storage == nil {
+		writeError(w, http.StatusServiceUnavailable, CodeUnavailable, "attachment storage is not configured")
+		return
+	}
+	org := tenantFrom(r.Context())
+	a, ok := loadAttachment(w, r, org.q)
+	if !ok {
+		return
+	}
+	s.writeAttachmentContent(w, r, org, a)
+}
+
+// DELETE /transactions/{id}/attachments/{attachment_id}
+//
+// The attachment sweeper removes the blob from storage once the deletion has
+// committed and no attachment in the organisation refers to it.
+func (s *Server) deleteAttachment(w http.ResponseWriter, r *http.Request) {
+	if s.storage == nil {
+		writeError(w, http.StatusServiceUnavailable, CodeUnavailable, "attachment storage is not configured")
+		return
+	}
+	org := tenantFrom(r.Context())
+	tx, qtx, err := org.begin(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to begin transaction")
+		return
+	}
+	defer tx.Rollback(r.Context())
+
+	a, ok := loadAttachment(w, r, qtx)
+	if !ok {
+		return
+	}
+	if err := qtx.LockAttachmentContent(r.Context(), db.LockAttachmentContentParams{OrganisationID: org.id, Sha256: a.Sha256}); err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to lock attachment")
+		return
+	}
+	// a concurrent delete may have won the lock
+	a, err = qtx.DeleteAttachment(r.Context(), db.DeleteAttachmentParams{OrganisationID: org.id, TransactionID: a.TransactionID, ID: a.ID})
+	if errors.Is(err, pgx.ErrNoRows) {
+		writeError(w, http.StatusNotFound, CodeNotFound, "attachment not found")
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to delete attachment")
+		return
+	}
+	if !s.releaseAttachment(w, r, qtx, org, a) {
+		return
+	}
+	if err := tx.Commit(r.Context()); err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to commit transaction")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Helpers
+
+// readAttachment reads the file part of a multipart/form-data upload,
+// sniffing its content type and checking it against the size and type
+// limits.
+func readAttachment(r *http.Request, errs *validationErrors) attachmentUpload {
+	mr, err := r.MultipartReader()
+	if err != nil {
+		errs.add(attachmentField, CodeInvalidFormat, "request must be multipart/form-data with a file field")
+		return attachmentUpload{}
+	}
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			errs.add(attachmentField, CodeRequired, "missing file")
+			return attachmentUpload{}
+		}
+		if err != nil {
+			addUploadError(err, errs)
+			return attachmentUpload{}
+		}
+		if part.FormName() != attachmentField {
+			part.Close()
+			continue
+		}
+
+		data, err := io.ReadAll(io.LimitReader(part, maxAttachmentBytes+1))
+		if err != nil {
+			addUploadError(err, errs)
+			return attachmentUpload{}
+		}
+		switch {
+		case len(data) == 0:
+			errs.add(attachmentField, CodeRequired, "file is empty")
+			return attachmentUpload{}
+		case len(data) > maxAttachmentBytes:
+			errs.add(attachmentField, CodeTooLong, "file larger than 8 MiB")
+			return attachmentUpload{}
+		}
+		upload := attachmentUpload{
+			filename:    sanitizeFilename(part.FileName()),
+			contentType: sniffAttachmentType(data),
+			data:        data,
+		}
+		if upload.contentType == "" {
+			errs.add(attachmentField, CodeInvalidValue, "file must be a JPEG, PNG, WebP or PDF")
+		}
+		return upload
+	}
+}
+
+func addUploadError(err error, errs *validationErrors) {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		errs.add(attachmentField, CodeTooLong, "file larger than 8 MiB")
+		return
+	}
+	errs.add(attachmentField, CodeInvalidFormat, "malformed multipart body")
+}
+
+// sniffAttachmentType returns the content type of data when it is one of
+// attachmentTypes, or "".
+func sniffAttachmentType(data []byte) string {
+	sniffed, _, _ := strings.Cut(http.DetectContentType(data), ";")
+	for _, t := range attachmentTypes {
+		if sniffed == t {
+			return t
+		}
+	}
+	return ""
+}
+
+// sanitizeFilename keeps the base name of a client-supplied filename, drops
+// control characters and caps its length, so it is safe to echo back in
+// Content-Disposition.
+func sanitizeFilename(name string) string {
+	if i := strings.LastIndexAny(name, `/\`); i >= 0 {
+		name = name[i+1:]
+	}
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) || r == utf8.RuneError {
+			return -1
+		}
+		return r
+	}, name)
+	name = strings.TrimSpace(name)
+	for len(name) > maxFilenameLength {
+		_, size := utf8.DecodeLastRuneInString(name)
+		name = name[:len(name)-size]
+	}
+	if name == "" || name == "." || name == ".." {
+		return "attachment"
+	}
+	return name
+}
+
+// releaseAttachment audits the deletion of attachment a, already deleted
+// under its content lock, and queues its blob for the attachment sweeper
+// once no attachment in the organisation refers to it. The blob outlives the
+// commit, so a deletion that rolls back never leaves a row without content.
+// It writes the error response itself and reports whether to carry on.
+func (s *Server) releaseAttachment(w http.ResponseWriter, r *http.Request, q *db.Queries, org *tenant, a db.Attachment) bool {
+	if err := recordAudit(r.Context(), q, org, audit.EntityAttachment, a.ID, audit.ActionDelete, toAttachmentResponse(a), nil); err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to record audit event")
+		return false
+	}
+	remaining, err := q.CountAttachmentsWithContent(r.Context(), db.CountAttachmentsWithContentParams{OrganisationID: org.id, Sha256: a.Sha256})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to count attachments")
+		return false
+	}
+	if remaining == 0 {
+		if err := q.QueueAttachmentContentDeletion(r.Context(), db.QueueAttachmentContentDeletionParams{OrganisationID: org.id, Sha256: a.Sha256}); err != nil {
+			writeError(w, http.StatusInternalServerError, CodeInternal, "failed to queue attachment content deletion")
+			return false
+		}
+	}
+	return true
+}
+
+// writeAttachmentContent sends the attachment's content as a download.
+func (s *Server) writeAttachmentContent(w http.ResponseWriter, r *http.Request, org *tenant, a db.Attachment) {
+	content, err := s.storage.Get(r.Context(), storage.Key(uuidString(org.id), a.Sha256))
+	if errors.Is(err, storage.ErrNotFound) {
+		writeError(w, http.StatusNotFound, CodeNotFound, "attachment content is missing from storage")
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to read attachment")
+		return
+	}
+	defer content.Close()
+
+	w.Header().Set("Content-Type", a.ContentType)
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": a.Filename}))
+	w.Header().Set("Content-Length", strconv.FormatInt(a.SizeBytes, 10))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(http.StatusOK)
+	_, _ = io.Copy(w, content)
+}
+
+// loadAttachment fetches the attachment named by the URL, writing the error
+// response and returning false when it cannot.
+func loadAttachment(w http.ResponseWriter, r *http.Request, q *db.Queries) (db.Attachment, bool) {
+	transactionID, err := parseUUID(chi.URLParam(r, "id"))
+	if err != nil {
+		writeFieldError(w, http.StatusBadRequest, CodeInvalidFormat, "id", "invalid id")
+		return db.Attachment{}, false
+	}
+	id, err := parseUUID(chi.URLParam(r, "attachment_id"))
+	if err != nil {
+		writeFieldError(w, http.StatusBadRequest, CodeInvalidFormat, "attachment_id", "invalid attachment_id")
+		return db.Attachment{}, false
+	}
+	org := tenantFrom(r.Context())
+	a, err := q.GetAttachment(r.Context(), db.GetAttachmentParams{OrganisationID: org.id, TransactionID: transactionID, ID: id})
+	if errors.Is(err, pgx.ErrNoRows) {
+		writeError(w, http.StatusNotFound, CodeNotFound, "attachment not found")
+		return db.Attachment{}, false
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to fetch attachment")
+		return db.Attachment{}, false
+	}
+	return a, true
+}
+
+func toAttachmentResponse(a db.Attachment) attachmentResponse {
+	return attachmentResponse{
+		ID:            uuidString(a.ID),
+		TransactionID: uuidString(a.TransactionID),
+		Filename:      a.Filename,
+		ContentType:   a.ContentType,
+		SizeBytes:     a.SizeBytes,
+		SHA256:        hex.EncodeToString(a.Sha256),
+		UploadedBy:    uuidString(a.UploadedBy),
+		CreatedAt:     a.CreatedAt.Time.Format(time.RFC3339Nano),
+	}
+}
@@ -0,0 +1,80 @@
+package httpserver
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	db "github.com/LBaronceli/go-figure/internal/db/sqlc"
+	"github.com/LBaronceli/go-figure/internal/storage"
+)
+
+// attachmentSweepBatch bounds how many queued blobs one sweep looks at.
+const attachmentSweepBatch = 100
+
+// SweepAttachmentContent deletes the blobs queued by attachment deletions
+// that have committed, and returns how many it deleted. Each is deleted
+// under its content lock, and only if no attachment has come to refer to it
+// since, so an upload of the same file never finds its content gone.
+func (s *Server) SweepAttachmentContent(ctx context.Context) (int, error) {
+	if s.storage == nil {
+		return 0, nil
+	}
+	var queued []db.AttachmentDeletion
+	err := s.withoutRLS(ctx, func(q *db.Queries) error {
+		var err error
+		queued, err = q.ListAttachmentContentDeletions(ctx, attachmentSweepBatch)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	deleted := 0
+	for _, d := range queued {
+		err := s.withoutRLS(ctx, func(q *db.Queries) error {
+			if err := q.LockAttachmentContent(ctx, db.LockAttachmentContentParams{OrganisationID: d.OrganisationID, Sha256: d.Sha256}); err != nil {
+				return err
+			}
+			remaining, err := q.CountAttachmentsWithContent(ctx, db.CountAttachmentsWithContentParams{OrganisationID: d.OrganisationID, Sha256: d.Sha256})
+			if err != nil {
+				return err
+			}
+			if remaining == 0 {
+				if err := s.storage.Delete(ctx, storage.Key(uuidString(d.OrganisationID), d.Sha256)); err != nil {
+					return err
+				}
+				deleted++
+			}
+			return q.DeleteAttachmentContentDeletion(ctx, db.DeleteAttachmentContentDeletionParams{OrganisationID: d.OrganisationID, Sha256: d.Sha256})
+		})
+		if err != nil {
+			// the blob stays queued for the next sweep
+			return deleted, fmt.Errorf("sweep attachment content %s: %w", storage.Key(uuidString(d.OrganisationID), d.Sha256), err)
+		}
+	}
+	return deleted, nil
+}
+
+// RunAttachmentSweeper deletes queued attachment content every interval
+// until ctx is done.
+func (s *Server) RunAttachmentSweeper(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			n, err := s.SweepAttachmentContent(ctx)
+			if err != nil {
+				slog.Error("attachment sweeper", "err", err)
+			}
+			if n > 0 {
+				slog.Info("attachment sweeper deleted content", "count", n)
+			}
+		}
+	}
+}
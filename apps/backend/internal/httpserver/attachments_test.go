@@ -0,0 +1,95 @@
+package httpserver
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// pdfBytes is enough of a PDF for content sniffing.
+var pdfBytes = []byte("%PDF-1.7\n1 0 obj\n<<>>\nendobj\n")
+
+func uploadRequest(t *testing.T, field, filename string, data []byte) *http.Request {
+	t.Helper()
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	require.NoError(t, mw.WriteField("note", "ignored"))
+	part, err := mw.CreateFormFile(field, filename)
+	require.NoError(t, err)
+	_, err = part.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, mw.Close())
+
+	r := httptest.NewRequest(http.MethodPost, "/transactions/x/attachments", &body)
+	r.Header.Set("Content-Type", mw.FormDataContentType())
+	return r
+}
+
+func TestReadAttachment(t *testing.T) {
+	var errs validationErrors
+	upload := readAttachment(uploadRequest(t, "file", "receipt.pdf", pdfBytes), &errs)
+	require.Empty(t, errs)
+	require.Equal(t, "receipt.pdf", upload.filename)
+	require.Equal(t, "application/pdf", upload.contentType)
+	require.Equal(t, pdfBytes, upload.data)
+}
+
+func TestReadAttachmentRejects(t *testing.T) {
+	tests := []struct {
+		name string
+		req  func(t *testing.T) *http.Request
+		code ErrorCode
+	}{
+		{"not multipart", func(t *testing.T) *http.Request {
+			r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{}`))
+			r.Header.Set("Content-Type", "application/json")
+			return r
+		}, CodeInvalidFormat},
+		{"no file field", func(t *testing.T) *http.Request {
+			return uploadRequest(t, "document", "receipt.pdf", pdfBytes)
+		}, CodeRequired},
+		{"empty file", func(t *testing.T) *http.Request {
+			return uploadRequest(t, "file", "receipt.pdf", nil)
+		}, CodeRequired},
+		{"unsupported type", func(t *testing.T) *http.Request {
+			// declaring a PDF does not make HTML one
+			return uploadRequest(t, "file", "receipt.pdf", []byte("<html><script>alert(1)</script></html>"))
+		}, CodeInvalidValue},
+		{"too large", func(t *testing.T) *http.Request {
+			return uploadRequest(t, "file", "scan.pdf", append(bytes.Repeat(pdfBytes, maxAttachmentBytes/len(pdfBytes)), pdfBytes...))
+		}, CodeTooLong},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var errs validationErrors
+			readAttachment(tt.req(t), &errs)
+			require.Len(t, errs, 1)
+			require.Equal(t, "file", errs[0].Field)
+			require.Equal(t, tt.code, errs[0].Code)
+		})
+	}
+}
+
+func TestSanitizeFilename(t *testing.T) {
+	require.Equal(t, "receipt.pdf", sanitizeFilename(`C:\Users\me\receipt.pdf`))
+	require.Equal(t, "passwd", sanitizeFilename("../../etc/passwd"))
+	require.Equal(t, "evil.pdf", sanitizeFilename("evil\r\n.pdf"))
+	require.Equal(t, "attachment", sanitizeFilename(".."))
+	require.Equal(t, "attachment", sanitizeFilename("   "))
+
+	long := sanitizeFilename(strings.Repeat("é", 200))
+	require.LessOrEqual(t, len(long), maxFilenameLength)
+	require.Equal(t, strings.Repeat("é", 127), long)
+}
+
+func TestSniffAttachmentType(t *testing.T) {
+	require.Equal(t, "image/png", sniffAttachmentType([]byte("\x89PNG\r\n\x1a\n0000")))
+	require.Equal(t, "image/jpeg", sniffAttachmentType([]byte("\xff\xd8\xff\xe0")))
+	require.Equal(t, "image/webp", sniffAttachmentType([]byte("RIFF\x00\x00\x00\x00WEBPVP8 ")))
+	require.Empty(t, sniffAttachmentType([]byte("GIF89a")))
+}
@@ -0,0 +1,302 @@
+package httpserver
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/LBaronceli/go-figure/internal/audit"
+	"github.com/LBaronceli/go-figure/internal/auth"
+	db "github.com/LBaronceli/go-figure/internal/db/sqlc"
+)
+
+type auditEventResponse struct {
+	Seq         int64           `json:"seq" doc:"Position in the organisation's hash chain, from 1"`
+	ID          string          `json:"id" openapi:"format=uuid"`
+	ActorUserID string          `json:"actor_user_id" openapi:"format=uuid"`
+	ActorEmail  string          `json:"actor_email"`
+	ActorMethod string          `json:"actor_method" openapi:"enum=api_token|session"`
+	RequestID   string          `json:"request_id"`
+	Entity      string          `json:"entity" openapi:"enum=account|transaction|period_lock|contact|invoice|bill|attachment"`
+	EntityID    string          `json:"entity_id" openapi:"format=uuid"`
+	Action      string          `json:"action" openapi:"enum=create|update|delete|archive|unarchive|lock|unlock|merge|approve|pay|void"`
+	Before      json.RawMessage `json:"before,omitempty" doc:"State before the change; absent for creations"`
+	After       json.RawMessage `json:"after,omitempty" doc:"State after the change; absent for deletions"`
+	CreatedAt   string          `json:"created_at" openapi:"format=date-time"`
+	Hash        string          `json:"hash" doc:"Hex SHA-256 chaining this event to the previous one"`
+}
+
+type auditVerificationResponse struct {
+	OK          bool  `json:"ok"`
+	Checked     int   `json:"checked" doc:"Events verified before the first break"`
+	BrokenAtSeq int64 `json:"broken_at_seq,omitempty" doc:"First event whose hash does not match; absent while the chain is intact"`
+}
+
+var auditFilters = []apiParam{
+	{name: "entity", typ: "string", desc: "Only events for this kind of entity", enum: audit.Entities},
+	{name: "entity_id", typ: "string", format: "uuid", desc: "Only events for this entity"},
+}
+
+// recordAudit appends an event to the organisation's audit chain. It must run
+// in the same database transaction as the change it describes, so the change
+// and its record commit or roll back together. before and after are response
+// values; nil means "no state".
+func recordAudit(ctx context.Context, q *db.Queries, org *tenant, entity string, entityID pgtype.UUID, action string, before, after any) error {
+	chain, err := openAuditChain(ctx, q, org)
+	if err != nil {
+		return err
+	}
+	e, err := chain.next(ctx, entity, entityID, action, before, after)
+	if err != nil {
+		return err
+	}
+
+	return q.CreateAuditEvent(ctx, db.CreateAuditEventParams{
+		OrganisationID: org.id,
+		Seq:            e.Seq,
+		ActorUserID:    pgtype.UUID{Bytes: e.ActorUserID, Valid: true},
+		ActorEmail:     e.ActorEmail,
+		ActorMethod:    e.ActorMethod,
+		RequestID:      e.RequestID,
+		Entity:         e.Entity,
+		EntityID:       entityID,
+		Action:         e.Action,
+		Before:         e.Before,
+		After:          e.After,
+		CreatedAt:      pgtype.Timestamptz{Time: e.CreatedAt, Valid: true},
+		PrevHash:       e.PrevHash,
+		Hash:           e.Hash,
+	})
+}
+
+// auditChange is one entity's before and after state for recordAudits.
+type auditChange struct {
+	entityID      pgtype.UUID
+	before, after any
+}
+
+// recordAudits is recordAudit for many entities changed the same way by one
+// request, written in a single statement.
+func recordAudits(ctx context.Context, q *db.Queries, org *tenant, entity, action string, changes []auditChange) error {
+	if len(changes) == 0 {
+		return nil
+	}
+	chain, err := openAuditChain(ctx, q, org)
+	if err != nil {
+		return err
+	}
+
+	p, _ := auth.PrincipalFrom(ctx)
+	arg := db.CreateAuditEventsParams{
+		OrganisationID: org.id,
+		ActorUserID:    pgtype.UUID{Bytes: p.UserID, Valid: true},
+		ActorEmail:     p.Email,
+		ActorMethod:    string(p.Method),
+		RequestID:      middleware.GetReqID(ctx),
+		Entity:         entity,
+		Action:         action,
+		CreatedAt:      pgtype.Timestamptz{Time: chain.at, Valid: true},
+		Seqs:           make([]int64, 0, len(changes)),
+		EntityIds:      make([]pgtype.UUID, 0, len(changes)),
+		Befores:        make([]string, 0, len(changes)),
+		Afters:         make([]string, 0, len(changes)),
+		PrevHashes:     make([][]byte, 0, len(changes)),
+		Hashes:         make([][]byte, 0, len(changes)),
+	}
+	for _, c := range changes {
+		e, err := chain.next(ctx, entity, c.entityID, action, c.before, c.after)
+		if err != nil {
+			return err
+		}
+		arg.Seqs = append(arg.Seqs, e.Seq)
+		arg.EntityIds = append(arg.EntityIds, c.entityID)
+		arg.Befores = append(arg.Befores, string(e.Before))
+		arg.Afters = append(arg.Afters, string(e.After))
+		arg.PrevHashes = append(arg.PrevHashes, e.PrevHash)
+		arg.Hashes = append(arg.Hashes, e.Hash)
+	}
+	return q.CreateAuditEvents(ctx, arg)
+}
+
+// auditChain builds events that follow the organisation's current chain head.
+type auditChain struct {
+	org  *tenant
+	prev []byte
+	seq  int64
+	// at is shared by every event built from this chain.
+	at time.Time
+}
+
+// openAuditChain locks the organisation's chain and reads its head. Appends
+// to one chain are serialised until the caller commits.
+func openAuditChain(ctx context.Context, q *db.Queries, org *tenant) (*auditChain, error) {
+	if err := q.LockAuditChain(ctx, org.id); err != nil {
+		return nil, err
+	}
+
+	c := &auditChain{org: org, at: time.Now().UTC().Truncate(time.Microsecond)}
+	head, err := q.GetAuditChainHead(ctx, org.id)
+	switch {
+	case err == nil:
+		c.prev = head.Hash
+		c.seq = head.Seq
+	case !errors.Is(err, pgx.ErrNoRows):
+		return nil, err
+	}
+	return c, nil
+}
+
+// next builds and hashes the event after the last one built.
+func (c *auditChain) next(ctx context.Context, entity string, entityID pgtype.UUID, action string, before, after any) (audit.Event, error) {
+	p, _ := auth.PrincipalFrom(ctx)
+	e := audit.Event{
+		OrganisationID: uuid.UUID(c.org.id.Bytes),
+		Seq:            c.seq + 1,
+		ActorUserID:    p.UserID,
+		ActorEmail:     p.Email,
+		ActorMethod:    string(p.Method),
+		RequestID:      middleware.GetReqID(ctx),
+		Entity:         entity,
+		EntityID:       uuid.UUID(entityID.Bytes),
+		Action:         action,
+		CreatedAt:      c.at,
+		PrevHash:       c.prev,
+	}
+	var err error
+	if e.Before, err = audit.Snapshot(before); err != nil {
+		return audit.Event{}, err
+	}
+	if e.After, err = audit.Snapshot(after); err != nil {
+		return audit.Event{}, err
+	}
+	if e.Hash, err = audit.ComputeHash(c.prev, e); err != nil {
+		return audit.Event{}, err
+	}
+
+	c.prev, c.seq = e.Hash, e.Seq
+	return e, nil
+}
+
+// GET /audit
+func (s *Server) listAuditEvents(w http.ResponseWriter, r *http.Request) {
+	limit := 50
+	offset := 0
+
+	var errs validationErrors
+
+	var entity pgtype.Text
+	if v := r.URL.Query().Get("entity"); v != "" {
+		if !slices.Contains(audit.Entities, v) {
+			errs.add("entity", CodeInvalidValue, "entity must be one of "+strings.Join(audit.Entities, ", "))
+		}
+		entity = pgtype.Text{String: v, Valid: true}
+	}
+
+	var entityID pgtype.UUID
+	if v := r.URL.Query().Get("entity_id"); v != "" {
+		id, err := parseUUID(v)
+		if err != nil {
+			errs.add("entity_id", CodeInvalidFormat, "invalid entity_id")
+		}
+		entityID = id
+	}
+
+	if !errs.empty() {
+		writeValidationErrors(w, errs)
+		return
+	}
+
+	org := tenantFrom(r.Context())
+	events, err := org.q.ListAuditEvents(r.Context(), db.ListAuditEventsParams{
+		OrganisationID: org.id,
+		Limit:          int32(limit),
+		Offset:         int32(offset),
+		Entity:         entity,
+		EntityID:       entityID,
+	})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to list audit events")
+		return
+	}
+
+	resp := make([]auditEventResponse, 0, len(events))
+	for _, e := range events {
+		resp = append(resp, toAuditEventResponse(e))
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// GET /audit/verify
+func (s *Server) verifyAuditChain(w http.ResponseWriter, r *http.Request) {
+	org := tenantFrom(r.Context())
+	rows, err := org.q.ListAuditChain(r.Context(), org.id)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to load audit events")
+		return
+	}
+
+	events := make([]audit.Event, 0, len(rows))
+	for _, e := range rows {
+		events = append(events, toAuditEvent(e))
+	}
+
+	v, err := audit.Verify(events)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to verify audit events")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, auditVerificationResponse{
+		OK:          v.OK(),
+		Checked:     v.Checked,
+		BrokenAtSeq: v.BrokenAt,
+	})
+}
+
+func toAuditEvent(e db.AuditEvent) audit.Event {
+	return audit.Event{
+		OrganisationID: uuid.UUID(e.OrganisationID.Bytes),
+		Seq:            e.Seq,
+		ActorUserID:    uuid.UUID(e.ActorUserID.Bytes),
+		ActorEmail:     e.ActorEmail,
+		ActorMethod:    e.ActorMethod,
+		RequestID:      e.RequestID,
+		Entity:         e.Entity,
+		EntityID:       uuid.UUID(e.EntityID.Bytes),
+		Action:         e.Action,
+		Before:         e.Before,
+		After:          e.After,
+		CreatedAt:      e.CreatedAt.Time,
+		PrevHash:       e.PrevHash,
+		Hash:           e.Hash,
+	}
+}
+
+func toAuditEventResponse(e db.AuditEvent) auditEventResponse {
+	return auditEventResponse{
+		Seq:         e.Seq,
+		ID:          uuid.UUID(e.ID.Bytes).String(),
+		ActorUserID: uuid.UUID(e.ActorUserID.Bytes).String(),
+		ActorEmail:  e.ActorEmail,
+		ActorMethod: e.ActorMethod,
+		RequestID:   e.RequestID,
+		Entity:      e.Entity,
+		EntityID:    uuid.UUID(e.EntityID.Bytes).String(),
+		Action:      e.Action,
+		Before:      e.Before,
+		After:       e.After,
+		CreatedAt:   e.CreatedAt.Time.Format(time.RFC3339Nano),
+		Hash:        hex.EncodeToString(e.Hash),
+	}
+}
@@ -0,0 +1,332 @@
+package httpserver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/LBaronceli/go-figure/internal/auth"
+	db "github.com/LBaronceli/go-figure/internal/db/sqlc"
+)
+
+const (
+	sessionCookieName = "gofigure_session"
+	defaultSessionTTL = 14 * 24 * time.Hour
+	// maxLoginBodySize is far more than an email and the longest password
+	// users may set.
+	maxLoginBodySize = 4 << 10
+)
+
+type loginRequest struct {
+	Email    string `json:"email" openapi:"minLength=1,maxLength=320"`
+	Password string `json:"password" openapi:"minLength=1,maxLength=1024"`
+}
+
+type sessionResponse struct {
+	User      userResponse `json:"user"`
+	ExpiresAt string       `json:"expires_at" openapi:"format=date-time"`
+}
+
+type principalResponse struct {
+	UserID string   `json:"user_id" openapi:"format=uuid"`
+	Email  string   `json:"email"`
+	Method string   `json:"method" openapi:"enum=api_token|session"`
+	Scopes []string `json:"scopes" openapi:"enum=read|write|admin"`
+}
+
+// authenticate resolves the caller from an API token in the Authorization
+// header or, failing that, the session cookie, and stores the principal in
+// the request context. Requests with neither get 401.
+func (s *Server) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		var (
+			p   auth.Principal
+			err error
+		)
+		if header := r.Header.Get("Authorization"); header != "" {
+			token, ok := strings.CutPrefix(header, "Bearer ")
+			if !ok || !auth.IsAPIToken(token) {
+				writeError(w, http.StatusUnauthorized, CodeUnauthenticated, "authorization header must be: Bearer <api token>")
+				return
+			}
+			p, err = s.apiTokenPrincipal(ctx, token)
+		} else if c, cerr := r.Cookie(sessionCookieName); cerr == nil && c.Value != "" {
+			p, err = s.sessionPrincipal(ctx, c.Value)
+		} else {
+			writeError(w, http.StatusUnauthorized, CodeUnauthenticated, "authentication required")
+			return
+		}
+
+		if errors.Is(err, pgx.ErrNoRows) {
+			writeError(w, http.StatusUnauthorized, CodeUnauthenticated, "invalid or expired credentials")
+			return
+		}
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, CodeInternal, "failed to authenticate")
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(auth.WithPrincipal(ctx, p)))
+	})
+}
+
+func (s *Server) apiTokenPrincipal(ctx context.Context, token string) (auth.Principal, error) {
+	row, err := s.q.GetAPITokenPrincipal(ctx, auth.HashToken(token))
+	if err != nil {
+		return auth.Principal{}, err
+	}
+	if err := s.q.TouchAPIToken(ctx, row.ID); err != nil {
+		slog.Warn("auth: touch api token", "err", err)
+	}
+
+	scopes := make([]auth.Scope, 0, len(row.Scopes))
+	for _, sc := range row.Scopes {
+		scopes = append(scopes, auth.Scope(sc))
+	}
+	return auth.Principal{
+		UserID:       uuid.UUID(row.UserID.Bytes),
+		Email:        row.Email,
+		Method:       auth.MethodAPIToken,
+		CredentialID: uuid.UUID(row.ID.Bytes),
+		Scopes:       scopes,
+	}, nil
+}
+
+// sessionPrincipal grants a logged-in user read and write, plus admin for
+// admin users. Sessions are for people using the web UI; narrower access is
+// what API tokens are for.
+func (s *Server) sessionPrincipal(ctx context.Context, token string) (auth.Principal, error) {
+	row, err := s.q.GetSessionPrincipal(ctx, auth.HashToken(token))
+	if err != nil {
+		return auth.Principal{}, err
+	}
+
+	scopes := []auth.Scope{auth.ScopeRead, auth.ScopeWrite}
+	if row.IsAdmin {
+		scopes = append(scopes, auth.ScopeAdmin)
+	}
+	return auth.Principal{
+		UserID:       uuid.UUID(row.UserID.Bytes),
+		Email:        row.Email,
+		Method:       auth.MethodSession,
+		CredentialID: uuid.UUID(row.ID.Bytes),
+		Scopes:       scopes,
+	}, nil
+}
+
+// requireScope rejects principals that do not hold scope.
+func requireScope(scope auth.Scope) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			p, ok := auth.PrincipalFrom(r.Context())
+			if !ok {
+				writeError(w, http.StatusUnauthorized, CodeUnauthenticated, "authentication required")
+				return
+			}
+			if !p.Has(scope) {
+				writeError(w, http.StatusForbidden, CodeInsufficientScope, "this request requires the "+string(scope)+" scope")
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// requireMethodScope requires read for safe methods and write for everything
+// else. Routes that need more add requireScope on top.
+func requireMethodScope(next http.Handler) http.Handler {
+	read := requireScope(auth.ScopeRead)(next)
+	write := requireScope(auth.ScopeWrite)(next)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if isMutating(r.Method) {
+			write.ServeHTTP(w, r)
+			return
+		}
+		read.ServeHTTP(w, r)
+	})
+}
+
+// methodScope is the scope requireMethodScope demands for method.
+func methodScope(method string) auth.Scope {
+	if isMutating(method) {
+		return auth.ScopeWrite
+	}
+	return auth.ScopeRead
+}
+
+// POST /auth/login
+func (s *Server) login(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxLoginBodySize)
+	var req loginRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			writeError(w, http.StatusRequestEntityTooLarge, CodeBodyTooLarge, "request body larger than 4 KiB")
+			return
+		}
+		writeError(w, http.StatusBadRequest, CodeInvalidJSON, "invalid json")
+		return
+	}
+
+	email := normalizeEmail(req.Email)
+	var errs validationErrors
+	if email == "" {
+		errs.add("email", CodeRequired, "email is required")
+	}
+	if req.Password == "" {
+		errs.add("password", CodeRequired, "password is required")
+	}
+	if !errs.empty() {
+		writeValidationErrors(w, errs)
+		return
+	}
+
+	ctx := r.Context()
+	user, err := s.q.GetUserByEmail(ctx, email)
+	if errors.Is(err, pgx.ErrNoRows) {
+		if err := auth.BurnPasswordCheck(req.Password); errors.Is(err, auth.ErrBusy) {
+			writeHashingBusy(w)
+			return
+		}
+		writeError(w, http.StatusUnauthorized, CodeInvalidCredentials, "invalid email or password")
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to look up user")
+		return
+	}
+
+	ok, err := auth.VerifyPassword(req.Password, user.PasswordHash)
+	if errors.Is(err, auth.ErrBusy) {
+		writeHashingBusy(w)
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to verify password")
+		return
+	}
+	if !ok {
+		writeError(w, http.StatusUnauthorized, CodeInvalidCredentials, "invalid email or password")
+		return
+	}
+
+	token, hash, err := auth.NewToken(auth.SessionPrefix)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to create session")
+		return
+	}
+	expires := time.Now().Add(s.sessionTTL)
+	if _, err := s.q.CreateSession(ctx, db.CreateSessionParams{
+		TokenHash: hash,
+		UserID:    user.ID,
+		ExpiresAt: pgtype.Timestamptz{Time: expires, Valid: true},
+	}); err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to create session")
+		return
+	}
+
+	http.SetCookie(w, s.sessionCookie(token, expires))
+	writeJSON(w, http.StatusOK, sessionResponse{
+		User:      toUserResponse(user),
+		ExpiresAt: expires.Format(time.RFC3339),
+	})
+}
+
+// POST /auth/logout
+func (s *Server) logout(w http.ResponseWriter, r *http.Request) {
+	p, _ := auth.PrincipalFrom(r.Context())
+	if p.Method == auth.MethodSession {
+		if err := s.q.DeleteSession(r.Context(), pgtype.UUID{Bytes: p.CredentialID, Valid: true}); err != nil {
+			writeError(w, http.StatusInternalServerError, CodeInternal, "failed to end session")
+			return
+		}
+	}
+
+	c := s.sessionCookie("", time.Unix(0, 0))
+	c.MaxAge = -1
+	http.SetCookie(w, c)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GET /auth/me
+func (s *Server) me(w http.ResponseWriter, r *http.Request) {
+	p, _ := auth.PrincipalFrom(r.Context())
+	writeJSON(w, http.StatusOK, toPrincipalResponse(p))
+}
+
+func (s *Server) sessionCookie(value string, expires time.Time) *http.Cookie {
+	return &http.Cookie{
+		Name:     sessionCookieName,
+		Value:    value,
+		Path:     "/",
+		Expires:  expires,
+		HttpOnly: true,
+		Secure:   !s.insecureCookies,
+		// Strict keeps the cookie off cross-site requests, which is the
+		// CSRF defence for cookie-authenticated writes.
+		SameSite: http.SameSiteStrictMode,
+	}
+}
+
+// DeleteExpiredSessions removes sessions past their expiry. Expired sessions
+// are already rejected at lookup; this only keeps the table small.
+func (s *Server) DeleteExpiredSessions(ctx context.Context) (int64, error) {
+	return s.q.DeleteExpiredSessions(ctx)
+}
+
+// RunSessionJanitor deletes expired sessions every interval until ctx is
+// done.
+func (s *Server) RunSessionJanitor(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			n, err := s.DeleteExpiredSessions(ctx)
+			if err != nil {
+				slog.Error("session janitor", "err", err)
+				continue
+			}
+			if n > 0 {
+				slog.Info("session janitor deleted sessions", "count", n)
+			}
+		}
+	}
+}
+
+// writeHashingBusy answers a request turned away because every password
+// hashing slot is taken.
+func writeHashingBusy(w http.ResponseWriter) {
+	w.Header().Set("Retry-After", "1")
+	writeError(w, http.StatusServiceUnavailable, CodeUnavailable, "too many password checks in progress; retry shortly")
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+func toPrincipalResponse(p auth.Principal) principalResponse {
+	scopes := make([]string, 0, len(p.Scopes))
+	for _, sc := range p.Scopes {
+		scopes = append(scopes, string(sc))
+	}
+	return principalResponse{
+		UserID: p.UserID.String(),
+		Email:  p.Email,
+		Method: string(p.Method),
+		Scopes: scopes,
+	}
+}
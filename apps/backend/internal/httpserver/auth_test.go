@@ -0,0 +1,91 @@
+package httpserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/LBaronceli/go-figure/internal/auth"
+)
+
+func errorCode(t *testing.T, rec *httptest.ResponseRecorder) ErrorCode {
+	t.Helper()
+	var body errorResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	return body.Error.Code
+}
+
+func TestProtectedRoutesRequireCredentials(t *testing.T) {
+	h := NewServer(nil).Routes()
+
+	for _, path := range []string{"/accounts", "/transactions", "/auth/me", "/users"} {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		require.Equal(t, http.StatusUnauthorized, rec.Code, path)
+		require.Equal(t, CodeUnauthenticated, errorCode(t, rec), path)
+	}
+}
+
+func TestHealthRoutesArePublic(t *testing.T) {
+	h := NewServer(nil).Routes()
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestLoginRejectsOversizedBody(t *testing.T) {
+	h := NewServer(nil).Routes()
+
+	body := `{"email":"a@example.com","password":"` + strings.Repeat("x", maxLoginBodySize) + `"}`
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/auth/login", strings.NewReader(body)))
+	require.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+	require.Equal(t, CodeBodyTooLarge, errorCode(t, rec))
+}
+
+func TestAuthenticateRejectsNonTokenBearer(t *testing.T) {
+	h := NewServer(nil).Routes()
+
+	req := httptest.NewRequest(http.MethodGet, "/accounts", nil)
+	req.Header.Set("Authorization", "Bearer "+auth.SessionPrefix+"abc")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusUnauthorized, rec.Code)
+}
+
+func TestRequireMethodScope(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	h := requireMethodScope(ok)
+	readOnly := auth.Principal{Scopes: []auth.Scope{auth.ScopeRead}}
+	admin := auth.Principal{Scopes: []auth.Scope{auth.ScopeAdmin}}
+
+	cases := []struct {
+		name   string
+		method string
+		p      auth.Principal
+		want   int
+	}{
+		{"read can get", http.MethodGet, readOnly, http.StatusNoContent},
+		{"read cannot post", http.MethodPost, readOnly, http.StatusForbidden},
+		{"admin implies write", http.MethodDelete, admin, http.StatusNoContent},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, "/accounts", nil)
+			req = req.WithContext(auth.WithPrincipal(req.Context(), tc.p))
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+			require.Equal(t, tc.want, rec.Code)
+			if tc.want == http.StatusForbidden {
+				require.Equal(t, CodeInsufficientScope, errorCode(t, rec))
+			}
+		})
+	}
+}
@@ -0,0 +1,895 @@
+package httpserver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/LBaronceli/go-figure/internal/audit"
+	db "github.com/LBaronceli/go-figure/internal/db/sqlc"
+	"github.com/LBaronceli/go-figure/internal/invoicing"
+	"github.com/LBaronceli/go-figure/internal/models"
+)
+
+// sourceBill marks transactions posted by approving, paying or voiding a
+// bill. They carry the bill's ID in bill_id.
+const sourceBill = "bill"
+
+// Bill states.
+const (
+	billDraft    = "draft"
+	billApproved = "approved"
+	billPaid     = "paid"
+	billVoid     = "void"
+)
+
+var billFilters = []apiParam{
+	{name: "status", typ: "string", enum: []string{billDraft, billApproved, billPaid, billVoid}, desc: "Only bills in this state"},
+	{name: "contact_id", typ: "string", format: "uuid", desc: "Only bills from this supplier"},
+}
+
+type billLineRequest struct {
+	Description string   `json:"description" openapi:"optional,maxLength=500"`
+	Quantity    *float64 `json:"quantity,omitempty" doc:"Up to 3 decimal places; defaults to 1"`
+	UnitPrice   int64    `json:"unit_price" doc:"Minor units before tax; negative for a discount"`
+	AccountID   string   `json:"account_id" openapi:"format=uuid" doc:"Expense or asset account the line is debited to"`
+	TaxCodeID   string   `json:"tax_code_id" openapi:"optional,format=uuid" doc:"The tax is debited to the tax code's account"`
+}
+
+type billRequest struct {
+	ContactID        string            `json:"contact_id" openapi:"format=uuid" doc:"Supplier the bill is from"`
+	Number           string            `json:"number" openapi:"minLength=1,maxLength=100" doc:"The supplier's invoice number; unique per supplier"`
+	Reference        string            `json:"reference" openapi:"optional,maxLength=500" doc:"Our reference, such as a purchase order number"`
+	IssueOn          string            `json:"issue_on" openapi:"optional,format=date" doc:"Defaults to today in the organisation's timezone; approval posts on this date"`
+	DueOn            string            `json:"due_on" openapi:"optional,format=date" doc:"Defaults to 30 days after issue_on"`
+	PayableAccountID string            `json:"payable_account_id" openapi:"format=uuid" doc:"Liability account the amount is owed on; sets the bill currency"`
+	Lines            []billLineRequest `json:"lines" openapi:"minItems=1,maxItems=100"`
+}
+
+type billPaymentRequest struct {
+	IdempotencyKey string `json:"idempotency_key" openapi:"minLength=1,maxLength=500" doc:"As for POST /transactions; a retry returns the payment already posted"`
+	AccountID      string `json:"account_id" openapi:"format=uuid" doc:"Bank, credit card or other asset or liability account the money left"`
+	Amount         int64  `json:"amount" doc:"Minor units; at most the amount outstanding"`
+	PaidOn         string `json:"paid_on" openapi:"optional,format=date" doc:"Defaults to today in the organisation's timezone"`
+	Description    string `json:"description" openapi:"optional,maxLength=500" doc:"Defaults to Payment for bill and the bill number"`
+}
+
+type voidBillRequest struct {
+	VoidedOn string `json:"voided_on" openapi:"optional,format=date" doc:"Date the reversal posts on; defaults to today in the organisation's timezone"`
+}
+
+type billResponse struct {
+	ID                string                   `json:"id" openapi:"format=uuid"`
+	ContactID         string                   `json:"contact_id" openapi:"format=uuid"`
+	Number            string                   `json:"number"`
+	Reference         string                   `json:"reference"`
+	Currency          string                   `json:"currency"`
+	IssueOn           string                   `json:"issue_on" openapi:"format=date"`
+	DueOn             string                   `json:"due_on" openapi:"format=date"`
+	Status            string                   `json:"status" openapi:"enum=draft|approved|paid|void"`
+	PayableAccountID  string                   `json:"payable_account_id" openapi:"format=uuid"`
+	Subtotal          int64                    `json:"subtotal"`
+	Tax               int64                    `json:"tax"`
+	Total             int64                    `json:"total"`
+	Paid              int64                    `json:"paid"`
+	Outstanding       int64                    `json:"outstanding" doc:"Total less payments; 0 for drafts and void bills"`
+	TransactionID     string                   `json:"transaction_id,omitempty" openapi:"format=uuid" doc:"Transaction posted on approval"`
+	VoidTransactionID string                   `json:"void_transaction_id,omitempty" openapi:"format=uuid" doc:"Transaction reversing the approval, for voided approved bills"`
+	ApprovedAt        string                   `json:"approved_at,omitempty" openapi:"format=date-time"`
+	PaidOn            string                   `json:"paid_on,omitempty" openapi:"format=date"`
+	VoidedOn          string                   `json:"voided_on,omitempty" openapi:"format=date"`
+	Lines             []invoiceLineResponse    `json:"lines,omitempty" doc:"Absent from listings"`
+	Payments          []invoicePaymentResponse `json:"payments,omitempty" doc:"Absent from listings"`
+	CreatedAt         string                   `json:"created_at" openapi:"format=date-time"`
+	UpdatedAt         string                   `json:"updated_at" openapi:"format=date-time"`
+}
+
+type billPostingResponse struct {
+	Bill        billResponse         `json:"bill"`
+	Transaction *transactionResponse `json:"transaction,omitempty" doc:"Transaction posted by the change; absent when voiding a draft"`
+}
+
+// billDraftParams is a validated bill ready to save.
+type billDraftParams struct {
+	bill  db.CreateBillParams
+	lines db.CreateBillLinesParams
+}
+
+// POST /bills
+func (s *Server) createBill(w http.ResponseWriter, r *http.Request) {
+	var req billRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, CodeInvalidJSON, "invalid json")
+		return
+	}
+
+	org := tenantFrom(r.Context())
+	tx, qtx, err := org.begin(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to begin transaction")
+		return
+	}
+	defer tx.Rollback(r.Context())
+
+	p, ok := resolveBillRequest(w, r, qtx, org, &req)
+	if !ok {
+		return
+	}
+
+	bill, err := qtx.CreateBill(r.Context(), p.bill)
+	if err != nil {
+		if isUniqueViolation(err) {
+			writeFieldError(w, http.StatusConflict, CodeBillNumberTaken, "number", "the supplier already has a bill with this number")
+			return
+		}
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to create bill")
+		return
+	}
+	p.lines.BillID = bill.ID
+	lines, err := qtx.CreateBillLines(r.Context(), p.lines)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to create bill lines")
+		return
+	}
+
+	resp := toBillResponse(bill, lines, nil)
+	if err := recordAudit(r.Context(), qtx, org, audit.EntityBill, bill.ID, audit.ActionCreate, nil, resp); err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to record audit event")
+		return
+	}
+	if err := tx.Commit(r.Context()); err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to commit transaction")
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, resp)
+}
+
+// GET /bills
+func (s *Server) listBills(w http.ResponseWriter, r *http.Request) {
+	var errs validationErrors
+	var status pgtype.Text
+	switch v := r.URL.Query().Get("status"); v {
+	case "":
+	case billDraft, billApproved, billPaid, billVoid:
+		status = pgtype.Text{String: v, Valid: true}
+	default:
+		errs.add("status", CodeInvalidValue, "invalid status (must be draft, approved, paid, or void)")
+	}
+	var contactID pgtype.UUID
+	if v := r.URL.Query().Get("contact_id"); v != "" {
+		id, err := parseUUID(v)
+		if err != nil {
+			errs.add("contact_id", CodeInvalidFormat, "invalid contact_id")
+		}
+		contactID = id
+	}
+	if !errs.empty() {
+		writeValidationErrors(w, errs)
+		return
+	}
+
+	org := tenantFrom(r.Context())
+	bills, err := org.q.ListBills(r.Context(), db.ListBillsParams{
+		OrganisationID: org.id,
+		Limit:          50,
+		Offset:         0,
+		Status:         status,
+		ContactID:      contactID,
+	})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to list bills")
+		return
+	}
+
+	resp := make([]billResponse, 0, len(bills))
+	for _, b := range bills {
+		resp = append(resp, toBillResponse(b, nil, nil))
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// GET /bills/{id}
+func (s *Server) getBill(w http.ResponseWriter, r *http.Request) {
+	org := tenantFrom(r.Context())
+	bill, ok := loadBill(w, r, org.q, false)
+	if !ok {
+		return
+	}
+	resp, err := billWithDetail(r.Context(), org.q, org, bill)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to load bill lines and payments")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// PUT /bills/{id}
+//
+// Replaces a draft bill, lines included. Approved bills are fixed; void them
+// and enter the bill again instead.
+func (s *Server) updateBill(w http.ResponseWriter, r *http.Request) {
+	var req billRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, CodeInvalidJSON, "invalid json")
+		return
+	}
+
+	org := tenantFrom(r.Context())
+	tx, qtx, err := org.begin(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to begin transaction")
+		return
+	}
+	defer tx.Rollback(r.Context())
+
+	current, ok := loadBill(w, r, qtx, true)
+	if !ok {
+		return
+	}
+	if current.Status != billDraft {
+		writeError(w, http.StatusConflict, CodeInvalidBillState, "only draft bills can be edited")
+		return
+	}
+	before, err := billWithDetail(r.Context(), qtx, org, current)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to load bill lines and payments")
+		return
+	}
+
+	p, ok := resolveBillRequest(w, r, qtx, org, &req)
+	if !ok {
+		return
+	}
+
+	bill, err := qtx.UpdateBill(r.Context(), db.UpdateBillParams{
+		OrganisationID:   org.id,
+		ID:               current.ID,
+		ContactID:        p.bill.ContactID,
+		Number:           p.bill.Number,
+		Reference:        p.bill.Reference,
+		Currency:         p.bill.Currency,
+		IssueOn:          p.bill.IssueOn,
+		DueOn:            p.bill.DueOn,
+		PayableAccountID: p.bill.PayableAccountID,
+		SubtotalMinor:    p.bill.SubtotalMinor,
+		TaxMinor:         p.bill.TaxMinor,
+		TotalMinor:       p.bill.TotalMinor,
+	})
+	if err != nil {
+		if isUniqueViolation(err) {
+			writeFieldError(w, http.StatusConflict, CodeBillNumberTaken, "number", "the supplier already has a bill with this number")
+			return
+		}
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to update bill")
+		return
+	}
+	if err := qtx.DeleteBillLines(r.Context(), db.DeleteBillLinesParams{OrganisationID: org.id, BillID: bill.ID}); err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to replace bill lines")
+		return
+	}
+	p.lines.BillID = bill.ID
+	lines, err := qtx.CreateBillLines(r.Context(), p.lines)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to replace bill lines")
+		return
+	}
+
+	resp := toBillResponse(bill, lines, nil)
+	if err := recordAudit(r.Context(), qtx, org, audit.EntityBill, bill.ID, audit.ActionUpdate, before, resp); err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to record audit event")
+		return
+	}
+	if err := tx.Commit(r.Context()); err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to commit transaction")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// DELETE /bills/{id}
+//
+// Only drafts can be deleted; approved bills are voided so their postings
+// stay on record.
+func (s *Server) deleteBill(w http.ResponseWriter, r *http.Request) {
+	org := tenantFrom(r.Context())
+	tx, qtx, err := org.begin(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to begin transaction")
+		return
+	}
+	defer tx.Rollback(r.Context())
+
+	bill, ok := loadBill(w, r, qtx, true)
+	if !ok {
+		return
+	}
+	if bill.Status != billDraft {
+		writeError(w, http.StatusConflict, CodeInvalidBillState, "only draft bills can be deleted; void approved bills instead")
+		return
+	}
+	before, err := billWithDetail(r.Context(), qtx, org, bill)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to load bill lines and payments")
+		return
+	}
+
+	if err := qtx.DeleteBill(r.Context(), db.DeleteBillParams{OrganisationID: org.id, ID: bill.ID}); err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to delete bill")
+		return
+	}
+	if err := recordAudit(r.Context(), qtx, org, audit.EntityBill, bill.ID, audit.ActionDelete, before, nil); err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to record audit event")
+		return
+	}
+	if err := tx.Commit(r.Context()); err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to commit transaction")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// POST /bills/{id}/approve
+//
+// Posts a draft on its issue date: each line's amount is debited to its
+// expense or asset account, the tax debited to each tax code's account and
+// the total credited to the payable account.
+func (s *Server) approveBill(w http.ResponseWriter, r *http.Request) {
+	org := tenantFrom(r.Context())
+	tx, qtx, err := org.begin(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to begin transaction")
+		return
+	}
+	defer tx.Rollback(r.Context())
+
+	bill, ok := loadBill(w, r, qtx, true)
+	if !ok {
+		return
+	}
+	if bill.Status != billDraft {
+		writeError(w, http.StatusConflict, CodeInvalidBillState, "only draft bills can be approved")
+		return
+	}
+	lines, err := qtx.ListBillLines(r.Context(), db.ListBillLinesParams{OrganisationID: org.id, BillID: bill.ID})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to list bill lines")
+		return
+	}
+
+	var taxCodeIDs []pgtype.UUID
+	for _, l := range lines {
+		if l.TaxCodeID.Valid {
+			taxCodeIDs = append(taxCodeIDs, l.TaxCodeID)
+		}
+	}
+	taxCodes, err := taxCodesByID(r, qtx, org, taxCodeIDs)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to fetch tax codes")
+		return
+	}
+
+	postings := []ledgerPosting{{accountID: bill.PayableAccountID, amount: -bill.TotalMinor}}
+	for i, l := range lines {
+		postings = append(postings, ledgerPosting{accountID: l.AccountID, amount: l.AmountMinor})
+		if l.TaxMinor == 0 {
+			continue
+		}
+		code, found := taxCodes[l.TaxCodeID]
+		if !found || !code.AccountID.Valid {
+			writeFieldError(w, http.StatusConflict, CodeInvalidBillState, lineField(i, "tax_code_id"), "tax code no longer has an account to debit; save the bill again")
+			return
+		}
+		postings = append(postings, ledgerPosting{accountID: code.AccountID, amount: l.TaxMinor})
+	}
+
+	if !checkPostings(w, r, qtx, org, bill.IssueOn, "issue_on", postings) {
+		return
+	}
+	t, err := postBillTransaction(r.Context(), qtx, org, bill, "Bill "+bill.Number, bill.IssueOn, postings)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to post bill")
+		return
+	}
+
+	before := toBillResponse(bill, lines, nil)
+	approved, err := qtx.ApproveBill(r.Context(), db.ApproveBillParams{OrganisationID: org.id, ID: bill.ID, TransactionID: t.ID})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to approve bill")
+		return
+	}
+	resp := billPostingResponse{Bill: toBillResponse(approved, lines, nil), Transaction: &t.response}
+	if err := recordAudit(r.Context(), qtx, org, audit.EntityBill, bill.ID, audit.ActionApprove, before, resp.Bill); err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to record audit event")
+		return
+	}
+	if err := tx.Commit(r.Context()); err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to commit transaction")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// POST /bills/{id}/payments
+//
+// Records money paid against an approved bill: the payable is debited and
+// the bank account credited. The payment goes through the same path as POST
+// /transactions, idempotency key included, with source bill and the bill's
+// ID on the transaction. The bill is locked and its balance checked in the
+// same database transaction, so concurrent payments cannot overpay it.
+func (s *Server) recordBillPayment(w http.ResponseWriter, r *http.Request) {
+	var req billPaymentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, CodeInvalidJSON, "invalid json")
+		return
+	}
+
+	org := tenantFrom(r.Context())
+	var errs validationErrors
+	accountID, err := parseUUID(req.AccountID)
+	if err != nil {
+		errs.add("account_id", CodeInvalidFormat, "invalid account_id")
+	}
+	if req.Amount <= 0 {
+		errs.add("amount", CodeOutOfRange, "amount must be positive")
+	}
+	if req.PaidOn != "" {
+		if _, err := parseDate(req.PaidOn); err != nil {
+			errs.add("paid_on", CodeInvalidFormat, "invalid paid_on (use YYYY-MM-DD)")
+		}
+	}
+	if !errs.empty() {
+		writeValidationErrors(w, errs)
+		return
+	}
+
+	bill, ok := loadBill(w, r, org.q, false)
+	if !ok {
+		return
+	}
+	acc, err := org.q.GetAccount(r.Context(), db.GetAccountParams{OrganisationID: org.id, ID: accountID})
+	if errors.Is(err, pgx.ErrNoRows) {
+		writeFieldError(w, http.StatusBadRequest, CodeAccountNotFound, "account_id", "account not found")
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to get account")
+		return
+	}
+	switch typ := models.AccountType(acc.Type); {
+	case typ != models.AccountTypeAsset && typ != models.AccountTypeLiability:
+		writeFieldError(w, http.StatusBadRequest, CodeInvalidValue, "account_id", "payments are made from an asset or liability account")
+		return
+	case acc.ID == bill.PayableAccountID:
+		writeFieldError(w, http.StatusBadRequest, CodeInvalidValue, "account_id", "payments are made from an account other than the bill's payable account")
+		return
+	case acc.Currency != bill.Currency:
+		writeFieldError(w, http.StatusUnprocessableEntity, CodeCurrencyMismatch, "account_id", "account currency differs from the bill's")
+		return
+	}
+
+	description := strings.TrimSpace(req.Description)
+	if description == "" {
+		description = "Payment for bill " + bill.Number
+	}
+	s.postTransaction(w, r, createTransactionRequest{
+		IdempotencyKey: req.IdempotencyKey,
+		Description:    description,
+		Source:         sourceBill,
+		PostedOn:       req.PaidOn,
+		ContactID:      uuidString(bill.ContactID),
+		Entries: []ledgerEntryRequest{
+			{AccountID: uuidString(bill.PayableAccountID), Amount: req.Amount},
+			{AccountID: uuidString(acc.ID), Amount: -req.Amount},
+		},
+		billID: bill.ID,
+		afterPost: func(w http.ResponseWriter, r *http.Request, q *db.Queries, t db.Transaction) bool {
+			return applyBillPayment(w, r, q, org, bill.ID, acc.ID, req.Amount, t)
+		},
+	})
+}
+
+// applyBillPayment records transaction t as a payment of amount on the bill,
+// once the bill is locked and shown to still be owed that much. It writes the
+// error response itself and reports whether to carry on.
+func applyBillPayment(w http.ResponseWriter, r *http.Request, q *db.Queries, org *tenant, billID, accountID pgtype.UUID, amount int64, t db.Transaction) bool {
+	bill, err := q.GetBillForUpdate(r.Context(), db.GetBillForUpdateParams{OrganisationID: org.id, ID: billID})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to get bill")
+		return false
+	}
+	if bill.Status != billApproved {
+		writeError(w, http.StatusConflict, CodeInvalidBillState, "only approved bills take payments")
+		return false
+	}
+	if outstanding := bill.TotalMinor - bill.PaidMinor; amount > outstanding {
+		writeFieldError(w, http.StatusUnprocessableEntity, CodeBillOverpaid, "amount", fmt.Sprintf("amount exceeds the %d outstanding", outstanding))
+		return false
+	}
+
+	if _, err := q.CreateBillPayment(r.Context(), db.CreateBillPaymentParams{
+		OrganisationID: org.id,
+		BillID:         bill.ID,
+		TransactionID:  t.ID,
+		AccountID:      accountID,
+		PaidOn:         t.PostedOn,
+		AmountMinor:    amount,
+	}); err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to record payment")
+		return false
+	}
+	paid, err := q.AddBillPayment(r.Context(), db.AddBillPaymentParams{OrganisationID: org.id, ID: bill.ID, AmountMinor: amount, PaidOn: t.PostedOn})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to record payment")
+		return false
+	}
+	if err := recordAudit(r.Context(), q, org, audit.EntityBill, bill.ID, audit.ActionPay, toBillResponse(bill, nil, nil), toBillResponse(paid, nil, nil)); err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to record audit event")
+		return false
+	}
+	return true
+}
+
+// POST /bills/{id}/void
+//
+// Cancels a draft, or an approved bill nothing has been paid on. An approved
+// bill's posting is reversed by a transaction on voided_on, so the original
+// posting stays in the books.
+func (s *Server) voidBill(w http.ResponseWriter, r *http.Request) {
+	var req voidBillRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, CodeInvalidJSON, "invalid json")
+		return
+	}
+	org := tenantFrom(r.Context())
+	var errs validationErrors
+	voidedOn := parseOptionalDate(req.VoidedOn, "voided_on", org, &errs)
+	if !errs.empty() {
+		writeValidationErrors(w, errs)
+		return
+	}
+
+	tx, qtx, err := org.begin(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to begin transaction")
+		return
+	}
+	defer tx.Rollback(r.Context())
+
+	bill, ok := loadBill(w, r, qtx, true)
+	if !ok {
+		return
+	}
+	switch {
+	case bill.Status == billVoid:
+		writeError(w, http.StatusConflict, CodeInvalidBillState, "bill is already void")
+		return
+	case bill.PaidMinor > 0:
+		writeError(w, http.StatusConflict, CodeInvalidBillState, "bill has payments and cannot be voided")
+		return
+	}
+
+	var resp billPostingResponse
+	var voidTransactionID pgtype.UUID
+	if bill.TransactionID.Valid {
+		if voidedOn.Time.Before(bill.IssueOn.Time) {
+			writeFieldError(w, http.StatusBadRequest, CodeOutOfRange, "voided_on", "voided_on must not be before issue_on")
+			return
+		}
+		entries, err := qtx.ListLedgerEntries(r.Context(), db.ListLedgerEntriesParams{OrganisationID: org.id, TransactionID: bill.TransactionID})
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, CodeInternal, "failed to fetch ledger entries")
+			return
+		}
+		postings := make([]ledgerPosting, 0, len(entries))
+		for _, e := range entries {
+			postings = append(postings, ledgerPosting{accountID: e.AccountID, amount: -e.AmountMinor})
+		}
+		if !checkPostings(w, r, qtx, org, voidedOn, "voided_on", postings) {
+			return
+		}
+		t, err := postBillTransaction(r.Context(), qtx, org, bill, "Void bill "+bill.Number, voidedOn, postings)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, CodeInternal, "failed to post reversal")
+			return
+		}
+		voidTransactionID = t.ID
+		resp.Transaction = &t.response
+	}
+
+	voided, err := qtx.VoidBill(r.Context(), db.VoidBillParams{OrganisationID: org.id, ID: bill.ID, VoidedOn: voidedOn, VoidTransactionID: voidTransactionID})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to void bill")
+		return
+	}
+	if resp.Bill, err = billWithDetail(r.Context(), qtx, org, voided); err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to load bill lines and payments")
+		return
+	}
+	if err := recordAudit(r.Context(), qtx, org, audit.EntityBill, bill.ID, audit.ActionVoid, toBillResponse(bill, nil, nil), resp.Bill); err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to record audit event")
+		return
+	}
+	if err := tx.Commit(r.Context()); err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to commit transaction")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// parseBillRequest validates the shape of req and returns the bill and its
+// lines as far as they can be worked out without the database.
+func parseBillRequest(req *billRequest, org *tenant, errs *validationErrors) (billDraftParams, []invoicing.Line) {
+	p := billDraftParams{
+		bill: db.CreateBillParams{
+			OrganisationID: org.id,
+			Number:         strings.TrimSpace(req.Number),
+			Reference:      strings.TrimSpace(req.Reference),
+		},
+		lines: db.CreateBillLinesParams{OrganisationID: org.id},
+	}
+
+	id, err := parseUUID(req.ContactID)
+	if err != nil {
+		errs.add("contact_id", CodeInvalidFormat, "invalid contact_id")
+	}
+	p.bill.ContactID = id
+	switch {
+	case p.bill.Number == "":
+		errs.add("number", CodeRequired, "missing number")
+	case len(p.bill.Number) > maxInvoiceNumberLength:
+		errs.add("number", CodeTooLong, "number too long")
+	}
+	if len(p.bill.Reference) > maxStringLength {
+		errs.add("reference", CodeTooLong, "reference too long")
+	}
+	p.bill.IssueOn = parseOptionalDate(req.IssueOn, "issue_on", org, errs)
+	if req.DueOn == "" {
+		p.bill.DueOn = pgtype.Date{Time: p.bill.IssueOn.Time.AddDate(0, 0, defaultPaymentTermDays), Valid: true}
+	} else if d, err := parseDate(req.DueOn); err != nil {
+		errs.add("due_on", CodeInvalidFormat, "invalid due_on (use YYYY-MM-DD)")
+	} else if p.bill.DueOn = (pgtype.Date{Time: d, Valid: true}); d.Before(p.bill.IssueOn.Time) {
+		errs.add("due_on", CodeOutOfRange, "due_on must not be before issue_on")
+	}
+	if id, err = parseUUID(req.PayableAccountID); err != nil {
+		errs.add("payable_account_id", CodeInvalidFormat, "invalid payable_account_id")
+	}
+	p.bill.PayableAccountID = id
+
+	if len(req.Lines) == 0 {
+		errs.add("lines", CodeTooFew, "bill must have at least 1 line")
+	}
+	reqLines := make([]invoiceLineRequest, len(req.Lines))
+	for i, l := range req.Lines {
+		reqLines[i] = invoiceLineRequest(l)
+	}
+	calc, lines := parseLineRequests(reqLines, errs)
+	p.lines.Positions = lines.positions
+	p.lines.Descriptions = lines.descriptions
+	p.lines.QuantitiesThousandths = lines.quantities
+	p.lines.UnitPricesMinor = lines.unitPrices
+	p.lines.AccountIds = lines.accountIDs
+	p.lines.TaxCodeIds = lines.taxCodeIDs
+	return p, calc
+}
+
+// resolveBillRequest validates req against the organisation's contacts,
+// accounts and tax codes and works out the amounts. It writes the error
+// response itself and reports whether to carry on.
+func resolveBillRequest(w http.ResponseWriter, r *http.Request, q *db.Queries, org *tenant, req *billRequest) (billDraftParams, bool) {
+	var errs validationErrors
+	p, calc := parseBillRequest(req, org, &errs)
+	if !errs.empty() {
+		writeValidationErrors(w, errs)
+		return p, false
+	}
+
+	if _, err := q.GetContact(r.Context(), db.GetContactParams{OrganisationID: org.id, ID: p.bill.ContactID}); err != nil {
+		if !errors.Is(err, pgx.ErrNoRows) {
+			writeError(w, http.StatusInternalServerError, CodeInternal, "failed to get contact")
+			return p, false
+		}
+		errs.add("contact_id", CodeContactNotFound, "contact not found")
+	}
+
+	accounts, err := q.GetAccountsByIDs(r.Context(), db.GetAccountsByIDsParams{
+		OrganisationID: org.id,
+		Ids:            append([]pgtype.UUID{p.bill.PayableAccountID}, p.lines.AccountIds...),
+	})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to fetch accounts")
+		return p, false
+	}
+	byID := make(map[pgtype.UUID]db.Account, len(accounts))
+	for _, a := range accounts {
+		byID[a.ID] = a
+	}
+	checkAccount := func(field string, id pgtype.UUID, message string, want ...models.AccountType) (db.Account, bool) {
+		acc, found := byID[id]
+		switch {
+		case !found:
+			errs.add(field, CodeAccountNotFound, "account not found")
+		case acc.ArchivedAt.Valid:
+			errs.add(field, CodeAccountArchived, "account is archived")
+		case !slices.Contains(want, models.AccountType(acc.Type)):
+			errs.add(field, CodeInvalidValue, message)
+		default:
+			return acc, true
+		}
+		return acc, false
+	}
+	payable, ok := checkAccount("payable_account_id", p.bill.PayableAccountID, "must be a liability account", models.AccountTypeLiability)
+	if !ok {
+		writeValidationErrors(w, errs)
+		return p, false
+	}
+	p.bill.Currency = payable.Currency
+
+	var taxCodeIDs []pgtype.UUID
+	for _, id := range p.lines.TaxCodeIds {
+		if id.Valid {
+			taxCodeIDs = append(taxCodeIDs, id)
+		}
+	}
+	taxCodes, err := taxCodesByID(r, q, org, taxCodeIDs)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to fetch tax codes")
+		return p, false
+	}
+
+	for i := range calc {
+		if acc, ok := checkAccount(lineField(i, "account_id"), p.lines.AccountIds[i], "must be an expense or asset account", models.AccountTypeExpense, models.AccountTypeAsset); ok && acc.Currency != p.bill.Currency {
+			errs.add(lineField(i, "account_id"), CodeCurrencyMismatch, "account currency differs from the payable account's")
+		}
+		id := p.lines.TaxCodeIds[i]
+		if !id.Valid {
+			continue
+		}
+		code, found := taxCodes[id]
+		if !found {
+			errs.add(lineField(i, "tax_code_id"), CodeTaxCodeNotFound, "tax code not found")
+			continue
+		}
+		calc[i].TaxRate = int64(code.RateBasisPoints)
+	}
+
+	amounts, total := invoicing.Invoice(calc)
+	if errs.empty() && total.Total <= 0 {
+		errs.add("lines", CodeInvalidValue, "bill total must be positive")
+	}
+	if !errs.empty() {
+		writeValidationErrors(w, errs)
+		return p, false
+	}
+
+	for _, a := range amounts {
+		p.lines.AmountsMinor = append(p.lines.AmountsMinor, a.Net)
+		p.lines.TaxesMinor = append(p.lines.TaxesMinor, a.Tax)
+	}
+	p.bill.SubtotalMinor = total.Net
+	p.bill.TaxMinor = total.Tax
+	p.bill.TotalMinor = total.Total
+	return p, true
+}
+
+// postBillTransaction posts postings as one transaction filed under the
+// bill's supplier and linked to the bill.
+func postBillTransaction(ctx context.Context, q *db.Queries, org *tenant, bill db.Bill, description string, postedOn pgtype.Date, postings []ledgerPosting) (postedTransaction, error) {
+	return postLedgerTransaction(ctx, q, org, db.CreateTransactionParams{
+		Description: pgtype.Text{String: description, Valid: true},
+		Source:      sourceBill,
+		PostedOn:    postedOn,
+		ContactID:   bill.ContactID,
+		BillID:      bill.ID,
+	}, bill.Currency, postings)
+}
+
+func loadBill(w http.ResponseWriter, r *http.Request, q *db.Queries, forUpdate bool) (db.Bill, bool) {
+	id, err := parseUUID(chi.URLParam(r, "id"))
+	if err != nil {
+		writeFieldError(w, http.StatusBadRequest, CodeInvalidFormat, "id", "invalid id")
+		return db.Bill{}, false
+	}
+
+	org := tenantFrom(r.Context())
+	var bill db.Bill
+	if forUpdate {
+		bill, err = q.GetBillForUpdate(r.Context(), db.GetBillForUpdateParams{OrganisationID: org.id, ID: id})
+	} else {
+		bill, err = q.GetBill(r.Context(), db.GetBillParams{OrganisationID: org.id, ID: id})
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		writeError(w, http.StatusNotFound, CodeNotFound, "bill not found")
+		return db.Bill{}, false
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to get bill")
+		return db.Bill{}, false
+	}
+	return bill, true
+}
+
+func billWithDetail(ctx context.Context, q *db.Queries, org *tenant, bill db.Bill) (billResponse, error) {
+	lines, err := q.ListBillLines(ctx, db.ListBillLinesParams{OrganisationID: org.id, BillID: bill.ID})
+	if err != nil {
+		return billResponse{}, err
+	}
+	payments, err := q.ListBillPayments(ctx, db.ListBillPaymentsParams{OrganisationID: org.id, BillID: bill.ID})
+	if err != nil {
+		return billResponse{}, err
+	}
+	return toBillResponse(bill, lines, payments), nil
+}
+
+func toBillResponse(bill db.Bill, lines []db.BillLine, payments []db.BillPayment) billResponse {
+	resp := billResponse{
+		ID:                uuid.UUID(bill.ID.Bytes).String(),
+		ContactID:         uuid.UUID(bill.ContactID.Bytes).String(),
+		Number:            bill.Number,
+		Reference:         bill.Reference,
+		Currency:          bill.Currency,
+		IssueOn:           bill.IssueOn.Time.Format(time.DateOnly),
+		DueOn:             bill.DueOn.Time.Format(time.DateOnly),
+		Status:            bill.Status,
+		PayableAccountID:  uuid.UUID(bill.PayableAccountID.Bytes).String(),
+		Subtotal:          bill.SubtotalMinor,
+		Tax:               bill.TaxMinor,
+		Total:             bill.TotalMinor,
+		Paid:              bill.PaidMinor,
+		TransactionID:     uuidString(bill.TransactionID),
+		VoidTransactionID: uuidString(bill.VoidTransactionID),
+		CreatedAt:         bill.CreatedAt.Time.Format(time.RFC3339Nano),
+		UpdatedAt:         bill.UpdatedAt.Time.Format(time.RFC3339Nano),
+	}
+	if bill.Status == billApproved {
+		resp.Outstanding = bill.TotalMinor - bill.PaidMinor
+	}
+	if bill.ApprovedAt.Valid {
+		resp.ApprovedAt = bill.ApprovedAt.Time.Format(time.RFC3339Nano)
+	}
+	if bill.PaidOn.Valid {
+		resp.PaidOn = bill.PaidOn.Time.Format(time.DateOnly)
+	}
+	if bill.VoidedOn.Valid {
+		resp.VoidedOn = bill.VoidedOn.Time.Format(time.DateOnly)
+	}
+	for _, l := range lines {
+		resp.Lines = append(resp.Lines, invoiceLineResponse{
+			ID:          uuid.UUID(l.ID.Bytes).String(),
+			Description: l.Description,
+			Quantity:    float64(l.QuantityThousandths) / 1000,
+			UnitPrice:   l.UnitPriceMinor,
+			AccountID:   uuid.UUID(l.AccountID.Bytes).String(),
+			TaxCodeID:   uuidString(l.TaxCodeID),
+			Amount:      l.AmountMinor,
+			Tax:         l.TaxMinor,
+		})
+	}
+	for _, p := range payments {
+		resp.Payments = append(resp.Payments, invoicePaymentResponse{
+			ID:            uuid.UUID(p.ID.Bytes).String(),
+			TransactionID: uuid.UUID(p.TransactionID.Bytes).String(),
+			AccountID:     uuid.UUID(p.AccountID.Bytes).String(),
+			PaidOn:        p.PaidOn.Time.Format(time.DateOnly),
+			Amount:        p.AmountMinor,
+			CreatedAt:     p.CreatedAt.Time.Format(time.RFC3339Nano),
+		})
+	}
+	return resp
+}
@@ -0,0 +1,115 @@
+package httpserver
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+
+	db "github.com/LBaronceli/go-figure/internal/db/sqlc"
+)
+
+func TestParseBillRequest(t *testing.T) {
+	quantity := 2.0
+	req := billRequest{
+		ContactID:        "0b8a1c1e-8a8f-4b8e-9a57-3f1f5a0d2c11",
+		Number:           " SUP-991 ",
+		IssueOn:          "2026-05-20",
+		DueOn:            "2026-06-20",
+		PayableAccountID: "5f0c7a52-3c1b-4d7e-8a3a-0d4b0f5c6e21",
+		Lines: []billLineRequest{
+			{Description: "Paper", Quantity: &quantity, UnitPrice: 1250, AccountID: "7d2e1f3a-9b8c-4a5d-8e6f-1a2b3c4d5e6f", TaxCodeID: "9c1d2e3f-4a5b-4c6d-8e7f-0a1b2c3d4e5f"},
+		},
+	}
+	var errs validationErrors
+	p, lines := parseBillRequest(&req, testTenant(t), &errs)
+	require.Empty(t, errs)
+	require.Equal(t, "SUP-991", p.bill.Number)
+	require.Equal(t, "2026-06-20", p.bill.DueOn.Time.Format(time.DateOnly))
+	require.Equal(t, []int32{1}, p.lines.Positions)
+	require.Equal(t, int64(2000), lines[0].QuantityThousandths)
+	require.True(t, p.lines.TaxCodeIds[0].Valid)
+}
+
+func TestParseBillRequestValidation(t *testing.T) {
+	req := billRequest{
+		ContactID:        "not-a-uuid",
+		IssueOn:          "2026-05-20",
+		DueOn:            "2026-05-01",
+		PayableAccountID: "5f0c7a52-3c1b-4d7e-8a3a-0d4b0f5c6e21",
+	}
+	var errs validationErrors
+	parseBillRequest(&req, testTenant(t), &errs)
+
+	fields := make([]string, 0, len(errs))
+	for _, e := range errs {
+		fields = append(fields, e.Field)
+	}
+	require.Equal(t, []string{"contact_id", "number", "due_on", "lines"}, fields)
+}
+
+func TestParseTransactionRequestBillSource(t *testing.T) {
+	entries := []ledgerEntryRequest{
+		{AccountID: "5f0c7a52-3c1b-4d7e-8a3a-0d4b0f5c6e21", Amount: 100},
+		{AccountID: "7d2e1f3a-9b8c-4a5d-8e6f-1a2b3c4d5e6f", Amount: -100},
+	}
+
+	// only bill payments may post with source bill
+	var errs validationErrors
+	req := createTransactionRequest{IdempotencyKey: "k", Source: "bill", Entries: entries}
+	parseTransactionRequest(&req, &errs)
+	require.Len(t, errs, 1)
+	require.Equal(t, "source", errs[0].Field)
+
+	errs = nil
+	req.billID = pgtype.UUID{Bytes: uuid.New(), Valid: true}
+	parseTransactionRequest(&req, &errs)
+	require.Empty(t, errs)
+
+	// a key reused for a payment of a different bill is not a retry
+	other := req
+	other.billID = pgtype.UUID{Bytes: uuid.New(), Valid: true}
+	require.NotEqual(t, req.requestHash(), other.requestHash())
+}
+
+func TestProjectCashFlow(t *testing.T) {
+	day := func(s string) pgtype.Date {
+		d, err := time.Parse(time.DateOnly, s)
+		require.NoError(t, err)
+		return pgtype.Date{Time: d, Valid: true}
+	}
+	asOf := day("2026-05-01").Time
+	invoices := []db.ListOutstandingInvoicesRow{
+		{Number: "INV-0001", Currency: "NZD", DueOn: day("2026-04-20"), OutstandingMinor: 5000},
+		{Number: "INV-0002", Currency: "NZD", DueOn: day("2026-05-10"), OutstandingMinor: 3000},
+		{Number: "INV-0003", Currency: "NZD", DueOn: day("2026-07-01"), OutstandingMinor: 9999},
+	}
+	bills := []db.ListOutstandingBillsRow{
+		{Number: "B-1", Currency: "NZD", DueOn: day("2026-05-03"), OutstandingMinor: 7000},
+		{Number: "B-2", Currency: "AUD", DueOn: day("2026-05-15"), OutstandingMinor: 1200},
+	}
+
+	resp := projectCashFlow(asOf, 14, invoices, bills)
+	require.Equal(t, "2026-05-15", resp.EndsOn)
+	require.Len(t, resp.Currencies, 2)
+
+	aud := resp.Currencies[0]
+	require.Equal(t, "AUD", aud.Currency)
+	require.Equal(t, int64(-1200), aud.Net)
+	require.Len(t, aud.Weeks, 3)
+	require.Equal(t, "2026-05-15", aud.Weeks[2].StartsOn)
+	require.Equal(t, int64(1200), aud.Weeks[2].Payments)
+
+	nzd := resp.Currencies[1]
+	require.Equal(t, int64(8000), nzd.Receipts)
+	require.Equal(t, int64(7000), nzd.Payments)
+	require.Equal(t, int64(1000), nzd.Net)
+	// the overdue invoice is expected today; INV-0003 is beyond the window
+	require.Equal(t, []string{"INV-0001", "B-1", "INV-0002"}, []string{nzd.Items[0].Number, nzd.Items[1].Number, nzd.Items[2].Number})
+	require.Len(t, nzd.Items, 3)
+	require.Equal(t, "2026-05-01", nzd.Items[0].ExpectedOn)
+	require.Equal(t, cashFlowWeek{StartsOn: "2026-05-01", EndsOn: "2026-05-07", Receipts: 5000, Payments: 7000, Net: -2000, Running: -2000}, nzd.Weeks[0])
+	require.Equal(t, cashFlowWeek{StartsOn: "2026-05-08", EndsOn: "2026-05-14", Receipts: 3000, Net: 3000, Running: 1000}, nzd.Weeks[1])
+}
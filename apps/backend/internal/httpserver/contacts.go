@@ -0,0 +1,709 @@
+package httpserver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/LBaronceli/go-figure/internal/audit"
+	"github.com/LBaronceli/go-figure/internal/contacts"
+	db "github.com/LBaronceli/go-figure/internal/db/sqlc"
+)
+
+type contactRequest struct {
+	Name             string `json:"name" openapi:"minLength=1,maxLength=500"`
+	Email            string `json:"email" openapi:"optional,maxLength=500"`
+	DefaultAccountID string `json:"default_account_id" openapi:"optional,format=uuid" doc:"Account suggested for transactions with this contact"`
+}
+
+type contactAliasRequest struct {
+	Pattern string `json:"pattern" openapi:"minLength=3,maxLength=500" doc:"Matched anywhere in a description, case-insensitively; * stands for any run of characters"`
+}
+
+type mergeContactRequest struct {
+	ContactID string `json:"contact_id" openapi:"format=uuid" doc:"Contact to merge into this one; it is deleted once its transactions and aliases move over"`
+}
+
+type setTransactionContactRequest struct {
+	ContactID string `json:"contact_id" openapi:"optional,format=uuid" doc:"Empty clears the contact"`
+}
+
+type contactAliasResponse struct {
+	ID        string `json:"id" openapi:"format=uuid"`
+	Pattern   string `json:"pattern"`
+	CreatedAt string `json:"created_at" openapi:"format=date-time"`
+}
+
+type contactResponse struct {
+	ID               string                 `json:"id" openapi:"format=uuid"`
+	Name             string                 `json:"name"`
+	Email            string                 `json:"email,omitempty"`
+	DefaultAccountID string                 `json:"default_account_id,omitempty" openapi:"format=uuid"`
+	Aliases          []contactAliasResponse `json:"aliases"`
+	CreatedAt        string                 `json:"created_at" openapi:"format=date-time"`
+	UpdatedAt        string                 `json:"updated_at" openapi:"format=date-time"`
+}
+
+type contactMatchResponse struct {
+	Contact contactResponse `json:"contact"`
+	Pattern string          `json:"pattern" doc:"The alias that matched"`
+}
+
+type mergeContactResponse struct {
+	Contact              contactResponse `json:"contact"`
+	TransactionsMoved    int64           `json:"transactions_moved"`
+	InvoicesMoved        int64           `json:"invoices_moved"`
+	BillsMoved           int64           `json:"bills_moved"`
+	ReceiptsMoved        int64           `json:"receipts_moved"`
+	AliasesMoved         int64           `json:"aliases_moved"`
+	MergedContactDeleted string          `json:"merged_contact_id" openapi:"format=uuid"`
+}
+
+// POST /contacts
+func (s *Server) createContact(w http.ResponseWriter, r *http.Request) {
+	var req contactRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, CodeInvalidJSON, "invalid json")
+		return
+	}
+	org := tenantFrom(r.Context())
+	params, ok := s.parseContactRequest(w, r, org, &req)
+	if !ok {
+		return
+	}
+
+	tx, qtx, err := org.begin(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to begin transaction")
+		return
+	}
+	defer tx.Rollback(r.Context())
+
+	c, err := qtx.CreateContact(r.Context(), db.CreateContactParams{
+		OrganisationID:   org.id,
+		Name:             params.Name,
+		Email:            params.Email,
+		DefaultAccountID: params.DefaultAccountID,
+	})
+	if err != nil {
+		if isUniqueViolation(err) {
+			writeFieldError(w, http.StatusConflict, CodeNameTaken, "name", "a contact with this name already exists")
+			return
+		}
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to create contact")
+		return
+	}
+
+	resp := toContactResponse(c, nil)
+	if err := recordAudit(r.Context(), qtx, org, audit.EntityContact, c.ID, audit.ActionCreate, nil, resp); err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to record audit event")
+		return
+	}
+	if err := tx.Commit(r.Context()); err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to commit transaction")
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, resp)
+}
+
+// GET /contacts
+func (s *Server) listContacts(w http.ResponseWriter, r *http.Request) {
+	org := tenantFrom(r.Context())
+	cs, err := org.q.ListContacts(r.Context(), org.id)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to list contacts")
+		return
+	}
+	aliases, err := org.q.ListContactAliases(r.Context(), db.ListContactAliasesParams{OrganisationID: org.id})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to list contact aliases")
+		return
+	}
+
+	resp := make([]contactResponse, 0, len(cs))
+	for _, c := range cs {
+		resp = append(resp, toContactResponse(c, aliases))
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// GET /contacts/{id}
+func (s *Server) getContact(w http.ResponseWriter, r *http.Request) {
+	org := tenantFrom(r.Context())
+	c, ok := loadContact(w, r, org.q)
+	if !ok {
+		return
+	}
+	resp, err := contactWithAliases(r.Context(), org.q, org, c)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to list contact aliases")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// PUT /contacts/{id}
+func (s *Server) updateContact(w http.ResponseWriter, r *http.Request) {
+	var req contactRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, CodeInvalidJSON, "invalid json")
+		return
+	}
+	org := tenantFrom(r.Context())
+	params, ok := s.parseContactRequest(w, r, org, &req)
+	if !ok {
+		return
+	}
+
+	tx, qtx, err := org.begin(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to begin transaction")
+		return
+	}
+	defer tx.Rollback(r.Context())
+
+	current, ok := loadContact(w, r, qtx)
+	if !ok {
+		return
+	}
+	before, err := contactWithAliases(r.Context(), qtx, org, current)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to list contact aliases")
+		return
+	}
+
+	c, err := qtx.UpdateContact(r.Context(), db.UpdateContactParams{
+		OrganisationID:   org.id,
+		ID:               current.ID,
+		Name:             params.Name,
+		Email:            params.Email,
+		DefaultAccountID: params.DefaultAccountID,
+	})
+	if err != nil {
+		if isUniqueViolation(err) {
+			writeFieldError(w, http.StatusConflict, CodeNameTaken, "name", "a contact with this name already exists")
+			return
+		}
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to update contact")
+		return
+	}
+
+	after := before
+	after.Name, after.Email, after.DefaultAccountID = c.Name, c.Email.String, uuidString(c.DefaultAccountID)
+	after.UpdatedAt = c.UpdatedAt.Time.Format(time.RFC3339Nano)
+	if err := recordAudit(r.Context(), qtx, org, audit.EntityContact, c.ID, audit.ActionUpdate, before, after); err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to record audit event")
+		return
+	}
+	if err := tx.Commit(r.Context()); err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to commit transaction")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, after)
+}
+
+// DELETE /contacts/{id}
+//
+// Only contacts without transactions can be deleted; merge the others into
+// the contact that replaces them.
+func (s *Server) deleteContact(w http.ResponseWriter, r *http.Request) {
+	org := tenantFrom(r.Context())
+	tx, qtx, err := org.begin(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to begin transaction")
+		return
+	}
+	defer tx.Rollback(r.Context())
+
+	c, ok := loadContact(w, r, qtx)
+	if !ok {
+		return
+	}
+	before, err := contactWithAliases(r.Context(), qtx, org, c)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to list contact aliases")
+		return
+	}
+
+	if err := qtx.DeleteContact(r.Context(), db.DeleteContactParams{OrganisationID: org.id, ID: c.ID}); err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" { // foreign_key_violation
+			writeError(w, http.StatusConflict, CodeContactInUse, "contact has "+contactReferences(pgErr.ConstraintName)+"; merge it into another contact instead")
+			return
+		}
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to delete contact")
+		return
+	}
+
+	if err := recordAudit(r.Context(), qtx, org, audit.EntityContact, c.ID, audit.ActionDelete, before, nil); err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to record audit event")
+		return
+	}
+	if err := tx.Commit(r.Context()); err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to commit transaction")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// POST /contacts/{id}/merge
+//
+// Folds a duplicate contact into this one: its transactions, invoices, bills,
+// receipts and aliases are re-pointed here, this contact inherits its default
+// account if it has none, and the duplicate is deleted. Both contacts having
+// a bill with the same number is a conflict, as suppliers number their own.
+func (s *Server) mergeContact(w http.ResponseWriter, r *http.Request) {
+	var req mergeContactRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, CodeInvalidJSON, "invalid json")
+		return
+	}
+	sourceID, err := parseUUID(req.ContactID)
+	if err != nil {
+		writeFieldError(w, http.StatusBadRequest, CodeInvalidFormat, "contact_id", "invalid contact_id")
+		return
+	}
+
+	org := tenantFrom(r.Context())
+	tx, qtx, err := org.begin(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to begin transaction")
+		return
+	}
+	defer tx.Rollback(r.Context())
+
+	target, ok := loadContact(w, r, qtx)
+	if !ok {
+		return
+	}
+	if target.ID == sourceID {
+		writeFieldError(w, http.StatusBadRequest, CodeInvalidValue, "contact_id", "a contact cannot be merged into itself")
+		return
+	}
+	source, err := qtx.GetContact(r.Context(), db.GetContactParams{OrganisationID: org.id, ID: sourceID})
+	if errors.Is(err, pgx.ErrNoRows) {
+		writeFieldError(w, http.StatusBadRequest, CodeContactNotFound, "contact_id", "contact not found")
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to get contact")
+		return
+	}
+	before, err := contactWithAliases(r.Context(), qtx, org, source)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to list contact aliases")
+		return
+	}
+
+	reassign := db.ReassignContactTransactionsParams{OrganisationID: org.id, ToContactID: target.ID, FromContactID: source.ID}
+	moved, err := qtx.ReassignContactTransactions(r.Context(), reassign)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to move transactions")
+		return
+	}
+	invoices, err := qtx.ReassignContactInvoices(r.Context(), db.ReassignContactInvoicesParams(reassign))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to move invoices")
+		return
+	}
+	bills, err := qtx.ReassignContactBills(r.Context(), db.ReassignContactBillsParams(reassign))
+	if err != nil {
+		if isUniqueViolation(err) {
+			writeFieldError(w, http.StatusConflict, CodeBillNumberTaken, "contact_id", "both contacts have a bill with the same number; renumber one first")
+			return
+		}
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to move bills")
+		return
+	}
+	scans, err := qtx.ReassignContactReceiptScans(r.Context(), db.ReassignContactReceiptScansParams(reassign))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to move receipts")
+		return
+	}
+	aliases, err := qtx.ReassignContactAliases(r.Context(), db.ReassignContactAliasesParams(reassign))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to move aliases")
+		return
+	}
+	if !target.DefaultAccountID.Valid && source.DefaultAccountID.Valid {
+		target, err = qtx.UpdateContact(r.Context(), db.UpdateContactParams{
+			OrganisationID:   org.id,
+			ID:               target.ID,
+			Name:             target.Name,
+			Email:            target.Email,
+			DefaultAccountID: source.DefaultAccountID,
+		})
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, CodeInternal, "failed to update contact")
+			return
+		}
+	}
+	if err := qtx.DeleteContact(r.Context(), db.DeleteContactParams{OrganisationID: org.id, ID: source.ID}); err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to delete merged contact")
+		return
+	}
+
+	merged, err := contactWithAliases(r.Context(), qtx, org, target)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to list contact aliases")
+		return
+	}
+	resp := mergeContactResponse{
+		Contact:              merged,
+		TransactionsMoved:    moved,
+		InvoicesMoved:        invoices,
+		BillsMoved:           bills,
+		ReceiptsMoved:        scans,
+		AliasesMoved:         aliases,
+		MergedContactDeleted: uuidString(source.ID),
+	}
+	if err := recordAudit(r.Context(), qtx, org, audit.EntityContact, source.ID, audit.ActionMerge, before, resp); err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to record audit event")
+		return
+	}
+	if err := tx.Commit(r.Context()); err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to commit transaction")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// POST /contacts/{id}/aliases
+func (s *Server) createContactAlias(w http.ResponseWriter, r *http.Request) {
+	var req contactAliasRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, CodeInvalidJSON, "invalid json")
+		return
+	}
+	if len(req.Pattern) > maxStringLength {
+		writeFieldError(w, http.StatusBadRequest, CodeTooLong, "pattern", "pattern too long")
+		return
+	}
+	pattern, err := contacts.NormalisePattern(req.Pattern)
+	if err != nil {
+		writeFieldError(w, http.StatusBadRequest, CodeTooShort, "pattern", err.Error())
+		return
+	}
+
+	org := tenantFrom(r.Context())
+	c, ok := loadContact(w, r, org.q)
+	if !ok {
+		return
+	}
+
+	a, err := org.q.CreateContactAlias(r.Context(), db.CreateContactAliasParams{OrganisationID: org.id, ContactID: c.ID, Pattern: pattern})
+	if err != nil {
+		if isUniqueViolation(err) {
+			writeFieldError(w, http.StatusConflict, CodeNameTaken, "pattern", "the pattern already names a contact")
+			return
+		}
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to create contact alias")
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, toContactAliasResponse(a))
+}
+
+// DELETE /contacts/{id}/aliases/{alias_id}
+func (s *Server) deleteContactAlias(w http.ResponseWriter, r *http.Request) {
+	contactID, err := parseUUID(chi.URLParam(r, "id"))
+	if err != nil {
+		writeFieldError(w, http.StatusBadRequest, CodeInvalidFormat, "id", "invalid id")
+		return
+	}
+	aliasID, err := parseUUID(chi.URLParam(r, "alias_id"))
+	if err != nil {
+		writeFieldError(w, http.StatusBadRequest, CodeInvalidFormat, "alias_id", "invalid alias_id")
+		return
+	}
+
+	org := tenantFrom(r.Context())
+	n, err := org.q.DeleteContactAlias(r.Context(), db.DeleteContactAliasParams{OrganisationID: org.id, ContactID: contactID, ID: aliasID})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to delete contact alias")
+		return
+	}
+	if n == 0 {
+		writeError(w, http.StatusNotFound, CodeNotFound, "contact alias not found")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GET /contacts/match
+//
+// Names the contact a raw description belongs to, for importers that want
+// the contact and its default account before posting.
+func (s *Server) matchContact(w http.ResponseWriter, r *http.Request) {
+	description := strings.TrimSpace(r.URL.Query().Get("description"))
+	if description == "" {
+		writeFieldError(w, http.StatusBadRequest, CodeRequired, "description", "missing description")
+		return
+	}
+
+	org := tenantFrom(r.Context())
+	aliases, err := org.q.ListContactAliases(r.Context(), db.ListContactAliasesParams{OrganisationID: org.id})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to list contact aliases")
+		return
+	}
+	alias, found := contacts.Match(toAliases(aliases), description)
+	if !found {
+		writeError(w, http.StatusNotFound, CodeNotFound, "no contact alias matches the description")
+		return
+	}
+
+	c, err := org.q.GetContact(r.Context(), db.GetContactParams{OrganisationID: org.id, ID: pgtype.UUID{Bytes: alias.ContactID, Valid: true}})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to get contact")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, contactMatchResponse{Contact: toContactResponse(c, aliases), Pattern: alias.Pattern})
+}
+
+// PUT /transactions/{id}/contact
+//
+// Sets or clears the contact of a posted transaction. The contact is a label,
+// not a figure, so it may change inside locked periods.
+func (s *Server) setTransactionContact(w http.ResponseWriter, r *http.Request) {
+	id, err := parseUUID(chi.URLParam(r, "id"))
+	if err != nil {
+		writeFieldError(w, http.StatusBadRequest, CodeInvalidFormat, "id", "invalid id")
+		return
+	}
+	var req setTransactionContactRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, CodeInvalidJSON, "invalid json")
+		return
+	}
+	var contactID pgtype.UUID
+	if req.ContactID != "" {
+		if contactID, err = parseUUID(req.ContactID); err != nil {
+			writeFieldError(w, http.StatusBadRequest, CodeInvalidFormat, "contact_id", "invalid contact_id")
+			return
+		}
+	}
+
+	org := tenantFrom(r.Context())
+	tx, qtx, err := org.begin(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to begin transaction")
+		return
+	}
+	defer tx.Rollback(r.Context())
+
+	current, err := qtx.GetTransaction(r.Context(), db.GetTransactionParams{OrganisationID: org.id, ID: id})
+	if errors.Is(err, pgx.ErrNoRows) {
+		writeError(w, http.StatusNotFound, CodeNotFound, "transaction not found")
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to get transaction")
+		return
+	}
+
+	t, err := qtx.SetTransactionContact(r.Context(), db.SetTransactionContactParams{OrganisationID: org.id, ID: id, ContactID: contactID})
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" { // foreign_key_violation
+			writeFieldError(w, http.StatusBadRequest, CodeContactNotFound, "contact_id", "contact not found")
+			return
+		}
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to update transaction")
+		return
+	}
+
+	before, after := toTransactionResponse(current), toTransactionResponse(t)
+	if err := recordAudit(r.Context(), qtx, org, audit.EntityTransaction, t.ID, audit.ActionUpdate, before, after); err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to record audit event")
+		return
+	}
+	if err := tx.Commit(r.Context()); err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to commit transaction")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, after)
+}
+
+// parseContactRequest validates req, including that its default account
+// exists, and returns the stored form of its fields.
+func (s *Server) parseContactRequest(w http.ResponseWriter, r *http.Request, org *tenant, req *contactRequest) (db.CreateContactParams, bool) {
+	var errs validationErrors
+	params := db.CreateContactParams{OrganisationID: org.id, Name: strings.TrimSpace(req.Name)}
+	if params.Name == "" {
+		errs.add("name", CodeRequired, "name is required")
+	} else if len(params.Name) > maxStringLength {
+		errs.add("name", CodeTooLong, "name too long")
+	}
+	if email := strings.TrimSpace(req.Email); email != "" {
+		if len(email) > maxStringLength {
+			errs.add("email", CodeTooLong, "email too long")
+		} else if !strings.Contains(email, "@") {
+			errs.add("email", CodeInvalidFormat, "invalid email")
+		}
+		params.Email = pgtype.Text{String: email, Valid: true}
+	}
+	if req.DefaultAccountID != "" {
+		id, err := parseUUID(req.DefaultAccountID)
+		if err != nil {
+			errs.add("default_account_id", CodeInvalidFormat, "invalid default_account_id")
+		}
+		params.DefaultAccountID = id
+	}
+	if !errs.empty() {
+		writeValidationErrors(w, errs)
+		return db.CreateContactParams{}, false
+	}
+
+	if params.DefaultAccountID.Valid {
+		_, err := org.q.GetAccount(r.Context(), db.GetAccountParams{OrganisationID: org.id, ID: params.DefaultAccountID})
+		if errors.Is(err, pgx.ErrNoRows) {
+			writeFieldError(w, http.StatusBadRequest, CodeAccountNotFound, "default_account_id", "account not found")
+			return db.CreateContactParams{}, false
+		}
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, CodeInternal, "failed to get account")
+			return db.CreateContactParams{}, false
+		}
+	}
+	return params, true
+}
+
+func loadContact(w http.ResponseWriter, r *http.Request, q *db.Queries) (db.Contact, bool) {
+	id, err := parseUUID(chi.URLParam(r, "id"))
+	if err != nil {
+		writeFieldError(w, http.StatusBadRequest, CodeInvalidFormat, "id", "invalid id")
+		return db.Contact{}, false
+	}
+
+	org := tenantFrom(r.Context())
+	c, err := q.GetContact(r.Context(), db.GetContactParams{OrganisationID: org.id, ID: id})
+	if errors.Is(err, pgx.ErrNoRows) {
+		writeError(w, http.StatusNotFound, CodeNotFound, "contact not found")
+		return db.Contact{}, false
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to get contact")
+		return db.Contact{}, false
+	}
+	return c, true
+}
+
+// contactReferences names what a foreign key violation on deleting a contact
+// says still refers to it.
+func contactReferences(constraint string) string {
+	switch constraint {
+	case "invoices_contact_fk":
+		return "invoices"
+	case "bills_contact_fk":
+		return "bills"
+	case "receipt_scans_contact_fk":
+		return "receipts"
+	default:
+		return "transactions"
+	}
+}
+
+func contactWithAliases(ctx context.Context, q *db.Queries, org *tenant, c db.Contact) (contactResponse, error) {
+	aliases, err := q.ListContactAliases(ctx, db.ListContactAliasesParams{OrganisationID: org.id, ContactID: c.ID})
+	if err != nil {
+		return contactResponse{}, err
+	}
+	return toContactResponse(c, aliases), nil
+}
+
+// resolveContact returns the contact a new transaction is filed under: the
+// one the request names, which must exist, or else the one whose alias
+// matches the description.
+func resolveContact(ctx context.Context, org *tenant, req createTransactionRequest, errs *validationErrors) (pgtype.UUID, error) {
+	if req.ContactID != "" {
+		id, _ := parseUUID(req.ContactID)
+		_, err := org.q.GetContact(ctx, db.GetContactParams{OrganisationID: org.id, ID: id})
+		if errors.Is(err, pgx.ErrNoRows) {
+			errs.add("contact_id", CodeContactNotFound, "contact not found")
+			return pgtype.UUID{}, nil
+		}
+		return id, err
+	}
+	if req.Description == "" {
+		return pgtype.UUID{}, nil
+	}
+	aliases, err := org.q.ListContactAliases(ctx, db.ListContactAliasesParams{OrganisationID: org.id})
+	if err != nil {
+		return pgtype.UUID{}, err
+	}
+	return matchContactAlias(toAliases(aliases), req.Description), nil
+}
+
+// matchContactAlias names the contact whose alias matches description, or
+// returns an invalid UUID when none does.
+func matchContactAlias(aliases []contacts.Alias, description string) pgtype.UUID {
+	if description == "" {
+		return pgtype.UUID{}
+	}
+	a, found := contacts.Match(aliases, description)
+	if !found {
+		return pgtype.UUID{}
+	}
+	return pgtype.UUID{Bytes: a.ContactID, Valid: true}
+}
+
+func toAliases(rows []db.ContactAlias) []contacts.Alias {
+	out := make([]contacts.Alias, 0, len(rows))
+	for _, a := range rows {
+		out = append(out, contacts.Alias{ID: a.ID.Bytes, ContactID: a.ContactID.Bytes, Pattern: a.Pattern})
+	}
+	return out
+}
+
+func toContactResponse(c db.Contact, aliases []db.ContactAlias) contactResponse {
+	resp := contactResponse{
+		ID:               uuidString(c.ID),
+		Name:             c.Name,
+		Email:            c.Email.String,
+		DefaultAccountID: uuidString(c.DefaultAccountID),
+		Aliases:          []contactAliasResponse{},
+		CreatedAt:        c.CreatedAt.Time.Format(time.RFC3339Nano),
+		UpdatedAt:        c.UpdatedAt.Time.Format(time.RFC3339Nano),
+	}
+	for _, a := range aliases {
+		if a.ContactID == c.ID {
+			resp.Aliases = append(resp.Aliases, toContactAliasResponse(a))
+		}
+	}
+	return resp
+}
+
+func toContactAliasResponse(a db.ContactAlias) contactAliasResponse {
+	return contactAliasResponse{
+		ID:        uuidString(a.ID),
+		Pattern:   a.Pattern,
+		CreatedAt: a.CreatedAt.Time.Format(time.RFC3339Nano),
+	}
+}
+
+// uuidString formats a nullable UUID, empty when it is NULL.
+func uuidString(id pgtype.UUID) string {
+	if !id.Valid {
+		return ""
+	}
+	return uuid.UUID(id.Bytes).String()
+}
@@ -0,0 +1,66 @@
+package httpserver
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+
+	db "github.com/LBaronceli/go-figure/internal/db/sqlc"
+)
+
+func TestToContactResponseKeepsOwnAliases(t *testing.T) {
+	contact := pgtype.UUID{Bytes: uuid.New(), Valid: true}
+	other := pgtype.UUID{Bytes: uuid.New(), Valid: true}
+	aliases := []db.ContactAlias{
+		{ID: pgtype.UUID{Bytes: uuid.New(), Valid: true}, ContactID: contact, Pattern: "COUNTDOWN"},
+		{ID: pgtype.UUID{Bytes: uuid.New(), Valid: true}, ContactID: other, Pattern: "BUNNINGS"},
+	}
+
+	resp := toContactResponse(db.Contact{ID: contact, Name: "Countdown"}, aliases)
+	require.Len(t, resp.Aliases, 1)
+	require.Equal(t, "COUNTDOWN", resp.Aliases[0].Pattern)
+	require.Empty(t, resp.Email)
+	require.Empty(t, resp.DefaultAccountID)
+
+	require.Empty(t, toContactResponse(db.Contact{ID: other}, nil).Aliases)
+	require.NotNil(t, toContactResponse(db.Contact{ID: other}, nil).Aliases)
+}
+
+func TestMatchContactAlias(t *testing.T) {
+	contact := uuid.New()
+	aliases := toAliases([]db.ContactAlias{
+		{ID: pgtype.UUID{Bytes: uuid.New(), Valid: true}, ContactID: pgtype.UUID{Bytes: contact, Valid: true}, Pattern: "POS * COUNTDOWN"},
+	})
+
+	got := matchContactAlias(aliases, "pos 4421 countdown ponsonby")
+	require.True(t, got.Valid)
+	require.Equal(t, contact, uuid.UUID(got.Bytes))
+
+	require.False(t, matchContactAlias(aliases, "countdown").Valid)
+	require.False(t, matchContactAlias(aliases, "").Valid)
+}
+
+func TestParseTransactionRequestCanonicalisesContact(t *testing.T) {
+	req := createTransactionRequest{ContactID: "0B8A1C1E-8A8F-4B8E-9A57-3F1F5A0D2C11"}
+	var errs validationErrors
+	parseTransactionRequest(&req, &errs)
+	require.Equal(t, "0b8a1c1e-8a8f-4b8e-9a57-3f1f5a0d2c11", req.ContactID)
+
+	req = createTransactionRequest{ContactID: "nope"}
+	errs = nil
+	parseTransactionRequest(&req, &errs)
+	fields := make([]string, 0, len(errs))
+	for _, e := range errs {
+		fields = append(fields, e.Field)
+	}
+	require.Contains(t, fields, "contact_id")
+}
+
+func TestContactReferences(t *testing.T) {
+	require.Equal(t, "transactions", contactReferences("transactions_contact_fk"))
+	require.Equal(t, "invoices", contactReferences("invoices_contact_fk"))
+	require.Equal(t, "bills", contactReferences("bills_contact_fk"))
+	require.Equal(t, "receipts", contactReferences("receipt_scans_contact_fk"))
+}
@@ -0,0 +1,252 @@
+package httpserver
+
+import (
+	"net/http"
+)
+
+// ErrorCode is a stable, machine-readable identifier for an API error.
+// Codes are part of the API contract: clients branch on them, so a published
+// code must never be renamed or reused. Messages are for humans and may change.
+type ErrorCode string
+
+const (
+	// Request shape
+
+	// CodeInvalidJSON means the request body could not be decoded.
+	CodeInvalidJSON ErrorCode = "invalid_json"
+	// CodeBodyTooLarge means the request body exceeded the size the server
+	// reads.
+	CodeBodyTooLarge ErrorCode = "body_too_large"
+	// CodeValidationFailed wraps one or more field errors, listed in
+	// details.fields.
+	CodeValidationFailed ErrorCode = "validation_failed"
+	// CodeRequired means a mandatory field was missing or blank.
+	CodeRequired ErrorCode = "required"
+	// CodeInvalidValue means a field was present but not an accepted value.
+	CodeInvalidValue ErrorCode = "invalid_value"
+	// CodeInvalidFormat means a field did not parse (uuid, RFC3339, ...).
+	CodeInvalidFormat ErrorCode = "invalid_format"
+	// CodeInvalidType means a field had the wrong JSON type.
+	CodeInvalidType ErrorCode = "invalid_type"
+	// CodeTooShort means a string field was shorter than its minimum length.
+	CodeTooShort ErrorCode = "too_short"
+	// CodeTooLong means a string field exceeded its maximum length.
+	CodeTooLong ErrorCode = "too_long"
+	// CodeTooFew means a list field had fewer items than required.
+	CodeTooFew ErrorCode = "too_few"
+	// CodeTooMany means a list field had more items than allowed.
+	CodeTooMany ErrorCode = "too_many"
+	// CodeOutOfRange means a number was outside its allowed range.
+	CodeOutOfRange ErrorCode = "out_of_range"
+	// CodeNothingToUpdate means an update request changed no fields.
+	CodeNothingToUpdate ErrorCode = "nothing_to_update"
+
+	// Resources
+
+	// CodeNotFound means the resource addressed by the URL does not exist.
+	CodeNotFound ErrorCode = "not_found"
+	// CodeAccountNotFound means a referenced account does not exist.
+	CodeAccountNotFound ErrorCode = "account_not_found"
+
+	// Accounts
+
+	// CodeAccountHasLedgerEntries means the account has history and can only
+	// be archived, not deleted.
+	CodeAccountHasLedgerEntries ErrorCode = "account_has_ledger_entries"
+	// CodeAccountTypeLocked means the account type cannot change because the
+	// account has history.
+	CodeAccountTypeLocked ErrorCode = "account_type_locked"
+	// CodeAccountArchived means an archived account was used in a posting.
+	CodeAccountArchived ErrorCode = "account_archived"
+
+	// Transactions
+
+	// CodeCurrencyMismatch means the entries of a transaction use accounts
+	// in different currencies.
+	CodeCurrencyMismatch ErrorCode = "currency_mismatch"
+	// CodeAmountOverflow means the entry amounts overflow int64.
+	CodeAmountOverflow ErrorCode = "amount_overflow"
+	// CodeTransactionNotBalanced means the entries do not sum to zero.
+	CodeTransactionNotBalanced ErrorCode = "transaction_not_balanced"
+	// CodeIdempotencyKeyReused means the idempotency key already belongs to
+	// a request with a different payload.
+	CodeIdempotencyKeyReused ErrorCode = "idempotency_key_reused"
+	// CodeIdempotencyKeyInFlight means the first request with this
+	// Idempotency-Key has not finished yet.
+	CodeIdempotencyKeyInFlight ErrorCode = "idempotency_key_in_flight"
+	// CodePeriodLocked means the posting date falls inside a locked
+	// accounting period, or a new lock overlaps an existing one.
+	CodePeriodLocked ErrorCode = "period_locked"
+	// CodeEntryAlreadyCorrected means a ledger entry was already reversed by
+	// a correcting transaction such as a split.
+	CodeEntryAlreadyCorrected ErrorCode = "entry_already_corrected"
+
+	// Reconciliation
+
+	// CodeReconciliationCompleted means the reconciliation is completed and
+	// can no longer change.
+	CodeReconciliationCompleted ErrorCode = "reconciliation_completed"
+	// CodeInvalidMatchState means the statement line is not in a state that
+	// allows the requested match change.
+	CodeInvalidMatchState ErrorCode = "invalid_match_state"
+	// CodeEntryAlreadyMatched means the ledger entry already clears another
+	// statement line.
+	CodeEntryAlreadyMatched ErrorCode = "entry_already_matched"
+	// CodeAmountMismatch means a statement line and a ledger entry have
+	// different amounts.
+	CodeAmountMismatch ErrorCode = "amount_mismatch"
+	// CodeReconciliationUnbalanced means the statement does not agree with
+	// the cleared ledger balance, or lines are still unmatched.
+	CodeReconciliationUnbalanced ErrorCode = "reconciliation_unbalanced"
+
+	// Tracking
+
+	// CodeNameTaken means another tracking category, option, contact or
+	// contact alias already has the name or pattern.
+	CodeNameTaken ErrorCode = "name_taken"
+	// CodeTrackingInUse means ledger entries use the tracking category or
+	// option, so it can only be deactivated.
+	CodeTrackingInUse ErrorCode = "tracking_in_use"
+	// CodeTrackingOptionNotFound means a referenced tracking option does not
+	// exist.
+	CodeTrackingOptionNotFound ErrorCode = "tracking_option_not_found"
+	// CodeTrackingOptionInactive means an inactive tracking option was used in
+	// a posting.
+	CodeTrackingOptionInactive ErrorCode = "tracking_option_inactive"
+
+	// Contacts
+
+	// CodeContactNotFound means a referenced contact does not exist.
+	CodeContactNotFound ErrorCode = "contact_not_found"
+	// CodeContactInUse means transactions, invoices, bills or receipts name
+	// the contact, so it can only be merged into another.
+	CodeContactInUse ErrorCode = "contact_in_use"
+
+	// Invoices
+
+	// CodeTaxCodeNotFound means a referenced tax code does not exist.
+	CodeTaxCodeNotFound ErrorCode = "tax_code_not_found"
+	// CodeTaxCodeInUse means invoice or bill lines use the tax code, so it cannot be
+	// deleted.
+	CodeTaxCodeInUse ErrorCode = "tax_code_in_use"
+	// CodeInvoiceNumberTaken means another invoice already has the number.
+	CodeInvoiceNumberTaken ErrorCode = "invoice_number_taken"
+	// CodeInvalidInvoiceState means the invoice's status does not allow the
+	// change, such as editing a sent invoice or voiding a paid one.
+	CodeInvalidInvoiceState ErrorCode = "invalid_invoice_state"
+	// CodeInvoiceOverpaid means a payment exceeds what is still owed on the
+	// invoice.
+	CodeInvoiceOverpaid ErrorCode = "invoice_overpaid"
+
+	// Bills
+
+	// CodeBillNumberTaken means the supplier already has a bill with the
+	// number.
+	CodeBillNumberTaken ErrorCode = "bill_number_taken"
+	// CodeInvalidBillState means the bill's status does not allow the
+	// change, such as paying a draft or voiding a paid bill.
+	CodeInvalidBillState ErrorCode = "invalid_bill_state"
+	// CodeBillOverpaid means a payment exceeds what is still owed on the
+	// bill.
+	CodeBillOverpaid ErrorCode = "bill_overpaid"
+
+	// Receipts
+
+	// CodeInvalidReceiptState means the receipt's scan does not allow the
+	// change, such as posting a receipt that matched a bank line or was
+	// already posted.
+	CodeInvalidReceiptState ErrorCode = "invalid_receipt_state"
+
+	// Authentication
+
+	// CodeUnauthenticated means the request carried no valid API token or
+	// session.
+	CodeUnauthenticated ErrorCode = "unauthenticated"
+	// CodeInvalidCredentials means a login used an unknown email or a wrong
+	// password; the two are deliberately indistinguishable.
+	CodeInvalidCredentials ErrorCode = "invalid_credentials"
+	// CodeInsufficientScope means the credential lacks the scope the route
+	// requires.
+	CodeInsufficientScope ErrorCode = "insufficient_scope"
+	// CodeEmailTaken means another user already has the email address.
+	CodeEmailTaken ErrorCode = "email_taken"
+
+	// Organisations
+
+	// CodeOrganisationNotFound means the organisation does not exist or the
+	// caller is not a member; the two are deliberately indistinguishable.
+	CodeOrganisationNotFound ErrorCode = "organisation_not_found"
+	// CodeOrganisationRequired means the caller belongs to several
+	// organisations and must pick one with X-Organisation-ID.
+	CodeOrganisationRequired ErrorCode = "organisation_required"
+	// CodeNoOrganisation means the caller belongs to no organisation yet.
+	CodeNoOrganisation ErrorCode = "no_organisation"
+	// CodeInsufficientRole means the caller's role in the organisation does
+	// not allow the request.
+	CodeInsufficientRole ErrorCode = "insufficient_role"
+	// CodeUserNotFound means a referenced user does not exist.
+	CodeUserNotFound ErrorCode = "user_not_found"
+	// CodeAlreadyMember means the user already belongs to the organisation.
+	CodeAlreadyMember ErrorCode = "already_member"
+	// CodeLastOwner means the change would leave the organisation without an
+	// owner.
+	CodeLastOwner ErrorCode = "last_owner"
+
+	// Server
+
+	// CodeInternal means the server failed; the request may be retried.
+	CodeInternal ErrorCode = "internal_error"
+	// CodeUnavailable means a dependency is not ready, or the server is too
+	// busy to take the request, in which case Retry-After is set.
+	CodeUnavailable ErrorCode = "unavailable"
+)
+
+type errorResponse struct {
+	Error apiError `json:"error"`
+}
+
+type apiError struct {
+	Code    ErrorCode      `json:"code"`
+	Message string         `json:"message"`
+	Field   string         `json:"field,omitempty"`
+	Details map[string]any `json:"details,omitempty"`
+}
+
+type fieldError struct {
+	Field   string    `json:"field"`
+	Code    ErrorCode `json:"code"`
+	Message string    `json:"message"`
+}
+
+// validationErrors collects every invalid field of a request so the client
+// gets them all in one response instead of fixing them one round trip at a time.
+type validationErrors []fieldError
+
+func (v *validationErrors) add(field string, code ErrorCode, msg string) {
+	*v = append(*v, fieldError{Field: field, Code: code, Message: msg})
+}
+
+func (v validationErrors) empty() bool {
+	return len(v) == 0
+}
+
+func writeError(w http.ResponseWriter, status int, code ErrorCode, msg string) {
+	writeJSON(w, status, errorResponse{Error: apiError{Code: code, Message: msg}})
+}
+
+func writeFieldError(w http.ResponseWriter, status int, code ErrorCode, field, msg string) {
+	writeJSON(w, status, errorResponse{Error: apiError{Code: code, Message: msg, Field: field}})
+}
+
+func writeValidationErrors(w http.ResponseWriter, errs validationErrors) {
+	e := apiError{
+		Code:    CodeValidationFailed,
+		Message: "request validation failed",
+		Details: map[string]any{"fields": errs},
+	}
+	if len(errs) == 1 {
+		e.Field = errs[0].Field
+		e.Message = errs[0].Message
+	}
+	writeJSON(w, http.StatusBadRequest, errorResponse{Error: e})
+}
@@ -0,0 +1,50 @@
+package httpserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteValidationErrorsReportsEveryField(t *testing.T) {
+	var errs validationErrors
+	errs.add("name", CodeRequired, "name is required")
+	errs.add("currency", CodeInvalidValue, "currency must be a 3-letter ISO 4217 code")
+
+	rec := httptest.NewRecorder()
+	writeValidationErrors(rec, errs)
+
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	require.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+	var body struct {
+		Error struct {
+			Code    ErrorCode `json:"code"`
+			Field   string    `json:"field"`
+			Details struct {
+				Fields []fieldError `json:"fields"`
+			} `json:"details"`
+		} `json:"error"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	require.Equal(t, CodeValidationFailed, body.Error.Code)
+	require.Empty(t, body.Error.Field)
+	require.Len(t, body.Error.Details.Fields, 2)
+	require.Equal(t, "currency", body.Error.Details.Fields[1].Field)
+}
+
+func TestWriteValidationErrorsSingleFieldIsHoisted(t *testing.T) {
+	var errs validationErrors
+	errs.add("entries", CodeTransactionNotBalanced, "transaction is not balanced (sum must be 0)")
+
+	rec := httptest.NewRecorder()
+	writeValidationErrors(rec, errs)
+
+	var body errorResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	require.Equal(t, "entries", body.Error.Field)
+	require.Equal(t, "transaction is not balanced (sum must be 0)", body.Error.Message)
+}
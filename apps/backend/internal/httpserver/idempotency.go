@@ -0,0 +1,410 @@
+package httpserver
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/LBaronceli/go-figure/internal/auth"
+	db "github.com/LBaronceli/go-figure/internal/db/sqlc"
+)
+
+// defaultIdempotencyRetention is how long an idempotency key stays bound to
+// its transaction. After that the key is released and may be reused.
+const defaultIdempotencyRetention = 30 * 24 * time.Hour
+
+// idempotentReplayedHeader marks a response that was served from an earlier
+// request with the same idempotency key rather than executed again.
+const idempotentReplayedHeader = "Idempotent-Replayed"
+
+// requestHash fingerprints everything in a create request that affects the
+// resulting transaction, so a reused key can be told apart from a genuine
+// retry. Call it after the request has been normalised.
+func (req createTransactionRequest) requestHash() []byte {
+	type canonicalEntry struct {
+		AccountID string                  `json:"account_id"`
+		Amount    int64                   `json:"amount"`
+		Tags      []string                `json:"tags,omitempty"`
+		Tracking  []entryTrackingResponse `json:"tracking,omitempty"`
+	}
+	canonical := struct {
+		Description string           `json:"description"`
+		Source      string           `json:"source"`
+		PostedOn    string           `json:"posted_on"`
+		PostedAt    string           `json:"posted_at"`
+		Entries     []canonicalEntry `json:"entries"`
+		Corrects    string           `json:"corrects_entry_id,omitempty"`
+		ContactID   string           `json:"contact_id,omitempty"`
+		BillID      string           `json:"bill_id,omitempty"`
+	}{
+		Description: req.Description,
+		Source:      req.Source,
+		PostedOn:    req.PostedOn,
+		PostedAt:    req.PostedAt,
+		ContactID:   req.ContactID,
+		BillID:      uuidString(req.billID),
+		Entries:     make([]canonicalEntry, 0, len(req.Entries)),
+	}
+	if req.correctsEntryID.Valid {
+		canonical.Corrects = uuid.UUID(req.correctsEntryID.Bytes).String()
+	}
+	if t, err := time.Parse(time.RFC3339, req.PostedAt); err == nil {
+		canonical.PostedAt = t.UTC().Format(time.RFC3339Nano)
+	}
+	for _, e := range req.Entries {
+		id := e.AccountID
+		if uid, err := uuid.Parse(id); err == nil {
+			id = uid.String()
+		}
+		ce := canonicalEntry{AccountID: id, Amount: e.Amount, Tags: e.Tags}
+		for _, t := range e.Tracking {
+			ct := entryTrackingResponse(t)
+			if uid, err := uuid.Parse(t.CategoryID); err == nil {
+				ct.CategoryID = uid.String()
+			}
+			if uid, err := uuid.Parse(t.OptionID); err == nil {
+				ct.OptionID = uid.String()
+			}
+			ce.Tracking = append(ce.Tracking, ct)
+		}
+		canonical.Entries = append(canonical.Entries, ce)
+	}
+
+	b, _ := json.Marshal(canonical)
+	sum := sha256.Sum256(b)
+	return sum[:]
+}
+
+// replayTransaction answers a request whose idempotency key already belongs
+// to a transaction: the stored transaction when the payload matches, 422 when
+// it does not.
+func (s *Server) replayTransaction(w http.ResponseWriter, r *http.Request, existing db.Transaction, hash []byte) {
+	// Rows written before hashes were stored cannot be checked; trust them.
+	if existing.RequestHash != nil && !bytes.Equal(existing.RequestHash, hash) {
+		writeFieldError(w, http.StatusUnprocessableEntity, CodeIdempotencyKeyReused, "idempotency_key", "idempotency key reused with different payload")
+		return
+	}
+
+	org := tenantFrom(r.Context())
+	entries, err := org.q.ListLedgerEntries(r.Context(), db.ListLedgerEntriesParams{OrganisationID: org.id, TransactionID: existing.ID})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to fetch ledger entries")
+		return
+	}
+	dims, err := loadEntryDimensions(r.Context(), org.q, org, entries)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to fetch entry tags and tracking")
+		return
+	}
+
+	resp := toFullTransactionResponse(existing, entries)
+	dims.attach(&resp)
+	w.Header().Set(idempotentReplayedHeader, "true")
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) idempotencyCutoff() pgtype.Timestamptz {
+	return pgtype.Timestamptz{Time: time.Now().Add(-s.idempotencyRetention), Valid: true}
+}
+
+// ReleaseExpiredIdempotencyKeys frees every idempotency key older than the
+// retention window, both transaction keys and Idempotency-Key records.
+func (s *Server) ReleaseExpiredIdempotencyKeys(ctx context.Context) (int64, error) {
+	var released int64
+	err := s.withoutRLS(ctx, func(q *db.Queries) error {
+		var err error
+		released, err = q.ReleaseExpiredIdempotencyKeys(ctx, s.idempotencyCutoff())
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	deleted, err := s.q.DeleteExpiredIdempotencyRecords(ctx, s.idempotencyCutoff())
+	if err != nil {
+		return released, err
+	}
+	return released + deleted, nil
+}
+
+// RunIdempotencyJanitor releases expired keys every interval until ctx is
+// done. Keys are also released lazily when reused, so the janitor only keeps
+// the table tidy.
+func (s *Server) RunIdempotencyJanitor(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			n, err := s.ReleaseExpiredIdempotencyKeys(ctx)
+			if err != nil {
+				slog.Error("idempotency janitor", "err", err)
+				continue
+			}
+			if n > 0 {
+				slog.Info("idempotency janitor released keys", "count", n)
+			}
+		}
+	}
+}
+
+// idempotencyKeyHeader is the standard header clients send to make a
+// mutating request safe to retry.
+const (
+	idempotencyKeyHeader    = "Idempotency-Key"
+	maxIdempotencyKeyLength = 255
+	maxIdempotentBodySize   = 10 << 20
+)
+
+// replayedHeaders are the response headers stored with a record and sent
+// again on replay. Everything else is per-response (Date, request IDs).
+var replayedHeaders = []string{"Content-Type", "Location"}
+
+// idempotencyKeys makes every mutating request that carries an
+// Idempotency-Key header execute at most once per key.
+//
+// The request runs in one database transaction that inserts the key's record
+// first and completes it last, so the handler's changes and the stored
+// response commit together or not at all. Handlers reach the transaction
+// through requestDB, and their own transactions become savepoints in it. A
+// concurrent duplicate blocks on the record's unique index until the first
+// request commits, then replays it. Server errors roll everything back so
+// the client can retry.
+func (s *Server) idempotencyKeys(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(idempotencyKeyHeader)
+		if key == "" || !isMutating(r.Method) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			writeFieldError(w, http.StatusBadRequest, CodeTooLong, idempotencyKeyHeader, "Idempotency-Key too long")
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxIdempotentBodySize))
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				writeError(w, http.StatusRequestEntityTooLarge, CodeBodyTooLarge, "request body larger than 10 MiB")
+				return
+			}
+			writeError(w, http.StatusBadRequest, CodeInvalidJSON, "failed to read body")
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		ctx := r.Context()
+		scope := idempotencyScope(r)
+		hash := sha256.New()
+		hash.Write([]byte(r.Method + " " + r.URL.RequestURI() + "\n"))
+		hash.Write(body)
+		requestHash := hash.Sum(nil)
+
+		tx, err := s.db.Begin(ctx)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, CodeInternal, "failed to begin transaction")
+			return
+		}
+		// once the handler has run its outcome is saved even if the client
+		// has gone
+		saveCtx := context.WithoutCancel(ctx)
+		defer tx.Rollback(saveCtx)
+
+		qtx := s.q.WithTx(tx)
+
+		// A record that disappears between the insert and the read expired
+		// in the meantime; claim the key again, once.
+		for attempt := 0; ; attempt++ {
+			if err := qtx.DeleteExpiredIdempotencyRecord(ctx, db.DeleteExpiredIdempotencyRecordParams{
+				Scope:  scope,
+				Key:    key,
+				Cutoff: s.idempotencyCutoff(),
+			}); err != nil {
+				writeError(w, http.StatusInternalServerError, CodeInternal, "failed to check idempotency key")
+				return
+			}
+
+			inserted, err := qtx.InsertIdempotencyRecord(ctx, db.InsertIdempotencyRecordParams{
+				Scope:       scope,
+				Key:         key,
+				Method:      r.Method,
+				Path:        r.URL.Path,
+				RequestHash: requestHash,
+			})
+			if err != nil {
+				writeError(w, http.StatusInternalServerError, CodeInternal, "failed to record idempotency key")
+				return
+			}
+			if inserted == 1 {
+				break
+			}
+
+			rec, err := qtx.GetIdempotencyRecord(ctx, db.GetIdempotencyRecordParams{Scope: scope, Key: key})
+			if errors.Is(err, pgx.ErrNoRows) && attempt == 0 {
+				continue
+			}
+			if err != nil {
+				writeError(w, http.StatusInternalServerError, CodeInternal, "failed to load idempotency record")
+				return
+			}
+			if !bytes.Equal(rec.RequestHash, requestHash) {
+				writeFieldError(w, http.StatusUnprocessableEntity, CodeIdempotencyKeyReused, idempotencyKeyHeader, "idempotency key reused with different payload")
+				return
+			}
+			if !rec.StatusCode.Valid {
+				// Only possible if a record was committed without completing,
+				// which the middleware never does.
+				writeError(w, http.StatusConflict, CodeIdempotencyKeyInFlight, "request with this idempotency key is still in progress")
+				return
+			}
+			replayRecord(w, rec)
+			return
+		}
+
+		rw := &bufferedResponse{header: make(http.Header), status: http.StatusOK}
+		next.ServeHTTP(rw, r.WithContext(context.WithValue(ctx, requestTxKey{}, tx)))
+
+		if rw.status >= http.StatusInternalServerError {
+			// roll back so a retry runs the request again
+			rw.flush(w)
+			return
+		}
+
+		stored := make(map[string]string, len(replayedHeaders))
+		for _, h := range replayedHeaders {
+			if v := rw.header.Get(h); v != "" {
+				stored[h] = v
+			}
+		}
+		headers, _ := json.Marshal(stored)
+
+		if err := qtx.CompleteIdempotencyRecord(saveCtx, db.CompleteIdempotencyRecordParams{
+			Scope:           scope,
+			Key:             key,
+			StatusCode:      pgtype.Int4{Int32: int32(rw.status), Valid: true},
+			ResponseHeaders: headers,
+			ResponseBody:    rw.body.Bytes(),
+		}); err != nil {
+			slog.Error("idempotency: failed to store response", "key", key, "err", err)
+			writeError(w, http.StatusInternalServerError, CodeInternal, "failed to store response")
+			return
+		}
+		if err := tx.Commit(saveCtx); err != nil {
+			// nothing the handler did was saved, so a retry runs it again
+			slog.Error("idempotency: failed to commit record", "key", key, "err", err)
+			writeError(w, http.StatusInternalServerError, CodeInternal, "failed to commit transaction")
+			return
+		}
+
+		rw.flush(w)
+	})
+}
+
+type requestTxKey struct{}
+
+// requestDB is where a request's statements and transactions begin: the
+// transaction of an Idempotency-Key request, so everything it does commits
+// with its stored response on the one connection it holds, or else the
+// pool.
+func (s *Server) requestDB(ctx context.Context) beginner {
+	if tx, ok := ctx.Value(requestTxKey{}).(pgx.Tx); ok {
+		return tx
+	}
+	return s.db
+}
+
+// queries is s.q for handlers outside an organisation, run in the request's
+// transaction when it has one.
+func (s *Server) queries(ctx context.Context) *db.Queries {
+	if tx, ok := ctx.Value(requestTxKey{}).(pgx.Tx); ok {
+		return db.New(statementDB{db: tx})
+	}
+	return s.q
+}
+
+// begin starts a transaction for a handler outside an organisation: a
+// savepoint in the request's transaction when it has one.
+func (s *Server) begin(ctx context.Context) (pgx.Tx, *db.Queries, error) {
+	tx, err := s.requestDB(ctx).Begin(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	return tx, s.q.WithTx(tx), nil
+}
+
+// idempotencyScope namespaces keys so two callers cannot collide on, or
+// replay, each other's keys.
+func idempotencyScope(r *http.Request) string {
+	p, _ := auth.PrincipalFrom(r.Context())
+	scope := p.UserID.String()
+	if org := r.Header.Get(organisationHeader); org != "" {
+		scope += "/" + org
+	}
+	return scope
+}
+
+func isMutating(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+func replayRecord(w http.ResponseWriter, rec db.IdempotencyRecord) {
+	var headers map[string]string
+	_ = json.Unmarshal(rec.ResponseHeaders, &headers)
+	for k, v := range headers {
+		w.Header().Set(k, v)
+	}
+	w.Header().Set(idempotentReplayedHeader, "true")
+	w.WriteHeader(int(rec.StatusCode.Int32))
+	_, _ = w.Write(rec.ResponseBody)
+}
+
+// bufferedResponse holds a handler's response until the idempotency record
+// is committed, so a client never sees a result that was not stored.
+type bufferedResponse struct {
+	header      http.Header
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (b *bufferedResponse) Header() http.Header {
+	return b.header
+}
+
+func (b *bufferedResponse) WriteHeader(status int) {
+	if b.wroteHeader {
+		return
+	}
+	b.status = status
+	b.wroteHeader = true
+}
+
+func (b *bufferedResponse) Write(p []byte) (int, error) {
+	b.wroteHeader = true
+	return b.body.Write(p)
+}
+
+func (b *bufferedResponse) flush(w http.ResponseWriter) {
+	for k, vs := range b.header {
+		w.Header()[k] = vs
+	}
+	w.WriteHeader(b.status)
+	_, _ = w.Write(b.body.Bytes())
+}
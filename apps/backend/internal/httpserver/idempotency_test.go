@@ -0,0 +1,26 @@
+package httpserver
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIdempotencyKeysRejectsOversizedBody(t *testing.T) {
+	// the body is read before any record is claimed, so no pool is needed
+	s := &Server{}
+	h := s.idempotencyKeys(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Fatal("handler ran with a truncated body")
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/transactions/batch", bytes.NewReader(make([]byte, maxIdempotentBodySize+1)))
+	req.Header.Set(idempotencyKeyHeader, "batch-1")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+	require.Contains(t, rec.Body.String(), string(CodeBodyTooLarge))
+}
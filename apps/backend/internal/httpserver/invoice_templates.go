@@ -0,0 +1,314 @@
+package httpserver
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	db "github.com/LBaronceli/go-figure/internal/db/sqlc"
+	"github.com/LBaronceli/go-figure/internal/invoicepdf"
+)
+
+const (
+	defaultAccentColor = "#1f4e79"
+	// maxTemplateTextLength bounds the address and the text templates.
+	maxTemplateTextLength = 2000
+)
+
+var accentColorPattern = regexp.MustCompile(`^#[0-9a-f]{6}$`)
+
+type invoiceTemplateRequest struct {
+	TradingName         string `json:"trading_name" openapi:"optional,maxLength=500" doc:"Name invoices are issued under; defaults to the organisation's name"`
+	Address             string `json:"address" openapi:"optional,maxLength=2000" doc:"One line per address line"`
+	TaxNumber           string `json:"tax_number" openapi:"optional,maxLength=500" doc:"GST number; when set, PDFs are titled TAX INVOICE"`
+	Email               string `json:"email" openapi:"optional,maxLength=500"`
+	AccentColor         string `json:"accent_color" openapi:"optional" doc:"#rrggbb; defaults to #1f4e79"`
+	PaymentInstructions string `json:"payment_instructions" openapi:"optional,maxLength=2000" doc:"Go text/template over the invoice, e.g. Pay {{.AmountDue}} {{.Currency}} to 12-3456-0123456-00 by {{.DueOn}} quoting {{.Number}}. Fields: Number, Reference, Currency, IssueOn, DueOn, Total, Paid, AmountDue, Customer, TradingName"`
+	Footer              string `json:"footer" openapi:"optional,maxLength=2000" doc:"Go text/template shown at the foot of every page, with the same fields as payment_instructions"`
+}
+
+type invoiceLogoRequest struct {
+	Data        []byte `json:"data" doc:"Base64-encoded image, at most 512 KiB and 4000 pixels a side"`
+	ContentType string `json:"content_type" openapi:"enum=image/png|image/jpeg"`
+}
+
+type invoiceTemplateResponse struct {
+	TradingName         string `json:"trading_name"`
+	Address             string `json:"address"`
+	TaxNumber           string `json:"tax_number"`
+	Email               string `json:"email"`
+	AccentColor         string `json:"accent_color"`
+	PaymentInstructions string `json:"payment_instructions"`
+	Footer              string `json:"footer"`
+	LogoType            string `json:"logo_type,omitempty" doc:"Absent without a logo"`
+	UpdatedAt           string `json:"updated_at,omitempty" openapi:"format=date-time" doc:"Absent until the template is first saved"`
+}
+
+// GET /invoice-template
+func (s *Server) getInvoiceTemplate(w http.ResponseWriter, r *http.Request) {
+	org := tenantFrom(r.Context())
+	t, err := loadInvoiceTemplate(r, org)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to get invoice template")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, toInvoiceTemplateResponse(t))
+}
+
+// PUT /invoice-template
+//
+// Replaces everything but the logo.
+func (s *Server) updateInvoiceTemplate(w http.ResponseWriter, r *http.Request) {
+	var req invoiceTemplateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, CodeInvalidJSON, "invalid json")
+		return
+	}
+
+	org := tenantFrom(r.Context())
+	params := db.UpsertInvoiceTemplateParams{
+		OrganisationID:      org.id,
+		TradingName:         strings.TrimSpace(req.TradingName),
+		Address:             strings.TrimSpace(req.Address),
+		TaxNumber:           strings.TrimSpace(req.TaxNumber),
+		Email:               strings.TrimSpace(req.Email),
+		AccentColor:         strings.ToLower(strings.TrimSpace(req.AccentColor)),
+		PaymentInstructions: req.PaymentInstructions,
+		Footer:              req.Footer,
+	}
+	var errs validationErrors
+	if len(params.TradingName) > maxStringLength {
+		errs.add("trading_name", CodeTooLong, "trading name too long")
+	}
+	if len(params.TaxNumber) > maxStringLength {
+		errs.add("tax_number", CodeTooLong, "tax number too long")
+	}
+	if len(params.Email) > maxStringLength {
+		errs.add("email", CodeTooLong, "email too long")
+	} else if params.Email != "" && !strings.Contains(params.Email, "@") {
+		errs.add("email", CodeInvalidFormat, "invalid email")
+	}
+	if params.AccentColor == "" {
+		params.AccentColor = defaultAccentColor
+	} else if !accentColorPattern.MatchString(params.AccentColor) {
+		errs.add("accent_color", CodeInvalidFormat, "invalid accent_color (use #rrggbb)")
+	}
+	if len(params.Address) > maxTemplateTextLength {
+		errs.add("address", CodeTooLong, "address too long")
+	}
+	checkText := func(field, src string) {
+		if len(src) > maxTemplateTextLength {
+			errs.add(field, CodeTooLong, strings.ReplaceAll(field, "_", " ")+" too long")
+		} else if err := invoicepdf.CheckText(src); err != nil {
+			errs.add(field, CodeInvalidValue, "invalid template: "+err.Error())
+		}
+	}
+	checkText("payment_instructions", params.PaymentInstructions)
+	checkText("footer", params.Footer)
+	if !errs.empty() {
+		writeValidationErrors(w, errs)
+		return
+	}
+
+	t, err := org.q.UpsertInvoiceTemplate(r.Context(), params)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to save invoice template")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, toInvoiceTemplateResponse(t))
+}
+
+// PUT /invoice-template/logo
+func (s *Server) updateInvoiceLogo(w http.ResponseWriter, r *http.Request) {
+	// base64 grows the image by a third
+	r.Body = http.MaxBytesReader(w, r.Body, invoicepdf.MaxLogoBytes*2)
+	var req invoiceLogoRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			writeFieldError(w, http.StatusBadRequest, CodeTooLong, "data", "logo too large")
+			return
+		}
+		writeError(w, http.StatusBadRequest, CodeInvalidJSON, "invalid json")
+		return
+	}
+	if len(req.Data) == 0 {
+		writeFieldError(w, http.StatusBadRequest, CodeRequired, "data", "data is required")
+		return
+	}
+	if err := invoicepdf.CheckLogo(req.Data, req.ContentType); err != nil {
+		writeFieldError(w, http.StatusBadRequest, CodeInvalidValue, "data", err.Error())
+		return
+	}
+
+	org := tenantFrom(r.Context())
+	t, err := org.q.SetInvoiceTemplateLogo(r.Context(), db.SetInvoiceTemplateLogoParams{
+		OrganisationID: org.id,
+		Logo:           req.Data,
+		LogoType:       pgtype.Text{String: req.ContentType, Valid: true},
+	})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to save logo")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, toInvoiceTemplateResponse(t))
+}
+
+// DELETE /invoice-template/logo
+func (s *Server) deleteInvoiceLogo(w http.ResponseWriter, r *http.Request) {
+	org := tenantFrom(r.Context())
+	if _, err := org.q.SetInvoiceTemplateLogo(r.Context(), db.SetInvoiceTemplateLogoParams{OrganisationID: org.id}); err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to remove logo")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GET /invoices/{id}.pdf
+func (s *Server) getInvoicePDF(w http.ResponseWriter, r *http.Request) {
+	org := tenantFrom(r.Context())
+	inv, ok := loadInvoice(w, r, org.q, false)
+	if !ok {
+		return
+	}
+	lines, err := org.q.ListInvoiceLines(r.Context(), db.ListInvoiceLinesParams{OrganisationID: org.id, InvoiceID: inv.ID})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to list invoice lines")
+		return
+	}
+	contact, err := org.q.GetContact(r.Context(), db.GetContactParams{OrganisationID: org.id, ID: inv.ContactID})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to get contact")
+		return
+	}
+	var taxCodeIDs []pgtype.UUID
+	for _, l := range lines {
+		if l.TaxCodeID.Valid {
+			taxCodeIDs = append(taxCodeIDs, l.TaxCodeID)
+		}
+	}
+	taxCodes, err := taxCodesByID(r, org.q, org, taxCodeIDs)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to fetch tax codes")
+		return
+	}
+	t, err := loadInvoiceTemplate(r, org)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to get invoice template")
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := invoicepdf.Render(&buf, toPDFTemplate(t, org), toPDFInvoice(inv, lines, contact, taxCodes)); err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to render invoice")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", `inline; filename="`+pdfFilename(inv.Number)+`"`)
+	w.Header().Set("Content-Length", strconv.Itoa(buf.Len()))
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(buf.Bytes())
+}
+
+// loadInvoiceTemplate returns the organisation's template, or the defaults
+// when it has not saved one.
+func loadInvoiceTemplate(r *http.Request, org *tenant) (db.InvoiceTemplate, error) {
+	t, err := org.q.GetInvoiceTemplate(r.Context(), org.id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return db.InvoiceTemplate{OrganisationID: org.id, AccentColor: defaultAccentColor}, nil
+	}
+	return t, err
+}
+
+func toPDFTemplate(t db.InvoiceTemplate, org *tenant) invoicepdf.Template {
+	name := t.TradingName
+	if name == "" {
+		name = org.name
+	}
+	return invoicepdf.Template{
+		TradingName:         name,
+		Address:             t.Address,
+		TaxNumber:           t.TaxNumber,
+		Email:               t.Email,
+		AccentColor:         t.AccentColor,
+		PaymentInstructions: t.PaymentInstructions,
+		Footer:              t.Footer,
+		Logo:                t.Logo,
+		LogoType:            t.LogoType.String,
+	}
+}
+
+// toPDFInvoice shows each taxed line under its tax code's current name and
+// rate; the tax amounts are those worked out when the invoice was saved.
+func toPDFInvoice(inv db.Invoice, lines []db.InvoiceLine, contact db.Contact, taxCodes map[pgtype.UUID]db.TaxCode) invoicepdf.Invoice {
+	out := invoicepdf.Invoice{
+		Number:        inv.Number,
+		Reference:     inv.Reference,
+		Currency:      inv.Currency,
+		Status:        inv.Status,
+		IssueOn:       inv.IssueOn.Time,
+		DueOn:         inv.DueOn.Time,
+		Customer:      contact.Name,
+		CustomerEmail: contact.Email.String,
+		Subtotal:      inv.SubtotalMinor,
+		Tax:           inv.TaxMinor,
+		Total:         inv.TotalMinor,
+		Paid:          inv.PaidMinor,
+	}
+	for _, l := range lines {
+		line := invoicepdf.Line{
+			Description:         l.Description,
+			QuantityThousandths: l.QuantityThousandths,
+			UnitPrice:           l.UnitPriceMinor,
+			Amount:              l.AmountMinor,
+			Tax:                 l.TaxMinor,
+		}
+		if code, ok := taxCodes[l.TaxCodeID]; ok {
+			line.TaxCode = code.Name
+			line.TaxRate = int64(code.RateBasisPoints)
+		}
+		out.Lines = append(out.Lines, line)
+	}
+	return out
+}
+
+// pdfFilename makes an invoice number safe for a Content-Disposition header.
+func pdfFilename(number string) string {
+	name := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' || r == '.' {
+			return r
+		}
+		return '_'
+	}, number)
+	return name + ".pdf"
+}
+
+func toInvoiceTemplateResponse(t db.InvoiceTemplate) invoiceTemplateResponse {
+	resp := invoiceTemplateResponse{
+		TradingName:         t.TradingName,
+		Address:             t.Address,
+		TaxNumber:           t.TaxNumber,
+		Email:               t.Email,
+		AccentColor:         t.AccentColor,
+		PaymentInstructions: t.PaymentInstructions,
+		Footer:              t.Footer,
+		LogoType:            t.LogoType.String,
+	}
+	if t.UpdatedAt.Valid {
+		resp.UpdatedAt = t.UpdatedAt.Time.Format(time.RFC3339Nano)
+	}
+	return resp
+}
@@ -0,0 +1,20 @@
+package httpserver
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	db "github.com/LBaronceli/go-figure/internal/db/sqlc"
+)
+
+func TestToPDFTemplateFallsBackToOrganisationName(t *testing.T) {
+	org := &tenant{name: "Household"}
+	require.Equal(t, "Household", toPDFTemplate(db.InvoiceTemplate{}, org).TradingName)
+	require.Equal(t, "Kowhai Ltd", toPDFTemplate(db.InvoiceTemplate{TradingName: "Kowhai Ltd"}, org).TradingName)
+}
+
+func TestPDFFilename(t *testing.T) {
+	require.Equal(t, "INV-0001.pdf", pdfFilename("INV-0001"))
+	require.Equal(t, "A_B__x_.pdf", pdfFilename(`A/B "x"`))
+}
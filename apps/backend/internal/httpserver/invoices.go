@@ -0,0 +1,1086 @@
+package httpserver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/LBaronceli/go-figure/internal/audit"
+	db "github.com/LBaronceli/go-figure/internal/db/sqlc"
+	"github.com/LBaronceli/go-figure/internal/invoicing"
+	"github.com/LBaronceli/go-figure/internal/models"
+)
+
+// sourceInvoice marks transactions posted by approving, paying or voiding an
+// invoice.
+const sourceInvoice = "invoice"
+
+// Invoice states.
+const (
+	invoiceDraft = "draft"
+	invoiceSent  = "sent"
+	invoicePaid  = "paid"
+	invoiceVoid  = "void"
+)
+
+const (
+	maxInvoiceLines        = 100
+	maxInvoiceNumberLength = 100
+	// defaultPaymentTermDays sets due_on when a request leaves it out.
+	defaultPaymentTermDays = 30
+)
+
+var invoiceFilters = []apiParam{
+	{name: "status", typ: "string", enum: []string{invoiceDraft, invoiceSent, invoicePaid, invoiceVoid}, desc: "Only invoices in this state"},
+	{name: "contact_id", typ: "string", format: "uuid", desc: "Only invoices to this contact"},
+}
+
+type invoiceLineRequest struct {
+	Description string   `json:"description" openapi:"optional,maxLength=500"`
+	Quantity    *float64 `json:"quantity,omitempty" doc:"Up to 3 decimal places; defaults to 1"`
+	UnitPrice   int64    `json:"unit_price" doc:"Minor units before tax; negative for a discount"`
+	AccountID   string   `json:"account_id" openapi:"format=uuid" doc:"Income account the line is credited to"`
+	TaxCodeID   string   `json:"tax_code_id" openapi:"optional,format=uuid"`
+}
+
+type invoiceRequest struct {
+	ContactID           string               `json:"contact_id" openapi:"format=uuid" doc:"Customer the invoice is to"`
+	Number              string               `json:"number" openapi:"optional,maxLength=100" doc:"Defaults to the next INV-0001 style number"`
+	Reference           string               `json:"reference" openapi:"optional,maxLength=500" doc:"Customer's reference, such as a purchase order number"`
+	IssueOn             string               `json:"issue_on" openapi:"optional,format=date" doc:"Defaults to today in the organisation's timezone; approval posts on this date"`
+	DueOn               string               `json:"due_on" openapi:"optional,format=date" doc:"Defaults to 30 days after issue_on"`
+	ReceivableAccountID string               `json:"receivable_account_id" openapi:"format=uuid" doc:"Asset account the amount is owed on; sets the invoice currency"`
+	Lines               []invoiceLineRequest `json:"lines" openapi:"minItems=1,maxItems=100"`
+}
+
+type invoicePaymentRequest struct {
+	AccountID string `json:"account_id" openapi:"format=uuid" doc:"Bank or other asset account the money arrived in"`
+	Amount    int64  `json:"amount" doc:"Minor units; at most the amount outstanding"`
+	PaidOn    string `json:"paid_on" openapi:"optional,format=date" doc:"Defaults to today in the organisation's timezone"`
+}
+
+type voidInvoiceRequest struct {
+	VoidedOn string `json:"voided_on" openapi:"optional,format=date" doc:"Date the reversal posts on; defaults to today in the organisation's timezone"`
+}
+
+type invoiceLineResponse struct {
+	ID          string  `json:"id" openapi:"format=uuid"`
+	Description string  `json:"description"`
+	Quantity    float64 `json:"quantity"`
+	UnitPrice   int64   `json:"unit_price"`
+	AccountID   string  `json:"account_id" openapi:"format=uuid"`
+	TaxCodeID   string  `json:"tax_code_id,omitempty" openapi:"format=uuid"`
+	Amount      int64   `json:"amount" doc:"Quantity times unit price, before tax"`
+	Tax         int64   `json:"tax"`
+}
+
+type invoicePaymentResponse struct {
+	ID            string `json:"id" openapi:"format=uuid"`
+	TransactionID string `json:"transaction_id" openapi:"format=uuid"`
+	AccountID     string `json:"account_id" openapi:"format=uuid"`
+	PaidOn        string `json:"paid_on" openapi:"format=date"`
+	Amount        int64  `json:"amount"`
+	CreatedAt     string `json:"created_at" openapi:"format=date-time"`
+}
+
+type invoiceResponse struct {
+	ID                  string                   `json:"id" openapi:"format=uuid"`
+	ContactID           string                   `json:"contact_id" openapi:"format=uuid"`
+	Number              string                   `json:"number"`
+	Reference           string                   `json:"reference"`
+	Currency            string                   `json:"currency"`
+	IssueOn             string                   `json:"issue_on" openapi:"format=date"`
+	DueOn               string                   `json:"due_on" openapi:"format=date"`
+	Status              string                   `json:"status" openapi:"enum=draft|sent|paid|void"`
+	ReceivableAccountID string                   `json:"receivable_account_id" openapi:"format=uuid"`
+	Subtotal            int64                    `json:"subtotal"`
+	Tax                 int64                    `json:"tax"`
+	Total               int64                    `json:"total"`
+	Paid                int64                    `json:"paid"`
+	Outstanding         int64                    `json:"outstanding" doc:"Total less payments; 0 for drafts and void invoices"`
+	TransactionID       string                   `json:"transaction_id,omitempty" openapi:"format=uuid" doc:"Transaction posted on approval"`
+	VoidTransactionID   string                   `json:"void_transaction_id,omitempty" openapi:"format=uuid" doc:"Transaction reversing the approval, for voided sent invoices"`
+	SentAt              string                   `json:"sent_at,omitempty" openapi:"format=date-time"`
+	PaidOn              string                   `json:"paid_on,omitempty" openapi:"format=date"`
+	VoidedOn            string                   `json:"voided_on,omitempty" openapi:"format=date"`
+	Lines               []invoiceLineResponse    `json:"lines,omitempty" doc:"Absent from listings"`
+	Payments            []invoicePaymentResponse `json:"payments,omitempty" doc:"Absent from listings"`
+	CreatedAt           string                   `json:"created_at" openapi:"format=date-time"`
+	UpdatedAt           string                   `json:"updated_at" openapi:"format=date-time"`
+}
+
+type invoicePostingResponse struct {
+	Invoice     invoiceResponse      `json:"invoice"`
+	Transaction *transactionResponse `json:"transaction,omitempty" doc:"Transaction posted by the change; absent when voiding a draft"`
+}
+
+// invoiceDraftParams is a validated invoice ready to save.
+type invoiceDraftParams struct {
+	invoice db.CreateInvoiceParams
+	lines   db.CreateInvoiceLinesParams
+}
+
+// POST /invoices
+func (s *Server) createInvoice(w http.ResponseWriter, r *http.Request) {
+	var req invoiceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, CodeInvalidJSON, "invalid json")
+		return
+	}
+
+	org := tenantFrom(r.Context())
+	tx, qtx, err := org.begin(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to begin transaction")
+		return
+	}
+	defer tx.Rollback(r.Context())
+
+	p, ok := resolveInvoiceRequest(w, r, qtx, org, &req)
+	if !ok {
+		return
+	}
+	if p.invoice.Number == "" {
+		next, err := qtx.NextInvoiceNumber(r.Context(), org.id)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, CodeInternal, "failed to number invoice")
+			return
+		}
+		p.invoice.Number = fmt.Sprintf("INV-%04d", next)
+	}
+
+	inv, err := qtx.CreateInvoice(r.Context(), p.invoice)
+	if err != nil {
+		if isUniqueViolation(err) {
+			writeFieldError(w, http.StatusConflict, CodeInvoiceNumberTaken, "number", "another invoice has this number")
+			return
+		}
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to create invoice")
+		return
+	}
+	p.lines.InvoiceID = inv.ID
+	lines, err := qtx.CreateInvoiceLines(r.Context(), p.lines)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to create invoice lines")
+		return
+	}
+
+	resp := toInvoiceResponse(inv, lines, nil)
+	if err := recordAudit(r.Context(), qtx, org, audit.EntityInvoice, inv.ID, audit.ActionCreate, nil, resp); err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to record audit event")
+		return
+	}
+	if err := tx.Commit(r.Context()); err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to commit transaction")
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, resp)
+}
+
+// GET /invoices
+func (s *Server) listInvoices(w http.ResponseWriter, r *http.Request) {
+	var errs validationErrors
+	var status pgtype.Text
+	switch v := r.URL.Query().Get("status"); v {
+	case "":
+	case invoiceDraft, invoiceSent, invoicePaid, invoiceVoid:
+		status = pgtype.Text{String: v, Valid: true}
+	default:
+		errs.add("status", CodeInvalidValue, "invalid status (must be draft, sent, paid, or void)")
+	}
+	var contactID pgtype.UUID
+	if v := r.URL.Query().Get("contact_id"); v != "" {
+		id, err := parseUUID(v)
+		if err != nil {
+			errs.add("contact_id", CodeInvalidFormat, "invalid contact_id")
+		}
+		contactID = id
+	}
+	if !errs.empty() {
+		writeValidationErrors(w, errs)
+		return
+	}
+
+	org := tenantFrom(r.Context())
+	invoices, err := org.q.ListInvoices(r.Context(), db.ListInvoicesParams{
+		OrganisationID: org.id,
+		Limit:          50,
+		Offset:         0,
+		Status:         status,
+		ContactID:      contactID,
+	})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to list invoices")
+		return
+	}
+
+	resp := make([]invoiceResponse, 0, len(invoices))
+	for _, inv := range invoices {
+		resp = append(resp, toInvoiceResponse(inv, nil, nil))
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// GET /invoices/{id}
+func (s *Server) getInvoice(w http.ResponseWriter, r *http.Request) {
+	org := tenantFrom(r.Context())
+	inv, ok := loadInvoice(w, r, org.q, false)
+	if !ok {
+		return
+	}
+	resp, err := invoiceWithDetail(r.Context(), org.q, org, inv)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to load invoice lines and payments")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// PUT /invoices/{id}
+//
+// Replaces a draft invoice, lines included. Sent invoices are fixed; void
+// them and issue a new one instead.
+func (s *Server) updateInvoice(w http.ResponseWriter, r *http.Request) {
+	var req invoiceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, CodeInvalidJSON, "invalid json")
+		return
+	}
+
+	org := tenantFrom(r.Context())
+	tx, qtx, err := org.begin(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to begin transaction")
+		return
+	}
+	defer tx.Rollback(r.Context())
+
+	current, ok := loadInvoice(w, r, qtx, true)
+	if !ok {
+		return
+	}
+	if current.Status != invoiceDraft {
+		writeError(w, http.StatusConflict, CodeInvalidInvoiceState, "only draft invoices can be edited")
+		return
+	}
+	before, err := invoiceWithDetail(r.Context(), qtx, org, current)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to load invoice lines and payments")
+		return
+	}
+
+	p, ok := resolveInvoiceRequest(w, r, qtx, org, &req)
+	if !ok {
+		return
+	}
+	if p.invoice.Number == "" {
+		p.invoice.Number = current.Number
+	}
+
+	inv, err := qtx.UpdateInvoice(r.Context(), db.UpdateInvoiceParams{
+		OrganisationID:      org.id,
+		ID:                  current.ID,
+		ContactID:           p.invoice.ContactID,
+		Number:              p.invoice.Number,
+		Reference:           p.invoice.Reference,
+		Currency:            p.invoice.Currency,
+		IssueOn:             p.invoice.IssueOn,
+		DueOn:               p.invoice.DueOn,
+		ReceivableAccountID: p.invoice.ReceivableAccountID,
+		SubtotalMinor:       p.invoice.SubtotalMinor,
+		TaxMinor:            p.invoice.TaxMinor,
+		TotalMinor:          p.invoice.TotalMinor,
+	})
+	if err != nil {
+		if isUniqueViolation(err) {
+			writeFieldError(w, http.StatusConflict, CodeInvoiceNumberTaken, "number", "another invoice has this number")
+			return
+		}
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to update invoice")
+		return
+	}
+	if err := qtx.DeleteInvoiceLines(r.Context(), db.DeleteInvoiceLinesParams{OrganisationID: org.id, InvoiceID: inv.ID}); err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to replace invoice lines")
+		return
+	}
+	p.lines.InvoiceID = inv.ID
+	lines, err := qtx.CreateInvoiceLines(r.Context(), p.lines)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to replace invoice lines")
+		return
+	}
+
+	resp := toInvoiceResponse(inv, lines, nil)
+	if err := recordAudit(r.Context(), qtx, org, audit.EntityInvoice, inv.ID, audit.ActionUpdate, before, resp); err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to record audit event")
+		return
+	}
+	if err := tx.Commit(r.Context()); err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to commit transaction")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// DELETE /invoices/{id}
+//
+// Only drafts can be deleted; sent invoices are voided so their number and
+// postings stay on record.
+func (s *Server) deleteInvoice(w http.ResponseWriter, r *http.Request) {
+	org := tenantFrom(r.Context())
+	tx, qtx, err := org.begin(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to begin transaction")
+		return
+	}
+	defer tx.Rollback(r.Context())
+
+	inv, ok := loadInvoice(w, r, qtx, true)
+	if !ok {
+		return
+	}
+	if inv.Status != invoiceDraft {
+		writeError(w, http.StatusConflict, CodeInvalidInvoiceState, "only draft invoices can be deleted; void sent invoices instead")
+		return
+	}
+	before, err := invoiceWithDetail(r.Context(), qtx, org, inv)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to load invoice lines and payments")
+		return
+	}
+
+	if err := qtx.DeleteInvoice(r.Context(), db.DeleteInvoiceParams{OrganisationID: org.id, ID: inv.ID}); err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to delete invoice")
+		return
+	}
+	if err := recordAudit(r.Context(), qtx, org, audit.EntityInvoice, inv.ID, audit.ActionDelete, before, nil); err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to record audit event")
+		return
+	}
+	if err := tx.Commit(r.Context()); err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to commit transaction")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// POST /invoices/{id}/approve
+//
+// Marks a draft as sent and posts it on its issue date: the total is debited
+// to the receivable account, each line's amount credited to its income
+// account and the tax credited to each tax code's account.
+func (s *Server) approveInvoice(w http.ResponseWriter, r *http.Request) {
+	org := tenantFrom(r.Context())
+	tx, qtx, err := org.begin(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to begin transaction")
+		return
+	}
+	defer tx.Rollback(r.Context())
+
+	inv, ok := loadInvoice(w, r, qtx, true)
+	if !ok {
+		return
+	}
+	if inv.Status != invoiceDraft {
+		writeError(w, http.StatusConflict, CodeInvalidInvoiceState, "only draft invoices can be approved")
+		return
+	}
+	lines, err := qtx.ListInvoiceLines(r.Context(), db.ListInvoiceLinesParams{OrganisationID: org.id, InvoiceID: inv.ID})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to list invoice lines")
+		return
+	}
+
+	var taxCodeIDs []pgtype.UUID
+	for _, l := range lines {
+		if l.TaxCodeID.Valid {
+			taxCodeIDs = append(taxCodeIDs, l.TaxCodeID)
+		}
+	}
+	taxCodes, err := taxCodesByID(r, qtx, org, taxCodeIDs)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to fetch tax codes")
+		return
+	}
+
+	postings := []ledgerPosting{{accountID: inv.ReceivableAccountID, amount: inv.TotalMinor}}
+	for i, l := range lines {
+		postings = append(postings, ledgerPosting{accountID: l.AccountID, amount: -l.AmountMinor})
+		if l.TaxMinor == 0 {
+			continue
+		}
+		// the rate was fixed when the line was saved; the account is the
+		// code's current one
+		code, found := taxCodes[l.TaxCodeID]
+		if !found || !code.AccountID.Valid {
+			writeFieldError(w, http.StatusConflict, CodeInvalidInvoiceState, lineField(i, "tax_code_id"), "tax code no longer has an account to credit; save the invoice again")
+			return
+		}
+		postings = append(postings, ledgerPosting{accountID: code.AccountID, amount: -l.TaxMinor})
+	}
+
+	if !checkPostings(w, r, qtx, org, inv.IssueOn, "issue_on", postings) {
+		return
+	}
+	t, err := postInvoiceTransaction(r.Context(), qtx, org, inv, "Invoice "+inv.Number, inv.IssueOn, postings)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to post invoice")
+		return
+	}
+
+	before := toInvoiceResponse(inv, lines, nil)
+	approved, err := qtx.ApproveInvoice(r.Context(), db.ApproveInvoiceParams{OrganisationID: org.id, ID: inv.ID, TransactionID: t.ID})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to approve invoice")
+		return
+	}
+	resp := invoicePostingResponse{Invoice: toInvoiceResponse(approved, lines, nil), Transaction: &t.response}
+	if err := recordAudit(r.Context(), qtx, org, audit.EntityInvoice, inv.ID, audit.ActionApprove, before, resp.Invoice); err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to record audit event")
+		return
+	}
+	if err := tx.Commit(r.Context()); err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to commit transaction")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// POST /invoices/{id}/payments
+//
+// Records money received against a sent invoice: the bank account is
+// debited and the receivable credited. The invoice is paid once payments
+// cover its total.
+func (s *Server) recordInvoicePayment(w http.ResponseWriter, r *http.Request) {
+	var req invoicePaymentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, CodeInvalidJSON, "invalid json")
+		return
+	}
+
+	org := tenantFrom(r.Context())
+	var errs validationErrors
+	accountID, err := parseUUID(req.AccountID)
+	if err != nil {
+		errs.add("account_id", CodeInvalidFormat, "invalid account_id")
+	}
+	if req.Amount <= 0 {
+		errs.add("amount", CodeOutOfRange, "amount must be positive")
+	}
+	paidOn := parseOptionalDate(req.PaidOn, "paid_on", org, &errs)
+	if !errs.empty() {
+		writeValidationErrors(w, errs)
+		return
+	}
+
+	tx, qtx, err := org.begin(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to begin transaction")
+		return
+	}
+	defer tx.Rollback(r.Context())
+
+	inv, ok := loadInvoice(w, r, qtx, true)
+	if !ok {
+		return
+	}
+	if inv.Status != invoiceSent {
+		writeError(w, http.StatusConflict, CodeInvalidInvoiceState, "only sent invoices take payments")
+		return
+	}
+	if outstanding := inv.TotalMinor - inv.PaidMinor; req.Amount > outstanding {
+		writeFieldError(w, http.StatusUnprocessableEntity, CodeInvoiceOverpaid, "amount", fmt.Sprintf("amount exceeds the %d outstanding", outstanding))
+		return
+	}
+
+	acc, err := qtx.GetAccount(r.Context(), db.GetAccountParams{OrganisationID: org.id, ID: accountID})
+	if errors.Is(err, pgx.ErrNoRows) {
+		writeFieldError(w, http.StatusBadRequest, CodeAccountNotFound, "account_id", "account not found")
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to get account")
+		return
+	}
+	if models.AccountType(acc.Type) != models.AccountTypeAsset {
+		writeFieldError(w, http.StatusBadRequest, CodeInvalidValue, "account_id", "payments are received into an asset account")
+		return
+	}
+	if acc.Currency != inv.Currency {
+		writeFieldError(w, http.StatusUnprocessableEntity, CodeCurrencyMismatch, "account_id", "account currency differs from the invoice's")
+		return
+	}
+
+	postings := []ledgerPosting{
+		{accountID: acc.ID, amount: req.Amount},
+		{accountID: inv.ReceivableAccountID, amount: -req.Amount},
+	}
+	if !checkPostings(w, r, qtx, org, paidOn, "paid_on", postings) {
+		return
+	}
+	t, err := postInvoiceTransaction(r.Context(), qtx, org, inv, "Payment for invoice "+inv.Number, paidOn, postings)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to post payment")
+		return
+	}
+
+	if _, err := qtx.CreateInvoicePayment(r.Context(), db.CreateInvoicePaymentParams{
+		OrganisationID: org.id,
+		InvoiceID:      inv.ID,
+		TransactionID:  t.ID,
+		AccountID:      acc.ID,
+		PaidOn:         paidOn,
+		AmountMinor:    req.Amount,
+	}); err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to record payment")
+		return
+	}
+	paid, err := qtx.AddInvoicePayment(r.Context(), db.AddInvoicePaymentParams{OrganisationID: org.id, ID: inv.ID, AmountMinor: req.Amount, PaidOn: paidOn})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to record payment")
+		return
+	}
+
+	updated, err := invoiceWithDetail(r.Context(), qtx, org, paid)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to load invoice lines and payments")
+		return
+	}
+	resp := invoicePostingResponse{Invoice: updated, Transaction: &t.response}
+	if err := recordAudit(r.Context(), qtx, org, audit.EntityInvoice, inv.ID, audit.ActionPay, toInvoiceResponse(inv, nil, nil), resp); err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to record audit event")
+		return
+	}
+	if err := tx.Commit(r.Context()); err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to commit transaction")
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, resp)
+}
+
+// POST /invoices/{id}/void
+//
+// Cancels a draft, or a sent invoice nothing has been paid on. A sent
+// invoice's approval is reversed by a transaction on voided_on, so the
+// original posting stays in the books.
+func (s *Server) voidInvoice(w http.ResponseWriter, r *http.Request) {
+	var req voidInvoiceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, CodeInvalidJSON, "invalid json")
+		return
+	}
+	org := tenantFrom(r.Context())
+	var errs validationErrors
+	voidedOn := parseOptionalDate(req.VoidedOn, "voided_on", org, &errs)
+	if !errs.empty() {
+		writeValidationErrors(w, errs)
+		return
+	}
+
+	tx, qtx, err := org.begin(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to begin transaction")
+		return
+	}
+	defer tx.Rollback(r.Context())
+
+	inv, ok := loadInvoice(w, r, qtx, true)
+	if !ok {
+		return
+	}
+	switch {
+	case inv.Status == invoiceVoid:
+		writeError(w, http.StatusConflict, CodeInvalidInvoiceState, "invoice is already void")
+		return
+	case inv.PaidMinor > 0:
+		writeError(w, http.StatusConflict, CodeInvalidInvoiceState, "invoice has payments and cannot be voided")
+		return
+	}
+
+	var resp invoicePostingResponse
+	var voidTransactionID pgtype.UUID
+	if inv.TransactionID.Valid {
+		if voidedOn.Time.Before(inv.IssueOn.Time) {
+			writeFieldError(w, http.StatusBadRequest, CodeOutOfRange, "voided_on", "voided_on must not be before issue_on")
+			return
+		}
+		entries, err := qtx.ListLedgerEntries(r.Context(), db.ListLedgerEntriesParams{OrganisationID: org.id, TransactionID: inv.TransactionID})
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, CodeInternal, "failed to fetch ledger entries")
+			return
+		}
+		postings := make([]ledgerPosting, 0, len(entries))
+		for _, e := range entries {
+			postings = append(postings, ledgerPosting{accountID: e.AccountID, amount: -e.AmountMinor})
+		}
+		if !checkPostings(w, r, qtx, org, voidedOn, "voided_on", postings) {
+			return
+		}
+		t, err := postInvoiceTransaction(r.Context(), qtx, org, inv, "Void invoice "+inv.Number, voidedOn, postings)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, CodeInternal, "failed to post reversal")
+			return
+		}
+		voidTransactionID = t.ID
+		resp.Transaction = &t.response
+	}
+
+	voided, err := qtx.VoidInvoice(r.Context(), db.VoidInvoiceParams{OrganisationID: org.id, ID: inv.ID, VoidedOn: voidedOn, VoidTransactionID: voidTransactionID})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to void invoice")
+		return
+	}
+	if resp.Invoice, err = invoiceWithDetail(r.Context(), qtx, org, voided); err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to load invoice lines and payments")
+		return
+	}
+	if err := recordAudit(r.Context(), qtx, org, audit.EntityInvoice, inv.ID, audit.ActionVoid, toInvoiceResponse(inv, nil, nil), resp.Invoice); err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to record audit event")
+		return
+	}
+	if err := tx.Commit(r.Context()); err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to commit transaction")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// parseInvoiceRequest validates the shape of req and returns the invoice and
+// its lines as far as they can be worked out without the database.
+func parseInvoiceRequest(req *invoiceRequest, org *tenant, errs *validationErrors) (invoiceDraftParams, []invoicing.Line) {
+	p := invoiceDraftParams{
+		invoice: db.CreateInvoiceParams{
+			OrganisationID: org.id,
+			Number:         strings.TrimSpace(req.Number),
+			Reference:      strings.TrimSpace(req.Reference),
+		},
+		lines: db.CreateInvoiceLinesParams{OrganisationID: org.id},
+	}
+
+	id, err := parseUUID(req.ContactID)
+	if err != nil {
+		errs.add("contact_id", CodeInvalidFormat, "invalid contact_id")
+	}
+	p.invoice.ContactID = id
+	if len(p.invoice.Number) > maxInvoiceNumberLength {
+		errs.add("number", CodeTooLong, "number too long")
+	}
+	if len(p.invoice.Reference) > maxStringLength {
+		errs.add("reference", CodeTooLong, "reference too long")
+	}
+	p.invoice.IssueOn = parseOptionalDate(req.IssueOn, "issue_on", org, errs)
+	if req.DueOn == "" {
+		p.invoice.DueOn = pgtype.Date{Time: p.invoice.IssueOn.Time.AddDate(0, 0, defaultPaymentTermDays), Valid: true}
+	} else if d, err := parseDate(req.DueOn); err != nil {
+		errs.add("due_on", CodeInvalidFormat, "invalid due_on (use YYYY-MM-DD)")
+	} else if p.invoice.DueOn = (pgtype.Date{Time: d, Valid: true}); d.Before(p.invoice.IssueOn.Time) {
+		errs.add("due_on", CodeOutOfRange, "due_on must not be before issue_on")
+	}
+	if id, err = parseUUID(req.ReceivableAccountID); err != nil {
+		errs.add("receivable_account_id", CodeInvalidFormat, "invalid receivable_account_id")
+	}
+	p.invoice.ReceivableAccountID = id
+
+	if len(req.Lines) == 0 {
+		errs.add("lines", CodeTooFew, "invoice must have at least 1 line")
+	}
+	calc, lines := parseLineRequests(req.Lines, errs)
+	p.lines.Positions = lines.positions
+	p.lines.Descriptions = lines.descriptions
+	p.lines.QuantitiesThousandths = lines.quantities
+	p.lines.UnitPricesMinor = lines.unitPrices
+	p.lines.AccountIds = lines.accountIDs
+	p.lines.TaxCodeIds = lines.taxCodeIDs
+	return p, calc
+}
+
+// parsedLines holds invoice or bill lines as the parallel arrays their
+// insert queries take.
+type parsedLines struct {
+	positions    []int32
+	descriptions []string
+	quantities   []int64
+	unitPrices   []int64
+	accountIDs   []pgtype.UUID
+	taxCodeIDs   []pgtype.UUID
+}
+
+// parseLineRequests validates the shape of invoice or bill lines and returns
+// them ready for the tax calculation and for saving.
+func parseLineRequests(reqLines []invoiceLineRequest, errs *validationErrors) ([]invoicing.Line, parsedLines) {
+	var lines parsedLines
+	if len(reqLines) > maxInvoiceLines {
+		errs.add("lines", CodeTooMany, fmt.Sprintf("too many lines (max %d)", maxInvoiceLines))
+	}
+	calc := make([]invoicing.Line, len(reqLines))
+	for i, l := range reqLines {
+		description := strings.TrimSpace(l.Description)
+		if len(description) > maxStringLength {
+			errs.add(lineField(i, "description"), CodeTooLong, "description too long")
+		}
+		quantity := int64(1000)
+		if l.Quantity != nil {
+			q := *l.Quantity * 1000
+			switch {
+			case *l.Quantity <= 0 || q > invoicing.MaxQuantityThousandths:
+				errs.add(lineField(i, "quantity"), CodeOutOfRange, "quantity must be greater than 0 and at most 1000000")
+			case math.Abs(q-math.Round(q)) > 1e-6:
+				errs.add(lineField(i, "quantity"), CodeInvalidValue, "quantity has more than 3 decimal places")
+			}
+			quantity = int64(math.Round(q))
+		}
+		if l.UnitPrice > invoicing.MaxUnitPrice || l.UnitPrice < -invoicing.MaxUnitPrice {
+			errs.add(lineField(i, "unit_price"), CodeOutOfRange, fmt.Sprintf("unit_price must be within ±%d", int64(invoicing.MaxUnitPrice)))
+		}
+		accountID, err := parseUUID(l.AccountID)
+		if err != nil {
+			errs.add(lineField(i, "account_id"), CodeInvalidFormat, "invalid account_id")
+		}
+		var taxCodeID pgtype.UUID
+		if l.TaxCodeID != "" {
+			if taxCodeID, err = parseUUID(l.TaxCodeID); err != nil {
+				errs.add(lineField(i, "tax_code_id"), CodeInvalidFormat, "invalid tax_code_id")
+			}
+		}
+
+		calc[i] = invoicing.Line{QuantityThousandths: quantity, UnitPrice: l.UnitPrice}
+		lines.positions = append(lines.positions, int32(i+1))
+		lines.descriptions = append(lines.descriptions, description)
+		lines.quantities = append(lines.quantities, quantity)
+		lines.unitPrices = append(lines.unitPrices, l.UnitPrice)
+		lines.accountIDs = append(lines.accountIDs, accountID)
+		lines.taxCodeIDs = append(lines.taxCodeIDs, taxCodeID)
+	}
+	return calc, lines
+}
+
+// resolveInvoiceRequest validates req against the organisation's contacts,
+// accounts and tax codes and works out the amounts. It writes the error
+// response itself and reports whether to carry on.
+func resolveInvoiceRequest(w http.ResponseWriter, r *http.Request, q *db.Queries, org *tenant, req *invoiceRequest) (invoiceDraftParams, bool) {
+	var errs validationErrors
+	p, calc := parseInvoiceRequest(req, org, &errs)
+	if !errs.empty() {
+		writeValidationErrors(w, errs)
+		return p, false
+	}
+
+	if _, err := q.GetContact(r.Context(), db.GetContactParams{OrganisationID: org.id, ID: p.invoice.ContactID}); err != nil {
+		if !errors.Is(err, pgx.ErrNoRows) {
+			writeError(w, http.StatusInternalServerError, CodeInternal, "failed to get contact")
+			return p, false
+		}
+		errs.add("contact_id", CodeContactNotFound, "contact not found")
+	}
+
+	accounts, err := q.GetAccountsByIDs(r.Context(), db.GetAccountsByIDsParams{
+		OrganisationID: org.id,
+		Ids:            append([]pgtype.UUID{p.invoice.ReceivableAccountID}, p.lines.AccountIds...),
+	})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to fetch accounts")
+		return p, false
+	}
+	byID := make(map[pgtype.UUID]db.Account, len(accounts))
+	for _, a := range accounts {
+		byID[a.ID] = a
+	}
+	checkAccount := func(field string, id pgtype.UUID, want models.AccountType) (db.Account, bool) {
+		acc, found := byID[id]
+		switch {
+		case !found:
+			errs.add(field, CodeAccountNotFound, "account not found")
+		case acc.ArchivedAt.Valid:
+			errs.add(field, CodeAccountArchived, "account is archived")
+		case models.AccountType(acc.Type) != want:
+			errs.add(field, CodeInvalidValue, fmt.Sprintf("must be an %s account", want))
+		default:
+			return acc, true
+		}
+		return acc, false
+	}
+	receivable, ok := checkAccount("receivable_account_id", p.invoice.ReceivableAccountID, models.AccountTypeAsset)
+	if !ok {
+		writeValidationErrors(w, errs)
+		return p, false
+	}
+	p.invoice.Currency = receivable.Currency
+
+	var taxCodeIDs []pgtype.UUID
+	for _, id := range p.lines.TaxCodeIds {
+		if id.Valid {
+			taxCodeIDs = append(taxCodeIDs, id)
+		}
+	}
+	taxCodes, err := taxCodesByID(r, q, org, taxCodeIDs)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to fetch tax codes")
+		return p, false
+	}
+
+	for i := range calc {
+		if acc, ok := checkAccount(lineField(i, "account_id"), p.lines.AccountIds[i], models.AccountTypeIncome); ok && acc.Currency != p.invoice.Currency {
+			errs.add(lineField(i, "account_id"), CodeCurrencyMismatch, "account currency differs from the receivable account's")
+		}
+		id := p.lines.TaxCodeIds[i]
+		if !id.Valid {
+			continue
+		}
+		code, found := taxCodes[id]
+		if !found {
+			errs.add(lineField(i, "tax_code_id"), CodeTaxCodeNotFound, "tax code not found")
+			continue
+		}
+		calc[i].TaxRate = int64(code.RateBasisPoints)
+	}
+
+	amounts, total := invoicing.Invoice(calc)
+	if errs.empty() && total.Total <= 0 {
+		errs.add("lines", CodeInvalidValue, "invoice total must be positive")
+	}
+	if !errs.empty() {
+		writeValidationErrors(w, errs)
+		return p, false
+	}
+
+	for _, a := range amounts {
+		p.lines.AmountsMinor = append(p.lines.AmountsMinor, a.Net)
+		p.lines.TaxesMinor = append(p.lines.TaxesMinor, a.Tax)
+	}
+	p.invoice.SubtotalMinor = total.Net
+	p.invoice.TaxMinor = total.Tax
+	p.invoice.TotalMinor = total.Total
+	return p, true
+}
+
+// parseOptionalDate parses a YYYY-MM-DD field, defaulting to today in the
+// organisation's timezone.
+func parseOptionalDate(v, field string, org *tenant, errs *validationErrors) pgtype.Date {
+	if v == "" {
+		return pgtype.Date{Time: org.calendar.Date(time.Now()), Valid: true}
+	}
+	d, err := parseDate(v)
+	if err != nil {
+		errs.add(field, CodeInvalidFormat, fmt.Sprintf("invalid %s (use YYYY-MM-DD)", field))
+	}
+	return pgtype.Date{Time: d, Valid: true}
+}
+
+// ledgerPosting is one ledger entry posted for an invoice or bill.
+type ledgerPosting struct {
+	accountID pgtype.UUID
+	amount    int64
+}
+
+// postedTransaction is a transaction posted for an invoice or bill.
+type postedTransaction struct {
+	ID       pgtype.UUID
+	response transactionResponse
+}
+
+// checkPostings checks that postings can go into the books on postedOn:
+// the period is open and no account is archived. It writes the error response
+// itself and reports whether to carry on.
+func checkPostings(w http.ResponseWriter, r *http.Request, q *db.Queries, org *tenant, postedOn pgtype.Date, field string, postings []ledgerPosting) bool {
+	ids := make([]pgtype.UUID, 0, len(postings))
+	for _, p := range postings {
+		ids = append(ids, p.accountID)
+	}
+	accounts, err := q.GetAccountsByIDs(r.Context(), db.GetAccountsByIDsParams{OrganisationID: org.id, Ids: ids})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to fetch accounts")
+		return false
+	}
+	for _, a := range accounts {
+		if a.ArchivedAt.Valid {
+			writeError(w, http.StatusConflict, CodeAccountArchived, fmt.Sprintf("account is archived: %s", a.Name))
+			return false
+		}
+	}
+
+	lock, err := checkPeriodOpen(r.Context(), q, org, postedOn.Time)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to check period locks")
+		return false
+	}
+	if lock != nil {
+		writePeriodLocked(w, field, lock)
+		return false
+	}
+	return true
+}
+
+// postInvoiceTransaction posts postings as one transaction filed under the
+// invoice's contact.
+func postInvoiceTransaction(ctx context.Context, q *db.Queries, org *tenant, inv db.Invoice, description string, postedOn pgtype.Date, postings []ledgerPosting) (postedTransaction, error) {
+	return postLedgerTransaction(ctx, q, org, db.CreateTransactionParams{
+		Description: pgtype.Text{String: description, Valid: true},
+		Source:      sourceInvoice,
+		PostedOn:    postedOn,
+		ContactID:   inv.ContactID,
+	}, inv.Currency, postings)
+}
+
+// postLedgerTransaction posts postings as one transaction with the header in
+// params, through the same CreateTransaction and CreateLedgerEntry queries as
+// POST /transactions, and audits it. Postings to the same account are netted
+// and those that net to zero left out.
+func postLedgerTransaction(ctx context.Context, q *db.Queries, org *tenant, params db.CreateTransactionParams, currency string, postings []ledgerPosting) (postedTransaction, error) {
+	var order []pgtype.UUID
+	net := make(map[pgtype.UUID]int64, len(postings))
+	var sum int64
+	for _, p := range postings {
+		if _, seen := net[p.accountID]; !seen {
+			order = append(order, p.accountID)
+		}
+		net[p.accountID] += p.amount
+		sum += p.amount
+	}
+	if sum != 0 {
+		return postedTransaction{}, fmt.Errorf("%q posting does not balance: %d", params.Description.String, sum)
+	}
+
+	params.OrganisationID = org.id
+	params.PostedAt = pgtype.Timestamptz{Time: time.Now(), Valid: true}
+	t, err := q.CreateTransaction(ctx, params)
+	if err != nil {
+		return postedTransaction{}, err
+	}
+
+	entries := make([]db.LedgerEntry, 0, len(order))
+	for _, id := range order {
+		if net[id] == 0 {
+			continue
+		}
+		le, err := q.CreateLedgerEntry(ctx, db.CreateLedgerEntryParams{
+			OrganisationID: org.id,
+			TransactionID:  t.ID,
+			AccountID:      id,
+			AmountMinor:    net[id],
+			Currency:       currency,
+		})
+		if err != nil {
+			return postedTransaction{}, err
+		}
+		entries = append(entries, le)
+	}
+
+	resp := toFullTransactionResponse(t, entries)
+	if err := recordAudit(ctx, q, org, audit.EntityTransaction, t.ID, audit.ActionCreate, nil, resp); err != nil {
+		return postedTransaction{}, err
+	}
+	return postedTransaction{ID: t.ID, response: resp}, nil
+}
+
+func loadInvoice(w http.ResponseWriter, r *http.Request, q *db.Queries, forUpdate bool) (db.Invoice, bool) {
+	id, err := parseUUID(chi.URLParam(r, "id"))
+	if err != nil {
+		writeFieldError(w, http.StatusBadRequest, CodeInvalidFormat, "id", "invalid id")
+		return db.Invoice{}, false
+	}
+
+	org := tenantFrom(r.Context())
+	var inv db.Invoice
+	if forUpdate {
+		inv, err = q.GetInvoiceForUpdate(r.Context(), db.GetInvoiceForUpdateParams{OrganisationID: org.id, ID: id})
+	} else {
+		inv, err = q.GetInvoice(r.Context(), db.GetInvoiceParams{OrganisationID: org.id, ID: id})
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		writeError(w, http.StatusNotFound, CodeNotFound, "invoice not found")
+		return db.Invoice{}, false
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to get invoice")
+		return db.Invoice{}, false
+	}
+	return inv, true
+}
+
+func invoiceWithDetail(ctx context.Context, q *db.Queries, org *tenant, inv db.Invoice) (invoiceResponse, error) {
+	lines, err := q.ListInvoiceLines(ctx, db.ListInvoiceLinesParams{OrganisationID: org.id, InvoiceID: inv.ID})
+	if err != nil {
+		return invoiceResponse{}, err
+	}
+	payments, err := q.ListInvoicePayments(ctx, db.ListInvoicePaymentsParams{OrganisationID: org.id, InvoiceID: inv.ID})
+	if err != nil {
+		return invoiceResponse{}, err
+	}
+	return toInvoiceResponse(inv, lines, payments), nil
+}
+
+func toInvoiceResponse(inv db.Invoice, lines []db.InvoiceLine, payments []db.InvoicePayment) invoiceResponse {
+	resp := invoiceResponse{
+		ID:                  uuid.UUID(inv.ID.Bytes).String(),
+		ContactID:           uuid.UUID(inv.ContactID.Bytes).String(),
+		Number:              inv.Number,
+		Reference:           inv.Reference,
+		Currency:            inv.Currency,
+		IssueOn:             inv.IssueOn.Time.Format(time.DateOnly),
+		DueOn:               inv.DueOn.Time.Format(time.DateOnly),
+		Status:              inv.Status,
+		ReceivableAccountID: uuid.UUID(inv.ReceivableAccountID.Bytes).String(),
+		Subtotal:            inv.SubtotalMinor,
+		Tax:                 inv.TaxMinor,
+		Total:               inv.TotalMinor,
+		Paid:                inv.PaidMinor,
+		TransactionID:       uuidString(inv.TransactionID),
+		VoidTransactionID:   uuidString(inv.VoidTransactionID),
+		CreatedAt:           inv.CreatedAt.Time.Format(time.RFC3339Nano),
+		UpdatedAt:           inv.UpdatedAt.Time.Format(time.RFC3339Nano),
+	}
+	if inv.Status == invoiceSent {
+		resp.Outstanding = inv.TotalMinor - inv.PaidMinor
+	}
+	if inv.SentAt.Valid {
+		resp.SentAt = inv.SentAt.Time.Format(time.RFC3339Nano)
+	}
+	if inv.PaidOn.Valid {
+		resp.PaidOn = inv.PaidOn.Time.Format(time.DateOnly)
+	}
+	if inv.VoidedOn.Valid {
+		resp.VoidedOn = inv.VoidedOn.Time.Format(time.DateOnly)
+	}
+	for _, l := range lines {
+		resp.Lines = append(resp.Lines, invoiceLineResponse{
+			ID:          uuid.UUID(l.ID.Bytes).String(),
+			Description: l.Description,
+			Quantity:    float64(l.QuantityThousandths) / 1000,
+			UnitPrice:   l.UnitPriceMinor,
+			AccountID:   uuid.UUID(l.AccountID.Bytes).String(),
+			TaxCodeID:   uuidString(l.TaxCodeID),
+			Amount:      l.AmountMinor,
+			Tax:         l.TaxMinor,
+		})
+	}
+	for _, p := range payments {
+		resp.Payments = append(resp.Payments, invoicePaymentResponse{
+			ID:            uuid.UUID(p.ID.Bytes).String(),
+			TransactionID: uuid.UUID(p.TransactionID.Bytes).String(),
+			AccountID:     uuid.UUID(p.AccountID.Bytes).String(),
+			PaidOn:        p.PaidOn.Time.Format(time.DateOnly),
+			Amount:        p.AmountMinor,
+			CreatedAt:     p.CreatedAt.Time.Format(time.RFC3339Nano),
+		})
+	}
+	return resp
+}
@@ -0,0 +1,82 @@
+package httpserver
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/LBaronceli/go-figure/internal/fiscal"
+	"github.com/LBaronceli/go-figure/internal/invoicing"
+)
+
+func testTenant(t *testing.T) *tenant {
+	cal, err := fiscal.NewCalendar("Pacific/Auckland", 4)
+	require.NoError(t, err)
+	return &tenant{calendar: cal}
+}
+
+func TestParseInvoiceRequestDefaults(t *testing.T) {
+	quantity := 1.5
+	req := invoiceRequest{
+		ContactID:           "0b8a1c1e-8a8f-4b8e-9a57-3f1f5a0d2c11",
+		IssueOn:             "2026-05-20",
+		ReceivableAccountID: "5f0c7a52-3c1b-4d7e-8a3a-0d4b0f5c6e21",
+		Lines: []invoiceLineRequest{
+			{Description: " Consulting ", Quantity: &quantity, UnitPrice: 15000, AccountID: "7d2e1f3a-9b8c-4a5d-8e6f-1a2b3c4d5e6f"},
+			{UnitPrice: -2000, AccountID: "7d2e1f3a-9b8c-4a5d-8e6f-1a2b3c4d5e6f"},
+		},
+	}
+	var errs validationErrors
+	p, lines := parseInvoiceRequest(&req, testTenant(t), &errs)
+	require.Empty(t, errs)
+	require.Equal(t, "2026-06-19", p.invoice.DueOn.Time.Format(time.DateOnly))
+	require.Equal(t, []int32{1, 2}, p.lines.Positions)
+	require.Equal(t, []string{"Consulting", ""}, p.lines.Descriptions)
+	require.Equal(t, []invoicing.Line{
+		{QuantityThousandths: 1500, UnitPrice: 15000},
+		{QuantityThousandths: 1000, UnitPrice: -2000},
+	}, lines)
+	require.False(t, p.lines.TaxCodeIds[0].Valid)
+}
+
+func TestParseInvoiceRequestValidation(t *testing.T) {
+	tooPrecise, zero := 1.0005, 0.0
+	req := invoiceRequest{
+		ContactID:           "nope",
+		IssueOn:             "2026-05-20",
+		DueOn:               "2026-05-19",
+		ReceivableAccountID: "5f0c7a52-3c1b-4d7e-8a3a-0d4b0f5c6e21",
+		Lines: []invoiceLineRequest{
+			{Quantity: &tooPrecise, UnitPrice: 100, AccountID: "7d2e1f3a-9b8c-4a5d-8e6f-1a2b3c4d5e6f"},
+			{Quantity: &zero, UnitPrice: invoicing.MaxUnitPrice + 1, AccountID: "7d2e1f3a-9b8c-4a5d-8e6f-1a2b3c4d5e6f", TaxCodeID: "x"},
+		},
+	}
+	var errs validationErrors
+	parseInvoiceRequest(&req, testTenant(t), &errs)
+	fields := make([]string, 0, len(errs))
+	for _, e := range errs {
+		fields = append(fields, e.Field)
+	}
+	require.ElementsMatch(t, []string{
+		"contact_id",
+		"due_on",
+		"lines[0].quantity",
+		"lines[1].quantity",
+		"lines[1].unit_price",
+		"lines[1].tax_code_id",
+	}, fields)
+
+	errs = nil
+	parseInvoiceRequest(&invoiceRequest{}, testTenant(t), &errs)
+	require.Contains(t, errs, fieldError{Field: "lines", Code: CodeTooFew, Message: "invoice must have at least 1 line"})
+}
+
+func TestAgedBalancesAdd(t *testing.T) {
+	var b agedBalances
+	b.add(invoicing.Current, 100)
+	b.add(invoicing.Overdue31To60, 250)
+	b.add(invoicing.OverdueOver90, 50)
+	b.add(invoicing.Overdue31To60, 25)
+	require.Equal(t, agedBalances{Current: 100, Days31To60: 275, Over90: 50, Total: 425}, b)
+}
@@ -0,0 +1,442 @@
+package httpserver
+
+import (
+	"bytes"
+	_ "embed"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/LBaronceli/go-figure/internal/auth"
+	"github.com/LBaronceli/go-figure/internal/openapi"
+)
+
+//go:generate go run ../../cmd/openapigen -spec openapi.json -client ../../client/client.gen.go
+
+// specJSON is the committed spec. TestOpenAPISpecUpToDate fails when it no
+// longer matches what Spec derives from the handler structs.
+//
+//go:embed openapi.json
+var specJSON []byte
+
+// apiOperation describes one route for the spec. Request and response bodies
+// are given as zero values of the handler structs so their schemas are derived
+// rather than written by hand.
+type apiOperation struct {
+	method   string
+	path     string
+	id       string
+	summary  string
+	tag      string
+	query    []apiParam
+	request  any
+	response any
+	status   int
+	// form documents request as a multipart/form-data upload rather than
+	// JSON; its one binary field is the file.
+	form bool
+	text bool
+	// media is the content type of a non-JSON response body, such as
+	// application/pdf.
+	media  string
+	errors []int
+	// replay documents a 200 answered from an earlier request with the same
+	// idempotency key.
+	replay bool
+	// public routes skip authentication. Everything else needs an API token
+	// or session holding scope, which defaults to methodScope(method).
+	public bool
+	scope  auth.Scope
+	// tenant routes operate on one organisation, chosen with
+	// X-Organisation-ID.
+	tenant bool
+}
+
+type apiParam struct {
+	name   string
+	typ    string
+	format string
+	desc   string
+	enum   []string
+}
+
+// transactionScope narrows both listing and search.
+var transactionScope = []apiParam{
+	{name: "account_id", typ: "string", format: "uuid", desc: "Only transactions with an entry on this account"},
+	{name: "start_date", typ: "string", format: "date", desc: "Inclusive lower bound on the accounting date posted_on (YYYY-MM-DD)"},
+	{name: "end_date", typ: "string", format: "date", desc: "Inclusive upper bound on the accounting date posted_on (YYYY-MM-DD)"},
+	{name: "period", typ: "string", desc: "Reporting period in the organisation's calendar, such as FY2026-Q3 or 2026-05; replaces start_date and end_date"},
+	{name: "tag", typ: "string", desc: "Only transactions with an entry carrying this tag"},
+	{name: "tracking_option_id", typ: "string", format: "uuid", desc: "Only transactions with an entry tracked to this option"},
+}
+
+var transactionFilters = append(slices.Clip(transactionScope),
+	apiParam{name: "source", typ: "string", enum: []string{"manual", "csv", "api", sourceClosing, sourceInvoice, sourceBill}, desc: "Only transactions from this source; closing marks year-end closing entries, invoice those posted by invoices and bill those posted by bills"},
+	apiParam{name: "min_amount", typ: "integer", desc: "Inclusive lower bound in minor units on the account_id entry, or on the transaction's total debits without account_id"},
+	apiParam{name: "max_amount", typ: "integer", desc: "Inclusive upper bound in minor units, measured as for min_amount"},
+	apiParam{name: "description_contains", typ: "string", desc: "Case-insensitive substring of the description"},
+	apiParam{name: "counterparty_account_id", typ: "string", format: "uuid", desc: "Only transactions with an entry on this account, other than the account_id side"},
+	apiParam{name: "contact_id", typ: "string", format: "uuid", desc: "Only transactions filed under this contact"},
+	apiParam{name: "has_tag", typ: "boolean", desc: "Only transactions whose entries carry at least one tag (true) or none (false)"},
+	apiParam{name: "sort", typ: "string", enum: transactionSorts, desc: "Sort key; amount is measured as for min_amount. Defaults to posted_on"},
+	apiParam{name: "order", typ: "string", enum: []string{"asc", "desc"}, desc: "Sort direction; defaults to desc"},
+)
+
+// operations lists every route registered in Routes. Keep both in sync;
+// TestOpenAPICoversRoutes fails when they drift.
+var operations = []apiOperation{
+	{method: http.MethodGet, path: "/healthz", id: "healthz", summary: "Liveness probe.", tag: "health", text: true, status: http.StatusOK, public: true},
+	{method: http.MethodGet, path: "/readyz", id: "readyz", summary: "Readiness probe; checks the database and fails once shutdown has begun.", tag: "health", text: true, status: http.StatusOK, errors: []int{503}, public: true},
+	{method: http.MethodGet, path: "/openapi.json", id: "getOpenAPISpec", summary: "This document.", tag: "meta", response: map[string]any{}, status: http.StatusOK, public: true},
+
+	{method: http.MethodPost, path: "/auth/login", id: "login", summary: "Log in with email and password; sets the session cookie.", tag: "auth", request: loginRequest{}, response: sessionResponse{}, status: http.StatusOK, errors: []int{400, 401, 413, 503}, public: true},
+	{method: http.MethodPost, path: "/auth/logout", id: "logout", summary: "End the current session and clear the cookie.", tag: "auth", status: http.StatusNoContent},
+	{method: http.MethodGet, path: "/auth/me", id: "getCurrentPrincipal", summary: "Describe the authenticated caller.", tag: "auth", response: principalResponse{}, status: http.StatusOK},
+	{method: http.MethodPost, path: "/auth/tokens", id: "createAPIToken", summary: "Create a personal API token; the secret is returned only once.", tag: "auth", request: createAPITokenRequest{}, response: createdAPITokenResponse{}, status: http.StatusCreated, errors: []int{400}},
+	{method: http.MethodGet, path: "/auth/tokens", id: "listAPITokens", summary: "List the caller's API tokens.", tag: "auth", response: []apiTokenResponse{}, status: http.StatusOK},
+	{method: http.MethodDelete, path: "/auth/tokens/{id}", id: "revokeAPIToken", summary: "Revoke one of the caller's API tokens.", tag: "auth", status: http.StatusNoContent, errors: []int{400, 404}},
+
+	{method: http.MethodPost, path: "/organisations", id: "createOrganisation", summary: "Create an organisation owned by the caller.", tag: "organisations", request: createOrganisationRequest{}, response: organisationResponse{}, status: http.StatusCreated, errors: []int{400}},
+	{method: http.MethodGet, path: "/organisations", id: "listOrganisations", summary: "List the caller's organisations.", tag: "organisations", response: []organisationResponse{}, status: http.StatusOK},
+	{method: http.MethodPut, path: "/organisations/{id}", id: "updateOrganisation", summary: "Rename an organisation or change its reporting calendar; owners only.", tag: "organisations", request: updateOrganisationRequest{}, response: organisationResponse{}, status: http.StatusOK, errors: []int{400, 404}},
+	{method: http.MethodGet, path: "/organisations/{id}/members", id: "listMembers", summary: "List an organisation's members.", tag: "organisations", response: []memberResponse{}, status: http.StatusOK, errors: []int{400, 404}},
+	{method: http.MethodPost, path: "/organisations/{id}/members", id: "addMember", summary: "Add a user to an organisation; owners only.", tag: "organisations", request: addMemberRequest{}, response: memberResponse{}, status: http.StatusCreated, errors: []int{400, 404, 409}},
+	{method: http.MethodPut, path: "/organisations/{id}/members/{user_id}", id: "updateMember", summary: "Change a member's role; owners only.", tag: "organisations", request: updateMemberRequest{}, response: memberResponse{}, status: http.StatusOK, errors: []int{400, 404, 409}},
+	{method: http.MethodDelete, path: "/organisations/{id}/members/{user_id}", id: "removeMember", summary: "Remove a member, or leave an organisation.", tag: "organisations", status: http.StatusNoContent, errors: []int{400, 404, 409}},
+
+	{method: http.MethodPost, path: "/users", id: "createUser", summary: "Create a user.", tag: "users", request: createUserRequest{}, response: userResponse{}, status: http.StatusCreated, errors: []int{400, 409, 503}, scope: auth.ScopeAdmin},
+	{method: http.MethodGet, path: "/users", id: "listUsers", summary: "List users.", tag: "users", response: []userResponse{}, status: http.StatusOK, scope: auth.ScopeAdmin},
+
+	{method: http.MethodPost, path: "/accounts", id: "createAccount", summary: "Create an account.", tag: "accounts", request: createAccountRequest{}, response: accountResponse{}, status: http.StatusCreated, errors: []int{400}, tenant: true},
+	{method: http.MethodGet, path: "/accounts", id: "listAccounts", summary: "List accounts.", tag: "accounts",
+		query:    []apiParam{{name: "include_archived", typ: "boolean", desc: "Include archived accounts"}},
+		response: []accountResponse{}, status: http.StatusOK, tenant: true},
+	{method: http.MethodGet, path: "/accounts/{id}", id: "getAccount", summary: "Get an account.", tag: "accounts", response: accountResponse{}, status: http.StatusOK, errors: []int{400, 404}, tenant: true},
+	{method: http.MethodPut, path: "/accounts/{id}", id: "updateAccount", summary: "Rename or retype an account.", tag: "accounts", request: updateAccountRequest{}, response: accountResponse{}, status: http.StatusOK, errors: []int{400, 404, 409}, tenant: true},
+	{method: http.MethodDelete, path: "/accounts/{id}", id: "deleteAccount", summary: "Delete an account that has no ledger entries.", tag: "accounts", status: http.StatusNoContent, errors: []int{400, 404, 409}, tenant: true},
+	{method: http.MethodPost, path: "/accounts/{id}/archive", id: "archiveAccount", summary: "Archive an account.", tag: "accounts", response: accountResponse{}, status: http.StatusOK, errors: []int{400, 404}, tenant: true},
+	{method: http.MethodPost, path: "/accounts/{id}/unarchive", id: "unarchiveAccount", summary: "Unarchive an account.", tag: "accounts", response: accountResponse{}, status: http.StatusOK, errors: []int{400, 404}, tenant: true},
+
+	{method: http.MethodPost, path: "/transactions", id: "createTransaction", summary: "Post a balanced transaction.", tag: "transactions", request: createTransactionRequest{}, response: transactionResponse{}, status: http.StatusCreated, replay: true, errors: []int{400, 409, 422}, tenant: true},
+	{method: http.MethodPost, path: "/transactions/batch", id: "createTransactionsBatch", summary: "Post up to 10000 transactions, all or nothing or reporting each one.", tag: "transactions", request: createTransactionsBatchRequest{}, response: createTransactionsBatchResponse{}, status: http.StatusOK, errors: []int{400, 409}, tenant: true},
+	{method: http.MethodGet, path: "/transactions", id: "listTransactions", summary: "List transactions.", tag: "transactions", query: transactionFilters, response: []transactionResponse{}, status: http.StatusOK, errors: []int{400}, tenant: true},
+	{method: http.MethodGet, path: "/transactions/search", id: "searchTransactions", summary: "Search transaction descriptions, ranked, with fuzzy matching and amount and tag terms.", tag: "transactions", query: searchFilters, response: []transactionSearchResult{}, status: http.StatusOK, errors: []int{400}, tenant: true},
+	{method: http.MethodGet, path: "/transactions/{id}", id: "getTransaction", summary: "Get a transaction with its entries.", tag: "transactions", response: transactionResponse{}, status: http.StatusOK, errors: []int{400, 404}, tenant: true},
+	{method: http.MethodPost, path: "/transactions/{id}/split", id: "splitTransaction", summary: "Reallocate one entry across accounts by amounts or percentages with a correcting transaction.", tag: "transactions", request: splitTransactionRequest{}, response: transactionResponse{}, status: http.StatusCreated, replay: true, errors: []int{400, 404, 409, 422}, tenant: true},
+	{method: http.MethodPost, path: "/transactions/{id}/attachments", id: "createAttachment", summary: "Attach a receipt or document (JPEG, PNG, WebP or PDF) to a transaction; re-uploading a file it already has returns that attachment.", tag: "transactions", request: attachmentUploadRequest{}, form: true, response: attachmentResponse{}, status: http.StatusCreated, replay: true, errors: []int{400, 404, 503}, tenant: true},
+	{method: http.MethodGet, path: "/transactions/{id}/attachments/{attachment_id}", id: "getAttachmentContent", summary: "Download an attachment's content.", tag: "transactions", media: "application/octet-stream", status: http.StatusOK, errors: []int{400, 404, 503}, tenant: true},
+	{method: http.MethodDelete, path: "/transactions/{id}/attachments/{attachment_id}", id: "deleteAttachment", summary: "Remove an attachment, deleting its content once no transaction refers to it.", tag: "transactions", status: http.StatusNoContent, errors: []int{400, 404, 503}, tenant: true},
+	{method: http.MethodPut, path: "/transactions/{id}/contact", id: "setTransactionContact", summary: "Set or clear the contact of a transaction.", tag: "transactions", request: setTransactionContactRequest{}, response: transactionResponse{}, status: http.StatusOK, errors: []int{400, 404}, tenant: true},
+
+	{method: http.MethodPost, path: "/period-locks", id: "createPeriodLock", summary: "Lock a period against postings; owners only.", tag: "periods", request: createPeriodLockRequest{}, response: periodLockResponse{}, status: http.StatusCreated, errors: []int{400, 409}, tenant: true},
+	{method: http.MethodGet, path: "/period-locks", id: "listPeriodLocks", summary: "List locked periods.", tag: "periods", response: []periodLockResponse{}, status: http.StatusOK, tenant: true},
+	{method: http.MethodPost, path: "/period-locks/close-year", id: "closeYear", summary: "Post closing entries into retained earnings and lock the year; owners only.", tag: "periods", request: closeYearRequest{}, response: closeYearResponse{}, status: http.StatusCreated, errors: []int{400, 409, 422}, tenant: true},
+	{method: http.MethodDelete, path: "/period-locks/{id}", id: "deletePeriodLock", summary: "Unlock a period; audited.", tag: "periods", status: http.StatusNoContent, errors: []int{400, 404}, scope: auth.ScopeAdmin, tenant: true},
+
+	{method: http.MethodPost, path: "/reconciliations", id: "createReconciliation", summary: "Start reconciling an account against a bank statement.", tag: "reconciliation", request: createReconciliationRequest{}, response: reconciliationResponse{}, status: http.StatusCreated, errors: []int{400}, tenant: true},
+	{method: http.MethodGet, path: "/reconciliations", id: "listReconciliations", summary: "List reconciliations.", tag: "reconciliation", query: reconciliationFilters, response: []reconciliationResponse{}, status: http.StatusOK, errors: []int{400}, tenant: true},
+	{method: http.MethodGet, path: "/reconciliations/{id}", id: "getReconciliation", summary: "Get a reconciliation.", tag: "reconciliation", response: reconciliationResponse{}, status: http.StatusOK, errors: []int{400, 404}, tenant: true},
+	{method: http.MethodPost, path: "/reconciliations/{id}/lines", id: "importStatementLines", summary: "Import statement lines.", tag: "reconciliation", request: importStatementLinesRequest{}, response: []statementLineResponse{}, status: http.StatusCreated, errors: []int{400, 404, 409}, tenant: true},
+	{method: http.MethodGet, path: "/reconciliations/{id}/lines", id: "listStatementLines", summary: "List statement lines with their match state.", tag: "reconciliation", response: []statementLineResponse{}, status: http.StatusOK, errors: []int{400, 404}, tenant: true},
+	{method: http.MethodPost, path: "/reconciliations/{id}/auto-match", id: "autoMatchStatementLines", summary: "Suggest ledger entries for unmatched lines by amount, date and description.", tag: "reconciliation", response: []statementLineResponse{}, status: http.StatusOK, errors: []int{400, 404, 409}, tenant: true},
+	{method: http.MethodPost, path: "/reconciliations/{id}/lines/{line_id}/accept", id: "acceptStatementLineMatch", summary: "Accept a suggested match.", tag: "reconciliation", response: statementLineResponse{}, status: http.StatusOK, errors: []int{400, 404, 409}, tenant: true},
+	{method: http.MethodPost, path: "/reconciliations/{id}/lines/{line_id}/reject", id: "rejectStatementLineMatch", summary: "Reject a match so it is not suggested again.", tag: "reconciliation", response: statementLineResponse{}, status: http.StatusOK, errors: []int{400, 404, 409}, tenant: true},
+	{method: http.MethodPost, path: "/reconciliations/{id}/lines/{line_id}/match", id: "matchStatementLine", summary: "Match a line to a ledger entry by hand.", tag: "reconciliation", request: matchStatementLineRequest{}, response: statementLineResponse{}, status: http.StatusOK, errors: []int{400, 404, 409, 422}, tenant: true},
+	{method: http.MethodGet, path: "/reconciliations/{id}/report", id: "getReconciliationReport", summary: "Compare the statement closing balance with the cleared ledger balance.", tag: "reconciliation", response: reconciliationReportResponse{}, status: http.StatusOK, errors: []int{400, 404}, tenant: true},
+	{method: http.MethodPost, path: "/reconciliations/{id}/complete", id: "completeReconciliation", summary: "Complete a balanced reconciliation.", tag: "reconciliation", response: reconciliationResponse{}, status: http.StatusOK, errors: []int{400, 404, 409}, tenant: true},
+
+	{method: http.MethodPost, path: "/tracking-categories", id: "createTrackingCategory", summary: "Create a tracking category such as a department or project.", tag: "tracking", request: trackingCategoryRequest{}, response: trackingCategoryResponse{}, status: http.StatusCreated, errors: []int{400, 409}, tenant: true},
+	{method: http.MethodGet, path: "/tracking-categories", id: "listTrackingCategories", summary: "List tracking categories with their options.", tag: "tracking", response: []trackingCategoryResponse{}, status: http.StatusOK, tenant: true},
+	{method: http.MethodGet, path: "/tracking-categories/{id}", id: "getTrackingCategory", summary: "Get a tracking category with its options.", tag: "tracking", response: trackingCategoryResponse{}, status: http.StatusOK, errors: []int{400, 404}, tenant: true},
+	{method: http.MethodPut, path: "/tracking-categories/{id}", id: "updateTrackingCategory", summary: "Rename a tracking category.", tag: "tracking", request: trackingCategoryRequest{}, response: trackingCategoryResponse{}, status: http.StatusOK, errors: []int{400, 404, 409}, tenant: true},
+	{method: http.MethodDelete, path: "/tracking-categories/{id}", id: "deleteTrackingCategory", summary: "Delete a tracking category no ledger entry uses.", tag: "tracking", status: http.StatusNoContent, errors: []int{400, 404, 409}, tenant: true},
+	{method: http.MethodPost, path: "/tracking-categories/{id}/options", id: "createTrackingOption", summary: "Add an option to a tracking category.", tag: "tracking", request: createTrackingOptionRequest{}, response: trackingOptionResponse{}, status: http.StatusCreated, errors: []int{400, 404, 409}, tenant: true},
+	{method: http.MethodPut, path: "/tracking-categories/{id}/options/{option_id}", id: "updateTrackingOption", summary: "Rename, deactivate or reactivate a tracking option.", tag: "tracking", request: updateTrackingOptionRequest{}, response: trackingOptionResponse{}, status: http.StatusOK, errors: []int{400, 404, 409}, tenant: true},
+	{method: http.MethodDelete, path: "/tracking-categories/{id}/options/{option_id}", id: "deleteTrackingOption", summary: "Delete a tracking option no ledger entry uses.", tag: "tracking", status: http.StatusNoContent, errors: []int{400, 404, 409}, tenant: true},
+
+	{method: http.MethodPost, path: "/contacts", id: "createContact", summary: "Create a payee, customer or supplier.", tag: "contacts", request: contactRequest{}, response: contactResponse{}, status: http.StatusCreated, errors: []int{400, 409}, tenant: true},
+	{method: http.MethodGet, path: "/contacts", id: "listContacts", summary: "List contacts with their aliases.", tag: "contacts", response: []contactResponse{}, status: http.StatusOK, tenant: true},
+	{method: http.MethodGet, path: "/contacts/match", id: "matchContact", summary: "Find the contact whose alias matches a raw description.", tag: "contacts", query: []apiParam{{name: "description", typ: "string", desc: "Description as it appears on a statement; required"}}, response: contactMatchResponse{}, status: http.StatusOK, errors: []int{400, 404}, tenant: true},
+	{method: http.MethodGet, path: "/contacts/{id}", id: "getContact", summary: "Get a contact with its aliases.", tag: "contacts", response: contactResponse{}, status: http.StatusOK, errors: []int{400, 404}, tenant: true},
+	{method: http.MethodPut, path: "/contacts/{id}", id: "updateContact", summary: "Replace a contact's name, email and default account.", tag: "contacts", request: contactRequest{}, response: contactResponse{}, status: http.StatusOK, errors: []int{400, 404, 409}, tenant: true},
+	{method: http.MethodDelete, path: "/contacts/{id}", id: "deleteContact", summary: "Delete a contact no transaction, invoice, bill or receipt names.", tag: "contacts", status: http.StatusNoContent, errors: []int{400, 404, 409}, tenant: true},
+	{method: http.MethodPost, path: "/contacts/{id}/merge", id: "mergeContact", summary: "Merge a duplicate contact into this one, moving everything that names it.", tag: "contacts", request: mergeContactRequest{}, response: mergeContactResponse{}, status: http.StatusOK, errors: []int{400, 404, 409}, tenant: true},
+	{method: http.MethodPost, path: "/contacts/{id}/aliases", id: "createContactAlias", summary: "Add a pattern that maps descriptions to the contact.", tag: "contacts", request: contactAliasRequest{}, response: contactAliasResponse{}, status: http.StatusCreated, errors: []int{400, 404, 409}, tenant: true},
+	{method: http.MethodDelete, path: "/contacts/{id}/aliases/{alias_id}", id: "deleteContactAlias", summary: "Remove a contact alias.", tag: "contacts", status: http.StatusNoContent, errors: []int{400, 404}, tenant: true},
+	{method: http.MethodPost, path: "/tax-codes", id: "createTaxCode", summary: "Create a sales tax rate and the liability account it is owed on.", tag: "invoices", request: taxCodeRequest{}, response: taxCodeResponse{}, status: http.StatusCreated, errors: []int{400, 409}, tenant: true},
+	{method: http.MethodGet, path: "/tax-codes", id: "listTaxCodes", summary: "List tax codes.", tag: "invoices", response: []taxCodeResponse{}, status: http.StatusOK, tenant: true},
+	{method: http.MethodGet, path: "/tax-codes/{id}", id: "getTaxCode", summary: "Get a tax code.", tag: "invoices", response: taxCodeResponse{}, status: http.StatusOK, errors: []int{400, 404}, tenant: true},
+	{method: http.MethodPut, path: "/tax-codes/{id}", id: "updateTaxCode", summary: "Replace a tax code's name, rate and account.", tag: "invoices", request: taxCodeRequest{}, response: taxCodeResponse{}, status: http.StatusOK, errors: []int{400, 404, 409}, tenant: true},
+	{method: http.MethodDelete, path: "/tax-codes/{id}", id: "deleteTaxCode", summary: "Delete a tax code no invoice or bill line uses.", tag: "invoices", status: http.StatusNoContent, errors: []int{400, 404, 409}, tenant: true},
+	{method: http.MethodGet, path: "/invoice-template", id: "getInvoiceTemplate", summary: "Get the branding and text invoice PDFs are rendered with.", tag: "invoices", response: invoiceTemplateResponse{}, status: http.StatusOK, tenant: true},
+	{method: http.MethodPut, path: "/invoice-template", id: "updateInvoiceTemplate", summary: "Replace the invoice template apart from the logo.", tag: "invoices", request: invoiceTemplateRequest{}, response: invoiceTemplateResponse{}, status: http.StatusOK, errors: []int{400}, tenant: true},
+	{method: http.MethodPut, path: "/invoice-template/logo", id: "updateInvoiceLogo", summary: "Upload the PNG or JPEG logo shown on invoice PDFs.", tag: "invoices", request: invoiceLogoRequest{}, response: invoiceTemplateResponse{}, status: http.StatusOK, errors: []int{400}, tenant: true},
+	{method: http.MethodDelete, path: "/invoice-template/logo", id: "deleteInvoiceLogo", summary: "Remove the invoice logo.", tag: "invoices", status: http.StatusNoContent, tenant: true},
+	{method: http.MethodPost, path: "/invoices", id: "createInvoice", summary: "Create a draft sales invoice.", tag: "invoices", request: invoiceRequest{}, response: invoiceResponse{}, status: http.StatusCreated, errors: []int{400, 409, 422}, tenant: true},
+	{method: http.MethodGet, path: "/invoices", id: "listInvoices", summary: "List invoices, newest first, without their lines.", tag: "invoices", query: invoiceFilters, response: []invoiceResponse{}, status: http.StatusOK, errors: []int{400}, tenant: true},
+	{method: http.MethodGet, path: "/invoices/{id}.pdf", id: "getInvoicePDF", summary: "Render an invoice as a PDF using the invoice template.", tag: "invoices", media: "application/pdf", status: http.StatusOK, errors: []int{400, 404}, tenant: true},
+	{method: http.MethodGet, path: "/invoices/{id}", id: "getInvoice", summary: "Get an invoice with its lines and payments.", tag: "invoices", response: invoiceResponse{}, status: http.StatusOK, errors: []int{400, 404}, tenant: true},
+	{method: http.MethodPut, path: "/invoices/{id}", id: "updateInvoice", summary: "Replace a draft invoice, lines included.", tag: "invoices", request: invoiceRequest{}, response: invoiceResponse{}, status: http.StatusOK, errors: []int{400, 404, 409, 422}, tenant: true},
+	{method: http.MethodDelete, path: "/invoices/{id}", id: "deleteInvoice", summary: "Delete a draft invoice.", tag: "invoices", status: http.StatusNoContent, errors: []int{400, 404, 409}, tenant: true},
+	{method: http.MethodPost, path: "/invoices/{id}/approve", id: "approveInvoice", summary: "Mark a draft as sent and post it to receivables, income and tax.", tag: "invoices", response: invoicePostingResponse{}, status: http.StatusOK, errors: []int{400, 404, 409}, tenant: true},
+	{method: http.MethodPost, path: "/invoices/{id}/payments", id: "recordInvoicePayment", summary: "Record money received against a sent invoice, clearing the receivable.", tag: "invoices", request: invoicePaymentRequest{}, response: invoicePostingResponse{}, status: http.StatusCreated, errors: []int{400, 404, 409, 422}, tenant: true},
+	{method: http.MethodPost, path: "/invoices/{id}/void", id: "voidInvoice", summary: "Void an unpaid invoice, reversing its posting if it was sent.", tag: "invoices", request: voidInvoiceRequest{}, response: invoicePostingResponse{}, status: http.StatusOK, errors: []int{400, 404, 409}, tenant: true},
+	{method: http.MethodPost, path: "/bills", id: "createBill", summary: "Create a draft bill from a supplier.", tag: "bills", request: billRequest{}, response: billResponse{}, status: http.StatusCreated, errors: []int{400, 409, 422}, tenant: true},
+	{method: http.MethodGet, path: "/bills", id: "listBills", summary: "List bills, newest first, without their lines.", tag: "bills", query: billFilters, response: []billResponse{}, status: http.StatusOK, errors: []int{400}, tenant: true},
+	{method: http.MethodGet, path: "/bills/{id}", id: "getBill", summary: "Get a bill with its lines and payments.", tag: "bills", response: billResponse{}, status: http.StatusOK, errors: []int{400, 404}, tenant: true},
+	{method: http.MethodPut, path: "/bills/{id}", id: "updateBill", summary: "Replace a draft bill, lines included.", tag: "bills", request: billRequest{}, response: billResponse{}, status: http.StatusOK, errors: []int{400, 404, 409, 422}, tenant: true},
+	{method: http.MethodDelete, path: "/bills/{id}", id: "deleteBill", summary: "Delete a draft bill.", tag: "bills", status: http.StatusNoContent, errors: []int{400, 404, 409}, tenant: true},
+	{method: http.MethodPost, path: "/bills/{id}/approve", id: "approveBill", summary: "Approve a draft and post it to expenses, tax and payables.", tag: "bills", response: billPostingResponse{}, status: http.StatusOK, errors: []int{400, 404, 409}, tenant: true},
+	{method: http.MethodPost, path: "/bills/{id}/payments", id: "recordBillPayment", summary: "Pay some or all of an approved bill, posting a transaction linked to it.", tag: "bills", request: billPaymentRequest{}, response: transactionResponse{}, status: http.StatusCreated, errors: []int{400, 404, 409, 422}, tenant: true},
+	{method: http.MethodPost, path: "/bills/{id}/void", id: "voidBill", summary: "Void an unpaid bill, reversing its posting if it was approved.", tag: "bills", request: voidBillRequest{}, response: billPostingResponse{}, status: http.StatusOK, errors: []int{400, 404, 409}, tenant: true},
+	{method: http.MethodPost, path: "/receipts", id: "createReceipt", summary: "Upload a photographed receipt (JPEG, PNG, WebP or PDF) to the inbox and queue it for scanning; re-uploading a file already there returns that receipt.", tag: "receipts", request: attachmentUploadRequest{}, form: true, response: receiptResponse{}, status: http.StatusCreated, replay: true, errors: []int{400, 503}, tenant: true},
+	{method: http.MethodGet, path: "/receipts", id: "listReceipts", summary: "List receipts with what their scans read, newest first.", tag: "receipts", query: receiptFilters, response: []receiptResponse{}, status: http.StatusOK, errors: []int{400}, tenant: true},
+	{method: http.MethodGet, path: "/receipts/{id}", id: "getReceipt", summary: "Get a receipt with the fields its scan read and their confidence.", tag: "receipts", response: receiptResponse{}, status: http.StatusOK, errors: []int{400, 404}, tenant: true},
+	{method: http.MethodDelete, path: "/receipts/{id}", id: "deleteReceipt", summary: "Discard a receipt still in the inbox.", tag: "receipts", status: http.StatusNoContent, errors: []int{400, 404, 409, 503}, tenant: true},
+	{method: http.MethodGet, path: "/receipts/{id}/content", id: "getReceiptContent", summary: "Download a receipt's file.", tag: "receipts", media: "application/octet-stream", status: http.StatusOK, errors: []int{400, 404, 503}, tenant: true},
+	{method: http.MethodPost, path: "/receipts/{id}/rescan", id: "rescanReceipt", summary: "Queue a receipt to be scanned again from scratch.", tag: "receipts", response: receiptResponse{}, status: http.StatusOK, errors: []int{400, 404, 409}, tenant: true},
+	{method: http.MethodPost, path: "/receipts/{id}/post", id: "postReceipt", summary: "Post a draft receipt as a transaction paid from a bank or card account, filing the receipt on it.", tag: "receipts", request: postReceiptRequest{}, response: transactionResponse{}, status: http.StatusCreated, replay: true, errors: []int{400, 404, 409, 422}, tenant: true},
+	{method: http.MethodGet, path: "/reports/account-totals", id: "getAccountTotalsReport", summary: "Net movement per account, optionally grouped by tag, tracking option or contact.", tag: "reports", query: reportFilters, response: accountTotalsResponse{}, status: http.StatusOK, errors: []int{400}, tenant: true},
+	{method: http.MethodGet, path: "/reports/aged-receivables", id: "getAgedReceivablesReport", summary: "Amounts owed on approved invoices by contact and days past due.", tag: "reports", query: []apiParam{{name: "as_of", typ: "string", format: "date", desc: "Day to age balances on; defaults to today in the organisation's timezone"}}, response: agedReceivablesResponse{}, status: http.StatusOK, errors: []int{400}, tenant: true},
+	{method: http.MethodGet, path: "/reports/aged-payables", id: "getAgedPayablesReport", summary: "Amounts owed on approved bills by supplier and days past due.", tag: "reports", query: []apiParam{{name: "as_of", typ: "string", format: "date", desc: "Day to age balances on; defaults to today in the organisation's timezone"}}, response: agedPayablesResponse{}, status: http.StatusOK, errors: []int{400}, tenant: true},
+	{method: http.MethodGet, path: "/reports/cash-flow", id: "getCashFlowReport", summary: "Receipts and payments expected from outstanding invoices and bills, by week.", tag: "reports", query: cashFlowFilters, response: cashFlowResponse{}, status: http.StatusOK, errors: []int{400}, tenant: true},
+
+	{method: http.MethodGet, path: "/audit", id: "listAuditEvents", summary: "List audit events, newest first.", tag: "audit", query: auditFilters, response: []auditEventResponse{}, status: http.StatusOK, errors: []int{400}, tenant: true},
+	{method: http.MethodGet, path: "/audit/verify", id: "verifyAuditChain", summary: "Recompute the audit hash chain and report the first break.", tag: "audit", response: auditVerificationResponse{}, status: http.StatusOK, tenant: true},
+}
+
+const (
+	bearerScheme = "apiToken"
+	cookieScheme = "session"
+)
+
+// Spec builds the OpenAPI document from the operations table.
+func Spec() *openapi.Document {
+	reg := openapi.NewRegistry()
+	errSchema := reg.SchemaFor(errorResponse{})
+
+	doc := &openapi.Document{
+		OpenAPI: openapi.Version,
+		Info: openapi.Info{
+			Title:       "Go Figure API",
+			Version:     "0.1.0",
+			Description: "Double-entry ledger API. Errors use the ErrorResponse envelope with a stable code.",
+		},
+		Paths: make(map[string]openapi.PathItem),
+	}
+
+	for _, op := range operations {
+		o := &openapi.Operation{
+			OperationID: op.id,
+			Summary:     op.summary,
+			Responses:   make(map[string]*openapi.Response),
+		}
+		if op.tag != "" {
+			o.Tags = []string{op.tag}
+		}
+		if !op.public {
+			scope := op.scope
+			if scope == "" {
+				scope = methodScope(op.method)
+			}
+			o.Description = "Requires the " + string(scope) + " scope."
+			o.Security = []openapi.SecurityRequirement{
+				{bearerScheme: {string(scope)}},
+				{cookieScheme: {string(scope)}},
+			}
+			op.errors = slices.Concat(op.errors, []int{http.StatusUnauthorized, http.StatusForbidden})
+		}
+
+		for _, seg := range strings.Split(op.path, "/") {
+			if strings.HasPrefix(seg, "{") {
+				name := strings.TrimSuffix(strings.TrimPrefix(seg, "{"), "}")
+				if i := strings.Index(name, "}"); i >= 0 {
+					name = name[:i]
+				}
+				o.Parameters = append(o.Parameters, openapi.Parameter{
+					Name:     name,
+					In:       "path",
+					Required: true,
+					Schema:   &openapi.Schema{Type: "string", Format: "uuid"},
+				})
+			}
+		}
+		// /auth routes return credentials, so they are not idempotent; see
+		// routes
+		if isMutating(op.method) && !op.public && !strings.HasPrefix(op.path, "/auth/") {
+			maxKeyLength := maxIdempotencyKeyLength
+			o.Parameters = append(o.Parameters, openapi.Parameter{
+				Name:        idempotencyKeyHeader,
+				In:          "header",
+				Description: "Makes the request safe to retry: the first response is stored and replayed for the same key",
+				Schema:      &openapi.Schema{Type: "string", MaxLength: &maxKeyLength},
+			})
+			// a key still in flight, and a body too large to record
+			for _, code := range []int{http.StatusConflict, http.StatusRequestEntityTooLarge} {
+				if !slices.Contains(op.errors, code) {
+					op.errors = append(op.errors, code)
+				}
+			}
+		}
+		if op.tenant {
+			o.Parameters = append(o.Parameters, openapi.Parameter{
+				Name:        organisationHeader,
+				In:          "header",
+				Description: "Organisation to operate on; optional for users who belong to exactly one",
+				Schema:      &openapi.Schema{Type: "string", Format: "uuid"},
+			})
+			if !slices.Contains(op.errors, http.StatusNotFound) {
+				op.errors = slices.Concat(op.errors, []int{http.StatusNotFound})
+			}
+		}
+		for _, q := range op.query {
+			o.Parameters = append(o.Parameters, openapi.Parameter{
+				Name:        q.name,
+				In:          "query",
+				Description: q.desc,
+				Schema:      &openapi.Schema{Type: q.typ, Format: q.format, Enum: q.enum},
+			})
+		}
+
+		if op.request != nil {
+			media := "application/json"
+			if op.form {
+				media = "multipart/form-data"
+			}
+			o.RequestBody = &openapi.RequestBody{
+				Required: true,
+				Content:  map[string]openapi.MediaType{media: {Schema: reg.SchemaFor(op.request)}},
+			}
+		}
+
+		resp := &openapi.Response{Description: http.StatusText(op.status)}
+		switch {
+		case op.text:
+			resp.Content = map[string]openapi.MediaType{"text/plain": {Schema: &openapi.Schema{Type: "string"}}}
+		case op.media != "":
+			resp.Content = map[string]openapi.MediaType{op.media: {Schema: &openapi.Schema{Type: "string", Format: "binary"}}}
+		case op.response != nil:
+			resp.Content = map[string]openapi.MediaType{"application/json": {Schema: reg.SchemaFor(op.response)}}
+		}
+		o.Responses[strconv.Itoa(op.status)] = resp
+		if op.replay {
+			replayed := *resp
+			replayed.Description = "Replay of an earlier request with the same idempotency key; sets Idempotent-Replayed: true"
+			o.Responses[strconv.Itoa(http.StatusOK)] = &replayed
+		}
+
+		for _, code := range append(op.errors, http.StatusInternalServerError) {
+			o.Responses[strconv.Itoa(code)] = &openapi.Response{
+				Description: http.StatusText(code),
+				Content:     map[string]openapi.MediaType{"application/json": {Schema: errSchema}},
+			}
+		}
+
+		item, ok := doc.Paths[op.path]
+		if !ok {
+			item = make(openapi.PathItem)
+			doc.Paths[op.path] = item
+		}
+		item[strings.ToLower(op.method)] = o
+	}
+
+	doc.Components.Schemas = reg.Schemas()
+	doc.Components.SecuritySchemes = map[string]*openapi.SecurityScheme{
+		bearerScheme: {
+			Type:         "http",
+			Scheme:       "bearer",
+			BearerFormat: "gf_ API token",
+			Description:  "Personal API token created with POST /auth/tokens",
+		},
+		cookieScheme: {
+			Type:        "apiKey",
+			In:          "cookie",
+			Name:        sessionCookieName,
+			Description: "Session cookie set by POST /auth/login",
+		},
+	}
+	return doc
+}
+
+// GET /openapi.json
+func (s *Server) openapiSpec(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(specJSON)
+}
+
+var (
+	parsedSpecOnce sync.Once
+	parsedSpec     *openapi.Document
+	parsedSpecErr  error
+)
+
+func loadSpec() (*openapi.Document, error) {
+	parsedSpecOnce.Do(func() {
+		parsedSpec, parsedSpecErr = openapi.Parse(specJSON)
+	})
+	return parsedSpec, parsedSpecErr
+}
+
+// validateRequests checks path parameters, query parameters and JSON bodies
+// against the spec before the handler runs. Handlers keep their own checks;
+// this catches type errors and unknown enum values uniformly and early.
+func (s *Server) validateRequests(next http.Handler) http.Handler {
+	doc, err := loadSpec()
+	if err != nil {
+		panic("httpserver: embedded openapi.json is invalid: " + err.Error())
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		op, pathParams := doc.Match(r.Method, r.URL.Path)
+		if op == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		var violations []openapi.Violation
+		query := r.URL.Query()
+		for _, p := range op.Parameters {
+			switch p.In {
+			case "path":
+				violations = append(violations, doc.ValidateParam(p, pathParams[p.Name])...)
+			case "query":
+				if v := query.Get(p.Name); v != "" {
+					violations = append(violations, doc.ValidateParam(p, v)...)
+				}
+			}
+		}
+
+		if op.RequestBody != nil {
+			if mt, ok := op.RequestBody.Content["application/json"]; ok {
+				body, err := io.ReadAll(r.Body)
+				if err != nil {
+					writeError(w, http.StatusBadRequest, CodeInvalidJSON, "failed to read body")
+					return
+				}
+				r.Body = io.NopCloser(bytes.NewReader(body))
+
+				bodyViolations, err := doc.ValidateJSON(mt.Schema, body)
+				if err != nil {
+					writeError(w, http.StatusBadRequest, CodeInvalidJSON, "invalid json")
+					return
+				}
+				violations = append(violations, bodyViolations...)
+			}
+		}
+
+		if len(violations) > 0 {
+			errs := make(validationErrors, 0, len(violations))
+			for _, v := range violations {
+				errs.add(v.Field, ErrorCode(v.Kind), v.Message)
+			}
+			writeValidationErrors(w, errs)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
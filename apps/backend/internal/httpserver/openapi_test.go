@@ -0,0 +1,111 @@
+package httpserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/require"
+
+	"github.com/LBaronceli/go-figure/internal/openapi"
+)
+
+const regenerate = "spec is stale; run: go generate ./internal/httpserver"
+
+func TestOpenAPISpecUpToDate(t *testing.T) {
+	want, err := Spec().Marshal()
+	require.NoError(t, err)
+	require.Equal(t, string(want), string(specJSON), regenerate)
+}
+
+func TestGeneratedClientUpToDate(t *testing.T) {
+	doc, err := openapi.Parse(specJSON)
+	require.NoError(t, err)
+
+	want, err := openapi.GenerateClient(doc, "client")
+	require.NoError(t, err)
+
+	got, err := os.ReadFile("../../client/client.gen.go")
+	require.NoError(t, err)
+	require.Equal(t, string(want), string(got), regenerate)
+}
+
+func TestOpenAPICoversRoutes(t *testing.T) {
+	var routes []string
+	err := chi.Walk(NewServer(nil).Routes().(chi.Routes), func(method, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+		if route != "/" {
+			route = strings.TrimSuffix(route, "/")
+		}
+		routes = append(routes, strings.ToLower(method)+" "+route)
+		return nil
+	})
+	require.NoError(t, err)
+
+	var documented []string
+	doc := Spec()
+	for path, item := range doc.Paths {
+		for method := range item {
+			documented = append(documented, method+" "+path)
+		}
+	}
+
+	sort.Strings(routes)
+	sort.Strings(documented)
+	require.Equal(t, routes, documented, "Routes() and the operations table have drifted")
+}
+
+func TestValidateRequestsRejectsInvalidBody(t *testing.T) {
+	s := NewServer(nil, WithRequestValidation())
+	h := s.validateRequests(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Fatal("handler must not run for an invalid request")
+	}))
+
+	body := `{"idempotency_key":"k1","source":"bank","entries":[{"account_id":"nope","amount":"10"}]}`
+	req := httptest.NewRequest(http.MethodPost, "/transactions", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+
+	var resp struct {
+		Error struct {
+			Code    ErrorCode `json:"code"`
+			Details struct {
+				Fields []fieldError `json:"fields"`
+			} `json:"details"`
+		} `json:"error"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	require.Equal(t, CodeValidationFailed, resp.Error.Code)
+
+	got := make(map[string]ErrorCode)
+	for _, f := range resp.Error.Details.Fields {
+		got[f.Field] = f.Code
+	}
+	require.Equal(t, map[string]ErrorCode{
+		"entries":               CodeTooFew,
+		"entries[0].account_id": CodeInvalidFormat,
+		"entries[0].amount":     CodeInvalidType,
+		"source":                CodeInvalidValue,
+	}, got)
+}
+
+func TestValidateRequestsPassesValidRequest(t *testing.T) {
+	s := NewServer(nil, WithRequestValidation())
+	called := false
+	h := s.validateRequests(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+		var req createAccountRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req), "body must still be readable")
+		require.Equal(t, "Cash", req.Name)
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/accounts", strings.NewReader(`{"name":"Cash","type":"asset","currency":"NZD"}`))
+	h.ServeHTTP(httptest.NewRecorder(), req)
+	require.True(t, called)
+}
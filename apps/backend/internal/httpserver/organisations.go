@@ -0,0 +1,705 @@
+package httpserver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/LBaronceli/go-figure/internal/auth"
+	db "github.com/LBaronceli/go-figure/internal/db/sqlc"
+	"github.com/LBaronceli/go-figure/internal/fiscal"
+)
+
+// organisationHeader selects the organisation a request operates on. It may
+// be omitted by users who belong to exactly one organisation.
+const organisationHeader = "X-Organisation-ID"
+
+type createOrganisationRequest struct {
+	Name                 string `json:"name" openapi:"minLength=1,maxLength=500"`
+	Timezone             string `json:"timezone" openapi:"optional" doc:"IANA timezone reporting periods are computed in; defaults to UTC"`
+	FiscalYearStartMonth int    `json:"fiscal_year_start_month" openapi:"optional,minimum=1,maximum=12" doc:"First month of the financial year; defaults to 1 (January)"`
+}
+
+type updateOrganisationRequest struct {
+	Name                 *string `json:"name" openapi:"maxLength=500"`
+	Timezone             *string `json:"timezone"`
+	FiscalYearStartMonth *int    `json:"fiscal_year_start_month" openapi:"minimum=1,maximum=12"`
+}
+
+type organisationResponse struct {
+	ID                   string `json:"id" openapi:"format=uuid"`
+	Name                 string `json:"name"`
+	Role                 string `json:"role" openapi:"enum=owner|member|viewer" doc:"The caller's role"`
+	Timezone             string `json:"timezone"`
+	FiscalYearStartMonth int    `json:"fiscal_year_start_month"`
+	CreatedAt            string `json:"created_at" openapi:"format=date-time"`
+}
+
+type addMemberRequest struct {
+	Email string `json:"email" openapi:"minLength=1,maxLength=320"`
+	Role  string `json:"role" openapi:"enum=owner|member|viewer"`
+}
+
+type updateMemberRequest struct {
+	Role string `json:"role" openapi:"enum=owner|member|viewer"`
+}
+
+type memberResponse struct {
+	UserID    string `json:"user_id" openapi:"format=uuid"`
+	Email     string `json:"email"`
+	Role      string `json:"role" openapi:"enum=owner|member|viewer"`
+	CreatedAt string `json:"created_at" openapi:"format=date-time"`
+}
+
+// tenant is the organisation a request operates on. Every statement its
+// queries run, and every transaction begin starts, sets app.organisation_id
+// first, so row-level security applies to everything the handler does.
+type tenant struct {
+	id       pgtype.UUID
+	name     string
+	role     auth.Role
+	calendar fiscal.Calendar
+	store    statementDB
+	q        *db.Queries
+}
+
+// begin starts a database transaction scoped to the tenant.
+func (t *tenant) begin(ctx context.Context) (pgx.Tx, *db.Queries, error) {
+	tx, err := t.store.begin(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	return tx, t.q.WithTx(tx), nil
+}
+
+// beginner starts transactions: a pool, or a transaction, whose Begin opens
+// a savepoint.
+type beginner interface {
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+// statementDB runs each statement in a transaction of its own, begun on db,
+// with app.organisation_id set locally when orgID is not empty. A tenant
+// holds a connection only while a statement runs, and no connection goes
+// back to the pool still set. Begun on a request's transaction, each
+// statement gets a savepoint, so a failed one does not abort the request.
+type statementDB struct {
+	db    beginner
+	orgID string
+}
+
+func (d statementDB) begin(ctx context.Context) (pgx.Tx, error) {
+	tx, err := d.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if d.orgID == "" {
+		return tx, nil
+	}
+	if _, err := tx.Exec(ctx, "SELECT set_config('app.organisation_id', $1, true)", d.orgID); err != nil {
+		_ = tx.Rollback(ctx)
+		return nil, err
+	}
+	return tx, nil
+}
+
+func (d statementDB) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	tx, err := d.begin(ctx)
+	if err != nil {
+		return pgconn.CommandTag{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx, sql, args...)
+	if err != nil {
+		return tag, err
+	}
+	return tag, tx.Commit(ctx)
+}
+
+func (d statementDB) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	tx, err := d.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := tx.Query(ctx, sql, args...)
+	if err != nil {
+		_ = tx.Rollback(ctx)
+		return nil, err
+	}
+	return &statementRows{Rows: rows, ctx: ctx, tx: tx}, nil
+}
+
+func (d statementDB) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	return statementRow{db: d, ctx: ctx, sql: sql, args: args}
+}
+
+// statementRows ends its statement's transaction once the rows are read.
+type statementRows struct {
+	pgx.Rows
+	ctx    context.Context
+	tx     pgx.Tx
+	closed bool
+	err    error
+}
+
+func (r *statementRows) Next() bool {
+	if r.Rows.Next() {
+		return true
+	}
+	r.Close()
+	return false
+}
+
+func (r *statementRows) Close() {
+	if r.closed {
+		return
+	}
+	r.closed = true
+	r.Rows.Close()
+	if r.Rows.Err() != nil {
+		_ = r.tx.Rollback(r.ctx)
+		return
+	}
+	r.err = r.tx.Commit(r.ctx)
+}
+
+func (r *statementRows) Err() error {
+	if err := r.Rows.Err(); err != nil {
+		return err
+	}
+	return r.err
+}
+
+// statementRow runs its statement when scanned.
+type statementRow struct {
+	db   statementDB
+	ctx  context.Context
+	sql  string
+	args []any
+}
+
+func (r statementRow) Scan(dest ...any) error {
+	tx, err := r.db.begin(r.ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(r.ctx)
+
+	if err := tx.QueryRow(r.ctx, r.sql, r.args...).Scan(dest...); err != nil {
+		return err
+	}
+	return tx.Commit(r.ctx)
+}
+
+type tenantKey struct{}
+
+func tenantFrom(ctx context.Context) *tenant {
+	t, _ := ctx.Value(tenantKey{}).(*tenant)
+	return t
+}
+
+// organisationContext resolves the organisation from the X-Organisation-ID
+// header, checks the caller is a member whose role allows the request, and
+// scopes the handler's queries to it.
+func (s *Server) organisationContext(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		p, _ := auth.PrincipalFrom(ctx)
+
+		memberships, err := s.queries(ctx).ListOrganisationsForUser(ctx, pgtype.UUID{Bytes: p.UserID, Valid: true})
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, CodeInternal, "failed to load organisations")
+			return
+		}
+
+		var org *db.ListOrganisationsForUserRow
+		if header := r.Header.Get(organisationHeader); header != "" {
+			id, err := uuid.Parse(header)
+			if err != nil {
+				writeFieldError(w, http.StatusBadRequest, CodeInvalidFormat, organisationHeader, "invalid organisation id")
+				return
+			}
+			for i := range memberships {
+				if uuid.UUID(memberships[i].ID.Bytes) == id {
+					org = &memberships[i]
+					break
+				}
+			}
+			if org == nil {
+				writeError(w, http.StatusNotFound, CodeOrganisationNotFound, "organisation not found")
+				return
+			}
+		} else {
+			switch len(memberships) {
+			case 0:
+				writeError(w, http.StatusForbidden, CodeNoOrganisation, "you are not a member of any organisation")
+				return
+			case 1:
+				org = &memberships[0]
+			default:
+				writeFieldError(w, http.StatusBadRequest, CodeOrganisationRequired, organisationHeader, "you belong to several organisations; set "+organisationHeader)
+				return
+			}
+		}
+
+		role := auth.Role(org.Role)
+		if isMutating(r.Method) && !role.CanWrite() {
+			writeError(w, http.StatusForbidden, CodeInsufficientRole, "your role in this organisation is read-only")
+			return
+		}
+
+		calendar, err := fiscal.NewCalendar(org.Timezone, int(org.FiscalYearStartMonth))
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, CodeInternal, "organisation has an invalid reporting calendar")
+			return
+		}
+
+		store := statementDB{db: s.requestDB(ctx), orgID: uuid.UUID(org.ID.Bytes).String()}
+		t := &tenant{id: org.ID, name: org.Name, role: role, calendar: calendar, store: store, q: db.New(store)}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(ctx, tenantKey{}, t)))
+	})
+}
+
+// withoutRLS runs fn in a transaction that bypasses row-level security, for
+// maintenance that spans organisations.
+func (s *Server) withoutRLS(ctx context.Context, fn func(q *db.Queries) error) error {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, "SELECT set_config('app.rls_bypass', 'on', true)"); err != nil {
+		return err
+	}
+	if err := fn(s.q.WithTx(tx)); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// GET /organisations
+func (s *Server) listOrganisations(w http.ResponseWriter, r *http.Request) {
+	p, _ := auth.PrincipalFrom(r.Context())
+
+	orgs, err := s.queries(r.Context()).ListOrganisationsForUser(r.Context(), pgtype.UUID{Bytes: p.UserID, Valid: true})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to list organisations")
+		return
+	}
+
+	resp := make([]organisationResponse, 0, len(orgs))
+	for _, o := range orgs {
+		resp = append(resp, organisationResponse{
+			ID:                   uuid.UUID(o.ID.Bytes).String(),
+			Name:                 o.Name,
+			Role:                 o.Role,
+			Timezone:             o.Timezone,
+			FiscalYearStartMonth: int(o.FiscalYearStartMonth),
+			CreatedAt:            o.CreatedAt.Time.Format(time.RFC3339Nano),
+		})
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// POST /organisations
+func (s *Server) createOrganisation(w http.ResponseWriter, r *http.Request) {
+	var req createOrganisationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, CodeInvalidJSON, "invalid json")
+		return
+	}
+
+	req.Name = strings.TrimSpace(req.Name)
+	req.Timezone = strings.TrimSpace(req.Timezone)
+	if req.Timezone == "" {
+		req.Timezone = "UTC"
+	}
+	if req.FiscalYearStartMonth == 0 {
+		req.FiscalYearStartMonth = int(time.January)
+	}
+
+	var errs validationErrors
+	if req.Name == "" {
+		errs.add("name", CodeRequired, "name is required")
+	} else if len(req.Name) > maxStringLength {
+		errs.add("name", CodeTooLong, "name too long")
+	}
+	validateTimezone(&errs, req.Timezone)
+	validateStartMonth(&errs, req.FiscalYearStartMonth)
+	if !errs.empty() {
+		writeValidationErrors(w, errs)
+		return
+	}
+
+	p, _ := auth.PrincipalFrom(r.Context())
+
+	tx, qtx, err := s.begin(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to begin transaction")
+		return
+	}
+	defer tx.Rollback(r.Context())
+
+	org, err := qtx.CreateOrganisation(r.Context(), db.CreateOrganisationParams{
+		Name:                 req.Name,
+		Timezone:             req.Timezone,
+		FiscalYearStartMonth: int16(req.FiscalYearStartMonth),
+	})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to create organisation")
+		return
+	}
+	// the creator owns the new books
+	if _, err := qtx.CreateMembership(r.Context(), db.CreateMembershipParams{
+		OrganisationID: org.ID,
+		UserID:         pgtype.UUID{Bytes: p.UserID, Valid: true},
+		Role:           string(auth.RoleOwner),
+	}); err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to create membership")
+		return
+	}
+
+	if err := tx.Commit(r.Context()); err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to commit transaction")
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, toOrganisationResponse(org, auth.RoleOwner))
+}
+
+// PUT /organisations/{id}
+func (s *Server) updateOrganisation(w http.ResponseWriter, r *http.Request) {
+	orgID, role, ok := s.callerMembership(w, r)
+	if !ok {
+		return
+	}
+	if !role.CanManageMembers() {
+		writeError(w, http.StatusForbidden, CodeInsufficientRole, "only owners can change organisation settings")
+		return
+	}
+
+	var req updateOrganisationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, CodeInvalidJSON, "invalid json")
+		return
+	}
+
+	if req.Name == nil && req.Timezone == nil && req.FiscalYearStartMonth == nil {
+		writeError(w, http.StatusBadRequest, CodeNothingToUpdate, "nothing to update")
+		return
+	}
+
+	params := db.UpdateOrganisationParams{ID: orgID}
+	var errs validationErrors
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		if name == "" {
+			errs.add("name", CodeRequired, "name cannot be empty")
+		} else if len(name) > maxStringLength {
+			errs.add("name", CodeTooLong, "name too long")
+		}
+		params.Name = pgtype.Text{String: name, Valid: true}
+	}
+	if req.Timezone != nil {
+		tz := strings.TrimSpace(*req.Timezone)
+		validateTimezone(&errs, tz)
+		params.Timezone = pgtype.Text{String: tz, Valid: true}
+	}
+	if req.FiscalYearStartMonth != nil {
+		validateStartMonth(&errs, *req.FiscalYearStartMonth)
+		params.FiscalYearStartMonth = pgtype.Int2{Int16: int16(*req.FiscalYearStartMonth), Valid: true}
+	}
+	if !errs.empty() {
+		writeValidationErrors(w, errs)
+		return
+	}
+
+	org, err := s.queries(r.Context()).UpdateOrganisation(r.Context(), params)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to update organisation")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, toOrganisationResponse(org, role))
+}
+
+func validateTimezone(errs *validationErrors, timezone string) {
+	if _, err := fiscal.LoadLocation(timezone); err != nil {
+		errs.add("timezone", CodeInvalidValue, "timezone must be an IANA name such as Pacific/Auckland")
+	}
+}
+
+func validateStartMonth(errs *validationErrors, month int) {
+	if month < 1 || month > 12 {
+		errs.add("fiscal_year_start_month", CodeOutOfRange, "fiscal_year_start_month must be between 1 and 12")
+	}
+}
+
+func toOrganisationResponse(o db.Organisation, role auth.Role) organisationResponse {
+	return organisationResponse{
+		ID:                   uuid.UUID(o.ID.Bytes).String(),
+		Name:                 o.Name,
+		Role:                 string(role),
+		Timezone:             o.Timezone,
+		FiscalYearStartMonth: int(o.FiscalYearStartMonth),
+		CreatedAt:            o.CreatedAt.Time.Format(time.RFC3339Nano),
+	}
+}
+
+// GET /organisations/{id}/members
+func (s *Server) listMembers(w http.ResponseWriter, r *http.Request) {
+	orgID, _, ok := s.callerMembership(w, r)
+	if !ok {
+		return
+	}
+
+	members, err := s.queries(r.Context()).ListMembers(r.Context(), orgID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to list members")
+		return
+	}
+
+	resp := make([]memberResponse, 0, len(members))
+	for _, m := range members {
+		resp = append(resp, memberResponse{
+			UserID:    uuid.UUID(m.UserID.Bytes).String(),
+			Email:     m.Email,
+			Role:      m.Role,
+			CreatedAt: m.CreatedAt.Time.Format(time.RFC3339Nano),
+		})
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// POST /organisations/{id}/members
+func (s *Server) addMember(w http.ResponseWriter, r *http.Request) {
+	orgID, role, ok := s.callerMembership(w, r)
+	if !ok {
+		return
+	}
+	if !role.CanManageMembers() {
+		writeError(w, http.StatusForbidden, CodeInsufficientRole, "only owners can manage members")
+		return
+	}
+
+	var req addMemberRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, CodeInvalidJSON, "invalid json")
+		return
+	}
+
+	email := normalizeEmail(req.Email)
+	var errs validationErrors
+	if email == "" {
+		errs.add("email", CodeRequired, "email is required")
+	}
+	if !auth.Role(req.Role).IsValid() {
+		errs.add("role", CodeInvalidValue, "invalid role (must be owner, member, or viewer)")
+	}
+	if !errs.empty() {
+		writeValidationErrors(w, errs)
+		return
+	}
+
+	user, err := s.queries(r.Context()).GetUserByEmail(r.Context(), email)
+	if errors.Is(err, pgx.ErrNoRows) {
+		writeFieldError(w, http.StatusBadRequest, CodeUserNotFound, "email", "no user has this email")
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to look up user")
+		return
+	}
+
+	m, err := s.queries(r.Context()).CreateMembership(r.Context(), db.CreateMembershipParams{
+		OrganisationID: orgID,
+		UserID:         user.ID,
+		Role:           req.Role,
+	})
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			writeFieldError(w, http.StatusConflict, CodeAlreadyMember, "email", "user is already a member")
+			return
+		}
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to add member")
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, memberResponse{
+		UserID:    uuid.UUID(m.UserID.Bytes).String(),
+		Email:     user.Email,
+		Role:      m.Role,
+		CreatedAt: m.CreatedAt.Time.Format(time.RFC3339Nano),
+	})
+}
+
+// PUT /organisations/{id}/members/{user_id}
+func (s *Server) updateMember(w http.ResponseWriter, r *http.Request) {
+	orgID, role, ok := s.callerMembership(w, r)
+	if !ok {
+		return
+	}
+	if !role.CanManageMembers() {
+		writeError(w, http.StatusForbidden, CodeInsufficientRole, "only owners can manage members")
+		return
+	}
+
+	userID, err := parseUUID(chi.URLParam(r, "user_id"))
+	if err != nil {
+		writeFieldError(w, http.StatusBadRequest, CodeInvalidFormat, "user_id", "invalid user_id")
+		return
+	}
+
+	var req updateMemberRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, CodeInvalidJSON, "invalid json")
+		return
+	}
+	if !auth.Role(req.Role).IsValid() {
+		writeFieldError(w, http.StatusBadRequest, CodeInvalidValue, "role", "invalid role (must be owner, member, or viewer)")
+		return
+	}
+
+	var m db.Membership
+	err = s.changeMembers(r.Context(), orgID, func(q *db.Queries) error {
+		var err error
+		m, err = q.UpdateMembershipRole(r.Context(), db.UpdateMembershipRoleParams{
+			OrganisationID: orgID,
+			UserID:         userID,
+			Role:           req.Role,
+		})
+		return err
+	})
+	if !writeMemberChangeError(w, err) {
+		return
+	}
+
+	user, err := s.queries(r.Context()).GetUser(r.Context(), userID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to load user")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, memberResponse{
+		UserID:    uuid.UUID(m.UserID.Bytes).String(),
+		Email:     user.Email,
+		Role:      m.Role,
+		CreatedAt: m.CreatedAt.Time.Format(time.RFC3339Nano),
+	})
+}
+
+// DELETE /organisations/{id}/members/{user_id}
+func (s *Server) removeMember(w http.ResponseWriter, r *http.Request) {
+	orgID, role, ok := s.callerMembership(w, r)
+	if !ok {
+		return
+	}
+
+	userID, err := parseUUID(chi.URLParam(r, "user_id"))
+	if err != nil {
+		writeFieldError(w, http.StatusBadRequest, CodeInvalidFormat, "user_id", "invalid user_id")
+		return
+	}
+
+	// anyone may leave; only owners may remove others
+	p, _ := auth.PrincipalFrom(r.Context())
+	if uuid.UUID(userID.Bytes) != p.UserID && !role.CanManageMembers() {
+		writeError(w, http.StatusForbidden, CodeInsufficientRole, "only owners can manage members")
+		return
+	}
+
+	err = s.changeMembers(r.Context(), orgID, func(q *db.Queries) error {
+		n, err := q.DeleteMembership(r.Context(), db.DeleteMembershipParams{OrganisationID: orgID, UserID: userID})
+		if err == nil && n == 0 {
+			return pgx.ErrNoRows
+		}
+		return err
+	})
+	if !writeMemberChangeError(w, err) {
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+var errLastOwner = errors.New("organisation would have no owner")
+
+// changeMembers applies a membership change with the organisation locked and
+// refuses it if no owner would be left.
+func (s *Server) changeMembers(ctx context.Context, orgID pgtype.UUID, fn func(q *db.Queries) error) error {
+	tx, qtx, err := s.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := qtx.LockOrganisation(ctx, orgID); err != nil {
+		return err
+	}
+	if err := fn(qtx); err != nil {
+		return err
+	}
+	owners, err := qtx.CountOwners(ctx, orgID)
+	if err != nil {
+		return err
+	}
+	if owners == 0 {
+		return errLastOwner
+	}
+	return tx.Commit(ctx)
+}
+
+// writeMemberChangeError reports whether err is nil, writing the error
+// response otherwise.
+func writeMemberChangeError(w http.ResponseWriter, err error) bool {
+	switch {
+	case err == nil:
+		return true
+	case errors.Is(err, pgx.ErrNoRows):
+		writeError(w, http.StatusNotFound, CodeNotFound, "member not found")
+	case errors.Is(err, errLastOwner):
+		writeError(w, http.StatusConflict, CodeLastOwner, "an organisation must keep at least one owner")
+	default:
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to change membership")
+	}
+	return false
+}
+
+// callerMembership resolves the {id} organisation and the caller's role in
+// it. Non-members get the same 404 as a missing organisation.
+func (s *Server) callerMembership(w http.ResponseWriter, r *http.Request) (pgtype.UUID, auth.Role, bool) {
+	orgID, err := parseUUID(chi.URLParam(r, "id"))
+	if err != nil {
+		writeFieldError(w, http.StatusBadRequest, CodeInvalidFormat, "id", "invalid id")
+		return pgtype.UUID{}, "", false
+	}
+
+	p, _ := auth.PrincipalFrom(r.Context())
+	m, err := s.queries(r.Context()).GetMembership(r.Context(), db.GetMembershipParams{
+		OrganisationID: orgID,
+		UserID:         pgtype.UUID{Bytes: p.UserID, Valid: true},
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		writeError(w, http.StatusNotFound, CodeOrganisationNotFound, "organisation not found")
+		return pgtype.UUID{}, "", false
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to load membership")
+		return pgtype.UUID{}, "", false
+	}
+	return orgID, auth.Role(m.Role), true
+}
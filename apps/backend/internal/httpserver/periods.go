@@ -0,0 +1,465 @@
+package httpserver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/LBaronceli/go-figure/internal/audit"
+	"github.com/LBaronceli/go-figure/internal/auth"
+	db "github.com/LBaronceli/go-figure/internal/db/sqlc"
+	"github.com/LBaronceli/go-figure/internal/fiscal"
+	"github.com/LBaronceli/go-figure/internal/models"
+)
+
+// Lock reasons. year_end locks are only created by the year-end close.
+const (
+	lockReasonGSTReturn = "gst_return"
+	lockReasonYearEnd   = "year_end"
+	lockReasonManual    = "manual"
+)
+
+// sourceClosing marks the transaction posted by a year-end close.
+const sourceClosing = "closing"
+
+type createPeriodLockRequest struct {
+	Period   string `json:"period" openapi:"optional" doc:"Reporting period such as FY2026-Q3 or 2026-05, instead of starts_on and ends_on"`
+	StartsOn string `json:"starts_on" openapi:"optional,format=date"`
+	EndsOn   string `json:"ends_on" openapi:"optional,format=date" doc:"Inclusive"`
+	Reason   string `json:"reason" openapi:"enum=gst_return|manual"`
+}
+
+type closeYearRequest struct {
+	Period                    string `json:"period" openapi:"optional" doc:"Financial year such as FY2026, instead of starts_on and ends_on"`
+	StartsOn                  string `json:"starts_on" openapi:"optional,format=date"`
+	EndsOn                    string `json:"ends_on" openapi:"optional,format=date" doc:"Inclusive"`
+	RetainedEarningsAccountID string `json:"retained_earnings_account_id" openapi:"format=uuid" doc:"Equity account that receives the year's profit or loss"`
+}
+
+type periodLockResponse struct {
+	ID        string `json:"id" openapi:"format=uuid"`
+	StartsOn  string `json:"starts_on" openapi:"format=date"`
+	EndsOn    string `json:"ends_on" openapi:"format=date" doc:"Inclusive"`
+	Reason    string `json:"reason" openapi:"enum=gst_return|year_end|manual"`
+	LockedBy  string `json:"locked_by" openapi:"format=uuid"`
+	CreatedAt string `json:"created_at" openapi:"format=date-time"`
+}
+
+type closeYearResponse struct {
+	Lock        periodLockResponse   `json:"lock"`
+	Transaction *transactionResponse `json:"transaction,omitempty" doc:"The closing entries; absent when no income or expense account moved"`
+}
+
+// checkPeriodOpen reports the lock covering the accounting date on, if any.
+// It must run inside the transaction that posts, after which no lock can be
+// added over on until that transaction ends.
+func checkPeriodOpen(ctx context.Context, q *db.Queries, org *tenant, on time.Time) (*db.PeriodLock, error) {
+	if err := q.LockPeriodsShared(ctx, org.id); err != nil {
+		return nil, err
+	}
+	lock, err := q.GetPeriodLockOn(ctx, db.GetPeriodLockOnParams{
+		OrganisationID: org.id,
+		On:             pgtype.Date{Time: on, Valid: true},
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &lock, nil
+}
+
+// periodLockOn finds the lock covering on among locks, for callers that
+// check many dates against one ListPeriodLocks read.
+func periodLockOn(locks []db.PeriodLock, on pgtype.Date) *db.PeriodLock {
+	for i, l := range locks {
+		if !on.Time.Before(l.StartsOn.Time) && !on.Time.After(l.EndsOn.Time) {
+			return &locks[i]
+		}
+	}
+	return nil
+}
+
+func writePeriodLocked(w http.ResponseWriter, field string, lock *db.PeriodLock) {
+	writeFieldError(w, http.StatusConflict, CodePeriodLocked, field, periodLockedMessage(lock))
+}
+
+func periodLockedMessage(lock *db.PeriodLock) string {
+	return fmt.Sprintf("period from %s to %s is locked",
+		lock.StartsOn.Time.Format(time.DateOnly), lock.EndsOn.Time.Format(time.DateOnly))
+}
+
+// parsePeriod validates either a period label in the organisation's calendar
+// or an inclusive starts_on and ends_on pair, returning the first and last
+// accounting dates.
+func parsePeriod(errs *validationErrors, cal fiscal.Calendar, period, startsOn, endsOn string) (time.Time, time.Time) {
+	if period != "" {
+		if startsOn != "" || endsOn != "" {
+			errs.add("period", CodeInvalidValue, "use either period or starts_on and ends_on")
+			return time.Time{}, time.Time{}
+		}
+		p, err := cal.Parse(period)
+		if err != nil {
+			errs.add("period", CodeInvalidFormat, err.Error())
+		}
+		return p.FirstDay(), p.LastDay()
+	}
+
+	first, err := parseDate(startsOn)
+	if err != nil {
+		errs.add("starts_on", CodeInvalidFormat, "invalid starts_on (use YYYY-MM-DD)")
+	}
+	last, err := parseDate(endsOn)
+	if err != nil {
+		errs.add("ends_on", CodeInvalidFormat, "invalid ends_on (use YYYY-MM-DD)")
+	}
+	if errs.empty() && last.Before(first) {
+		errs.add("ends_on", CodeOutOfRange, "ends_on must not be before starts_on")
+	}
+	return first, last
+}
+
+// GET /period-locks
+func (s *Server) listPeriodLocks(w http.ResponseWriter, r *http.Request) {
+	org := tenantFrom(r.Context())
+	locks, err := org.q.ListPeriodLocks(r.Context(), org.id)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to list period locks")
+		return
+	}
+
+	resp := make([]periodLockResponse, 0, len(locks))
+	for _, l := range locks {
+		resp = append(resp, toPeriodLockResponse(l))
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// POST /period-locks
+func (s *Server) createPeriodLock(w http.ResponseWriter, r *http.Request) {
+	org := tenantFrom(r.Context())
+	if !org.role.CanLockPeriods() {
+		writeError(w, http.StatusForbidden, CodeInsufficientRole, "only owners can lock periods")
+		return
+	}
+
+	var req createPeriodLockRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, CodeInvalidJSON, "invalid json")
+		return
+	}
+
+	var errs validationErrors
+	first, last := parsePeriod(&errs, org.calendar, req.Period, req.StartsOn, req.EndsOn)
+	if req.Reason != lockReasonGSTReturn && req.Reason != lockReasonManual {
+		errs.add("reason", CodeInvalidValue, "reason must be gst_return or manual; year_end locks come from closing the year")
+	}
+	if !errs.empty() {
+		writeValidationErrors(w, errs)
+		return
+	}
+
+	tx, qtx, err := org.begin(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to begin transaction")
+		return
+	}
+	defer tx.Rollback(r.Context())
+
+	lock, ok := s.lockPeriod(w, r, qtx, org, first, last, req.Reason)
+	if !ok {
+		return
+	}
+
+	if err := tx.Commit(r.Context()); err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to commit transaction")
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, toPeriodLockResponse(lock))
+}
+
+// lockPeriod creates and audits a lock from first to last inclusive, refusing
+// overlaps. It writes the error response itself and reports whether to carry
+// on.
+func (s *Server) lockPeriod(w http.ResponseWriter, r *http.Request, q *db.Queries, org *tenant, first, last time.Time, reason string) (db.PeriodLock, bool) {
+	if err := q.LockPeriodsExclusive(r.Context(), org.id); err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to lock periods")
+		return db.PeriodLock{}, false
+	}
+
+	existing, err := q.GetOverlappingPeriodLock(r.Context(), db.GetOverlappingPeriodLockParams{
+		OrganisationID: org.id,
+		StartsOn:       pgtype.Date{Time: first, Valid: true},
+		EndsOn:         pgtype.Date{Time: last, Valid: true},
+	})
+	if err == nil {
+		writePeriodLocked(w, "starts_on", &existing)
+		return db.PeriodLock{}, false
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to check period locks")
+		return db.PeriodLock{}, false
+	}
+
+	p, _ := auth.PrincipalFrom(r.Context())
+	lock, err := q.CreatePeriodLock(r.Context(), db.CreatePeriodLockParams{
+		OrganisationID: org.id,
+		StartsOn:       pgtype.Date{Time: first, Valid: true},
+		EndsOn:         pgtype.Date{Time: last, Valid: true},
+		Reason:         reason,
+		LockedBy:       pgtype.UUID{Bytes: p.UserID, Valid: true},
+	})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to lock period")
+		return db.PeriodLock{}, false
+	}
+
+	if err := recordAudit(r.Context(), q, org, audit.EntityPeriodLock, lock.ID, audit.ActionLock, nil, toPeriodLockResponse(lock)); err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to record audit event")
+		return db.PeriodLock{}, false
+	}
+
+	return lock, true
+}
+
+// DELETE /period-locks/{id}
+//
+// Unlocking reopens filed figures, so it needs the admin scope and is
+// audited.
+func (s *Server) deletePeriodLock(w http.ResponseWriter, r *http.Request) {
+	id, err := parseUUID(chi.URLParam(r, "id"))
+	if err != nil {
+		writeFieldError(w, http.StatusBadRequest, CodeInvalidFormat, "id", "invalid id")
+		return
+	}
+
+	org := tenantFrom(r.Context())
+	if !org.role.CanLockPeriods() {
+		writeError(w, http.StatusForbidden, CodeInsufficientRole, "only owners can unlock periods")
+		return
+	}
+
+	tx, qtx, err := org.begin(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to begin transaction")
+		return
+	}
+	defer tx.Rollback(r.Context())
+
+	if err := qtx.LockPeriodsExclusive(r.Context(), org.id); err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to lock periods")
+		return
+	}
+
+	lock, err := qtx.GetPeriodLock(r.Context(), db.GetPeriodLockParams{OrganisationID: org.id, ID: id})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			writeError(w, http.StatusNotFound, CodeNotFound, "period lock not found")
+			return
+		}
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to get period lock")
+		return
+	}
+
+	if err := qtx.DeletePeriodLock(r.Context(), db.DeletePeriodLockParams{OrganisationID: org.id, ID: id}); err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to unlock period")
+		return
+	}
+
+	if err := recordAudit(r.Context(), qtx, org, audit.EntityPeriodLock, lock.ID, audit.ActionUnlock, toPeriodLockResponse(lock), nil); err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to record audit event")
+		return
+	}
+
+	if err := tx.Commit(r.Context()); err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to commit transaction")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// POST /period-locks/close-year
+//
+// Closing a year posts one transaction that zeroes every income and expense
+// account's movement over the year into a retained earnings account, dated
+// on the last day of the year, then locks the year. Both happen in one
+// database transaction.
+func (s *Server) closeYear(w http.ResponseWriter, r *http.Request) {
+	org := tenantFrom(r.Context())
+	if !org.role.CanLockPeriods() {
+		writeError(w, http.StatusForbidden, CodeInsufficientRole, "only owners can close the year")
+		return
+	}
+
+	var req closeYearRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, CodeInvalidJSON, "invalid json")
+		return
+	}
+
+	var errs validationErrors
+	first, last := parsePeriod(&errs, org.calendar, req.Period, req.StartsOn, req.EndsOn)
+	retainedID, err := parseUUID(req.RetainedEarningsAccountID)
+	if err != nil {
+		errs.add("retained_earnings_account_id", CodeInvalidFormat, "invalid retained_earnings_account_id")
+	}
+	if !errs.empty() {
+		writeValidationErrors(w, errs)
+		return
+	}
+
+	tx, qtx, err := org.begin(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to begin transaction")
+		return
+	}
+	defer tx.Rollback(r.Context())
+
+	retained, err := qtx.GetAccount(r.Context(), db.GetAccountParams{OrganisationID: org.id, ID: retainedID})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			writeFieldError(w, http.StatusBadRequest, CodeAccountNotFound, "retained_earnings_account_id", "account not found")
+			return
+		}
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to get account")
+		return
+	}
+	if models.AccountType(retained.Type) != models.AccountTypeEquity {
+		writeFieldError(w, http.StatusBadRequest, CodeInvalidValue, "retained_earnings_account_id", "retained earnings must be an equity account")
+		return
+	}
+	if retained.ArchivedAt.Valid {
+		writeFieldError(w, http.StatusConflict, CodeAccountArchived, "retained_earnings_account_id", "account is archived")
+		return
+	}
+
+	// Taken before reading balances, so nothing can post into the year
+	// between the totals and the lock.
+	if err := qtx.LockPeriodsExclusive(r.Context(), org.id); err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to lock periods")
+		return
+	}
+
+	balances, err := qtx.ListClosingBalances(r.Context(), db.ListClosingBalancesParams{
+		OrganisationID: org.id,
+		StartsOn:       pgtype.Date{Time: first, Valid: true},
+		EndsOn:         pgtype.Date{Time: last, Valid: true},
+	})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to total the year")
+		return
+	}
+
+	// lockPeriod refuses a year that overlaps an existing lock, which rolls
+	// the closing entries back with it
+	var resp closeYearResponse
+	if len(balances) > 0 {
+		t, ok := s.postClosingEntries(w, r, qtx, org, retained, balances, first, last)
+		if !ok {
+			return
+		}
+		resp.Transaction = &t
+	}
+
+	lock, ok := s.lockPeriod(w, r, qtx, org, first, last, lockReasonYearEnd)
+	if !ok {
+		return
+	}
+	resp.Lock = toPeriodLockResponse(lock)
+
+	if err := tx.Commit(r.Context()); err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to commit transaction")
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, resp)
+}
+
+// postClosingEntries posts the closing transaction and audits it. It writes
+// the error response itself and reports whether to carry on.
+func (s *Server) postClosingEntries(w http.ResponseWriter, r *http.Request, q *db.Queries, org *tenant, retained db.Account, balances []db.ListClosingBalancesRow, first, last time.Time) (transactionResponse, bool) {
+	var net int64
+	for _, b := range balances {
+		if b.Currency != retained.Currency {
+			writeFieldError(w, http.StatusUnprocessableEntity, CodeCurrencyMismatch, "retained_earnings_account_id",
+				"multi-currency close not supported yet (income and expense accounts must use the retained earnings currency)")
+			return transactionResponse{}, false
+		}
+		if (b.BalanceMinor > 0 && net > (1<<63-1)-b.BalanceMinor) || (b.BalanceMinor < 0 && net < -(1<<63-1)-b.BalanceMinor) {
+			writeError(w, http.StatusUnprocessableEntity, CodeAmountOverflow, "closing amount overflow")
+			return transactionResponse{}, false
+		}
+		net += b.BalanceMinor
+	}
+
+	description := fmt.Sprintf("Year-end close %s to %s", first.Format(time.DateOnly), last.Format(time.DateOnly))
+	t, err := q.CreateTransaction(r.Context(), db.CreateTransactionParams{
+		OrganisationID: org.id,
+		Description:    pgtype.Text{String: description, Valid: true},
+		Source:         sourceClosing,
+		PostedAt:       pgtype.Timestamptz{Time: time.Now(), Valid: true},
+		PostedOn:       pgtype.Date{Time: last, Valid: true},
+	})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to create closing transaction")
+		return transactionResponse{}, false
+	}
+
+	entries := make([]db.LedgerEntry, 0, len(balances)+1)
+	post := func(accountID pgtype.UUID, amount int64) bool {
+		le, err := q.CreateLedgerEntry(r.Context(), db.CreateLedgerEntryParams{
+			OrganisationID: org.id,
+			TransactionID:  t.ID,
+			AccountID:      accountID,
+			AmountMinor:    amount,
+			Currency:       retained.Currency,
+		})
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, CodeInternal, "failed to create ledger entry")
+			return false
+		}
+		entries = append(entries, le)
+		return true
+	}
+	for _, b := range balances {
+		if !post(b.AccountID, -b.BalanceMinor) {
+			return transactionResponse{}, false
+		}
+	}
+	// income is credited (negative), so a profit leaves net negative: a
+	// credit to retained earnings
+	if net != 0 && !post(retained.ID, net) {
+		return transactionResponse{}, false
+	}
+
+	resp := toFullTransactionResponse(t, entries)
+	if err := recordAudit(r.Context(), q, org, audit.EntityTransaction, t.ID, audit.ActionCreate, nil, resp); err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to record audit event")
+		return transactionResponse{}, false
+	}
+
+	return resp, true
+}
+
+func toPeriodLockResponse(l db.PeriodLock) periodLockResponse {
+	return periodLockResponse{
+		ID:        uuid.UUID(l.ID.Bytes).String(),
+		StartsOn:  l.StartsOn.Time.Format(time.DateOnly),
+		EndsOn:    l.EndsOn.Time.Format(time.DateOnly),
+		Reason:    l.Reason,
+		LockedBy:  uuid.UUID(l.LockedBy.Bytes).String(),
+		CreatedAt: l.CreatedAt.Time.Format(time.RFC3339Nano),
+	}
+}
@@ -7,15 +7,19 @@ import (
 )
 
 func (s *Server) readyz(w http.ResponseWriter, r *http.Request) {
+	if s.draining.Load() {
+		writeError(w, http.StatusServiceUnavailable, CodeUnavailable, "shutting down")
+		return
+	}
+
 	ctx, cancel := context.WithTimeout(r.Context(), 2*time.Second)
 	defer cancel()
 
 	if err := s.db.Ping(ctx); err != nil {
-		http.Error(w, "db not ready", http.StatusServiceUnavailable)
+		writeError(w, http.StatusServiceUnavailable, CodeUnavailable, "db not ready")
 		return
 	}
 
 	w.WriteHeader(http.StatusOK)
 	_, _ = w.Write([]byte("ready\n"))
 }
-
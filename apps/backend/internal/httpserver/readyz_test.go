@@ -0,0 +1,21 @@
+package httpserver
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadyzFailsWhileDraining(t *testing.T) {
+	// draining is checked before the database, so no pool is needed
+	s := &Server{}
+	s.Drain()
+
+	rec := httptest.NewRecorder()
+	s.readyz(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+	require.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	require.Contains(t, rec.Body.String(), string(CodeUnavailable))
+}
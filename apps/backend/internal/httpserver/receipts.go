@@ -0,0 +1,501 @@
+package httpserver
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/LBaronceli/go-figure/internal/audit"
+	db "github.com/LBaronceli/go-figure/internal/db/sqlc"
+)
+
+// Receipt scan statuses: the state of the OCR job.
+const (
+	receiptQueued  = "queued"
+	receiptRunning = "running"
+	receiptDone    = "done"
+	receiptFailed  = "failed"
+)
+
+// Receipt scan outcomes: what a finished scan led to.
+const (
+	receiptAttached = "attached"
+	receiptMatched  = "matched"
+	receiptDraft    = "draft"
+	receiptPosted   = "posted"
+)
+
+var receiptFilters = []apiParam{
+	{name: "status", typ: "string", enum: []string{receiptQueued, receiptRunning, receiptDone, receiptFailed}, desc: "Only receipts whose scan is in this state"},
+	{name: "outcome", typ: "string", enum: []string{receiptAttached, receiptMatched, receiptDraft, receiptPosted}, desc: "Only receipts whose scan led to this; draft lists the receipts waiting to be posted"},
+}
+
+type extractedText struct {
+	Value      string  `json:"value"`
+	Confidence float64 `json:"confidence" openapi:"minimum=0,maximum=1" doc:"How sure the scan is of the value; highlight low values for checking"`
+}
+
+type extractedDate struct {
+	Value      string  `json:"value" openapi:"format=date"`
+	Confidence float64 `json:"confidence" openapi:"minimum=0,maximum=1" doc:"How sure the scan is of the value; highlight low values for checking"`
+}
+
+type extractedAmount struct {
+	Value      int64   `json:"value" doc:"Minor units"`
+	Confidence float64 `json:"confidence" openapi:"minimum=0,maximum=1" doc:"How sure the scan is of the value; highlight low values for checking"`
+}
+
+type receiptResponse struct {
+	ID              string             `json:"id" openapi:"format=uuid"`
+	Attachment      attachmentResponse `json:"attachment"`
+	Status          string             `json:"status" openapi:"enum=queued|running|done|failed"`
+	Attempts        int32              `json:"attempts"`
+	Error           string             `json:"error,omitempty" doc:"Why the last scan attempt failed"`
+	Outcome         string             `json:"outcome,omitempty" openapi:"enum=attached|matched|draft|posted" doc:"Set once scanned: attached when the file was already on a transaction, matched when an imported bank line has its total near its date, draft when it waits to be posted and posted once it has been"`
+	Merchant        *extractedText     `json:"merchant,omitempty"`
+	Date            *extractedDate     `json:"date,omitempty"`
+	Total           *extractedAmount   `json:"total,omitempty" doc:"Amount paid, GST included"`
+	GST             *extractedAmount   `json:"gst,omitempty" doc:"GST included in the total"`
+	Text            string             `json:"text,omitempty" doc:"Everything recognised, one line per line"`
+	StatementLineID string             `json:"statement_line_id,omitempty" openapi:"format=uuid" doc:"Imported bank line the receipt matched"`
+	TransactionID   string             `json:"transaction_id,omitempty" openapi:"format=uuid" doc:"Transaction the receipt is evidence for"`
+	ContactID       string             `json:"contact_id,omitempty" openapi:"format=uuid" doc:"Suggested contact, whose alias matches the merchant"`
+	AccountID       string             `json:"account_id,omitempty" openapi:"format=uuid" doc:"Suggested account: the contact's default account"`
+	CreatedAt       string             `json:"created_at" openapi:"format=date-time"`
+	UpdatedAt       string             `json:"updated_at" openapi:"format=date-time"`
+}
+
+type postReceiptRequest struct {
+	IdempotencyKey   string `json:"idempotency_key" openapi:"minLength=1,maxLength=500"`
+	PaymentAccountID string `json:"payment_account_id" openapi:"format=uuid" doc:"Bank, card or cash account the receipt was paid from"`
+	AccountID        string `json:"account_id" openapi:"optional,format=uuid" doc:"Account debited with the spending; defaults to the suggested account_id"`
+	TaxAccountID     string `json:"tax_account_id" openapi:"optional,format=uuid" doc:"Account debited with the GST; without it the GST stays in the amount debited to account_id"`
+	Total            *int64 `json:"total,omitempty" doc:"Minor units, GST included; defaults to the scanned total"`
+	GST              *int64 `json:"gst,omitempty" doc:"Minor units; defaults to the scanned GST. Only used with tax_account_id"`
+	PostedOn         string `json:"posted_on" openapi:"optional,format=date" doc:"Defaults to the scanned date"`
+	Description      string `json:"description" openapi:"optional,maxLength=500" doc:"Defaults to the scanned merchant"`
+}
+
+// POST /receipts
+//
+// Puts a photographed receipt in the inbox, an attachment without a
+// transaction, and queues its scan. Uploading a file already in the inbox
+// returns that receipt with 200.
+func (s *Server) createReceipt(w http.ResponseWriter, r *http.Request) {
+	if s.storage == nil {
+		writeError(w, http.StatusServiceUnavailable, CodeUnavailable, "attachment storage is not configured")
+		return
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, maxAttachmentBytes+64<<10)
+	var errs validationErrors
+	upload := readAttachment(r, &errs)
+	if !errs.empty() {
+		writeValidationErrors(w, errs)
+		return
+	}
+
+	org := tenantFrom(r.Context())
+	tx, qtx, err := org.begin(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to begin transaction")
+		return
+	}
+	defer tx.Rollback(r.Context())
+
+	a, created, ok := s.saveAttachment(w, r, qtx, org, pgtype.UUID{}, upload)
+	if !ok {
+		return
+	}
+	scan, err := qtx.GetReceiptScanByAttachment(r.Context(), db.GetReceiptScanByAttachmentParams{OrganisationID: org.id, AttachmentID: a.ID})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to get receipt")
+		return
+	}
+	if !created {
+		writeJSON(w, http.StatusOK, toReceiptResponse(scan, a))
+		return
+	}
+	if err := tx.Commit(r.Context()); err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to commit transaction")
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, toReceiptResponse(scan, a))
+}
+
+// GET /receipts
+func (s *Server) listReceipts(w http.ResponseWriter, r *http.Request) {
+	var errs validationErrors
+	var status, outcome pgtype.Text
+	switch v := r.URL.Query().Get("status"); v {
+	case "":
+	case receiptQueued, receiptRunning, receiptDone, receiptFailed:
+		status = pgtype.Text{String: v, Valid: true}
+	default:
+		errs.add("status", CodeInvalidValue, "invalid status (must be queued, running, done, or failed)")
+	}
+	switch v := r.URL.Query().Get("outcome"); v {
+	case "":
+	case receiptAttached, receiptMatched, receiptDraft, receiptPosted:
+		outcome = pgtype.Text{String: v, Valid: true}
+	default:
+		errs.add("outcome", CodeInvalidValue, "invalid outcome (must be attached, matched, draft, or posted)")
+	}
+	if !errs.empty() {
+		writeValidationErrors(w, errs)
+		return
+	}
+
+	org := tenantFrom(r.Context())
+	scans, err := org.q.ListReceiptScans(r.Context(), db.ListReceiptScansParams{
+		OrganisationID: org.id,
+		Limit:          50,
+		Offset:         0,
+		Status:         status,
+		Outcome:        outcome,
+	})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to list receipts")
+		return
+	}
+	ids := make([]pgtype.UUID, len(scans))
+	for i, scan := range scans {
+		ids[i] = scan.AttachmentID
+	}
+	attachments, err := org.q.GetAttachmentsByIDs(r.Context(), db.GetAttachmentsByIDsParams{OrganisationID: org.id, Ids: ids})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to fetch attachments")
+		return
+	}
+	byID := make(map[pgtype.UUID]db.Attachment, len(attachments))
+	for _, a := range attachments {
+		byID[a.ID] = a
+	}
+
+	resp := make([]receiptResponse, 0, len(scans))
+	for _, scan := range scans {
+		resp = append(resp, toReceiptResponse(scan, byID[scan.AttachmentID]))
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// GET /receipts/{id}
+func (s *Server) getReceipt(w http.ResponseWriter, r *http.Request) {
+	org := tenantFrom(r.Context())
+	scan, a, ok := loadReceipt(w, r, org.q, false)
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, toReceiptResponse(scan, a))
+}
+
+// GET /receipts/{id}/content
+func (s *Server) getReceiptContent(w http.ResponseWriter, r *http.Request) {
+	if s.storage == nil {
+		writeError(w, http.StatusServiceUnavailable, CodeUnavailable, "attachment storage is not configured")
+		return
+	}
+	org := tenantFrom(r.Context())
+	_, a, ok := loadReceipt(w, r, org.q, false)
+	if !ok {
+		return
+	}
+	s.writeAttachmentContent(w, r, org, a)
+}
+
+// POST /receipts/{id}/rescan
+//
+// Queues the receipt to be scanned again from scratch, after a failure or
+// once the OCR engine has improved. Posted receipts keep their scan.
+func (s *Server) rescanReceipt(w http.ResponseWriter, r *http.Request) {
+	org := tenantFrom(r.Context())
+	tx, qtx, err := org.begin(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to begin transaction")
+		return
+	}
+	defer tx.Rollback(r.Context())
+
+	scan, a, ok := loadReceipt(w, r, qtx, true)
+	if !ok {
+		return
+	}
+	if scan.Outcome.String == receiptPosted {
+		writeError(w, http.StatusConflict, CodeInvalidReceiptState, "receipt is already posted")
+		return
+	}
+	scan, err = qtx.RequeueReceiptScan(r.Context(), db.RequeueReceiptScanParams{OrganisationID: org.id, ID: scan.ID})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to queue receipt scan")
+		return
+	}
+	if err := tx.Commit(r.Context()); err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to commit transaction")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, toReceiptResponse(scan, a))
+}
+
+// DELETE /receipts/{id}
+//
+// Discards a receipt still in the inbox. One filed on a transaction is
+// removed from the transaction instead.
+func (s *Server) deleteReceipt(w http.ResponseWriter, r *http.Request) {
+	if s.storage == nil {
+		writeError(w, http.StatusServiceUnavailable, CodeUnavailable, "attachment storage is not configured")
+		return
+	}
+	org := tenantFrom(r.Context())
+	tx, qtx, err := org.begin(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to begin transaction")
+		return
+	}
+	defer tx.Rollback(r.Context())
+
+	_, a, ok := loadReceipt(w, r, qtx, false)
+	if !ok {
+		return
+	}
+	if err := qtx.LockAttachmentContent(r.Context(), db.LockAttachmentContentParams{OrganisationID: org.id, Sha256: a.Sha256}); err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to lock attachment")
+		return
+	}
+	// the scan may have filed it, or a concurrent delete won the lock;
+	// the receipt scan goes with the attachment
+	a, err = qtx.DeleteInboxAttachment(r.Context(), db.DeleteInboxAttachmentParams{OrganisationID: org.id, ID: a.ID})
+	if errors.Is(err, pgx.ErrNoRows) {
+		writeError(w, http.StatusConflict, CodeInvalidReceiptState, "receipt is filed on a transaction; delete it from the transaction")
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to delete receipt")
+		return
+	}
+	if !s.releaseAttachment(w, r, qtx, org, a) {
+		return
+	}
+	if err := tx.Commit(r.Context()); err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to commit transaction")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// POST /receipts/{id}/post
+//
+// Posts a draft receipt as a new transaction: the account is debited with
+// the total, less the GST when a tax account takes it, and the payment
+// account credited. Anything not given defaults to what the scan read. The
+// posting goes through the same path as POST /transactions, idempotency key
+// included, and files the receipt on the transaction in the same database
+// transaction.
+func (s *Server) postReceipt(w http.ResponseWriter, r *http.Request) {
+	var req postReceiptRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, CodeInvalidJSON, "invalid json")
+		return
+	}
+
+	org := tenantFrom(r.Context())
+	scan, _, ok := loadReceipt(w, r, org.q, false)
+	if !ok {
+		return
+	}
+	entries, errs := receiptEntries(req, scan)
+	if !errs.empty() {
+		writeValidationErrors(w, errs)
+		return
+	}
+
+	postedOn := req.PostedOn
+	if postedOn == "" && scan.ReceiptDate.Valid {
+		postedOn = scan.ReceiptDate.Time.Format(time.DateOnly)
+	}
+	description := strings.TrimSpace(req.Description)
+	if description == "" {
+		description = scan.Merchant.String
+	}
+	// the outcome is checked once the scan is locked, after a replayed
+	// request has had its chance to return the transaction it posted
+	s.postTransaction(w, r, createTransactionRequest{
+		IdempotencyKey: req.IdempotencyKey,
+		Description:    description,
+		Source:         "manual",
+		PostedOn:       postedOn,
+		ContactID:      uuidString(scan.ContactID),
+		Entries:        entries,
+		afterPost: func(w http.ResponseWriter, r *http.Request, q *db.Queries, t db.Transaction) bool {
+			return applyReceiptPosting(w, r, q, org, scan.ID, t)
+		},
+	})
+}
+
+// receiptEntries builds the entries posting a draft receipt, filling in
+// what the request leaves out from the scan.
+func receiptEntries(req postReceiptRequest, scan db.ReceiptScan) ([]ledgerEntryRequest, validationErrors) {
+	var errs validationErrors
+	if _, err := parseUUID(req.PaymentAccountID); err != nil {
+		errs.add("payment_account_id", CodeInvalidFormat, "invalid payment_account_id")
+	}
+	accountID := req.AccountID
+	if accountID == "" {
+		accountID = uuidString(scan.AccountID)
+	}
+	switch _, err := parseUUID(accountID); {
+	case accountID == "":
+		errs.add("account_id", CodeRequired, "no account was suggested for the receipt; give account_id")
+	case err != nil:
+		errs.add("account_id", CodeInvalidFormat, "invalid account_id")
+	}
+	if req.TaxAccountID != "" {
+		if _, err := parseUUID(req.TaxAccountID); err != nil {
+			errs.add("tax_account_id", CodeInvalidFormat, "invalid tax_account_id")
+		}
+	}
+
+	var total int64
+	switch {
+	case req.Total != nil:
+		total = *req.Total
+	case scan.TotalMinor.Valid:
+		total = scan.TotalMinor.Int64
+	default:
+		errs.add("total", CodeRequired, "no total was read from the receipt; give total")
+	}
+	if total < 0 || (total == 0 && (req.Total != nil || scan.TotalMinor.Valid)) {
+		errs.add("total", CodeOutOfRange, "total must be positive")
+	}
+	var gst int64
+	if req.TaxAccountID != "" {
+		switch {
+		case req.GST != nil:
+			gst = *req.GST
+		case scan.GstMinor.Valid:
+			gst = scan.GstMinor.Int64
+		}
+		if gst < 0 || (total > 0 && gst >= total) {
+			errs.add("gst", CodeOutOfRange, "gst must be at least 0 and less than the total")
+		}
+	}
+	if !errs.empty() {
+		return nil, errs
+	}
+
+	entries := []ledgerEntryRequest{{AccountID: accountID, Amount: total - gst}}
+	if gst > 0 {
+		entries = append(entries, ledgerEntryRequest{AccountID: req.TaxAccountID, Amount: gst})
+	}
+	return append(entries, ledgerEntryRequest{AccountID: req.PaymentAccountID, Amount: -total}), nil
+}
+
+// applyReceiptPosting files the receipt on transaction t, once its scan is
+// locked and shown to still be a draft. It writes the error response itself
+// and reports whether to carry on.
+func applyReceiptPosting(w http.ResponseWriter, r *http.Request, q *db.Queries, org *tenant, scanID pgtype.UUID, t db.Transaction) bool {
+	scan, err := q.GetReceiptScanForUpdate(r.Context(), db.GetReceiptScanForUpdateParams{OrganisationID: org.id, ID: scanID})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to get receipt")
+		return false
+	}
+	if scan.Outcome.String != receiptDraft {
+		writeError(w, http.StatusConflict, CodeInvalidReceiptState, "only draft receipts can be posted")
+		return false
+	}
+	before, err := q.GetAttachmentByID(r.Context(), db.GetAttachmentByIDParams{OrganisationID: org.id, ID: scan.AttachmentID})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to get attachment")
+		return false
+	}
+	after, err := q.SetAttachmentTransaction(r.Context(), db.SetAttachmentTransactionParams{OrganisationID: org.id, ID: before.ID, TransactionID: t.ID})
+	if errors.Is(err, pgx.ErrNoRows) {
+		writeError(w, http.StatusConflict, CodeInvalidReceiptState, "receipt is already filed on a transaction")
+		return false
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to file receipt")
+		return false
+	}
+	if _, err := q.SetReceiptScanPosted(r.Context(), db.SetReceiptScanPostedParams{OrganisationID: org.id, ID: scan.ID, TransactionID: t.ID}); err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to update receipt")
+		return false
+	}
+	if err := recordAudit(r.Context(), q, org, audit.EntityAttachment, after.ID, audit.ActionUpdate, toAttachmentResponse(before), toAttachmentResponse(after)); err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to record audit event")
+		return false
+	}
+	return true
+}
+
+// Helpers
+
+// loadReceipt fetches the receipt scan named by the URL and its attachment,
+// writing the error response and returning false when it cannot.
+func loadReceipt(w http.ResponseWriter, r *http.Request, q *db.Queries, forUpdate bool) (db.ReceiptScan, db.Attachment, bool) {
+	id, err := parseUUID(chi.URLParam(r, "id"))
+	if err != nil {
+		writeFieldError(w, http.StatusBadRequest, CodeInvalidFormat, "id", "invalid id")
+		return db.ReceiptScan{}, db.Attachment{}, false
+	}
+
+	org := tenantFrom(r.Context())
+	var scan db.ReceiptScan
+	if forUpdate {
+		scan, err = q.GetReceiptScanForUpdate(r.Context(), db.GetReceiptScanForUpdateParams{OrganisationID: org.id, ID: id})
+	} else {
+		scan, err = q.GetReceiptScan(r.Context(), db.GetReceiptScanParams{OrganisationID: org.id, ID: id})
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		writeError(w, http.StatusNotFound, CodeNotFound, "receipt not found")
+		return db.ReceiptScan{}, db.Attachment{}, false
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to get receipt")
+		return db.ReceiptScan{}, db.Attachment{}, false
+	}
+	a, err := q.GetAttachmentByID(r.Context(), db.GetAttachmentByIDParams{OrganisationID: org.id, ID: scan.AttachmentID})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to get attachment")
+		return db.ReceiptScan{}, db.Attachment{}, false
+	}
+	return scan, a, true
+}
+
+func toReceiptResponse(scan db.ReceiptScan, a db.Attachment) receiptResponse {
+	resp := receiptResponse{
+		ID:              uuidString(scan.ID),
+		Attachment:      toAttachmentResponse(a),
+		Status:          scan.Status,
+		Attempts:        scan.Attempts,
+		Error:           scan.Error,
+		Outcome:         scan.Outcome.String,
+		Text:            scan.Text,
+		StatementLineID: uuidString(scan.StatementLineID),
+		TransactionID:   uuidString(scan.TransactionID),
+		ContactID:       uuidString(scan.ContactID),
+		AccountID:       uuidString(scan.AccountID),
+		CreatedAt:       scan.CreatedAt.Time.Format(time.RFC3339Nano),
+		UpdatedAt:       scan.UpdatedAt.Time.Format(time.RFC3339Nano),
+	}
+	if scan.Merchant.Valid {
+		resp.Merchant = &extractedText{Value: scan.Merchant.String, Confidence: scan.MerchantConfidence.Float64}
+	}
+	if scan.ReceiptDate.Valid {
+		resp.Date = &extractedDate{Value: scan.ReceiptDate.Time.Format(time.DateOnly), Confidence: scan.DateConfidence.Float64}
+	}
+	if scan.TotalMinor.Valid {
+		resp.Total = &extractedAmount{Value: scan.TotalMinor.Int64, Confidence: scan.TotalConfidence.Float64}
+	}
+	if scan.GstMinor.Valid {
+		resp.GST = &extractedAmount{Value: scan.GstMinor.Int64, Confidence: scan.GstConfidence.Float64}
+	}
+	return resp
+}
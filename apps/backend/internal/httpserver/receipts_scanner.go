@@ -0,0 +1,284 @@
+package httpserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	db "github.com/LBaronceli/go-figure/internal/db/sqlc"
+	"github.com/LBaronceli/go-figure/internal/ocr"
+	"github.com/LBaronceli/go-figure/internal/receipts"
+	"github.com/LBaronceli/go-figure/internal/reconcile"
+	"github.com/LBaronceli/go-figure/internal/storage"
+)
+
+const (
+	// receiptScanAttempts is how many times a scan runs before it is
+	// marked failed.
+	receiptScanAttempts = 3
+	// receiptScanTimeout bounds one run of the OCR engine.
+	receiptScanTimeout = 2 * time.Minute
+	// receiptScanStaleAfter is how long a running scan may go without
+	// finishing before another worker takes it over.
+	receiptScanStaleAfter = 10 * time.Minute
+)
+
+// ScanNextReceipt claims the next due receipt scan in any organisation and
+// runs it. It reports whether there was one; a scan that fails is queued
+// again with a backoff, or marked failed once out of attempts, and its error
+// returned. Scans that went stale on their last attempt are marked failed
+// first.
+//
+// Scans run without a user, so the changes they make are not audited.
+func (s *Server) ScanNextReceipt(ctx context.Context) (bool, error) {
+	if s.ocr == nil || s.storage == nil {
+		return false, nil
+	}
+	var scan db.ReceiptScan
+	err := s.withoutRLS(ctx, func(q *db.Queries) error {
+		staleBefore := pgtype.Timestamptz{Time: time.Now().Add(-receiptScanStaleAfter), Valid: true}
+		// a scan whose worker keeps dying, say on a receipt that crashes the
+		// engine, is not taken over again once out of attempts
+		failed, err := q.FailStaleReceiptScans(ctx, db.FailStaleReceiptScansParams{StaleBefore: staleBefore, MaxAttempts: receiptScanAttempts})
+		if err != nil {
+			return err
+		}
+		if failed > 0 {
+			slog.Warn("receipt scanner gave up on stalled scans", "count", failed)
+		}
+		scan, err = q.ClaimReceiptScan(ctx, db.ClaimReceiptScanParams{StaleBefore: staleBefore, MaxAttempts: receiptScanAttempts})
+		return err
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	scanErr := s.scanReceipt(ctx, scan)
+	if scanErr == nil {
+		return true, nil
+	}
+	// A scan cut short by shutdown goes straight back on the queue, so the
+	// next worker need not wait for it to go stale.
+	interrupted := ctx.Err() != nil
+	saveCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+	defer cancel()
+	err = s.withoutRLS(saveCtx, func(q *db.Queries) error {
+		if !interrupted && (scan.Attempts >= receiptScanAttempts || errors.Is(scanErr, ocr.ErrUnsupported)) {
+			return q.FailReceiptScan(saveCtx, db.FailReceiptScanParams{OrganisationID: scan.OrganisationID, ID: scan.ID, Error: scanErr.Error()})
+		}
+		backoff := time.Duration(scan.Attempts*scan.Attempts) * time.Minute
+		if interrupted {
+			backoff = 0
+		}
+		return q.RetryReceiptScan(saveCtx, db.RetryReceiptScanParams{
+			OrganisationID: scan.OrganisationID,
+			ID:             scan.ID,
+			Error:          scanErr.Error(),
+			RunAfter:       pgtype.Timestamptz{Time: time.Now().Add(backoff), Valid: true},
+		})
+	})
+	return true, errors.Join(fmt.Errorf("receipt scan %s: %w", uuidString(scan.ID), scanErr), err)
+}
+
+// RunReceiptScanner works through the receipt scan queue until ctx is done,
+// looking for new scans every interval once the queue is empty. Workers in
+// several processes share the queue safely.
+func (s *Server) RunReceiptScanner(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		for ctx.Err() == nil {
+			found, err := s.ScanNextReceipt(ctx)
+			if err != nil {
+				slog.Error("receipt scanner", "err", err)
+			}
+			if !found {
+				break
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// scanReceipt reads the scan's attachment, extracts its fields and files
+// it. The extraction runs outside any database transaction; the result is
+// saved only if the scan is still this worker's.
+func (s *Server) scanReceipt(ctx context.Context, scan db.ReceiptScan) error {
+	var a db.Attachment
+	err := s.withoutRLS(ctx, func(q *db.Queries) error {
+		var err error
+		a, err = q.GetAttachmentByID(ctx, db.GetAttachmentByIDParams{OrganisationID: scan.OrganisationID, ID: scan.AttachmentID})
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("get attachment: %w", err)
+	}
+	content, err := s.storage.Get(ctx, storage.Key(uuidString(a.OrganisationID), a.Sha256))
+	if err != nil {
+		return fmt.Errorf("read attachment: %w", err)
+	}
+	data, err := io.ReadAll(io.LimitReader(content, maxAttachmentBytes))
+	content.Close()
+	if err != nil {
+		return fmt.Errorf("read attachment: %w", err)
+	}
+
+	ocrCtx, cancel := context.WithTimeout(ctx, receiptScanTimeout)
+	defer cancel()
+	ext, err := receipts.Scan(ocrCtx, s.ocr, data, a.ContentType)
+	if err != nil {
+		return err
+	}
+
+	return s.withoutRLS(ctx, func(q *db.Queries) error {
+		current, err := q.GetReceiptScanForUpdate(ctx, db.GetReceiptScanForUpdateParams{OrganisationID: scan.OrganisationID, ID: scan.ID})
+		if err != nil {
+			return err
+		}
+		// requeued, or taken over after this worker went stale
+		if current.Status != receiptRunning || current.Attempts != scan.Attempts {
+			return nil
+		}
+		// the attachment may have been filed while the engine ran
+		a, err := q.GetAttachmentByID(ctx, db.GetAttachmentByIDParams{OrganisationID: scan.OrganisationID, ID: scan.AttachmentID})
+		if err != nil {
+			return err
+		}
+		params := completeReceiptScanParams(scan, ext)
+		if err := fileReceipt(ctx, q, a, scan.ID, ext, &params); err != nil {
+			return err
+		}
+		_, err = q.CompleteReceiptScan(ctx, params)
+		return err
+	})
+}
+
+// fileReceipt decides what a scanned receipt is evidence of: the
+// transaction it is already attached to, an imported bank line paying its
+// total near its date, or otherwise a draft for a new transaction, with the
+// contact whose alias matches the merchant suggested.
+func fileReceipt(ctx context.Context, q *db.Queries, a db.Attachment, scanID pgtype.UUID, ext receipts.Extraction, p *db.CompleteReceiptScanParams) error {
+	if a.TransactionID.Valid {
+		p.Outcome = pgtype.Text{String: receiptAttached, Valid: true}
+		p.TransactionID = a.TransactionID
+		return nil
+	}
+
+	line, found, err := matchReceiptLine(ctx, q, a.OrganisationID, scanID, ext)
+	if err != nil {
+		return err
+	}
+	if found {
+		p.Outcome = pgtype.Text{String: receiptMatched, Valid: true}
+		p.StatementLineID = line.ID
+		if !line.TransactionID.Valid {
+			return nil
+		}
+		p.TransactionID = line.TransactionID
+		// a transaction that already has the file keeps its copy, and this
+		// one stays in the inbox
+		_, err := q.GetAttachmentByContent(ctx, db.GetAttachmentByContentParams{OrganisationID: a.OrganisationID, TransactionID: line.TransactionID, Sha256: a.Sha256})
+		if err == nil {
+			return nil
+		}
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return err
+		}
+		_, err = q.SetAttachmentTransaction(ctx, db.SetAttachmentTransactionParams{OrganisationID: a.OrganisationID, ID: a.ID, TransactionID: line.TransactionID})
+		return err
+	}
+
+	p.Outcome = pgtype.Text{String: receiptDraft, Valid: true}
+	if ext.Merchant == nil {
+		return nil
+	}
+	aliases, err := q.ListContactAliases(ctx, db.ListContactAliasesParams{OrganisationID: a.OrganisationID})
+	if err != nil {
+		return err
+	}
+	contactID := matchContactAlias(toAliases(aliases), ext.Merchant.Value)
+	if !contactID.Valid {
+		return nil
+	}
+	c, err := q.GetContact(ctx, db.GetContactParams{OrganisationID: a.OrganisationID, ID: contactID})
+	if err != nil {
+		return err
+	}
+	p.ContactID, p.AccountID = c.ID, c.DefaultAccountID
+	return nil
+}
+
+// matchReceiptLine finds the imported statement line that best matches the
+// receipt: money out for its total within the reconciliation date window,
+// scored like reconciliation suggestions on date and merchant.
+func matchReceiptLine(ctx context.Context, q *db.Queries, orgID, scanID pgtype.UUID, ext receipts.Extraction) (db.ListReceiptMatchCandidatesRow, bool, error) {
+	if ext.Total == nil || ext.Date == nil || ext.Total.Value <= 0 {
+		return db.ListReceiptMatchCandidatesRow{}, false, nil
+	}
+	window := reconcile.DefaultOptions.DateWindow
+	rows, err := q.ListReceiptMatchCandidates(ctx, db.ListReceiptMatchCandidatesParams{
+		OrganisationID: orgID,
+		AmountMinor:    -ext.Total.Value,
+		FromDate:       pgtype.Date{Time: ext.Date.Value.AddDate(0, 0, -window), Valid: true},
+		ToDate:         pgtype.Date{Time: ext.Date.Value.AddDate(0, 0, window), Valid: true},
+	})
+	if err != nil || len(rows) == 0 {
+		return db.ListReceiptMatchCandidatesRow{}, false, err
+	}
+
+	lines := make([]reconcile.Line, len(rows))
+	for i, row := range rows {
+		lines[i] = reconcile.Line{ID: row.ID.Bytes, PostedOn: row.PostedOn.Time, Amount: row.AmountMinor, Description: row.Description}
+	}
+	entry := reconcile.Entry{ID: scanID.Bytes, PostedOn: ext.Date.Value, Amount: -ext.Total.Value}
+	if ext.Merchant != nil {
+		entry.Description = ext.Merchant.Value
+	}
+	matches := reconcile.AutoMatch(lines, []reconcile.Entry{entry}, reconcile.DefaultOptions)
+	if len(matches) == 0 {
+		return db.ListReceiptMatchCandidatesRow{}, false, nil
+	}
+	for _, row := range rows {
+		if row.ID.Bytes == matches[0].LineID {
+			return row, true, nil
+		}
+	}
+	return db.ListReceiptMatchCandidatesRow{}, false, nil
+}
+
+// completeReceiptScanParams records the extracted fields; fileReceipt adds
+// the outcome.
+func completeReceiptScanParams(scan db.ReceiptScan, ext receipts.Extraction) db.CompleteReceiptScanParams {
+	p := db.CompleteReceiptScanParams{OrganisationID: scan.OrganisationID, ID: scan.ID, Text: ext.Text}
+	if f := ext.Merchant; f != nil {
+		p.Merchant = pgtype.Text{String: f.Value, Valid: true}
+		p.MerchantConfidence = pgtype.Float8{Float64: f.Confidence, Valid: true}
+	}
+	if f := ext.Date; f != nil {
+		p.ReceiptDate = pgtype.Date{Time: f.Value, Valid: true}
+		p.DateConfidence = pgtype.Float8{Float64: f.Confidence, Valid: true}
+	}
+	if f := ext.Total; f != nil {
+		p.TotalMinor = pgtype.Int8{Int64: f.Value, Valid: true}
+		p.TotalConfidence = pgtype.Float8{Float64: f.Confidence, Valid: true}
+	}
+	if f := ext.GST; f != nil {
+		p.GstMinor = pgtype.Int8{Int64: f.Value, Valid: true}
+		p.GstConfidence = pgtype.Float8{Float64: f.Confidence, Valid: true}
+	}
+	return p
+}
@@ -0,0 +1,129 @@
+package httpserver
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+
+	db "github.com/LBaronceli/go-figure/internal/db/sqlc"
+	"github.com/LBaronceli/go-figure/internal/ocr"
+	"github.com/LBaronceli/go-figure/internal/receipts"
+)
+
+func newUUID() pgtype.UUID {
+	return pgtype.UUID{Bytes: uuid.New(), Valid: true}
+}
+
+func draftScan() db.ReceiptScan {
+	return db.ReceiptScan{
+		ID:          newUUID(),
+		Status:      receiptDone,
+		Outcome:     pgtype.Text{String: receiptDraft, Valid: true},
+		Merchant:    pgtype.Text{String: "COUNTDOWN PONSONBY", Valid: true},
+		ReceiptDate: pgtype.Date{Time: time.Date(2026, 5, 14, 0, 0, 0, 0, time.UTC), Valid: true},
+		TotalMinor:  pgtype.Int8{Int64: 8450, Valid: true},
+		GstMinor:    pgtype.Int8{Int64: 1102, Valid: true},
+		AccountID:   newUUID(),
+	}
+}
+
+func TestCompleteReceiptScanParams(t *testing.T) {
+	ext := receipts.Extract(ocr.Result{Lines: []ocr.Line{
+		{Text: "COUNTDOWN PONSONBY", Confidence: 1},
+		{Text: "14/05/2026", Confidence: 1},
+		{Text: "TOTAL 84.50", Confidence: 1},
+	}})
+	scan := db.ReceiptScan{ID: newUUID(), OrganisationID: newUUID()}
+	p := completeReceiptScanParams(scan, ext)
+
+	require.Equal(t, scan.ID, p.ID)
+	require.Equal(t, pgtype.Text{String: "COUNTDOWN PONSONBY", Valid: true}, p.Merchant)
+	require.Equal(t, 0.9, p.MerchantConfidence.Float64)
+	require.Equal(t, time.Date(2026, 5, 14, 0, 0, 0, 0, time.UTC), p.ReceiptDate.Time)
+	require.Equal(t, pgtype.Int8{Int64: 8450, Valid: true}, p.TotalMinor)
+	// nothing was read for GST, so it stays null rather than zero
+	require.False(t, p.GstMinor.Valid)
+	require.False(t, p.GstConfidence.Valid)
+	require.False(t, p.Outcome.Valid)
+}
+
+func TestToReceiptResponse(t *testing.T) {
+	scan := draftScan()
+	scan.MerchantConfidence = pgtype.Float8{Float64: 0.81, Valid: true}
+	scan.TotalConfidence = pgtype.Float8{Float64: 0.9, Valid: true}
+	resp := toReceiptResponse(scan, db.Attachment{ID: newUUID(), Filename: "receipt.jpg"})
+
+	require.Equal(t, &extractedText{Value: "COUNTDOWN PONSONBY", Confidence: 0.81}, resp.Merchant)
+	require.Equal(t, "2026-05-14", resp.Date.Value)
+	require.Equal(t, &extractedAmount{Value: 8450, Confidence: 0.9}, resp.Total)
+	require.Equal(t, receiptDraft, resp.Outcome)
+
+	// an inbox attachment has no transaction to report
+	body, err := json.Marshal(resp)
+	require.NoError(t, err)
+	require.NotContains(t, string(body), `"transaction_id"`)
+	require.NotContains(t, string(body), `"statement_line_id"`)
+}
+
+func TestReceiptEntries(t *testing.T) {
+	scan := draftScan()
+	bank, gst := uuidString(newUUID()), uuidString(newUUID())
+
+	// GST stays in the expense without a tax account
+	entries, errs := receiptEntries(postReceiptRequest{PaymentAccountID: bank}, scan)
+	require.Empty(t, errs)
+	require.Equal(t, []ledgerEntryRequest{
+		{AccountID: uuidString(scan.AccountID), Amount: 8450},
+		{AccountID: bank, Amount: -8450},
+	}, entries)
+
+	entries, errs = receiptEntries(postReceiptRequest{PaymentAccountID: bank, TaxAccountID: gst}, scan)
+	require.Empty(t, errs)
+	require.Equal(t, []ledgerEntryRequest{
+		{AccountID: uuidString(scan.AccountID), Amount: 7348},
+		{AccountID: gst, Amount: 1102},
+		{AccountID: bank, Amount: -8450},
+	}, entries)
+
+	// corrections override what was read
+	total, zero := int64(9000), int64(0)
+	entries, errs = receiptEntries(postReceiptRequest{PaymentAccountID: bank, TaxAccountID: gst, Total: &total, GST: &zero}, scan)
+	require.Empty(t, errs)
+	require.Equal(t, []ledgerEntryRequest{
+		{AccountID: uuidString(scan.AccountID), Amount: 9000},
+		{AccountID: bank, Amount: -9000},
+	}, entries)
+}
+
+func TestReceiptEntriesRejects(t *testing.T) {
+	bank := uuidString(newUUID())
+	negative, tooMuch := int64(-1), int64(8450)
+	unread := draftScan()
+	unread.TotalMinor, unread.AccountID = pgtype.Int8{}, pgtype.UUID{}
+
+	tests := []struct {
+		name  string
+		req   postReceiptRequest
+		scan  db.ReceiptScan
+		field string
+		code  ErrorCode
+	}{
+		{"bad payment account", postReceiptRequest{PaymentAccountID: "nope"}, draftScan(), "payment_account_id", CodeInvalidFormat},
+		{"no total read", postReceiptRequest{PaymentAccountID: bank, AccountID: bank}, unread, "total", CodeRequired},
+		{"no account suggested", postReceiptRequest{PaymentAccountID: bank, Total: &tooMuch}, unread, "account_id", CodeRequired},
+		{"negative total", postReceiptRequest{PaymentAccountID: bank, Total: &negative}, draftScan(), "total", CodeOutOfRange},
+		{"gst not below total", postReceiptRequest{PaymentAccountID: bank, TaxAccountID: bank, GST: &tooMuch}, draftScan(), "gst", CodeOutOfRange},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, errs := receiptEntries(tt.req, tt.scan)
+			require.Len(t, errs, 1)
+			require.Equal(t, tt.field, errs[0].Field)
+			require.Equal(t, tt.code, errs[0].Code)
+		})
+	}
+}
@@ -0,0 +1,752 @@
+package httpserver
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/LBaronceli/go-figure/internal/auth"
+	db "github.com/LBaronceli/go-figure/internal/db/sqlc"
+	"github.com/LBaronceli/go-figure/internal/models"
+	"github.com/LBaronceli/go-figure/internal/reconcile"
+)
+
+const maxStatementLines = 1000
+
+// Statement line match states.
+const (
+	matchUnmatched = "unmatched"
+	matchSuggested = "suggested"
+	matchMatched   = "matched"
+)
+
+// Reconciliation states.
+const (
+	reconciliationOpen      = "open"
+	reconciliationCompleted = "completed"
+)
+
+var reconciliationFilters = []apiParam{
+	{name: "account_id", typ: "string", format: "uuid", desc: "Only reconciliations of this account"},
+}
+
+type createReconciliationRequest struct {
+	AccountID      string `json:"account_id" openapi:"format=uuid" doc:"Bank or card account the statement belongs to"`
+	StartsOn       string `json:"starts_on" openapi:"format=date"`
+	EndsOn         string `json:"ends_on" openapi:"format=date" doc:"Statement date, inclusive"`
+	ClosingBalance int64  `json:"closing_balance" doc:"Statement closing balance in minor units"`
+	OpeningBalance *int64 `json:"opening_balance" openapi:"optional" doc:"Statement opening balance in minor units; defaults to the closing balance of the account's previous statement, or 0 for its first"`
+}
+
+type statementLineRequest struct {
+	PostedOn    string `json:"posted_on" openapi:"format=date"`
+	Amount      int64  `json:"amount" doc:"Minor units; deposits positive, withdrawals negative"`
+	Description string `json:"description" openapi:"optional,maxLength=500"`
+	Reference   string `json:"reference" openapi:"optional,maxLength=500"`
+}
+
+type importStatementLinesRequest struct {
+	Lines []statementLineRequest `json:"lines" openapi:"minItems=1,maxItems=1000"`
+}
+
+type matchStatementLineRequest struct {
+	LedgerEntryID string `json:"ledger_entry_id" openapi:"format=uuid"`
+}
+
+type reconciliationResponse struct {
+	ID             string `json:"id" openapi:"format=uuid"`
+	AccountID      string `json:"account_id" openapi:"format=uuid"`
+	StartsOn       string `json:"starts_on" openapi:"format=date"`
+	EndsOn         string `json:"ends_on" openapi:"format=date"`
+	OpeningBalance int64  `json:"opening_balance"`
+	ClosingBalance int64  `json:"closing_balance"`
+	Status         string `json:"status" openapi:"enum=open|completed"`
+	CreatedBy      string `json:"created_by" openapi:"format=uuid"`
+	CreatedAt      string `json:"created_at" openapi:"format=date-time"`
+	CompletedAt    string `json:"completed_at,omitempty" openapi:"format=date-time"`
+}
+
+type statementLineResponse struct {
+	ID            string   `json:"id" openapi:"format=uuid"`
+	PostedOn      string   `json:"posted_on" openapi:"format=date"`
+	Amount        int64    `json:"amount"`
+	Description   string   `json:"description"`
+	Reference     string   `json:"reference"`
+	MatchStatus   string   `json:"match_status" openapi:"enum=unmatched|suggested|matched"`
+	LedgerEntryID string   `json:"ledger_entry_id,omitempty" openapi:"format=uuid"`
+	MatchScore    *float64 `json:"match_score,omitempty" doc:"Auto-matcher confidence from 0 to 1"`
+}
+
+type reconciliationEntryResponse struct {
+	LedgerEntryID string `json:"ledger_entry_id" openapi:"format=uuid"`
+	PostedOn      string `json:"posted_on" openapi:"format=date"`
+	Amount        int64  `json:"amount"`
+	Description   string `json:"description"`
+}
+
+type reconciliationReportResponse struct {
+	Reconciliation          reconciliationResponse        `json:"reconciliation"`
+	StatementClosingBalance int64                         `json:"statement_closing_balance"`
+	ClearedBalance          int64                         `json:"cleared_balance" doc:"The statement's opening balance plus the ledger entries matched to its lines"`
+	Difference              int64                         `json:"difference" doc:"statement_closing_balance minus cleared_balance"`
+	UnreconciledLines       []statementLineResponse       `json:"unreconciled_lines" doc:"Statement lines without an accepted match"`
+	UnclearedEntries        []reconciliationEntryResponse `json:"uncleared_entries" doc:"Ledger entries up to the statement date without an accepted match"`
+}
+
+// POST /reconciliations
+func (s *Server) createReconciliation(w http.ResponseWriter, r *http.Request) {
+	var req createReconciliationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, CodeInvalidJSON, "invalid json")
+		return
+	}
+
+	var errs validationErrors
+	accountID, err := parseUUID(req.AccountID)
+	if err != nil {
+		errs.add("account_id", CodeInvalidFormat, "invalid account_id")
+	}
+	startsOn, err := parseDate(req.StartsOn)
+	if err != nil {
+		errs.add("starts_on", CodeInvalidFormat, "invalid starts_on (use YYYY-MM-DD)")
+	}
+	endsOn, err := parseDate(req.EndsOn)
+	if err != nil {
+		errs.add("ends_on", CodeInvalidFormat, "invalid ends_on (use YYYY-MM-DD)")
+	}
+	if errs.empty() && endsOn.Before(startsOn) {
+		errs.add("ends_on", CodeOutOfRange, "ends_on must not be before starts_on")
+	}
+	if !errs.empty() {
+		writeValidationErrors(w, errs)
+		return
+	}
+
+	org := tenantFrom(r.Context())
+	acc, err := org.q.GetAccount(r.Context(), db.GetAccountParams{OrganisationID: org.id, ID: accountID})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			writeFieldError(w, http.StatusBadRequest, CodeAccountNotFound, "account_id", "account not found")
+			return
+		}
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to get account")
+		return
+	}
+	// statements come from banks and card issuers
+	if t := models.AccountType(acc.Type); t != models.AccountTypeAsset && t != models.AccountTypeLiability {
+		writeFieldError(w, http.StatusBadRequest, CodeInvalidValue, "account_id", "only asset and liability accounts can be reconciled")
+		return
+	}
+
+	// a statement opens where the account's previous one closed
+	var opening int64
+	if req.OpeningBalance != nil {
+		opening = *req.OpeningBalance
+	} else {
+		opening, err = org.q.GetPreviousClosingBalance(r.Context(), db.GetPreviousClosingBalanceParams{
+			OrganisationID: org.id,
+			AccountID:      accountID,
+			StartsOn:       pgtype.Date{Time: startsOn, Valid: true},
+		})
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			writeError(w, http.StatusInternalServerError, CodeInternal, "failed to get previous statement")
+			return
+		}
+	}
+
+	p, _ := auth.PrincipalFrom(r.Context())
+	rec, err := org.q.CreateReconciliation(r.Context(), db.CreateReconciliationParams{
+		OrganisationID:      org.id,
+		AccountID:           accountID,
+		StartsOn:            pgtype.Date{Time: startsOn, Valid: true},
+		EndsOn:              pgtype.Date{Time: endsOn, Valid: true},
+		ClosingBalanceMinor: req.ClosingBalance,
+		CreatedBy:           pgtype.UUID{Bytes: p.UserID, Valid: true},
+		OpeningBalanceMinor: opening,
+	})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to create reconciliation")
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, toReconciliationResponse(rec))
+}
+
+// GET /reconciliations
+func (s *Server) listReconciliations(w http.ResponseWriter, r *http.Request) {
+	var accountID pgtype.UUID
+	if v := r.URL.Query().Get("account_id"); v != "" {
+		id, err := parseUUID(v)
+		if err != nil {
+			writeFieldError(w, http.StatusBadRequest, CodeInvalidFormat, "account_id", "invalid account_id")
+			return
+		}
+		accountID = id
+	}
+
+	org := tenantFrom(r.Context())
+	recs, err := org.q.ListReconciliations(r.Context(), db.ListReconciliationsParams{OrganisationID: org.id, AccountID: accountID})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to list reconciliations")
+		return
+	}
+
+	resp := make([]reconciliationResponse, 0, len(recs))
+	for _, rec := range recs {
+		resp = append(resp, toReconciliationResponse(rec))
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// GET /reconciliations/{id}
+func (s *Server) getReconciliation(w http.ResponseWriter, r *http.Request) {
+	rec, ok := s.loadReconciliation(w, r, tenantFrom(r.Context()).q, false)
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, toReconciliationResponse(rec))
+}
+
+// POST /reconciliations/{id}/lines
+func (s *Server) importStatementLines(w http.ResponseWriter, r *http.Request) {
+	var req importStatementLinesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, CodeInvalidJSON, "invalid json")
+		return
+	}
+
+	var errs validationErrors
+	if len(req.Lines) == 0 {
+		errs.add("lines", CodeTooFew, "at least one line is required")
+	}
+	if len(req.Lines) > maxStatementLines {
+		errs.add("lines", CodeTooMany, fmt.Sprintf("too many lines (max %d)", maxStatementLines))
+	}
+	dates := make([]time.Time, len(req.Lines))
+	for i := range req.Lines {
+		l := &req.Lines[i]
+		l.Description = strings.TrimSpace(l.Description)
+		l.Reference = strings.TrimSpace(l.Reference)
+		d, err := parseDate(l.PostedOn)
+		if err != nil {
+			errs.add(lineField(i, "posted_on"), CodeInvalidFormat, "invalid posted_on (use YYYY-MM-DD)")
+		}
+		dates[i] = d
+		if len(l.Description) > maxStringLength {
+			errs.add(lineField(i, "description"), CodeTooLong, "description too long")
+		}
+		if len(l.Reference) > maxStringLength {
+			errs.add(lineField(i, "reference"), CodeTooLong, "reference too long")
+		}
+	}
+	if !errs.empty() {
+		writeValidationErrors(w, errs)
+		return
+	}
+
+	org := tenantFrom(r.Context())
+	tx, qtx, err := org.begin(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to begin transaction")
+		return
+	}
+	defer tx.Rollback(r.Context())
+
+	rec, ok := s.loadReconciliation(w, r, qtx, true)
+	if !ok {
+		return
+	}
+
+	for i, d := range dates {
+		if d.Before(rec.StartsOn.Time) || d.After(rec.EndsOn.Time) {
+			errs.add(lineField(i, "posted_on"), CodeOutOfRange, "posted_on is outside the statement period")
+		}
+	}
+	if !errs.empty() {
+		writeValidationErrors(w, errs)
+		return
+	}
+
+	resp := make([]statementLineResponse, 0, len(req.Lines))
+	for i, l := range req.Lines {
+		line, err := qtx.CreateStatementLine(r.Context(), db.CreateStatementLineParams{
+			OrganisationID:   org.id,
+			ReconciliationID: rec.ID,
+			PostedOn:         pgtype.Date{Time: dates[i], Valid: true},
+			AmountMinor:      l.Amount,
+			Description:      l.Description,
+			Reference:        l.Reference,
+		})
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, CodeInternal, "failed to import statement line")
+			return
+		}
+		resp = append(resp, toStatementLineResponse(line))
+	}
+
+	if err := tx.Commit(r.Context()); err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to commit transaction")
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, resp)
+}
+
+// GET /reconciliations/{id}/lines
+func (s *Server) listStatementLines(w http.ResponseWriter, r *http.Request) {
+	org := tenantFrom(r.Context())
+	rec, ok := s.loadReconciliation(w, r, org.q, false)
+	if !ok {
+		return
+	}
+
+	lines, err := org.q.ListStatementLines(r.Context(), db.ListStatementLinesParams{OrganisationID: org.id, ReconciliationID: rec.ID})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to list statement lines")
+		return
+	}
+
+	resp := make([]statementLineResponse, 0, len(lines))
+	for _, l := range lines {
+		resp = append(resp, toStatementLineResponse(l))
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// POST /reconciliations/{id}/auto-match
+//
+// Replaces earlier suggestions with fresh ones for every line that has no
+// accepted match. Suggestions still need accepting.
+func (s *Server) autoMatchStatementLines(w http.ResponseWriter, r *http.Request) {
+	org := tenantFrom(r.Context())
+	tx, qtx, err := org.begin(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to begin transaction")
+		return
+	}
+	defer tx.Rollback(r.Context())
+
+	rec, ok := s.loadReconciliation(w, r, qtx, true)
+	if !ok {
+		return
+	}
+
+	if err := qtx.ClearSuggestedMatches(r.Context(), db.ClearSuggestedMatchesParams{OrganisationID: org.id, ReconciliationID: rec.ID}); err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to clear suggestions")
+		return
+	}
+
+	lines, err := qtx.ListStatementLines(r.Context(), db.ListStatementLinesParams{OrganisationID: org.id, ReconciliationID: rec.ID})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to list statement lines")
+		return
+	}
+
+	window := reconcile.DefaultOptions.DateWindow
+	candidates, err := qtx.ListMatchCandidates(r.Context(), db.ListMatchCandidatesParams{
+		OrganisationID: org.id,
+		AccountID:      rec.AccountID,
+		FromDate:       pgtype.Date{Time: rec.StartsOn.Time.AddDate(0, 0, -window), Valid: true},
+		ToDate:         pgtype.Date{Time: rec.EndsOn.Time.AddDate(0, 0, window), Valid: true},
+	})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to list ledger entries")
+		return
+	}
+
+	open := make([]reconcile.Line, 0, len(lines))
+	for _, l := range lines {
+		if l.MatchStatus != matchUnmatched {
+			continue
+		}
+		rejected := make([]uuid.UUID, 0, len(l.RejectedEntryIds))
+		for _, id := range l.RejectedEntryIds {
+			rejected = append(rejected, uuid.UUID(id.Bytes))
+		}
+		open = append(open, reconcile.Line{
+			ID:          uuid.UUID(l.ID.Bytes),
+			PostedOn:    l.PostedOn.Time,
+			Amount:      l.AmountMinor,
+			Description: l.Description,
+			Rejected:    rejected,
+		})
+	}
+	entries := make([]reconcile.Entry, 0, len(candidates))
+	for _, c := range candidates {
+		entries = append(entries, reconcile.Entry{
+			ID:          uuid.UUID(c.ID.Bytes),
+			PostedOn:    c.PostedOn.Time,
+			Amount:      c.AmountMinor,
+			Description: c.Description,
+		})
+	}
+
+	for _, m := range reconcile.AutoMatch(open, entries, reconcile.DefaultOptions) {
+		if _, err := qtx.SetStatementLineMatch(r.Context(), db.SetStatementLineMatchParams{
+			OrganisationID: org.id,
+			ID:             pgtype.UUID{Bytes: m.LineID, Valid: true},
+			MatchStatus:    matchSuggested,
+			LedgerEntryID:  pgtype.UUID{Bytes: m.EntryID, Valid: true},
+			MatchScore:     pgtype.Float8{Float64: m.Score, Valid: true},
+		}); err != nil {
+			writeError(w, http.StatusInternalServerError, CodeInternal, "failed to suggest match")
+			return
+		}
+	}
+
+	lines, err = qtx.ListStatementLines(r.Context(), db.ListStatementLinesParams{OrganisationID: org.id, ReconciliationID: rec.ID})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to list statement lines")
+		return
+	}
+
+	if err := tx.Commit(r.Context()); err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to commit transaction")
+		return
+	}
+
+	resp := make([]statementLineResponse, 0, len(lines))
+	for _, l := range lines {
+		resp = append(resp, toStatementLineResponse(l))
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// POST /reconciliations/{id}/lines/{line_id}/accept
+func (s *Server) acceptStatementLineMatch(w http.ResponseWriter, r *http.Request) {
+	s.changeStatementLine(w, r, func(q *db.Queries, org *tenant, rec db.Reconciliation, line db.StatementLine) (db.StatementLine, bool) {
+		if line.MatchStatus != matchSuggested {
+			writeError(w, http.StatusConflict, CodeInvalidMatchState, "line has no suggested match to accept")
+			return db.StatementLine{}, false
+		}
+		updated, err := q.SetStatementLineMatch(r.Context(), db.SetStatementLineMatchParams{
+			OrganisationID: org.id,
+			ID:             line.ID,
+			MatchStatus:    matchMatched,
+			LedgerEntryID:  line.LedgerEntryID,
+			MatchScore:     line.MatchScore,
+		})
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, CodeInternal, "failed to accept match")
+			return db.StatementLine{}, false
+		}
+		return updated, true
+	})
+}
+
+// POST /reconciliations/{id}/lines/{line_id}/reject
+//
+// Undoes a suggested or accepted match. The auto-matcher will not propose
+// the same entry for this line again.
+func (s *Server) rejectStatementLineMatch(w http.ResponseWriter, r *http.Request) {
+	s.changeStatementLine(w, r, func(q *db.Queries, org *tenant, rec db.Reconciliation, line db.StatementLine) (db.StatementLine, bool) {
+		if line.MatchStatus == matchUnmatched {
+			writeError(w, http.StatusConflict, CodeInvalidMatchState, "line has no match to reject")
+			return db.StatementLine{}, false
+		}
+		updated, err := q.RejectStatementLineMatch(r.Context(), db.RejectStatementLineMatchParams{OrganisationID: org.id, ID: line.ID})
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, CodeInternal, "failed to reject match")
+			return db.StatementLine{}, false
+		}
+		return updated, true
+	})
+}
+
+// POST /reconciliations/{id}/lines/{line_id}/match
+func (s *Server) matchStatementLine(w http.ResponseWriter, r *http.Request) {
+	var req matchStatementLineRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, CodeInvalidJSON, "invalid json")
+		return
+	}
+	entryID, err := parseUUID(req.LedgerEntryID)
+	if err != nil {
+		writeFieldError(w, http.StatusBadRequest, CodeInvalidFormat, "ledger_entry_id", "invalid ledger_entry_id")
+		return
+	}
+
+	s.changeStatementLine(w, r, func(q *db.Queries, org *tenant, rec db.Reconciliation, line db.StatementLine) (db.StatementLine, bool) {
+		if line.MatchStatus == matchMatched {
+			writeError(w, http.StatusConflict, CodeInvalidMatchState, "line is already matched; reject the match first")
+			return db.StatementLine{}, false
+		}
+
+		entry, err := q.GetAccountLedgerEntry(r.Context(), db.GetAccountLedgerEntryParams{
+			OrganisationID: org.id,
+			AccountID:      rec.AccountID,
+			ID:             entryID,
+		})
+		if errors.Is(err, pgx.ErrNoRows) {
+			writeFieldError(w, http.StatusBadRequest, CodeNotFound, "ledger_entry_id", "no ledger entry with this id on the reconciled account")
+			return db.StatementLine{}, false
+		}
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, CodeInternal, "failed to get ledger entry")
+			return db.StatementLine{}, false
+		}
+		if entry.AmountMinor != line.AmountMinor {
+			writeFieldError(w, http.StatusUnprocessableEntity, CodeAmountMismatch, "ledger_entry_id",
+				fmt.Sprintf("entry amount %d does not equal line amount %d", entry.AmountMinor, line.AmountMinor))
+			return db.StatementLine{}, false
+		}
+
+		updated, err := q.SetStatementLineMatch(r.Context(), db.SetStatementLineMatchParams{
+			OrganisationID: org.id,
+			ID:             line.ID,
+			MatchStatus:    matchMatched,
+			LedgerEntryID:  entry.ID,
+		})
+		if err != nil {
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) && pgErr.Code == "23505" { // unique_violation
+				writeFieldError(w, http.StatusConflict, CodeEntryAlreadyMatched, "ledger_entry_id", "entry already clears another statement line")
+				return db.StatementLine{}, false
+			}
+			writeError(w, http.StatusInternalServerError, CodeInternal, "failed to match line")
+			return db.StatementLine{}, false
+		}
+		return updated, true
+	})
+}
+
+// changeStatementLine runs change on one line of an open reconciliation,
+// with the reconciliation locked, and writes the updated line.
+func (s *Server) changeStatementLine(w http.ResponseWriter, r *http.Request, change func(q *db.Queries, org *tenant, rec db.Reconciliation, line db.StatementLine) (db.StatementLine, bool)) {
+	lineID, err := parseUUID(chi.URLParam(r, "line_id"))
+	if err != nil {
+		writeFieldError(w, http.StatusBadRequest, CodeInvalidFormat, "line_id", "invalid line_id")
+		return
+	}
+
+	org := tenantFrom(r.Context())
+	tx, qtx, err := org.begin(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to begin transaction")
+		return
+	}
+	defer tx.Rollback(r.Context())
+
+	rec, ok := s.loadReconciliation(w, r, qtx, true)
+	if !ok {
+		return
+	}
+
+	line, err := qtx.GetStatementLine(r.Context(), db.GetStatementLineParams{OrganisationID: org.id, ReconciliationID: rec.ID, ID: lineID})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			writeError(w, http.StatusNotFound, CodeNotFound, "statement line not found")
+			return
+		}
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to get statement line")
+		return
+	}
+
+	updated, ok := change(qtx, org, rec, line)
+	if !ok {
+		return
+	}
+
+	if err := tx.Commit(r.Context()); err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to commit transaction")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, toStatementLineResponse(updated))
+}
+
+// GET /reconciliations/{id}/report
+func (s *Server) getReconciliationReport(w http.ResponseWriter, r *http.Request) {
+	org := tenantFrom(r.Context())
+	rec, ok := s.loadReconciliation(w, r, org.q, false)
+	if !ok {
+		return
+	}
+
+	report, err := reconciliationReport(r, org.q, org, rec)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to build reconciliation report")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, report)
+}
+
+// POST /reconciliations/{id}/complete
+//
+// A reconciliation completes only when every line has an accepted match and
+// the statement agrees with the cleared balance. It cannot change afterwards.
+func (s *Server) completeReconciliation(w http.ResponseWriter, r *http.Request) {
+	org := tenantFrom(r.Context())
+	tx, qtx, err := org.begin(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to begin transaction")
+		return
+	}
+	defer tx.Rollback(r.Context())
+
+	rec, ok := s.loadReconciliation(w, r, qtx, true)
+	if !ok {
+		return
+	}
+
+	report, err := reconciliationReport(r, qtx, org, rec)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to build reconciliation report")
+		return
+	}
+	if len(report.UnreconciledLines) > 0 {
+		writeError(w, http.StatusConflict, CodeReconciliationUnbalanced,
+			fmt.Sprintf("%d statement lines have no accepted match", len(report.UnreconciledLines)))
+		return
+	}
+	if report.Difference != 0 {
+		writeError(w, http.StatusConflict, CodeReconciliationUnbalanced,
+			fmt.Sprintf("statement closing balance differs from the cleared balance by %d", report.Difference))
+		return
+	}
+
+	rec, err = qtx.CompleteReconciliation(r.Context(), db.CompleteReconciliationParams{OrganisationID: org.id, ID: rec.ID})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to complete reconciliation")
+		return
+	}
+
+	if err := tx.Commit(r.Context()); err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to commit transaction")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, toReconciliationResponse(rec))
+}
+
+// loadReconciliation fetches the {id} reconciliation. With forUpdate it locks
+// the row for the rest of the transaction and refuses completed ones. It
+// writes the error response itself and reports whether to carry on.
+func (s *Server) loadReconciliation(w http.ResponseWriter, r *http.Request, q *db.Queries, forUpdate bool) (db.Reconciliation, bool) {
+	id, err := parseUUID(chi.URLParam(r, "id"))
+	if err != nil {
+		writeFieldError(w, http.StatusBadRequest, CodeInvalidFormat, "id", "invalid id")
+		return db.Reconciliation{}, false
+	}
+
+	org := tenantFrom(r.Context())
+	var rec db.Reconciliation
+	if forUpdate {
+		rec, err = q.GetReconciliationForUpdate(r.Context(), db.GetReconciliationForUpdateParams{OrganisationID: org.id, ID: id})
+	} else {
+		rec, err = q.GetReconciliation(r.Context(), db.GetReconciliationParams{OrganisationID: org.id, ID: id})
+	}
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			writeError(w, http.StatusNotFound, CodeNotFound, "reconciliation not found")
+			return db.Reconciliation{}, false
+		}
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to get reconciliation")
+		return db.Reconciliation{}, false
+	}
+
+	if forUpdate && rec.Status == reconciliationCompleted {
+		writeError(w, http.StatusConflict, CodeReconciliationCompleted, "reconciliation is completed")
+		return db.Reconciliation{}, false
+	}
+	return rec, true
+}
+
+func reconciliationReport(r *http.Request, q *db.Queries, org *tenant, rec db.Reconciliation) (reconciliationReportResponse, error) {
+	cleared, err := q.GetClearedBalance(r.Context(), db.GetClearedBalanceParams{OrganisationID: org.id, ID: rec.ID})
+	if err != nil {
+		return reconciliationReportResponse{}, err
+	}
+
+	lines, err := q.ListStatementLines(r.Context(), db.ListStatementLinesParams{OrganisationID: org.id, ReconciliationID: rec.ID})
+	if err != nil {
+		return reconciliationReportResponse{}, err
+	}
+
+	uncleared, err := q.ListUnclearedEntries(r.Context(), db.ListUnclearedEntriesParams{
+		OrganisationID: org.id,
+		AccountID:      rec.AccountID,
+		EndsOn:         rec.EndsOn,
+	})
+	if err != nil {
+		return reconciliationReportResponse{}, err
+	}
+
+	report := reconciliationReportResponse{
+		Reconciliation:          toReconciliationResponse(rec),
+		StatementClosingBalance: rec.ClosingBalanceMinor,
+		ClearedBalance:          cleared,
+		Difference:              rec.ClosingBalanceMinor - cleared,
+		UnreconciledLines:       []statementLineResponse{},
+		UnclearedEntries:        make([]reconciliationEntryResponse, 0, len(uncleared)),
+	}
+	for _, l := range lines {
+		if l.MatchStatus != matchMatched {
+			report.UnreconciledLines = append(report.UnreconciledLines, toStatementLineResponse(l))
+		}
+	}
+	for _, e := range uncleared {
+		report.UnclearedEntries = append(report.UnclearedEntries, reconciliationEntryResponse{
+			LedgerEntryID: uuid.UUID(e.ID.Bytes).String(),
+			PostedOn:      e.PostedOn.Time.Format(time.DateOnly),
+			Amount:        e.AmountMinor,
+			Description:   e.Description,
+		})
+	}
+	return report, nil
+}
+
+func lineField(i int, name string) string {
+	return fmt.Sprintf("lines[%d].%s", i, name)
+}
+
+func toReconciliationResponse(rec db.Reconciliation) reconciliationResponse {
+	completed := ""
+	if rec.CompletedAt.Valid {
+		completed = rec.CompletedAt.Time.Format(time.RFC3339Nano)
+	}
+	return reconciliationResponse{
+		ID:             uuid.UUID(rec.ID.Bytes).String(),
+		AccountID:      uuid.UUID(rec.AccountID.Bytes).String(),
+		StartsOn:       rec.StartsOn.Time.Format(time.DateOnly),
+		EndsOn:         rec.EndsOn.Time.Format(time.DateOnly),
+		OpeningBalance: rec.OpeningBalanceMinor,
+		ClosingBalance: rec.ClosingBalanceMinor,
+		Status:         rec.Status,
+		CreatedBy:      uuid.UUID(rec.CreatedBy.Bytes).String(),
+		CreatedAt:      rec.CreatedAt.Time.Format(time.RFC3339Nano),
+		CompletedAt:    completed,
+	}
+}
+
+func toStatementLineResponse(l db.StatementLine) statementLineResponse {
+	resp := statementLineResponse{
+		ID:          uuid.UUID(l.ID.Bytes).String(),
+		PostedOn:    l.PostedOn.Time.Format(time.DateOnly),
+		Amount:      l.AmountMinor,
+		Description: l.Description,
+		Reference:   l.Reference,
+		MatchStatus: l.MatchStatus,
+	}
+	if l.LedgerEntryID.Valid {
+		resp.LedgerEntryID = uuid.UUID(l.LedgerEntryID.Bytes).String()
+	}
+	if l.MatchScore.Valid {
+		score := l.MatchScore.Float64
+		resp.MatchScore = &score
+	}
+	return resp
+}
@@ -0,0 +1,540 @@
+package httpserver
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	db "github.com/LBaronceli/go-figure/internal/db/sqlc"
+	"github.com/LBaronceli/go-figure/internal/invoicing"
+)
+
+// Report groupings.
+const (
+	groupByAccount  = "account"
+	groupByTag      = "tag"
+	groupByTracking = "tracking"
+	groupByContact  = "contact"
+)
+
+var reportFilters = []apiParam{
+	{name: "start_date", typ: "string", format: "date", desc: "Inclusive lower bound on the accounting date posted_on (YYYY-MM-DD)"},
+	{name: "end_date", typ: "string", format: "date", desc: "Inclusive upper bound on the accounting date posted_on (YYYY-MM-DD)"},
+	{name: "period", typ: "string", desc: "Reporting period in the organisation's calendar, such as FY2026-Q3 or 2026-05; replaces start_date and end_date"},
+	{name: "group_by", typ: "string", enum: []string{groupByAccount, groupByTag, groupByTracking, groupByContact}, desc: "Break each account's total down by tag, tracking option or contact; defaults to account"},
+	{name: "tracking_category_id", typ: "string", format: "uuid", desc: "Category whose options to group by; required with group_by=tracking"},
+}
+
+type accountTotalRow struct {
+	AccountID   string `json:"account_id" openapi:"format=uuid"`
+	AccountName string `json:"account_name"`
+	AccountType string `json:"account_type"`
+	Currency    string `json:"currency"`
+	Group       string `json:"group,omitempty" doc:"Tag, tracking option ID or contact ID; absent for entries without one"`
+	GroupName   string `json:"group_name,omitempty" doc:"Tracking option or contact name when grouped by tracking or contact"`
+	Total       int64  `json:"total" doc:"Net movement in minor units; debits positive"`
+}
+
+type accountTotalsResponse struct {
+	StartDate  string            `json:"start_date,omitempty" openapi:"format=date"`
+	EndDate    string            `json:"end_date,omitempty" openapi:"format=date"`
+	GroupBy    string            `json:"group_by" openapi:"enum=account|tag|tracking|contact"`
+	CategoryID string            `json:"tracking_category_id,omitempty" openapi:"format=uuid"`
+	Rows       []accountTotalRow `json:"rows"`
+}
+
+// GET /reports/account-totals
+//
+// Net movement per account over a date range, optionally broken down by tag,
+// by the options of one tracking category or by contact, which makes spend by
+// payee the expense rows grouped by contact. An entry with several tags is
+// counted under each of them.
+func (s *Server) getAccountTotalsReport(w http.ResponseWriter, r *http.Request) {
+	var errs validationErrors
+	org := tenantFrom(r.Context())
+	startDate, endDate := parseDateRange(r, org, &errs)
+	groupBy, categoryID := parseReportGrouping(r, &errs)
+	if !errs.empty() {
+		writeValidationErrors(w, errs)
+		return
+	}
+
+	if categoryID.Valid {
+		_, err := org.q.GetTrackingCategory(r.Context(), db.GetTrackingCategoryParams{OrganisationID: org.id, ID: categoryID})
+		if errors.Is(err, pgx.ErrNoRows) {
+			writeFieldError(w, http.StatusBadRequest, CodeNotFound, "tracking_category_id", "tracking category not found")
+			return
+		}
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, CodeInternal, "failed to get tracking category")
+			return
+		}
+	}
+
+	rows, err := org.q.ReportAccountTotals(r.Context(), db.ReportAccountTotalsParams{
+		GroupBy:        groupBy,
+		CategoryID:     categoryID,
+		OrganisationID: org.id,
+		StartDate:      startDate,
+		EndDate:        endDate,
+	})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to build report")
+		return
+	}
+
+	names, err := reportGroupNames(r, org, groupBy, categoryID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to name report groups")
+		return
+	}
+
+	resp := accountTotalsResponse{GroupBy: groupBy, Rows: make([]accountTotalRow, 0, len(rows))}
+	if startDate.Valid {
+		resp.StartDate = startDate.Time.Format(time.DateOnly)
+	}
+	if endDate.Valid {
+		resp.EndDate = endDate.Time.Format(time.DateOnly)
+	}
+	if categoryID.Valid {
+		resp.CategoryID = uuid.UUID(categoryID.Bytes).String()
+	}
+	for _, row := range rows {
+		resp.Rows = append(resp.Rows, accountTotalRow{
+			AccountID:   uuid.UUID(row.AccountID.Bytes).String(),
+			AccountName: row.AccountName,
+			AccountType: row.AccountType,
+			Currency:    row.Currency,
+			Group:       row.GroupKey.String,
+			GroupName:   names[row.GroupKey.String],
+			Total:       row.TotalMinor,
+		})
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// parseReportGrouping reads the group_by and tracking_category_id query
+// parameters; grouping by tracking needs the category to group by.
+func parseReportGrouping(r *http.Request, errs *validationErrors) (string, pgtype.UUID) {
+	groupBy := r.URL.Query().Get("group_by")
+	if groupBy == "" {
+		groupBy = groupByAccount
+	}
+	if groupBy != groupByAccount && groupBy != groupByTag && groupBy != groupByTracking && groupBy != groupByContact {
+		errs.add("group_by", CodeInvalidValue, "invalid group_by (must be account, tag, tracking, or contact)")
+	}
+
+	var categoryID pgtype.UUID
+	v := r.URL.Query().Get("tracking_category_id")
+	switch {
+	case v != "" && groupBy != groupByTracking:
+		errs.add("tracking_category_id", CodeInvalidValue, "tracking_category_id only applies to group_by=tracking")
+	case v != "":
+		id, err := parseUUID(v)
+		if err != nil {
+			errs.add("tracking_category_id", CodeInvalidFormat, "invalid tracking_category_id")
+		}
+		categoryID = id
+	case groupBy == groupByTracking:
+		errs.add("tracking_category_id", CodeRequired, "tracking_category_id is required with group_by=tracking")
+	}
+	return groupBy, categoryID
+}
+
+// reportGroupNames maps group keys to display names: the category's option
+// names when grouping by tracking, contact names when grouping by contact,
+// and nothing otherwise.
+func reportGroupNames(r *http.Request, org *tenant, groupBy string, categoryID pgtype.UUID) (map[string]string, error) {
+	names := make(map[string]string)
+	if groupBy == groupByContact {
+		cs, err := org.q.ListContacts(r.Context(), org.id)
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range cs {
+			names[uuid.UUID(c.ID.Bytes).String()] = c.Name
+		}
+		return names, nil
+	}
+	if !categoryID.Valid {
+		return names, nil
+	}
+	options, err := org.q.ListTrackingOptions(r.Context(), db.ListTrackingOptionsParams{OrganisationID: org.id, CategoryID: categoryID})
+	if err != nil {
+		return nil, err
+	}
+	for _, o := range options {
+		names[uuid.UUID(o.ID.Bytes).String()] = o.Name
+	}
+	return names, nil
+}
+
+// agedBalances splits an amount owed by how far past due it is.
+type agedBalances struct {
+	Current    int64 `json:"current" doc:"Not yet past due"`
+	Days1To30  int64 `json:"days_1_30"`
+	Days31To60 int64 `json:"days_31_60"`
+	Days61To90 int64 `json:"days_61_90"`
+	Over90     int64 `json:"over_90"`
+	Total      int64 `json:"total"`
+}
+
+func (b *agedBalances) add(bucket invoicing.Bucket, amount int64) {
+	switch bucket {
+	case invoicing.Current:
+		b.Current += amount
+	case invoicing.Overdue1To30:
+		b.Days1To30 += amount
+	case invoicing.Overdue31To60:
+		b.Days31To60 += amount
+	case invoicing.Overdue61To90:
+		b.Days61To90 += amount
+	default:
+		b.Over90 += amount
+	}
+	b.Total += amount
+}
+
+type agedInvoice struct {
+	InvoiceID   string `json:"invoice_id" openapi:"format=uuid"`
+	Number      string `json:"number"`
+	DueOn       string `json:"due_on" openapi:"format=date"`
+	DaysOverdue int    `json:"days_overdue" doc:"0 until the due date has passed"`
+	Bucket      string `json:"bucket" openapi:"enum=current|1_30|31_60|61_90|over_90"`
+	Outstanding int64  `json:"outstanding"`
+}
+
+type agedContactRow struct {
+	ContactID   string `json:"contact_id" openapi:"format=uuid"`
+	ContactName string `json:"contact_name"`
+	Currency    string `json:"currency"`
+	agedBalances
+	Invoices []agedInvoice `json:"invoices"`
+}
+
+type agedCurrencyTotal struct {
+	Currency string `json:"currency"`
+	agedBalances
+}
+
+type agedReceivablesResponse struct {
+	AsOf   string              `json:"as_of" openapi:"format=date"`
+	Rows   []agedContactRow    `json:"rows" doc:"One row per contact and currency"`
+	Totals []agedCurrencyTotal `json:"totals" doc:"One row per currency"`
+}
+
+// GET /reports/aged-receivables
+//
+// What customers owe on approved invoices as of a day, by contact and by how
+// far past due. Payments and voids dated after as_of are ignored, so the
+// report can be rerun for any past day.
+func (s *Server) getAgedReceivablesReport(w http.ResponseWriter, r *http.Request) {
+	org := tenantFrom(r.Context())
+	var errs validationErrors
+	asOf := parseOptionalDate(r.URL.Query().Get("as_of"), "as_of", org, &errs)
+	if !errs.empty() {
+		writeValidationErrors(w, errs)
+		return
+	}
+
+	invoices, err := org.q.ListOutstandingInvoices(r.Context(), db.ListOutstandingInvoicesParams{OrganisationID: org.id, AsOf: asOf})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to build report")
+		return
+	}
+
+	resp := agedReceivablesResponse{
+		AsOf:   asOf.Time.Format(time.DateOnly),
+		Rows:   []agedContactRow{},
+		Totals: []agedCurrencyTotal{},
+	}
+	totals := map[string]int{}
+	for _, inv := range invoices {
+		contactID := uuid.UUID(inv.ContactID.Bytes).String()
+		// rows come ordered by contact then currency
+		if n := len(resp.Rows); n == 0 || resp.Rows[n-1].ContactID != contactID || resp.Rows[n-1].Currency != inv.Currency {
+			resp.Rows = append(resp.Rows, agedContactRow{ContactID: contactID, ContactName: inv.ContactName, Currency: inv.Currency, Invoices: []agedInvoice{}})
+		}
+		row := &resp.Rows[len(resp.Rows)-1]
+
+		bucket := invoicing.Age(inv.DueOn.Time, asOf.Time)
+		row.add(bucket, inv.OutstandingMinor)
+		row.Invoices = append(row.Invoices, agedInvoice{
+			InvoiceID:   uuid.UUID(inv.ID.Bytes).String(),
+			Number:      inv.Number,
+			DueOn:       inv.DueOn.Time.Format(time.DateOnly),
+			DaysOverdue: max(0, int(asOf.Time.Sub(inv.DueOn.Time).Hours()/24)),
+			Bucket:      bucket.String(),
+			Outstanding: inv.OutstandingMinor,
+		})
+
+		i, ok := totals[inv.Currency]
+		if !ok {
+			i = len(resp.Totals)
+			totals[inv.Currency] = i
+			resp.Totals = append(resp.Totals, agedCurrencyTotal{Currency: inv.Currency})
+		}
+		resp.Totals[i].add(bucket, inv.OutstandingMinor)
+	}
+	slices.SortFunc(resp.Totals, func(a, b agedCurrencyTotal) int { return strings.Compare(a.Currency, b.Currency) })
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+type agedBill struct {
+	BillID      string `json:"bill_id" openapi:"format=uuid"`
+	Number      string `json:"number"`
+	DueOn       string `json:"due_on" openapi:"format=date"`
+	DaysOverdue int    `json:"days_overdue" doc:"0 until the due date has passed"`
+	Bucket      string `json:"bucket" openapi:"enum=current|1_30|31_60|61_90|over_90"`
+	Outstanding int64  `json:"outstanding"`
+}
+
+type agedSupplierRow struct {
+	ContactID   string `json:"contact_id" openapi:"format=uuid"`
+	ContactName string `json:"contact_name"`
+	Currency    string `json:"currency"`
+	agedBalances
+	Bills []agedBill `json:"bills"`
+}
+
+type agedPayablesResponse struct {
+	AsOf   string              `json:"as_of" openapi:"format=date"`
+	Rows   []agedSupplierRow   `json:"rows" doc:"One row per supplier and currency"`
+	Totals []agedCurrencyTotal `json:"totals" doc:"One row per currency"`
+}
+
+// GET /reports/aged-payables
+//
+// What is owed to suppliers on approved bills as of a day, by supplier and by
+// how far past due. Like aged receivables, later payments and voids are
+// ignored.
+func (s *Server) getAgedPayablesReport(w http.ResponseWriter, r *http.Request) {
+	org := tenantFrom(r.Context())
+	var errs validationErrors
+	asOf := parseOptionalDate(r.URL.Query().Get("as_of"), "as_of", org, &errs)
+	if !errs.empty() {
+		writeValidationErrors(w, errs)
+		return
+	}
+
+	bills, err := org.q.ListOutstandingBills(r.Context(), db.ListOutstandingBillsParams{OrganisationID: org.id, AsOf: asOf})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to build report")
+		return
+	}
+
+	resp := agedPayablesResponse{
+		AsOf:   asOf.Time.Format(time.DateOnly),
+		Rows:   []agedSupplierRow{},
+		Totals: []agedCurrencyTotal{},
+	}
+	totals := map[string]int{}
+	for _, b := range bills {
+		contactID := uuid.UUID(b.ContactID.Bytes).String()
+		// rows come ordered by supplier then currency
+		if n := len(resp.Rows); n == 0 || resp.Rows[n-1].ContactID != contactID || resp.Rows[n-1].Currency != b.Currency {
+			resp.Rows = append(resp.Rows, agedSupplierRow{ContactID: contactID, ContactName: b.ContactName, Currency: b.Currency, Bills: []agedBill{}})
+		}
+		row := &resp.Rows[len(resp.Rows)-1]
+
+		bucket := invoicing.Age(b.DueOn.Time, asOf.Time)
+		row.add(bucket, b.OutstandingMinor)
+		row.Bills = append(row.Bills, agedBill{
+			BillID:      uuid.UUID(b.ID.Bytes).String(),
+			Number:      b.Number,
+			DueOn:       b.DueOn.Time.Format(time.DateOnly),
+			DaysOverdue: max(0, int(asOf.Time.Sub(b.DueOn.Time).Hours()/24)),
+			Bucket:      bucket.String(),
+			Outstanding: b.OutstandingMinor,
+		})
+
+		i, ok := totals[b.Currency]
+		if !ok {
+			i = len(resp.Totals)
+			totals[b.Currency] = i
+			resp.Totals = append(resp.Totals, agedCurrencyTotal{Currency: b.Currency})
+		}
+		resp.Totals[i].add(bucket, b.OutstandingMinor)
+	}
+	slices.SortFunc(resp.Totals, func(a, b agedCurrencyTotal) int { return strings.Compare(a.Currency, b.Currency) })
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+const (
+	defaultCashFlowDays = 30
+	maxCashFlowDays     = 366
+)
+
+var cashFlowFilters = []apiParam{
+	{name: "days", typ: "integer", desc: "How many days ahead to project; defaults to 30, at most 366"},
+}
+
+type cashFlowItem struct {
+	Source      string `json:"source" openapi:"enum=invoice|bill" doc:"invoice for money coming in, bill for money going out"`
+	ID          string `json:"id" openapi:"format=uuid" doc:"Invoice or bill ID"`
+	Number      string `json:"number"`
+	ContactID   string `json:"contact_id" openapi:"format=uuid"`
+	ContactName string `json:"contact_name"`
+	DueOn       string `json:"due_on" openapi:"format=date"`
+	ExpectedOn  string `json:"expected_on" openapi:"format=date" doc:"due_on, or as_of for overdue items"`
+	Amount      int64  `json:"amount" doc:"Amount outstanding"`
+}
+
+type cashFlowWeek struct {
+	StartsOn string `json:"starts_on" openapi:"format=date"`
+	EndsOn   string `json:"ends_on" openapi:"format=date"`
+	Receipts int64  `json:"receipts" doc:"Outstanding on invoices expected in the week"`
+	Payments int64  `json:"payments" doc:"Outstanding on bills expected in the week"`
+	Net      int64  `json:"net" doc:"Receipts less payments"`
+	Running  int64  `json:"running" doc:"Net from as_of to the end of the week"`
+}
+
+type cashFlowCurrency struct {
+	Currency string         `json:"currency"`
+	Receipts int64          `json:"receipts"`
+	Payments int64          `json:"payments"`
+	Net      int64          `json:"net"`
+	Weeks    []cashFlowWeek `json:"weeks"`
+	Items    []cashFlowItem `json:"items" doc:"Ordered by expected_on"`
+}
+
+type cashFlowResponse struct {
+	AsOf       string             `json:"as_of" openapi:"format=date"`
+	EndsOn     string             `json:"ends_on" openapi:"format=date"`
+	Currencies []cashFlowCurrency `json:"currencies" doc:"One entry per currency with anything due"`
+}
+
+// GET /reports/cash-flow
+//
+// Money expected in and out over the next days: what is outstanding on
+// approved invoices and bills due by then, in weekly buckets from today.
+// Overdue amounts are expected today.
+func (s *Server) getCashFlowReport(w http.ResponseWriter, r *http.Request) {
+	var errs validationErrors
+	days := defaultCashFlowDays
+	if v := r.URL.Query().Get("days"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxCashFlowDays {
+			errs.add("days", CodeOutOfRange, fmt.Sprintf("days must be between 1 and %d", maxCashFlowDays))
+		}
+		days = n
+	}
+	if !errs.empty() {
+		writeValidationErrors(w, errs)
+		return
+	}
+
+	org := tenantFrom(r.Context())
+	asOf := pgtype.Date{Time: org.calendar.Date(time.Now()), Valid: true}
+	invoices, err := org.q.ListOutstandingInvoices(r.Context(), db.ListOutstandingInvoicesParams{OrganisationID: org.id, AsOf: asOf})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to build report")
+		return
+	}
+	bills, err := org.q.ListOutstandingBills(r.Context(), db.ListOutstandingBillsParams{OrganisationID: org.id, AsOf: asOf})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to build report")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, projectCashFlow(asOf.Time, days, invoices, bills))
+}
+
+// projectCashFlow buckets what is outstanding on invoices and bills due
+// within days of asOf into weeks starting on asOf, per currency.
+func projectCashFlow(asOf time.Time, days int, invoices []db.ListOutstandingInvoicesRow, bills []db.ListOutstandingBillsRow) cashFlowResponse {
+	endsOn := asOf.AddDate(0, 0, days)
+	resp := cashFlowResponse{
+		AsOf:       asOf.Format(time.DateOnly),
+		EndsOn:     endsOn.Format(time.DateOnly),
+		Currencies: []cashFlowCurrency{},
+	}
+
+	type dated struct {
+		item     cashFlowItem
+		currency string
+		expected time.Time
+	}
+	var items []dated
+	add := func(source string, id, contactID pgtype.UUID, number, contactName, currency string, dueOn time.Time, amount int64) {
+		if dueOn.After(endsOn) {
+			return
+		}
+		expected := dueOn
+		if expected.Before(asOf) {
+			expected = asOf
+		}
+		items = append(items, dated{
+			item: cashFlowItem{
+				Source:      source,
+				ID:          uuid.UUID(id.Bytes).String(),
+				Number:      number,
+				ContactID:   uuid.UUID(contactID.Bytes).String(),
+				ContactName: contactName,
+				DueOn:       dueOn.Format(time.DateOnly),
+				ExpectedOn:  expected.Format(time.DateOnly),
+				Amount:      amount,
+			},
+			currency: currency,
+			expected: expected,
+		})
+	}
+	for _, inv := range invoices {
+		add(sourceInvoice, inv.ID, inv.ContactID, inv.Number, inv.ContactName, inv.Currency, inv.DueOn.Time, inv.OutstandingMinor)
+	}
+	for _, b := range bills {
+		add(sourceBill, b.ID, b.ContactID, b.Number, b.ContactName, b.Currency, b.DueOn.Time, b.OutstandingMinor)
+	}
+	slices.SortStableFunc(items, func(a, b dated) int {
+		if c := strings.Compare(a.currency, b.currency); c != 0 {
+			return c
+		}
+		return a.expected.Compare(b.expected)
+	})
+
+	for _, d := range items {
+		if n := len(resp.Currencies); n == 0 || resp.Currencies[n-1].Currency != d.currency {
+			c := cashFlowCurrency{Currency: d.currency, Items: []cashFlowItem{}}
+			for start := asOf; !start.After(endsOn); start = start.AddDate(0, 0, 7) {
+				end := start.AddDate(0, 0, 6)
+				if end.After(endsOn) {
+					end = endsOn
+				}
+				c.Weeks = append(c.Weeks, cashFlowWeek{StartsOn: start.Format(time.DateOnly), EndsOn: end.Format(time.DateOnly)})
+			}
+			resp.Currencies = append(resp.Currencies, c)
+		}
+		c := &resp.Currencies[len(resp.Currencies)-1]
+		week := &c.Weeks[int(d.expected.Sub(asOf).Hours()/24)/7]
+		if d.item.Source == sourceInvoice {
+			c.Receipts += d.item.Amount
+			week.Receipts += d.item.Amount
+		} else {
+			c.Payments += d.item.Amount
+			week.Payments += d.item.Amount
+		}
+		c.Items = append(c.Items, d.item)
+	}
+	for i := range resp.Currencies {
+		c := &resp.Currencies[i]
+		c.Net = c.Receipts - c.Payments
+		var running int64
+		for j := range c.Weeks {
+			c.Weeks[j].Net = c.Weeks[j].Receipts - c.Weeks[j].Payments
+			running += c.Weeks[j].Net
+			c.Weeks[j].Running = running
+		}
+	}
+	return resp
+}
@@ -5,6 +5,8 @@ import (
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
+
+	"github.com/LBaronceli/go-figure/internal/auth"
 )
 
 func (s *Server) Routes() http.Handler {
@@ -15,25 +17,191 @@ func (s *Server) Routes() http.Handler {
 	r.Use(middleware.RealIP)
 	r.Use(middleware.Logger)
 	r.Use(middleware.Recoverer)
+	if s.requestValidation {
+		r.Use(s.validateRequests)
+	}
 
 	// health
 	r.Get("/healthz", s.healthz)
 	r.Get("/readyz", s.readyz)
 
-	// accounts
-	r.Route("/accounts", func(r chi.Router) {
-		r.Post("/", s.createAccount)
-		r.Get("/", s.listAccounts)
-		r.Get("/{id}", s.getAccount)
-		r.Put("/{id}", s.updateAccount)
-		r.Delete("/{id}", s.deleteAccount)
-	})
+	// api description
+	r.Get("/openapi.json", s.openapiSpec)
+
+	// login is the only public route that touches data
+	r.Post("/auth/login", s.login)
+
+	r.Group(func(r chi.Router) {
+		r.Use(s.authenticate)
+		r.Use(requireMethodScope)
+
+		// auth, outside idempotencyKeys: its stored response would keep a
+		// new API token's plaintext
+		r.Route("/auth", func(r chi.Router) {
+			r.Post("/logout", s.logout)
+			r.Get("/me", s.me)
+			r.Post("/tokens", s.createAPIToken)
+			r.Get("/tokens", s.listAPITokens)
+			r.Delete("/tokens/{id}", s.revokeAPIToken)
+		})
+
+		r.Group(func(r chi.Router) {
+			// after authentication, so keys are scoped to the caller
+			r.Use(s.idempotencyKeys)
+
+			// users
+			r.Route("/users", func(r chi.Router) {
+				r.Use(requireScope(auth.ScopeAdmin))
+				r.Post("/", s.createUser)
+				r.Get("/", s.listUsers)
+			})
+
+			// organisations
+			r.Route("/organisations", func(r chi.Router) {
+				r.Post("/", s.createOrganisation)
+				r.Get("/", s.listOrganisations)
+				r.Put("/{id}", s.updateOrganisation)
+				r.Get("/{id}/members", s.listMembers)
+				r.Post("/{id}/members", s.addMember)
+				r.Put("/{id}/members/{user_id}", s.updateMember)
+				r.Delete("/{id}/members/{user_id}", s.removeMember)
+			})
+
+			// everything below belongs to one organisation's books
+			r.Group(func(r chi.Router) {
+				r.Use(s.organisationContext)
+
+				// accounts
+				r.Route("/accounts", func(r chi.Router) {
+					r.Post("/", s.createAccount)
+					r.Get("/", s.listAccounts)
+					r.Get("/{id}", s.getAccount)
+					r.Put("/{id}", s.updateAccount)
+					r.Delete("/{id}", s.deleteAccount)
+					r.Post("/{id}/archive", s.archiveAccount)
+					r.Post("/{id}/unarchive", s.unarchiveAccount)
+				})
+
+				// transactions
+				r.Route("/transactions", func(r chi.Router) {
+					r.Post("/", s.createTransaction)
+					r.Post("/batch", s.createTransactionsBatch)
+					r.Get("/", s.listTransactions)
+					r.Get("/search", s.searchTransactions)
+					r.Get("/{id}", s.getTransaction)
+					r.Post("/{id}/split", s.splitTransaction)
+					r.Put("/{id}/contact", s.setTransactionContact)
+					r.Post("/{id}/attachments", s.createAttachment)
+					r.Get("/{id}/attachments/{attachment_id}", s.getAttachmentContent)
+					r.Delete("/{id}/attachments/{attachment_id}", s.deleteAttachment)
+				})
+
+				// period locks
+				r.Route("/period-locks", func(r chi.Router) {
+					r.Post("/", s.createPeriodLock)
+					r.Get("/", s.listPeriodLocks)
+					r.Post("/close-year", s.closeYear)
+					r.With(requireScope(auth.ScopeAdmin)).Delete("/{id}", s.deletePeriodLock)
+				})
+
+				// reconciliation
+				r.Route("/reconciliations", func(r chi.Router) {
+					r.Post("/", s.createReconciliation)
+					r.Get("/", s.listReconciliations)
+					r.Get("/{id}", s.getReconciliation)
+					r.Post("/{id}/lines", s.importStatementLines)
+					r.Get("/{id}/lines", s.listStatementLines)
+					r.Post("/{id}/auto-match", s.autoMatchStatementLines)
+					r.Post("/{id}/lines/{line_id}/accept", s.acceptStatementLineMatch)
+					r.Post("/{id}/lines/{line_id}/reject", s.rejectStatementLineMatch)
+					r.Post("/{id}/lines/{line_id}/match", s.matchStatementLine)
+					r.Get("/{id}/report", s.getReconciliationReport)
+					r.Post("/{id}/complete", s.completeReconciliation)
+				})
+
+				// tracking
+				r.Route("/tracking-categories", func(r chi.Router) {
+					r.Post("/", s.createTrackingCategory)
+					r.Get("/", s.listTrackingCategories)
+					r.Get("/{id}", s.getTrackingCategory)
+					r.Put("/{id}", s.updateTrackingCategory)
+					r.Delete("/{id}", s.deleteTrackingCategory)
+					r.Post("/{id}/options", s.createTrackingOption)
+					r.Put("/{id}/options/{option_id}", s.updateTrackingOption)
+					r.Delete("/{id}/options/{option_id}", s.deleteTrackingOption)
+				})
+
+				// contacts
+				r.Route("/contacts", func(r chi.Router) {
+					r.Post("/", s.createContact)
+					r.Get("/", s.listContacts)
+					r.Get("/match", s.matchContact)
+					r.Get("/{id}", s.getContact)
+					r.Put("/{id}", s.updateContact)
+					r.Delete("/{id}", s.deleteContact)
+					r.Post("/{id}/merge", s.mergeContact)
+					r.Post("/{id}/aliases", s.createContactAlias)
+					r.Delete("/{id}/aliases/{alias_id}", s.deleteContactAlias)
+				})
+
+				// invoicing
+				r.Route("/tax-codes", func(r chi.Router) {
+					r.Post("/", s.createTaxCode)
+					r.Get("/", s.listTaxCodes)
+					r.Get("/{id}", s.getTaxCode)
+					r.Put("/{id}", s.updateTaxCode)
+					r.Delete("/{id}", s.deleteTaxCode)
+				})
+				r.Get("/invoice-template", s.getInvoiceTemplate)
+				r.Put("/invoice-template", s.updateInvoiceTemplate)
+				r.Put("/invoice-template/logo", s.updateInvoiceLogo)
+				r.Delete("/invoice-template/logo", s.deleteInvoiceLogo)
+				r.Route("/invoices", func(r chi.Router) {
+					r.Post("/", s.createInvoice)
+					r.Get("/", s.listInvoices)
+					r.Get("/{id}", s.getInvoice)
+					r.Get("/{id}.pdf", s.getInvoicePDF)
+					r.Put("/{id}", s.updateInvoice)
+					r.Delete("/{id}", s.deleteInvoice)
+					r.Post("/{id}/approve", s.approveInvoice)
+					r.Post("/{id}/payments", s.recordInvoicePayment)
+					r.Post("/{id}/void", s.voidInvoice)
+				})
+
+				// payables
+				r.Route("/bills", func(r chi.Router) {
+					r.Post("/", s.createBill)
+					r.Get("/", s.listBills)
+					r.Get("/{id}", s.getBill)
+					r.Put("/{id}", s.updateBill)
+					r.Delete("/{id}", s.deleteBill)
+					r.Post("/{id}/approve", s.approveBill)
+					r.Post("/{id}/payments", s.recordBillPayment)
+					r.Post("/{id}/void", s.voidBill)
+				})
+
+				// receipt inbox
+				r.Route("/receipts", func(r chi.Router) {
+					r.Post("/", s.createReceipt)
+					r.Get("/", s.listReceipts)
+					r.Get("/{id}", s.getReceipt)
+					r.Delete("/{id}", s.deleteReceipt)
+					r.Get("/{id}/content", s.getReceiptContent)
+					r.Post("/{id}/rescan", s.rescanReceipt)
+					r.Post("/{id}/post", s.postReceipt)
+				})
+
+				// reports
+				r.Get("/reports/account-totals", s.getAccountTotalsReport)
+				r.Get("/reports/aged-receivables", s.getAgedReceivablesReport)
+				r.Get("/reports/aged-payables", s.getAgedPayablesReport)
+				r.Get("/reports/cash-flow", s.getCashFlowReport)
 
-	// transactions
-	r.Route("/transactions", func(r chi.Router) {
-		r.Post("/", s.createTransaction)
-		r.Get("/", s.listTransactions)
-		r.Get("/{id}", s.getTransaction)
+				// audit
+				r.Get("/audit", s.listAuditEvents)
+				r.Get("/audit/verify", s.verifyAuditChain)
+			})
+		})
 	})
 
 	return r
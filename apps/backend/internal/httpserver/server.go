@@ -1,20 +1,99 @@
 package httpserver
 
 import (
+	"sync/atomic"
+	"time"
+
 	"github.com/jackc/pgx/v5/pgxpool"
 
 	db "github.com/LBaronceli/go-figure/internal/db/sqlc"
+	"github.com/LBaronceli/go-figure/internal/ocr"
+	"github.com/LBaronceli/go-figure/internal/storage"
 )
 
 type Server struct {
 	db *pgxpool.Pool
 	q  *db.Queries
+	// storage holds attachment content; attachment routes answer 503
+	// without it.
+	storage storage.Store
+	// ocr reads receipts for the receipt scanner, which does nothing
+	// without it.
+	ocr ocr.Engine
+
+	// draining is set once shutdown starts, so /readyz turns load
+	// balancers away while in-flight requests finish.
+	draining atomic.Bool
+
+	requestValidation    bool
+	idempotencyRetention time.Duration
+	sessionTTL           time.Duration
+	insecureCookies      bool
+}
+
+// Option configures optional Server behaviour.
+type Option func(*Server)
+
+// WithRequestValidation validates every request against the OpenAPI spec
+// before it reaches a handler.
+func WithRequestValidation() Option {
+	return func(s *Server) {
+		s.requestValidation = true
+	}
+}
+
+// WithIdempotencyRetention sets how long idempotency keys stay bound to the
+// transaction they created.
+func WithIdempotencyRetention(d time.Duration) Option {
+	return func(s *Server) {
+		s.idempotencyRetention = d
+	}
+}
+
+// WithSessionTTL sets how long a login session lasts.
+func WithSessionTTL(d time.Duration) Option {
+	return func(s *Server) {
+		s.sessionTTL = d
+	}
+}
+
+// WithInsecureCookies drops the Secure attribute from the session cookie so
+// logins work over plain HTTP during local development.
+func WithInsecureCookies() Option {
+	return func(s *Server) {
+		s.insecureCookies = true
+	}
+}
+
+// WithStorage sets where attachment content is stored.
+func WithStorage(store storage.Store) Option {
+	return func(s *Server) {
+		s.storage = store
+	}
+}
+
+// WithOCR sets the engine the receipt scanner reads receipts with.
+func WithOCR(engine ocr.Engine) Option {
+	return func(s *Server) {
+		s.ocr = engine
+	}
 }
 
-func NewServer(dbpool *pgxpool.Pool) *Server {
-	return &Server{
-		db: dbpool,
-		q:  db.New(dbpool),
+func NewServer(dbpool *pgxpool.Pool, opts ...Option) *Server {
+	s := &Server{
+		db:                   dbpool,
+		q:                    db.New(dbpool),
+		idempotencyRetention: defaultIdempotencyRetention,
+		sessionTTL:           defaultSessionTTL,
+	}
+	for _, opt := range opts {
+		opt(s)
 	}
+	return s
 }
 
+// Drain marks the server as shutting down: /readyz answers 503 from now on,
+// while every other route keeps serving until the HTTP server is shut down.
+func (s *Server) Drain() {
+	s.draining.Store(true)
+}
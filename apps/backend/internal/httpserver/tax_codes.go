@@ -0,0 +1,253 @@
+package httpserver
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	db "github.com/LBaronceli/go-figure/internal/db/sqlc"
+	"github.com/LBaronceli/go-figure/internal/models"
+)
+
+type taxCodeRequest struct {
+	Name      string  `json:"name" openapi:"minLength=1,maxLength=500"`
+	Rate      float64 `json:"rate" doc:"Percent with up to 2 decimal places, from 0 to 100"`
+	AccountID string  `json:"account_id" openapi:"optional,format=uuid" doc:"Liability account the tax is credited to; required unless rate is 0"`
+}
+
+type taxCodeResponse struct {
+	ID        string  `json:"id" openapi:"format=uuid"`
+	Name      string  `json:"name"`
+	Rate      float64 `json:"rate" doc:"Percent"`
+	AccountID string  `json:"account_id,omitempty" openapi:"format=uuid"`
+	CreatedAt string  `json:"created_at" openapi:"format=date-time"`
+	UpdatedAt string  `json:"updated_at" openapi:"format=date-time"`
+}
+
+// POST /tax-codes
+func (s *Server) createTaxCode(w http.ResponseWriter, r *http.Request) {
+	params, ok := decodeTaxCode(w, r)
+	if !ok {
+		return
+	}
+
+	org := tenantFrom(r.Context())
+	c, err := org.q.CreateTaxCode(r.Context(), params)
+	if err != nil {
+		if isUniqueViolation(err) {
+			writeFieldError(w, http.StatusConflict, CodeNameTaken, "name", "a tax code with this name already exists")
+			return
+		}
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to create tax code")
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, toTaxCodeResponse(c))
+}
+
+// GET /tax-codes
+func (s *Server) listTaxCodes(w http.ResponseWriter, r *http.Request) {
+	org := tenantFrom(r.Context())
+	codes, err := org.q.ListTaxCodes(r.Context(), org.id)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to list tax codes")
+		return
+	}
+
+	resp := make([]taxCodeResponse, 0, len(codes))
+	for _, c := range codes {
+		resp = append(resp, toTaxCodeResponse(c))
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// GET /tax-codes/{id}
+func (s *Server) getTaxCode(w http.ResponseWriter, r *http.Request) {
+	id, err := parseUUID(chi.URLParam(r, "id"))
+	if err != nil {
+		writeFieldError(w, http.StatusBadRequest, CodeInvalidFormat, "id", "invalid id")
+		return
+	}
+
+	org := tenantFrom(r.Context())
+	c, err := org.q.GetTaxCode(r.Context(), db.GetTaxCodeParams{OrganisationID: org.id, ID: id})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			writeError(w, http.StatusNotFound, CodeNotFound, "tax code not found")
+			return
+		}
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to get tax code")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, toTaxCodeResponse(c))
+}
+
+// PUT /tax-codes/{id}
+//
+// A new rate applies to invoices saved from now on; invoices already saved
+// keep the tax they were worked out with.
+func (s *Server) updateTaxCode(w http.ResponseWriter, r *http.Request) {
+	id, err := parseUUID(chi.URLParam(r, "id"))
+	if err != nil {
+		writeFieldError(w, http.StatusBadRequest, CodeInvalidFormat, "id", "invalid id")
+		return
+	}
+	params, ok := decodeTaxCode(w, r)
+	if !ok {
+		return
+	}
+
+	org := tenantFrom(r.Context())
+	c, err := org.q.UpdateTaxCode(r.Context(), db.UpdateTaxCodeParams{
+		OrganisationID:  org.id,
+		ID:              id,
+		Name:            params.Name,
+		RateBasisPoints: params.RateBasisPoints,
+		AccountID:       params.AccountID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			writeError(w, http.StatusNotFound, CodeNotFound, "tax code not found")
+			return
+		}
+		if isUniqueViolation(err) {
+			writeFieldError(w, http.StatusConflict, CodeNameTaken, "name", "a tax code with this name already exists")
+			return
+		}
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to update tax code")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, toTaxCodeResponse(c))
+}
+
+// DELETE /tax-codes/{id}
+//
+// Only tax codes no invoice line uses can be deleted.
+func (s *Server) deleteTaxCode(w http.ResponseWriter, r *http.Request) {
+	id, err := parseUUID(chi.URLParam(r, "id"))
+	if err != nil {
+		writeFieldError(w, http.StatusBadRequest, CodeInvalidFormat, "id", "invalid id")
+		return
+	}
+
+	org := tenantFrom(r.Context())
+	if _, err := org.q.GetTaxCode(r.Context(), db.GetTaxCodeParams{OrganisationID: org.id, ID: id}); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			writeError(w, http.StatusNotFound, CodeNotFound, "tax code not found")
+			return
+		}
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to get tax code")
+		return
+	}
+	if err := org.q.DeleteTaxCode(r.Context(), db.DeleteTaxCodeParams{OrganisationID: org.id, ID: id}); err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" { // foreign_key_violation
+			writeError(w, http.StatusConflict, CodeTaxCodeInUse, "tax code is used by invoice or bill lines")
+			return
+		}
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to delete tax code")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// decodeTaxCode reads and validates a tax code body, including that its
+// account is a liability account. It writes the error response itself.
+func decodeTaxCode(w http.ResponseWriter, r *http.Request) (db.CreateTaxCodeParams, bool) {
+	var req taxCodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, CodeInvalidJSON, "invalid json")
+		return db.CreateTaxCodeParams{}, false
+	}
+
+	org := tenantFrom(r.Context())
+	params := db.CreateTaxCodeParams{OrganisationID: org.id, Name: strings.TrimSpace(req.Name)}
+	var errs validationErrors
+	if params.Name == "" {
+		errs.add("name", CodeRequired, "name is required")
+	} else if len(params.Name) > maxStringLength {
+		errs.add("name", CodeTooLong, "name too long")
+	}
+	bp := req.Rate * 100
+	switch {
+	case req.Rate < 0 || req.Rate > 100:
+		errs.add("rate", CodeOutOfRange, "rate must be from 0 to 100")
+	case math.Abs(bp-math.Round(bp)) > 1e-6:
+		errs.add("rate", CodeInvalidValue, "rate has more than 2 decimal places")
+	}
+	params.RateBasisPoints = int32(math.Round(bp))
+	if req.AccountID != "" {
+		id, err := parseUUID(req.AccountID)
+		if err != nil {
+			errs.add("account_id", CodeInvalidFormat, "invalid account_id")
+		}
+		params.AccountID = id
+	} else if params.RateBasisPoints > 0 {
+		errs.add("account_id", CodeRequired, "account_id is required unless rate is 0")
+	}
+	if !errs.empty() {
+		writeValidationErrors(w, errs)
+		return db.CreateTaxCodeParams{}, false
+	}
+
+	if params.AccountID.Valid {
+		acc, err := org.q.GetAccount(r.Context(), db.GetAccountParams{OrganisationID: org.id, ID: params.AccountID})
+		if errors.Is(err, pgx.ErrNoRows) {
+			writeFieldError(w, http.StatusBadRequest, CodeAccountNotFound, "account_id", "account not found")
+			return db.CreateTaxCodeParams{}, false
+		}
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, CodeInternal, "failed to get account")
+			return db.CreateTaxCodeParams{}, false
+		}
+		// tax collected is owed to the tax authority
+		if models.AccountType(acc.Type) != models.AccountTypeLiability {
+			writeFieldError(w, http.StatusBadRequest, CodeInvalidValue, "account_id", "tax must be credited to a liability account")
+			return db.CreateTaxCodeParams{}, false
+		}
+	}
+	return params, true
+}
+
+// taxCodesByID fetches the tax codes with the given IDs, keyed by ID.
+func taxCodesByID(r *http.Request, q *db.Queries, org *tenant, ids []pgtype.UUID) (map[pgtype.UUID]db.TaxCode, error) {
+	out := make(map[pgtype.UUID]db.TaxCode, len(ids))
+	if len(ids) == 0 {
+		return out, nil
+	}
+	codes, err := q.GetTaxCodesByIDs(r.Context(), db.GetTaxCodesByIDsParams{OrganisationID: org.id, Ids: ids})
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range codes {
+		out[c.ID] = c
+	}
+	return out, nil
+}
+
+func toTaxCodeResponse(c db.TaxCode) taxCodeResponse {
+	resp := taxCodeResponse{
+		ID:        uuid.UUID(c.ID.Bytes).String(),
+		Name:      c.Name,
+		Rate:      float64(c.RateBasisPoints) / 100,
+		CreatedAt: c.CreatedAt.Time.Format(time.RFC3339Nano),
+		UpdatedAt: c.UpdatedAt.Time.Format(time.RFC3339Nano),
+	}
+	if c.AccountID.Valid {
+		resp.AccountID = uuid.UUID(c.AccountID.Bytes).String()
+	}
+	return resp
+}
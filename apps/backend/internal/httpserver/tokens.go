@@ -0,0 +1,179 @@
+package httpserver
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/LBaronceli/go-figure/internal/auth"
+	db "github.com/LBaronceli/go-figure/internal/db/sqlc"
+)
+
+type createAPITokenRequest struct {
+	Name      string     `json:"name" openapi:"minLength=1,maxLength=100"`
+	Scopes    []string   `json:"scopes" openapi:"enum=read|write|admin,minItems=1,maxItems=3"`
+	ExpiresAt *time.Time `json:"expires_at" doc:"Omit for a token that never expires"`
+}
+
+type apiTokenResponse struct {
+	ID         string   `json:"id" openapi:"format=uuid"`
+	Name       string   `json:"name"`
+	Prefix     string   `json:"prefix" doc:"First characters of the token, to tell tokens apart"`
+	Scopes     []string `json:"scopes" openapi:"enum=read|write|admin"`
+	CreatedAt  string   `json:"created_at" openapi:"format=date-time"`
+	LastUsedAt string   `json:"last_used_at,omitempty" openapi:"format=date-time"`
+	ExpiresAt  string   `json:"expires_at,omitempty" openapi:"format=date-time"`
+	RevokedAt  string   `json:"revoked_at,omitempty" openapi:"format=date-time"`
+}
+
+type createdAPITokenResponse struct {
+	apiTokenResponse
+	Token string `json:"token" doc:"The secret. It is only ever returned here"`
+}
+
+// POST /auth/tokens
+func (s *Server) createAPIToken(w http.ResponseWriter, r *http.Request) {
+	var req createAPITokenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, CodeInvalidJSON, "invalid json")
+		return
+	}
+
+	p, _ := auth.PrincipalFrom(r.Context())
+
+	req.Name = strings.TrimSpace(req.Name)
+	var errs validationErrors
+	if req.Name == "" {
+		errs.add("name", CodeRequired, "name is required")
+	} else if len(req.Name) > 100 {
+		errs.add("name", CodeTooLong, "name too long")
+	}
+	if len(req.Scopes) == 0 {
+		errs.add("scopes", CodeRequired, "at least one scope is required")
+	}
+	scopes := make([]string, 0, len(req.Scopes))
+	seen := make(map[auth.Scope]bool)
+	for _, raw := range req.Scopes {
+		sc := auth.Scope(strings.TrimSpace(strings.ToLower(raw)))
+		if !sc.IsValid() {
+			errs.add("scopes", CodeInvalidValue, "invalid scope (must be read, write, or admin)")
+			continue
+		}
+		// a token can never do more than the caller that minted it
+		if !p.Has(sc) {
+			writeError(w, http.StatusForbidden, CodeInsufficientScope, "cannot grant the "+string(sc)+" scope without holding it")
+			return
+		}
+		if !seen[sc] {
+			seen[sc] = true
+			scopes = append(scopes, string(sc))
+		}
+	}
+	var expires pgtype.Timestamptz
+	if req.ExpiresAt != nil {
+		if !req.ExpiresAt.After(time.Now()) {
+			errs.add("expires_at", CodeOutOfRange, "expires_at must be in the future")
+		}
+		expires = pgtype.Timestamptz{Time: *req.ExpiresAt, Valid: true}
+	}
+	if !errs.empty() {
+		writeValidationErrors(w, errs)
+		return
+	}
+
+	token, hash, err := auth.NewToken(auth.APITokenPrefix)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to create token")
+		return
+	}
+
+	t, err := s.q.CreateAPIToken(r.Context(), db.CreateAPITokenParams{
+		UserID:      pgtype.UUID{Bytes: p.UserID, Valid: true},
+		Name:        req.Name,
+		TokenPrefix: auth.DisplayPrefix(token),
+		TokenHash:   hash,
+		Scopes:      scopes,
+		ExpiresAt:   expires,
+	})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to create token")
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, createdAPITokenResponse{
+		apiTokenResponse: toAPITokenResponse(t),
+		Token:            token,
+	})
+}
+
+// GET /auth/tokens
+func (s *Server) listAPITokens(w http.ResponseWriter, r *http.Request) {
+	p, _ := auth.PrincipalFrom(r.Context())
+
+	tokens, err := s.q.ListAPITokens(r.Context(), pgtype.UUID{Bytes: p.UserID, Valid: true})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to list tokens")
+		return
+	}
+
+	resp := make([]apiTokenResponse, 0, len(tokens))
+	for _, t := range tokens {
+		resp = append(resp, toAPITokenResponse(t))
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// DELETE /auth/tokens/{id}
+func (s *Server) revokeAPIToken(w http.ResponseWriter, r *http.Request) {
+	id, err := parseUUID(chi.URLParam(r, "id"))
+	if err != nil {
+		writeFieldError(w, http.StatusBadRequest, CodeInvalidFormat, "id", "invalid id")
+		return
+	}
+
+	p, _ := auth.PrincipalFrom(r.Context())
+
+	// scoped to the caller, so another user's token reads as not found
+	_, err = s.q.RevokeAPIToken(r.Context(), db.RevokeAPITokenParams{
+		ID:     id,
+		UserID: pgtype.UUID{Bytes: p.UserID, Valid: true},
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		writeError(w, http.StatusNotFound, CodeNotFound, "token not found")
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to revoke token")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func toAPITokenResponse(t db.ApiToken) apiTokenResponse {
+	resp := apiTokenResponse{
+		ID:        uuid.UUID(t.ID.Bytes).String(),
+		Name:      t.Name,
+		Prefix:    t.TokenPrefix,
+		Scopes:    t.Scopes,
+		CreatedAt: t.CreatedAt.Time.Format(time.RFC3339Nano),
+	}
+	if t.LastUsedAt.Valid {
+		resp.LastUsedAt = t.LastUsedAt.Time.Format(time.RFC3339Nano)
+	}
+	if t.ExpiresAt.Valid {
+		resp.ExpiresAt = t.ExpiresAt.Time.Format(time.RFC3339Nano)
+	}
+	if t.RevokedAt.Valid {
+		resp.RevokedAt = t.RevokedAt.Time.Format(time.RFC3339Nano)
+	}
+	return resp
+}
@@ -0,0 +1,556 @@
+package httpserver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	db "github.com/LBaronceli/go-figure/internal/db/sqlc"
+)
+
+const (
+	maxEntryTags     = 20
+	maxTagLength     = 50
+	maxEntryTracking = 10
+)
+
+type entryTrackingRequest struct {
+	CategoryID string `json:"category_id" openapi:"format=uuid"`
+	OptionID   string `json:"option_id" openapi:"format=uuid"`
+}
+
+type entryTrackingResponse struct {
+	CategoryID string `json:"category_id" openapi:"format=uuid"`
+	OptionID   string `json:"option_id" openapi:"format=uuid"`
+}
+
+type trackingCategoryRequest struct {
+	Name string `json:"name" openapi:"minLength=1,maxLength=500"`
+}
+
+type createTrackingOptionRequest struct {
+	Name string `json:"name" openapi:"minLength=1,maxLength=500"`
+}
+
+type updateTrackingOptionRequest struct {
+	Name   *string `json:"name" openapi:"maxLength=500"`
+	Active *bool   `json:"active" doc:"Inactive options stay on existing entries but cannot be used on new ones"`
+}
+
+type trackingOptionResponse struct {
+	ID         string `json:"id" openapi:"format=uuid"`
+	CategoryID string `json:"category_id" openapi:"format=uuid"`
+	Name       string `json:"name"`
+	Active     bool   `json:"active"`
+	CreatedAt  string `json:"created_at" openapi:"format=date-time"`
+	UpdatedAt  string `json:"updated_at" openapi:"format=date-time"`
+}
+
+type trackingCategoryResponse struct {
+	ID        string                   `json:"id" openapi:"format=uuid"`
+	Name      string                   `json:"name"`
+	Options   []trackingOptionResponse `json:"options"`
+	CreatedAt string                   `json:"created_at" openapi:"format=date-time"`
+	UpdatedAt string                   `json:"updated_at" openapi:"format=date-time"`
+}
+
+// POST /tracking-categories
+func (s *Server) createTrackingCategory(w http.ResponseWriter, r *http.Request) {
+	name, ok := decodeTrackingName(w, r)
+	if !ok {
+		return
+	}
+
+	org := tenantFrom(r.Context())
+	c, err := org.q.CreateTrackingCategory(r.Context(), db.CreateTrackingCategoryParams{OrganisationID: org.id, Name: name})
+	if err != nil {
+		if isUniqueViolation(err) {
+			writeFieldError(w, http.StatusConflict, CodeNameTaken, "name", "a tracking category with this name already exists")
+			return
+		}
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to create tracking category")
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, toTrackingCategoryResponse(c, nil))
+}
+
+// GET /tracking-categories
+func (s *Server) listTrackingCategories(w http.ResponseWriter, r *http.Request) {
+	org := tenantFrom(r.Context())
+	categories, err := org.q.ListTrackingCategories(r.Context(), org.id)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to list tracking categories")
+		return
+	}
+	options, err := org.q.ListTrackingOptions(r.Context(), db.ListTrackingOptionsParams{OrganisationID: org.id})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to list tracking options")
+		return
+	}
+
+	resp := make([]trackingCategoryResponse, 0, len(categories))
+	for _, c := range categories {
+		resp = append(resp, toTrackingCategoryResponse(c, options))
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// GET /tracking-categories/{id}
+func (s *Server) getTrackingCategory(w http.ResponseWriter, r *http.Request) {
+	c, ok := s.loadTrackingCategory(w, r)
+	if !ok {
+		return
+	}
+
+	org := tenantFrom(r.Context())
+	options, err := org.q.ListTrackingOptions(r.Context(), db.ListTrackingOptionsParams{OrganisationID: org.id, CategoryID: c.ID})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to list tracking options")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, toTrackingCategoryResponse(c, options))
+}
+
+// PUT /tracking-categories/{id}
+func (s *Server) updateTrackingCategory(w http.ResponseWriter, r *http.Request) {
+	id, err := parseUUID(chi.URLParam(r, "id"))
+	if err != nil {
+		writeFieldError(w, http.StatusBadRequest, CodeInvalidFormat, "id", "invalid id")
+		return
+	}
+	name, ok := decodeTrackingName(w, r)
+	if !ok {
+		return
+	}
+
+	org := tenantFrom(r.Context())
+	c, err := org.q.UpdateTrackingCategory(r.Context(), db.UpdateTrackingCategoryParams{OrganisationID: org.id, ID: id, Name: name})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			writeError(w, http.StatusNotFound, CodeNotFound, "tracking category not found")
+			return
+		}
+		if isUniqueViolation(err) {
+			writeFieldError(w, http.StatusConflict, CodeNameTaken, "name", "a tracking category with this name already exists")
+			return
+		}
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to update tracking category")
+		return
+	}
+
+	options, err := org.q.ListTrackingOptions(r.Context(), db.ListTrackingOptionsParams{OrganisationID: org.id, CategoryID: c.ID})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to list tracking options")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, toTrackingCategoryResponse(c, options))
+}
+
+// DELETE /tracking-categories/{id}
+//
+// Removes the category and its options. Categories used by any ledger entry
+// are kept; deactivate their options instead.
+func (s *Server) deleteTrackingCategory(w http.ResponseWriter, r *http.Request) {
+	c, ok := s.loadTrackingCategory(w, r)
+	if !ok {
+		return
+	}
+
+	org := tenantFrom(r.Context())
+	if err := org.q.DeleteTrackingCategory(r.Context(), db.DeleteTrackingCategoryParams{OrganisationID: org.id, ID: c.ID}); err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" { // foreign_key_violation
+			writeError(w, http.StatusConflict, CodeTrackingInUse, "tracking category is used by ledger entries; deactivate its options instead")
+			return
+		}
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to delete tracking category")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// POST /tracking-categories/{id}/options
+func (s *Server) createTrackingOption(w http.ResponseWriter, r *http.Request) {
+	c, ok := s.loadTrackingCategory(w, r)
+	if !ok {
+		return
+	}
+	name, ok := decodeTrackingName(w, r)
+	if !ok {
+		return
+	}
+
+	org := tenantFrom(r.Context())
+	o, err := org.q.CreateTrackingOption(r.Context(), db.CreateTrackingOptionParams{OrganisationID: org.id, CategoryID: c.ID, Name: name})
+	if err != nil {
+		if isUniqueViolation(err) {
+			writeFieldError(w, http.StatusConflict, CodeNameTaken, "name", "the category already has an option with this name")
+			return
+		}
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to create tracking option")
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, toTrackingOptionResponse(o))
+}
+
+// PUT /tracking-categories/{id}/options/{option_id}
+func (s *Server) updateTrackingOption(w http.ResponseWriter, r *http.Request) {
+	categoryID, optionID, ok := trackingOptionPath(w, r)
+	if !ok {
+		return
+	}
+
+	var req updateTrackingOptionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, CodeInvalidJSON, "invalid json")
+		return
+	}
+
+	org := tenantFrom(r.Context())
+	params := db.UpdateTrackingOptionParams{OrganisationID: org.id, CategoryID: categoryID, ID: optionID}
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		if len(name) > maxStringLength {
+			writeFieldError(w, http.StatusBadRequest, CodeTooLong, "name", "name too long")
+			return
+		}
+		params.Name = pgtype.Text{String: name, Valid: name != ""}
+	}
+	if req.Active != nil {
+		params.Active = pgtype.Bool{Bool: *req.Active, Valid: true}
+	}
+	if !params.Name.Valid && !params.Active.Valid {
+		writeError(w, http.StatusBadRequest, CodeNothingToUpdate, "nothing to update")
+		return
+	}
+
+	o, err := org.q.UpdateTrackingOption(r.Context(), params)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			writeError(w, http.StatusNotFound, CodeNotFound, "tracking option not found")
+			return
+		}
+		if isUniqueViolation(err) {
+			writeFieldError(w, http.StatusConflict, CodeNameTaken, "name", "the category already has an option with this name")
+			return
+		}
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to update tracking option")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, toTrackingOptionResponse(o))
+}
+
+// DELETE /tracking-categories/{id}/options/{option_id}
+func (s *Server) deleteTrackingOption(w http.ResponseWriter, r *http.Request) {
+	categoryID, optionID, ok := trackingOptionPath(w, r)
+	if !ok {
+		return
+	}
+
+	org := tenantFrom(r.Context())
+	n, err := org.q.DeleteTrackingOption(r.Context(), db.DeleteTrackingOptionParams{OrganisationID: org.id, CategoryID: categoryID, ID: optionID})
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" { // foreign_key_violation
+			writeError(w, http.StatusConflict, CodeTrackingInUse, "tracking option is used by ledger entries; deactivate it instead")
+			return
+		}
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to delete tracking option")
+		return
+	}
+	if n == 0 {
+		writeError(w, http.StatusNotFound, CodeNotFound, "tracking option not found")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) loadTrackingCategory(w http.ResponseWriter, r *http.Request) (db.TrackingCategory, bool) {
+	id, err := parseUUID(chi.URLParam(r, "id"))
+	if err != nil {
+		writeFieldError(w, http.StatusBadRequest, CodeInvalidFormat, "id", "invalid id")
+		return db.TrackingCategory{}, false
+	}
+
+	org := tenantFrom(r.Context())
+	c, err := org.q.GetTrackingCategory(r.Context(), db.GetTrackingCategoryParams{OrganisationID: org.id, ID: id})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			writeError(w, http.StatusNotFound, CodeNotFound, "tracking category not found")
+			return db.TrackingCategory{}, false
+		}
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to get tracking category")
+		return db.TrackingCategory{}, false
+	}
+	return c, true
+}
+
+func trackingOptionPath(w http.ResponseWriter, r *http.Request) (pgtype.UUID, pgtype.UUID, bool) {
+	categoryID, err := parseUUID(chi.URLParam(r, "id"))
+	if err != nil {
+		writeFieldError(w, http.StatusBadRequest, CodeInvalidFormat, "id", "invalid id")
+		return pgtype.UUID{}, pgtype.UUID{}, false
+	}
+	optionID, err := parseUUID(chi.URLParam(r, "option_id"))
+	if err != nil {
+		writeFieldError(w, http.StatusBadRequest, CodeInvalidFormat, "option_id", "invalid option_id")
+		return pgtype.UUID{}, pgtype.UUID{}, false
+	}
+	return categoryID, optionID, true
+}
+
+// decodeTrackingName reads the name from a category or option request body.
+func decodeTrackingName(w http.ResponseWriter, r *http.Request) (string, bool) {
+	var req trackingCategoryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, CodeInvalidJSON, "invalid json")
+		return "", false
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		writeFieldError(w, http.StatusBadRequest, CodeRequired, "name", "name is required")
+		return "", false
+	}
+	if len(name) > maxStringLength {
+		writeFieldError(w, http.StatusBadRequest, CodeTooLong, "name", "name too long")
+		return "", false
+	}
+	return name, true
+}
+
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
+
+// Entry dimensions
+
+// parseEntryDimensions normalises each entry's tags and tracking and checks
+// their shape, leaving option lookups to checkTracking. field names the
+// entry's fields in errors.
+func parseEntryDimensions(entries []ledgerEntryRequest, field func(i int, name string) string, errs *validationErrors) {
+	for i := range entries {
+		e := &entries[i]
+
+		tags := make([]string, 0, len(e.Tags))
+		for _, tag := range e.Tags {
+			tag = strings.ToLower(strings.TrimSpace(tag))
+			switch {
+			case tag == "":
+				errs.add(field(i, "tags"), CodeInvalidValue, "tags must not be empty")
+			case len(tag) > maxTagLength:
+				errs.add(field(i, "tags"), CodeTooLong, fmt.Sprintf("tag too long (max %d)", maxTagLength))
+			case !slices.Contains(tags, tag):
+				tags = append(tags, tag)
+			}
+		}
+		slices.Sort(tags)
+		e.Tags = tags
+		if len(tags) > maxEntryTags {
+			errs.add(field(i, "tags"), CodeTooMany, fmt.Sprintf("too many tags (max %d)", maxEntryTags))
+		}
+
+		if len(e.Tracking) > maxEntryTracking {
+			errs.add(field(i, "tracking"), CodeTooMany, fmt.Sprintf("too many tracking categories (max %d)", maxEntryTracking))
+		}
+		seen := make(map[string]bool, len(e.Tracking))
+		for j := range e.Tracking {
+			t := &e.Tracking[j]
+			prefix := fmt.Sprintf("%s[%d]", field(i, "tracking"), j)
+			if category, err := uuid.Parse(t.CategoryID); err != nil {
+				errs.add(prefix+".category_id", CodeInvalidFormat, "invalid category_id uuid")
+			} else if t.CategoryID = category.String(); seen[t.CategoryID] {
+				errs.add(prefix+".category_id", CodeInvalidValue, "an entry takes one option per tracking category")
+			}
+			seen[t.CategoryID] = true
+			if option, err := uuid.Parse(t.OptionID); err != nil {
+				errs.add(prefix+".option_id", CodeInvalidFormat, "invalid option_id uuid")
+			} else {
+				t.OptionID = option.String()
+			}
+		}
+	}
+}
+
+// trackingOptionIDs lists the options named by entries parsed with
+// parseEntryDimensions.
+func trackingOptionIDs(entries []ledgerEntryRequest) []pgtype.UUID {
+	var ids []pgtype.UUID
+	for _, e := range entries {
+		for _, t := range e.Tracking {
+			ids = append(ids, pgtype.UUID{Bytes: uuid.MustParse(t.OptionID), Valid: true})
+		}
+	}
+	return ids
+}
+
+// trackingOptionsByID maps options by their canonical UUID string.
+func trackingOptionsByID(options []db.TrackingOption) map[string]db.TrackingOption {
+	m := make(map[string]db.TrackingOption, len(options))
+	for _, o := range options {
+		m[uuid.UUID(o.ID.Bytes).String()] = o
+	}
+	return m
+}
+
+// checkTracking checks that every option exists, belongs to the category it
+// is given under and is still active. Reversing entries may keep options
+// deactivated since the entry they reverse was posted.
+func checkTracking(entries []ledgerEntryRequest, options map[string]db.TrackingOption, errs *validationErrors) {
+	for i, e := range entries {
+		for j, t := range e.Tracking {
+			field := fmt.Sprintf("%s[%d].option_id", entryField(i, "tracking"), j)
+			o, found := options[uuid.MustParse(t.OptionID).String()]
+			switch {
+			case !found:
+				errs.add(field, CodeTrackingOptionNotFound, fmt.Sprintf("tracking option not found: %s", t.OptionID))
+			case uuid.UUID(o.CategoryID.Bytes) != uuid.MustParse(t.CategoryID):
+				errs.add(field, CodeInvalidValue, "option belongs to a different tracking category")
+			case !o.Active && !e.reversal:
+				errs.add(field, CodeTrackingOptionInactive, fmt.Sprintf("tracking option is inactive: %s", o.Name))
+			}
+		}
+	}
+}
+
+// entryDimensions holds ledger entries' tags and tracking, keyed by entry ID.
+type entryDimensions struct {
+	tags     map[string][]string
+	tracking map[string][]entryTrackingResponse
+}
+
+// requestDimensions pairs request entries with the IDs they were stored
+// under.
+func requestDimensions(entries []ledgerEntryRequest, ids []pgtype.UUID) entryDimensions {
+	d := entryDimensions{tags: make(map[string][]string), tracking: make(map[string][]entryTrackingResponse)}
+	for i, e := range entries {
+		id := uuid.UUID(ids[i].Bytes).String()
+		if len(e.Tags) > 0 {
+			d.tags[id] = e.Tags
+		}
+		for _, t := range e.Tracking {
+			d.tracking[id] = append(d.tracking[id], entryTrackingResponse{
+				CategoryID: uuid.MustParse(t.CategoryID).String(),
+				OptionID:   uuid.MustParse(t.OptionID).String(),
+			})
+		}
+	}
+	return d
+}
+
+// saveEntryDimensions stores the tags and tracking of entries, already
+// validated, against the ledger entry IDs they were posted as.
+func saveEntryDimensions(ctx context.Context, q *db.Queries, org *tenant, entries []ledgerEntryRequest, ids []pgtype.UUID) error {
+	tags := db.CreateLedgerEntryTagsParams{OrganisationID: org.id}
+	tracking := db.CreateLedgerEntryTrackingParams{OrganisationID: org.id}
+	for i, e := range entries {
+		for _, tag := range e.Tags {
+			tags.LedgerEntryIds = append(tags.LedgerEntryIds, ids[i])
+			tags.Tags = append(tags.Tags, tag)
+		}
+		for _, t := range e.Tracking {
+			tracking.LedgerEntryIds = append(tracking.LedgerEntryIds, ids[i])
+			tracking.CategoryIds = append(tracking.CategoryIds, pgtype.UUID{Bytes: uuid.MustParse(t.CategoryID), Valid: true})
+			tracking.OptionIds = append(tracking.OptionIds, pgtype.UUID{Bytes: uuid.MustParse(t.OptionID), Valid: true})
+		}
+	}
+
+	if len(tags.Tags) > 0 {
+		if err := q.CreateLedgerEntryTags(ctx, tags); err != nil {
+			return err
+		}
+	}
+	if len(tracking.OptionIds) > 0 {
+		if err := q.CreateLedgerEntryTracking(ctx, tracking); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadEntryDimensions reads the tags and tracking of stored entries.
+func loadEntryDimensions(ctx context.Context, q *db.Queries, org *tenant, entries []db.LedgerEntry) (entryDimensions, error) {
+	d := entryDimensions{tags: make(map[string][]string), tracking: make(map[string][]entryTrackingResponse)}
+	ids := make([]pgtype.UUID, 0, len(entries))
+	for _, e := range entries {
+		ids = append(ids, e.ID)
+	}
+
+	tags, err := q.ListLedgerEntryTags(ctx, db.ListLedgerEntryTagsParams{OrganisationID: org.id, LedgerEntryIds: ids})
+	if err != nil {
+		return entryDimensions{}, err
+	}
+	for _, t := range tags {
+		id := uuid.UUID(t.LedgerEntryID.Bytes).String()
+		d.tags[id] = append(d.tags[id], t.Tag)
+	}
+
+	tracking, err := q.ListLedgerEntryTracking(ctx, db.ListLedgerEntryTrackingParams{OrganisationID: org.id, LedgerEntryIds: ids})
+	if err != nil {
+		return entryDimensions{}, err
+	}
+	for _, t := range tracking {
+		id := uuid.UUID(t.LedgerEntryID.Bytes).String()
+		d.tracking[id] = append(d.tracking[id], entryTrackingResponse{
+			CategoryID: uuid.UUID(t.CategoryID.Bytes).String(),
+			OptionID:   uuid.UUID(t.OptionID.Bytes).String(),
+		})
+	}
+	return d, nil
+}
+
+// attach copies the dimensions onto a transaction's entries.
+func (d entryDimensions) attach(resp *transactionResponse) {
+	for i := range resp.Entries {
+		e := &resp.Entries[i]
+		e.Tags = d.tags[e.ID]
+		e.Tracking = d.tracking[e.ID]
+	}
+}
+
+func toTrackingCategoryResponse(c db.TrackingCategory, options []db.TrackingOption) trackingCategoryResponse {
+	resp := trackingCategoryResponse{
+		ID:        uuid.UUID(c.ID.Bytes).String(),
+		Name:      c.Name,
+		Options:   []trackingOptionResponse{},
+		CreatedAt: c.CreatedAt.Time.Format(time.RFC3339Nano),
+		UpdatedAt: c.UpdatedAt.Time.Format(time.RFC3339Nano),
+	}
+	for _, o := range options {
+		if o.CategoryID == c.ID {
+			resp.Options = append(resp.Options, toTrackingOptionResponse(o))
+		}
+	}
+	return resp
+}
+
+func toTrackingOptionResponse(o db.TrackingOption) trackingOptionResponse {
+	return trackingOptionResponse{
+		ID:         uuid.UUID(o.ID.Bytes).String(),
+		CategoryID: uuid.UUID(o.CategoryID.Bytes).String(),
+		Name:       o.Name,
+		Active:     o.Active,
+		CreatedAt:  o.CreatedAt.Time.Format(time.RFC3339Nano),
+		UpdatedAt:  o.UpdatedAt.Time.Format(time.RFC3339Nano),
+	}
+}
@@ -0,0 +1,83 @@
+package httpserver
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+
+	db "github.com/LBaronceli/go-figure/internal/db/sqlc"
+)
+
+func TestParseEntryDimensionsNormalises(t *testing.T) {
+	category := "0B8A1C1E-8A8F-4B8E-9A57-3F1F5A0D2C11"
+	entries := []ledgerEntryRequest{{
+		Tags:     []string{" Travel ", "q3", "TRAVEL"},
+		Tracking: []entryTrackingRequest{{CategoryID: category, OptionID: "5d7e0a52-3c1b-4d0a-8f7e-2b9c4a6e1f30"}},
+	}}
+	var errs validationErrors
+	parseEntryDimensions(entries, entryField, &errs)
+
+	require.True(t, errs.empty())
+	require.Equal(t, []string{"q3", "travel"}, entries[0].Tags)
+	require.Equal(t, "0b8a1c1e-8a8f-4b8e-9a57-3f1f5a0d2c11", entries[0].Tracking[0].CategoryID)
+}
+
+func TestParseEntryDimensionsRejectsBadShape(t *testing.T) {
+	category := "0b8a1c1e-8a8f-4b8e-9a57-3f1f5a0d2c11"
+	option := "5d7e0a52-3c1b-4d0a-8f7e-2b9c4a6e1f30"
+	var errs validationErrors
+	parseEntryDimensions([]ledgerEntryRequest{{
+		Tags: []string{"  "},
+		Tracking: []entryTrackingRequest{
+			{CategoryID: category, OptionID: option},
+			{CategoryID: category, OptionID: "nope"},
+		},
+	}}, entryField, &errs)
+
+	fields := make([]string, 0, len(errs))
+	for _, e := range errs {
+		fields = append(fields, e.Field)
+	}
+	require.Equal(t, []string{"entries[0].tags", "entries[0].tracking[1].category_id", "entries[0].tracking[1].option_id"}, fields)
+}
+
+func TestCheckTracking(t *testing.T) {
+	category, other := uuid.New(), uuid.New()
+	active, inactive := uuid.New(), uuid.New()
+	options := trackingOptionsByID([]db.TrackingOption{
+		{ID: pgtype.UUID{Bytes: active, Valid: true}, CategoryID: pgtype.UUID{Bytes: category, Valid: true}, Active: true},
+		{ID: pgtype.UUID{Bytes: inactive, Valid: true}, CategoryID: pgtype.UUID{Bytes: category, Valid: true}},
+	})
+	entry := func(category, option uuid.UUID) ledgerEntryRequest {
+		return ledgerEntryRequest{Tracking: []entryTrackingRequest{{CategoryID: category.String(), OptionID: option.String()}}}
+	}
+
+	var errs validationErrors
+	checkTracking([]ledgerEntryRequest{
+		entry(category, active),
+		entry(category, inactive),
+		entry(other, active),
+		entry(category, uuid.New()),
+	}, options, &errs)
+
+	codes := make([]ErrorCode, 0, len(errs))
+	for _, e := range errs {
+		codes = append(codes, e.Code)
+	}
+	require.Equal(t, []ErrorCode{CodeTrackingOptionInactive, CodeInvalidValue, CodeTrackingOptionNotFound}, codes)
+
+	reversal := entry(category, inactive)
+	reversal.reversal = true
+	errs = nil
+	checkTracking([]ledgerEntryRequest{reversal}, options, &errs)
+	require.True(t, errs.empty())
+}
+
+func TestDimensionKeyIgnoresOrder(t *testing.T) {
+	a := ledgerEntryRequest{AccountID: "acc", Tags: []string{"b", "a"}, Tracking: []entryTrackingRequest{{CategoryID: "2", OptionID: "y"}, {CategoryID: "1", OptionID: "x"}}}
+	b := ledgerEntryRequest{AccountID: "acc", Tags: []string{"a", "b"}, Tracking: []entryTrackingRequest{{CategoryID: "1", OptionID: "x"}, {CategoryID: "2", OptionID: "y"}}}
+	require.Equal(t, dimensionKey(a), dimensionKey(b))
+	require.NotEqual(t, dimensionKey(a), dimensionKey(ledgerEntryRequest{AccountID: "acc"}))
+}
@@ -1,21 +1,30 @@
 package httpserver
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgtype"
 
+	"github.com/LBaronceli/go-figure/internal/audit"
 	db "github.com/LBaronceli/go-figure/internal/db/sqlc"
 )
 
+// transactionSorts are the orders GET /transactions accepts; the first is
+// the default.
+var transactionSorts = []string{"posted_on", "posted_at", "amount", "created_at"}
+
 // maxStringLength exists so we can prevent someone from uploading a massive string.
 const (
 	maxLedgerEntries = 100
@@ -23,209 +32,240 @@ const (
 )
 
 type ledgerEntryRequest struct {
-	AccountID string `json:"account_id"`
-	Amount    int64  `json:"amount"` // Minor units
+	AccountID string                 `json:"account_id" openapi:"format=uuid"`
+	Amount    int64                  `json:"amount" doc:"Minor units; debits positive, credits negative"` // Minor units
+	Tags      []string               `json:"tags,omitempty" openapi:"maxItems=20" doc:"Free-form labels, stored trimmed and lowercased"`
+	Tracking  []entryTrackingRequest `json:"tracking,omitempty" openapi:"maxItems=10" doc:"At most one active option per tracking category"`
+
+	// reversal is set on entries that reverse a posted entry.
+	reversal bool
 }
 
 type createTransactionRequest struct {
-	IdempotencyKey string               `json:"idempotency_key"`
-	Description    string               `json:"description"`
-	Source         string               `json:"source"`
-	PostedAt       string               `json:"posted_at"` // ISO8601
-	Entries        []ledgerEntryRequest `json:"entries"`
+	IdempotencyKey string               `json:"idempotency_key" openapi:"minLength=1,maxLength=500"`
+	Description    string               `json:"description" openapi:"optional,maxLength=500"`
+	Source         string               `json:"source" openapi:"enum=manual|csv|api"`
+	PostedOn       string               `json:"posted_on" openapi:"optional,format=date" doc:"Accounting date (YYYY-MM-DD); defaults to the day posted_at falls on in the organisation's timezone"`
+	PostedAt       string               `json:"posted_at" openapi:"optional,format=date-time" doc:"Capture timestamp; defaults to now"` // ISO8601
+	ContactID      string               `json:"contact_id" openapi:"optional,format=uuid" doc:"Defaults to the contact whose alias matches the description"`
+	Entries        []ledgerEntryRequest `json:"entries" openapi:"minItems=2,maxItems=100"`
+
+	// correctsEntryID is set by endpoints that post correcting transactions.
+	correctsEntryID pgtype.UUID
+	// billID is set by bill payments, which post with source bill.
+	billID pgtype.UUID
+	// afterPost, when set, runs in the same database transaction once the
+	// transaction and its entries are saved, so endpoints can record what
+	// the posting was for. It writes the error response itself and reports
+	// whether to carry on.
+	afterPost func(w http.ResponseWriter, r *http.Request, q *db.Queries, t db.Transaction) bool
 }
 
 type ledgerEntryResponse struct {
-	ID        string `json:"id"`
-	AccountID string `json:"account_id"`
-	Amount    int64  `json:"amount"`
-	Currency  string `json:"currency"`
+	ID        string                  `json:"id" openapi:"format=uuid"`
+	AccountID string                  `json:"account_id" openapi:"format=uuid"`
+	Amount    int64                   `json:"amount"`
+	Currency  string                  `json:"currency"`
+	Tags      []string                `json:"tags,omitempty"`
+	Tracking  []entryTrackingResponse `json:"tracking,omitempty"`
 }
 
 type transactionResponse struct {
-	ID             string                `json:"id"`
-	IdempotencyKey string                `json:"idempotency_key"`
-	Description    string                `json:"description"`
-	Source         string                `json:"source"`
-	PostedAt       string                `json:"posted_at"`
-	CreatedAt      string                `json:"created_at"`
-	Entries        []ledgerEntryResponse `json:"entries,omitempty"`
+	ID              string                `json:"id" openapi:"format=uuid"`
+	IdempotencyKey  string                `json:"idempotency_key" doc:"Empty once the key's retention window has passed"`
+	Description     string                `json:"description"`
+	Source          string                `json:"source"`
+	PostedOn        string                `json:"posted_on" openapi:"format=date" doc:"Accounting date"`
+	PostedAt        string                `json:"posted_at" openapi:"format=date-time" doc:"Capture timestamp"`
+	CreatedAt       string                `json:"created_at" openapi:"format=date-time"`
+	CorrectsEntryID string                `json:"corrects_entry_id,omitempty" openapi:"format=uuid" doc:"Ledger entry this transaction reverses and reposts, for corrections such as splits"`
+	ContactID       string                `json:"contact_id,omitempty" openapi:"format=uuid"`
+	BillID          string                `json:"bill_id,omitempty" openapi:"format=uuid" doc:"Bill the transaction approves, pays or voids"`
+	Entries         []ledgerEntryResponse `json:"entries,omitempty"`
+	Attachments     []attachmentResponse  `json:"attachments,omitempty" doc:"Receipts and documents; only returned by GET /transactions/{id}"`
 }
 
 // POST /transactions
 func (s *Server) createTransaction(w http.ResponseWriter, r *http.Request) {
 	var req createTransactionRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "invalid json", http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, CodeInvalidJSON, "invalid json")
 		return
 	}
 
-	req.IdempotencyKey = strings.TrimSpace(req.IdempotencyKey)
-	req.Description = strings.TrimSpace(req.Description)
-	req.Source = strings.TrimSpace(strings.ToLower(req.Source))
+	s.postTransaction(w, r, req)
+}
 
-	if req.IdempotencyKey == "" {
-		http.Error(w, "missing idempotency_key", http.StatusBadRequest)
+// postTransaction validates and posts req, or replays the transaction its
+// idempotency key already names.
+func (s *Server) postTransaction(w http.ResponseWriter, r *http.Request, req createTransactionRequest) {
+	var errs validationErrors
+	postedAt, postedOn, accountIDs := parseTransactionRequest(&req, &errs)
+	if !errs.empty() {
+		writeValidationErrors(w, errs)
 		return
 	}
-	if len(req.IdempotencyKey) > maxStringLength {
-		http.Error(w, "idempotency_key too long", http.StatusBadRequest)
-		return
-	}
-	if len(req.Description) > maxStringLength {
-		http.Error(w, "description too long", http.StatusBadRequest)
-		return
-	}
-	if req.Source != "manual" && req.Source != "csv" && req.Source != "api" {
-		http.Error(w, "invalid source (must be manual, csv, or api)", http.StatusBadRequest)
-		return
-	}
-	if len(req.Entries) < 2 {
-		http.Error(w, "transaction must have at least 2 entries", http.StatusBadRequest)
-		return
+
+	org := tenantFrom(r.Context())
+	// the accounting date is the organisation's day, not the server's
+	if !postedOn.Valid {
+		postedOn = pgtype.Date{Time: org.calendar.Date(postedAt.Time), Valid: true}
 	}
-	if len(req.Entries) > maxLedgerEntries {
-		http.Error(w, fmt.Sprintf("too many entries (max %d)", maxLedgerEntries), http.StatusBadRequest)
+
+	// scoped to the organisation, so another organisation's account reads as
+	// not found
+	accounts, err := org.q.GetAccountsByIDs(r.Context(), db.GetAccountsByIDsParams{
+		OrganisationID: org.id,
+		Ids:            accountIDs,
+	})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to fetch accounts")
 		return
 	}
 
-	var postedAt pgtype.Timestamptz
-	if req.PostedAt != "" {
-		t, err := time.Parse(time.RFC3339, req.PostedAt)
-		if err != nil {
-			http.Error(w, "invalid posted_at format (use RFC3339)", http.StatusBadRequest)
-			return
-		}
-		postedAt = pgtype.Timestamptz{Time: t, Valid: true}
-	} else {
-		postedAt = pgtype.Timestamptz{Time: time.Now(), Valid: true}
+	commonCurrency, archived := checkEntries(req.Entries, accountsByID(accounts), &errs)
+	if archived != nil {
+		writeFieldError(w, http.StatusConflict, archived.Code, archived.Field, archived.Message)
+		return
 	}
-
-	// resolve accounts and validate logic
-	accountIDs := make([]pgtype.UUID, 0, len(req.Entries))
-
-	for _, entry := range req.Entries {
-		id, err := parseUUID(entry.AccountID)
+	if optionIDs := trackingOptionIDs(req.Entries); len(optionIDs) > 0 {
+		options, err := org.q.GetTrackingOptionsByIDs(r.Context(), db.GetTrackingOptionsByIDsParams{OrganisationID: org.id, Ids: optionIDs})
 		if err != nil {
-			http.Error(w, "invalid account_id uuid", http.StatusBadRequest)
+			writeError(w, http.StatusInternalServerError, CodeInternal, "failed to fetch tracking options")
 			return
 		}
-		accountIDs = append(accountIDs, id)
-
+		checkTracking(req.Entries, trackingOptionsByID(options), &errs)
 	}
-
-	accounts, err := s.q.GetAccountsByIDs(r.Context(), accountIDs)
+	contactID, err := resolveContact(r.Context(), org, req, &errs)
 	if err != nil {
-		http.Error(w, "failed to fetch accounts", http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to resolve contact")
 		return
 	}
-
-	// Map accounts for easy lookup
-	accMap := make(map[string]db.Account)
-	for _, acc := range accounts {
-		if acc.ID.Valid {
-			idStr := uuid.UUID(acc.ID.Bytes).String()
-			accMap[idStr] = acc
-		}
+	if !errs.empty() {
+		writeValidationErrors(w, errs)
+		return
 	}
 
-	// Validate Existence, Currencies, and Balance
-	var sum int64
-	var commonCurrency string
-
-	for i, entry := range req.Entries {
-		// Normalize ID string from request (assuming parseUUID validated format implicitly)
-		uid, _ := uuid.Parse(entry.AccountID) // safe because parseUUID passed
-		accID := uid.String()
-
-		acc, found := accMap[accID]
-		if !found {
-			http.Error(w, fmt.Sprintf("account not found: %s", entry.AccountID), http.StatusBadRequest)
-			return
-		}
-
-		if i == 0 {
-			commonCurrency = acc.Currency
-		} else {
-			if acc.Currency != commonCurrency {
-				http.Error(w, "multi-currency transactions not supported yet (all accounts must have same currency)", http.StatusBadRequest)
-				return
-			}
-		}
-
-		// Check overflow
-		if (entry.Amount > 0 && sum > (1<<63-1)-entry.Amount) || (entry.Amount < 0 && sum < -(1<<63-1)-entry.Amount) {
-			http.Error(w, "transaction amount overflow", http.StatusBadRequest)
-			return
-		}
-		sum += entry.Amount
+	// Idempotency: a key already bound to a transaction is a replay (or a
+	// misuse) and must not post again. Keys past their retention window are
+	// released first so they can be reused.
+	idempotencyKey := pgtype.Text{String: req.IdempotencyKey, Valid: true}
+	hash := req.requestHash()
+
+	if _, err := org.q.ReleaseExpiredIdempotencyKey(r.Context(), db.ReleaseExpiredIdempotencyKeyParams{
+		OrganisationID: org.id,
+		IdempotencyKey: idempotencyKey,
+		Cutoff:         s.idempotencyCutoff(),
+	}); err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to check idempotency key")
+		return
 	}
 
-	if sum != 0 {
-		http.Error(w, "transaction is not balanced (sum must be 0)", http.StatusBadRequest)
+	byKey := db.GetTransactionByIdempotencyKeyParams{OrganisationID: org.id, IdempotencyKey: idempotencyKey}
+	existing, err := org.q.GetTransactionByIdempotencyKey(r.Context(), byKey)
+	if err == nil {
+		s.replayTransaction(w, r, existing, hash)
+		return
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to check idempotency key")
 		return
 	}
 
 	// Execute DB Transaction
-	tx, err := s.db.Begin(r.Context())
+	tx, qtx, err := org.begin(r.Context())
 	if err != nil {
-		http.Error(w, "failed to begin transaction", http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to begin transaction")
 		return
 	}
 	defer tx.Rollback(r.Context())
 
-	qtx := s.q.WithTx(tx)
+	// Filed figures must not move: reject postings into a locked period.
+	lock, err := checkPeriodOpen(r.Context(), qtx, org, postedOn.Time)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to check period locks")
+		return
+	}
+	if lock != nil {
+		writePeriodLocked(w, "posted_on", lock)
+		return
+	}
 
 	// Create Header
 	t, err := qtx.CreateTransaction(r.Context(), db.CreateTransactionParams{
-		IdempotencyKey: req.IdempotencyKey,
-		Description:    pgtype.Text{String: req.Description, Valid: req.Description != ""},
-		Source:         req.Source,
-		PostedAt:       postedAt,
+		OrganisationID:  org.id,
+		IdempotencyKey:  idempotencyKey,
+		Description:     pgtype.Text{String: req.Description, Valid: req.Description != ""},
+		Source:          req.Source,
+		PostedAt:        postedAt,
+		RequestHash:     hash,
+		PostedOn:        postedOn,
+		CorrectsEntryID: req.correctsEntryID,
+		ContactID:       contactID,
+		BillID:          req.billID,
 	})
 	if err != nil {
 		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == correctsEntryUnique {
+			writeError(w, http.StatusConflict, CodeEntryAlreadyCorrected, "ledger entry has already been corrected; correct the correcting transaction instead")
+			return
+		}
 		if errors.As(err, &pgErr) && pgErr.Code == "23505" { // unique_violation
-			// Idempotency: fetch existing
-			existing, getErr := s.q.GetTransactionByIdempotencyKey(r.Context(), req.IdempotencyKey)
+			// A concurrent request with the same key won the race. The
+			// failed insert aborted tx, so look the winner up outside it.
+			_ = tx.Rollback(r.Context())
+			existing, getErr := org.q.GetTransactionByIdempotencyKey(r.Context(), byKey)
 			if getErr != nil {
-				http.Error(w, "idempotency conflict handling failed", http.StatusInternalServerError)
+				writeError(w, http.StatusInternalServerError, CodeInternal, "idempotency conflict handling failed")
 				return
 			}
-			// In a real strict implementation, we would verify the payload matches.
-			// For now, we return the existing transaction as per plan.
-			// We should fetch entries too.
-			entries, _ := s.q.ListLedgerEntries(r.Context(), existing.ID)
-			writeJSON(w, http.StatusOK, toFullTransactionResponse(existing, entries))
+			s.replayTransaction(w, r, existing, hash)
 			return
 		}
-		http.Error(w, "failed to create transaction", http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to create transaction")
 		return
 	}
 
 	// Create Entries
 	createdEntries := make([]db.LedgerEntry, 0, len(req.Entries))
+	entryIDs := make([]pgtype.UUID, 0, len(req.Entries))
 	for _, entry := range req.Entries {
 		accID, _ := parseUUID(entry.AccountID)
 		le, err := qtx.CreateLedgerEntry(r.Context(), db.CreateLedgerEntryParams{
-			TransactionID: t.ID,
-			AccountID:     accID,
-			AmountMinor:   entry.Amount,
-			Currency:      commonCurrency,
+			OrganisationID: org.id,
+			TransactionID:  t.ID,
+			AccountID:      accID,
+			AmountMinor:    entry.Amount,
+			Currency:       commonCurrency,
 		})
 		if err != nil {
-			http.Error(w, "failed to create ledger entry", http.StatusInternalServerError)
+			writeError(w, http.StatusInternalServerError, CodeInternal, "failed to create ledger entry")
 			return
 		}
 		createdEntries = append(createdEntries, le)
+		entryIDs = append(entryIDs, le.ID)
+	}
+	if err := saveEntryDimensions(r.Context(), qtx, org, req.Entries, entryIDs); err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to save entry tags and tracking")
+		return
+	}
+	if req.afterPost != nil && !req.afterPost(w, r, qtx, t) {
+		return
+	}
+
+	resp := toFullTransactionResponse(t, createdEntries)
+	requestDimensions(req.Entries, entryIDs).attach(&resp)
+	if err := recordAudit(r.Context(), qtx, org, audit.EntityTransaction, t.ID, audit.ActionCreate, nil, resp); err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to record audit event")
+		return
 	}
 
 	if err := tx.Commit(r.Context()); err != nil {
-		http.Error(w, "failed to commit transaction", http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to commit transaction")
 		return
 	}
 
-	writeJSON(w, http.StatusCreated, toFullTransactionResponse(t, createdEntries))
+	writeJSON(w, http.StatusCreated, resp)
 }
 
 // GET /transactions
@@ -235,45 +275,124 @@ func (s *Server) listTransactions(w http.ResponseWriter, r *http.Request) {
 	offset := 0
 
 	// Filters
+	var errs validationErrors
+
 	var accountID pgtype.UUID
 	if v := r.URL.Query().Get("account_id"); v != "" {
 		id, err := parseUUID(v)
 		if err != nil {
-			http.Error(w, "invalid account_id", http.StatusBadRequest)
-			return
+			errs.add("account_id", CodeInvalidFormat, "invalid account_id")
 		}
 		accountID = id
 	}
 
-	var startDate pgtype.Timestamptz
-	if v := r.URL.Query().Get("start_date"); v != "" {
-		t, err := time.Parse(time.RFC3339, v)
+	var tag pgtype.Text
+	if v := r.URL.Query().Get("tag"); v != "" {
+		tag = pgtype.Text{String: strings.ToLower(strings.TrimSpace(v)), Valid: true}
+	}
+
+	var trackingOptionID pgtype.UUID
+	if v := r.URL.Query().Get("tracking_option_id"); v != "" {
+		id, err := parseUUID(v)
 		if err != nil {
-			http.Error(w, "invalid start_date (use RFC3339)", http.StatusBadRequest)
-			return
+			errs.add("tracking_option_id", CodeInvalidFormat, "invalid tracking_option_id")
 		}
-		startDate = pgtype.Timestamptz{Time: t, Valid: true}
+		trackingOptionID = id
 	}
 
-	var endDate pgtype.Timestamptz
-	if v := r.URL.Query().Get("end_date"); v != "" {
-		t, err := time.Parse(time.RFC3339, v)
+	var source pgtype.Text
+	if v := r.URL.Query().Get("source"); v != "" {
+		v = strings.ToLower(strings.TrimSpace(v))
+		if v != "manual" && v != "csv" && v != "api" && v != sourceClosing && v != sourceInvoice && v != sourceBill {
+			errs.add("source", CodeInvalidValue, "invalid source (must be manual, csv, api, closing, invoice, or bill)")
+		}
+		source = pgtype.Text{String: v, Valid: true}
+	}
+
+	minAmount := parseAmountFilter(r, "min_amount", &errs)
+	maxAmount := parseAmountFilter(r, "max_amount", &errs)
+	if minAmount.Valid && maxAmount.Valid && minAmount.Int64 > maxAmount.Int64 {
+		errs.add("min_amount", CodeOutOfRange, "min_amount is greater than max_amount")
+	}
+
+	var descriptionContains pgtype.Text
+	if v := strings.TrimSpace(r.URL.Query().Get("description_contains")); v != "" {
+		if len(v) > maxStringLength {
+			errs.add("description_contains", CodeTooLong, "description_contains too long")
+		}
+		descriptionContains = pgtype.Text{String: escapeLike(v), Valid: true}
+	}
+
+	var counterpartyID pgtype.UUID
+	if v := r.URL.Query().Get("counterparty_account_id"); v != "" {
+		id, err := parseUUID(v)
 		if err != nil {
-			http.Error(w, "invalid end_date (use RFC3339)", http.StatusBadRequest)
-			return
+			errs.add("counterparty_account_id", CodeInvalidFormat, "invalid counterparty_account_id")
 		}
-		endDate = pgtype.Timestamptz{Time: t, Valid: true}
+		counterpartyID = id
 	}
 
-	txs, err := s.q.ListTransactions(r.Context(), db.ListTransactionsParams{
-		Limit:     int32(limit),
-		Offset:    int32(offset),
-		AccountID: accountID,
-		StartDate: startDate,
-		EndDate:   endDate,
-	})
+	var contactID pgtype.UUID
+	if v := r.URL.Query().Get("contact_id"); v != "" {
+		id, err := parseUUID(v)
+		if err != nil {
+			errs.add("contact_id", CodeInvalidFormat, "invalid contact_id")
+		}
+		contactID = id
+	}
+
+	var hasTag pgtype.Bool
+	if v := r.URL.Query().Get("has_tag"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			errs.add("has_tag", CodeInvalidValue, "invalid has_tag (must be true or false)")
+		}
+		hasTag = pgtype.Bool{Bool: b, Valid: true}
+	}
+
+	sort := "posted_on"
+	if v := r.URL.Query().Get("sort"); v != "" {
+		if !slices.Contains(transactionSorts, v) {
+			errs.add("sort", CodeInvalidValue, "invalid sort (must be posted_on, posted_at, amount, or created_at)")
+		}
+		sort = v
+	}
+	ascending := false
+	switch r.URL.Query().Get("order") {
+	case "", "desc":
+	case "asc":
+		ascending = true
+	default:
+		errs.add("order", CodeInvalidValue, "invalid order (must be asc or desc)")
+	}
+
+	org := tenantFrom(r.Context())
+	startDate, endDate := parseDateRange(r, org, &errs)
+
+	if !errs.empty() {
+		writeValidationErrors(w, errs)
+		return
+	}
+
+	txs, err := listTransactions(r.Context(), org.q, db.ListTransactionsParams{
+		OrganisationID:        org.id,
+		Limit:                 int32(limit),
+		Offset:                int32(offset),
+		AccountID:             accountID,
+		StartDate:             startDate,
+		EndDate:               endDate,
+		Tag:                   tag,
+		TrackingOptionID:      trackingOptionID,
+		Source:                source,
+		MinAmount:             minAmount,
+		MaxAmount:             maxAmount,
+		DescriptionContains:   descriptionContains,
+		CounterpartyAccountID: counterpartyID,
+		ContactID:             contactID,
+		HasTag:                hasTag,
+	}, sort, ascending)
 	if err != nil {
-		http.Error(w, "failed to list transactions", http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to list transactions")
 		return
 	}
 
@@ -286,32 +405,256 @@ func (s *Server) listTransactions(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, resp)
 }
 
+// listTransactions runs the ListTransactions query for sort, one of
+// transactionSorts, in ascending or descending order.
+func listTransactions(ctx context.Context, q *db.Queries, arg db.ListTransactionsParams, sort string, ascending bool) ([]db.Transaction, error) {
+	switch {
+	case sort == "posted_on" && ascending:
+		return q.ListTransactionsPostedOnAsc(ctx, db.ListTransactionsPostedOnAscParams(arg))
+	case sort == "posted_at" && ascending:
+		return q.ListTransactionsPostedAtAsc(ctx, db.ListTransactionsPostedAtAscParams(arg))
+	case sort == "posted_at":
+		return q.ListTransactionsPostedAtDesc(ctx, db.ListTransactionsPostedAtDescParams(arg))
+	case sort == "amount" && ascending:
+		return q.ListTransactionsAmountAsc(ctx, db.ListTransactionsAmountAscParams(arg))
+	case sort == "amount":
+		return q.ListTransactionsAmountDesc(ctx, db.ListTransactionsAmountDescParams(arg))
+	case sort == "created_at" && ascending:
+		return q.ListTransactionsCreatedAtAsc(ctx, db.ListTransactionsCreatedAtAscParams(arg))
+	case sort == "created_at":
+		return q.ListTransactionsCreatedAtDesc(ctx, db.ListTransactionsCreatedAtDescParams(arg))
+	}
+	return q.ListTransactions(ctx, arg)
+}
+
 // GET /transactions/{id}
 func (s *Server) getTransaction(w http.ResponseWriter, r *http.Request) {
 	idStr := chi.URLParam(r, "id")
 	id, err := parseUUID(idStr)
 	if err != nil {
-		http.Error(w, "invalid id", http.StatusBadRequest)
+		writeFieldError(w, http.StatusBadRequest, CodeInvalidFormat, "id", "invalid id")
 		return
 	}
 
-	t, err := s.q.GetTransaction(r.Context(), id)
+	org := tenantFrom(r.Context())
+	t, err := org.q.GetTransaction(r.Context(), db.GetTransactionParams{OrganisationID: org.id, ID: id})
 	if err != nil {
-		http.Error(w, "transaction not found", http.StatusNotFound)
+		writeError(w, http.StatusNotFound, CodeNotFound, "transaction not found")
 		return
 	}
 
-	entries, err := s.q.ListLedgerEntries(r.Context(), t.ID)
+	entries, err := org.q.ListLedgerEntries(r.Context(), db.ListLedgerEntriesParams{OrganisationID: org.id, TransactionID: t.ID})
 	if err != nil {
-		http.Error(w, "failed to fetch ledger entries", http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to fetch ledger entries")
+		return
+	}
+	dims, err := loadEntryDimensions(r.Context(), org.q, org, entries)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to fetch entry tags and tracking")
 		return
 	}
 
-	writeJSON(w, http.StatusOK, toFullTransactionResponse(t, entries))
+	attachments, err := org.q.ListAttachments(r.Context(), db.ListAttachmentsParams{OrganisationID: org.id, TransactionID: t.ID})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to fetch attachments")
+		return
+	}
+
+	resp := toFullTransactionResponse(t, entries)
+	dims.attach(&resp)
+	for _, a := range attachments {
+		resp.Attachments = append(resp.Attachments, toAttachmentResponse(a))
+	}
+	writeJSON(w, http.StatusOK, resp)
 }
 
 // Helpers
 
+// parseTransactionRequest normalises req and checks everything that can be
+// checked without the database. It returns the capture time, the accounting
+// date when one was given, and the entries' account IDs.
+func parseTransactionRequest(req *createTransactionRequest, errs *validationErrors) (pgtype.Timestamptz, pgtype.Date, []pgtype.UUID) {
+	req.IdempotencyKey = strings.TrimSpace(req.IdempotencyKey)
+	req.Description = strings.TrimSpace(req.Description)
+	req.Source = strings.TrimSpace(strings.ToLower(req.Source))
+
+	if req.IdempotencyKey == "" {
+		errs.add("idempotency_key", CodeRequired, "missing idempotency_key")
+	} else if len(req.IdempotencyKey) > maxStringLength {
+		errs.add("idempotency_key", CodeTooLong, "idempotency_key too long")
+	}
+	if len(req.Description) > maxStringLength {
+		errs.add("description", CodeTooLong, "description too long")
+	}
+	switch {
+	case req.Source == "manual" || req.Source == "csv" || req.Source == "api":
+	case req.Source == sourceBill && req.billID.Valid:
+	default:
+		errs.add("source", CodeInvalidValue, "invalid source (must be manual, csv, or api)")
+	}
+	if len(req.Entries) < 2 {
+		errs.add("entries", CodeTooFew, "transaction must have at least 2 entries")
+	}
+	if len(req.Entries) > maxLedgerEntries {
+		errs.add("entries", CodeTooMany, fmt.Sprintf("too many entries (max %d)", maxLedgerEntries))
+	}
+
+	var postedAt pgtype.Timestamptz
+	if req.PostedAt != "" {
+		t, err := time.Parse(time.RFC3339, req.PostedAt)
+		if err != nil {
+			errs.add("posted_at", CodeInvalidFormat, "invalid posted_at format (use RFC3339)")
+		}
+		postedAt = pgtype.Timestamptz{Time: t, Valid: true}
+	} else {
+		postedAt = pgtype.Timestamptz{Time: time.Now(), Valid: true}
+	}
+
+	var postedOn pgtype.Date
+	if req.PostedOn != "" {
+		d, err := parseDate(req.PostedOn)
+		if err != nil {
+			errs.add("posted_on", CodeInvalidFormat, "invalid posted_on format (use YYYY-MM-DD)")
+		}
+		postedOn = pgtype.Date{Time: d, Valid: true}
+	}
+
+	if req.ContactID != "" {
+		if id, err := uuid.Parse(req.ContactID); err != nil {
+			errs.add("contact_id", CodeInvalidFormat, "invalid contact_id uuid")
+		} else {
+			req.ContactID = id.String()
+		}
+	}
+
+	accountIDs := make([]pgtype.UUID, 0, len(req.Entries))
+	for i, entry := range req.Entries {
+		id, err := parseUUID(entry.AccountID)
+		if err != nil {
+			errs.add(entryField(i, "account_id"), CodeInvalidFormat, "invalid account_id uuid")
+			continue
+		}
+		accountIDs = append(accountIDs, id)
+	}
+	parseEntryDimensions(req.Entries, entryField, errs)
+
+	return postedAt, postedOn, accountIDs
+}
+
+// checkEntries resolves each entry's account and checks that the entries
+// balance in a single currency. An archived account is returned on its own
+// because it is a conflict with the account's state, not a bad request.
+func checkEntries(entries []ledgerEntryRequest, accounts map[string]db.Account, errs *validationErrors) (string, *fieldError) {
+	var sum int64
+	var commonCurrency string
+	var archived *fieldError
+
+	for i, entry := range entries {
+		// Normalize ID string from request (parseTransactionRequest validated the format)
+		uid, _ := uuid.Parse(entry.AccountID)
+
+		acc, found := accounts[uid.String()]
+		if !found {
+			errs.add(entryField(i, "account_id"), CodeAccountNotFound, fmt.Sprintf("account not found: %s", entry.AccountID))
+			continue
+		}
+		if acc.ArchivedAt.Valid && archived == nil {
+			archived = &fieldError{Field: entryField(i, "account_id"), Code: CodeAccountArchived, Message: fmt.Sprintf("account is archived: %s", entry.AccountID)}
+		}
+
+		if commonCurrency == "" {
+			commonCurrency = acc.Currency
+		} else if acc.Currency != commonCurrency {
+			errs.add(entryField(i, "account_id"), CodeCurrencyMismatch, "multi-currency transactions not supported yet (all accounts must have same currency)")
+		}
+
+		// Check overflow
+		if (entry.Amount > 0 && sum > (1<<63-1)-entry.Amount) || (entry.Amount < 0 && sum < -(1<<63-1)-entry.Amount) {
+			errs.add(entryField(i, "amount"), CodeAmountOverflow, "transaction amount overflow")
+			continue
+		}
+		sum += entry.Amount
+	}
+
+	if sum != 0 {
+		errs.add("entries", CodeTransactionNotBalanced, "transaction is not balanced (sum must be 0)")
+	}
+	return commonCurrency, archived
+}
+
+// accountsByID maps accounts by their canonical UUID string.
+func accountsByID(accounts []db.Account) map[string]db.Account {
+	m := make(map[string]db.Account, len(accounts))
+	for _, acc := range accounts {
+		if acc.ID.Valid {
+			m[uuid.UUID(acc.ID.Bytes).String()] = acc
+		}
+	}
+	return m
+}
+
+// parseAmountFilter reads an optional amount in minor units from the query.
+func parseAmountFilter(r *http.Request, name string, errs *validationErrors) pgtype.Int8 {
+	v := r.URL.Query().Get(name)
+	if v == "" {
+		return pgtype.Int8{}
+	}
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		errs.add(name, CodeInvalidFormat, fmt.Sprintf("invalid %s (use an integer in minor units)", name))
+	}
+	return pgtype.Int8{Int64: n, Valid: true}
+}
+
+// escapeLike escapes the LIKE wildcards in s so it matches literally.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
+// parseDate parses a YYYY-MM-DD calendar date.
+func parseDate(s string) (time.Time, error) {
+	return time.Parse(time.DateOnly, s)
+}
+
+// parseDateRange reads the start_date, end_date and period query parameters
+// shared by listings and reports. A period is resolved in the organisation's
+// calendar and replaces the explicit dates.
+func parseDateRange(r *http.Request, org *tenant, errs *validationErrors) (pgtype.Date, pgtype.Date) {
+	var startDate pgtype.Date
+	if v := r.URL.Query().Get("start_date"); v != "" {
+		d, err := parseDate(v)
+		if err != nil {
+			errs.add("start_date", CodeInvalidFormat, "invalid start_date (use YYYY-MM-DD)")
+		}
+		startDate = pgtype.Date{Time: d, Valid: true}
+	}
+
+	var endDate pgtype.Date
+	if v := r.URL.Query().Get("end_date"); v != "" {
+		d, err := parseDate(v)
+		if err != nil {
+			errs.add("end_date", CodeInvalidFormat, "invalid end_date (use YYYY-MM-DD)")
+		}
+		endDate = pgtype.Date{Time: d, Valid: true}
+	}
+
+	if v := r.URL.Query().Get("period"); v != "" {
+		if startDate.Valid || endDate.Valid {
+			errs.add("period", CodeInvalidValue, "use either period or start_date and end_date")
+		} else if p, err := org.calendar.Parse(v); err != nil {
+			errs.add("period", CodeInvalidFormat, err.Error())
+		} else {
+			startDate = pgtype.Date{Time: p.FirstDay(), Valid: true}
+			endDate = pgtype.Date{Time: p.LastDay(), Valid: true}
+		}
+	}
+	return startDate, endDate
+}
+
+func entryField(i int, name string) string {
+	return fmt.Sprintf("entries[%d].%s", i, name)
+}
+
 func toTransactionResponse(t db.Transaction) transactionResponse {
 	idStr := uuid.UUID(t.ID.Bytes).String()
 
@@ -322,13 +665,22 @@ func toTransactionResponse(t db.Transaction) transactionResponse {
 
 	created := t.CreatedAt.Time.Format(time.RFC3339Nano)
 
+	corrects := ""
+	if t.CorrectsEntryID.Valid {
+		corrects = uuid.UUID(t.CorrectsEntryID.Bytes).String()
+	}
+
 	return transactionResponse{
-		ID:             idStr,
-		IdempotencyKey: t.IdempotencyKey,
-		Description:    t.Description.String,
-		Source:         t.Source,
-		PostedAt:       posted,
-		CreatedAt:      created,
+		ID:              idStr,
+		IdempotencyKey:  t.IdempotencyKey.String,
+		Description:     t.Description.String,
+		Source:          t.Source,
+		PostedOn:        t.PostedOn.Time.Format(time.DateOnly),
+		PostedAt:        posted,
+		CreatedAt:       created,
+		CorrectsEntryID: corrects,
+		ContactID:       uuidString(t.ContactID),
+		BillID:          uuidString(t.BillID),
 	}
 }
 
@@ -0,0 +1,445 @@
+package httpserver
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/LBaronceli/go-figure/internal/audit"
+	"github.com/LBaronceli/go-figure/internal/contacts"
+	db "github.com/LBaronceli/go-figure/internal/db/sqlc"
+)
+
+const maxBatchTransactions = 10000
+
+// Batch modes.
+const (
+	batchAllOrNothing = "all_or_nothing"
+	batchPerItem      = "per_item"
+)
+
+// Batch item outcomes.
+const (
+	batchCreated  = "created"
+	batchReplayed = "replayed"
+	batchFailed   = "failed"
+)
+
+type createTransactionsBatchRequest struct {
+	Mode         string                     `json:"mode" openapi:"enum=all_or_nothing|per_item" doc:"all_or_nothing posts nothing unless every transaction is valid; per_item posts the valid ones and reports the rest"`
+	Transactions []createTransactionRequest `json:"transactions" openapi:"minItems=1,maxItems=10000"`
+}
+
+type batchTransactionResult struct {
+	Index       int                  `json:"index" doc:"Position in the request's transactions"`
+	Status      string               `json:"status" openapi:"enum=created|replayed|failed"`
+	Transaction *transactionResponse `json:"transaction,omitempty"`
+	Errors      []fieldError         `json:"errors,omitempty" doc:"Why the transaction failed; fields are relative to the transaction"`
+}
+
+type createTransactionsBatchResponse struct {
+	Created  int                      `json:"created"`
+	Replayed int                      `json:"replayed"`
+	Failed   int                      `json:"failed"`
+	Results  []batchTransactionResult `json:"results"`
+}
+
+// batchItem tracks one transaction of a batch through validation and posting.
+type batchItem struct {
+	req        createTransactionRequest
+	postedAt   pgtype.Timestamptz
+	postedOn   pgtype.Date
+	accountIDs []pgtype.UUID
+	currency   string
+	contactID  pgtype.UUID
+	hash       []byte
+	errs       validationErrors
+	// existing is set when the idempotency key already names a transaction.
+	existing *db.Transaction
+}
+
+func (it *batchItem) failed() bool {
+	return !it.errs.empty()
+}
+
+// POST /transactions/batch
+//
+// Posts many transactions with a fixed number of queries: one account lookup,
+// one tracking option lookup, one contact and one alias lookup, one
+// idempotency lookup and one insert each for
+// transactions, ledger entries, entry dimensions and audit events, however
+// large the batch.
+func (s *Server) createTransactionsBatch(w http.ResponseWriter, r *http.Request) {
+	var req createTransactionsBatchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, CodeInvalidJSON, "invalid json")
+		return
+	}
+
+	var errs validationErrors
+	req.Mode = strings.TrimSpace(strings.ToLower(req.Mode))
+	if req.Mode != batchAllOrNothing && req.Mode != batchPerItem {
+		errs.add("mode", CodeInvalidValue, "invalid mode (must be all_or_nothing or per_item)")
+	}
+	if len(req.Transactions) == 0 {
+		errs.add("transactions", CodeTooFew, "at least one transaction is required")
+	}
+	if len(req.Transactions) > maxBatchTransactions {
+		errs.add("transactions", CodeTooMany, fmt.Sprintf("too many transactions (max %d)", maxBatchTransactions))
+	}
+	if !errs.empty() {
+		writeValidationErrors(w, errs)
+		return
+	}
+
+	org := tenantFrom(r.Context())
+	items := make([]batchItem, len(req.Transactions))
+	keys := make(map[string]int, len(items))
+	var accountIDs, optionIDs, contactIDs []pgtype.UUID
+	matchAliases := false
+	for i := range items {
+		it := &items[i]
+		it.req = req.Transactions[i]
+		it.postedAt, it.postedOn, it.accountIDs = parseTransactionRequest(&it.req, &it.errs)
+		if first, dup := keys[it.req.IdempotencyKey]; dup && it.req.IdempotencyKey != "" {
+			it.errs.add("idempotency_key", CodeInvalidValue, fmt.Sprintf("idempotency_key also used by transactions[%d]", first))
+		} else {
+			keys[it.req.IdempotencyKey] = i
+		}
+		if it.failed() {
+			continue
+		}
+		if !it.postedOn.Valid {
+			it.postedOn = pgtype.Date{Time: org.calendar.Date(it.postedAt.Time), Valid: true}
+		}
+		it.hash = it.req.requestHash()
+		accountIDs = append(accountIDs, it.accountIDs...)
+		optionIDs = append(optionIDs, trackingOptionIDs(it.req.Entries)...)
+		if it.req.ContactID != "" {
+			it.contactID, _ = parseUUID(it.req.ContactID)
+			contactIDs = append(contactIDs, it.contactID)
+		} else if it.req.Description != "" {
+			matchAliases = true
+		}
+	}
+
+	accounts, err := org.q.GetAccountsByIDs(r.Context(), db.GetAccountsByIDsParams{
+		OrganisationID: org.id,
+		Ids:            accountIDs,
+	})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to fetch accounts")
+		return
+	}
+	byID := accountsByID(accounts)
+
+	var options []db.TrackingOption
+	if len(optionIDs) > 0 {
+		options, err = org.q.GetTrackingOptionsByIDs(r.Context(), db.GetTrackingOptionsByIDsParams{OrganisationID: org.id, Ids: optionIDs})
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, CodeInternal, "failed to fetch tracking options")
+			return
+		}
+	}
+	optionsByID := trackingOptionsByID(options)
+
+	knownContacts := make(map[pgtype.UUID]bool, len(contactIDs))
+	if len(contactIDs) > 0 {
+		cs, err := org.q.GetContactsByIDs(r.Context(), db.GetContactsByIDsParams{OrganisationID: org.id, Ids: contactIDs})
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, CodeInternal, "failed to fetch contacts")
+			return
+		}
+		for _, c := range cs {
+			knownContacts[c.ID] = true
+		}
+	}
+	var aliases []contacts.Alias
+	if matchAliases {
+		rows, err := org.q.ListContactAliases(r.Context(), db.ListContactAliasesParams{OrganisationID: org.id})
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, CodeInternal, "failed to list contact aliases")
+			return
+		}
+		aliases = toAliases(rows)
+	}
+
+	var pending []string
+	for i := range items {
+		it := &items[i]
+		if it.failed() {
+			continue
+		}
+		var archived *fieldError
+		it.currency, archived = checkEntries(it.req.Entries, byID, &it.errs)
+		if archived != nil {
+			it.errs = append(it.errs, *archived)
+		}
+		checkTracking(it.req.Entries, optionsByID, &it.errs)
+		if it.contactID.Valid {
+			if !knownContacts[it.contactID] {
+				it.errs.add("contact_id", CodeContactNotFound, "contact not found")
+			}
+		} else {
+			it.contactID = matchContactAlias(aliases, it.req.Description)
+		}
+		if !it.failed() {
+			pending = append(pending, it.req.IdempotencyKey)
+		}
+	}
+
+	// Idempotency as for single posts: released keys may be reused, bound
+	// keys replay when the payload matches.
+	if _, err := org.q.ReleaseExpiredIdempotencyKeysIn(r.Context(), db.ReleaseExpiredIdempotencyKeysInParams{
+		OrganisationID: org.id,
+		Keys:           pending,
+		Cutoff:         s.idempotencyCutoff(),
+	}); err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to check idempotency keys")
+		return
+	}
+	existing, err := org.q.ListTransactionsByIdempotencyKeys(r.Context(), db.ListTransactionsByIdempotencyKeysParams{
+		OrganisationID: org.id,
+		Keys:           pending,
+	})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to check idempotency keys")
+		return
+	}
+	for _, t := range existing {
+		it := &items[keys[t.IdempotencyKey.String]]
+		// Rows written before hashes were stored cannot be checked; trust them.
+		if t.RequestHash != nil && !bytes.Equal(t.RequestHash, it.hash) {
+			it.errs.add("idempotency_key", CodeIdempotencyKeyReused, "idempotency key reused with different payload")
+			continue
+		}
+		it.existing = &t
+	}
+
+	if req.Mode == batchAllOrNothing && writeBatchErrors(w, items) {
+		return
+	}
+
+	tx, qtx, err := org.begin(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to begin transaction")
+		return
+	}
+	defer tx.Rollback(r.Context())
+
+	// Filed figures must not move: same check as checkPeriodOpen, against
+	// every lock at once.
+	if err := qtx.LockPeriodsShared(r.Context(), org.id); err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to check period locks")
+		return
+	}
+	locks, err := qtx.ListPeriodLocks(r.Context(), org.id)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to check period locks")
+		return
+	}
+
+	var create []*batchItem
+	for i := range items {
+		it := &items[i]
+		if it.failed() || it.existing != nil {
+			continue
+		}
+		if lock := periodLockOn(locks, it.postedOn); lock != nil {
+			it.errs.add("posted_on", CodePeriodLocked, periodLockedMessage(lock))
+			continue
+		}
+		create = append(create, it)
+	}
+
+	if req.Mode == batchAllOrNothing && writeBatchErrors(w, items) {
+		return
+	}
+
+	created, err := createBatchTransactions(r, qtx, org, create)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" { // unique_violation
+			// A concurrent request bound one of the keys first; retrying
+			// replays it.
+			writeError(w, http.StatusConflict, CodeIdempotencyKeyInFlight, "another request is posting one of these idempotency keys; retry the batch")
+			return
+		}
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to create transactions")
+		return
+	}
+
+	if err := tx.Commit(r.Context()); err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to commit transaction")
+		return
+	}
+
+	replayed, err := replayBatchTransactions(r, org, items)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to fetch ledger entries")
+		return
+	}
+
+	resp := createTransactionsBatchResponse{Results: make([]batchTransactionResult, 0, len(items))}
+	for i := range items {
+		it := &items[i]
+		res := batchTransactionResult{Index: i}
+		switch {
+		case it.failed():
+			res.Status = batchFailed
+			res.Errors = it.errs
+			resp.Failed++
+		case it.existing != nil:
+			t := replayed[it.req.IdempotencyKey]
+			res.Status = batchReplayed
+			res.Transaction = &t
+			resp.Replayed++
+		default:
+			t := created[it.req.IdempotencyKey]
+			res.Status = batchCreated
+			res.Transaction = &t
+			resp.Created++
+		}
+		resp.Results = append(resp.Results, res)
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// createBatchTransactions inserts the transactions, their entries and their
+// audit events, and returns the transactions keyed by idempotency key.
+func createBatchTransactions(r *http.Request, q *db.Queries, org *tenant, items []*batchItem) (map[string]transactionResponse, error) {
+	out := make(map[string]transactionResponse, len(items))
+	if len(items) == 0 {
+		return out, nil
+	}
+
+	txArg := db.CreateTransactionsParams{OrganisationID: org.id}
+	for _, it := range items {
+		txArg.IdempotencyKeys = append(txArg.IdempotencyKeys, it.req.IdempotencyKey)
+		txArg.Descriptions = append(txArg.Descriptions, it.req.Description)
+		txArg.Sources = append(txArg.Sources, it.req.Source)
+		txArg.PostedAts = append(txArg.PostedAts, it.postedAt)
+		txArg.RequestHashes = append(txArg.RequestHashes, it.hash)
+		txArg.PostedOns = append(txArg.PostedOns, it.postedOn)
+		txArg.ContactIds = append(txArg.ContactIds, it.contactID)
+	}
+	txs, err := q.CreateTransactions(r.Context(), txArg)
+	if err != nil {
+		return nil, err
+	}
+
+	// rows come back in no promised order; keys tie them to the request
+	byKey := make(map[string]db.Transaction, len(txs))
+	for _, t := range txs {
+		byKey[t.IdempotencyKey.String] = t
+	}
+
+	// entry IDs are chosen here so tags and tracking can be inserted in bulk
+	// without matching entries back to the request
+	entryArg := db.CreateLedgerEntriesParams{OrganisationID: org.id}
+	var dimEntries []ledgerEntryRequest
+	for _, it := range items {
+		t := byKey[it.req.IdempotencyKey]
+		for i, entry := range it.req.Entries {
+			entryArg.Ids = append(entryArg.Ids, pgtype.UUID{Bytes: uuid.New(), Valid: true})
+			entryArg.TransactionIds = append(entryArg.TransactionIds, t.ID)
+			entryArg.AccountIds = append(entryArg.AccountIds, it.accountIDs[i])
+			entryArg.AmountsMinor = append(entryArg.AmountsMinor, entry.Amount)
+			entryArg.Currencies = append(entryArg.Currencies, it.currency)
+		}
+		dimEntries = append(dimEntries, it.req.Entries...)
+	}
+	entries, err := q.CreateLedgerEntries(r.Context(), entryArg)
+	if err != nil {
+		return nil, err
+	}
+	if err := saveEntryDimensions(r.Context(), q, org, dimEntries, entryArg.Ids); err != nil {
+		return nil, err
+	}
+
+	dims := requestDimensions(dimEntries, entryArg.Ids)
+	grouped := groupEntries(entries)
+	changes := make([]auditChange, 0, len(items))
+	for _, it := range items {
+		t := byKey[it.req.IdempotencyKey]
+		resp := toFullTransactionResponse(t, grouped[t.ID])
+		dims.attach(&resp)
+		out[it.req.IdempotencyKey] = resp
+		changes = append(changes, auditChange{entityID: t.ID, after: resp})
+	}
+
+	if err := recordAudits(r.Context(), q, org, audit.EntityTransaction, audit.ActionCreate, changes); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// replayBatchTransactions loads the entries of every replayed transaction,
+// with their tags and tracking, in one query each and returns the transactions keyed by idempotency key.
+func replayBatchTransactions(r *http.Request, org *tenant, items []batchItem) (map[string]transactionResponse, error) {
+	out := make(map[string]transactionResponse)
+	var ids []pgtype.UUID
+	for i := range items {
+		if it := &items[i]; !it.failed() && it.existing != nil {
+			ids = append(ids, it.existing.ID)
+		}
+	}
+	if len(ids) == 0 {
+		return out, nil
+	}
+
+	entries, err := org.q.ListLedgerEntriesForTransactions(r.Context(), db.ListLedgerEntriesForTransactionsParams{
+		OrganisationID: org.id,
+		TransactionIds: ids,
+	})
+	if err != nil {
+		return nil, err
+	}
+	dims, err := loadEntryDimensions(r.Context(), org.q, org, entries)
+	if err != nil {
+		return nil, err
+	}
+
+	grouped := groupEntries(entries)
+	for i := range items {
+		if it := &items[i]; !it.failed() && it.existing != nil {
+			resp := toFullTransactionResponse(*it.existing, grouped[it.existing.ID])
+			dims.attach(&resp)
+			out[it.req.IdempotencyKey] = resp
+		}
+	}
+	return out, nil
+}
+
+// writeBatchErrors answers an all-or-nothing batch with every failure, field
+// names prefixed by the transaction's index, and reports whether it did.
+func writeBatchErrors(w http.ResponseWriter, items []batchItem) bool {
+	var errs validationErrors
+	for i := range items {
+		for _, e := range items[i].errs {
+			e.Field = fmt.Sprintf("transactions[%d].%s", i, e.Field)
+			errs = append(errs, e)
+		}
+	}
+	if errs.empty() {
+		return false
+	}
+	writeValidationErrors(w, errs)
+	return true
+}
+
+func groupEntries(entries []db.LedgerEntry) map[pgtype.UUID][]db.LedgerEntry {
+	out := make(map[pgtype.UUID][]db.LedgerEntry)
+	for _, e := range entries {
+		out[e.TransactionID] = append(out[e.TransactionID], e)
+	}
+	return out
+}
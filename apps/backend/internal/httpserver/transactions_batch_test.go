@@ -0,0 +1,57 @@
+package httpserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+
+	db "github.com/LBaronceli/go-figure/internal/db/sqlc"
+)
+
+func TestCheckEntriesReportsEveryProblem(t *testing.T) {
+	cash := db.Account{ID: pgtype.UUID{Bytes: uuid.New(), Valid: true}, Currency: "NZD"}
+	usd := db.Account{ID: pgtype.UUID{Bytes: uuid.New(), Valid: true}, Currency: "USD"}
+	old := db.Account{ID: pgtype.UUID{Bytes: uuid.New(), Valid: true}, Currency: "NZD", ArchivedAt: pgtype.Timestamptz{Valid: true}}
+	accounts := accountsByID([]db.Account{cash, usd, old})
+
+	var errs validationErrors
+	_, archived := checkEntries([]ledgerEntryRequest{
+		{AccountID: uuid.UUID(cash.ID.Bytes).String(), Amount: 100},
+		{AccountID: uuid.UUID(usd.ID.Bytes).String(), Amount: -50},
+		{AccountID: uuid.New().String(), Amount: -50},
+		{AccountID: uuid.UUID(old.ID.Bytes).String(), Amount: 0},
+	}, accounts, &errs)
+
+	require.NotNil(t, archived)
+	require.Equal(t, "entries[3].account_id", archived.Field)
+	require.Equal(t, []ErrorCode{CodeCurrencyMismatch, CodeAccountNotFound, CodeTransactionNotBalanced},
+		[]ErrorCode{errs[0].Code, errs[1].Code, errs[2].Code})
+}
+
+func TestWriteBatchErrorsPrefixesFields(t *testing.T) {
+	items := make([]batchItem, 3)
+	items[2].errs.add("entries[1].amount", CodeAmountOverflow, "transaction amount overflow")
+	items[2].errs.add("posted_on", CodePeriodLocked, "period from 2026-01-01 to 2026-03-31 is locked")
+
+	require.False(t, writeBatchErrors(httptest.NewRecorder(), items[:2]))
+
+	rec := httptest.NewRecorder()
+	require.True(t, writeBatchErrors(rec, items))
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+
+	var body struct {
+		Error struct {
+			Details struct {
+				Fields []fieldError `json:"fields"`
+			} `json:"details"`
+		} `json:"error"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	require.Equal(t, "transactions[2].entries[1].amount", body.Error.Details.Fields[0].Field)
+	require.Equal(t, "transactions[2].posted_on", body.Error.Details.Fields[1].Field)
+}
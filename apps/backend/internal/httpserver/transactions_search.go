@@ -0,0 +1,138 @@
+package httpserver
+
+import (
+	"html"
+	"net/http"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	db "github.com/LBaronceli/go-figure/internal/db/sqlc"
+	"github.com/LBaronceli/go-figure/internal/search"
+)
+
+const maxSearchLength = 500
+
+// headline markers set by SearchTransactions around matched words
+const (
+	headlineStart = "\x02"
+	headlineStop  = "\x03"
+)
+
+var searchFilters = append([]apiParam{
+	{name: "q", typ: "string", desc: "Words to find in descriptions, in web search syntax (\"exact phrase\", or, -exclude), plus amount:>100, amount:-45.50 (major units, debits positive; every amount term must hold for one entry) and tag:travel terms"},
+}, transactionScope...)
+
+type transactionSearchResult struct {
+	Transaction transactionResponse `json:"transaction"`
+	Rank        float64             `json:"rank" doc:"Higher is a better match"`
+	Highlight   string              `json:"highlight" doc:"HTML-escaped description with matched words wrapped in <mark>"`
+}
+
+// GET /transactions/search
+//
+// Ranked search over descriptions, mixing full-text matching with trigram
+// similarity so near misses still turn up, narrowed by the listing filters
+// and by amount and tag terms in the query itself.
+func (s *Server) searchTransactions(w http.ResponseWriter, r *http.Request) {
+	var errs validationErrors
+	raw := strings.TrimSpace(r.URL.Query().Get("q"))
+	var q search.Query
+	switch {
+	case raw == "":
+		errs.add("q", CodeRequired, "missing q")
+	case len(raw) > maxSearchLength:
+		errs.add("q", CodeTooLong, "q too long")
+	default:
+		var err error
+		if q, err = search.Parse(raw); err != nil {
+			errs.add("q", CodeInvalidValue, err.Error())
+		}
+	}
+
+	var accountID pgtype.UUID
+	if v := r.URL.Query().Get("account_id"); v != "" {
+		id, err := parseUUID(v)
+		if err != nil {
+			errs.add("account_id", CodeInvalidFormat, "invalid account_id")
+		}
+		accountID = id
+	}
+
+	if v := r.URL.Query().Get("tag"); v != "" {
+		q.Tags = append(q.Tags, strings.ToLower(strings.TrimSpace(v)))
+	}
+
+	var trackingOptionID pgtype.UUID
+	if v := r.URL.Query().Get("tracking_option_id"); v != "" {
+		id, err := parseUUID(v)
+		if err != nil {
+			errs.add("tracking_option_id", CodeInvalidFormat, "invalid tracking_option_id")
+		}
+		trackingOptionID = id
+	}
+
+	org := tenantFrom(r.Context())
+	startDate, endDate := parseDateRange(r, org, &errs)
+
+	if !errs.empty() {
+		writeValidationErrors(w, errs)
+		return
+	}
+
+	params := db.SearchTransactionsParams{
+		Limit:            50,
+		Text:             q.Text,
+		OrganisationID:   org.id,
+		AccountID:        accountID,
+		StartDate:        startDate,
+		EndDate:          endDate,
+		TrackingOptionID: trackingOptionID,
+		Tags:             q.Tags,
+		AmountOps:        []string{},
+		AmountsMinor:     []int64{},
+	}
+	if params.Tags == nil {
+		params.Tags = []string{}
+	}
+	for _, a := range q.Amounts {
+		params.AmountOps = append(params.AmountOps, string(a.Op))
+		params.AmountsMinor = append(params.AmountsMinor, a.Minor)
+	}
+
+	rows, err := org.q.SearchTransactions(r.Context(), params)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to search transactions")
+		return
+	}
+
+	resp := make([]transactionSearchResult, 0, len(rows))
+	for _, row := range rows {
+		resp = append(resp, transactionSearchResult{
+			Transaction: toTransactionResponse(db.Transaction{
+				ID:              row.ID,
+				IdempotencyKey:  row.IdempotencyKey,
+				Description:     row.Description,
+				Source:          row.Source,
+				PostedAt:        row.PostedAt,
+				CreatedAt:       row.CreatedAt,
+				RequestHash:     row.RequestHash,
+				OrganisationID:  row.OrganisationID,
+				PostedOn:        row.PostedOn,
+				CorrectsEntryID: row.CorrectsEntryID,
+				ContactID:       row.ContactID,
+				BillID:          row.BillID,
+			}),
+			Rank:      row.Rank,
+			Highlight: highlightHeadline(row.Headline),
+		})
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// highlightHeadline escapes a SearchTransactions headline for HTML and turns
+// its markers into <mark> tags.
+func highlightHeadline(headline string) string {
+	return strings.NewReplacer(headlineStart, "<mark>", headlineStop, "</mark>").Replace(html.EscapeString(headline))
+}
@@ -0,0 +1,12 @@
+package httpserver
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHighlightHeadlineEscapesDescription(t *testing.T) {
+	got := highlightHeadline("<b>" + headlineStart + "Bunnings" + headlineStop + " & co")
+	require.Equal(t, "&lt;b&gt;<mark>Bunnings</mark> &amp; co", got)
+}
@@ -0,0 +1,316 @@
+package httpserver
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	db "github.com/LBaronceli/go-figure/internal/db/sqlc"
+	"github.com/LBaronceli/go-figure/internal/models"
+	"github.com/LBaronceli/go-figure/internal/money"
+)
+
+// correctsEntryUnique is the index that lets each ledger entry be corrected
+// only once.
+const correctsEntryUnique = "transactions_corrects_entry_id_unique"
+
+// maxSplitParts leaves room for the reversing entry within maxLedgerEntries.
+const maxSplitParts = maxLedgerEntries - 1
+
+type splitPartRequest struct {
+	AccountID string                 `json:"account_id" openapi:"format=uuid"`
+	Amount    *int64                 `json:"amount,omitempty" doc:"Minor units, same sign as the split entry; give amount or percent"`
+	Percent   *float64               `json:"percent,omitempty" doc:"Share of the split entry with up to 2 decimal places; percents must total 100"`
+	Tags      []string               `json:"tags,omitempty" openapi:"maxItems=20"`
+	Tracking  []entryTrackingRequest `json:"tracking,omitempty" openapi:"maxItems=10"`
+}
+
+type splitTransactionRequest struct {
+	IdempotencyKey string             `json:"idempotency_key" openapi:"minLength=1,maxLength=500"`
+	LedgerEntryID  string             `json:"ledger_entry_id" openapi:"optional,format=uuid" doc:"Entry to reallocate; defaults to the transaction's only expense entry"`
+	Description    string             `json:"description" openapi:"optional,maxLength=500" doc:"Defaults to the original description prefixed with \"Split: \""`
+	PostedOn       string             `json:"posted_on" openapi:"optional,format=date" doc:"Defaults to the original transaction's accounting date"`
+	Parts          []splitPartRequest `json:"parts" openapi:"minItems=1,maxItems=99"`
+}
+
+// POST /transactions/{id}/split
+//
+// Reallocates one entry of a posted transaction across other accounts. The
+// original stays untouched: a correcting transaction reverses the entry and
+// posts the parts, so the books show both what was imported and how it was
+// reclassified.
+func (s *Server) splitTransaction(w http.ResponseWriter, r *http.Request) {
+	id, err := parseUUID(chi.URLParam(r, "id"))
+	if err != nil {
+		writeFieldError(w, http.StatusBadRequest, CodeInvalidFormat, "id", "invalid id")
+		return
+	}
+
+	var req splitTransactionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, CodeInvalidJSON, "invalid json")
+		return
+	}
+
+	var errs validationErrors
+	var entryID pgtype.UUID
+	if req.LedgerEntryID != "" {
+		if entryID, err = parseUUID(req.LedgerEntryID); err != nil {
+			errs.add("ledger_entry_id", CodeInvalidFormat, "invalid ledger_entry_id")
+		}
+	}
+	partAccounts := parseSplitParts(req.Parts, &errs)
+	if !errs.empty() {
+		writeValidationErrors(w, errs)
+		return
+	}
+
+	org := tenantFrom(r.Context())
+	original, err := org.q.GetTransaction(r.Context(), db.GetTransactionParams{OrganisationID: org.id, ID: id})
+	if errors.Is(err, pgx.ErrNoRows) {
+		writeError(w, http.StatusNotFound, CodeNotFound, "transaction not found")
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to get transaction")
+		return
+	}
+
+	entry, ok := s.splitEntry(w, r, org, original, entryID)
+	if !ok {
+		return
+	}
+
+	shares, err := splitShares(req.Parts, entry.AmountMinor)
+	if errors.Is(err, errSplitOverflow) {
+		writeFieldError(w, http.StatusBadRequest, CodeAmountOverflow, "parts", err.Error())
+		return
+	}
+	if err != nil {
+		writeFieldError(w, http.StatusBadRequest, CodeInvalidValue, "parts", err.Error())
+		return
+	}
+
+	dims, err := loadEntryDimensions(r.Context(), org.q, org, []db.LedgerEntry{entry})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to fetch entry tags and tracking")
+		return
+	}
+
+	// Reverse the entry, tags and tracking included, and post the parts.
+	// Parts with the same account and dimensions are netted, so a part that
+	// stays where the entry was cancels against the reversal.
+	entryKey := uuid.UUID(entry.ID.Bytes).String()
+	reversal := ledgerEntryRequest{
+		AccountID: uuid.UUID(entry.AccountID.Bytes).String(),
+		Amount:    -entry.AmountMinor,
+		Tags:      dims.tags[entryKey],
+		reversal:  true,
+	}
+	for _, t := range dims.tracking[entryKey] {
+		reversal.Tracking = append(reversal.Tracking, entryTrackingRequest(t))
+	}
+	netted := []ledgerEntryRequest{reversal}
+	index := map[string]int{dimensionKey(reversal): 0}
+	for i, p := range req.Parts {
+		part := ledgerEntryRequest{AccountID: partAccounts[i], Amount: shares[i], Tags: p.Tags, Tracking: p.Tracking}
+		if j, ok := index[dimensionKey(part)]; ok {
+			netted[j].Amount += part.Amount
+			continue
+		}
+		index[dimensionKey(part)] = len(netted)
+		netted = append(netted, part)
+	}
+	entries := make([]ledgerEntryRequest, 0, len(netted))
+	for _, e := range netted {
+		if e.Amount != 0 {
+			entries = append(entries, e)
+		}
+	}
+	if len(entries) == 0 {
+		writeFieldError(w, http.StatusBadRequest, CodeInvalidValue, "parts", "split leaves the entry where it is")
+		return
+	}
+
+	description := req.Description
+	if strings.TrimSpace(description) == "" {
+		description = strings.TrimSpace("Split: " + original.Description.String)
+	}
+	postedOn := req.PostedOn
+	if postedOn == "" {
+		postedOn = original.PostedOn.Time.Format(time.DateOnly)
+	}
+
+	s.postTransaction(w, r, createTransactionRequest{
+		IdempotencyKey:  req.IdempotencyKey,
+		Description:     description,
+		Source:          "manual",
+		PostedOn:        postedOn,
+		ContactID:       uuidString(original.ContactID),
+		Entries:         entries,
+		correctsEntryID: entry.ID,
+	})
+}
+
+// splitEntry picks the entry of t to split: the one named by entryID, or
+// else t's only entry on an expense account.
+func (s *Server) splitEntry(w http.ResponseWriter, r *http.Request, org *tenant, t db.Transaction, entryID pgtype.UUID) (db.LedgerEntry, bool) {
+	if entryID.Valid {
+		entry, err := org.q.GetLedgerEntry(r.Context(), db.GetLedgerEntryParams{OrganisationID: org.id, TransactionID: t.ID, ID: entryID})
+		if errors.Is(err, pgx.ErrNoRows) {
+			writeFieldError(w, http.StatusBadRequest, CodeNotFound, "ledger_entry_id", "no entry with this id on the transaction")
+			return db.LedgerEntry{}, false
+		}
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, CodeInternal, "failed to get ledger entry")
+			return db.LedgerEntry{}, false
+		}
+		return entry, true
+	}
+
+	entries, err := org.q.ListLedgerEntries(r.Context(), db.ListLedgerEntriesParams{OrganisationID: org.id, TransactionID: t.ID})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to fetch ledger entries")
+		return db.LedgerEntry{}, false
+	}
+	ids := make([]pgtype.UUID, 0, len(entries))
+	for _, e := range entries {
+		ids = append(ids, e.AccountID)
+	}
+	accounts, err := org.q.GetAccountsByIDs(r.Context(), db.GetAccountsByIDsParams{OrganisationID: org.id, Ids: ids})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to fetch accounts")
+		return db.LedgerEntry{}, false
+	}
+	byID := accountsByID(accounts)
+
+	var expense []db.LedgerEntry
+	for _, e := range entries {
+		if models.AccountType(byID[uuid.UUID(e.AccountID.Bytes).String()].Type) == models.AccountTypeExpense {
+			expense = append(expense, e)
+		}
+	}
+	if len(expense) != 1 {
+		writeFieldError(w, http.StatusBadRequest, CodeRequired, "ledger_entry_id",
+			fmt.Sprintf("transaction has %d expense entries; name the one to split", len(expense)))
+		return db.LedgerEntry{}, false
+	}
+	return expense[0], true
+}
+
+// parseSplitParts checks each part's shape, normalises its tags and tracking
+// and returns the parts' accounts as canonical UUID strings.
+func parseSplitParts(parts []splitPartRequest, errs *validationErrors) []string {
+	if len(parts) == 0 {
+		errs.add("parts", CodeTooFew, "at least one part is required")
+	}
+	if len(parts) > maxSplitParts {
+		errs.add("parts", CodeTooMany, fmt.Sprintf("too many parts (max %d)", maxSplitParts))
+	}
+
+	accounts := make([]string, len(parts))
+	byPercent := 0
+	for i, p := range parts {
+		id, err := uuid.Parse(p.AccountID)
+		if err != nil {
+			errs.add(partField(i, "account_id"), CodeInvalidFormat, "invalid account_id uuid")
+		}
+		accounts[i] = id.String()
+
+		switch {
+		case (p.Amount == nil) == (p.Percent == nil):
+			errs.add(partField(i, "amount"), CodeInvalidValue, "give exactly one of amount or percent")
+		case p.Percent != nil:
+			byPercent++
+			if *p.Percent <= 0 || *p.Percent > 100 {
+				errs.add(partField(i, "percent"), CodeOutOfRange, "percent must be greater than 0 and at most 100")
+			} else if bp := *p.Percent * 100; math.Abs(bp-math.Round(bp)) > 1e-6 {
+				errs.add(partField(i, "percent"), CodeInvalidValue, "percent has more than 2 decimal places")
+			}
+		case *p.Amount == 0:
+			errs.add(partField(i, "amount"), CodeInvalidValue, "amount must not be zero")
+		}
+	}
+	if byPercent != 0 && byPercent != len(parts) {
+		errs.add("parts", CodeInvalidValue, "split either every part by amount or every part by percent")
+	}
+
+	dims := make([]ledgerEntryRequest, len(parts))
+	for i, p := range parts {
+		dims[i] = ledgerEntryRequest{Tags: p.Tags, Tracking: p.Tracking}
+	}
+	parseEntryDimensions(dims, partField, errs)
+	for i := range parts {
+		parts[i].Tags, parts[i].Tracking = dims[i].Tags, dims[i].Tracking
+	}
+	return accounts
+}
+
+// dimensionKey identifies an entry's account, tags and tracking, for entries
+// normalised by parseEntryDimensions.
+func dimensionKey(e ledgerEntryRequest) string {
+	tracking := make([]string, 0, len(e.Tracking))
+	for _, t := range e.Tracking {
+		tracking = append(tracking, t.CategoryID+"="+t.OptionID)
+	}
+	slices.Sort(tracking)
+	tags := slices.Sorted(slices.Values(e.Tags))
+	return e.AccountID + "|" + strings.Join(tags, ",") + "|" + strings.Join(tracking, ",")
+}
+
+// errSplitOverflow means the part amounts overflow int64, which could
+// otherwise wrap around to the entry's amount.
+var errSplitOverflow = errors.New("amounts overflow")
+
+// splitShares turns validated parts into amounts that sum exactly to total.
+// Percentages are allocated with money.Allocate, so rounding is the same for
+// the same split every time.
+func splitShares(parts []splitPartRequest, total int64) ([]int64, error) {
+	if parts[0].Percent != nil {
+		weights := make([]int64, len(parts))
+		var sum int64
+		for i, p := range parts {
+			weights[i] = int64(math.Round(*p.Percent * 100)) // basis points
+			sum += weights[i]
+		}
+		if sum != 10000 {
+			return nil, fmt.Errorf("percents total %s, not 100", formatBasisPoints(sum))
+		}
+		return money.Allocate(total, weights)
+	}
+
+	shares := make([]int64, len(parts))
+	var sum int64
+	for i, p := range parts {
+		if (*p.Amount < 0) != (total < 0) {
+			return nil, fmt.Errorf("parts[%d].amount has the opposite sign to the entry", i)
+		}
+		if (*p.Amount > 0 && sum > math.MaxInt64-*p.Amount) || (*p.Amount < 0 && sum < math.MinInt64-*p.Amount) {
+			return nil, errSplitOverflow
+		}
+		shares[i] = *p.Amount
+		sum += *p.Amount
+	}
+	if sum != total {
+		return nil, fmt.Errorf("amounts total %d, not the entry's %d", sum, total)
+	}
+	return shares, nil
+}
+
+func formatBasisPoints(bp int64) string {
+	return fmt.Sprintf("%d.%02d", bp/100, bp%100)
+}
+
+func partField(i int, name string) string {
+	return fmt.Sprintf("parts[%d].%s", i, name)
+}
@@ -0,0 +1,55 @@
+package httpserver
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitSharesByPercentSumToEntry(t *testing.T) {
+	pct := func(v float64) splitPartRequest { return splitPartRequest{Percent: &v} }
+
+	shares, err := splitShares([]splitPartRequest{pct(33.33), pct(33.33), pct(33.34)}, 8451)
+	require.NoError(t, err)
+	require.Equal(t, []int64{2817, 2817, 2817}, shares)
+
+	shares, err = splitShares([]splitPartRequest{pct(50), pct(50)}, -8451)
+	require.NoError(t, err)
+	require.Equal(t, []int64{-4226, -4225}, shares)
+
+	_, err = splitShares([]splitPartRequest{pct(60), pct(30)}, 100)
+	require.EqualError(t, err, "percents total 90.00, not 100")
+}
+
+func TestSplitSharesByAmount(t *testing.T) {
+	amt := func(v int64) splitPartRequest { return splitPartRequest{Amount: &v} }
+
+	shares, err := splitShares([]splitPartRequest{amt(5000), amt(3450)}, 8450)
+	require.NoError(t, err)
+	require.Equal(t, []int64{5000, 3450}, shares)
+
+	_, err = splitShares([]splitPartRequest{amt(5000), amt(3000)}, 8450)
+	require.Error(t, err)
+	_, err = splitShares([]splitPartRequest{amt(9000), amt(-550)}, 8450)
+	require.Error(t, err)
+	// wrapping past the int64 range must not land back on the entry's amount
+	_, err = splitShares([]splitPartRequest{amt(math.MaxInt64), amt(math.MaxInt64), amt(3)}, 1)
+	require.ErrorIs(t, err, errSplitOverflow)
+}
+
+func TestParseSplitPartsRejectsMixedModes(t *testing.T) {
+	amount, percent := int64(100), 12.345
+	var errs validationErrors
+	parseSplitParts([]splitPartRequest{
+		{AccountID: "not-a-uuid", Amount: &amount},
+		{AccountID: "0b8a1c1e-8a8f-4b8e-9a57-3f1f5a0d2c11", Percent: &percent},
+		{AccountID: "0b8a1c1e-8a8f-4b8e-9a57-3f1f5a0d2c11"},
+	}, &errs)
+
+	fields := make([]string, 0, len(errs))
+	for _, e := range errs {
+		fields = append(fields, e.Field)
+	}
+	require.Equal(t, []string{"parts[0].account_id", "parts[1].percent", "parts[2].amount", "parts"}, fields)
+}
@@ -0,0 +1,11 @@
+package httpserver
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEscapeLikeMatchesLiterally(t *testing.T) {
+	require.Equal(t, `50\% off\_sale \\ more`, escapeLike(`50% off_sale \ more`))
+}
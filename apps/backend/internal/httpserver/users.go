@@ -0,0 +1,110 @@
+package httpserver
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/LBaronceli/go-figure/internal/auth"
+	db "github.com/LBaronceli/go-figure/internal/db/sqlc"
+)
+
+const minPasswordLength = 12
+
+type createUserRequest struct {
+	Email    string `json:"email" openapi:"minLength=3,maxLength=320"`
+	Password string `json:"password" openapi:"minLength=12,maxLength=1024"`
+	IsAdmin  bool   `json:"is_admin,omitempty"`
+}
+
+type userResponse struct {
+	ID        string `json:"id" openapi:"format=uuid"`
+	Email     string `json:"email"`
+	IsAdmin   bool   `json:"is_admin"`
+	CreatedAt string `json:"created_at" openapi:"format=date-time"`
+}
+
+// POST /users
+func (s *Server) createUser(w http.ResponseWriter, r *http.Request) {
+	var req createUserRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, CodeInvalidJSON, "invalid json")
+		return
+	}
+
+	email := normalizeEmail(req.Email)
+	var errs validationErrors
+	if email == "" {
+		errs.add("email", CodeRequired, "email is required")
+	} else if !strings.Contains(email, "@") {
+		errs.add("email", CodeInvalidFormat, "email must be an email address")
+	} else if len(email) > 320 {
+		errs.add("email", CodeTooLong, "email too long")
+	}
+	if len(req.Password) < minPasswordLength {
+		errs.add("password", CodeTooShort, "password must be at least 12 characters")
+	} else if len(req.Password) > 1024 {
+		errs.add("password", CodeTooLong, "password too long")
+	}
+	if !errs.empty() {
+		writeValidationErrors(w, errs)
+		return
+	}
+
+	hash, err := auth.HashPassword(req.Password)
+	if errors.Is(err, auth.ErrBusy) {
+		writeHashingBusy(w)
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to hash password")
+		return
+	}
+
+	user, err := s.queries(r.Context()).CreateUser(r.Context(), db.CreateUserParams{
+		Email:        email,
+		PasswordHash: hash,
+		IsAdmin:      req.IsAdmin,
+	})
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			writeFieldError(w, http.StatusConflict, CodeEmailTaken, "email", "a user with this email already exists")
+			return
+		}
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to create user")
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, toUserResponse(user))
+}
+
+// GET /users
+func (s *Server) listUsers(w http.ResponseWriter, r *http.Request) {
+	users, err := s.queries(r.Context()).ListUsers(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "failed to list users")
+		return
+	}
+
+	resp := make([]userResponse, 0, len(users))
+	for _, u := range users {
+		resp = append(resp, toUserResponse(u))
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func toUserResponse(u db.User) userResponse {
+	return userResponse{
+		ID:        uuid.UUID(u.ID.Bytes).String(),
+		Email:     u.Email,
+		IsAdmin:   u.IsAdmin,
+		CreatedAt: u.CreatedAt.Time.Format(time.RFC3339Nano),
+	}
+}
@@ -0,0 +1,644 @@
+// Package invoicepdf renders invoices as PDF documents. It uses fpdf and its
+// built-in fonts, which are pure Go, so rendering needs no browser, fonts or
+// other files in the container. Amounts are in minor units with two decimal
+// places.
+package invoicepdf
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/jpeg" // logo formats
+	_ "image/png"
+	"io"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/go-pdf/fpdf"
+)
+
+// Logo types accepted, as MIME types.
+const (
+	LogoPNG  = "image/png"
+	LogoJPEG = "image/jpeg"
+)
+
+const (
+	// MaxLogoBytes bounds an uploaded logo.
+	MaxLogoBytes = 512 << 10
+	// MaxLogoPixels bounds each side of a logo; it is drawn at most 60mm wide.
+	MaxLogoPixels = 4000
+	// MaxTextOutput bounds what a text template may expand to.
+	MaxTextOutput = 4000
+)
+
+// Template is how an organisation's invoices look apart from the invoice
+// itself.
+type Template struct {
+	TradingName string
+	// Address may span several lines.
+	Address   string
+	TaxNumber string
+	Email     string
+	// AccentColor is "#rrggbb".
+	AccentColor string
+	// PaymentInstructions and Footer are text/template sources executed
+	// against Data.
+	PaymentInstructions string
+	Footer              string
+	Logo                []byte
+	LogoType            string
+}
+
+// Invoice is the invoice being rendered.
+type Invoice struct {
+	Number    string
+	Reference string
+	Currency  string
+	// Status is draft, sent, paid or void; drafts and void invoices are
+	// stamped as such.
+	Status   string
+	IssueOn  time.Time
+	DueOn    time.Time
+	Customer string
+	// CustomerEmail is optional.
+	CustomerEmail string
+	Lines         []Line
+	Subtotal      int64
+	Tax           int64
+	Total         int64
+	Paid          int64
+}
+
+// Line is one invoice line.
+type Line struct {
+	Description         string
+	QuantityThousandths int64
+	UnitPrice           int64
+	// TaxCode names the line's tax code; empty for untaxed lines.
+	TaxCode string
+	// TaxRate is in basis points.
+	TaxRate int64
+	Amount  int64
+	Tax     int64
+}
+
+// TaxRow totals the lines under one tax code.
+type TaxRow struct {
+	TaxCode string
+	TaxRate int64
+	Taxable int64
+	Tax     int64
+}
+
+// Data is what the payment instructions and footer templates see, for
+// example "Please pay {{.AmountDue}} {{.Currency}} by {{.DueOn}}".
+type Data struct {
+	Number      string
+	Reference   string
+	Currency    string
+	IssueOn     string
+	DueOn       string
+	Total       string
+	Paid        string
+	AmountDue   string
+	Customer    string
+	TradingName string
+}
+
+// sample stands in for an invoice when checking a template.
+var sample = Data{
+	Number:      "INV-0001",
+	Reference:   "PO-1",
+	Currency:    "NZD",
+	IssueOn:     "1 Apr 2026",
+	DueOn:       "1 May 2026",
+	Total:       "115.00",
+	Paid:        "0.00",
+	AmountDue:   "115.00",
+	Customer:    "Customer Ltd",
+	TradingName: "Trading Ltd",
+}
+
+// ErrTextTooLong is returned when a text template expands past
+// MaxTextOutput bytes.
+var ErrTextTooLong = fmt.Errorf("template output longer than %d bytes", MaxTextOutput)
+
+// CheckText reports whether src parses and executes as a text template over
+// Data, so mistakes surface when the template is saved rather than when an
+// invoice is rendered.
+func CheckText(src string) error {
+	_, err := executeText(src, sample)
+	return err
+}
+
+// CheckLogo reports whether data is a PNG or JPEG logo of contentType that
+// fits the limits and that fpdf can embed; fpdf rejects some valid images,
+// such as interlaced PNGs.
+func CheckLogo(data []byte, contentType string) error {
+	if len(data) > MaxLogoBytes {
+		return fmt.Errorf("logo larger than %d bytes", MaxLogoBytes)
+	}
+	imageType, err := fpdfImageType(contentType)
+	if err != nil {
+		return err
+	}
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return errors.New("logo is not a PNG or JPEG image")
+	}
+	if "image/"+format != contentType {
+		return fmt.Errorf("logo is %s, not %s", "image/"+format, contentType)
+	}
+	if cfg.Width > MaxLogoPixels || cfg.Height > MaxLogoPixels {
+		return fmt.Errorf("logo larger than %dx%d pixels", MaxLogoPixels, MaxLogoPixels)
+	}
+
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.RegisterImageOptionsReader("logo", fpdf.ImageOptions{ImageType: imageType}, bytes.NewReader(data))
+	if err := pdf.Error(); err != nil {
+		return fmt.Errorf("logo cannot be embedded: %w", err)
+	}
+	return nil
+}
+
+// TaxSummary totals lines by tax code, in the order the codes first appear.
+// Untaxed lines are left out.
+func TaxSummary(lines []Line) []TaxRow {
+	var rows []TaxRow
+	index := map[string]int{}
+	for _, l := range lines {
+		if l.TaxCode == "" {
+			continue
+		}
+		i, ok := index[l.TaxCode]
+		if !ok {
+			i = len(rows)
+			index[l.TaxCode] = i
+			rows = append(rows, TaxRow{TaxCode: l.TaxCode, TaxRate: l.TaxRate})
+		}
+		rows[i].Taxable += l.Amount
+		rows[i].Tax += l.Tax
+	}
+	return rows
+}
+
+// FormatAmount formats minor units as 1,234.56.
+func FormatAmount(minor int64) string {
+	sign := ""
+	u := uint64(minor)
+	if minor < 0 {
+		sign = "-"
+		u = -u
+	}
+	whole := strconv.FormatUint(u/100, 10)
+	var b strings.Builder
+	b.WriteString(sign)
+	for i, c := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(c)
+	}
+	fmt.Fprintf(&b, ".%02d", u%100)
+	return b.String()
+}
+
+// FormatQuantity formats thousandths without trailing zeros: 1500 is 1.5.
+func FormatQuantity(thousandths int64) string {
+	s := strconv.FormatFloat(float64(thousandths)/1000, 'f', 3, 64)
+	return strings.TrimSuffix(strings.TrimRight(s, "0"), ".")
+}
+
+// FormatRate formats basis points as a percentage: 1250 is 12.5%.
+func FormatRate(basisPoints int64) string {
+	s := strconv.FormatFloat(float64(basisPoints)/100, 'f', 2, 64)
+	return strings.TrimSuffix(strings.TrimRight(s, "0"), ".") + "%"
+}
+
+// DataFor is the template data for inv.
+func DataFor(t Template, inv Invoice) Data {
+	return Data{
+		Number:      inv.Number,
+		Reference:   inv.Reference,
+		Currency:    inv.Currency,
+		IssueOn:     formatDate(inv.IssueOn),
+		DueOn:       formatDate(inv.DueOn),
+		Total:       FormatAmount(inv.Total),
+		Paid:        FormatAmount(inv.Paid),
+		AmountDue:   FormatAmount(inv.Total - inv.Paid),
+		Customer:    inv.Customer,
+		TradingName: t.TradingName,
+	}
+}
+
+// Render writes inv as an A4 PDF laid out with t.
+func Render(w io.Writer, t Template, inv Invoice) error {
+	data := DataFor(t, inv)
+	instructions, err := executeText(t.PaymentInstructions, data)
+	if err != nil {
+		return fmt.Errorf("payment instructions: %w", err)
+	}
+	footer, err := executeText(t.Footer, data)
+	if err != nil {
+		return fmt.Errorf("footer: %w", err)
+	}
+
+	r := newRenderer(t, inv)
+	r.pdf.SetFooterFunc(func() { r.footer(footer) })
+	r.pdf.AddPage()
+	r.header()
+	r.parties()
+	r.details()
+	r.lines()
+	r.totals()
+	r.taxSummary()
+	r.paymentInstructions(instructions)
+
+	if err := r.pdf.Error(); err != nil {
+		return err
+	}
+	return r.pdf.Output(w)
+}
+
+// Page layout, in millimetres.
+const (
+	margin       = 15.0
+	contentWidth = 210 - 2*margin
+	lineHeight   = 5.0
+	bottomMargin = 25.0
+)
+
+// Line table column widths: description, quantity, unit price, tax, amount.
+var columns = [...]float64{82, 18, 28, 22, 30}
+
+type renderer struct {
+	pdf    *fpdf.Fpdf
+	tr     func(string) string
+	t      Template
+	inv    Invoice
+	accent [3]int
+}
+
+func newRenderer(t Template, inv Invoice) *renderer {
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(margin, margin, margin)
+	pdf.SetAutoPageBreak(true, bottomMargin)
+	pdf.AliasNbPages("")
+	// fixed dates keep the output the same for the same invoice
+	pdf.SetCreationDate(inv.IssueOn)
+	pdf.SetModificationDate(inv.IssueOn)
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+	pdf.SetTitle("Invoice "+inv.Number, true)
+	pdf.SetAuthor(t.TradingName, true)
+	pdf.SetCreator("Go Figure", true)
+	return &renderer{pdf: pdf, tr: tr, t: t, inv: inv, accent: parseColor(t.AccentColor)}
+}
+
+func (r *renderer) font(style string, size float64) {
+	r.pdf.SetFont("Helvetica", style, size)
+	r.pdf.SetTextColor(33, 33, 33)
+}
+
+func (r *renderer) header() {
+	pdf := r.pdf
+	top := pdf.GetY()
+	logoBottom := top
+	if len(r.t.Logo) > 0 {
+		imageType, _ := fpdfImageType(r.t.LogoType)
+		info := pdf.RegisterImageOptionsReader("logo", fpdf.ImageOptions{ImageType: imageType}, bytes.NewReader(r.t.Logo))
+		if info != nil && info.Width() > 0 && info.Height() > 0 {
+			// fit within 60mm by 20mm, keeping the aspect ratio
+			w, h := 60.0, 60*info.Height()/info.Width()
+			if h > 20 {
+				w, h = 20*info.Width()/info.Height(), 20
+			}
+			pdf.ImageOptions("logo", margin, top, w, h, false, fpdf.ImageOptions{ImageType: imageType}, 0, "")
+			logoBottom = top + h
+		}
+	}
+
+	title := "INVOICE"
+	if r.t.TaxNumber != "" {
+		title = "TAX INVOICE"
+	}
+	r.font("B", 20)
+	pdf.SetTextColor(r.accent[0], r.accent[1], r.accent[2])
+	pdf.CellFormat(contentWidth, 10, title, "", 1, "R", false, 0, "")
+	switch r.inv.Status {
+	case "draft", "void":
+		r.font("B", 12)
+		pdf.SetTextColor(192, 0, 0)
+		pdf.CellFormat(contentWidth, 6, strings.ToUpper(r.inv.Status), "", 1, "R", false, 0, "")
+	}
+	pdf.SetY(max(pdf.GetY(), logoBottom) + 6)
+}
+
+// parties puts the customer on the left and the seller on the right.
+func (r *renderer) parties() {
+	pdf := r.pdf
+	top := pdf.GetY()
+	half := contentWidth / 2
+
+	r.font("B", 9)
+	pdf.CellFormat(half, lineHeight, "Bill to", "", 2, "L", false, 0, "")
+	r.font("", 10)
+	pdf.MultiCell(half, lineHeight, r.tr(r.inv.Customer), "", "L", false)
+	if r.inv.CustomerEmail != "" {
+		pdf.MultiCell(half, lineHeight, r.tr(r.inv.CustomerEmail), "", "L", false)
+	}
+	left := pdf.GetY()
+
+	pdf.SetXY(margin+half, top)
+	r.font("B", 10)
+	pdf.MultiCell(half, lineHeight, r.tr(r.t.TradingName), "", "R", false)
+	r.font("", 10)
+	for _, l := range strings.Split(strings.TrimSpace(r.t.Address), "\n") {
+		if l = strings.TrimSpace(l); l != "" {
+			pdf.SetX(margin + half)
+			pdf.MultiCell(half, lineHeight, r.tr(l), "", "R", false)
+		}
+	}
+	if r.t.TaxNumber != "" {
+		pdf.SetX(margin + half)
+		pdf.MultiCell(half, lineHeight, r.tr("GST number: "+r.t.TaxNumber), "", "R", false)
+	}
+	if r.t.Email != "" {
+		pdf.SetX(margin + half)
+		pdf.MultiCell(half, lineHeight, r.tr(r.t.Email), "", "R", false)
+	}
+	pdf.SetY(max(left, pdf.GetY()) + 6)
+}
+
+func (r *renderer) details() {
+	pdf := r.pdf
+	fields := [][2]string{
+		{"Invoice number", r.inv.Number},
+		{"Issue date", formatDate(r.inv.IssueOn)},
+		{"Due date", formatDate(r.inv.DueOn)},
+		{"Reference", r.inv.Reference},
+	}
+	w := contentWidth / float64(len(fields))
+	x := pdf.GetX()
+	r.font("B", 9)
+	for _, f := range fields {
+		pdf.CellFormat(w, lineHeight, f[0], "", 0, "L", false, 0, "")
+	}
+	pdf.Ln(lineHeight)
+	pdf.SetX(x)
+	r.font("", 10)
+	for _, f := range fields {
+		pdf.CellFormat(w, lineHeight, fitText(pdf, r.tr, f[1], w-1), "", 0, "L", false, 0, "")
+	}
+	pdf.Ln(lineHeight + 6)
+}
+
+func (r *renderer) tableHeader() {
+	pdf := r.pdf
+	r.font("B", 9)
+	pdf.SetFillColor(r.accent[0], r.accent[1], r.accent[2])
+	pdf.SetTextColor(255, 255, 255)
+	headings := [...]string{"Description", "Quantity", "Unit price", "Tax", "Amount " + r.inv.Currency}
+	for i, h := range headings {
+		align := "R"
+		if i == 0 || i == 3 {
+			align = "L"
+		}
+		pdf.CellFormat(columns[i], 7, h, "", 0, align, true, 0, "")
+	}
+	pdf.Ln(7)
+}
+
+func (r *renderer) lines() {
+	pdf := r.pdf
+	_, pageHeight := pdf.GetPageSize()
+	r.tableHeader()
+	pdf.SetDrawColor(210, 210, 210)
+	for _, l := range r.inv.Lines {
+		r.font("", 9)
+		desc := r.split(l.Description, columns[0]-2)
+		if len(desc) == 0 {
+			desc = []string{""}
+		}
+		h := float64(len(desc))*lineHeight + 2
+		if pdf.GetY()+h > pageHeight-bottomMargin {
+			pdf.AddPage()
+			r.tableHeader()
+			r.font("", 9)
+		}
+
+		y := pdf.GetY()
+		for i, d := range desc {
+			pdf.SetXY(margin, y+1+float64(i)*lineHeight)
+			pdf.CellFormat(columns[0], lineHeight, d, "", 0, "L", false, 0, "")
+		}
+		cells := [...]string{
+			FormatQuantity(l.QuantityThousandths),
+			FormatAmount(l.UnitPrice),
+			fitText(pdf, r.tr, l.TaxCode, columns[3]-1),
+			FormatAmount(l.Amount),
+		}
+		x := margin + columns[0]
+		for i, c := range cells {
+			align := "R"
+			if i == 2 {
+				align = "L"
+			}
+			pdf.SetXY(x, y+1)
+			pdf.CellFormat(columns[i+1], lineHeight, c, "", 0, align, false, 0, "")
+			x += columns[i+1]
+		}
+		pdf.Line(margin, y+h, margin+contentWidth, y+h)
+		pdf.SetXY(margin, y+h)
+	}
+	pdf.Ln(3)
+}
+
+func (r *renderer) totals() {
+	rows := [][2]string{{"Subtotal", FormatAmount(r.inv.Subtotal)}}
+	for _, t := range TaxSummary(r.inv.Lines) {
+		rows = append(rows, [2]string{"Total " + r.tr(t.TaxCode), FormatAmount(t.Tax)})
+	}
+	rows = append(rows, [2]string{"Total " + r.inv.Currency, FormatAmount(r.inv.Total)})
+	if r.inv.Paid != 0 {
+		rows = append(rows, [2]string{"Paid", FormatAmount(r.inv.Paid)})
+	}
+	rows = append(rows, [2]string{"Amount due " + r.inv.Currency, FormatAmount(r.inv.Total - r.inv.Paid)})
+
+	pdf := r.pdf
+	labelWidth, amountWidth := 50.0, 30.0
+	for i, row := range rows {
+		last := i == len(rows)-1
+		style := ""
+		if last {
+			style = "B"
+		}
+		r.font(style, 10)
+		pdf.SetX(margin + contentWidth - labelWidth - amountWidth)
+		border := ""
+		if last {
+			border = "T"
+		}
+		pdf.CellFormat(labelWidth, 6, row[0], border, 0, "R", false, 0, "")
+		pdf.CellFormat(amountWidth, 6, row[1], border, 1, "R", false, 0, "")
+	}
+	pdf.Ln(6)
+}
+
+func (r *renderer) taxSummary() {
+	rows := TaxSummary(r.inv.Lines)
+	if len(rows) == 0 {
+		return
+	}
+	pdf := r.pdf
+	widths := [...]float64{60, 25, 35, 35}
+	r.font("B", 9)
+	pdf.CellFormat(0, lineHeight, "Tax summary", "", 1, "L", false, 0, "")
+	for i, h := range [...]string{"Tax code", "Rate", "Taxable " + r.inv.Currency, "Tax " + r.inv.Currency} {
+		align := "R"
+		if i == 0 {
+			align = "L"
+		}
+		pdf.CellFormat(widths[i], lineHeight, h, "B", 0, align, false, 0, "")
+	}
+	pdf.Ln(lineHeight)
+	r.font("", 9)
+	for _, t := range rows {
+		pdf.CellFormat(widths[0], lineHeight, fitText(pdf, r.tr, t.TaxCode, widths[0]-1), "", 0, "L", false, 0, "")
+		pdf.CellFormat(widths[1], lineHeight, FormatRate(t.TaxRate), "", 0, "R", false, 0, "")
+		pdf.CellFormat(widths[2], lineHeight, FormatAmount(t.Taxable), "", 0, "R", false, 0, "")
+		pdf.CellFormat(widths[3], lineHeight, FormatAmount(t.Tax), "", 1, "R", false, 0, "")
+	}
+	pdf.Ln(6)
+}
+
+func (r *renderer) paymentInstructions(text string) {
+	if strings.TrimSpace(text) == "" {
+		return
+	}
+	pdf := r.pdf
+	r.font("B", 10)
+	pdf.SetTextColor(r.accent[0], r.accent[1], r.accent[2])
+	pdf.CellFormat(0, 6, "How to pay", "", 1, "L", false, 0, "")
+	r.font("", 10)
+	pdf.MultiCell(0, lineHeight, r.tr(strings.TrimSpace(text)), "", "L", false)
+}
+
+func (r *renderer) footer(text string) {
+	pdf := r.pdf
+	pdf.SetY(-bottomMargin + 5)
+	r.font("", 8)
+	pdf.SetTextColor(110, 110, 110)
+	if text = strings.TrimSpace(text); text != "" {
+		// the footer has room for two lines
+		lines := r.split(text, contentWidth-25)
+		if len(lines) > 2 {
+			lines = lines[:2]
+		}
+		y := pdf.GetY()
+		for i, l := range lines {
+			pdf.SetXY(margin, y+float64(i)*4)
+			pdf.CellFormat(contentWidth-25, 4, l, "", 0, "L", false, 0, "")
+		}
+		pdf.SetY(y)
+	}
+	pdf.SetX(margin + contentWidth - 25)
+	pdf.CellFormat(25, 4, fmt.Sprintf("Page %d of {nb}", pdf.PageNo()), "", 0, "R", false, 0, "")
+}
+
+// split translates s and wraps it to width w in the current font. fpdf's
+// SplitText expects UTF-8, so it cannot wrap translated text.
+func (r *renderer) split(s string, w float64) []string {
+	var lines []string
+	for _, l := range r.pdf.SplitLines([]byte(r.tr(s)), w) {
+		lines = append(lines, string(l))
+	}
+	return lines
+}
+
+// fitText translates s and shortens it with an ellipsis to fit width w.
+func fitText(pdf *fpdf.Fpdf, tr func(string) string, s string, w float64) string {
+	t := tr(s)
+	if pdf.GetStringWidth(t) <= w {
+		return t
+	}
+	runes := []rune(s)
+	for len(runes) > 0 {
+		runes = runes[:len(runes)-1]
+		if t = tr(string(runes) + "..."); pdf.GetStringWidth(t) <= w {
+			return t
+		}
+	}
+	return ""
+}
+
+// executeText runs a text template over data, returning "" for an empty
+// source.
+func executeText(src string, data Data) (string, error) {
+	if strings.TrimSpace(src) == "" {
+		return "", nil
+	}
+	tmpl, err := template.New("text").Option("missingkey=error").Parse(src)
+	if err != nil {
+		return "", err
+	}
+	out := &limitedBuffer{max: MaxTextOutput}
+	if err := tmpl.Execute(out, data); err != nil {
+		if errors.Is(err, ErrTextTooLong) {
+			return "", ErrTextTooLong
+		}
+		return "", err
+	}
+	return out.String(), nil
+}
+
+// limitedBuffer fails writes past max bytes, which stops a template that
+// expands without bound.
+type limitedBuffer struct {
+	bytes.Buffer
+	max int
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if b.Len()+len(p) > b.max {
+		return 0, ErrTextTooLong
+	}
+	return b.Buffer.Write(p)
+}
+
+func fpdfImageType(contentType string) (string, error) {
+	switch contentType {
+	case LogoPNG:
+		return "PNG", nil
+	case LogoJPEG:
+		return "JPG", nil
+	}
+	return "", fmt.Errorf("logo must be %s or %s", LogoPNG, LogoJPEG)
+}
+
+// parseColor reads "#rrggbb", falling back to a dark blue.
+func parseColor(s string) [3]int {
+	var c [3]int
+	if len(s) == 7 && s[0] == '#' {
+		ok := true
+		for i := range c {
+			v, err := strconv.ParseUint(s[1+2*i:3+2*i], 16, 8)
+			ok = ok && err == nil
+			c[i] = int(v)
+		}
+		if ok {
+			return c
+		}
+	}
+	return [3]int{0x1f, 0x4e, 0x79}
+}
+
+func formatDate(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format("2 Jan 2006")
+}
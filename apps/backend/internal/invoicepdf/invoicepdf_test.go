@@ -0,0 +1,116 @@
+package invoicepdf
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func testLogo(t *testing.T) []byte {
+	img := image.NewRGBA(image.Rect(0, 0, 120, 40))
+	for x := range 120 {
+		for y := range 40 {
+			img.Set(x, y, color.RGBA{R: uint8(x), G: 80, B: 160, A: 255})
+		}
+	}
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, img))
+	return buf.Bytes()
+}
+
+func TestRender(t *testing.T) {
+	tmpl := Template{
+		TradingName:         "Kōwhai Consulting",
+		Address:             "1 Queen Street\nAuckland 1010",
+		TaxNumber:           "123-456-789",
+		AccentColor:         "#336699",
+		PaymentInstructions: "Pay {{.AmountDue}} {{.Currency}} to 12-3456-0123456-00 by {{.DueOn}}, quoting {{.Number}}.",
+		Footer:              "{{.TradingName}} · Thank you",
+		Logo:                testLogo(t),
+		LogoType:            LogoPNG,
+	}
+	inv := Invoice{
+		Number:   "INV-0042",
+		Currency: "NZD",
+		Status:   "sent",
+		IssueOn:  time.Date(2026, 5, 20, 0, 0, 0, 0, time.UTC),
+		DueOn:    time.Date(2026, 6, 19, 0, 0, 0, 0, time.UTC),
+		Customer: "Café Ltd",
+		Subtotal: 20000,
+		Tax:      3000,
+		Total:    23000,
+		Paid:     5000,
+	}
+	for i := range 60 {
+		inv.Lines = append(inv.Lines, Line{
+			Description:         strings.Repeat("Consulting on the ledger migration ", i%4+1),
+			QuantityThousandths: 1500,
+			UnitPrice:           10000,
+			TaxCode:             "GST 15%",
+			TaxRate:             1500,
+			Amount:              15000,
+			Tax:                 2250,
+		})
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, Render(&buf, tmpl, inv))
+	require.True(t, bytes.HasPrefix(buf.Bytes(), []byte("%PDF-")))
+
+	var again bytes.Buffer
+	require.NoError(t, Render(&again, tmpl, inv))
+	require.Equal(t, buf.Bytes(), again.Bytes(), "same invoice, same bytes")
+
+	require.NoError(t, Render(&bytes.Buffer{}, Template{}, Invoice{Number: "INV-1", Status: "draft"}))
+}
+
+func TestRenderRejectsBrokenTemplate(t *testing.T) {
+	err := Render(&bytes.Buffer{}, Template{Footer: "{{.Nope}}"}, Invoice{})
+	require.ErrorContains(t, err, "footer")
+}
+
+func TestCheckText(t *testing.T) {
+	require.NoError(t, CheckText(""))
+	require.NoError(t, CheckText("Due {{.DueOn}}: {{.AmountDue}}"))
+	require.Error(t, CheckText("{{.DueOn"))
+	require.Error(t, CheckText("{{.BankAccount}}"))
+	require.ErrorIs(t, CheckText("{{range 1000}}{{$.Number}}{{end}}"), ErrTextTooLong)
+}
+
+func TestCheckLogo(t *testing.T) {
+	logo := testLogo(t)
+	require.NoError(t, CheckLogo(logo, LogoPNG))
+	require.Error(t, CheckLogo(logo, LogoJPEG))
+	require.Error(t, CheckLogo(logo, "image/gif"))
+	require.Error(t, CheckLogo([]byte("not an image"), LogoPNG))
+}
+
+func TestTaxSummary(t *testing.T) {
+	rows := TaxSummary([]Line{
+		{TaxCode: "GST 15%", TaxRate: 1500, Amount: 10000, Tax: 1500},
+		{Amount: 500},
+		{TaxCode: "Zero rated", Amount: 2000},
+		{TaxCode: "GST 15%", TaxRate: 1500, Amount: -1000, Tax: -150},
+	})
+	require.Equal(t, []TaxRow{
+		{TaxCode: "GST 15%", TaxRate: 1500, Taxable: 9000, Tax: 1350},
+		{TaxCode: "Zero rated", Taxable: 2000},
+	}, rows)
+}
+
+func TestFormat(t *testing.T) {
+	require.Equal(t, "0.05", FormatAmount(5))
+	require.Equal(t, "1,234,567.89", FormatAmount(123456789))
+	require.Equal(t, "-1,000.00", FormatAmount(-100000))
+	require.Equal(t, "1.5", FormatQuantity(1500))
+	require.Equal(t, "2", FormatQuantity(2000))
+	require.Equal(t, "0.125", FormatQuantity(125))
+	require.Equal(t, "15%", FormatRate(1500))
+	require.Equal(t, "12.5%", FormatRate(1250))
+}
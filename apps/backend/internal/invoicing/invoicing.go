@@ -0,0 +1,114 @@
+// Package invoicing works out invoice amounts and how overdue they are. All
+// amounts are in minor units; quantities are in thousandths so fractional
+// quantities such as 1.5 hours stay exact.
+package invoicing
+
+import "time"
+
+// Limits that keep every product and sum within int64: 100 lines at the
+// largest quantity and price, plus their tax, still fit.
+const (
+	// MaxQuantityThousandths is a quantity of one million.
+	MaxQuantityThousandths = 1_000_000_000
+	// MaxUnitPrice is the largest unit price, in minor units, either way.
+	MaxUnitPrice = 10_000_000_000
+	// MaxTaxRate is 100%, in basis points.
+	MaxTaxRate = 10_000
+)
+
+// Line is one invoice line before amounts are worked out.
+type Line struct {
+	QuantityThousandths int64
+	UnitPrice           int64
+	// TaxRate is in basis points; 1500 is 15%.
+	TaxRate int64
+}
+
+// Amounts is what one line, or a whole invoice, comes to.
+type Amounts struct {
+	// Net is before tax.
+	Net   int64
+	Tax   int64
+	Total int64
+}
+
+// LineAmounts works out a line: quantity times unit price, then tax on that
+// net amount. Both are rounded half away from zero, once each, so a negative
+// line (a discount) is the exact opposite of the positive one.
+func LineAmounts(l Line) Amounts {
+	// split the products so neither can overflow within the limits
+	q := l.QuantityThousandths
+	net := l.UnitPrice*(q/1000) + divRound(l.UnitPrice*(q%1000), 1000)
+	tax := (net/10000)*l.TaxRate + divRound((net%10000)*l.TaxRate, 10000)
+	return Amounts{Net: net, Tax: tax, Total: net + tax}
+}
+
+// Invoice works out each line and the invoice totals. Tax is rounded per
+// line, so the invoice tax is always the sum of what its lines show.
+func Invoice(lines []Line) ([]Amounts, Amounts) {
+	out := make([]Amounts, len(lines))
+	var total Amounts
+	for i, l := range lines {
+		out[i] = LineAmounts(l)
+		total.Net += out[i].Net
+		total.Tax += out[i].Tax
+		total.Total += out[i].Total
+	}
+	return out, total
+}
+
+// divRound divides n by a positive d, rounding half away from zero.
+func divRound(n, d int64) int64 {
+	if n < 0 {
+		return -((-n + d/2) / d)
+	}
+	return (n + d/2) / d
+}
+
+// Bucket is an age band for money owed, by days past the due date.
+type Bucket int
+
+const (
+	Current Bucket = iota
+	Overdue1To30
+	Overdue31To60
+	Overdue61To90
+	OverdueOver90
+)
+
+// Buckets lists every band, youngest first.
+var Buckets = []Bucket{Current, Overdue1To30, Overdue31To60, Overdue61To90, OverdueOver90}
+
+// String is the band's name in reports.
+func (b Bucket) String() string {
+	switch b {
+	case Current:
+		return "current"
+	case Overdue1To30:
+		return "1_30"
+	case Overdue31To60:
+		return "31_60"
+	case Overdue61To90:
+		return "61_90"
+	default:
+		return "over_90"
+	}
+}
+
+// Age places an amount due on dueOn into its band as of asOf. Both are
+// calendar dates; anything not yet past due, including due today, is current.
+func Age(dueOn, asOf time.Time) Bucket {
+	days := int(asOf.Sub(dueOn).Hours() / 24)
+	switch {
+	case days <= 0:
+		return Current
+	case days <= 30:
+		return Overdue1To30
+	case days <= 60:
+		return Overdue31To60
+	case days <= 90:
+		return Overdue61To90
+	default:
+		return OverdueOver90
+	}
+}
@@ -0,0 +1,70 @@
+package invoicing
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLineAmountsRoundsHalfAwayFromZero(t *testing.T) {
+	tests := []struct {
+		line Line
+		want Amounts
+	}{
+		{Line{QuantityThousandths: 1000, UnitPrice: 10000, TaxRate: 1500}, Amounts{Net: 10000, Tax: 1500, Total: 11500}},
+		// 1.5 x 33.33 = 49.995
+		{Line{QuantityThousandths: 1500, UnitPrice: 3333, TaxRate: 0}, Amounts{Net: 5000, Tax: 0, Total: 5000}},
+		// 15% of 49.99 = 7.4985
+		{Line{QuantityThousandths: 1000, UnitPrice: 4999, TaxRate: 1500}, Amounts{Net: 4999, Tax: 750, Total: 5749}},
+		{Line{QuantityThousandths: 1500, UnitPrice: -3333, TaxRate: 1500}, Amounts{Net: -5000, Tax: -750, Total: -5750}},
+		{Line{QuantityThousandths: 250, UnitPrice: 2, TaxRate: 1250}, Amounts{Net: 1, Tax: 0, Total: 1}},
+	}
+	for _, tt := range tests {
+		require.Equal(t, tt.want, LineAmounts(tt.line), "%+v", tt.line)
+	}
+}
+
+func TestLineAmountsAtLimitsDoNotOverflow(t *testing.T) {
+	l := Line{QuantityThousandths: MaxQuantityThousandths, UnitPrice: MaxUnitPrice, TaxRate: MaxTaxRate}
+	got := LineAmounts(l)
+	require.Equal(t, int64(MaxUnitPrice)*1_000_000, got.Net)
+	require.Equal(t, got.Net, got.Tax)
+
+	lines := make([]Line, 100)
+	for i := range lines {
+		lines[i] = l
+	}
+	_, total := Invoice(lines)
+	require.Equal(t, 200*got.Net, total.Total)
+}
+
+func TestInvoiceSumsLines(t *testing.T) {
+	lines, total := Invoice([]Line{
+		{QuantityThousandths: 2000, UnitPrice: 4999, TaxRate: 1500},
+		{QuantityThousandths: 1000, UnitPrice: -1000, TaxRate: 1500},
+		{QuantityThousandths: 1000, UnitPrice: 2500},
+	})
+	require.Len(t, lines, 3)
+	require.Equal(t, Amounts{Net: 11498, Tax: 1350, Total: 12848}, total)
+}
+
+func TestAge(t *testing.T) {
+	due := time.Date(2026, 3, 31, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		asOf time.Time
+		want Bucket
+	}{
+		{due.AddDate(0, 0, -5), Current},
+		{due, Current},
+		{due.AddDate(0, 0, 1), Overdue1To30},
+		{due.AddDate(0, 0, 30), Overdue1To30},
+		{due.AddDate(0, 0, 31), Overdue31To60},
+		{due.AddDate(0, 0, 60), Overdue31To60},
+		{due.AddDate(0, 0, 90), Overdue61To90},
+		{due.AddDate(0, 0, 91), OverdueOver90},
+	}
+	for _, tt := range tests {
+		require.Equal(t, tt.want, Age(due, tt.asOf), tt.asOf.Format(time.DateOnly))
+	}
+}
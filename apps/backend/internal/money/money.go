@@ -0,0 +1,64 @@
+// Package money does arithmetic on amounts in minor units, where every result
+// must still add up to the amount it came from.
+package money
+
+import (
+	"errors"
+	"math/bits"
+	"sort"
+)
+
+// ErrInvalidWeights is returned when weights are empty, negative or all zero.
+var ErrInvalidWeights = errors.New("money: weights must be non-negative with a positive sum")
+
+// Allocate splits total in proportion to weights and returns one share per
+// weight. The shares always sum to exactly total: each share is first rounded
+// towards zero, then the minor units left over go one each to the shares with
+// the largest remainders, earlier shares first on ties. The same input always
+// gives the same split. Shares carry total's sign.
+func Allocate(total int64, weights []int64) ([]int64, error) {
+	var sum uint64
+	for _, w := range weights {
+		if w < 0 {
+			return nil, ErrInvalidWeights
+		}
+		sum += uint64(w)
+	}
+	if sum == 0 {
+		return nil, ErrInvalidWeights
+	}
+
+	abs := uint64(total)
+	if total < 0 {
+		abs = -abs
+	}
+
+	shares := make([]uint64, len(weights))
+	rems := make([]uint64, len(weights))
+	var allocated uint64
+	for i, w := range weights {
+		// abs*w can exceed 64 bits; the quotient cannot, since w <= sum
+		hi, lo := bits.Mul64(abs, uint64(w))
+		shares[i], rems[i] = bits.Div64(hi, lo, sum)
+		allocated += shares[i]
+	}
+
+	order := make([]int, len(weights))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return rems[order[a]] > rems[order[b]] })
+	for i := uint64(0); i < abs-allocated; i++ {
+		shares[order[i]]++
+	}
+
+	out := make([]int64, len(shares))
+	for i, s := range shares {
+		if total < 0 {
+			out[i] = -int64(s)
+		} else {
+			out[i] = int64(s)
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,41 @@
+package money
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAllocateSumsExactly(t *testing.T) {
+	tests := []struct {
+		total   int64
+		weights []int64
+		want    []int64
+	}{
+		{100, []int64{1, 1, 1}, []int64{34, 33, 33}},
+		{-100, []int64{1, 1, 1}, []int64{-34, -33, -33}},
+		{8450, []int64{5000, 3000, 2000}, []int64{4225, 2535, 1690}},
+		{5, []int64{3333, 3333, 3334}, []int64{2, 1, 2}},
+		{1, []int64{0, 1}, []int64{0, 1}},
+		{0, []int64{1, 2}, []int64{0, 0}},
+	}
+	for _, tt := range tests {
+		got, err := Allocate(tt.total, tt.weights)
+		require.NoError(t, err)
+		require.Equal(t, tt.want, got)
+	}
+}
+
+func TestAllocateLargeAmounts(t *testing.T) {
+	const max = 1<<63 - 1
+	got, err := Allocate(max, []int64{max, max})
+	require.NoError(t, err)
+	require.Equal(t, int64(max), got[0]+got[1])
+}
+
+func TestAllocateRejectsBadWeights(t *testing.T) {
+	for _, w := range [][]int64{nil, {0, 0}, {1, -1}} {
+		_, err := Allocate(100, w)
+		require.ErrorIs(t, err, ErrInvalidWeights)
+	}
+}
@@ -0,0 +1,69 @@
+// Package ocr recognises the text in photographed documents such as
+// receipts. Engines are pluggable: Tesseract runs the tesseract command,
+// Remote calls another process serving an engine through Handler, and Fake
+// returns canned text for tests.
+package ocr
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrUnsupported is returned for content an engine cannot read, such as a
+// PDF given to an engine that only reads images.
+var ErrUnsupported = errors.New("ocr: unsupported content type")
+
+// Engine recognises text. Implementations must be safe for concurrent use.
+type Engine interface {
+	Recognize(ctx context.Context, data []byte, contentType string) (Result, error)
+}
+
+// Result is the recognised text, top to bottom.
+type Result struct {
+	Lines []Line `json:"lines"`
+}
+
+// Line is one line of text with the engine's confidence in it, from 0 to 1.
+type Line struct {
+	Text       string  `json:"text"`
+	Confidence float64 `json:"confidence"`
+}
+
+// Fake is an Engine for tests. It returns Result, or Err when set, and counts
+// its calls.
+type Fake struct {
+	Result Result
+	Err    error
+
+	mu    sync.Mutex
+	calls int
+}
+
+// FakeLines returns a Fake recognising each of lines with confidence 0.9.
+func FakeLines(lines ...string) *Fake {
+	f := &Fake{}
+	for _, l := range lines {
+		f.Result.Lines = append(f.Result.Lines, Line{Text: l, Confidence: 0.9})
+	}
+	return f
+}
+
+func (f *Fake) Recognize(ctx context.Context, data []byte, contentType string) (Result, error) {
+	f.mu.Lock()
+	f.calls++
+	f.mu.Unlock()
+	if f.Err != nil {
+		return Result{}, f.Err
+	}
+	return f.Result, nil
+}
+
+// Calls reports how many times Recognize ran.
+func (f *Fake) Calls() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.calls
+}
+
+var _ Engine = (*Fake)(nil)
@@ -0,0 +1,78 @@
+package ocr
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const sampleTSV = "level\tpage_num\tblock_num\tpar_num\tline_num\tword_num\tleft\ttop\twidth\theight\tconf\ttext\n" +
+	"1\t1\t0\t0\t0\t0\t0\t0\t600\t900\t-1\t\n" +
+	"4\t1\t1\t1\t1\t0\t40\t30\t300\t40\t-1\t\n" +
+	"5\t1\t1\t1\t1\t1\t40\t30\t150\t40\t96.5\tCOUNTDOWN\n" +
+	"5\t1\t1\t1\t1\t2\t200\t30\t140\t40\t91.5\tPONSONBY\n" +
+	"4\t1\t1\t1\t2\t0\t40\t80\t300\t40\t-1\t\n" +
+	"5\t1\t1\t1\t2\t1\t40\t80\t100\t40\t88\tTOTAL\n" +
+	"5\t1\t1\t1\t2\t2\t200\t80\t100\t40\t72\t$84.50\n" +
+	"5\t1\t2\t1\t1\t1\t40\t130\t100\t40\t60\t \n"
+
+func TestParseTSV(t *testing.T) {
+	res, err := parseTSV(sampleTSV)
+	require.NoError(t, err)
+	require.Len(t, res.Lines, 2)
+	require.Equal(t, "COUNTDOWN PONSONBY", res.Lines[0].Text)
+	require.InDelta(t, 0.94, res.Lines[0].Confidence, 1e-9)
+	require.Equal(t, "TOTAL $84.50", res.Lines[1].Text)
+	require.InDelta(t, 0.80, res.Lines[1].Confidence, 1e-9)
+
+	_, err = parseTSV("5\t1\t1")
+	require.Error(t, err)
+}
+
+func TestTesseractRejectsPDF(t *testing.T) {
+	_, err := (&Tesseract{}).Recognize(context.Background(), []byte("%PDF-1.7"), "application/pdf")
+	require.ErrorIs(t, err, ErrUnsupported)
+}
+
+// TestTesseractRuns checks the command line against a real tesseract when
+// one is installed.
+func TestTesseractRuns(t *testing.T) {
+	if _, err := exec.LookPath("tesseract"); err != nil {
+		t.Skip("tesseract not installed")
+	}
+	// a blank 1x1 PNG has no text but must not fail
+	png := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR\x00\x00\x00\x01\x00\x00\x00\x01\x08\x06\x00\x00\x00\x1f\x15\xc4\x89\x00\x00\x00\rIDATx\x9cc\xf8\xff\xff?\x00\x05\xfe\x02\xfe\xa7\x35\x81\x84\x00\x00\x00\x00IEND\xaeB`\x82")
+	_, err := (&Tesseract{}).Recognize(context.Background(), png, "image/png")
+	require.NoError(t, err)
+}
+
+func TestFake(t *testing.T) {
+	f := FakeLines("A", "B")
+	res, err := f.Recognize(context.Background(), nil, "image/png")
+	require.NoError(t, err)
+	require.Len(t, res.Lines, 2)
+	require.Equal(t, 1, f.Calls())
+}
+
+func TestRemoteRoundTrip(t *testing.T) {
+	engine := FakeLines("COUNTDOWN PONSONBY", "TOTAL 84.50")
+	srv := httptest.NewServer(Handler(engine))
+	defer srv.Close()
+
+	res, err := (&Remote{URL: srv.URL}).Recognize(context.Background(), []byte("jpeg"), "image/jpeg")
+	require.NoError(t, err)
+	require.Equal(t, engine.Result, res)
+
+	// engine errors keep their meaning across the wire
+	engine.Err = ErrUnsupported
+	_, err = (&Remote{URL: srv.URL}).Recognize(context.Background(), []byte("%PDF"), "application/pdf")
+	require.ErrorIs(t, err, ErrUnsupported)
+
+	engine.Err = errors.New("out of memory")
+	_, err = (&Remote{URL: srv.URL}).Recognize(context.Background(), []byte("jpeg"), "image/jpeg")
+	require.ErrorContains(t, err, "500 Internal Server Error: out of memory")
+}
@@ -0,0 +1,92 @@
+package ocr
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// MaxImageBytes bounds the documents Handler reads.
+const MaxImageBytes = 16 << 20
+
+// Remote is an Engine that sends each document to a Handler over HTTP, so
+// the process scanning receipts needs no OCR tooling of its own.
+type Remote struct {
+	// URL is the Handler's base URL, such as http://ocr:8090.
+	URL string
+	// Client defaults to http.DefaultClient; ctx bounds each call.
+	Client *http.Client
+}
+
+func (e *Remote) Recognize(ctx context.Context, data []byte, contentType string) (Result, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimRight(e.URL, "/")+"/recognize", bytes.NewReader(data))
+	if err != nil {
+		return Result{}, fmt.Errorf("ocr: %w", err)
+	}
+	req.Header.Set("Content-Type", contentType)
+	client := e.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return Result{}, fmt.Errorf("ocr: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusUnsupportedMediaType:
+		return Result{}, fmt.Errorf("%w: %s", ErrUnsupported, contentType)
+	default:
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<10))
+		return Result{}, fmt.Errorf("ocr: remote engine: %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	var res Result
+	if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		return Result{}, fmt.Errorf("ocr: remote engine: %w", err)
+	}
+	return res, nil
+}
+
+// Handler serves engine to Remote clients: POST /recognize takes the
+// document as the body, typed by its Content-Type, and answers with the
+// Result as JSON, or 415 for content the engine cannot read. GET /healthz
+// answers 200.
+func Handler(engine Engine) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	mux.HandleFunc("POST /recognize", func(w http.ResponseWriter, r *http.Request) {
+		data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, MaxImageBytes))
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				http.Error(w, "document too large", http.StatusRequestEntityTooLarge)
+				return
+			}
+			http.Error(w, "failed to read body", http.StatusBadRequest)
+			return
+		}
+		res, err := engine.Recognize(r.Context(), data, r.Header.Get("Content-Type"))
+		if errors.Is(err, ErrUnsupported) {
+			http.Error(w, err.Error(), http.StatusUnsupportedMediaType)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(res)
+	})
+	return mux
+}
+
+var _ Engine = (*Remote)(nil)
@@ -0,0 +1,101 @@
+package ocr
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// Tesseract runs the tesseract command line tool, which must be on PATH or
+// at Path. It reads JPEG, PNG and WebP images; PDFs are ErrUnsupported.
+type Tesseract struct {
+	// Path defaults to "tesseract".
+	Path string
+	// Language is a tesseract language code; defaults to "eng".
+	Language string
+}
+
+func (t *Tesseract) Recognize(ctx context.Context, data []byte, contentType string) (Result, error) {
+	switch contentType {
+	case "image/jpeg", "image/png", "image/webp":
+	default:
+		return Result{}, fmt.Errorf("%w: %s", ErrUnsupported, contentType)
+	}
+	path, lang := t.Path, t.Language
+	if path == "" {
+		path = "tesseract"
+	}
+	if lang == "" {
+		lang = "eng"
+	}
+
+	// --psm 4 reads a single column of text of variable sizes, which is
+	// what a till receipt is
+	cmd := exec.CommandContext(ctx, path, "stdin", "stdout", "-l", lang, "--psm", "4", "tsv")
+	cmd.Stdin = bytes.NewReader(data)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		return Result{}, fmt.Errorf("ocr: tesseract: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return parseTSV(stdout.String())
+}
+
+// parseTSV groups the words of tesseract's TSV output into lines. A line's
+// confidence is the mean of its words'.
+func parseTSV(tsv string) (Result, error) {
+	type lineKey struct{ page, block, par, line string }
+	var (
+		res     Result
+		current lineKey
+		words   []string
+		total   float64
+	)
+	flush := func() {
+		if len(words) > 0 {
+			res.Lines = append(res.Lines, Line{
+				Text:       strings.Join(words, " "),
+				Confidence: total / float64(len(words)) / 100,
+			})
+		}
+		words, total = nil, 0
+	}
+
+	rows := strings.Split(strings.TrimRight(tsv, "\n"), "\n")
+	for i, row := range rows {
+		if i == 0 && strings.HasPrefix(row, "level") {
+			continue
+		}
+		cols := strings.Split(strings.TrimRight(row, "\r"), "\t")
+		if len(cols) < 12 {
+			return Result{}, fmt.Errorf("ocr: malformed tesseract row %d", i+1)
+		}
+		// level 5 rows are words; the others outline pages, blocks,
+		// paragraphs and lines and carry confidence -1
+		if cols[0] != "5" {
+			continue
+		}
+		text := strings.TrimSpace(cols[11])
+		if text == "" {
+			continue
+		}
+		conf, err := strconv.ParseFloat(cols[10], 64)
+		if err != nil {
+			return Result{}, fmt.Errorf("ocr: malformed confidence on row %d", i+1)
+		}
+		key := lineKey{cols[1], cols[2], cols[3], cols[4]}
+		if key != current {
+			flush()
+			current = key
+		}
+		words = append(words, text)
+		total += max(conf, 0)
+	}
+	flush()
+	return res, nil
+}
+
+var _ Engine = (*Tesseract)(nil)
@@ -0,0 +1,402 @@
+package openapi
+
+import (
+	"fmt"
+	"go/format"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// GenerateClient renders Go types for every component schema and one method
+// per operation. The output relies on the hand-written half of the client
+// package for Client, the do helper and error decoding.
+func GenerateClient(d *Document, pkg string) ([]byte, error) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "// Code generated by openapigen from the OpenAPI spec. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&b, "package %s\n\n", pkg)
+
+	paths := d.SortedPaths()
+	needsFmt, needsURL, needsStrconv := false, false, false
+	for _, p := range paths {
+		for _, op := range d.Paths[p] {
+			for _, prm := range op.Parameters {
+				switch prm.In {
+				case "path":
+					needsFmt, needsURL = true, true
+				case "query":
+					needsURL = true
+					if d.queryType(prm.Schema) != "string" {
+						needsStrconv = true
+					}
+				}
+			}
+		}
+	}
+	b.WriteString("import (\n\t\"context\"\n")
+	if needsFmt {
+		b.WriteString("\t\"fmt\"\n")
+	}
+	b.WriteString("\t\"net/http\"\n")
+	if needsURL {
+		b.WriteString("\t\"net/url\"\n")
+	}
+	if needsStrconv {
+		b.WriteString("\t\"strconv\"\n")
+	}
+	b.WriteString(")\n\n")
+
+	names := make([]string, 0, len(d.Components.Schemas))
+	for name := range d.Components.Schemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		d.writeType(&b, name, d.Components.Schemas[name])
+	}
+
+	for _, p := range paths {
+		item := d.Paths[p]
+		for _, m := range item.Methods() {
+			if err := d.writeOperation(&b, p, m, item[m]); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	out, err := format.Source([]byte(b.String()))
+	if err != nil {
+		return nil, fmt.Errorf("format generated client: %w", err)
+	}
+	return out, nil
+}
+
+func (d *Document) writeType(b *strings.Builder, name string, s *Schema) {
+	if s.Description != "" {
+		fmt.Fprintf(b, "// %s %s\n", name, s.Description)
+	}
+	fmt.Fprintf(b, "type %s struct {\n", name)
+	props := make([]string, 0, len(s.Properties))
+	for p := range s.Properties {
+		props = append(props, p)
+	}
+	sort.Strings(props)
+	required := make(map[string]bool, len(s.Required))
+	for _, r := range s.Required {
+		required[r] = true
+	}
+	for _, p := range props {
+		prop := s.Properties[p]
+		tag := p
+		if !required[p] {
+			tag += ",omitempty"
+		}
+		if prop.Description != "" {
+			fmt.Fprintf(b, "\t// %s\n", prop.Description)
+		}
+		fmt.Fprintf(b, "\t%s %s `json:%q`\n", GoName(p), d.goType(prop), tag)
+	}
+	b.WriteString("}\n\n")
+}
+
+func (d *Document) goType(s *Schema) string {
+	if s == nil {
+		return "any"
+	}
+	if s.Ref != "" {
+		return s.RefName()
+	}
+	types, nullable := s.Types()
+	if len(types) == 0 {
+		return "any"
+	}
+	var t string
+	switch types[0] {
+	case "string":
+		t = "string"
+	case "boolean":
+		t = "bool"
+	case "number":
+		t = "float64"
+	case "integer":
+		t = "int64"
+		if s.Format == "int32" {
+			t = "int32"
+		}
+	case "array":
+		return "[]" + d.goType(s.Items)
+	case "object":
+		if s.AdditionalProperties != nil {
+			return "map[string]" + d.goType(s.AdditionalProperties)
+		}
+		return "map[string]any"
+	default:
+		t = "any"
+	}
+	if nullable {
+		return "*" + t
+	}
+	return t
+}
+
+func (d *Document) writeOperation(b *strings.Builder, path, method string, op *Operation) error {
+	name := GoName(op.OperationID)
+
+	var pathParams, queryParams []Parameter
+	for _, p := range op.Parameters {
+		switch p.In {
+		case "path":
+			pathParams = append(pathParams, p)
+		case "query":
+			queryParams = append(queryParams, p)
+		}
+	}
+	// keep path parameters in template order so call sites read left to right
+	sort.SliceStable(pathParams, func(i, j int) bool {
+		return strings.Index(path, "{"+pathParams[i].Name+"}") < strings.Index(path, "{"+pathParams[j].Name+"}")
+	})
+
+	paramsType := name + "Params"
+	if len(queryParams) > 0 {
+		fmt.Fprintf(b, "// %s holds the query parameters of %s.\n", paramsType, name)
+		fmt.Fprintf(b, "type %s struct {\n", paramsType)
+		for _, p := range queryParams {
+			if p.Description != "" {
+				fmt.Fprintf(b, "\t// %s\n", p.Description)
+			}
+			fmt.Fprintf(b, "\t%s %s\n", GoName(p.Name), d.queryType(p.Schema))
+		}
+		b.WriteString("}\n\n")
+	}
+
+	args := []string{"ctx context.Context"}
+	for _, p := range pathParams {
+		args = append(args, lowerFirst(GoName(p.Name))+" string")
+	}
+	if len(queryParams) > 0 {
+		args = append(args, "params *"+paramsType)
+	}
+	bodyArg := "nil"
+	if op.RequestBody != nil {
+		if mt, ok := op.RequestBody.Content["application/json"]; ok {
+			args = append(args, "body "+d.goType(mt.Schema))
+			bodyArg = "body"
+		} else if mt, ok := op.RequestBody.Content["multipart/form-data"]; ok {
+			field, err := d.fileField(mt.Schema)
+			if err != nil {
+				return fmt.Errorf("%s: %w", op.OperationID, err)
+			}
+			args = append(args, "file File")
+			bodyArg = fmt.Sprintf("formFile{field: %q, file: file}", field)
+		}
+	}
+
+	resp := successResponse(op)
+	var result, zero, outArg string
+	if resp != nil {
+		if mt, ok := resp.Content["application/json"]; ok && mt.Schema != nil {
+			t := d.goType(mt.Schema)
+			if mt.Schema.Ref != "" {
+				t = "*" + t
+			}
+			result, zero, outArg = t, "nil", "&out"
+		} else if _, ok := resp.Content["text/plain"]; ok {
+			result, zero, outArg = "string", `""`, "&out"
+		} else if len(resp.Content) > 0 {
+			result, zero, outArg = "[]byte", "nil", "&out"
+		}
+	}
+
+	fmt.Fprintf(b, "// %s calls %s %s.\n", name, strings.ToUpper(method), path)
+	if op.Summary != "" {
+		fmt.Fprintf(b, "//\n// %s\n", op.Summary)
+	}
+	if result != "" {
+		fmt.Fprintf(b, "func (c *Client) %s(%s) (%s, error) {\n", name, strings.Join(args, ", "), result)
+	} else {
+		fmt.Fprintf(b, "func (c *Client) %s(%s) error {\n", name, strings.Join(args, ", "))
+	}
+
+	// path
+	pathExpr := strconv.Quote(path)
+	if len(pathParams) > 0 {
+		format := path
+		var vals []string
+		for _, p := range pathParams {
+			format = strings.Replace(format, "{"+p.Name+"}", "%s", 1)
+			vals = append(vals, "url.PathEscape("+lowerFirst(GoName(p.Name))+")")
+		}
+		pathExpr = fmt.Sprintf("fmt.Sprintf(%q, %s)", format, strings.Join(vals, ", "))
+	}
+
+	queryArg := "nil"
+	if len(queryParams) > 0 {
+		queryArg = "q"
+		b.WriteString("\tq := url.Values{}\n\tif params != nil {\n")
+		for _, p := range queryParams {
+			field := "params." + GoName(p.Name)
+			switch d.queryType(p.Schema) {
+			case "string":
+				fmt.Fprintf(b, "\t\tif %s != \"\" {\n\t\t\tq.Set(%q, %s)\n\t\t}\n", field, p.Name, field)
+			case "*int64":
+				fmt.Fprintf(b, "\t\tif %s != nil {\n\t\t\tq.Set(%q, strconv.FormatInt(*%s, 10))\n\t\t}\n", field, p.Name, field)
+			case "*bool":
+				fmt.Fprintf(b, "\t\tif %s != nil {\n\t\t\tq.Set(%q, strconv.FormatBool(*%s))\n\t\t}\n", field, p.Name, field)
+			}
+		}
+		b.WriteString("\t}\n")
+	}
+
+	if result != "" {
+		if strings.HasPrefix(result, "*") {
+			fmt.Fprintf(b, "\tvar out %s\n", strings.TrimPrefix(result, "*"))
+		} else {
+			fmt.Fprintf(b, "\tvar out %s\n", result)
+		}
+		fmt.Fprintf(b, "\tif err := c.do(ctx, %s, %s, %s, %s, %s); err != nil {\n\t\treturn %s, err\n\t}\n",
+			methodConst(method), pathExpr, queryArg, bodyArg, outArg, zero)
+		if strings.HasPrefix(result, "*") {
+			b.WriteString("\treturn &out, nil\n}\n\n")
+		} else {
+			b.WriteString("\treturn out, nil\n}\n\n")
+		}
+		return nil
+	}
+	fmt.Fprintf(b, "\treturn c.do(ctx, %s, %s, %s, %s, nil)\n}\n\n", methodConst(method), pathExpr, queryArg, bodyArg)
+	return nil
+}
+
+// fileField names the one binary field of a multipart/form-data upload; the
+// hand-written client can only send a form holding a single file.
+func (d *Document) fileField(s *Schema) (string, error) {
+	s = d.Resolve(s)
+	if s == nil || len(s.Properties) != 1 {
+		return "", fmt.Errorf("multipart body must have exactly one field")
+	}
+	for name, prop := range s.Properties {
+		if prop.Format != "binary" {
+			return "", fmt.Errorf("multipart field %s is not binary", name)
+		}
+		return name, nil
+	}
+	return "", nil
+}
+
+func (d *Document) queryType(s *Schema) string {
+	s = d.Resolve(s)
+	if s == nil {
+		return "string"
+	}
+	types, _ := s.Types()
+	if len(types) == 1 {
+		switch types[0] {
+		case "integer":
+			return "*int64"
+		case "boolean":
+			return "*bool"
+		}
+	}
+	return "string"
+}
+
+// successResponse returns the lowest 2xx response; every 2xx response of an
+// operation shares its body schema.
+func successResponse(op *Operation) *Response {
+	codes := make([]int, 0, len(op.Responses))
+	for code := range op.Responses {
+		n, err := strconv.Atoi(code)
+		if err == nil && n >= 200 && n < 300 {
+			codes = append(codes, n)
+		}
+	}
+	if len(codes) == 0 {
+		return nil
+	}
+	sort.Ints(codes)
+	return op.Responses[strconv.Itoa(codes[0])]
+}
+
+func methodConst(m string) string {
+	switch strings.ToUpper(m) {
+	case http.MethodGet:
+		return "http.MethodGet"
+	case http.MethodPost:
+		return "http.MethodPost"
+	case http.MethodPut:
+		return "http.MethodPut"
+	case http.MethodPatch:
+		return "http.MethodPatch"
+	case http.MethodDelete:
+		return "http.MethodDelete"
+	}
+	return strconv.Quote(strings.ToUpper(m))
+}
+
+var initialisms = map[string]string{
+	"id":     "ID",
+	"ids":    "IDs",
+	"url":    "URL",
+	"api":    "API",
+	"uuid":   "UUID",
+	"json":   "JSON",
+	"http":   "HTTP",
+	"pdf":    "PDF",
+	"gst":    "GST",
+	"ocr":    "OCR",
+	"ip":     "IP",
+	"fy":     "FY",
+	"mime":   "MIME",
+	"sha256": "SHA256",
+}
+
+// GoName converts snake_case or camelCase identifiers to exported Go names
+// with the usual initialisms.
+func GoName(s string) string {
+	var words []string
+	for _, part := range strings.FieldsFunc(s, func(r rune) bool { return r == '_' || r == '-' || r == '.' }) {
+		words = append(words, splitCamel(part)...)
+	}
+	var b strings.Builder
+	for _, w := range words {
+		lw := strings.ToLower(w)
+		if in, ok := initialisms[lw]; ok {
+			b.WriteString(in)
+			continue
+		}
+		b.WriteString(strings.ToUpper(lw[:1]) + lw[1:])
+	}
+	return b.String()
+}
+
+// splitCamel splits "getOpenAPISpec" into get, Open, API, Spec.
+func splitCamel(s string) []string {
+	isUpper := func(c byte) bool { return c >= 'A' && c <= 'Z' }
+	var words []string
+	start := 0
+	for i := 1; i < len(s); i++ {
+		if !isUpper(s[i]) {
+			continue
+		}
+		prevLower := !isUpper(s[i-1])
+		endOfRun := isUpper(s[i-1]) && i+1 < len(s) && !isUpper(s[i+1])
+		if prevLower || endOfRun {
+			words = append(words, s[start:i])
+			start = i
+		}
+	}
+	return append(words, s[start:])
+}
+
+func lowerFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	// keep leading initialisms readable: ID -> id, not iD
+	for _, in := range initialisms {
+		if strings.HasPrefix(s, in) && (len(s) == len(in) || s[len(in)] >= 'A' && s[len(in)] <= 'Z') {
+			return strings.ToLower(in) + s[len(in):]
+		}
+	}
+	return strings.ToLower(s[:1]) + s[1:]
+}
@@ -0,0 +1,72 @@
+package openapi
+
+import (
+	"strings"
+)
+
+// Match finds the operation serving method and path. Path templates use
+// {name} segments; a literal segment wins over a parameter at the same depth
+// so /transactions/batch is not mistaken for /transactions/{id}.
+func (d *Document) Match(method, path string) (*Operation, map[string]string) {
+	method = strings.ToLower(method)
+	segs := split(path)
+
+	var (
+		best       *Operation
+		bestParams map[string]string
+		bestScore  = -1
+	)
+	for tmpl, item := range d.Paths {
+		op, ok := item[method]
+		if !ok {
+			continue
+		}
+		params, score, ok := matchTemplate(split(tmpl), segs)
+		if ok && score > bestScore {
+			best, bestParams, bestScore = op, params, score
+		}
+	}
+	return best, bestParams
+}
+
+func matchTemplate(tmpl, segs []string) (map[string]string, int, bool) {
+	if len(tmpl) != len(segs) {
+		return nil, 0, false
+	}
+	params := make(map[string]string)
+	score := 0
+	for i, t := range tmpl {
+		if name, suffix, ok := param(t); ok {
+			if !strings.HasSuffix(segs[i], suffix) {
+				return nil, 0, false
+			}
+			params[name] = strings.TrimSuffix(segs[i], suffix)
+			continue
+		}
+		if t != segs[i] {
+			return nil, 0, false
+		}
+		score++
+	}
+	return params, score, true
+}
+
+// param parses a {name} segment, allowing a literal suffix such as {id}.pdf.
+func param(seg string) (name, suffix string, ok bool) {
+	if !strings.HasPrefix(seg, "{") {
+		return "", "", false
+	}
+	end := strings.Index(seg, "}")
+	if end < 0 {
+		return "", "", false
+	}
+	return seg[1:end], seg[end+1:], true
+}
+
+func split(path string) []string {
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return nil
+	}
+	return strings.Split(path, "/")
+}
@@ -0,0 +1,30 @@
+package openapi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchPrefersLiteralSegments(t *testing.T) {
+	doc := &Document{Paths: map[string]PathItem{
+		"/transactions/{id}":     {"post": {OperationID: "byID"}},
+		"/transactions/batch":    {"post": {OperationID: "batch"}},
+		"/invoices/{id}.pdf":     {"get": {OperationID: "pdf"}},
+		"/accounts/{id}/archive": {"post": {OperationID: "archive"}},
+	}}
+
+	op, _ := doc.Match("POST", "/transactions/batch")
+	require.Equal(t, "batch", op.OperationID)
+
+	op, params := doc.Match("POST", "/transactions/abc")
+	require.Equal(t, "byID", op.OperationID)
+	require.Equal(t, "abc", params["id"])
+
+	op, params = doc.Match("GET", "/invoices/abc.pdf")
+	require.Equal(t, "pdf", op.OperationID)
+	require.Equal(t, "abc", params["id"])
+
+	op, _ = doc.Match("GET", "/accounts/abc/archive")
+	require.Nil(t, op)
+}
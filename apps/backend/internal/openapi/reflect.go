@@ -0,0 +1,244 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Registry derives component schemas from Go types. Struct types become named
+// components referenced with $ref; everything else is inlined.
+//
+// Field rules:
+//   - the property name comes from the json tag; fields tagged "-" are skipped
+//   - a field is required unless it is a pointer, has omitempty, or is tagged
+//     openapi:"optional"
+//   - pointers are nullable
+//   - the openapi tag adds constraints: enum=a|b, format=uuid, minLength=1,
+//     maxLength=500, minItems=2, maxItems=100, minimum=0, maximum=10
+//   - the doc tag becomes the property description
+type Registry struct {
+	schemas map[string]*Schema
+	names   map[reflect.Type]string
+}
+
+func NewRegistry() *Registry {
+	return &Registry{
+		schemas: make(map[string]*Schema),
+		names:   make(map[reflect.Type]string),
+	}
+}
+
+// Schemas returns every component registered so far.
+func (r *Registry) Schemas() map[string]*Schema {
+	return r.schemas
+}
+
+// SchemaFor returns the schema of v's type.
+func (r *Registry) SchemaFor(v any) *Schema {
+	return r.SchemaOf(reflect.TypeOf(v))
+}
+
+var (
+	timeType = reflect.TypeOf(time.Time{})
+	rawType  = reflect.TypeOf(json.RawMessage{})
+)
+
+func (r *Registry) SchemaOf(t reflect.Type) *Schema {
+	switch {
+	case t == timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case t == rawType:
+		return &Schema{}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		s := r.SchemaOf(t.Elem())
+		return nullable(s)
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: r.SchemaOf(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: r.SchemaOf(t.Elem())}
+	case reflect.Interface:
+		return &Schema{}
+	case reflect.Struct:
+		return r.structRef(t)
+	}
+	panic(fmt.Sprintf("openapi: unsupported type %s", t))
+}
+
+func (r *Registry) structRef(t reflect.Type) *Schema {
+	name, ok := r.names[t]
+	if !ok {
+		name = ComponentName(t.Name())
+		if existing, taken := r.schemas[name]; taken && existing != nil {
+			panic(fmt.Sprintf("openapi: component name %q used by two types", name))
+		}
+		r.names[t] = name
+		r.schemas[name] = nil // reserve the name so recursive types terminate
+		r.schemas[name] = r.structSchema(t)
+	}
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
+
+func (r *Registry) structSchema(t reflect.Type) *Schema {
+	s := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	r.addFields(s, t)
+	return s
+}
+
+func (r *Registry) addFields(s *Schema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Anonymous && f.Type.Kind() == reflect.Struct {
+			r.addFields(s, f.Type)
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+
+		name, omitempty := jsonName(f)
+		if name == "-" {
+			continue
+		}
+
+		prop := r.SchemaOf(f.Type)
+		opts := parseTag(f.Tag.Get("openapi"))
+		if len(opts) > 0 || f.Tag.Get("doc") != "" {
+			// never decorate a shared $ref in place
+			if prop.Ref != "" {
+				prop = &Schema{Ref: prop.Ref}
+			}
+			applyTag(prop, opts)
+			prop.Description = f.Tag.Get("doc")
+		}
+		s.Properties[name] = prop
+
+		_, optional := opts["optional"]
+		if !omitempty && !optional && f.Type.Kind() != reflect.Pointer {
+			s.Required = append(s.Required, name)
+		}
+	}
+}
+
+func jsonName(f reflect.StructField) (string, bool) {
+	tag := f.Tag.Get("json")
+	if tag == "-" {
+		return "-", false
+	}
+	parts := strings.Split(tag, ",")
+	name := parts[0]
+	if name == "" {
+		name = f.Name
+	}
+	omitempty := false
+	for _, p := range parts[1:] {
+		if p == "omitempty" || p == "omitzero" {
+			omitempty = true
+		}
+	}
+	return name, omitempty
+}
+
+func parseTag(tag string) map[string]string {
+	opts := make(map[string]string)
+	if tag == "" {
+		return opts
+	}
+	for _, part := range strings.Split(tag, ",") {
+		k, v, _ := strings.Cut(strings.TrimSpace(part), "=")
+		opts[k] = v
+	}
+	return opts
+}
+
+func applyTag(s *Schema, opts map[string]string) {
+	// constraints on a list apply to the list; element constraints live on
+	// the element type
+	for k, v := range opts {
+		switch k {
+		case "enum":
+			target(s).Enum = strings.Split(v, "|")
+		case "format":
+			target(s).Format = v
+		case "minLength":
+			target(s).MinLength = intPtr(atoi(v))
+		case "maxLength":
+			target(s).MaxLength = intPtr(atoi(v))
+		case "minItems":
+			s.MinItems = intPtr(atoi(v))
+		case "maxItems":
+			s.MaxItems = intPtr(atoi(v))
+		case "minimum":
+			target(s).Minimum = int64Ptr(int64(atoi(v)))
+		case "maximum":
+			target(s).Maximum = int64Ptr(int64(atoi(v)))
+		case "optional", "required":
+		default:
+			panic(fmt.Sprintf("openapi: unknown tag option %q", k))
+		}
+	}
+}
+
+// target returns the schema scalar constraints apply to: the items of an
+// array of scalars, or the schema itself.
+func target(s *Schema) *Schema {
+	if s.Items != nil && s.Items.Ref == "" {
+		if types, _ := s.Types(); len(types) == 1 && types[0] == "array" {
+			return s.Items
+		}
+	}
+	return s
+}
+
+func atoi(v string) int {
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		panic(fmt.Sprintf("openapi: invalid number %q in tag", v))
+	}
+	return n
+}
+
+func nullable(s *Schema) *Schema {
+	if s.Ref != "" {
+		// 3.1 has no nullable $ref shorthand; the client treats refs as
+		// pointers anyway
+		return s
+	}
+	types, isNullable := s.Types()
+	if isNullable || len(types) != 1 {
+		return s
+	}
+	out := *s
+	out.Type = []string{types[0], "null"}
+	return &out
+}
+
+// ComponentName turns a Go type name into an exported component name.
+func ComponentName(goName string) string {
+	if goName == "" {
+		return goName
+	}
+	r := []rune(goName)
+	r[0] = unicode.ToUpper(r[0])
+	return string(r)
+}
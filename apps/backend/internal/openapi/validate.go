@@ -0,0 +1,219 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+// Violation kinds. They deliberately match the API error codes of the same
+// name so the HTTP layer can pass them through unchanged.
+const (
+	KindRequired      = "required"
+	KindInvalidType   = "invalid_type"
+	KindInvalidValue  = "invalid_value"
+	KindInvalidFormat = "invalid_format"
+	KindTooShort      = "too_short"
+	KindTooLong       = "too_long"
+	KindTooFew        = "too_few"
+	KindTooMany       = "too_many"
+	KindOutOfRange    = "out_of_range"
+)
+
+// Violation is one way a value failed its schema. Field is a path such as
+// entries[1].account_id.
+type Violation struct {
+	Field   string
+	Kind    string
+	Message string
+}
+
+// ValidateJSON decodes body and validates it against schema.
+func (d *Document) ValidateJSON(schema *Schema, body []byte) ([]Violation, error) {
+	var v any
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	var out []Violation
+	d.validate(schema, v, "", &out)
+	return out, nil
+}
+
+// ValidateParam validates a raw query or path parameter.
+func (d *Document) ValidateParam(p Parameter, raw string) []Violation {
+	var out []Violation
+	s := d.Resolve(p.Schema)
+	if s == nil {
+		return nil
+	}
+	types, _ := s.Types()
+	var v any = raw
+	if len(types) == 1 {
+		switch types[0] {
+		case "integer":
+			n, err := strconv.ParseInt(raw, 10, 64)
+			if err != nil {
+				out = append(out, Violation{p.Name, KindInvalidType, fmt.Sprintf("%s must be an integer", p.Name)})
+				return out
+			}
+			v = json.Number(strconv.FormatInt(n, 10))
+		case "boolean":
+			b, err := strconv.ParseBool(raw)
+			if err != nil {
+				out = append(out, Violation{p.Name, KindInvalidType, fmt.Sprintf("%s must be a boolean", p.Name)})
+				return out
+			}
+			v = b
+		}
+	}
+	d.validate(s, v, p.Name, &out)
+	return out
+}
+
+func (d *Document) validate(s *Schema, v any, path string, out *[]Violation) {
+	s = d.Resolve(s)
+	if s == nil {
+		return
+	}
+	types, nullable := s.Types()
+	if v == nil {
+		if !nullable && len(types) > 0 {
+			*out = append(*out, Violation{path, KindInvalidType, fmt.Sprintf("%s must not be null", label(path))})
+		}
+		return
+	}
+	if len(types) == 0 {
+		return // any value
+	}
+
+	switch types[0] {
+	case "object":
+		obj, ok := v.(map[string]any)
+		if !ok {
+			*out = append(*out, Violation{path, KindInvalidType, fmt.Sprintf("%s must be an object", label(path))})
+			return
+		}
+		for _, name := range s.Required {
+			if _, present := obj[name]; !present {
+				*out = append(*out, Violation{join(path, name), KindRequired, fmt.Sprintf("%s is required", name)})
+			}
+		}
+		keys := make([]string, 0, len(obj))
+		for k := range obj {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if prop, ok := s.Properties[k]; ok {
+				d.validate(prop, obj[k], join(path, k), out)
+			} else if s.AdditionalProperties != nil {
+				d.validate(s.AdditionalProperties, obj[k], join(path, k), out)
+			}
+		}
+	case "array":
+		arr, ok := v.([]any)
+		if !ok {
+			*out = append(*out, Violation{path, KindInvalidType, fmt.Sprintf("%s must be an array", label(path))})
+			return
+		}
+		if s.MinItems != nil && len(arr) < *s.MinItems {
+			*out = append(*out, Violation{path, KindTooFew, fmt.Sprintf("%s must have at least %d items", label(path), *s.MinItems)})
+		}
+		if s.MaxItems != nil && len(arr) > *s.MaxItems {
+			*out = append(*out, Violation{path, KindTooMany, fmt.Sprintf("%s must have at most %d items", label(path), *s.MaxItems)})
+			return
+		}
+		for i, item := range arr {
+			d.validate(s.Items, item, fmt.Sprintf("%s[%d]", path, i), out)
+		}
+	case "string":
+		str, ok := v.(string)
+		if !ok {
+			*out = append(*out, Violation{path, KindInvalidType, fmt.Sprintf("%s must be a string", label(path))})
+			return
+		}
+		validateString(s, str, path, out)
+	case "integer":
+		num, ok := v.(json.Number)
+		if !ok {
+			*out = append(*out, Violation{path, KindInvalidType, fmt.Sprintf("%s must be an integer", label(path))})
+			return
+		}
+		n, err := num.Int64()
+		if err != nil {
+			*out = append(*out, Violation{path, KindInvalidType, fmt.Sprintf("%s must be an integer", label(path))})
+			return
+		}
+		if (s.Minimum != nil && n < *s.Minimum) || (s.Maximum != nil && n > *s.Maximum) {
+			*out = append(*out, Violation{path, KindOutOfRange, fmt.Sprintf("%s is out of range", label(path))})
+		}
+	case "number":
+		if _, ok := v.(json.Number); !ok {
+			*out = append(*out, Violation{path, KindInvalidType, fmt.Sprintf("%s must be a number", label(path))})
+		}
+	case "boolean":
+		if _, ok := v.(bool); !ok {
+			*out = append(*out, Violation{path, KindInvalidType, fmt.Sprintf("%s must be a boolean", label(path))})
+		}
+	}
+}
+
+func validateString(s *Schema, str, path string, out *[]Violation) {
+	if len(s.Enum) > 0 {
+		found := false
+		for _, e := range s.Enum {
+			if e == str {
+				found = true
+				break
+			}
+		}
+		if !found {
+			*out = append(*out, Violation{path, KindInvalidValue, fmt.Sprintf("%s must be one of %v", label(path), s.Enum)})
+			return
+		}
+	}
+	n := utf8.RuneCountInString(str)
+	if s.MinLength != nil && n < *s.MinLength {
+		*out = append(*out, Violation{path, KindTooShort, fmt.Sprintf("%s too short", label(path))})
+	}
+	if s.MaxLength != nil && n > *s.MaxLength {
+		*out = append(*out, Violation{path, KindTooLong, fmt.Sprintf("%s too long", label(path))})
+	}
+	if str == "" {
+		return // emptiness is for minLength to judge, not the format
+	}
+	var err error
+	switch s.Format {
+	case "uuid":
+		_, err = uuid.Parse(str)
+	case "date-time":
+		_, err = time.Parse(time.RFC3339, str)
+	case "date":
+		_, err = time.Parse(time.DateOnly, str)
+	}
+	if err != nil {
+		*out = append(*out, Violation{path, KindInvalidFormat, fmt.Sprintf("%s is not a valid %s", label(path), s.Format)})
+	}
+}
+
+func join(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+func label(path string) string {
+	if path == "" {
+		return "body"
+	}
+	return path
+}
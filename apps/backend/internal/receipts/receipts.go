@@ -0,0 +1,282 @@
+// Package receipts pulls the merchant, date, total and GST out of the text
+// recognised on a photographed receipt. Every field carries a confidence
+// from 0 to 1: the OCR engine's confidence in the line it came from, scaled
+// by how sure the heuristic that picked it can be, so a UI can highlight the
+// values a person should check.
+package receipts
+
+import (
+	"context"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/LBaronceli/go-figure/internal/ocr"
+)
+
+// Field is an extracted value and the confidence in it.
+type Field[T any] struct {
+	Value      T
+	Confidence float64
+}
+
+// Extraction is what was read from one receipt. Fields not found are nil.
+type Extraction struct {
+	Merchant *Field[string]
+	Date     *Field[time.Time]
+	// Total and GST are in minor units.
+	Total *Field[int64]
+	GST   *Field[int64]
+	// Text is everything recognised, one line per line.
+	Text string
+}
+
+// maxMerchantLength caps the merchant name taken from a line.
+const maxMerchantLength = 200
+
+// Scan recognises data with engine and extracts the fields.
+func Scan(ctx context.Context, engine ocr.Engine, data []byte, contentType string) (Extraction, error) {
+	res, err := engine.Recognize(ctx, data, contentType)
+	if err != nil {
+		return Extraction{}, err
+	}
+	return Extract(res), nil
+}
+
+// Extract finds the fields in recognised text.
+func Extract(res ocr.Result) Extraction {
+	texts := make([]string, len(res.Lines))
+	for i, l := range res.Lines {
+		texts[i] = l.Text
+	}
+	ext := Extraction{
+		Merchant: merchant(res.Lines),
+		Date:     date(res.Lines),
+		Total:    total(res.Lines),
+		Text:     strings.Join(texts, "\n"),
+	}
+	ext.GST = gst(res.Lines, ext.Total)
+	return ext
+}
+
+var (
+	amountPattern = regexp.MustCompile(`(?:^|[^\d.,])(\d{1,3}(?:,\d{3})+|\d+)[.](\d{2})(?:$|[^\d])`)
+	letters       = regexp.MustCompile(`\p{L}`)
+)
+
+// amounts returns the decimal amounts on a line, in minor units, left to
+// right. Amounts need two decimal places so quantities and codes are not
+// mistaken for money.
+func amounts(s string) []int64 {
+	var out []int64
+	for _, m := range amountPattern.FindAllStringSubmatch(s, -1) {
+		whole, err := strconv.ParseInt(strings.ReplaceAll(m[1], ",", ""), 10, 64)
+		if err != nil || whole > math.MaxInt64/100-1 {
+			continue
+		}
+		cents, _ := strconv.ParseInt(m[2], 10, 64)
+		out = append(out, whole*100+cents)
+	}
+	return out
+}
+
+// totalLabels are the labels a receipt's total is printed against, with how
+// sure each makes us that the amount beside it is the total.
+var totalLabels = []struct {
+	label  string
+	weight float64
+}{
+	{"TOTAL", 1},
+	{"AMOUNT DUE", 0.9},
+	{"BALANCE DUE", 0.9},
+	{"EFTPOS", 0.7},
+	{"CREDIT CARD", 0.7},
+	{"VISA", 0.6},
+	{"MASTERCARD", 0.6},
+	{"PAID", 0.6},
+}
+
+// subtotalLabels look like totals but are not the amount paid.
+var subtotalLabels = []string{"SUBTOTAL", "SUB TOTAL", "SUB-TOTAL", "GST", "TAX", "TOTAL SAVINGS", "DISCOUNT"}
+
+// total takes the last amount on the line with the strongest total label,
+// preferring the larger amount on ties (a grand total over a running one).
+// Without a label it falls back to the largest amount on the receipt.
+func total(lines []ocr.Line) *Field[int64] {
+	var best *Field[int64]
+	for _, l := range lines {
+		upper := strings.ToUpper(l.Text)
+		if containsAny(upper, subtotalLabels) {
+			continue
+		}
+		found := amounts(l.Text)
+		if len(found) == 0 {
+			continue
+		}
+		for _, tl := range totalLabels {
+			if !strings.Contains(upper, tl.label) {
+				continue
+			}
+			f := &Field[int64]{Value: found[len(found)-1], Confidence: clamp(l.Confidence * tl.weight)}
+			if best == nil || f.Confidence > best.Confidence || (f.Confidence == best.Confidence && f.Value > best.Value) {
+				best = f
+			}
+			break
+		}
+	}
+	if best != nil {
+		return best
+	}
+	for _, l := range lines {
+		for _, a := range amounts(l.Text) {
+			if best == nil || a > best.Value {
+				best = &Field[int64]{Value: a, Confidence: clamp(l.Confidence * 0.4)}
+			}
+		}
+	}
+	return best
+}
+
+// gst takes the amount on a GST or tax line. NZ GST is 3/23 of a
+// GST-inclusive total; an amount that agrees with the total keeps the line's
+// confidence and one that does not is marked down.
+func gst(lines []ocr.Line, total *Field[int64]) *Field[int64] {
+	for _, l := range lines {
+		upper := strings.ToUpper(l.Text)
+		weight := 0.0
+		switch {
+		case strings.Contains(upper, "GST"):
+			weight = 0.9
+		case strings.Contains(upper, "TAX") && !strings.Contains(upper, "TAX INVOICE"):
+			weight = 0.7
+		default:
+			continue
+		}
+		found := amounts(l.Text)
+		if len(found) == 0 {
+			continue
+		}
+		amount := found[len(found)-1]
+		if total != nil {
+			if amount >= total.Value {
+				continue
+			}
+			expected := float64(total.Value) * 3 / 23
+			if math.Abs(float64(amount)-expected) <= 2 {
+				weight = 1
+			} else {
+				weight *= 0.6
+			}
+		}
+		return &Field[int64]{Value: amount, Confidence: clamp(l.Confidence * weight)}
+	}
+	return nil
+}
+
+var (
+	isoDate     = regexp.MustCompile(`\b(\d{4})-(\d{2})-(\d{2})\b`)
+	numericDate = regexp.MustCompile(`\b(\d{1,2})[/.-](\d{1,2})[/.-](\d{4}|\d{2})\b`)
+	namedDate   = regexp.MustCompile(`(?i)\b(\d{1,2})(?:st|nd|rd|th)?[\s-]*(jan|feb|mar|apr|may|jun|jul|aug|sep|oct|nov|dec)[a-z]*\.?[\s-]*(\d{4}|\d{2})\b`)
+	months      = []string{"jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec"}
+)
+
+// date takes the most certain date on the receipt. Numeric dates are read
+// day first, as receipts in New Zealand and Australia print them; one that
+// would also be valid month first is less certain.
+func date(lines []ocr.Line) *Field[time.Time] {
+	var best *Field[time.Time]
+	consider := func(d time.Time, ok bool, conf float64) {
+		if ok && (best == nil || conf > best.Confidence) {
+			best = &Field[time.Time]{Value: d, Confidence: clamp(conf)}
+		}
+	}
+	for _, l := range lines {
+		for _, m := range isoDate.FindAllStringSubmatch(l.Text, -1) {
+			d, ok := makeDate(m[1], m[2], m[3])
+			consider(d, ok, l.Confidence)
+		}
+		for _, m := range namedDate.FindAllStringSubmatch(l.Text, -1) {
+			month := 0
+			for i, name := range months {
+				if strings.EqualFold(m[2], name) {
+					month = i + 1
+				}
+			}
+			d, ok := makeDate(m[3], strconv.Itoa(month), m[1])
+			consider(d, ok, l.Confidence)
+		}
+		for _, m := range numericDate.FindAllStringSubmatch(l.Text, -1) {
+			d, ok := makeDate(m[3], m[2], m[1])
+			weight := 0.8
+			if day, _ := strconv.Atoi(m[1]); day <= 12 && m[1] != m[2] {
+				weight = 0.6
+			}
+			consider(d, ok, l.Confidence*weight)
+		}
+	}
+	return best
+}
+
+// makeDate builds a date from year, month and day strings, rejecting
+// impossible ones such as 31/02. Two-digit years are this century.
+func makeDate(year, month, day string) (time.Time, bool) {
+	y, err1 := strconv.Atoi(year)
+	m, err2 := strconv.Atoi(month)
+	d, err3 := strconv.Atoi(day)
+	if err1 != nil || err2 != nil || err3 != nil {
+		return time.Time{}, false
+	}
+	if len(year) == 2 {
+		y += 2000
+	}
+	t := time.Date(y, time.Month(m), d, 0, 0, 0, 0, time.UTC)
+	if t.Year() != y || int(t.Month()) != m || t.Day() != d || y < 2000 {
+		return time.Time{}, false
+	}
+	return t, true
+}
+
+// notMerchant marks header lines that are not the business's name.
+var notMerchant = []string{"TAX INVOICE", "RECEIPT", "GST", "WELCOME", "THANK", "INVOICE", "PHONE", "PH:", "TEL"}
+
+// merchant takes the first line near the top that reads like a name: mostly
+// letters, no amount and no boilerplate. The top line is the likeliest.
+func merchant(lines []ocr.Line) *Field[string] {
+	for i, l := range lines {
+		if i >= 5 {
+			break
+		}
+		text := strings.Join(strings.Fields(l.Text), " ")
+		text = strings.TrimFunc(text, func(r rune) bool { return unicode.IsPunct(r) || unicode.IsSpace(r) })
+		upper := strings.ToUpper(text)
+		if len(letters.FindAllString(text, -1)) < 3 || len(amounts(text)) > 0 || containsAny(upper, notMerchant) {
+			continue
+		}
+		if len([]rune(text)) > maxMerchantLength {
+			text = string([]rune(text)[:maxMerchantLength])
+		}
+		weight := 0.7
+		if i == 0 {
+			weight = 0.9
+		}
+		return &Field[string]{Value: text, Confidence: clamp(l.Confidence * weight)}
+	}
+	return nil
+}
+
+func containsAny(s string, subs []string) bool {
+	for _, sub := range subs {
+		if strings.Contains(s, sub) {
+			return true
+		}
+	}
+	return false
+}
+
+// clamp rounds a confidence to two places within [0, 1].
+func clamp(c float64) float64 {
+	return math.Round(min(max(c, 0), 1)*100) / 100
+}
@@ -0,0 +1,96 @@
+package receipts
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/LBaronceli/go-figure/internal/ocr"
+)
+
+func TestScanSupermarketReceipt(t *testing.T) {
+	engine := ocr.FakeLines(
+		"COUNTDOWN PONSONBY",
+		"TAX INVOICE  GST No 123-456-789",
+		"Date: 14/05/2026 17:42",
+		"MILK 2L            4.20",
+		"BREAD              3.50",
+		"SUBTOTAL          84.50",
+		"TOTAL            $84.50",
+		"GST INCLUDED      11.02",
+		"EFTPOS           $84.50",
+	)
+	ext, err := Scan(context.Background(), engine, []byte("jpeg"), "image/jpeg")
+	require.NoError(t, err)
+
+	require.Equal(t, "COUNTDOWN PONSONBY", ext.Merchant.Value)
+	require.Equal(t, 0.81, ext.Merchant.Confidence)
+	require.Equal(t, time.Date(2026, 5, 14, 0, 0, 0, 0, time.UTC), ext.Date.Value)
+	require.Equal(t, 0.72, ext.Date.Confidence)
+	require.Equal(t, int64(8450), ext.Total.Value)
+	require.Equal(t, 0.9, ext.Total.Confidence)
+	// 3/23 of 84.50 is 11.02, so the GST agrees with the total
+	require.Equal(t, int64(1102), ext.GST.Value)
+	require.Equal(t, 0.9, ext.GST.Confidence)
+	require.Contains(t, ext.Text, "EFTPOS")
+}
+
+func TestExtractLowConfidenceFallbacks(t *testing.T) {
+	ext := Extract(ocr.Result{Lines: []ocr.Line{
+		{Text: "12.00 6.50", Confidence: 0.5},
+		{Text: "Joe's Cafe", Confidence: 0.5},
+		{Text: "03/04/26", Confidence: 1},
+		{Text: "Tax 9.99", Confidence: 1},
+	}})
+
+	require.Equal(t, "Joe's Cafe", ext.Merchant.Value)
+	require.Equal(t, 0.35, ext.Merchant.Confidence)
+	// 03/04 could be read month first
+	require.Equal(t, time.Date(2026, 4, 3, 0, 0, 0, 0, time.UTC), ext.Date.Value)
+	require.Equal(t, 0.6, ext.Date.Confidence)
+	// no total label: the largest amount, flagged as a guess
+	require.Equal(t, int64(1200), ext.Total.Value)
+	require.Equal(t, 0.2, ext.Total.Confidence)
+	// far from 3/23 of the total
+	require.Equal(t, int64(999), ext.GST.Value)
+	require.Equal(t, 0.42, ext.GST.Confidence)
+}
+
+func TestExtractNothing(t *testing.T) {
+	ext := Extract(ocr.Result{Lines: []ocr.Line{{Text: "~~ ## ~~", Confidence: 0.2}}})
+	require.Nil(t, ext.Merchant)
+	require.Nil(t, ext.Date)
+	require.Nil(t, ext.Total)
+	require.Nil(t, ext.GST)
+}
+
+func TestDates(t *testing.T) {
+	tests := map[string]time.Time{
+		"2026-05-14 09:12":   time.Date(2026, 5, 14, 0, 0, 0, 0, time.UTC),
+		"14 May 2026":        time.Date(2026, 5, 14, 0, 0, 0, 0, time.UTC),
+		"Thu 2nd Jan 26":     time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC),
+		"31.12.2025":         time.Date(2025, 12, 31, 0, 0, 0, 0, time.UTC),
+		"ref 31/02/2026 bad": {},
+	}
+	for text, want := range tests {
+		got := date([]ocr.Line{{Text: text, Confidence: 1}})
+		if want.IsZero() {
+			require.Nil(t, got, text)
+			continue
+		}
+		require.NotNil(t, got, text)
+		require.Equal(t, want, got.Value, text)
+	}
+}
+
+func TestAmounts(t *testing.T) {
+	require.Equal(t, []int64{123456, 50}, amounts("1,234.56 x 0.50"))
+	require.Empty(t, amounts("qty 3 code 12345 1.5"))
+}
+
+func TestScanPassesEngineErrors(t *testing.T) {
+	_, err := Scan(context.Background(), &ocr.Fake{Err: ocr.ErrUnsupported}, nil, "application/pdf")
+	require.ErrorIs(t, err, ocr.ErrUnsupported)
+}
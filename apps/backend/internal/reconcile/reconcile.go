@@ -0,0 +1,145 @@
+// Package reconcile pairs bank statement lines with the ledger entries they
+// record. Matching is advisory: the matcher proposes pairs and a person
+// accepts or rejects them.
+package reconcile
+
+import (
+	"math"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/google/uuid"
+)
+
+// Line is an imported statement line waiting for a match.
+type Line struct {
+	ID          uuid.UUID
+	PostedOn    time.Time
+	Amount      int64
+	Description string
+	// Rejected lists entries a person has already turned down for this line.
+	Rejected []uuid.UUID
+}
+
+// Entry is an unmatched ledger entry on the account being reconciled.
+type Entry struct {
+	ID          uuid.UUID
+	PostedOn    time.Time
+	Amount      int64
+	Description string
+}
+
+// Options tune the matcher.
+type Options struct {
+	// DateWindow is how many days a line and an entry may be apart. Banks
+	// often settle a day or two after the books record a payment.
+	DateWindow int
+	// MinScore discards weaker candidates; 0 keeps every pair within the
+	// window.
+	MinScore float64
+}
+
+// DefaultOptions suit day-to-day bank feeds.
+var DefaultOptions = Options{DateWindow: 3, MinScore: 0}
+
+// Match is a proposed pairing. Score is in [0, 1], higher is better.
+type Match struct {
+	LineID  uuid.UUID
+	EntryID uuid.UUID
+	Score   float64
+}
+
+// AutoMatch proposes at most one entry per line and one line per entry.
+// Amounts must be equal and dates within the window; among those, closer
+// dates and more similar descriptions win. Ties break on IDs so the same
+// input always gives the same pairs.
+func AutoMatch(lines []Line, entries []Entry, opt Options) []Match {
+	var candidates []Match
+	for _, l := range lines {
+		for _, e := range entries {
+			if l.Amount != e.Amount || slices.Contains(l.Rejected, e.ID) {
+				continue
+			}
+			days := math.Abs(l.PostedOn.Sub(e.PostedOn).Hours() / 24)
+			if days > float64(opt.DateWindow) {
+				continue
+			}
+			closeness := 1 - days/float64(opt.DateWindow+1)
+			score := (closeness + Similarity(l.Description, e.Description)) / 2
+			if score < opt.MinScore {
+				continue
+			}
+			candidates = append(candidates, Match{LineID: l.ID, EntryID: e.ID, Score: score})
+		}
+	}
+
+	slices.SortFunc(candidates, func(a, b Match) int {
+		if a.Score != b.Score {
+			if a.Score > b.Score {
+				return -1
+			}
+			return 1
+		}
+		if c := strings.Compare(a.LineID.String(), b.LineID.String()); c != 0 {
+			return c
+		}
+		return strings.Compare(a.EntryID.String(), b.EntryID.String())
+	})
+
+	usedLines := make(map[uuid.UUID]bool)
+	usedEntries := make(map[uuid.UUID]bool)
+	var out []Match
+	for _, c := range candidates {
+		if usedLines[c.LineID] || usedEntries[c.EntryID] {
+			continue
+		}
+		usedLines[c.LineID] = true
+		usedEntries[c.EntryID] = true
+		out = append(out, c)
+	}
+	return out
+}
+
+// Similarity is the Dice coefficient of the two descriptions' character
+// trigrams, ignoring case and punctuation. Bank descriptions are noisy
+// ("POS 4421 COUNTDOWN AKL" against "Countdown groceries"), so shared
+// fragments count for more than exact words.
+func Similarity(a, b string) float64 {
+	ta, tb := trigrams(a), trigrams(b)
+	if len(ta) == 0 || len(tb) == 0 {
+		return 0
+	}
+	shared := 0
+	for g := range ta {
+		if tb[g] {
+			shared++
+		}
+	}
+	return 2 * float64(shared) / float64(len(ta)+len(tb))
+}
+
+func trigrams(s string) map[string]bool {
+	var b strings.Builder
+	space := true
+	for _, r := range strings.ToLower(s) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+			space = false
+		} else if !space {
+			b.WriteRune(' ')
+			space = true
+		}
+	}
+	words := strings.Fields(b.String())
+
+	out := make(map[string]bool)
+	for _, w := range words {
+		padded := []rune("  " + w + " ")
+		for i := 0; i+3 <= len(padded); i++ {
+			out[string(padded[i:i+3])] = true
+		}
+	}
+	return out
+}
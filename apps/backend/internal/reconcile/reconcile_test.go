@@ -0,0 +1,64 @@
+package reconcile
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func day(d int) time.Time {
+	return time.Date(2026, time.March, d, 0, 0, 0, 0, time.UTC)
+}
+
+func TestAutoMatchPairsOnAmountDateAndDescription(t *testing.T) {
+	groceries := Entry{ID: uuid.New(), PostedOn: day(10), Amount: -8450, Description: "Countdown groceries"}
+	fuel := Entry{ID: uuid.New(), PostedOn: day(11), Amount: -8450, Description: "Z Energy fuel"}
+	rent := Entry{ID: uuid.New(), PostedOn: day(1), Amount: -250000, Description: "Rent"}
+
+	lineGroceries := Line{ID: uuid.New(), PostedOn: day(12), Amount: -8450, Description: "POS 4421 COUNTDOWN AKL"}
+	lineFuel := Line{ID: uuid.New(), PostedOn: day(11), Amount: -8450, Description: "Z ENERGY PONSONBY"}
+	lineUnknown := Line{ID: uuid.New(), PostedOn: day(20), Amount: -1999, Description: "NETFLIX"}
+
+	matches := AutoMatch(
+		[]Line{lineGroceries, lineFuel, lineUnknown},
+		[]Entry{groceries, fuel, rent},
+		DefaultOptions,
+	)
+
+	got := make(map[uuid.UUID]uuid.UUID)
+	for _, m := range matches {
+		got[m.LineID] = m.EntryID
+	}
+	require.Len(t, got, 2)
+	// same amounts on both: the descriptions decide
+	require.Equal(t, groceries.ID, got[lineGroceries.ID])
+	require.Equal(t, fuel.ID, got[lineFuel.ID])
+}
+
+func TestAutoMatchRespectsWindowAndRejections(t *testing.T) {
+	entry := Entry{ID: uuid.New(), PostedOn: day(1), Amount: 100, Description: "Interest"}
+
+	tooLate := Line{ID: uuid.New(), PostedOn: day(5), Amount: 100, Description: "Interest"}
+	require.Empty(t, AutoMatch([]Line{tooLate}, []Entry{entry}, DefaultOptions))
+
+	rejected := Line{ID: uuid.New(), PostedOn: day(1), Amount: 100, Description: "Interest", Rejected: []uuid.UUID{entry.ID}}
+	require.Empty(t, AutoMatch([]Line{rejected}, []Entry{entry}, DefaultOptions))
+}
+
+func TestAutoMatchIsOneToOne(t *testing.T) {
+	entry := Entry{ID: uuid.New(), PostedOn: day(3), Amount: 500, Description: "Transfer"}
+	a := Line{ID: uuid.New(), PostedOn: day(3), Amount: 500, Description: "Transfer"}
+	b := Line{ID: uuid.New(), PostedOn: day(4), Amount: 500, Description: "Transfer"}
+
+	matches := AutoMatch([]Line{b, a}, []Entry{entry}, DefaultOptions)
+	require.Len(t, matches, 1)
+	require.Equal(t, a.ID, matches[0].LineID)
+}
+
+func TestSimilarity(t *testing.T) {
+	require.Equal(t, 1.0, Similarity("Countdown", "COUNTDOWN!"))
+	require.Zero(t, Similarity("", "anything"))
+	require.Greater(t, Similarity("POS COUNTDOWN AKL", "Countdown groceries"), Similarity("POS COUNTDOWN AKL", "Z Energy"))
+}
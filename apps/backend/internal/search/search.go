@@ -0,0 +1,166 @@
+// Package search parses the transaction search language: free text, matched
+// against descriptions by the database, mixed with field terms such as
+// amount:>100 or tag:travel that narrow the results exactly.
+package search
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Op compares a ledger entry amount with an amount term.
+type Op string
+
+const (
+	OpEq  Op = "="
+	OpGt  Op = ">"
+	OpGte Op = ">="
+	OpLt  Op = "<"
+	OpLte Op = "<="
+)
+
+// Amount is one amount:<op><value> term, in minor units.
+type Amount struct {
+	Op    Op
+	Minor int64
+}
+
+// Query is a parsed search.
+type Query struct {
+	// Text is the free text with field terms removed, in websearch syntax:
+	// quoted phrases, OR and -negation pass through unchanged.
+	Text string
+	// Amounts must all hold for the same ledger entry of a transaction.
+	Amounts []Amount
+	// Tags must all be present on the transaction's entries.
+	Tags []string
+}
+
+// Error reports a field term that did not parse.
+type Error struct {
+	Term   string
+	Reason string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s: %s", e.Term, e.Reason)
+}
+
+// Parse splits q into free text and field terms. Amounts are written in major
+// units with at most two decimal places, signed like ledger entries: -45.50
+// is a 45.50 credit. Terms with an unknown field name are kept as text.
+func Parse(q string) (Query, error) {
+	var out Query
+	var text []string
+	for _, term := range terms(q) {
+		field, value, ok := strings.Cut(term, ":")
+		switch {
+		case ok && strings.EqualFold(field, "amount"):
+			a, err := parseAmount(value)
+			if err != nil {
+				return Query{}, &Error{Term: term, Reason: err.Error()}
+			}
+			out.Amounts = append(out.Amounts, a)
+		case ok && strings.EqualFold(field, "tag"):
+			tag := strings.ToLower(strings.Trim(value, `"`))
+			if tag == "" {
+				return Query{}, &Error{Term: term, Reason: "tag is empty"}
+			}
+			out.Tags = append(out.Tags, tag)
+		default:
+			text = append(text, term)
+		}
+	}
+	out.Text = strings.Join(text, " ")
+	return out, nil
+}
+
+// terms splits q on whitespace, keeping double-quoted phrases whole.
+func terms(q string) []string {
+	var out []string
+	var cur strings.Builder
+	quoted := false
+	for _, r := range q {
+		switch {
+		case r == '"':
+			quoted = !quoted
+			cur.WriteRune(r)
+		case !quoted && (r == ' ' || r == '\t' || r == '\n'):
+			if cur.Len() > 0 {
+				out = append(out, cur.String())
+				cur.Reset()
+			}
+		default:
+			cur.WriteRune(r)
+		}
+	}
+	if cur.Len() > 0 {
+		out = append(out, cur.String())
+	}
+	return out
+}
+
+func parseAmount(s string) (Amount, error) {
+	a := Amount{Op: OpEq}
+	for _, op := range []Op{OpGte, OpLte, OpGt, OpLt, OpEq} {
+		if rest, ok := strings.CutPrefix(s, string(op)); ok {
+			a.Op, s = op, rest
+			break
+		}
+	}
+
+	minor, err := parseMinor(s)
+	if err != nil {
+		return Amount{}, err
+	}
+	a.Minor = minor
+	return a, nil
+}
+
+// parseMinor converts a decimal amount in major units to minor units.
+func parseMinor(s string) (int64, error) {
+	if s == "" {
+		return 0, fmt.Errorf("amount is empty")
+	}
+	neg := false
+	if rest, ok := strings.CutPrefix(s, "-"); ok {
+		neg, s = true, rest
+	} else if rest, ok := strings.CutPrefix(s, "+"); ok {
+		s = rest
+	}
+
+	whole, frac, hasFrac := strings.Cut(s, ".")
+	if whole == "" && frac == "" || hasFrac && frac == "" {
+		return 0, fmt.Errorf("%q is not an amount", s)
+	}
+	if len(frac) > 2 {
+		return 0, fmt.Errorf("amount has more than 2 decimal places")
+	}
+	for _, part := range []string{whole, frac} {
+		if strings.TrimLeft(part, "0123456789") != "" {
+			return 0, fmt.Errorf("%q is not an amount", s)
+		}
+	}
+
+	var units, cents int64
+	if whole != "" {
+		var err error
+		// at most 2 decimal digits follow, so the whole part must leave room
+		if units, err = strconv.ParseInt(whole, 10, 64); err != nil || units > (1<<63-1)/100-1 {
+			return 0, fmt.Errorf("amount is too large")
+		}
+	}
+	if frac != "" {
+		cents, _ = strconv.ParseInt(frac, 10, 64)
+		if len(frac) == 1 {
+			cents *= 10
+		}
+	}
+
+	minor := units*100 + cents
+	if neg {
+		minor = -minor
+	}
+	return minor, nil
+}
@@ -0,0 +1,46 @@
+package search
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSeparatesTextAndTerms(t *testing.T) {
+	q, err := Parse(`bunnings "garden supplies" amount:>100 tag:Home amount:<=250.5 note:x`)
+	require.NoError(t, err)
+	require.Equal(t, `bunnings "garden supplies" note:x`, q.Text)
+	require.Equal(t, []Amount{{Op: OpGt, Minor: 10000}, {Op: OpLte, Minor: 25050}}, q.Amounts)
+	require.Equal(t, []string{"home"}, q.Tags)
+}
+
+func TestParseAmounts(t *testing.T) {
+	cases := map[string]Amount{
+		"amount:-45.50": {Op: OpEq, Minor: -4550},
+		"amount:=12":    {Op: OpEq, Minor: 1200},
+		"amount:>=0.5":  {Op: OpGte, Minor: 50},
+		"amount:<-.99":  {Op: OpLt, Minor: -99},
+		"AMOUNT:+3.07":  {Op: OpEq, Minor: 307},
+	}
+	for in, want := range cases {
+		q, err := Parse(in)
+		require.NoError(t, err, in)
+		require.Equal(t, []Amount{want}, q.Amounts, in)
+		require.Empty(t, q.Text, in)
+	}
+}
+
+func TestParseRejectsBadAmounts(t *testing.T) {
+	for _, in := range []string{"amount:", "amount:>", "amount:1.234", "amount:12a", "amount:1.", "amount:99999999999999999999", "tag:"} {
+		_, err := Parse(in)
+		var perr *Error
+		require.ErrorAs(t, err, &perr, in)
+	}
+}
+
+func TestParseKeepsQuotedPhrasesWhole(t *testing.T) {
+	q, err := Parse(`  "amount:5 items"   -refund `)
+	require.NoError(t, err)
+	require.Equal(t, `"amount:5 items" -refund`, q.Text)
+	require.Empty(t, q.Amounts)
+}
@@ -0,0 +1,39 @@
+// Package storage keeps uploaded files such as receipts outside the
+// database. Files are content-addressed: the key ends in the hex SHA-256 of
+// the bytes, so the same file uploaded twice is stored once and a key
+// always names the same content.
+package storage
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+)
+
+// ErrNotFound is returned by Get for a key with nothing stored under it.
+var ErrNotFound = errors.New("storage: not found")
+
+// Store is a blob store. Implementations must be safe for concurrent use.
+type Store interface {
+	// Put stores data under key. Storing the same key again replaces the
+	// content, which for content-addressed keys is the same bytes.
+	Put(ctx context.Context, key string, data []byte, contentType string) error
+	// Get opens the content stored under key, or returns ErrNotFound.
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete removes key. Deleting a missing key is not an error.
+	Delete(ctx context.Context, key string) error
+}
+
+// ContentKey is the key data is stored under within namespace, such as an
+// organisation ID, along with its SHA-256.
+func ContentKey(namespace string, data []byte) (string, [sha256.Size]byte) {
+	sum := sha256.Sum256(data)
+	return Key(namespace, sum[:]), sum
+}
+
+// Key is the key for content with the given SHA-256 within namespace.
+func Key(namespace string, sum []byte) string {
+	return namespace + "/" + hex.EncodeToString(sum)
+}
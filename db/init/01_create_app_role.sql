@@ -0,0 +1,4 @@
+-- Runs once, when the compose database is first created. The API logs in as
+-- gofigure_app, which row-level security applies to; migrations run as the
+-- gofigure owner and grant gofigure_app its privileges.
+CREATE ROLE gofigure_app LOGIN PASSWORD 'gofigure_app' NOSUPERUSER NOBYPASSRLS;
@@ -0,0 +1,8 @@
+-- +goose Up
+ALTER TABLE accounts ADD COLUMN archived_at TIMESTAMPTZ;
+
+CREATE INDEX idx_accounts_archived_at ON accounts (archived_at);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_accounts_archived_at;
+ALTER TABLE accounts DROP COLUMN IF EXISTS archived_at;
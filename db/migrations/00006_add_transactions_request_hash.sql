@@ -0,0 +1,16 @@
+-- +goose Up
+-- request_hash lets a replayed idempotency key be checked against the original
+-- payload. Keys are released (set to NULL) once their retention window passes,
+-- so the column becomes nullable; the unique constraint ignores NULLs.
+ALTER TABLE transactions ADD COLUMN request_hash BYTEA;
+ALTER TABLE transactions ALTER COLUMN idempotency_key DROP NOT NULL;
+
+CREATE INDEX idx_transactions_idempotency_created_at
+  ON transactions (created_at)
+  WHERE idempotency_key IS NOT NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_transactions_idempotency_created_at;
+UPDATE transactions SET idempotency_key = id::text WHERE idempotency_key IS NULL;
+ALTER TABLE transactions ALTER COLUMN idempotency_key SET NOT NULL;
+ALTER TABLE transactions DROP COLUMN IF EXISTS request_hash;
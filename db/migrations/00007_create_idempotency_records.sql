@@ -0,0 +1,27 @@
+-- +goose Up
+-- Responses recorded by the Idempotency-Key middleware. The row is inserted,
+-- and therefore locked, for as long as the first request runs, so concurrent
+-- duplicates block on it and then replay the stored response.
+CREATE TABLE idempotency_records (
+  scope TEXT NOT NULL DEFAULT '',
+  key TEXT NOT NULL,
+
+  method TEXT NOT NULL,
+  path TEXT NOT NULL,
+  request_hash BYTEA NOT NULL,
+
+  status_code INT,
+  response_headers JSONB,
+  response_body BYTEA,
+
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  completed_at TIMESTAMPTZ,
+
+  CONSTRAINT idempotency_records_pkey PRIMARY KEY (scope, key)
+);
+
+CREATE INDEX idx_idempotency_records_created_at ON idempotency_records (created_at);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_idempotency_records_created_at;
+DROP TABLE IF EXISTS idempotency_records;
@@ -0,0 +1,187 @@
+-- +goose Up
+CREATE TABLE organisations (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  name TEXT NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- +goose StatementBegin
+CREATE TRIGGER organisations_set_updated_at
+BEFORE UPDATE ON organisations
+FOR EACH ROW
+EXECUTE FUNCTION set_updated_at();
+-- +goose StatementEnd
+
+CREATE TABLE memberships (
+  organisation_id UUID NOT NULL,
+  user_id UUID NOT NULL,
+  role TEXT NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+
+  CONSTRAINT memberships_pkey PRIMARY KEY (organisation_id, user_id),
+  CONSTRAINT memberships_organisation_fk
+    FOREIGN KEY (organisation_id) REFERENCES organisations(id) ON DELETE CASCADE,
+  CONSTRAINT memberships_user_fk
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+  CONSTRAINT memberships_role_check CHECK (role IN ('owner', 'member', 'viewer'))
+);
+
+CREATE INDEX idx_memberships_user_id ON memberships (user_id);
+
+-- Everything that exists today becomes the books of a single default
+-- organisation, owned by every existing user.
+INSERT INTO organisations (name)
+SELECT 'Default'
+WHERE EXISTS (SELECT 1 FROM accounts)
+   OR EXISTS (SELECT 1 FROM transactions)
+   OR EXISTS (SELECT 1 FROM users);
+
+INSERT INTO memberships (organisation_id, user_id, role)
+SELECT o.id, u.id, 'owner'
+FROM organisations o
+CROSS JOIN users u;
+
+ALTER TABLE accounts ADD COLUMN organisation_id UUID;
+ALTER TABLE transactions ADD COLUMN organisation_id UUID;
+ALTER TABLE ledger_entries ADD COLUMN organisation_id UUID;
+
+UPDATE accounts SET organisation_id = (SELECT id FROM organisations);
+UPDATE transactions SET organisation_id = (SELECT id FROM organisations);
+UPDATE ledger_entries SET organisation_id = (SELECT id FROM organisations);
+
+ALTER TABLE accounts ALTER COLUMN organisation_id SET NOT NULL;
+ALTER TABLE transactions ALTER COLUMN organisation_id SET NOT NULL;
+ALTER TABLE ledger_entries ALTER COLUMN organisation_id SET NOT NULL;
+
+ALTER TABLE accounts
+  ADD CONSTRAINT accounts_organisation_fk
+    FOREIGN KEY (organisation_id) REFERENCES organisations(id),
+  ADD CONSTRAINT accounts_organisation_id_id_unique UNIQUE (organisation_id, id);
+
+ALTER TABLE transactions
+  ADD CONSTRAINT transactions_organisation_fk
+    FOREIGN KEY (organisation_id) REFERENCES organisations(id),
+  ADD CONSTRAINT transactions_organisation_id_id_unique UNIQUE (organisation_id, id);
+
+-- Idempotency keys are per organisation.
+ALTER TABLE transactions
+  DROP CONSTRAINT transactions_idempotency_key_unique,
+  ADD CONSTRAINT transactions_idempotency_key_unique UNIQUE (organisation_id, idempotency_key);
+
+-- Entries reference their transaction and account through the organisation,
+-- so an entry can never point at another organisation's account.
+ALTER TABLE ledger_entries
+  DROP CONSTRAINT ledger_entries_transaction_fk,
+  DROP CONSTRAINT ledger_entries_account_fk,
+  ADD CONSTRAINT ledger_entries_transaction_fk
+    FOREIGN KEY (organisation_id, transaction_id) REFERENCES transactions(organisation_id, id),
+  ADD CONSTRAINT ledger_entries_account_fk
+    FOREIGN KEY (organisation_id, account_id) REFERENCES accounts(organisation_id, id);
+
+CREATE OR REPLACE VIEW account_balances WITH (security_invoker = true) AS
+SELECT
+  a.id         AS account_id,
+  a.name       AS account_name,
+  a.type       AS account_type,
+  a.currency   AS account_currency,
+  COALESCE(SUM(le.amount_minor), 0) AS balance_minor,
+  a.organisation_id AS organisation_id
+FROM accounts a
+LEFT JOIN ledger_entries le
+  ON le.organisation_id = a.organisation_id
+  AND le.account_id = a.id
+GROUP BY
+  a.id, a.name, a.type, a.currency, a.organisation_id;
+
+-- Row-level security is the second line of defence behind the
+-- organisation_id filter in every query. The API sets app.organisation_id on
+-- the connection serving a request; maintenance jobs that legitimately span
+-- organisations set app.rls_bypass = 'on' inside their transaction, and so
+-- must data migrations (SET LOCAL app.rls_bypass = 'on').
+-- +goose StatementBegin
+CREATE FUNCTION app_current_organisation() RETURNS UUID
+LANGUAGE sql STABLE AS $$
+  SELECT NULLIF(current_setting('app.organisation_id', true), '')::uuid
+$$;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE FUNCTION app_rls_bypass() RETURNS BOOLEAN
+LANGUAGE sql STABLE AS $$
+  SELECT COALESCE(current_setting('app.rls_bypass', true), '') = 'on'
+$$;
+-- +goose StatementEnd
+
+ALTER TABLE accounts ENABLE ROW LEVEL SECURITY;
+ALTER TABLE accounts FORCE ROW LEVEL SECURITY;
+CREATE POLICY accounts_organisation_isolation ON accounts
+  USING (app_rls_bypass() OR organisation_id = app_current_organisation())
+  WITH CHECK (app_rls_bypass() OR organisation_id = app_current_organisation());
+
+ALTER TABLE transactions ENABLE ROW LEVEL SECURITY;
+ALTER TABLE transactions FORCE ROW LEVEL SECURITY;
+CREATE POLICY transactions_organisation_isolation ON transactions
+  USING (app_rls_bypass() OR organisation_id = app_current_organisation())
+  WITH CHECK (app_rls_bypass() OR organisation_id = app_current_organisation());
+
+ALTER TABLE ledger_entries ENABLE ROW LEVEL SECURITY;
+ALTER TABLE ledger_entries FORCE ROW LEVEL SECURITY;
+CREATE POLICY ledger_entries_organisation_isolation ON ledger_entries
+  USING (app_rls_bypass() OR organisation_id = app_current_organisation())
+  WITH CHECK (app_rls_bypass() OR organisation_id = app_current_organisation());
+
+-- +goose Down
+DROP POLICY IF EXISTS ledger_entries_organisation_isolation ON ledger_entries;
+ALTER TABLE ledger_entries NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE ledger_entries DISABLE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS transactions_organisation_isolation ON transactions;
+ALTER TABLE transactions NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE transactions DISABLE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS accounts_organisation_isolation ON accounts;
+ALTER TABLE accounts NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE accounts DISABLE ROW LEVEL SECURITY;
+DROP FUNCTION IF EXISTS app_rls_bypass();
+DROP FUNCTION IF EXISTS app_current_organisation();
+
+DROP VIEW IF EXISTS account_balances;
+CREATE VIEW account_balances AS
+SELECT
+  a.id         AS account_id,
+  a.name       AS account_name,
+  a.type       AS account_type,
+  a.currency   AS account_currency,
+  COALESCE(SUM(le.amount_minor), 0) AS balance_minor
+FROM accounts a
+LEFT JOIN ledger_entries le
+  ON le.account_id = a.id
+GROUP BY
+  a.id, a.name, a.type, a.currency;
+
+ALTER TABLE ledger_entries
+  DROP CONSTRAINT ledger_entries_transaction_fk,
+  DROP CONSTRAINT ledger_entries_account_fk,
+  ADD CONSTRAINT ledger_entries_transaction_fk
+    FOREIGN KEY (transaction_id) REFERENCES transactions(id),
+  ADD CONSTRAINT ledger_entries_account_fk
+    FOREIGN KEY (account_id) REFERENCES accounts(id);
+
+ALTER TABLE transactions
+  DROP CONSTRAINT transactions_idempotency_key_unique,
+  ADD CONSTRAINT transactions_idempotency_key_unique UNIQUE (idempotency_key);
+
+ALTER TABLE transactions
+  DROP CONSTRAINT transactions_organisation_id_id_unique,
+  DROP CONSTRAINT transactions_organisation_fk;
+ALTER TABLE accounts
+  DROP CONSTRAINT accounts_organisation_id_id_unique,
+  DROP CONSTRAINT accounts_organisation_fk;
+
+ALTER TABLE ledger_entries DROP COLUMN IF EXISTS organisation_id;
+ALTER TABLE transactions DROP COLUMN IF EXISTS organisation_id;
+ALTER TABLE accounts DROP COLUMN IF EXISTS organisation_id;
+
+DROP INDEX IF EXISTS idx_memberships_user_id;
+DROP TABLE IF EXISTS memberships;
+DROP TRIGGER IF EXISTS organisations_set_updated_at ON organisations;
+DROP TABLE IF EXISTS organisations;
@@ -0,0 +1,63 @@
+-- +goose Up
+-- Append-only audit trail. Each organisation's events form a hash chain
+-- (see internal/audit): hash covers prev_hash and the event's fields, so any
+-- edit, deletion or reordering is detectable by recomputing the chain.
+CREATE TABLE audit_events (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  organisation_id UUID NOT NULL,
+  seq BIGINT NOT NULL,
+
+  -- actors are snapshotted: users may be deleted, their history may not
+  actor_user_id UUID NOT NULL,
+  actor_email TEXT NOT NULL,
+  actor_method TEXT NOT NULL,
+  request_id TEXT NOT NULL DEFAULT '',
+
+  entity TEXT NOT NULL,
+  entity_id UUID NOT NULL,
+  action TEXT NOT NULL,
+  before JSONB,
+  after JSONB,
+
+  created_at TIMESTAMPTZ NOT NULL,
+  prev_hash BYTEA,
+  hash BYTEA NOT NULL,
+
+  CONSTRAINT audit_events_organisation_fk
+    FOREIGN KEY (organisation_id) REFERENCES organisations(id),
+  CONSTRAINT audit_events_organisation_seq_unique UNIQUE (organisation_id, seq)
+);
+
+CREATE INDEX idx_audit_events_entity ON audit_events (organisation_id, entity, entity_id);
+
+-- +goose StatementBegin
+CREATE FUNCTION audit_events_append_only() RETURNS TRIGGER AS $$
+BEGIN
+  RAISE EXCEPTION 'audit_events is append-only';
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER audit_events_no_update
+BEFORE UPDATE OR DELETE ON audit_events
+FOR EACH ROW
+EXECUTE FUNCTION audit_events_append_only();
+
+CREATE TRIGGER audit_events_no_truncate
+BEFORE TRUNCATE ON audit_events
+FOR EACH STATEMENT
+EXECUTE FUNCTION audit_events_append_only();
+
+ALTER TABLE audit_events ENABLE ROW LEVEL SECURITY;
+ALTER TABLE audit_events FORCE ROW LEVEL SECURITY;
+CREATE POLICY audit_events_organisation_isolation ON audit_events
+  USING (app_rls_bypass() OR organisation_id = app_current_organisation())
+  WITH CHECK (app_rls_bypass() OR organisation_id = app_current_organisation());
+
+-- +goose Down
+DROP POLICY IF EXISTS audit_events_organisation_isolation ON audit_events;
+DROP TRIGGER IF EXISTS audit_events_no_truncate ON audit_events;
+DROP TRIGGER IF EXISTS audit_events_no_update ON audit_events;
+DROP FUNCTION IF EXISTS audit_events_append_only();
+DROP INDEX IF EXISTS idx_audit_events_entity;
+DROP TABLE IF EXISTS audit_events;
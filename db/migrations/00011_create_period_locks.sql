@@ -0,0 +1,42 @@
+-- +goose Up
+-- A locked period rejects postings dated inside it: once a GST return is
+-- filed or a year is closed, its figures must not move. Periods are half-open,
+-- [starts_at, ends_at).
+CREATE TABLE period_locks (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  organisation_id UUID NOT NULL,
+
+  starts_at TIMESTAMPTZ NOT NULL,
+  ends_at TIMESTAMPTZ NOT NULL,
+  reason TEXT NOT NULL,
+
+  locked_by UUID NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+
+  CONSTRAINT period_locks_organisation_fk
+    FOREIGN KEY (organisation_id) REFERENCES organisations(id),
+  CONSTRAINT period_locks_range_check CHECK (starts_at < ends_at),
+  CONSTRAINT period_locks_reason_check CHECK (reason IN ('gst_return', 'year_end', 'manual'))
+);
+
+CREATE INDEX idx_period_locks_organisation_id ON period_locks (organisation_id, starts_at);
+
+ALTER TABLE period_locks ENABLE ROW LEVEL SECURITY;
+ALTER TABLE period_locks FORCE ROW LEVEL SECURITY;
+CREATE POLICY period_locks_organisation_isolation ON period_locks
+  USING (app_rls_bypass() OR organisation_id = app_current_organisation())
+  WITH CHECK (app_rls_bypass() OR organisation_id = app_current_organisation());
+
+-- Closing entries are posted by the year-end close, never by clients.
+ALTER TABLE transactions
+  DROP CONSTRAINT transactions_source_check,
+  ADD CONSTRAINT transactions_source_check CHECK (source IN ('manual', 'csv', 'api', 'closing'));
+
+-- +goose Down
+ALTER TABLE transactions
+  DROP CONSTRAINT transactions_source_check,
+  ADD CONSTRAINT transactions_source_check CHECK (source IN ('manual', 'csv', 'api'));
+
+DROP POLICY IF EXISTS period_locks_organisation_isolation ON period_locks;
+DROP INDEX IF EXISTS idx_period_locks_organisation_id;
+DROP TABLE IF EXISTS period_locks;
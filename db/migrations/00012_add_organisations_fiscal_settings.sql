@@ -0,0 +1,15 @@
+-- +goose Up
+-- Reporting periods (FY2026-Q3, 2026-05) are computed in the organisation's
+-- timezone, with financial years starting on the first of
+-- fiscal_year_start_month (4 for New Zealand, 7 for Australia).
+ALTER TABLE organisations
+  ADD COLUMN timezone TEXT NOT NULL DEFAULT 'UTC',
+  ADD COLUMN fiscal_year_start_month SMALLINT NOT NULL DEFAULT 1,
+  ADD CONSTRAINT organisations_fiscal_year_start_month_check
+    CHECK (fiscal_year_start_month BETWEEN 1 AND 12);
+
+-- +goose Down
+ALTER TABLE organisations
+  DROP CONSTRAINT IF EXISTS organisations_fiscal_year_start_month_check,
+  DROP COLUMN IF EXISTS fiscal_year_start_month,
+  DROP COLUMN IF EXISTS timezone;
@@ -0,0 +1,65 @@
+-- +goose Up
+-- posted_on is the accounting date: the day the transaction belongs to in the
+-- organisation's books. posted_at stays as the capture timestamp. Existing
+-- rows take the date posted_at fell on in the organisation's timezone.
+SET LOCAL app.rls_bypass = 'on';
+
+ALTER TABLE transactions ADD COLUMN posted_on DATE;
+
+UPDATE transactions t
+SET posted_on = (COALESCE(t.posted_at, t.created_at) AT TIME ZONE o.timezone)::date
+FROM organisations o
+WHERE o.id = t.organisation_id;
+
+ALTER TABLE transactions ALTER COLUMN posted_on SET NOT NULL;
+
+CREATE INDEX idx_transactions_organisation_posted_on ON transactions (organisation_id, posted_on);
+
+-- Period locks cover whole accounting days, inclusive at both ends.
+ALTER TABLE period_locks
+  ADD COLUMN starts_on DATE,
+  ADD COLUMN ends_on DATE;
+
+UPDATE period_locks l
+SET starts_on = (l.starts_at AT TIME ZONE o.timezone)::date,
+    ends_on = ((l.ends_at AT TIME ZONE o.timezone) - interval '1 microsecond')::date
+FROM organisations o
+WHERE o.id = l.organisation_id;
+
+DROP INDEX IF EXISTS idx_period_locks_organisation_id;
+ALTER TABLE period_locks
+  DROP CONSTRAINT period_locks_range_check,
+  DROP COLUMN starts_at,
+  DROP COLUMN ends_at,
+  ALTER COLUMN starts_on SET NOT NULL,
+  ALTER COLUMN ends_on SET NOT NULL,
+  ADD CONSTRAINT period_locks_range_check CHECK (starts_on <= ends_on);
+
+CREATE INDEX idx_period_locks_organisation_id ON period_locks (organisation_id, starts_on);
+
+-- +goose Down
+SET LOCAL app.rls_bypass = 'on';
+
+ALTER TABLE period_locks
+  ADD COLUMN starts_at TIMESTAMPTZ,
+  ADD COLUMN ends_at TIMESTAMPTZ;
+
+UPDATE period_locks l
+SET starts_at = l.starts_on::timestamp AT TIME ZONE o.timezone,
+    ends_at = (l.ends_on + 1)::timestamp AT TIME ZONE o.timezone
+FROM organisations o
+WHERE o.id = l.organisation_id;
+
+DROP INDEX IF EXISTS idx_period_locks_organisation_id;
+ALTER TABLE period_locks
+  DROP CONSTRAINT period_locks_range_check,
+  DROP COLUMN starts_on,
+  DROP COLUMN ends_on,
+  ALTER COLUMN starts_at SET NOT NULL,
+  ALTER COLUMN ends_at SET NOT NULL,
+  ADD CONSTRAINT period_locks_range_check CHECK (starts_at < ends_at);
+
+CREATE INDEX idx_period_locks_organisation_id ON period_locks (organisation_id, starts_at);
+
+DROP INDEX IF EXISTS idx_transactions_organisation_posted_on;
+ALTER TABLE transactions DROP COLUMN IF EXISTS posted_on;
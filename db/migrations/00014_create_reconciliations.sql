@@ -0,0 +1,82 @@
+-- +goose Up
+-- A reconciliation checks one account against one bank statement. Statement
+-- lines are kept apart from the ledger: they are evidence, not postings, and
+-- only become linked to the ledger through a match.
+CREATE TABLE reconciliations (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  organisation_id UUID NOT NULL,
+  account_id UUID NOT NULL,
+
+  starts_on DATE NOT NULL,
+  ends_on DATE NOT NULL,
+  closing_balance_minor BIGINT NOT NULL,
+
+  status TEXT NOT NULL DEFAULT 'open',
+  created_by UUID NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  completed_at TIMESTAMPTZ,
+
+  CONSTRAINT reconciliations_organisation_id_id_unique UNIQUE (organisation_id, id),
+  CONSTRAINT reconciliations_account_fk
+    FOREIGN KEY (organisation_id, account_id) REFERENCES accounts(organisation_id, id),
+  CONSTRAINT reconciliations_range_check CHECK (starts_on <= ends_on),
+  CONSTRAINT reconciliations_status_check CHECK (status IN ('open', 'completed'))
+);
+
+CREATE INDEX idx_reconciliations_account ON reconciliations (organisation_id, account_id);
+
+-- match_status moves unmatched -> suggested (by the auto-matcher) ->
+-- matched (accepted), or straight to matched when done by hand. Rejecting a
+-- suggestion remembers the entry so it is not proposed again.
+CREATE TABLE statement_lines (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  organisation_id UUID NOT NULL,
+  reconciliation_id UUID NOT NULL,
+
+  posted_on DATE NOT NULL,
+  amount_minor BIGINT NOT NULL,
+  description TEXT NOT NULL DEFAULT '',
+  reference TEXT NOT NULL DEFAULT '',
+
+  match_status TEXT NOT NULL DEFAULT 'unmatched',
+  ledger_entry_id UUID,
+  match_score DOUBLE PRECISION,
+  rejected_entry_ids UUID[] NOT NULL DEFAULT '{}',
+
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+
+  CONSTRAINT statement_lines_reconciliation_fk
+    FOREIGN KEY (organisation_id, reconciliation_id) REFERENCES reconciliations(organisation_id, id) ON DELETE CASCADE,
+  CONSTRAINT statement_lines_ledger_entry_fk
+    FOREIGN KEY (ledger_entry_id) REFERENCES ledger_entries(id),
+  CONSTRAINT statement_lines_match_status_check
+    CHECK (match_status IN ('unmatched', 'suggested', 'matched')),
+  CONSTRAINT statement_lines_match_check
+    CHECK ((match_status = 'unmatched') = (ledger_entry_id IS NULL))
+);
+
+CREATE INDEX idx_statement_lines_reconciliation ON statement_lines (organisation_id, reconciliation_id, posted_on);
+-- an entry clears against at most one statement line
+CREATE UNIQUE INDEX idx_statement_lines_ledger_entry ON statement_lines (ledger_entry_id)
+  WHERE ledger_entry_id IS NOT NULL;
+
+ALTER TABLE reconciliations ENABLE ROW LEVEL SECURITY;
+ALTER TABLE reconciliations FORCE ROW LEVEL SECURITY;
+CREATE POLICY reconciliations_organisation_isolation ON reconciliations
+  USING (app_rls_bypass() OR organisation_id = app_current_organisation())
+  WITH CHECK (app_rls_bypass() OR organisation_id = app_current_organisation());
+
+ALTER TABLE statement_lines ENABLE ROW LEVEL SECURITY;
+ALTER TABLE statement_lines FORCE ROW LEVEL SECURITY;
+CREATE POLICY statement_lines_organisation_isolation ON statement_lines
+  USING (app_rls_bypass() OR organisation_id = app_current_organisation())
+  WITH CHECK (app_rls_bypass() OR organisation_id = app_current_organisation());
+
+-- +goose Down
+DROP POLICY IF EXISTS statement_lines_organisation_isolation ON statement_lines;
+DROP POLICY IF EXISTS reconciliations_organisation_isolation ON reconciliations;
+DROP INDEX IF EXISTS idx_statement_lines_ledger_entry;
+DROP INDEX IF EXISTS idx_statement_lines_reconciliation;
+DROP TABLE IF EXISTS statement_lines;
+DROP INDEX IF EXISTS idx_reconciliations_account;
+DROP TABLE IF EXISTS reconciliations;
@@ -0,0 +1,19 @@
+-- +goose Up
+-- A correcting transaction, such as a split, reverses one ledger entry of an
+-- earlier transaction and reposts the amount elsewhere. It points at the
+-- entry it corrects, and each entry is corrected at most once: a later change
+-- corrects the correcting transaction instead of reversing the original twice.
+ALTER TABLE transactions
+  ADD COLUMN corrects_entry_id UUID,
+  ADD CONSTRAINT transactions_corrects_entry_fk
+    FOREIGN KEY (corrects_entry_id) REFERENCES ledger_entries(id);
+
+CREATE UNIQUE INDEX transactions_corrects_entry_id_unique
+  ON transactions (corrects_entry_id)
+  WHERE corrects_entry_id IS NOT NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS transactions_corrects_entry_id_unique;
+ALTER TABLE transactions
+  DROP CONSTRAINT IF EXISTS transactions_corrects_entry_fk,
+  DROP COLUMN IF EXISTS corrects_entry_id;
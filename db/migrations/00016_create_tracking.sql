@@ -0,0 +1,109 @@
+-- +goose Up
+-- Tracking categories are organisation-defined dimensions (project, client,
+-- vehicle) with a fixed list of options. A ledger entry takes at most one
+-- option per category. Tags are free-form labels on ledger entries.
+CREATE TABLE tracking_categories (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  organisation_id UUID NOT NULL,
+  name TEXT NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+
+  CONSTRAINT tracking_categories_organisation_fk
+    FOREIGN KEY (organisation_id) REFERENCES organisations(id),
+  CONSTRAINT tracking_categories_organisation_id_id_unique UNIQUE (organisation_id, id),
+  CONSTRAINT tracking_categories_name_unique UNIQUE (organisation_id, name)
+);
+
+CREATE TRIGGER tracking_categories_set_updated_at
+BEFORE UPDATE ON tracking_categories
+FOR EACH ROW
+EXECUTE FUNCTION set_updated_at();
+
+-- Options in use are deactivated rather than deleted: old entries keep
+-- them, new entries cannot use them.
+CREATE TABLE tracking_options (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  organisation_id UUID NOT NULL,
+  category_id UUID NOT NULL,
+  name TEXT NOT NULL,
+  active BOOLEAN NOT NULL DEFAULT true,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+
+  CONSTRAINT tracking_options_category_id_id_unique UNIQUE (organisation_id, category_id, id),
+  CONSTRAINT tracking_options_name_unique UNIQUE (category_id, name),
+  CONSTRAINT tracking_options_category_fk
+    FOREIGN KEY (organisation_id, category_id) REFERENCES tracking_categories(organisation_id, id) ON DELETE CASCADE
+);
+
+CREATE TRIGGER tracking_options_set_updated_at
+BEFORE UPDATE ON tracking_options
+FOR EACH ROW
+EXECUTE FUNCTION set_updated_at();
+
+-- The composite key ties each option to the category it is filed under, so
+-- an entry cannot claim "Vehicle: Project Alpha".
+CREATE TABLE ledger_entry_tracking (
+  organisation_id UUID NOT NULL,
+  ledger_entry_id UUID NOT NULL,
+  category_id UUID NOT NULL,
+  option_id UUID NOT NULL,
+
+  PRIMARY KEY (ledger_entry_id, category_id),
+  CONSTRAINT ledger_entry_tracking_entry_fk
+    FOREIGN KEY (ledger_entry_id) REFERENCES ledger_entries(id),
+  CONSTRAINT ledger_entry_tracking_option_fk
+    FOREIGN KEY (organisation_id, category_id, option_id)
+    REFERENCES tracking_options(organisation_id, category_id, id)
+);
+
+CREATE INDEX idx_ledger_entry_tracking_option ON ledger_entry_tracking (organisation_id, option_id);
+
+CREATE TABLE ledger_entry_tags (
+  organisation_id UUID NOT NULL,
+  ledger_entry_id UUID NOT NULL,
+  tag TEXT NOT NULL,
+
+  PRIMARY KEY (ledger_entry_id, tag),
+  CONSTRAINT ledger_entry_tags_entry_fk
+    FOREIGN KEY (ledger_entry_id) REFERENCES ledger_entries(id)
+);
+
+CREATE INDEX idx_ledger_entry_tags_tag ON ledger_entry_tags (organisation_id, tag);
+
+ALTER TABLE tracking_categories ENABLE ROW LEVEL SECURITY;
+ALTER TABLE tracking_categories FORCE ROW LEVEL SECURITY;
+CREATE POLICY tracking_categories_organisation_isolation ON tracking_categories
+  USING (app_rls_bypass() OR organisation_id = app_current_organisation())
+  WITH CHECK (app_rls_bypass() OR organisation_id = app_current_organisation());
+
+ALTER TABLE tracking_options ENABLE ROW LEVEL SECURITY;
+ALTER TABLE tracking_options FORCE ROW LEVEL SECURITY;
+CREATE POLICY tracking_options_organisation_isolation ON tracking_options
+  USING (app_rls_bypass() OR organisation_id = app_current_organisation())
+  WITH CHECK (app_rls_bypass() OR organisation_id = app_current_organisation());
+
+ALTER TABLE ledger_entry_tracking ENABLE ROW LEVEL SECURITY;
+ALTER TABLE ledger_entry_tracking FORCE ROW LEVEL SECURITY;
+CREATE POLICY ledger_entry_tracking_organisation_isolation ON ledger_entry_tracking
+  USING (app_rls_bypass() OR organisation_id = app_current_organisation())
+  WITH CHECK (app_rls_bypass() OR organisation_id = app_current_organisation());
+
+ALTER TABLE ledger_entry_tags ENABLE ROW LEVEL SECURITY;
+ALTER TABLE ledger_entry_tags FORCE ROW LEVEL SECURITY;
+CREATE POLICY ledger_entry_tags_organisation_isolation ON ledger_entry_tags
+  USING (app_rls_bypass() OR organisation_id = app_current_organisation())
+  WITH CHECK (app_rls_bypass() OR organisation_id = app_current_organisation());
+
+-- +goose Down
+DROP POLICY IF EXISTS ledger_entry_tags_organisation_isolation ON ledger_entry_tags;
+DROP POLICY IF EXISTS ledger_entry_tracking_organisation_isolation ON ledger_entry_tracking;
+DROP POLICY IF EXISTS tracking_options_organisation_isolation ON tracking_options;
+DROP POLICY IF EXISTS tracking_categories_organisation_isolation ON tracking_categories;
+DROP TABLE IF EXISTS ledger_entry_tags;
+DROP TABLE IF EXISTS ledger_entry_tracking;
+DROP TRIGGER IF EXISTS tracking_options_set_updated_at ON tracking_options;
+DROP TABLE IF EXISTS tracking_options;
+DROP TRIGGER IF EXISTS tracking_categories_set_updated_at ON tracking_categories;
+DROP TABLE IF EXISTS tracking_categories;
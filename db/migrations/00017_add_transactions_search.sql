@@ -0,0 +1,15 @@
+-- +goose Up
+-- Transaction search ranks descriptions by full-text match and falls back on
+-- trigram similarity for misspellings ("bunings"). Both indexes are on
+-- expressions the search query repeats verbatim, so the planner can use them.
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX idx_transactions_description_tsv ON transactions
+  USING GIN (to_tsvector('english', COALESCE(description, '')));
+
+CREATE INDEX idx_transactions_description_trgm ON transactions
+  USING GIN (COALESCE(description, '') gin_trgm_ops);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_transactions_description_trgm;
+DROP INDEX IF EXISTS idx_transactions_description_tsv;
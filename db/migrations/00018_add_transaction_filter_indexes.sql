@@ -0,0 +1,18 @@
+-- +goose Up
+-- Indexes behind the GET /transactions filters and sort orders. Description
+-- filters use idx_transactions_description_trgm, tag filters the
+-- ledger_entry_tags primary key.
+CREATE INDEX idx_transactions_organisation_created_at ON transactions (organisation_id, created_at);
+CREATE INDEX idx_transactions_organisation_posted_at ON transactions (organisation_id, posted_at);
+CREATE INDEX idx_transactions_organisation_source ON transactions (organisation_id, source, posted_on);
+
+-- Covers the per-transaction amount lookup the amount filters and sort need,
+-- without visiting the heap.
+CREATE INDEX idx_ledger_entries_transaction_account ON ledger_entries (transaction_id, account_id)
+  INCLUDE (amount_minor);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_ledger_entries_transaction_account;
+DROP INDEX IF EXISTS idx_transactions_organisation_source;
+DROP INDEX IF EXISTS idx_transactions_organisation_posted_at;
+DROP INDEX IF EXISTS idx_transactions_organisation_created_at;
@@ -0,0 +1,75 @@
+-- +goose Up
+-- Contacts are the people and businesses money moves to and from: payees,
+-- customers and suppliers. Aliases map the noisy descriptions banks produce
+-- ("POS 1234 COUNTDOWN AKL") to the contact behind them.
+CREATE TABLE contacts (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  organisation_id UUID NOT NULL,
+  name TEXT NOT NULL,
+  email TEXT,
+  -- suggested account for transactions with this contact
+  default_account_id UUID,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+
+  CONSTRAINT contacts_organisation_fk
+    FOREIGN KEY (organisation_id) REFERENCES organisations(id),
+  CONSTRAINT contacts_organisation_id_id_unique UNIQUE (organisation_id, id),
+  CONSTRAINT contacts_name_unique UNIQUE (organisation_id, name),
+  CONSTRAINT contacts_default_account_fk
+    FOREIGN KEY (organisation_id, default_account_id) REFERENCES accounts(organisation_id, id)
+    ON DELETE SET NULL (default_account_id)
+);
+
+CREATE TRIGGER contacts_set_updated_at
+BEFORE UPDATE ON contacts
+FOR EACH ROW
+EXECUTE FUNCTION set_updated_at();
+
+-- Patterns match anywhere in a description, case-insensitively, with *
+-- standing for any run of characters; the most specific matching pattern
+-- wins. A pattern belongs to one contact so it never maps two ways.
+CREATE TABLE contact_aliases (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  organisation_id UUID NOT NULL,
+  contact_id UUID NOT NULL,
+  pattern TEXT NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+
+  CONSTRAINT contact_aliases_pattern_unique UNIQUE (organisation_id, pattern),
+  CONSTRAINT contact_aliases_contact_fk
+    FOREIGN KEY (organisation_id, contact_id) REFERENCES contacts(organisation_id, id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_contact_aliases_contact ON contact_aliases (organisation_id, contact_id);
+
+ALTER TABLE transactions
+  ADD COLUMN contact_id UUID,
+  ADD CONSTRAINT transactions_contact_fk
+    FOREIGN KEY (organisation_id, contact_id) REFERENCES contacts(organisation_id, id);
+
+CREATE INDEX idx_transactions_contact ON transactions (organisation_id, contact_id)
+  WHERE contact_id IS NOT NULL;
+
+ALTER TABLE contacts ENABLE ROW LEVEL SECURITY;
+ALTER TABLE contacts FORCE ROW LEVEL SECURITY;
+CREATE POLICY contacts_organisation_isolation ON contacts
+  USING (app_rls_bypass() OR organisation_id = app_current_organisation())
+  WITH CHECK (app_rls_bypass() OR organisation_id = app_current_organisation());
+
+ALTER TABLE contact_aliases ENABLE ROW LEVEL SECURITY;
+ALTER TABLE contact_aliases FORCE ROW LEVEL SECURITY;
+CREATE POLICY contact_aliases_organisation_isolation ON contact_aliases
+  USING (app_rls_bypass() OR organisation_id = app_current_organisation())
+  WITH CHECK (app_rls_bypass() OR organisation_id = app_current_organisation());
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_transactions_contact;
+ALTER TABLE transactions
+  DROP CONSTRAINT IF EXISTS transactions_contact_fk,
+  DROP COLUMN IF EXISTS contact_id;
+DROP POLICY IF EXISTS contact_aliases_organisation_isolation ON contact_aliases;
+DROP POLICY IF EXISTS contacts_organisation_isolation ON contacts;
+DROP TABLE IF EXISTS contact_aliases;
+DROP TRIGGER IF EXISTS contacts_set_updated_at ON contacts;
+DROP TABLE IF EXISTS contacts;
@@ -0,0 +1,175 @@
+-- +goose Up
+-- Tax codes name a rate and the liability account the tax collected on
+-- invoices is credited to. A zero rate (exempt, zero-rated) needs no account.
+CREATE TABLE tax_codes (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  organisation_id UUID NOT NULL,
+  name TEXT NOT NULL,
+  -- 1500 is 15%
+  rate_basis_points INTEGER NOT NULL,
+  account_id UUID,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+
+  CONSTRAINT tax_codes_organisation_fk
+    FOREIGN KEY (organisation_id) REFERENCES organisations(id),
+  CONSTRAINT tax_codes_organisation_id_id_unique UNIQUE (organisation_id, id),
+  CONSTRAINT tax_codes_name_unique UNIQUE (organisation_id, name),
+  CONSTRAINT tax_codes_account_fk
+    FOREIGN KEY (organisation_id, account_id) REFERENCES accounts(organisation_id, id),
+  CONSTRAINT tax_codes_rate_check CHECK (rate_basis_points BETWEEN 0 AND 10000),
+  CONSTRAINT tax_codes_account_check CHECK (rate_basis_points = 0 OR account_id IS NOT NULL)
+);
+
+CREATE TRIGGER tax_codes_set_updated_at
+BEFORE UPDATE ON tax_codes
+FOR EACH ROW
+EXECUTE FUNCTION set_updated_at();
+
+-- An invoice moves draft -> sent (approved: posted to receivables) -> paid
+-- once payments cover its total. Drafts and unpaid sent invoices can be
+-- voided; voiding a sent invoice posts a reversal. Amounts are kept on the
+-- row as computed when the lines were last saved, so approval posts exactly
+-- what the customer was shown.
+CREATE TABLE invoices (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  organisation_id UUID NOT NULL,
+  contact_id UUID NOT NULL,
+  number TEXT NOT NULL,
+  reference TEXT NOT NULL DEFAULT '',
+  currency CHAR(3) NOT NULL,
+  issue_on DATE NOT NULL,
+  due_on DATE NOT NULL,
+  status TEXT NOT NULL DEFAULT 'draft',
+  receivable_account_id UUID NOT NULL,
+
+  subtotal_minor BIGINT NOT NULL,
+  tax_minor BIGINT NOT NULL,
+  total_minor BIGINT NOT NULL,
+  paid_minor BIGINT NOT NULL DEFAULT 0,
+
+  -- posted on approval and on voiding a sent invoice
+  transaction_id UUID,
+  void_transaction_id UUID,
+
+  sent_at TIMESTAMPTZ,
+  paid_on DATE,
+  voided_on DATE,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+
+  CONSTRAINT invoices_organisation_id_id_unique UNIQUE (organisation_id, id),
+  CONSTRAINT invoices_number_unique UNIQUE (organisation_id, number),
+  CONSTRAINT invoices_contact_fk
+    FOREIGN KEY (organisation_id, contact_id) REFERENCES contacts(organisation_id, id),
+  CONSTRAINT invoices_receivable_account_fk
+    FOREIGN KEY (organisation_id, receivable_account_id) REFERENCES accounts(organisation_id, id),
+  CONSTRAINT invoices_transaction_fk
+    FOREIGN KEY (organisation_id, transaction_id) REFERENCES transactions(organisation_id, id),
+  CONSTRAINT invoices_void_transaction_fk
+    FOREIGN KEY (organisation_id, void_transaction_id) REFERENCES transactions(organisation_id, id),
+  CONSTRAINT invoices_status_check CHECK (status IN ('draft', 'sent', 'paid', 'void')),
+  CONSTRAINT invoices_dates_check CHECK (issue_on <= due_on),
+  CONSTRAINT invoices_paid_check CHECK (paid_minor BETWEEN 0 AND total_minor)
+);
+
+CREATE INDEX idx_invoices_contact ON invoices (organisation_id, contact_id);
+CREATE INDEX idx_invoices_status ON invoices (organisation_id, status, due_on);
+
+CREATE TRIGGER invoices_set_updated_at
+BEFORE UPDATE ON invoices
+FOR EACH ROW
+EXECUTE FUNCTION set_updated_at();
+
+-- Quantities are stored in thousandths so 1.5 hours is exact.
+CREATE TABLE invoice_lines (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  organisation_id UUID NOT NULL,
+  invoice_id UUID NOT NULL,
+  position INTEGER NOT NULL,
+  description TEXT NOT NULL DEFAULT '',
+  quantity_thousandths BIGINT NOT NULL,
+  unit_price_minor BIGINT NOT NULL,
+  account_id UUID NOT NULL,
+  tax_code_id UUID,
+  amount_minor BIGINT NOT NULL,
+  tax_minor BIGINT NOT NULL,
+
+  CONSTRAINT invoice_lines_position_unique UNIQUE (invoice_id, position),
+  CONSTRAINT invoice_lines_invoice_fk
+    FOREIGN KEY (organisation_id, invoice_id) REFERENCES invoices(organisation_id, id) ON DELETE CASCADE,
+  CONSTRAINT invoice_lines_account_fk
+    FOREIGN KEY (organisation_id, account_id) REFERENCES accounts(organisation_id, id),
+  CONSTRAINT invoice_lines_tax_code_fk
+    FOREIGN KEY (organisation_id, tax_code_id) REFERENCES tax_codes(organisation_id, id),
+  CONSTRAINT invoice_lines_quantity_check CHECK (quantity_thousandths > 0)
+);
+
+CREATE INDEX idx_invoice_lines_tax_code ON invoice_lines (organisation_id, tax_code_id)
+  WHERE tax_code_id IS NOT NULL;
+
+CREATE TABLE invoice_payments (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  organisation_id UUID NOT NULL,
+  invoice_id UUID NOT NULL,
+  transaction_id UUID NOT NULL,
+  account_id UUID NOT NULL,
+  paid_on DATE NOT NULL,
+  amount_minor BIGINT NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+
+  CONSTRAINT invoice_payments_invoice_fk
+    FOREIGN KEY (organisation_id, invoice_id) REFERENCES invoices(organisation_id, id),
+  CONSTRAINT invoice_payments_transaction_fk
+    FOREIGN KEY (organisation_id, transaction_id) REFERENCES transactions(organisation_id, id),
+  CONSTRAINT invoice_payments_account_fk
+    FOREIGN KEY (organisation_id, account_id) REFERENCES accounts(organisation_id, id),
+  CONSTRAINT invoice_payments_amount_check CHECK (amount_minor > 0)
+);
+
+CREATE INDEX idx_invoice_payments_invoice ON invoice_payments (organisation_id, invoice_id, paid_on);
+
+-- invoice postings are their own source so they can be told apart from
+-- hand-entered transactions
+ALTER TABLE transactions
+  DROP CONSTRAINT transactions_source_check,
+  ADD CONSTRAINT transactions_source_check CHECK (source IN ('manual', 'csv', 'api', 'closing', 'invoice'));
+
+ALTER TABLE tax_codes ENABLE ROW LEVEL SECURITY;
+ALTER TABLE tax_codes FORCE ROW LEVEL SECURITY;
+CREATE POLICY tax_codes_organisation_isolation ON tax_codes
+  USING (app_rls_bypass() OR organisation_id = app_current_organisation())
+  WITH CHECK (app_rls_bypass() OR organisation_id = app_current_organisation());
+
+ALTER TABLE invoices ENABLE ROW LEVEL SECURITY;
+ALTER TABLE invoices FORCE ROW LEVEL SECURITY;
+CREATE POLICY invoices_organisation_isolation ON invoices
+  USING (app_rls_bypass() OR organisation_id = app_current_organisation())
+  WITH CHECK (app_rls_bypass() OR organisation_id = app_current_organisation());
+
+ALTER TABLE invoice_lines ENABLE ROW LEVEL SECURITY;
+ALTER TABLE invoice_lines FORCE ROW LEVEL SECURITY;
+CREATE POLICY invoice_lines_organisation_isolation ON invoice_lines
+  USING (app_rls_bypass() OR organisation_id = app_current_organisation())
+  WITH CHECK (app_rls_bypass() OR organisation_id = app_current_organisation());
+
+ALTER TABLE invoice_payments ENABLE ROW LEVEL SECURITY;
+ALTER TABLE invoice_payments FORCE ROW LEVEL SECURITY;
+CREATE POLICY invoice_payments_organisation_isolation ON invoice_payments
+  USING (app_rls_bypass() OR organisation_id = app_current_organisation())
+  WITH CHECK (app_rls_bypass() OR organisation_id = app_current_organisation());
+
+-- +goose Down
+ALTER TABLE transactions
+  DROP CONSTRAINT transactions_source_check,
+  ADD CONSTRAINT transactions_source_check CHECK (source IN ('manual', 'csv', 'api', 'closing'));
+DROP POLICY IF EXISTS invoice_payments_organisation_isolation ON invoice_payments;
+DROP POLICY IF EXISTS invoice_lines_organisation_isolation ON invoice_lines;
+DROP POLICY IF EXISTS invoices_organisation_isolation ON invoices;
+DROP POLICY IF EXISTS tax_codes_organisation_isolation ON tax_codes;
+DROP TABLE IF EXISTS invoice_payments;
+DROP TABLE IF EXISTS invoice_lines;
+DROP TRIGGER IF EXISTS invoices_set_updated_at ON invoices;
+DROP TABLE IF EXISTS invoices;
+DROP TRIGGER IF EXISTS tax_codes_set_updated_at ON tax_codes;
+DROP TABLE IF EXISTS tax_codes;
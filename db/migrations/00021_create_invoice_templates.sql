@@ -0,0 +1,40 @@
+-- +goose Up
+-- One row per organisation with what its invoice PDFs show besides the
+-- invoice itself. payment_instructions and footer are Go text/template
+-- sources rendered against the invoice. Without a row the PDF uses the
+-- organisation's name and nothing else.
+CREATE TABLE invoice_templates (
+  organisation_id UUID PRIMARY KEY,
+  trading_name TEXT NOT NULL DEFAULT '',
+  address TEXT NOT NULL DEFAULT '',
+  tax_number TEXT NOT NULL DEFAULT '',
+  email TEXT NOT NULL DEFAULT '',
+  accent_color TEXT NOT NULL DEFAULT '#1f4e79',
+  payment_instructions TEXT NOT NULL DEFAULT '',
+  footer TEXT NOT NULL DEFAULT '',
+  logo BYTEA,
+  logo_type TEXT,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+
+  CONSTRAINT invoice_templates_organisation_fk
+    FOREIGN KEY (organisation_id) REFERENCES organisations(id),
+  CONSTRAINT invoice_templates_accent_color_check CHECK (accent_color ~ '^#[0-9a-f]{6}$'),
+  CONSTRAINT invoice_templates_logo_type_check CHECK (logo_type IN ('image/png', 'image/jpeg')),
+  CONSTRAINT invoice_templates_logo_check CHECK ((logo IS NULL) = (logo_type IS NULL))
+);
+
+CREATE TRIGGER invoice_templates_set_updated_at
+BEFORE UPDATE ON invoice_templates
+FOR EACH ROW
+EXECUTE FUNCTION set_updated_at();
+
+ALTER TABLE invoice_templates ENABLE ROW LEVEL SECURITY;
+ALTER TABLE invoice_templates FORCE ROW LEVEL SECURITY;
+CREATE POLICY invoice_templates_organisation_isolation ON invoice_templates
+  USING (app_rls_bypass() OR organisation_id = app_current_organisation())
+  WITH CHECK (app_rls_bypass() OR organisation_id = app_current_organisation());
+
+-- +goose Down
+DROP POLICY IF EXISTS invoice_templates_organisation_isolation ON invoice_templates;
+DROP TABLE IF EXISTS invoice_templates;
@@ -0,0 +1,152 @@
+-- +goose Up
+-- A bill is a supplier's invoice to us. It moves draft -> approved (posted
+-- to payables) -> paid once payments cover its total. Drafts and unpaid
+-- approved bills can be voided; voiding an approved bill posts a reversal.
+-- As with invoices, amounts are kept on the row as computed when the lines
+-- were last saved.
+CREATE TABLE bills (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  organisation_id UUID NOT NULL,
+  contact_id UUID NOT NULL,
+  -- the supplier's own invoice number
+  number TEXT NOT NULL,
+  reference TEXT NOT NULL DEFAULT '',
+  currency CHAR(3) NOT NULL,
+  issue_on DATE NOT NULL,
+  due_on DATE NOT NULL,
+  status TEXT NOT NULL DEFAULT 'draft',
+  payable_account_id UUID NOT NULL,
+
+  subtotal_minor BIGINT NOT NULL,
+  tax_minor BIGINT NOT NULL,
+  total_minor BIGINT NOT NULL,
+  paid_minor BIGINT NOT NULL DEFAULT 0,
+
+  -- posted on approval and on voiding an approved bill
+  transaction_id UUID,
+  void_transaction_id UUID,
+
+  approved_at TIMESTAMPTZ,
+  paid_on DATE,
+  voided_on DATE,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+
+  CONSTRAINT bills_organisation_id_id_unique UNIQUE (organisation_id, id),
+  -- suppliers number their own invoices, so numbers are unique per supplier
+  CONSTRAINT bills_number_unique UNIQUE (organisation_id, contact_id, number),
+  CONSTRAINT bills_contact_fk
+    FOREIGN KEY (organisation_id, contact_id) REFERENCES contacts(organisation_id, id),
+  CONSTRAINT bills_payable_account_fk
+    FOREIGN KEY (organisation_id, payable_account_id) REFERENCES accounts(organisation_id, id),
+  CONSTRAINT bills_transaction_fk
+    FOREIGN KEY (organisation_id, transaction_id) REFERENCES transactions(organisation_id, id),
+  CONSTRAINT bills_void_transaction_fk
+    FOREIGN KEY (organisation_id, void_transaction_id) REFERENCES transactions(organisation_id, id),
+  CONSTRAINT bills_status_check CHECK (status IN ('draft', 'approved', 'paid', 'void')),
+  CONSTRAINT bills_dates_check CHECK (issue_on <= due_on),
+  CONSTRAINT bills_paid_check CHECK (paid_minor BETWEEN 0 AND total_minor)
+);
+
+CREATE INDEX idx_bills_contact ON bills (organisation_id, contact_id);
+CREATE INDEX idx_bills_status ON bills (organisation_id, status, due_on);
+
+CREATE TRIGGER bills_set_updated_at
+BEFORE UPDATE ON bills
+FOR EACH ROW
+EXECUTE FUNCTION set_updated_at();
+
+CREATE TABLE bill_lines (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  organisation_id UUID NOT NULL,
+  bill_id UUID NOT NULL,
+  position INTEGER NOT NULL,
+  description TEXT NOT NULL DEFAULT '',
+  quantity_thousandths BIGINT NOT NULL,
+  unit_price_minor BIGINT NOT NULL,
+  account_id UUID NOT NULL,
+  tax_code_id UUID,
+  amount_minor BIGINT NOT NULL,
+  tax_minor BIGINT NOT NULL,
+
+  CONSTRAINT bill_lines_position_unique UNIQUE (bill_id, position),
+  CONSTRAINT bill_lines_bill_fk
+    FOREIGN KEY (organisation_id, bill_id) REFERENCES bills(organisation_id, id) ON DELETE CASCADE,
+  CONSTRAINT bill_lines_account_fk
+    FOREIGN KEY (organisation_id, account_id) REFERENCES accounts(organisation_id, id),
+  CONSTRAINT bill_lines_tax_code_fk
+    FOREIGN KEY (organisation_id, tax_code_id) REFERENCES tax_codes(organisation_id, id),
+  CONSTRAINT bill_lines_quantity_check CHECK (quantity_thousandths > 0)
+);
+
+CREATE INDEX idx_bill_lines_tax_code ON bill_lines (organisation_id, tax_code_id)
+  WHERE tax_code_id IS NOT NULL;
+
+CREATE TABLE bill_payments (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  organisation_id UUID NOT NULL,
+  bill_id UUID NOT NULL,
+  transaction_id UUID NOT NULL,
+  account_id UUID NOT NULL,
+  paid_on DATE NOT NULL,
+  amount_minor BIGINT NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+
+  CONSTRAINT bill_payments_transaction_unique UNIQUE (transaction_id),
+  CONSTRAINT bill_payments_bill_fk
+    FOREIGN KEY (organisation_id, bill_id) REFERENCES bills(organisation_id, id),
+  CONSTRAINT bill_payments_transaction_fk
+    FOREIGN KEY (organisation_id, transaction_id) REFERENCES transactions(organisation_id, id),
+  CONSTRAINT bill_payments_account_fk
+    FOREIGN KEY (organisation_id, account_id) REFERENCES accounts(organisation_id, id),
+  CONSTRAINT bill_payments_amount_check CHECK (amount_minor > 0)
+);
+
+CREATE INDEX idx_bill_payments_bill ON bill_payments (organisation_id, bill_id, paid_on);
+
+-- Bill postings (approval, payments, voids) link back to their bill.
+ALTER TABLE transactions
+  ADD COLUMN bill_id UUID,
+  ADD CONSTRAINT transactions_bill_fk
+    FOREIGN KEY (organisation_id, bill_id) REFERENCES bills(organisation_id, id);
+
+CREATE INDEX idx_transactions_bill ON transactions (organisation_id, bill_id)
+  WHERE bill_id IS NOT NULL;
+
+ALTER TABLE transactions
+  DROP CONSTRAINT transactions_source_check,
+  ADD CONSTRAINT transactions_source_check CHECK (source IN ('manual', 'csv', 'api', 'closing', 'invoice', 'bill'));
+
+ALTER TABLE bills ENABLE ROW LEVEL SECURITY;
+ALTER TABLE bills FORCE ROW LEVEL SECURITY;
+CREATE POLICY bills_organisation_isolation ON bills
+  USING (app_rls_bypass() OR organisation_id = app_current_organisation())
+  WITH CHECK (app_rls_bypass() OR organisation_id = app_current_organisation());
+
+ALTER TABLE bill_lines ENABLE ROW LEVEL SECURITY;
+ALTER TABLE bill_lines FORCE ROW LEVEL SECURITY;
+CREATE POLICY bill_lines_organisation_isolation ON bill_lines
+  USING (app_rls_bypass() OR organisation_id = app_current_organisation())
+  WITH CHECK (app_rls_bypass() OR organisation_id = app_current_organisation());
+
+ALTER TABLE bill_payments ENABLE ROW LEVEL SECURITY;
+ALTER TABLE bill_payments FORCE ROW LEVEL SECURITY;
+CREATE POLICY bill_payments_organisation_isolation ON bill_payments
+  USING (app_rls_bypass() OR organisation_id = app_current_organisation())
+  WITH CHECK (app_rls_bypass() OR organisation_id = app_current_organisation());
+
+-- +goose Down
+ALTER TABLE transactions
+  DROP CONSTRAINT transactions_source_check,
+  ADD CONSTRAINT transactions_source_check CHECK (source IN ('manual', 'csv', 'api', 'closing', 'invoice'));
+DROP INDEX IF EXISTS idx_transactions_bill;
+ALTER TABLE transactions
+  DROP CONSTRAINT IF EXISTS transactions_bill_fk,
+  DROP COLUMN IF EXISTS bill_id;
+DROP POLICY IF EXISTS bill_payments_organisation_isolation ON bill_payments;
+DROP POLICY IF EXISTS bill_lines_organisation_isolation ON bill_lines;
+DROP POLICY IF EXISTS bills_organisation_isolation ON bills;
+DROP TABLE IF EXISTS bill_payments;
+DROP TABLE IF EXISTS bill_lines;
+DROP TRIGGER IF EXISTS bills_set_updated_at ON bills;
+DROP TABLE IF EXISTS bills;
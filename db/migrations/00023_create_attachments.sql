@@ -0,0 +1,39 @@
+-- +goose Up
+-- Receipts and other documents attached to transactions. The bytes live in
+-- the blob store under <organisation_id>/<hex sha256>, so a file attached to
+-- several transactions is stored once; the blob is deleted with the last
+-- row that refers to it.
+CREATE TABLE attachments (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  organisation_id UUID NOT NULL,
+  transaction_id UUID NOT NULL,
+  sha256 BYTEA NOT NULL,
+  filename TEXT NOT NULL,
+  content_type TEXT NOT NULL,
+  size_bytes BIGINT NOT NULL,
+  uploaded_by UUID NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+
+  CONSTRAINT attachments_organisation_id_id_unique UNIQUE (organisation_id, id),
+  -- uploading the same file to a transaction again returns the first row
+  CONSTRAINT attachments_content_unique UNIQUE (transaction_id, sha256),
+  CONSTRAINT attachments_transaction_fk
+    FOREIGN KEY (organisation_id, transaction_id) REFERENCES transactions(organisation_id, id),
+  CONSTRAINT attachments_uploaded_by_fk
+    FOREIGN KEY (uploaded_by) REFERENCES users(id),
+  CONSTRAINT attachments_sha256_check CHECK (octet_length(sha256) = 32),
+  CONSTRAINT attachments_size_check CHECK (size_bytes > 0)
+);
+
+CREATE INDEX idx_attachments_transaction ON attachments (organisation_id, transaction_id, created_at);
+CREATE INDEX idx_attachments_content ON attachments (organisation_id, sha256);
+
+ALTER TABLE attachments ENABLE ROW LEVEL SECURITY;
+ALTER TABLE attachments FORCE ROW LEVEL SECURITY;
+CREATE POLICY attachments_organisation_isolation ON attachments
+  USING (app_rls_bypass() OR organisation_id = app_current_organisation())
+  WITH CHECK (app_rls_bypass() OR organisation_id = app_current_organisation());
+
+-- +goose Down
+DROP POLICY IF EXISTS attachments_organisation_isolation ON attachments;
+DROP TABLE IF EXISTS attachments;
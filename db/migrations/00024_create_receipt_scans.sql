@@ -0,0 +1,95 @@
+-- +goose Up
+-- Receipts can be uploaded before the transaction they record exists: an
+-- attachment without a transaction waits in the receipts inbox until it is
+-- matched to a bank line or posted from its draft.
+ALTER TABLE attachments ALTER COLUMN transaction_id DROP NOT NULL;
+
+-- uploading the same file to the inbox again returns the first row
+CREATE UNIQUE INDEX idx_attachments_inbox_content ON attachments (organisation_id, sha256)
+  WHERE transaction_id IS NULL;
+
+-- One OCR scan per attachment. The row is also the scanner's job: workers
+-- claim queued rows with FOR UPDATE SKIP LOCKED, and a running row whose
+-- worker died is claimed again once locked_at is stale. Extracted values
+-- carry the extractor's confidence from 0 to 1.
+--
+-- outcome records what the scan led to: 'attached' when the file was
+-- already on a transaction, 'matched' when it was matched to an imported
+-- statement line, 'draft' when it waits to be posted as a new transaction
+-- and 'posted' once it has been.
+CREATE TABLE receipt_scans (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  organisation_id UUID NOT NULL,
+  attachment_id UUID NOT NULL,
+  status TEXT NOT NULL DEFAULT 'queued',
+  attempts INTEGER NOT NULL DEFAULT 0,
+  run_after TIMESTAMPTZ NOT NULL DEFAULT now(),
+  locked_at TIMESTAMPTZ,
+  error TEXT NOT NULL DEFAULT '',
+
+  text TEXT NOT NULL DEFAULT '',
+  merchant TEXT,
+  merchant_confidence DOUBLE PRECISION,
+  receipt_date DATE,
+  date_confidence DOUBLE PRECISION,
+  total_minor BIGINT,
+  total_confidence DOUBLE PRECISION,
+  gst_minor BIGINT,
+  gst_confidence DOUBLE PRECISION,
+
+  outcome TEXT,
+  statement_line_id UUID,
+  transaction_id UUID,
+  -- suggestions for posting a draft, from the merchant's contact alias
+  contact_id UUID,
+  account_id UUID,
+
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+
+  CONSTRAINT receipt_scans_organisation_id_id_unique UNIQUE (organisation_id, id),
+  CONSTRAINT receipt_scans_attachment_unique UNIQUE (attachment_id),
+  CONSTRAINT receipt_scans_attachment_fk
+    FOREIGN KEY (organisation_id, attachment_id) REFERENCES attachments(organisation_id, id) ON DELETE CASCADE,
+  CONSTRAINT receipt_scans_statement_line_fk
+    FOREIGN KEY (statement_line_id) REFERENCES statement_lines(id) ON DELETE SET NULL,
+  CONSTRAINT receipt_scans_transaction_fk
+    FOREIGN KEY (organisation_id, transaction_id) REFERENCES transactions(organisation_id, id),
+  CONSTRAINT receipt_scans_contact_fk
+    FOREIGN KEY (organisation_id, contact_id) REFERENCES contacts(organisation_id, id),
+  CONSTRAINT receipt_scans_account_fk
+    FOREIGN KEY (organisation_id, account_id) REFERENCES accounts(organisation_id, id),
+  CONSTRAINT receipt_scans_status_check CHECK (status IN ('queued', 'running', 'done', 'failed')),
+  CONSTRAINT receipt_scans_outcome_check CHECK (outcome IN ('attached', 'matched', 'draft', 'posted')),
+  CONSTRAINT receipt_scans_done_check CHECK ((status = 'done') = (outcome IS NOT NULL)),
+  CONSTRAINT receipt_scans_confidence_check CHECK (
+    merchant_confidence BETWEEN 0 AND 1 AND date_confidence BETWEEN 0 AND 1
+    AND total_confidence BETWEEN 0 AND 1 AND gst_confidence BETWEEN 0 AND 1
+  )
+);
+
+CREATE INDEX idx_receipt_scans_queue ON receipt_scans (run_after)
+  WHERE status IN ('queued', 'running');
+CREATE INDEX idx_receipt_scans_outcome ON receipt_scans (organisation_id, outcome, created_at);
+-- a statement line is evidenced by at most one receipt
+CREATE UNIQUE INDEX idx_receipt_scans_statement_line ON receipt_scans (statement_line_id)
+  WHERE statement_line_id IS NOT NULL;
+
+CREATE TRIGGER receipt_scans_set_updated_at
+BEFORE UPDATE ON receipt_scans
+FOR EACH ROW
+EXECUTE FUNCTION set_updated_at();
+
+ALTER TABLE receipt_scans ENABLE ROW LEVEL SECURITY;
+ALTER TABLE receipt_scans FORCE ROW LEVEL SECURITY;
+CREATE POLICY receipt_scans_organisation_isolation ON receipt_scans
+  USING (app_rls_bypass() OR organisation_id = app_current_organisation())
+  WITH CHECK (app_rls_bypass() OR organisation_id = app_current_organisation());
+
+-- +goose Down
+DROP POLICY IF EXISTS receipt_scans_organisation_isolation ON receipt_scans;
+DROP TRIGGER IF EXISTS receipt_scans_set_updated_at ON receipt_scans;
+DROP TABLE IF EXISTS receipt_scans;
+DROP INDEX IF EXISTS idx_attachments_inbox_content;
+DELETE FROM attachments WHERE transaction_id IS NULL;
+ALTER TABLE attachments ALTER COLUMN transaction_id SET NOT NULL;
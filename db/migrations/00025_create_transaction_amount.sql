@@ -0,0 +1,19 @@
+-- +goose Up
+-- The amount GET /transactions filters and sorts by: the given account's side
+-- of the transaction, or its total debits when no account is given. It is
+-- only worked out for listings that ask for it, through
+-- idx_ledger_entries_transaction_account.
+-- +goose StatementBegin
+CREATE FUNCTION transaction_amount(transaction_id UUID, account_id UUID) RETURNS BIGINT
+LANGUAGE sql STABLE AS $$
+  SELECT CASE
+    WHEN $2 IS NULL THEN COALESCE(SUM(le.amount_minor) FILTER (WHERE le.amount_minor > 0), 0)
+    ELSE COALESCE(SUM(le.amount_minor) FILTER (WHERE le.account_id = $2), 0)
+  END::bigint
+  FROM ledger_entries le
+  WHERE le.transaction_id = $1
+$$;
+-- +goose StatementEnd
+
+-- +goose Down
+DROP FUNCTION IF EXISTS transaction_amount(UUID, UUID);
@@ -0,0 +1,36 @@
+-- +goose Up
+-- The API connects as gofigure_app rather than as the owner that runs these
+-- migrations: owners and superusers skip row-level security, even when it is
+-- forced, and gofigure_app is neither. Deployments give it a login, as the
+-- compose database does in db/init, or grant it to the role the API uses.
+-- +goose StatementBegin
+DO $$
+BEGIN
+  IF NOT EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'gofigure_app') THEN
+    CREATE ROLE gofigure_app NOLOGIN NOSUPERUSER NOBYPASSRLS;
+  END IF;
+END
+$$;
+-- +goose StatementEnd
+
+GRANT USAGE ON SCHEMA public TO gofigure_app;
+GRANT SELECT, INSERT, UPDATE, DELETE ON ALL TABLES IN SCHEMA public TO gofigure_app;
+GRANT USAGE, SELECT ON ALL SEQUENCES IN SCHEMA public TO gofigure_app;
+
+-- the audit trail only grows
+REVOKE UPDATE, DELETE ON audit_events FROM gofigure_app;
+
+-- tables added by later migrations are the API's too
+ALTER DEFAULT PRIVILEGES IN SCHEMA public
+  GRANT SELECT, INSERT, UPDATE, DELETE ON TABLES TO gofigure_app;
+ALTER DEFAULT PRIVILEGES IN SCHEMA public
+  GRANT USAGE, SELECT ON SEQUENCES TO gofigure_app;
+
+-- +goose Down
+ALTER DEFAULT PRIVILEGES IN SCHEMA public
+  REVOKE USAGE, SELECT ON SEQUENCES FROM gofigure_app;
+ALTER DEFAULT PRIVILEGES IN SCHEMA public
+  REVOKE SELECT, INSERT, UPDATE, DELETE ON TABLES FROM gofigure_app;
+REVOKE ALL ON ALL SEQUENCES IN SCHEMA public FROM gofigure_app;
+REVOKE ALL ON ALL TABLES IN SCHEMA public FROM gofigure_app;
+REVOKE USAGE ON SCHEMA public FROM gofigure_app;
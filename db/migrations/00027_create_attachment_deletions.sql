@@ -0,0 +1,24 @@
+-- +goose Up
+-- Blobs left without an attachment. A row is written in the transaction that
+-- deletes the last attachment of a file, and the attachment sweeper deletes
+-- the blob once that has committed, so content is never removed while the
+-- deletion could still roll back or an attachment still refers to it.
+CREATE TABLE attachment_deletions (
+  organisation_id UUID NOT NULL,
+  sha256 BYTEA NOT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+
+  CONSTRAINT attachment_deletions_pkey PRIMARY KEY (organisation_id, sha256),
+  CONSTRAINT attachment_deletions_organisation_fk
+    FOREIGN KEY (organisation_id) REFERENCES organisations(id)
+);
+
+ALTER TABLE attachment_deletions ENABLE ROW LEVEL SECURITY;
+ALTER TABLE attachment_deletions FORCE ROW LEVEL SECURITY;
+CREATE POLICY attachment_deletions_organisation_isolation ON attachment_deletions
+  USING (app_rls_bypass() OR organisation_id = app_current_organisation())
+  WITH CHECK (app_rls_bypass() OR organisation_id = app_current_organisation());
+
+-- +goose Down
+DROP POLICY IF EXISTS attachment_deletions_organisation_isolation ON attachment_deletions;
+DROP TABLE IF EXISTS attachment_deletions;
@@ -0,0 +1,23 @@
+-- +goose Up
+-- A statement's cleared balance is its opening balance plus the entries
+-- matched to its lines, so the first statement of an account with history
+-- can balance. Sessions opened before carry on from the closing balance of
+-- the account's previous statement, as the cleared balance did.
+SET LOCAL app.rls_bypass = 'on';
+
+ALTER TABLE reconciliations ADD COLUMN opening_balance_minor BIGINT NOT NULL DEFAULT 0;
+
+UPDATE reconciliations r
+SET opening_balance_minor = COALESCE((
+  SELECT p.closing_balance_minor FROM reconciliations p
+  WHERE p.organisation_id = r.organisation_id
+    AND p.account_id = r.account_id
+    AND p.ends_on < r.starts_on
+  ORDER BY p.ends_on DESC, p.created_at DESC
+  LIMIT 1
+), 0);
+
+ALTER TABLE reconciliations ALTER COLUMN opening_balance_minor DROP DEFAULT;
+
+-- +goose Down
+ALTER TABLE reconciliations DROP COLUMN IF EXISTS opening_balance_minor;